@@ -32,10 +32,86 @@ type InternalServiceExportSpec struct {
 	IsInternalLoadBalancer bool `json:"isInternalLoadBalancer,omitempty"`
 	// PublicIPResourceID is the Azure Resource URI of public IP. This is only applicable for Load Balancer type Services.
 	PublicIPResourceID *string `json:"publicIPResourceID,omitempty"`
+	// PublicIPAddresses are the Azure public IP addresses assigned to the load balancer of the Service, one for each
+	// IP family, for example, both an IPv4 and an IPv6 public IP address for a dual-stack Service.
+	// PublicIPResourceID and IsDNSLabelConfigured describe the public IP address of the first load balancer ingress IP.
+	// This is only applicable for Load Balancer type Services.
+	// +listType=atomic
+	// +optional
+	PublicIPAddresses []PublicIPAddress `json:"publicIPAddresses,omitempty"`
 	// Weight is the weight of the ServiceExport.
 	// If unspecified, weight defaults to 1.
 	// The value is from serviceExport "networking.fleet.azure.com/weight" annotation and should be in the range [0, 1000].
 	Weight *int64 `json:"weight,omitempty"`
+	// WeightPercentage is the percentage of the TrafficManagerBackend weight assigned to the ServiceExport.
+	// It is set when the serviceExport "networking.fleet.azure.com/weight" annotation is a percentage (for example,
+	// "30%") and the Weight holds the same value. The services with percentages are assigned their shares of the
+	// TrafficManagerBackend weight first, and the remaining weight is distributed among the services with absolute weights.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	WeightPercentage *int64 `json:"weightPercentage,omitempty"`
+	// MinWeightPercentage is the minimum percentage of the TrafficManagerBackend weight assigned to the ServiceExport.
+	// The value is from serviceExport "networking.fleet.azure.com/min-weight-percentage" annotation.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MinWeightPercentage *int64 `json:"minWeightPercentage,omitempty"`
+	// MaxWeightPercentage is the maximum percentage of the TrafficManagerBackend weight assigned to the ServiceExport.
+	// The value is from serviceExport "networking.fleet.azure.com/max-weight-percentage" annotation.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxWeightPercentage *int64 `json:"maxWeightPercentage,omitempty"`
+	// ReadyEndpointCount is the number of the ready endpoints backing the exported Service in the member cluster.
+	// It is only set when the serviceExport "networking.fleet.azure.com/weight-by-ready-endpoints" annotation is "true",
+	// and is used instead of the Weight as the weight of the Azure Traffic Manager endpoint, so that the traffic is
+	// distributed in proportion to the capacities of the clusters.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ReadyEndpointCount *int64 `json:"readyEndpointCount,omitempty"`
+	// Priority is the priority of the ServiceExport when using the "Priority" traffic routing method.
+	// The value is from serviceExport "networking.fleet.azure.com/priority" annotation and should be in the range [1, 1000].
+	// +optional
+	Priority *int64 `json:"priority,omitempty"`
+	// ExternalTargetFQDN is the fully-qualified domain name which fronts the Service outside of the Service object,
+	// for example, a shared ingress.
+	// When set, the Service is exposed as an Azure Traffic Manager external endpoint targeting this domain name and
+	// the load balancer related fields are ignored. It cannot be set together with ExternalTargetIP.
+	// The value is from serviceExport "networking.fleet.azure.com/external-target-fqdn" annotation.
+	// +optional
+	ExternalTargetFQDN *string `json:"externalTargetFQDN,omitempty"`
+	// ExternalTargetIP is the public IP address which fronts the Service outside of the Service object.
+	// When set, the Service is exposed as an Azure Traffic Manager external endpoint targeting this IP address and
+	// the load balancer related fields are ignored. It cannot be set together with ExternalTargetFQDN.
+	// The value is from serviceExport "networking.fleet.azure.com/external-target-ip" annotation.
+	// +optional
+	ExternalTargetIP *string `json:"externalTargetIP,omitempty"`
+	// ExternalTargetLocation is the Azure region closest to the external target, for example, "eastus" for an on-premises
+	// cluster located in the east of the US. It is only applicable when the external target is set.
+	// The value is from serviceExport "networking.fleet.azure.com/external-target-location" annotation.
+	// +optional
+	ExternalTargetLocation *string `json:"externalTargetLocation,omitempty"`
+	// Region is the Azure region of the member cluster which exports the Service, for example, "eastus".
+	// It is used as the location of the Azure Traffic Manager endpoint when using the "Performance" traffic routing
+	// method.
+	// The value is from the cloud config of the member cluster.
+	// +optional
+	Region *string `json:"region,omitempty"`
+}
+
+// PublicIPAddress is an Azure public IP address assigned to the load balancer of the exported Service.
+type PublicIPAddress struct {
+	// IPFamily is the IP family of the public IP address.
+	// +kubebuilder:validation:Enum=IPv4;IPv6
+	// +required
+	IPFamily corev1.IPFamily `json:"ipFamily"`
+	// ResourceID is the Azure Resource URI of the public IP address.
+	// +required
+	ResourceID string `json:"resourceID"`
+	// IsDNSLabelConfigured determines if the public IP address has a DNS label configured.
+	// +optional
+	IsDNSLabelConfigured bool `json:"isDNSLabelConfigured,omitempty"`
 }
 
 // InternalServiceExportStatus contains the current status of an InternalServiceExport.
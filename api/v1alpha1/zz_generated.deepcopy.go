@@ -310,11 +310,61 @@ func (in *InternalServiceExportSpec) DeepCopyInto(out *InternalServiceExportSpec
 		*out = new(string)
 		**out = **in
 	}
+	if in.PublicIPAddresses != nil {
+		in, out := &in.PublicIPAddresses, &out.PublicIPAddresses
+		*out = make([]PublicIPAddress, len(*in))
+		copy(*out, *in)
+	}
 	if in.Weight != nil {
 		in, out := &in.Weight, &out.Weight
 		*out = new(int64)
 		**out = **in
 	}
+	if in.WeightPercentage != nil {
+		in, out := &in.WeightPercentage, &out.WeightPercentage
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinWeightPercentage != nil {
+		in, out := &in.MinWeightPercentage, &out.MinWeightPercentage
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxWeightPercentage != nil {
+		in, out := &in.MaxWeightPercentage, &out.MaxWeightPercentage
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ReadyEndpointCount != nil {
+		in, out := &in.ReadyEndpointCount, &out.ReadyEndpointCount
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ExternalTargetFQDN != nil {
+		in, out := &in.ExternalTargetFQDN, &out.ExternalTargetFQDN
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExternalTargetIP != nil {
+		in, out := &in.ExternalTargetIP, &out.ExternalTargetIP
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExternalTargetLocation != nil {
+		in, out := &in.ExternalTargetLocation, &out.ExternalTargetLocation
+		*out = new(string)
+		**out = **in
+	}
+	if in.Region != nil {
+		in, out := &in.Region, &out.Region
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InternalServiceExportSpec.
@@ -602,6 +652,21 @@ func (in *OwnerServiceReference) DeepCopy() *OwnerServiceReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublicIPAddress) DeepCopyInto(out *PublicIPAddress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublicIPAddress.
+func (in *PublicIPAddress) DeepCopy() *PublicIPAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(PublicIPAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceExport) DeepCopyInto(out *ServiceExport) {
 	*out = *in
@@ -21,6 +21,11 @@ const (
 	// field(s) under contention, which cluster won, and why.
 	// Users should not expect detailed per-cluster information in the conflict message.
 	ServiceExportConflict ServiceExportConditionType = "Conflict"
+	// ServiceExportExposedAsTrafficManagerEndpoint means that the exported service is exposed as an Azure Traffic
+	// Manager endpoint by a TrafficManagerBackend.
+	// It is set on the InternalServiceExport by the hub networking controller when the exported service is referenced by
+	// a TrafficManagerBackend. When "False", the condition message contains the reason why the service cannot be exposed.
+	ServiceExportExposedAsTrafficManagerEndpoint ServiceExportConditionType = "ExposedAsTrafficManagerEndpoint"
 )
 
 // ServiceExportStatus contains the current status of an export.
@@ -5,7 +5,10 @@ Licensed under the MIT license.
 
 package v1beta1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 const (
 	TrafficManagerBackendKind = "TrafficManagerBackend"
@@ -18,6 +21,7 @@ const (
 // +kubebuilder:printcolumn:JSONPath=`.spec.profile.name`,name="Profile",type=string
 // +kubebuilder:printcolumn:JSONPath=`.spec.backend.name`,name="Backend",type=string
 // +kubebuilder:printcolumn:JSONPath=`.status.conditions[?(@.type=='Accepted')].status`,name="Is-Accepted",type=string
+// +kubebuilder:printcolumn:JSONPath=`.status.conditions[?(@.type=='Ready')].status`,name="Is-Ready",type=string
 // +kubebuilder:printcolumn:JSONPath=`.metadata.creationTimestamp`,name="Age",type=date
 
 // TrafficManagerBackend is used to manage the Azure Traffic Manager Endpoints using cloud native way.
@@ -46,40 +50,158 @@ type TrafficManagerBackendSpec struct {
 
 	// The reference to a backend.
 	// +required
-	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec.backend is immutable"
+	// +kubebuilder:validation:XValidation:rule="self.name == oldSelf.name",message="spec.backend.name is immutable"
 	Backend TrafficManagerBackendRef `json:"backend"`
 
 	// The total weight of endpoints behind the serviceImport when using the 'Weighted' traffic routing method.
 	// Possible values are from 0 to 1000.
 	// By default, the routing method is 'Weighted'.
-	// If weight is set to 0, all the endpoints behind the serviceImport will be removed from the profile.
-	// The actual weight of each endpoint is the ceiling value of a number computed as weight/(sum of all weights behind the serviceImport)
-	// * weight of serviceExport.
+	// If weight is set to 0, all the endpoints behind the serviceImport will be removed from the profile, regardless of
+	// the traffic routing method of the profile.
+	// The weight is distributed among the endpoints in proportion to the weights of the serviceExports, so that the sum of
+	// the endpoint weights equals the weight exactly. Each endpoint gets the integer part of
+	// weight/(sum of all weights behind the serviceImport) * weight of serviceExport first, and the remaining weight is given
+	// one by one to the endpoints with the largest fractional parts (ties are broken by the cluster name).
 	// For example, if the weight is 500 and there are two serviceExports from cluster-1 (weight: 100) and cluster-2 (weight: 200)
 	// behind serviceImport.
 	// As a result, two endpoints will be created.
-	// The weight of endpoint from cluster-1 is 100/(100+200)*500 = 167, and the weight of cluster-2 is 200/(100+200)*500 = 334.
-	// There may be slight deviations from the exact proportions defined in the serviceExports due to ceiling calculations.
+	// The weight of endpoint from cluster-1 is 100/(100+200)*500 = 166.67, and the weight of cluster-2 is 200/(100+200)*500 = 333.33.
+	// The remaining weight 1 is given to cluster-1, so the weights of the endpoints are 167 and 333.
 	// +optional
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=1000
 	// +kubebuilder:default=1
 	Weight *int64 `json:"weight,omitempty"`
+
+	// Suspend tells the controller to stop reconciling the Azure Traffic Manager endpoints of the backend, for example,
+	// to freeze the traffic configuration during a maintenance window.
+	// The existing endpoints are left untouched while the backend is suspended and the changes made in the meantime
+	// are applied when it is resumed. The endpoints are still deleted when the backend is deleted.
+	// Defaults to false.
+	// +optional
+	Suspend *bool `json:"suspend,omitempty"`
+
+	// IPFamily is the preferred IP family of the public IP address used as the target of the Azure Traffic Manager
+	// endpoints, for example, to expose the IPv6 public IP address of the dual-stack services.
+	// The service which does not have a public IP address of the IP family is not accepted as an endpoint.
+	// If not set, the public IP address of the first load balancer ingress IP of the service is used.
+	// +optional
+	// +kubebuilder:validation:Enum=IPv4;IPv6
+	IPFamily *corev1.IPFamily `json:"ipFamily,omitempty"`
+
+	// MinHealthyEndpoints is the minimum number of accepted endpoints whose monitor status is Online for the backend to
+	// report the MinimumHealthyEndpointsMet condition as true, for example, to let the progressive delivery controllers
+	// gate the rollout on the health of the endpoints.
+	// If not set, the MinimumHealthyEndpointsMet condition is not reported.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=200
+	MinHealthyEndpoints *int64 `json:"minHealthyEndpoints,omitempty"`
 }
 
-// TrafficManagerProfileRef is a reference to a trafficManagerProfile object in the same namespace as the TrafficManagerBackend object.
+// TrafficManagerProfileRef is a reference to a trafficManagerProfile object.
 type TrafficManagerProfileRef struct {
 	// Name is the name of the referenced trafficManagerProfile.
 	// +required
 	Name string `json:"name"`
+
+	// Namespace is the namespace of the referenced trafficManagerProfile.
+	// If not set, the trafficManagerProfile in the same namespace as the TrafficManagerBackend object is used.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // TrafficManagerBackendRef is the reference to a backend.
 // Currently, we only support one backend type: ServiceImport.
+// +kubebuilder:validation:XValidation:rule="!has(self.additionalNames) || !(self.name in self.additionalNames)",message="spec.backend.additionalNames must not contain spec.backend.name"
+// +kubebuilder:validation:XValidation:rule="!has(self.clusterSubnets) || !has(self.clusterWeightOverrides)",message="spec.backend.clusterWeightOverrides cannot be used together with spec.backend.clusterSubnets"
 type TrafficManagerBackendRef struct {
 	// Name is the reference to the ServiceImport in the same namespace as the TrafficManagerBackend object.
 	// +required
 	Name string `json:"name"`
+
+	// AdditionalNames are the references to more ServiceImports in the same namespace as the TrafficManagerBackend
+	// object, whose exported services are exposed together with the ones behind the ServiceImport referenced by Name,
+	// for example, to put the blue and green deployments of an application behind the same profile.
+	// The weight of the backend is distributed among the exported services behind all the ServiceImports combined.
+	// The ServiceImports which are not found are skipped, while the backend is invalid when the ServiceImport
+	// referenced by Name is not found.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=10
+	AdditionalNames []string `json:"additionalNames,omitempty"`
+
+	// ClusterSelector restricts the member clusters whose exported services are exposed as the Azure Traffic Manager
+	// endpoints, for example, to temporarily pin the traffic to a subset of clusters during an incident without updating
+	// every serviceExport.
+	// The endpoints of the excluded clusters are deleted and the weights of their exported services are not counted
+	// when distributing the weight of the backend.
+	// If not set, the services exported from all the clusters are exposed.
+	// +optional
+	ClusterSelector *TrafficManagerBackendClusterSelector `json:"clusterSelector,omitempty"`
+
+	// ClusterWeightOverrides overrides the weights configured in the serviceExports of the named member clusters, so
+	// that the backend owner has the authoritative control over the traffic split without updating the serviceExports
+	// owned by the cluster teams. For example, setting the weight of a cluster to 0 deletes the endpoints of the cluster.
+	// The overridden weight replaces both the weight and the weight percentage of the serviceExports from the cluster,
+	// and is only used by the 'Weighted' traffic routing method.
+	// The overrides of the clusters which are not listed in the ServiceImports are ignored and reported as warning
+	// events.
+	// +optional
+	// +listType=map
+	// +listMapKey=cluster
+	// +kubebuilder:validation:MaxItems=100
+	ClusterWeightOverrides []ClusterWeight `json:"clusterWeightOverrides,omitempty"`
+
+	// ClusterSubnets configures the subnets of the named member clusters when using the 'Subnet' traffic routing
+	// method, which take precedence over the subnets annotation of the serviceExports from the clusters.
+	// The DNS queries from the clients within the subnets are routed to the endpoints of the cluster. The subnets must
+	// not overlap with the subnets of the other endpoints of the profile, and the weights are not used by the 'Subnet'
+	// traffic routing method.
+	// +optional
+	// +listType=map
+	// +listMapKey=cluster
+	// +kubebuilder:validation:MaxItems=100
+	ClusterSubnets []ClusterSubnets `json:"clusterSubnets,omitempty"`
+}
+
+// ClusterSubnets defines the subnets routed to the services exported from a member cluster.
+type ClusterSubnets struct {
+	// Cluster is the name of the member cluster.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Cluster string `json:"cluster"`
+
+	// Subnets are the IP address ranges of the clients in the CIDR notation, for example, "10.1.0.0/16".
+	// +required
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=100
+	Subnets []string `json:"subnets"`
+}
+
+// ClusterWeight defines the weight of the services exported from a member cluster.
+type ClusterWeight struct {
+	// Cluster is the name of the member cluster.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Cluster string `json:"cluster"`
+
+	// Weight is the weight of the services exported from the cluster.
+	// Possible values are from 0 to 1000.
+	// +required
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	Weight int64 `json:"weight"`
+}
+
+// TrafficManagerBackendClusterSelector selects the member clusters behind the backend.
+type TrafficManagerBackendClusterSelector struct {
+	// Clusters is the allowlist of the member cluster names whose exported services are exposed.
+	// An empty list excludes all the clusters.
+	// +required
+	// +listType=set
+	Clusters []string `json:"clusters"`
 }
 
 // TrafficManagerEndpointStatus is the status of Azure Traffic Manager endpoint which is successfully accepted under the traffic
@@ -98,31 +220,150 @@ type TrafficManagerEndpointStatus struct {
 	// +optional
 	Weight *int64 `json:"weight,omitempty"`
 
+	// Percentage is the approximate percentage of the traffic routed to this endpoint among the accepted endpoints of
+	// the backend when using the 'Weighted' traffic routing method, that is, the weight of the endpoint divided by the
+	// sum of the weights of the accepted endpoints, rounded to the nearest integer.
+	// It does not take the health of the endpoints into account.
+	// +optional
+	Percentage *int64 `json:"percentage,omitempty"`
+
 	// The fully-qualified DNS name or IP address of the endpoint.
 	// +optional
 	Target *string `json:"target,omitempty"`
 
+	// The list of geographic regions mapped to this endpoint when using the 'Geographic' traffic routing method.
+	// +optional
+	GeoMapping []string `json:"geoMapping,omitempty"`
+
+	// The priority of this endpoint when using the 'Priority' traffic routing method.
+	// Possible values are from 1 to 1000, lower values represent higher priority.
+	// +optional
+	Priority *int64 `json:"priority,omitempty"`
+
+	// The list of subnets in the CIDR notation mapped to this endpoint when using the 'Subnet' traffic routing method.
+	// +optional
+	Subnets []string `json:"subnets,omitempty"`
+
+	// MonitorStatus is the health monitoring status of the endpoint reported by the Azure Traffic Manager.
+	// It may be stale as the Azure Traffic Manager probes the endpoint asynchronously.
+	// +optional
+	MonitorStatus *TrafficManagerEndpointMonitorStatus `json:"monitorStatus,omitempty"`
+
 	// From is where the endpoint is exported from.
 	// +optional
 	From *FromCluster `json:"from,omitempty"`
+
+	// FromGeneration is the generation of the internalServiceExport which the endpoint was last derived from.
+	// It tells whether the endpoint reflects the latest service exported from the cluster.
+	// +optional
+	FromGeneration int64 `json:"fromGeneration,omitempty"`
 }
 
+// TrafficManagerEndpointMonitorStatus is the health monitoring status of an Azure Traffic Manager endpoint.
+// https://learn.microsoft.com/en-us/azure/traffic-manager/traffic-manager-monitoring#endpoint-monitor-status
+type TrafficManagerEndpointMonitorStatus string
+
+const (
+	TrafficManagerEndpointMonitorStatusCheckingEndpoint TrafficManagerEndpointMonitorStatus = "CheckingEndpoint"
+	TrafficManagerEndpointMonitorStatusDegraded         TrafficManagerEndpointMonitorStatus = "Degraded"
+	TrafficManagerEndpointMonitorStatusDisabled         TrafficManagerEndpointMonitorStatus = "Disabled"
+	TrafficManagerEndpointMonitorStatusInactive         TrafficManagerEndpointMonitorStatus = "Inactive"
+	TrafficManagerEndpointMonitorStatusOnline           TrafficManagerEndpointMonitorStatus = "Online"
+	TrafficManagerEndpointMonitorStatusStopped          TrafficManagerEndpointMonitorStatus = "Stopped"
+	TrafficManagerEndpointMonitorStatusUnmonitored      TrafficManagerEndpointMonitorStatus = "Unmonitored"
+)
+
 // FromCluster contains service configuration mapped to a specific source cluster.
 type FromCluster struct {
 	// ClusterStatus describes the source cluster status.
 	ClusterStatus `json:",inline"`
 
-	// Weight defines the weight configured in the serviceExport from the source cluster.
+	// Weight defines the weight configured in the serviceExport from the source cluster, or the weight override of the
+	// cluster in the spec.backend.clusterWeightOverrides.
 	// Possible values are from 0 to 1000.
 	// +optional
 	Weight *int64 `json:"weight,omitempty"`
 }
 
+// InvalidEndpointReason is the reason why an exported service cannot be exposed as an Azure Traffic Manager endpoint.
+// +enum
+type InvalidEndpointReason string
+
+const (
+	// InvalidEndpointReasonInvalidService means the exported service is invalid, for example, it's not a load balancer
+	// service with a DNS name, or its weight, priority or geographic regions conflict with the other exported services.
+	InvalidEndpointReasonInvalidService InvalidEndpointReason = "InvalidService"
+
+	// InvalidEndpointReasonServiceConflict means the service exported from the cluster is in conflict with the services
+	// exported from the other clusters.
+	InvalidEndpointReasonServiceConflict InvalidEndpointReason = "ServiceConflict"
+
+	// InvalidEndpointReasonEndpointFailed means the Azure Traffic Manager endpoint failed to be created or updated.
+	InvalidEndpointReasonEndpointFailed InvalidEndpointReason = "EndpointFailed"
+)
+
+// InvalidEndpointStatus is the status of an exported service which cannot be exposed as an Azure Traffic Manager
+// endpoint.
+type InvalidEndpointStatus struct {
+	// Cluster is the name of the cluster which the service is exported from.
+	// +required
+	Cluster string `json:"cluster"`
+
+	// ServiceImport is the name of the serviceImport which the service is exported for.
+	// +required
+	ServiceImport string `json:"serviceImport"`
+
+	// Name of the Azure Traffic Manager endpoint, which is set when the endpoint failed to be created or updated.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Reason is why the service cannot be exposed.
+	// +kubebuilder:validation:Enum=InvalidService;ServiceConflict;EndpointFailed
+	// +required
+	Reason InvalidEndpointReason `json:"reason"`
+
+	// Message is the human-readable details of the failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 type TrafficManagerBackendStatus struct {
 	// Endpoints contains a list of accepted Azure endpoints which are created or updated under the traffic manager Profile.
 	// +optional
 	Endpoints []TrafficManagerEndpointStatus `json:"endpoints,omitempty"`
 
+	// InvalidEndpoints contains a list of exported services which cannot be exposed as the Azure Traffic Manager
+	// endpoints, including the ones whose endpoints failed to be created or updated, sorted by the serviceImport and
+	// cluster.
+	// +optional
+	InvalidEndpoints []InvalidEndpointStatus `json:"invalidEndpoints,omitempty"`
+
+	// ProfileResourceID is the fully qualified Azure resource Id of the Azure Traffic Manager profile under which the
+	// endpoints were last created or updated, including the resource group.
+	// When the trafficManagerProfile is recreated under another resource group, the endpoints under this profile are
+	// deleted before creating the endpoints under the new one.
+	// Ex - /subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Network/trafficManagerProfiles/{resourceName}
+	// +optional
+	ProfileResourceID string `json:"profileResourceID,omitempty"`
+
+	// EndpointsCreated is false when the finalizer of the backend is added but none of its Azure Traffic Manager
+	// endpoints has been created yet, and becomes true once any endpoint is created or updated.
+	// The deletion skips calling Azure when it is false, so that the backend is not stuck in the deleting state when the
+	// endpoints were never created, for example, because of the authorization failure.
+	// +optional
+	EndpointsCreated *bool `json:"endpointsCreated,omitempty"`
+
+	// DrainStartTime is when the Azure Traffic Manager endpoints of the backend were disabled to drain the DNS traffic
+	// before being deleted, when the backend is deleted with the drain grace period annotation.
+	// +optional
+	DrainStartTime *metav1.Time `json:"drainStartTime,omitempty"`
+
+	// LastSyncTime is when the Azure Traffic Manager endpoints of the backend were last reconciled against Azure
+	// without error, even if some exported services cannot be exposed.
+	// It is used to detect the backends which have not been synced for a while while the condition is still true.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
 	// Current backend status.
 	// +optional
 	// +patchMergeKey=type
@@ -150,6 +391,13 @@ const (
 	// Possible reasons for this condition to be False are:
 	//
 	// * "Invalid"
+	// * "ZeroTotalWeight"
+	// * "EndpointLimitExceeded"
+	// * "Suspended"
+	// * "AuthorizationFailed"
+	// * "NoExportedServices"
+	// * "InfeasibleWeightBounds"
+	// * "ReconciliationPaused"
 	//
 	// Possible reasons for this condition to be Unknown are:
 	//
@@ -165,9 +413,103 @@ const (
 	// and cannot be configured on the Profile with more details in the message.
 	TrafficManagerBackendReasonInvalid TrafficManagerBackendConditionReason = "Invalid"
 
+	// TrafficManagerBackendReasonZeroTotalWeight is used with the "Accepted" condition when all the valid services
+	// behind the backend are exported with zero weight and the weights of the endpoints cannot be calculated.
+	TrafficManagerBackendReasonZeroTotalWeight TrafficManagerBackendConditionReason = "ZeroTotalWeight"
+
 	// TrafficManagerBackendReasonPending is used with the "Accepted" when creating or updating endpoint hits an internal error with
 	// more details in the message and the controller will keep retry.
 	TrafficManagerBackendReasonPending TrafficManagerBackendConditionReason = "Pending"
+
+	// TrafficManagerBackendReasonEndpointLimitExceeded is used with the "Accepted" condition when the number of endpoints
+	// exceeds the maximum number of endpoints allowed in the Azure Traffic Manager profile and some of the services
+	// exported from clusters are not exposed, with the dropped clusters in the message.
+	TrafficManagerBackendReasonEndpointLimitExceeded TrafficManagerBackendConditionReason = "EndpointLimitExceeded"
+
+	// TrafficManagerBackendReasonSuspended is used with the "Accepted" condition when the backend is suspended and the
+	// existing endpoints are left untouched.
+	TrafficManagerBackendReasonSuspended TrafficManagerBackendConditionReason = "Suspended"
+
+	// TrafficManagerBackendReasonAuthorizationFailed is used with the "Accepted" condition when the Azure identity of the
+	// controller does not have the permission to manage the Azure Traffic Manager endpoints, with the action and scope
+	// in the message.
+	TrafficManagerBackendReasonAuthorizationFailed TrafficManagerBackendConditionReason = "AuthorizationFailed"
+
+	// TrafficManagerBackendReasonNoExportedServices is used with the "Accepted" condition when no member cluster has
+	// exported the services behind the serviceImports for longer than the threshold of the controller, which usually
+	// means the serviceExports are never created or are invalid.
+	TrafficManagerBackendReasonNoExportedServices TrafficManagerBackendConditionReason = "NoExportedServices"
+
+	// TrafficManagerBackendReasonInfeasibleWeightBounds is used with the "Accepted" condition when the minimum and
+	// maximum weight percentages of the exported services cannot be satisfied at the same time, for example, the sum of
+	// the minimums exceeds 100%, and the existing endpoints are left untouched.
+	TrafficManagerBackendReasonInfeasibleWeightBounds TrafficManagerBackendConditionReason = "InfeasibleWeightBounds"
+
+	// TrafficManagerBackendReasonReconciliationPaused is used with the "Accepted" condition when the reconciliation of
+	// all the backends is paused by the kill switch of the controller and the existing endpoints are left untouched.
+	TrafficManagerBackendReasonReconciliationPaused TrafficManagerBackendConditionReason = "ReconciliationPaused"
+
+	// TrafficManagerBackendConditionProfileInSync condition indicates whether the monitor settings of the Azure Traffic
+	// Manager profile match the ones defined in the trafficManagerProfile.
+	// The condition is only reported when they do not match, for example, the Azure Traffic Manager profile is changed
+	// outside of the fleet.
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "ProfileDrift"
+	//
+	TrafficManagerBackendConditionProfileInSync TrafficManagerBackendConditionType = "ProfileInSync"
+
+	// TrafficManagerBackendReasonProfileDrift is used with the "ProfileInSync" condition when the monitor settings of the
+	// Azure Traffic Manager profile differ from the trafficManagerProfile, with the fields which differ in the message.
+	TrafficManagerBackendReasonProfileDrift TrafficManagerBackendConditionReason = "ProfileDrift"
+
+	// TrafficManagerBackendConditionMinimumHealthyEndpointsMet condition indicates whether the number of accepted
+	// endpoints whose monitor status is Online reaches the minHealthyEndpoints of the backend.
+	// The condition is only reported when the minHealthyEndpoints is set.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "MinimumHealthyEndpointsMet"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "InsufficientHealthyEndpoints"
+	//
+	TrafficManagerBackendConditionMinimumHealthyEndpointsMet TrafficManagerBackendConditionType = "MinimumHealthyEndpointsMet"
+
+	// TrafficManagerBackendReasonMinimumHealthyEndpointsMet is used with the "MinimumHealthyEndpointsMet" condition when
+	// the condition is True.
+	TrafficManagerBackendReasonMinimumHealthyEndpointsMet TrafficManagerBackendConditionReason = "MinimumHealthyEndpointsMet"
+
+	// TrafficManagerBackendReasonInsufficientHealthyEndpoints is used with the "MinimumHealthyEndpointsMet" condition
+	// when fewer accepted endpoints than the minHealthyEndpoints are Online.
+	TrafficManagerBackendReasonInsufficientHealthyEndpoints TrafficManagerBackendConditionReason = "InsufficientHealthyEndpoints"
+
+	// TrafficManagerBackendConditionReady condition summarizes whether the backend is fully reconciled, that is, the
+	// trafficManagerProfile is programmed, the serviceImports are valid and all the exported services are accepted as
+	// Azure Traffic Manager endpoints without any invalid endpoints, so that it can be used as a single gate.
+	//
+	// Possible reasons for this condition to be True are:
+	//
+	// * "Ready"
+	//
+	// Possible reasons for this condition to be False are:
+	//
+	// * "NotAccepted"
+	//
+	// Possible reasons for this condition to be Unknown are:
+	//
+	// * "Pending"
+	//
+	TrafficManagerBackendConditionReady TrafficManagerBackendConditionType = "Ready"
+
+	// TrafficManagerBackendReasonReady is used with the "Ready" condition when the condition is True.
+	TrafficManagerBackendReasonReady TrafficManagerBackendConditionReason = "Ready"
+
+	// TrafficManagerBackendReasonNotAccepted is used with the "Ready" condition when the "Accepted" condition is False,
+	// with the reason and message of the "Accepted" condition in the message.
+	TrafficManagerBackendReasonNotAccepted TrafficManagerBackendConditionReason = "NotAccepted"
 )
 
 //+kubebuilder:object:root=true
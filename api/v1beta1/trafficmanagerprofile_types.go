@@ -24,6 +24,7 @@ const (
 // TrafficManagerProfile is used to manage a simple Azure Traffic Manager Profile using cloud native way.
 // https://learn.microsoft.com/en-us/azure/traffic-manager/traffic-manager-overview
 // +kubebuilder:validation:XValidation:rule="size(self.metadata.name) < 64",message="metadata.name max length is 63"
+// +kubebuilder:validation:XValidation:rule="!has(self.spec.parentProfile) || self.spec.parentProfile.name != self.metadata.name",message="parentProfile cannot reference the profile itself"
 type TrafficManagerProfile struct {
 	metav1.TypeMeta `json:",inline"`
 	// +optional
@@ -38,7 +39,9 @@ type TrafficManagerProfile struct {
 }
 
 // TrafficManagerProfileSpec defines the desired state of TrafficManagerProfile.
-// For now, only the "Weighted" traffic routing method is supported.
+// The "Weighted", "Geographic", "Priority", "Performance", "Subnet" and "MultiValue" traffic routing methods are supported.
+// +kubebuilder:validation:XValidation:rule="has(oldSelf.subscriptionID) == has(self.subscriptionID)",message="subscriptionID is immutable"
+// +kubebuilder:validation:XValidation:rule="has(self.routingMethod) && self.routingMethod == 'MultiValue' ? has(self.maxReturn) : !has(self.maxReturn)",message="maxReturn is required when routingMethod is MultiValue and cannot be set otherwise"
 type TrafficManagerProfileSpec struct {
 	// The name of the resource group to contain the Azure Traffic Manager resource corresponding to this profile.
 	// When this profile is created, updated, or deleted, the corresponding traffic manager with the same name will be created, updated, or deleted
@@ -50,9 +53,156 @@ type TrafficManagerProfileSpec struct {
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="resourceGroup is immutable"
 	ResourceGroup string `json:"resourceGroup"`
 
+	// The ID of the Azure subscription to contain the Azure Traffic Manager resource corresponding to this profile.
+	// If not set, the subscription configured in the cloud config of the hub networking controller manager is used.
+	// The credential in the cloud config is used to access all the subscriptions, so it must be granted the permissions
+	// to manage the Traffic Manager resources in the specified resource group of this subscription.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="subscriptionID is immutable"
+	SubscriptionID string `json:"subscriptionID,omitempty"`
+
+	// The traffic routing method of the Traffic Manager profile.
+	// * "Weighted" distributes the traffic across the endpoints based on the weights.
+	// * "Geographic" routes the traffic to the endpoints based on the geographic location where the DNS query originates
+	//   from. The geographic regions of each endpoint are configured by the geo mapping of the exported services.
+	//   The same region cannot be mapped to more than one endpoint of the profile. The controller rejects the exported
+	//   services whose regions are exactly the same as the ones of other endpoints, while the overlapping of regions
+	//   which contain each other (for example, "GEO-EU" and "DE") is reported by the Azure Traffic Manager only.
+	// * "Priority" routes all the traffic to the healthy endpoint with the lowest priority value (active/passive
+	//   failover). The priority of each endpoint is configured by the priority of the exported services and must be
+	//   unique in the profile.
+	// * "Performance" routes the traffic to the endpoint with the lowest network latency from the DNS query origin.
+	//   The location of each endpoint is the Azure region of the member cluster, or the external target location of
+	//   the exported services with an external target.
+	// * "Subnet" routes the traffic to the endpoints based on the IP address ranges of the clients issuing the DNS
+	//   queries. The subnets of each endpoint are configured by the subnets of the exported services, or by the
+	//   clusterSubnets of the backend, and must not overlap with the subnets of the other endpoints of the profile.
+	// * "MultiValue" returns the IP addresses of multiple healthy endpoints, up to maxReturn, in the DNS responses.
+	//   Only the exported services with an external target IP address (IPv4 or IPv6) can be used as the endpoints.
+	// Reference link: https://learn.microsoft.com/en-us/azure/traffic-manager/traffic-manager-routing-methods
+	// +optional
+	// +kubebuilder:default="Weighted"
+	// +kubebuilder:validation:Enum=Weighted;Geographic;Priority;Performance;Subnet;MultiValue
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="routingMethod is immutable"
+	RoutingMethod TrafficManagerRoutingMethod `json:"routingMethod,omitempty"`
+
+	// The maximum number of endpoints returned in the DNS responses of the Traffic Manager profile.
+	// It is required when the routing method is "MultiValue" and cannot be set otherwise.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=8
+	MaxReturn *int64 `json:"maxReturn,omitempty"`
+
 	// The endpoint monitoring settings of the Traffic Manager profile.
 	// +optional
 	MonitorConfig *MonitorConfig `json:"monitorConfig,omitempty"`
+
+	// The DNS settings of the Traffic Manager profile.
+	// +optional
+	DNSConfig *DNSConfig `json:"dnsConfig,omitempty"`
+
+	// The Azure resource tags of the Traffic Manager profile, for example, used by the cost allocation.
+	// The tags removed from this field are removed from the Azure Traffic Manager profile as well, while the tags added
+	// to the Azure Traffic Manager profile outside of the fleet are preserved.
+	// The tag names starting with "networking.fleet.azure.com." are reserved for the fleet.
+	// Reference link: https://learn.microsoft.com/en-us/azure/azure-resource-manager/management/tag-resources
+	// +optional
+	// +kubebuilder:validation:MaxProperties=48
+	// +kubebuilder:validation:XValidation:rule="self.all(k, !k.lowerAscii().startsWith('networking.fleet.azure.com.'))",message="tag names starting with networking.fleet.azure.com. are reserved"
+	// +kubebuilder:validation:XValidation:rule="self.all(k, !k.contains(','))",message="tag names cannot contain ','"
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// The parent Traffic Manager profile in the same namespace, which routes the traffic to this profile via a nested
+	// endpoint for the hierarchical routing. For example, the parent profile uses the "Priority" routing method across
+	// the regions while each child profile uses the "Weighted" routing method across the clusters in the same region.
+	// The nested endpoint is registered once both this profile and the parent profile are programmed, and is removed
+	// from the parent profile when this field is removed or this profile is deleted.
+	// Reference link: https://learn.microsoft.com/en-us/azure/traffic-manager/traffic-manager-nested-profiles
+	// +optional
+	ParentProfile *TrafficManagerParentProfile `json:"parentProfile,omitempty"`
+
+	// The retention policy of the Azure Traffic Manager profile when this profile is deleted, for example, to recover
+	// from the accidental deletion.
+	// If set, the Azure Traffic Manager profile is disabled so that no traffic is routed to its endpoints, and is kept
+	// for the retention period before being deleted. This profile is not removed until the retention period elapses.
+	// If not set, the Azure Traffic Manager profile is deleted immediately.
+	// +optional
+	RetentionPolicy *TrafficManagerProfileRetentionPolicy `json:"retentionPolicy,omitempty"`
+}
+
+// TrafficManagerProfileRetentionPolicy defines how long the Azure Traffic Manager profile is kept after the profile is
+// deleted.
+type TrafficManagerProfileRetentionPolicy struct {
+	// The number of hours to keep the disabled Azure Traffic Manager profile after the profile is deleted.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=720
+	RetentionHours int64 `json:"retentionHours"`
+}
+
+// TrafficManagerParentProfile defines the parent Traffic Manager profile and the settings of the nested endpoint
+// registered in the parent profile.
+// Only the parent profiles using the "Weighted" or "Priority" routing method are supported.
+type TrafficManagerParentProfile struct {
+	// Name is the name of the parent trafficManagerProfile in the same namespace.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// The weight of the nested endpoint when the parent profile uses the "Weighted" routing method.
+	// If unspecified, defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	Weight *int64 `json:"weight,omitempty"`
+
+	// The priority of the nested endpoint, which is required when the parent profile uses the "Priority" routing method.
+	// The endpoint with the lowest value has the highest priority.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	Priority *int64 `json:"priority,omitempty"`
+
+	// The minimum number of the healthy endpoints in this profile for the nested endpoint to be considered as healthy
+	// by the parent profile.
+	// If unspecified, defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinChildEndpoints *int64 `json:"minChildEndpoints,omitempty"`
+
+	// The fallback of the minChildEndpoints when this profile has fewer enabled endpoints than the minChildEndpoints,
+	// for example, after a member cluster leaves the fleet.
+	// With "None", the minChildEndpoints is always used, so that the parent profile treats the nested endpoint as
+	// degraded until this profile has enough healthy endpoints again.
+	// With "EndpointCount", the minChildEndpoints is lowered to the number of the enabled endpoints in this profile, so
+	// that the nested endpoint is only treated as degraded when any of the remaining endpoints is unhealthy.
+	// If unspecified, defaults to "None".
+	// +optional
+	// +kubebuilder:validation:Enum=None;EndpointCount
+	MinChildEndpointsFallback TrafficManagerMinChildEndpointsFallback `json:"minChildEndpointsFallback,omitempty"`
+}
+
+// TrafficManagerMinChildEndpointsFallback defines the fallback of the minChildEndpoints of the nested endpoint.
+type TrafficManagerMinChildEndpointsFallback string
+
+const (
+	TrafficManagerMinChildEndpointsFallbackNone          TrafficManagerMinChildEndpointsFallback = "None"
+	TrafficManagerMinChildEndpointsFallbackEndpointCount TrafficManagerMinChildEndpointsFallback = "EndpointCount"
+)
+
+// DNSConfig defines the DNS settings of the Traffic Manager profile.
+// https://learn.microsoft.com/en-us/azure/traffic-manager/traffic-manager-performance-considerations
+type DNSConfig struct {
+	// The DNS Time-To-Live (TTL), in seconds. This informs the local DNS resolvers and DNS clients how long to cache DNS
+	// responses provided by this Traffic Manager profile.
+	// A low TTL (for example, less than 30 seconds) speeds up the failover while increasing the number of DNS queries
+	// and the cost of the Azure Traffic Manager.
+	// If unspecified, defaults to 60 seconds.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=2147483647
+	TTLInSeconds *int64 `json:"ttlInSeconds,omitempty"`
 }
 
 // MonitorConfigCustomHeader defines a custom header for endpoint monitoring.
@@ -66,10 +216,26 @@ type MonitorConfigCustomHeader struct {
 	Value string `json:"value"`
 }
 
+// MonitorConfigExpectedStatusCodeRange defines a range of HTTP status codes which are considered as healthy when probing
+// the endpoints.
+// +kubebuilder:validation:XValidation:rule="self.min <= self.max",message="min must be less than or equal to max"
+type MonitorConfigExpectedStatusCodeRange struct {
+	// Min is the inclusive lower bound of the status code range.
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Maximum=999
+	Min int32 `json:"min"`
+
+	// Max is the inclusive upper bound of the status code range.
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Maximum=999
+	Max int32 `json:"max"`
+}
+
 // MonitorConfig defines the endpoint monitoring settings of the Traffic Manager profile.
 // https://learn.microsoft.com/en-us/azure/traffic-manager/traffic-manager-monitoring
 // +kubebuilder:validation:XValidation:rule="has(self.intervalInSeconds) && self.intervalInSeconds == 30 ? (!has(self.timeoutInSeconds) || (self.timeoutInSeconds >= 5 && self.timeoutInSeconds <= 10)) : true",message="timeoutInSeconds must be between 5 and 10 when intervalInSeconds is 30"
 // +kubebuilder:validation:XValidation:rule="has(self.intervalInSeconds) && self.intervalInSeconds == 10 ? (!has(self.timeoutInSeconds) || (self.timeoutInSeconds >= 5 && self.timeoutInSeconds <= 9)) : true",message="timeoutInSeconds must be between 5 and 9 when intervalInSeconds is 10"
+// +kubebuilder:validation:XValidation:rule="has(self.protocol) && self.protocol == 'TCP' ? !has(self.expectedStatusCodeRanges) : true",message="expectedStatusCodeRanges is not supported when protocol is TCP"
 type MonitorConfig struct {
 	// The monitor interval for endpoints in this profile. This is the interval at which Traffic Manager will check the health
 	// of each endpoint in this profile.
@@ -102,6 +268,12 @@ type MonitorConfig struct {
 	// +kubebuilder:validation:MaxItems=8
 	CustomHeaders []MonitorConfigCustomHeader `json:"customHeaders,omitempty"`
 
+	// The list of HTTP status code ranges which are considered as healthy when probing endpoints with HTTP or HTTPS.
+	// If not specified, only the status code 200 is considered as healthy.
+	// +optional
+	// +kubebuilder:validation:MaxItems=8
+	ExpectedStatusCodeRanges []MonitorConfigExpectedStatusCodeRange `json:"expectedStatusCodeRanges,omitempty"`
+
 	// The monitor timeout for endpoints in this profile. This is the time that Traffic Manager allows endpoints in this profile
 	// to response to the health check.
 	// +optional
@@ -122,6 +294,18 @@ type MonitorConfig struct {
 	ToleratedNumberOfFailures *int64 `json:"toleratedNumberOfFailures,omitempty"`
 }
 
+// TrafficManagerRoutingMethod defines the traffic routing method of the Traffic Manager profile.
+type TrafficManagerRoutingMethod string
+
+const (
+	TrafficManagerRoutingMethodWeighted    TrafficManagerRoutingMethod = "Weighted"
+	TrafficManagerRoutingMethodGeographic  TrafficManagerRoutingMethod = "Geographic"
+	TrafficManagerRoutingMethodPriority    TrafficManagerRoutingMethod = "Priority"
+	TrafficManagerRoutingMethodPerformance TrafficManagerRoutingMethod = "Performance"
+	TrafficManagerRoutingMethodSubnet      TrafficManagerRoutingMethod = "Subnet"
+	TrafficManagerRoutingMethodMultiValue  TrafficManagerRoutingMethod = "MultiValue"
+)
+
 // TrafficManagerMonitorProtocol defines the protocol used to probe for endpoint health.
 type TrafficManagerMonitorProtocol string
 
@@ -143,6 +327,27 @@ type TrafficManagerProfileStatus struct {
 	// Ex - /subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Network/trafficManagerProfiles/{resourceName}
 	ResourceID string `json:"resourceID,omitempty"`
 
+	// NestedEndpointResourceID is the fully qualified Azure resource Id of the nested endpoint which is registered in
+	// the Azure Traffic Manager profile of the parent profile.
+	// Ex - /subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Network/trafficManagerProfiles/{parentProfileName}/nestedEndpoints/{endpointName}
+	// +optional
+	NestedEndpointResourceID string `json:"nestedEndpointResourceID,omitempty"`
+
+	// MonitorStatus is the profile-level monitoring status reported by the Azure Traffic Manager, which summarizes the
+	// health of all the endpoints in the profile.
+	// +optional
+	MonitorStatus TrafficManagerProfileMonitorStatus `json:"monitorStatus,omitempty"`
+
+	// EndpointSummary summarizes the Azure Traffic Manager endpoints created for the TrafficManagerBackends in the
+	// Azure Traffic Manager profile, which is refreshed whenever the profile is reconciled.
+	// +optional
+	EndpointSummary *TrafficManagerProfileEndpointSummary `json:"endpointSummary,omitempty"`
+
+	// ScheduledDeletionTime is the time when the Azure Traffic Manager profile is deleted, which is set when this profile
+	// is deleted with a retention policy.
+	// +optional
+	ScheduledDeletionTime *metav1.Time `json:"scheduledDeletionTime,omitempty"`
+
 	// Current profile status.
 	// +optional
 	// +patchMergeKey=type
@@ -152,6 +357,27 @@ type TrafficManagerProfileStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+// TrafficManagerProfileEndpointSummary summarizes the Azure Traffic Manager endpoints created by the fleet controllers
+// for all the TrafficManagerBackends referencing the Traffic Manager profile.
+type TrafficManagerProfileEndpointSummary struct {
+	// Count is the number of the endpoints.
+	Count int64 `json:"count"`
+
+	// TotalWeight is the sum of the weights of the endpoints.
+	TotalWeight int64 `json:"totalWeight"`
+}
+
+// TrafficManagerProfileMonitorStatus defines the profile-level monitoring status of the Traffic Manager profile.
+type TrafficManagerProfileMonitorStatus string
+
+const (
+	TrafficManagerProfileMonitorStatusCheckingEndpoints TrafficManagerProfileMonitorStatus = "CheckingEndpoints"
+	TrafficManagerProfileMonitorStatusDegraded          TrafficManagerProfileMonitorStatus = "Degraded"
+	TrafficManagerProfileMonitorStatusDisabled          TrafficManagerProfileMonitorStatus = "Disabled"
+	TrafficManagerProfileMonitorStatusInactive          TrafficManagerProfileMonitorStatus = "Inactive"
+	TrafficManagerProfileMonitorStatusOnline            TrafficManagerProfileMonitorStatus = "Online"
+)
+
 // TrafficManagerProfileConditionType is a type of condition associated with a
 // Traffic Manager Profile. This type should be used within the TrafficManagerProfileStatus.Conditions field.
 type TrafficManagerProfileConditionType string
@@ -171,6 +397,7 @@ const (
 	// Possible reasons for this condition to be True are:
 	//
 	// * "Programmed"
+	// * "Degraded"
 	//
 	// Possible reasons for this condition to be False are:
 	//
@@ -186,6 +413,10 @@ const (
 	// TrafficManagerProfileReasonProgrammed is used with the "Programmed" condition when the condition is true.
 	TrafficManagerProfileReasonProgrammed TrafficManagerProfileConditionReason = "Programmed"
 
+	// TrafficManagerProfileReasonDegraded is used with the "Programmed" condition when the condition is true but the
+	// Azure Traffic Manager reports the profile monitor status as degraded, that is, one or more endpoints are unhealthy.
+	TrafficManagerProfileReasonDegraded TrafficManagerProfileConditionReason = "Degraded"
+
 	// TrafficManagerProfileReasonInvalid is used with the "Programmed" when the profile is syntactically or semantically invalid.
 	TrafficManagerProfileReasonInvalid TrafficManagerProfileConditionReason = "Invalid"
 
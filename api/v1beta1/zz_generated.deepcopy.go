@@ -10,6 +10,7 @@ Licensed under the MIT license.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -29,6 +30,61 @@ func (in *ClusterStatus) DeepCopy() *ClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSubnets) DeepCopyInto(out *ClusterSubnets) {
+	*out = *in
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSubnets.
+func (in *ClusterSubnets) DeepCopy() *ClusterSubnets {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSubnets)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWeight) DeepCopyInto(out *ClusterWeight) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWeight.
+func (in *ClusterWeight) DeepCopy() *ClusterWeight {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWeight)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNSConfig) DeepCopyInto(out *DNSConfig) {
+	*out = *in
+	if in.TTLInSeconds != nil {
+		in, out := &in.TTLInSeconds, &out.TTLInSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSConfig.
+func (in *DNSConfig) DeepCopy() *DNSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FromCluster) DeepCopyInto(out *FromCluster) {
 	*out = *in
@@ -50,6 +106,21 @@ func (in *FromCluster) DeepCopy() *FromCluster {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InvalidEndpointStatus) DeepCopyInto(out *InvalidEndpointStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InvalidEndpointStatus.
+func (in *InvalidEndpointStatus) DeepCopy() *InvalidEndpointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InvalidEndpointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MonitorConfig) DeepCopyInto(out *MonitorConfig) {
 	*out = *in
@@ -78,6 +149,11 @@ func (in *MonitorConfig) DeepCopyInto(out *MonitorConfig) {
 		*out = make([]MonitorConfigCustomHeader, len(*in))
 		copy(*out, *in)
 	}
+	if in.ExpectedStatusCodeRanges != nil {
+		in, out := &in.ExpectedStatusCodeRanges, &out.ExpectedStatusCodeRanges
+		*out = make([]MonitorConfigExpectedStatusCodeRange, len(*in))
+		copy(*out, *in)
+	}
 	if in.TimeoutInSeconds != nil {
 		in, out := &in.TimeoutInSeconds, &out.TimeoutInSeconds
 		*out = new(int64)
@@ -115,6 +191,21 @@ func (in *MonitorConfigCustomHeader) DeepCopy() *MonitorConfigCustomHeader {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorConfigExpectedStatusCodeRange) DeepCopyInto(out *MonitorConfigExpectedStatusCodeRange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorConfigExpectedStatusCodeRange.
+func (in *MonitorConfigExpectedStatusCodeRange) DeepCopy() *MonitorConfigExpectedStatusCodeRange {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorConfigExpectedStatusCodeRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceExport) DeepCopyInto(out *ServiceExport) {
 	*out = *in
@@ -222,6 +313,26 @@ func (in *TrafficManagerBackend) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficManagerBackendClusterSelector) DeepCopyInto(out *TrafficManagerBackendClusterSelector) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficManagerBackendClusterSelector.
+func (in *TrafficManagerBackendClusterSelector) DeepCopy() *TrafficManagerBackendClusterSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficManagerBackendClusterSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficManagerBackendList) DeepCopyInto(out *TrafficManagerBackendList) {
 	*out = *in
@@ -257,6 +368,28 @@ func (in *TrafficManagerBackendList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficManagerBackendRef) DeepCopyInto(out *TrafficManagerBackendRef) {
 	*out = *in
+	if in.AdditionalNames != nil {
+		in, out := &in.AdditionalNames, &out.AdditionalNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterSelector != nil {
+		in, out := &in.ClusterSelector, &out.ClusterSelector
+		*out = new(TrafficManagerBackendClusterSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterWeightOverrides != nil {
+		in, out := &in.ClusterWeightOverrides, &out.ClusterWeightOverrides
+		*out = make([]ClusterWeight, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterSubnets != nil {
+		in, out := &in.ClusterSubnets, &out.ClusterSubnets
+		*out = make([]ClusterSubnets, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficManagerBackendRef.
@@ -273,12 +406,27 @@ func (in *TrafficManagerBackendRef) DeepCopy() *TrafficManagerBackendRef {
 func (in *TrafficManagerBackendSpec) DeepCopyInto(out *TrafficManagerBackendSpec) {
 	*out = *in
 	out.Profile = in.Profile
-	out.Backend = in.Backend
+	in.Backend.DeepCopyInto(&out.Backend)
 	if in.Weight != nil {
 		in, out := &in.Weight, &out.Weight
 		*out = new(int64)
 		**out = **in
 	}
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IPFamily != nil {
+		in, out := &in.IPFamily, &out.IPFamily
+		*out = new(corev1.IPFamily)
+		**out = **in
+	}
+	if in.MinHealthyEndpoints != nil {
+		in, out := &in.MinHealthyEndpoints, &out.MinHealthyEndpoints
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficManagerBackendSpec.
@@ -301,6 +449,24 @@ func (in *TrafficManagerBackendStatus) DeepCopyInto(out *TrafficManagerBackendSt
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.InvalidEndpoints != nil {
+		in, out := &in.InvalidEndpoints, &out.InvalidEndpoints
+		*out = make([]InvalidEndpointStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.EndpointsCreated != nil {
+		in, out := &in.EndpointsCreated, &out.EndpointsCreated
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DrainStartTime != nil {
+		in, out := &in.DrainStartTime, &out.DrainStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -328,11 +494,36 @@ func (in *TrafficManagerEndpointStatus) DeepCopyInto(out *TrafficManagerEndpoint
 		*out = new(int64)
 		**out = **in
 	}
+	if in.Percentage != nil {
+		in, out := &in.Percentage, &out.Percentage
+		*out = new(int64)
+		**out = **in
+	}
 	if in.Target != nil {
 		in, out := &in.Target, &out.Target
 		*out = new(string)
 		**out = **in
 	}
+	if in.GeoMapping != nil {
+		in, out := &in.GeoMapping, &out.GeoMapping
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MonitorStatus != nil {
+		in, out := &in.MonitorStatus, &out.MonitorStatus
+		*out = new(TrafficManagerEndpointMonitorStatus)
+		**out = **in
+	}
 	if in.From != nil {
 		in, out := &in.From, &out.From
 		*out = new(FromCluster)
@@ -350,6 +541,36 @@ func (in *TrafficManagerEndpointStatus) DeepCopy() *TrafficManagerEndpointStatus
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficManagerParentProfile) DeepCopyInto(out *TrafficManagerParentProfile) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinChildEndpoints != nil {
+		in, out := &in.MinChildEndpoints, &out.MinChildEndpoints
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficManagerParentProfile.
+func (in *TrafficManagerParentProfile) DeepCopy() *TrafficManagerParentProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficManagerParentProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficManagerProfile) DeepCopyInto(out *TrafficManagerProfile) {
 	*out = *in
@@ -377,6 +598,21 @@ func (in *TrafficManagerProfile) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficManagerProfileEndpointSummary) DeepCopyInto(out *TrafficManagerProfileEndpointSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficManagerProfileEndpointSummary.
+func (in *TrafficManagerProfileEndpointSummary) DeepCopy() *TrafficManagerProfileEndpointSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficManagerProfileEndpointSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficManagerProfileList) DeepCopyInto(out *TrafficManagerProfileList) {
 	*out = *in
@@ -424,14 +660,56 @@ func (in *TrafficManagerProfileRef) DeepCopy() *TrafficManagerProfileRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficManagerProfileRetentionPolicy) DeepCopyInto(out *TrafficManagerProfileRetentionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficManagerProfileRetentionPolicy.
+func (in *TrafficManagerProfileRetentionPolicy) DeepCopy() *TrafficManagerProfileRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficManagerProfileRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficManagerProfileSpec) DeepCopyInto(out *TrafficManagerProfileSpec) {
 	*out = *in
+	if in.MaxReturn != nil {
+		in, out := &in.MaxReturn, &out.MaxReturn
+		*out = new(int64)
+		**out = **in
+	}
 	if in.MonitorConfig != nil {
 		in, out := &in.MonitorConfig, &out.MonitorConfig
 		*out = new(MonitorConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(DNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ParentProfile != nil {
+		in, out := &in.ParentProfile, &out.ParentProfile
+		*out = new(TrafficManagerParentProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetentionPolicy != nil {
+		in, out := &in.RetentionPolicy, &out.RetentionPolicy
+		*out = new(TrafficManagerProfileRetentionPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficManagerProfileSpec.
@@ -452,6 +730,15 @@ func (in *TrafficManagerProfileStatus) DeepCopyInto(out *TrafficManagerProfileSt
 		*out = new(string)
 		**out = **in
 	}
+	if in.EndpointSummary != nil {
+		in, out := &in.EndpointSummary, &out.EndpointSummary
+		*out = new(TrafficManagerProfileEndpointSummary)
+		**out = **in
+	}
+	if in.ScheduledDeletionTime != nil {
+		in, out := &in.ScheduledDeletionTime, &out.ScheduledDeletionTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -0,0 +1,97 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Binary atm-endpoint-lister lists the Azure Traffic Manager endpoints created by the fleet controller under an Azure
+// Traffic Manager profile, and decodes their names into the owner trafficManagerBackend UID, service import name and
+// cluster, so that the Azure resources can be correlated to the custom resources in the hub cluster.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"go.goms.io/fleet-networking/pkg/controllers/hub/trafficmanagerbackend"
+)
+
+var (
+	subscriptionID = flag.String("subscription-id", "", "The Azure subscription ID of the Azure Traffic Manager profile (required)")
+	resourceGroup  = flag.String("resource-group", "", "The resource group of the Azure Traffic Manager profile (required)")
+	profileName    = flag.String("profile-name", "", "The name of the Azure Traffic Manager profile (required)")
+	namePrefix     = flag.String("endpoint-name-prefix", trafficmanagerbackend.DefaultAzureResourceEndpointNamePrefix,
+		"The prefix of the Azure Traffic Manager endpoint names created by the fleet controller")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Parse()
+	defer klog.Flush()
+
+	if *subscriptionID == "" || *resourceGroup == "" || *profileName == "" {
+		klog.ErrorS(nil, "Missing required flags", "subscriptionID", *subscriptionID, "resourceGroup", *resourceGroup, "profileName", *profileName)
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err := trafficmanagerbackend.ValidateAzureResourceEndpointNamePrefix(*namePrefix); err != nil {
+		klog.ErrorS(err, "Invalid endpoint name prefix")
+		os.Exit(1)
+	}
+
+	// DefaultAzureCredential picks up the credential of the operator, e.g. from the Azure CLI.
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		klog.ErrorS(err, "Failed to obtain the default Azure credential")
+		os.Exit(1)
+	}
+	profilesClient, err := armtrafficmanager.NewProfilesClient(*subscriptionID, cred, nil)
+	if err != nil {
+		klog.ErrorS(err, "Failed to create Azure trafficManager profiles client")
+		os.Exit(1)
+	}
+
+	res, err := profilesClient.Get(context.Background(), *resourceGroup, *profileName, nil)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get the Azure Traffic Manager profile", "resourceGroup", *resourceGroup, "profileName", *profileName)
+		os.Exit(1)
+	}
+	if err := printFleetManagedEndpoints(os.Stdout, *namePrefix, &res.Profile); err != nil {
+		klog.ErrorS(err, "Failed to print the Azure Traffic Manager endpoints")
+		os.Exit(1)
+	}
+}
+
+// printFleetManagedEndpoints writes a table of the endpoints created by the fleet controller with the endpoint name
+// prefix under the profile.
+// The endpoints which do not follow the naming convention of the fleet controller with the prefix are skipped.
+func printFleetManagedEndpoints(w io.Writer, namePrefix string, profile *armtrafficmanager.Profile) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENDPOINT\tBACKEND UID\tSERVICE IMPORT\tCLUSTER\tTARGET\tSTATUS")
+	if profile.Properties != nil {
+		for _, endpoint := range profile.Properties.Endpoints {
+			if endpoint == nil || endpoint.Name == nil {
+				continue
+			}
+			backendUID, serviceImportName, clusterID, ok := trafficmanagerbackend.ParseAzureTrafficManagerEndpointName(namePrefix, *endpoint.Name)
+			if !ok {
+				continue
+			}
+			var target, status string
+			if endpoint.Properties != nil {
+				target = ptr.Deref(endpoint.Properties.TargetResourceID, ptr.Deref(endpoint.Properties.Target, ""))
+				status = string(ptr.Deref(endpoint.Properties.EndpointStatus, ""))
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", *endpoint.Name, backendUID, serviceImportName, clusterID, target, status)
+		}
+	}
+	return tw.Flush()
+}
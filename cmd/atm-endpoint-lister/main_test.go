@@ -0,0 +1,69 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+
+	"go.goms.io/fleet-networking/pkg/controllers/hub/trafficmanagerbackend"
+)
+
+func TestPrintFleetManagedEndpoints(t *testing.T) {
+	profile := &armtrafficmanager.Profile{
+		Properties: &armtrafficmanager.ProfileProperties{
+			Endpoints: []*armtrafficmanager.Endpoint{
+				{
+					Name: ptr.To("fleet-backend-uid#test-import#member-1"),
+					Properties: &armtrafficmanager.EndpointProperties{
+						TargetResourceID: ptr.To("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/ip-1"),
+						EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					},
+				},
+				{
+					Name: ptr.To("fleet-backend-uid#test-import#member-2"),
+					Properties: &armtrafficmanager.EndpointProperties{
+						Target:         ptr.To("example.com"),
+						EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusDisabled),
+					},
+				},
+				{
+					Name: ptr.To("fleet-nested-profile-uid"),
+				},
+				{
+					Name: ptr.To("staging-backend-uid#test-import#member-1"), // created by another fleet controller
+				},
+				{
+					Name: ptr.To("user-endpoint"),
+				},
+				nil,
+			},
+		},
+	}
+	var buf bytes.Buffer
+	if err := printFleetManagedEndpoints(&buf, trafficmanagerbackend.DefaultAzureResourceEndpointNamePrefix, profile); err != nil {
+		t.Fatalf("printFleetManagedEndpoints() got error %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var got [][]string
+	for _, line := range lines {
+		got = append(got, strings.Fields(line))
+	}
+	want := [][]string{
+		{"ENDPOINT", "BACKEND", "UID", "SERVICE", "IMPORT", "CLUSTER", "TARGET", "STATUS"},
+		{"fleet-backend-uid#test-import#member-1", "backend-uid", "test-import", "member-1", "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/ip-1", "Enabled"},
+		{"fleet-backend-uid#test-import#member-2", "backend-uid", "test-import", "member-2", "example.com", "Disabled"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("printFleetManagedEndpoints() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -8,16 +8,22 @@ Licensed under the MIT license.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	azpolicy "github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/discovery"
@@ -27,6 +33,8 @@ import (
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient/policy/ratelimit"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -39,6 +47,8 @@ import (
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/azureclient"
+	"go.goms.io/fleet-networking/pkg/common/ratelimiter"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/endpointsliceexport"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/internalserviceexport"
 	"go.goms.io/fleet-networking/pkg/controllers/hub/internalserviceimport"
@@ -68,7 +78,109 @@ var (
 
 	enableTrafficManagerFeature = flag.Bool("enable-traffic-manager-feature", true, "If set, the traffic manager feature will be enabled.")
 
+	endpointMonitorResyncInterval = flag.Duration("endpoint-monitor-resync-interval", 5*time.Minute,
+		"The interval for the trafficmanagerbackend controller to resync the Azure Traffic Manager endpoints and refresh "+
+			"their monitor status. Setting it to 0 disables the periodic resync.")
+
+	maxConcurrentEndpointDeletes = flag.Int("max-concurrent-endpoint-deletes", trafficmanagerbackend.DefaultMaxConcurrentEndpointDeletes,
+		"The maximum number of Azure Traffic Manager endpoints the trafficmanagerbackend controller deletes concurrently when cleaning up a backend.")
+
+	maxEndpointsPerProfile = flag.Int("max-endpoints-per-profile", trafficmanagerbackend.DefaultMaxEndpointsPerProfile,
+		"The maximum number of endpoints the trafficmanagerbackend controller creates in an Azure Traffic Manager profile.")
+
+	noExportedServicesThreshold = flag.Duration("no-exported-services-threshold", trafficmanagerbackend.DefaultNoExportedServicesThreshold,
+		"The duration the serviceImports behind a trafficManagerBackend can have no exported services before its Accepted "+
+			"condition becomes False with the NoExportedServices reason.")
+
+	azureAPIErrorEventInterval = flag.Duration("azure-api-error-event-interval", trafficmanagerbackend.DefaultAzureAPIErrorEventInterval,
+		"The minimum interval between two AzureAPIError events of a trafficmanagerbackend caused by the same category of "+
+			"errors, for example, throttling, while every error is still logged. The events are not limited when it's negative.")
+
+	azureEndpointNamePrefix = flag.String("azure-endpoint-name-prefix", trafficmanagerbackend.DefaultAzureResourceEndpointNamePrefix,
+		"The prefix of the Azure Traffic Manager endpoint names created by the trafficmanagerbackend controller, which "+
+			"must be 1-31 lowercase letters or digits followed by a hyphen. Fleets sharing the same Azure Traffic Manager "+
+			"profiles must use different prefixes.")
+
+	enableOrphanEndpointGC = flag.Bool("enable-orphan-endpoint-gc", false,
+		"If set, the Azure Traffic Manager endpoints whose trafficmanagerbackends no longer exist will be deleted periodically.")
+
+	orphanEndpointGCInterval = flag.Duration("orphan-endpoint-gc-interval", trafficmanagerbackend.DefaultOrphanEndpointGCInterval,
+		"The interval between two garbage collection passes of the orphaned Azure Traffic Manager endpoints.")
+
+	orphanAzureResourcesOnDelete = flag.Bool("orphan-azure-resources-on-delete", false,
+		"If set, the Azure Traffic Manager endpoints are left behind when the trafficmanagerbackends are deleted, for "+
+			"example, when the controller is uninstalled for an upgrade, and can be adopted by the trafficmanagerbackends "+
+			"created later. It cannot be used together with the --enable-orphan-endpoint-gc flag.")
+
+	pauseConfigMapName = flag.String("pause-configmap-name", "",
+		"The name of the configmap whose \"paused\" key pauses the reconciliation of all the trafficmanagerbackends "+
+			"when set to \"true\", leaving the Azure Traffic Manager endpoints untouched. The kill switch is disabled when empty.")
+
+	pauseConfigMapNamespace = flag.String("pause-configmap-namespace", "fleet-system",
+		"The namespace of the configmap set by the --pause-configmap-name flag.")
+
+	publicIPSubscriptions = flag.String("public-ip-subscriptions", "",
+		"The comma-separated IDs of the subscriptions whose public IP addresses can be accessed by the Azure identity. "+
+			"If set, the exported services whose public IP addresses are in other subscriptions cannot be exposed as the "+
+			"Azure Traffic Manager endpoints.")
+
+	azureAPIQPS = flag.Float64("azure-api-qps", 10,
+		"The number of Azure Resource Manager write requests per second allowed per subscription, shared by all the "+
+			"traffic manager controllers. Setting it to 0 disables the rate limit.")
+
+	azureAPIBurst = flag.Int("azure-api-burst", 200,
+		"The maximum burst of the Azure Resource Manager write requests per subscription.")
+
+	trafficManagerRetryMode = flag.String("trafficmanager-retry-mode", string(ratelimiter.ModeBoth),
+		"How the traffic manager controllers delay the requests whose reconciliation fails: \"per-item\" backs off each "+
+			"request exponentially, \"overall\" limits the rate of the retries across all the requests, and \"both\" uses "+
+			"the longer delay of the two.")
+
+	trafficManagerRetryBaseDelay = flag.Duration("trafficmanager-retry-base-delay", ratelimiter.DefaultBaseDelay,
+		"The delay of the first retry of a failed request of the traffic manager controllers, which is doubled on each "+
+			"consecutive failure of the request.")
+
+	trafficManagerRetryMaxDelay = flag.Duration("trafficmanager-retry-max-delay", ratelimiter.DefaultMaxDelay,
+		"The maximum delay of the retries of a failed request of the traffic manager controllers.")
+
+	trafficManagerRetryQPS = flag.Float64("trafficmanager-retry-qps", ratelimiter.DefaultQPS,
+		"The number of retries per second allowed across all the failed requests of each traffic manager controller.")
+
+	trafficManagerRetryBurst = flag.Int("trafficmanager-retry-burst", ratelimiter.DefaultBurst,
+		"The maximum burst of the retries across all the failed requests of each traffic manager controller.")
+
+	enableTrafficManagerBackendWebhook = flag.Bool("enable-trafficmanagerbackend-webhook", false,
+		"If set, the validating webhook rejecting the trafficmanagerbackends which expose the same serviceimport in the "+
+			"same trafficmanagerprofile will be served. The serving certificates and the webhook configuration must be provisioned separately.")
+
+	enableTrafficManagerProfileWebhook = flag.Bool("enable-trafficmanagerprofile-webhook", false,
+		"If set, the mutating webhook setting the default values of the trafficmanagerprofiles, for example, the monitor config, "+
+			"will be served. The serving certificates and the webhook configuration must be provisioned separately.")
+
+	azureReadinessCheckInterval = flag.Duration("azure-readiness-check-interval", azureclient.DefaultReadinessCheckInterval,
+		"The interval between two Azure connectivity probes of the readiness check when the traffic manager feature is enabled. "+
+			"Each probe lists the Azure Traffic Manager profiles in the resource group of the cloud config.")
+	azureReadinessStalenessThreshold = flag.Duration("azure-readiness-staleness-threshold", azureclient.DefaultReadinessStalenessThreshold,
+		"The duration since the last successful Azure connectivity probe after which the readiness check fails. "+
+			"If set to 0 or no resource group is configured in the cloud config, the Azure connectivity is not checked.")
+
+	azureCloud = flag.String("azure-cloud", "",
+		"The Azure cloud the Azure Traffic Manager clients target, one of \"AzurePublic\", \"AzureUSGovernment\" or "+
+			"\"AzureChina\". If not set, the cloud of the cloud config is used.")
+
 	cloudConfigFile = flag.String("cloud-config", "/etc/kubernetes/provider/azure.json", "The path to the cloud config file which will be used to access the Azure resource.")
+
+	workloadIdentityClientID = flag.String("workload-identity-client-id", "",
+		"The client ID of the federated workload identity used to access the Azure Traffic Manager. If set, the "+
+			"credential of the cloud config is not used, and the controller fails to start when the identity cannot "+
+			"acquire a token.")
+
+	workloadIdentityTenantID = flag.String("workload-identity-tenant-id", "",
+		"The tenant ID of the federated workload identity set by the --workload-identity-client-id flag.")
+
+	workloadIdentityTokenFile = flag.String("workload-identity-token-file", azureclient.DefaultWorkloadIdentityTokenFilePath,
+		"The path of the projected service account token of the federated workload identity set by the "+
+			"--workload-identity-client-id flag.")
 )
 
 var (
@@ -109,9 +221,23 @@ func main() {
 	// Set up controller-runtime logger
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
+	pauseConfigMap := types.NamespacedName{Namespace: *pauseConfigMapNamespace, Name: *pauseConfigMapName}
+	cacheOptions := cache.Options{}
+	if pauseConfigMap.Name != "" {
+		klog.V(1).InfoS("TrafficManagerBackend reconciliation can be paused by the configMap", "configMap", klog.KRef(pauseConfigMap.Namespace, pauseConfigMap.Name))
+		// Only cache the pause configMap instead of all the configMaps in the hub cluster.
+		cacheOptions.ByObject = map[client.Object]cache.ByObject{
+			&corev1.ConfigMap{}: {
+				Namespaces: map[string]cache.Config{pauseConfigMap.Namespace: {}},
+				Field:      fields.OneTermEqualSelector("metadata.name", pauseConfigMap.Name),
+			},
+		}
+	}
+
 	hubConfig := ctrl.GetConfigOrDie()
 	mgr, err := ctrl.NewManager(hubConfig, ctrl.Options{
 		Scheme: scheme,
+		Cache:  cacheOptions,
 		Metrics: metricsserver.Options{
 			BindAddress: *metricsAddr,
 		},
@@ -199,6 +325,24 @@ func main() {
 			}
 		}
 
+		azureCloudConfiguration, err := azureclient.CloudConfiguration(*azureCloud)
+		if err != nil {
+			klog.ErrorS(err, "Invalid Azure cloud", "azureCloud", *azureCloud)
+			exitWithErrorFunc()
+		}
+		if err := trafficmanagerbackend.ValidateAzureResourceEndpointNamePrefix(*azureEndpointNamePrefix); err != nil {
+			klog.ErrorS(err, "Invalid traffic manager flags", "azureEndpointNamePrefix", *azureEndpointNamePrefix)
+			exitWithErrorFunc()
+		}
+		if *orphanAzureResourcesOnDelete && *enableOrphanEndpointGC {
+			// The garbage collector would delete the orphaned endpoints before they are adopted again.
+			klog.ErrorS(fmt.Errorf("--orphan-azure-resources-on-delete cannot be used together with --enable-orphan-endpoint-gc"), "Invalid traffic manager flags")
+			exitWithErrorFunc()
+		}
+		if *orphanAzureResourcesOnDelete {
+			klog.V(1).InfoS("The Azure Traffic Manager endpoints will be orphaned when the trafficManagerBackends are deleted")
+		}
+
 		klog.V(1).InfoS("Traffic manager feature is enabled, loading cloud config and creating azure clients", "cloudConfigFile", *cloudConfigFile)
 		cloudConfig, err := azure.NewCloudConfigFromFile(*cloudConfigFile)
 		if err != nil {
@@ -208,16 +352,50 @@ func main() {
 		cloudConfig.SetUserAgent("fleet-hub-net-controller-manager")
 		klog.V(1).InfoS("Cloud config loaded", "cloudConfig", cloudConfig)
 
-		profilesClient, endpointsClient, err := initAzureTrafficManagerClients(cloudConfig)
+		azureClientFactory, err := initAzureTrafficManagerClientFactory(ctx, cloudConfig, azureCloudConfiguration)
 		if err != nil {
 			klog.ErrorS(err, "Unable to create Azure Traffic Manager clients")
 			exitWithErrorFunc()
 		}
+
+		if *azureReadinessStalenessThreshold > 0 && cloudConfig.ResourceGroup != "" {
+			klog.V(1).InfoS("Start to setup the Azure connectivity readiness check", "resourceGroup", cloudConfig.ResourceGroup, "stalenessThreshold", *azureReadinessStalenessThreshold)
+			readinessChecker := &azureclient.ReadinessChecker{
+				ClientFactory:      azureClientFactory,
+				ResourceGroup:      cloudConfig.ResourceGroup,
+				Interval:           *azureReadinessCheckInterval,
+				StalenessThreshold: *azureReadinessStalenessThreshold,
+			}
+			if err := mgr.Add(readinessChecker); err != nil {
+				klog.ErrorS(err, "Unable to add the Azure connectivity readiness checker")
+				exitWithErrorFunc()
+			}
+			if err := mgr.AddReadyzCheck("azure", readinessChecker.Check); err != nil {
+				klog.ErrorS(err, "Unable to set up the Azure connectivity ready check")
+				exitWithErrorFunc()
+			}
+		}
+
+		retryOptions := ratelimiter.Options{
+			Mode:      ratelimiter.Mode(*trafficManagerRetryMode),
+			BaseDelay: *trafficManagerRetryBaseDelay,
+			MaxDelay:  *trafficManagerRetryMaxDelay,
+			QPS:       *trafficManagerRetryQPS,
+			Burst:     *trafficManagerRetryBurst,
+		}
+		if err := retryOptions.Validate(); err != nil {
+			klog.ErrorS(err, "Invalid retry options of the traffic manager controllers")
+			exitWithErrorFunc()
+		}
+
 		klog.V(1).InfoS("Start to setup TrafficManagerProfile controller")
 		if err := (&trafficmanagerprofile.Reconciler{
-			Client:         mgr.GetClient(),
-			ProfilesClient: profilesClient,
-			Recorder:       mgr.GetEventRecorderFor(trafficmanagerprofile.ControllerName),
+			Client:             mgr.GetClient(),
+			AzureClientFactory: azureClientFactory,
+			Recorder:           mgr.GetEventRecorderFor(trafficmanagerprofile.ControllerName),
+			// Each controller has its own rate limiter as the work queues are independent.
+			RateLimiter:               ratelimiter.New(retryOptions),
+			BackendEndpointNamePrefix: *azureEndpointNamePrefix,
 		}).SetupWithManager(mgr); err != nil {
 			klog.ErrorS(err, "Unable to create TrafficManagerProfile controller")
 			exitWithErrorFunc()
@@ -225,16 +403,58 @@ func main() {
 
 		klog.V(1).InfoS("Start to setup TrafficManagerBackend controller")
 		if err := (&trafficmanagerbackend.Reconciler{
-			Client:          mgr.GetClient(),
-			ProfilesClient:  profilesClient,
-			EndpointsClient: endpointsClient,
-			Recorder:        mgr.GetEventRecorderFor(trafficmanagerbackend.ControllerName),
+			Client:             mgr.GetClient(),
+			AzureClientFactory: azureClientFactory,
+			Recorder:           mgr.GetEventRecorderFor(trafficmanagerbackend.ControllerName),
+
+			AzureAPIErrorEventInterval:    *azureAPIErrorEventInterval,
+			EndpointMonitorResyncInterval: *endpointMonitorResyncInterval,
+			EndpointNamePrefix:            *azureEndpointNamePrefix,
+			MaxConcurrentEndpointDeletes:  *maxConcurrentEndpointDeletes,
+			MaxEndpointsPerProfile:        *maxEndpointsPerProfile,
+			NoExportedServicesThreshold:   *noExportedServicesThreshold,
+			OrphanAzureResourcesOnDelete:  *orphanAzureResourcesOnDelete,
+			PauseConfigMap:                pauseConfigMap,
+			PublicIPSubscriptions:         parseSubscriptionIDs(*publicIPSubscriptions),
+			RateLimiter:                   ratelimiter.New(retryOptions),
 			// serviceImport controller has already enabled the internalServiceExportIndexer.
 			// Therefore, no need to setup it again.
 		}).SetupWithManager(ctx, mgr, true); err != nil {
 			klog.ErrorS(err, "Unable to create TrafficManagerProfile controller")
 			exitWithErrorFunc()
 		}
+
+		if *enableTrafficManagerProfileWebhook {
+			klog.V(1).InfoS("Start to setup TrafficManagerProfile webhook")
+			if err := (&trafficmanagerprofile.Defaulter{}).SetupWebhookWithManager(mgr); err != nil {
+				klog.ErrorS(err, "Unable to create TrafficManagerProfile webhook")
+				exitWithErrorFunc()
+			}
+		}
+
+		if *enableTrafficManagerBackendWebhook {
+			klog.V(1).InfoS("Start to setup TrafficManagerBackend webhook")
+			// The validator relies on the field indexes set up by the TrafficManagerBackend controller.
+			if err := (&trafficmanagerbackend.Validator{
+				Client: mgr.GetClient(),
+			}).SetupWebhookWithManager(mgr); err != nil {
+				klog.ErrorS(err, "Unable to create TrafficManagerBackend webhook")
+				exitWithErrorFunc()
+			}
+		}
+
+		if *enableOrphanEndpointGC {
+			klog.V(1).InfoS("Orphaned endpoint garbage collection is enabled", "interval", *orphanEndpointGCInterval)
+			if err := mgr.Add(&trafficmanagerbackend.OrphanEndpointCollector{
+				Client:             mgr.GetClient(),
+				AzureClientFactory: azureClientFactory,
+				Interval:           *orphanEndpointGCInterval,
+				EndpointNamePrefix: *azureEndpointNamePrefix,
+			}); err != nil {
+				klog.ErrorS(err, "Unable to add the orphaned endpoint garbage collector")
+				exitWithErrorFunc()
+			}
+		}
 	}
 
 	klog.V(1).InfoS("Starting ServiceExportImport controller manager")
@@ -244,11 +464,17 @@ func main() {
 	}
 }
 
-// initAzureTrafficManagerClients initializes the Azure Traffic Manager profiles and endpoints clients.
-func initAzureTrafficManagerClients(cloudConfig *azure.CloudConfig) (*armtrafficmanager.ProfilesClient, *armtrafficmanager.EndpointsClient, error) {
-	authProvider, err := azclient.NewAuthProvider(&cloudConfig.ARMClientConfig, &cloudConfig.AzureAuthConfig)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create Azure auth provider: %w", err)
+// initAzureTrafficManagerClientFactory initializes the factory of the Azure Traffic Manager profiles and endpoints
+// clients, which creates the clients of the subscriptions other than the one in the cloud config on demand using the
+// same credential.
+// When the azureCloud is set, both the credential and the clients target its endpoints instead of the cloud of the
+// cloud config.
+// When the workload identity is configured, its credential is used instead of the one of the cloud config.
+func initAzureTrafficManagerClientFactory(ctx context.Context, cloudConfig *azure.CloudConfig, azureCloud *cloud.Configuration) (*azureclient.TrafficManagerClientFactory, error) {
+	setCloud := func(option *azpolicy.ClientOptions) {
+		if azureCloud != nil {
+			option.Cloud = *azureCloud
+		}
 	}
 
 	factoryConfig := &azclient.ClientFactoryConfig{
@@ -257,21 +483,47 @@ func initAzureTrafficManagerClients(cloudConfig *azure.CloudConfig) (*armtraffic
 	}
 	options, err := azclient.GetDefaultResourceClientOption(&cloudConfig.ARMClientConfig, factoryConfig)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get default resource client option: %w", err)
+		return nil, fmt.Errorf("failed to get default resource client option: %w", err)
 	}
+	setCloud(&options.ClientOptions)
 
 	if rateLimitPolicy := ratelimit.NewRateLimitPolicy(cloudConfig.Config); rateLimitPolicy != nil {
 		options.ClientOptions.PerCallPolicies = append(options.ClientOptions.PerCallPolicies, rateLimitPolicy)
 	}
+	// Gate every attempt of the write requests, including the retries, so that all the controllers back off together
+	// when the Azure Resource Manager throttles the requests.
+	options.ClientOptions.PerRetryPolicies = append(options.ClientOptions.PerRetryPolicies, azureclient.NewWriteRateLimitPolicy(*azureAPIQPS, *azureAPIBurst))
+
+	if *workloadIdentityClientID != "" {
+		klog.V(1).InfoS("Using the federated workload identity to access the Azure Traffic Manager", "clientID", *workloadIdentityClientID, "tenantID", *workloadIdentityTenantID, "tokenFile", *workloadIdentityTokenFile)
+		// Bound the token request so that an unreachable identity endpoint fails the startup instead of hanging.
+		tokenCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		credential, err := azureclient.NewWorkloadIdentityCredential(tokenCtx, azureclient.WorkloadIdentityOptions{
+			ClientID:      *workloadIdentityClientID,
+			TenantID:      *workloadIdentityTenantID,
+			TokenFilePath: *workloadIdentityTokenFile,
+		}, options.ClientOptions.Cloud)
+		if err != nil {
+			return nil, err
+		}
+		return azureclient.NewTrafficManagerClientFactory(cloudConfig.SubscriptionID, credential, options)
+	}
 
-	profilesClient, err := armtrafficmanager.NewProfilesClient(cloudConfig.SubscriptionID, authProvider.GetAzIdentity(), options)
+	authProvider, err := azclient.NewAuthProvider(&cloudConfig.ARMClientConfig, &cloudConfig.AzureAuthConfig, setCloud)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create Azure trafficManager profiles client: %w", err)
+		return nil, fmt.Errorf("failed to create Azure auth provider: %w", err)
 	}
+	return azureclient.NewTrafficManagerClientFactory(cloudConfig.SubscriptionID, authProvider.GetAzIdentity(), options)
+}
 
-	endpointsClient, err := armtrafficmanager.NewEndpointsClient(cloudConfig.SubscriptionID, authProvider.GetAzIdentity(), options)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create Azure trafficManager endpoints client: %w", err)
+// parseSubscriptionIDs splits the comma-separated subscription IDs and drops the empty ones.
+func parseSubscriptionIDs(s string) []string {
+	var ids []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
 	}
-	return profilesClient, endpointsClient, nil
+	return ids
 }
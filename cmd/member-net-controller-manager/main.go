@@ -326,7 +326,7 @@ func setupControllersWithManager(ctx context.Context, hubMgr, memberMgr manager.
 	}
 
 	var azurePublicIPAddressClient publicipaddressclient.Interface
-	var resourceGroupName string
+	var resourceGroupName, region string
 	if *enableTrafficManagerFeature {
 		klog.V(1).InfoS("Traffic manager feature is enabled, loading cloud config and creating azure clients", "cloudConfigFile", *cloudConfigFile)
 		cloudConfig, err := azure.NewCloudConfigFromFile(*cloudConfigFile)
@@ -344,6 +344,7 @@ func setupControllersWithManager(ctx context.Context, hubMgr, memberMgr manager.
 		}
 
 		resourceGroupName = cloudConfig.ResourceGroup
+		region = cloudConfig.Location
 	}
 
 	klog.V(1).InfoS("Create serviceexport reconciler", "enableTrafficManagerFeature", *enableTrafficManagerFeature)
@@ -355,6 +356,7 @@ func setupControllersWithManager(ctx context.Context, hubMgr, memberMgr manager.
 		Recorder:                    memberMgr.GetEventRecorderFor(serviceexport.ControllerName),
 		EnableTrafficManagerFeature: *enableTrafficManagerFeature,
 		ResourceGroupName:           resourceGroupName,
+		Region:                      region,
 		AzurePublicIPAddressClient:  azurePublicIPAddressClient,
 	}).SetupWithManager(memberMgr); err != nil {
 		klog.ErrorS(err, "Unable to create serviceexport reconciler")
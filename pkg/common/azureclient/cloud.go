@@ -0,0 +1,42 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package azureclient
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+const (
+	// AzureCloudPublic is the name of the Azure public cloud.
+	AzureCloudPublic = "AzurePublic"
+	// AzureCloudUSGovernment is the name of the Azure US Government cloud.
+	AzureCloudUSGovernment = "AzureUSGovernment"
+	// AzureCloudChina is the name of the Azure China cloud.
+	AzureCloudChina = "AzureChina"
+)
+
+// azureClouds maps the supported Azure cloud names to their Azure Active Directory and Azure Resource Manager endpoints.
+var azureClouds = map[string]cloud.Configuration{
+	AzureCloudPublic:       cloud.AzurePublic,
+	AzureCloudUSGovernment: cloud.AzureGovernment,
+	AzureCloudChina:        cloud.AzureChina,
+}
+
+// CloudConfiguration returns the cloud configuration of the given Azure cloud name.
+// It returns nil when the name is empty so that the cloud in the cloud config file is used, and an error when the
+// name is not one of the supported Azure clouds.
+func CloudConfiguration(name string) (*cloud.Configuration, error) {
+	if name == "" {
+		return nil, nil
+	}
+	cfg, ok := azureClouds[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported Azure cloud %q, must be one of %q, %q or %q", name, AzureCloudPublic, AzureCloudUSGovernment, AzureCloudChina)
+	}
+	return &cfg, nil
+}
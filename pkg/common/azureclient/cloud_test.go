@@ -0,0 +1,62 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package azureclient
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCloudConfiguration(t *testing.T) {
+	tests := []struct {
+		name    string
+		cloud   string
+		want    *cloud.Configuration
+		wantErr bool
+	}{
+		{
+			name: "empty name",
+		},
+		{
+			name:  "Azure public cloud",
+			cloud: AzureCloudPublic,
+			want:  &cloud.AzurePublic,
+		},
+		{
+			name:  "Azure US Government cloud",
+			cloud: AzureCloudUSGovernment,
+			want:  &cloud.AzureGovernment,
+		},
+		{
+			name:  "Azure China cloud",
+			cloud: AzureCloudChina,
+			want:  &cloud.AzureChina,
+		},
+		{
+			name:    "unknown cloud",
+			cloud:   "AzureGermany",
+			wantErr: true,
+		},
+		{
+			name:    "name of the cloud config file",
+			cloud:   "AzureUSGovernmentCloud",
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := CloudConfiguration(tc.cloud)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("CloudConfiguration() got error %v, want error %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("CloudConfiguration() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package azureclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// DefaultWorkloadIdentityTokenFilePath is the path of the service account token projected by the Azure Workload
+// Identity webhook in AKS.
+const DefaultWorkloadIdentityTokenFilePath = "/var/run/secrets/azure/tokens/azure-identity-token"
+
+// WorkloadIdentityOptions configures the federated workload identity used to access the Azure Resource Manager.
+// All the fields must be set explicitly, so that the credential never falls back to the environment variables, which
+// may be stale.
+type WorkloadIdentityOptions struct {
+	// ClientID is the client ID of the user-assigned managed identity or the application federated with the service
+	// account.
+	ClientID string
+	// TenantID is the tenant ID of the identity.
+	TenantID string
+	// TokenFilePath is the path of the projected service account token exchanged for the Azure AD token.
+	TokenFilePath string
+}
+
+// NewWorkloadIdentityCredential returns the credential of the federated workload identity in the cloud, and fails if
+// it cannot acquire a token of the Azure Resource Manager, so that a misconfigured identity is reported at startup
+// instead of failing every Azure call.
+func NewWorkloadIdentityCredential(ctx context.Context, opts WorkloadIdentityOptions, azureCloud cloud.Configuration) (azcore.TokenCredential, error) {
+	if opts.ClientID == "" || opts.TenantID == "" || opts.TokenFilePath == "" {
+		return nil, errors.New("the client ID, tenant ID and token file path of the workload identity must all be set")
+	}
+	credential, err := azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+		ClientOptions: azcore.ClientOptions{Cloud: azureCloud},
+		ClientID:      opts.ClientID,
+		TenantID:      opts.TenantID,
+		TokenFilePath: opts.TokenFilePath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the workload identity credential: %w", err)
+	}
+	if err := verifyCredential(ctx, credential, azureCloud); err != nil {
+		return nil, fmt.Errorf("workload identity %q of tenant %q cannot acquire a token: %w", opts.ClientID, opts.TenantID, err)
+	}
+	return credential, nil
+}
+
+// verifyCredential acquires a token of the Azure Resource Manager of the cloud, which is the Azure public cloud when
+// not configured.
+func verifyCredential(ctx context.Context, credential azcore.TokenCredential, azureCloud cloud.Configuration) error {
+	audience := azureCloud.Services[cloud.ResourceManager].Audience
+	if audience == "" {
+		audience = cloud.AzurePublic.Services[cloud.ResourceManager].Audience
+	}
+	_, err := credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{strings.TrimSuffix(audience, "/") + "/.default"}})
+	return err
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package azureclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/google/go-cmp/cmp"
+)
+
+// scopeRecordingCredential records the scopes of the token requests.
+type scopeRecordingCredential struct {
+	scopes []string
+	err    error
+}
+
+func (c *scopeRecordingCredential) GetToken(_ context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.scopes = append(c.scopes, opts.Scopes...)
+	return azcore.AccessToken{Token: "token"}, c.err
+}
+
+func TestVerifyCredential(t *testing.T) {
+	tests := []struct {
+		name       string
+		azureCloud cloud.Configuration
+		err        error
+		wantScopes []string
+		wantErr    bool
+	}{
+		{
+			name:       "cloud not configured",
+			wantScopes: []string{"https://management.core.windows.net/.default"},
+		},
+		{
+			name:       "Azure US Government cloud",
+			azureCloud: cloud.AzureGovernment,
+			wantScopes: []string{"https://management.core.usgovcloudapi.net/.default"},
+		},
+		{
+			name:       "failed to acquire the token",
+			azureCloud: cloud.AzurePublic,
+			err:        errors.New("AADSTS700016: application not found"),
+			wantScopes: []string{"https://management.core.windows.net/.default"},
+			wantErr:    true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			credential := &scopeRecordingCredential{err: tc.err}
+			err := verifyCredential(context.Background(), credential, tc.azureCloud)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("verifyCredential() got error %v, want error %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantScopes, credential.scopes); diff != "" {
+				t.Errorf("verifyCredential() scopes mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewWorkloadIdentityCredential(t *testing.T) {
+	tests := []struct {
+		name string
+		opts WorkloadIdentityOptions
+	}{
+		{
+			name: "client ID not set",
+			opts: WorkloadIdentityOptions{TenantID: "tenant", TokenFilePath: DefaultWorkloadIdentityTokenFilePath},
+		},
+		{
+			name: "tenant ID not set",
+			opts: WorkloadIdentityOptions{ClientID: "client", TokenFilePath: DefaultWorkloadIdentityTokenFilePath},
+		},
+		{
+			name: "token file path not set",
+			opts: WorkloadIdentityOptions{ClientID: "client", TenantID: "tenant"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewWorkloadIdentityCredential(context.Background(), tc.opts, cloud.AzurePublic); err == nil {
+				t.Errorf("NewWorkloadIdentityCredential() got nil error, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package azureclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"golang.org/x/time/rate"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// defaultThrottleBackoff is how long the write requests are held after being throttled when the Azure Resource
+	// Manager does not return the Retry-After header.
+	defaultThrottleBackoff = 10 * time.Second
+	// maxThrottleBackoff caps the Retry-After header returned by the Azure Resource Manager.
+	maxThrottleBackoff = 5 * time.Minute
+)
+
+// WriteRateLimitPolicy is a per-retry policy which gates the Azure write requests (any method other than GET and HEAD)
+// by a token bucket rate limiter per subscription, so that all the controllers share the same budget of the Azure
+// Resource Manager write calls.
+// When a write request is throttled, the subsequent write requests of the subscription are held until the time
+// indicated by the Retry-After header, so that the controllers back off collectively instead of amplifying the
+// throttling by retrying independently.
+type WriteRateLimitPolicy struct {
+	qps   float64
+	burst int
+
+	mu sync.Mutex
+	// limiters is keyed by the lowercase subscription ID.
+	limiters map[string]*subscriptionRateLimiter
+}
+
+type subscriptionRateLimiter struct {
+	// limiter is nil when the rate limit is disabled.
+	limiter *rate.Limiter
+
+	mu sync.Mutex
+	// throttledUntil is the time until which the write requests are held after being throttled.
+	throttledUntil time.Time
+}
+
+// NewWriteRateLimitPolicy creates a policy allowing qps write requests per second with bursts of up to burst
+// requests per subscription.
+// The rate limit is disabled when qps is not positive, while the write requests are still held after being throttled.
+func NewWriteRateLimitPolicy(qps float64, burst int) *WriteRateLimitPolicy {
+	if burst < 1 {
+		burst = 1
+	}
+	return &WriteRateLimitPolicy{
+		qps:      qps,
+		burst:    burst,
+		limiters: make(map[string]*subscriptionRateLimiter),
+	}
+}
+
+// Do implements the policy.Policy interface.
+func (p *WriteRateLimitPolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	if raw.Method == http.MethodGet || raw.Method == http.MethodHead {
+		return req.Next()
+	}
+	subscriptionID := subscriptionIDFromPath(raw.URL.Path)
+	l := p.limiterFor(subscriptionID)
+	if err := l.wait(raw.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := req.Next()
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		backoff := retryAfter(resp)
+		l.throttle(backoff)
+		klog.V(2).InfoS("Azure write requests are throttled, holding the subsequent write requests", "subscriptionID", subscriptionID, "retryAfter", backoff)
+	}
+	return resp, err
+}
+
+func (p *WriteRateLimitPolicy) limiterFor(subscriptionID string) *subscriptionRateLimiter {
+	key := strings.ToLower(subscriptionID) // subscription IDs are case-insensitive
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if l, ok := p.limiters[key]; ok {
+		return l
+	}
+	l := &subscriptionRateLimiter{}
+	if p.qps > 0 {
+		l.limiter = rate.NewLimiter(rate.Limit(p.qps), p.burst)
+	}
+	p.limiters[key] = l
+	return l
+}
+
+// wait blocks until the write request is allowed or the context is done.
+func (l *subscriptionRateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	delay := time.Until(l.throttledUntil)
+	l.mu.Unlock()
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if l.limiter == nil {
+		return nil
+	}
+	return l.limiter.Wait(ctx)
+}
+
+// throttle holds the write requests for the backoff, unless they are already held for longer.
+func (l *subscriptionRateLimiter) throttle(backoff time.Duration) {
+	until := time.Now().Add(backoff)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.throttledUntil) {
+		l.throttledUntil = until
+	}
+}
+
+// subscriptionIDFromPath returns the subscription ID of the Azure Resource Manager request path, for example,
+// /subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/..., or an empty string if not found.
+func subscriptionIDFromPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if strings.EqualFold(segments[i], "subscriptions") {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
+
+// retryAfter returns the backoff indicated by the Retry-After header of the throttled response, bounded by the
+// maxThrottleBackoff.
+func retryAfter(resp *http.Response) time.Duration {
+	backoff := defaultThrottleBackoff
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+			backoff = time.Duration(seconds) * time.Second
+		} else if t, err := http.ParseTime(v); err == nil {
+			backoff = time.Until(t)
+		}
+	}
+	if backoff < 0 {
+		return 0
+	}
+	if backoff > maxThrottleBackoff {
+		return maxThrottleBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package azureclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// fakeTransport returns the status code of the request path, or 200 if not set.
+type fakeTransport struct {
+	statusCodes map[string]int
+}
+
+func (t *fakeTransport) Do(req *http.Request) (*http.Response, error) {
+	code := http.StatusOK
+	if c, ok := t.statusCodes[req.URL.Path]; ok {
+		code = c
+	}
+	header := http.Header{}
+	if code == http.StatusTooManyRequests {
+		header.Set("Retry-After", "1")
+	}
+	return &http.Response{StatusCode: code, Header: header, Body: http.NoBody, Request: req}, nil
+}
+
+func TestWriteRateLimitPolicy_Throttled(t *testing.T) {
+	throttledPath := "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Network/trafficManagerProfiles/throttled"
+	path := "/subscriptions/SUB-1/resourceGroups/rg/providers/Microsoft.Network/trafficManagerProfiles/profile"
+	otherSubscriptionPath := "/subscriptions/sub-2/resourceGroups/rg/providers/Microsoft.Network/trafficManagerProfiles/profile"
+	pl := runtime.NewPipeline("azureclient", "test", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{NewWriteRateLimitPolicy(0, 0)},
+	}, &policy.ClientOptions{
+		Transport: &fakeTransport{statusCodes: map[string]int{throttledPath: http.StatusTooManyRequests}},
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	})
+
+	send := func(method, path string) time.Duration {
+		req, err := runtime.NewRequest(context.Background(), method, "https://management.azure.com"+path)
+		if err != nil {
+			t.Fatalf("NewRequest() got error %v, want nil", err)
+		}
+		start := time.Now()
+		if _, err := pl.Do(req); err != nil {
+			t.Fatalf("Do(%s %s) got error %v, want nil", method, path, err)
+		}
+		return time.Since(start)
+	}
+
+	if got := send(http.MethodPut, throttledPath); got > 500*time.Millisecond {
+		t.Errorf("the first write request took %v, want no wait", got)
+	}
+	if got := send(http.MethodGet, path); got > 500*time.Millisecond {
+		t.Errorf("read request took %v, want no wait", got)
+	}
+	if got := send(http.MethodPut, otherSubscriptionPath); got > 500*time.Millisecond {
+		t.Errorf("write request of another subscription took %v, want no wait", got)
+	}
+	if got := send(http.MethodDelete, path); got < 500*time.Millisecond {
+		t.Errorf("write request of the throttled subscription took %v, want to wait for the Retry-After", got)
+	}
+}
+
+func TestWriteRateLimitPolicy_ContextDone(t *testing.T) {
+	p := NewWriteRateLimitPolicy(0.001, 1)
+	l := p.limiterFor("sub")
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("wait() got error %v, want nil", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); err == nil {
+		t.Errorf("wait() got nil error, want error when the rate limit is exceeded")
+	}
+	if p.limiterFor("SUB") != l {
+		t.Errorf("limiterFor(%q) returned a different limiter, want the same limiter as %q", "SUB", "sub")
+	}
+}
+
+func TestSubscriptionIDFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{
+			path: "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/trafficManagerProfiles/profile",
+			want: "sub",
+		},
+		{
+			path: "/Subscriptions/sub",
+			want: "sub",
+		},
+		{
+			path: "/subscriptions",
+		},
+		{
+			path: "/providers/Microsoft.Network/checkTrafficManagerNameAvailability",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			if got := subscriptionIDFromPath(tc.path); got != tc.want {
+				t.Errorf("subscriptionIDFromPath(%q) = %q, want %q", tc.path, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{
+			name: "no header",
+			want: defaultThrottleBackoff,
+		},
+		{
+			name:  "seconds",
+			value: "30",
+			want:  30 * time.Second,
+		},
+		{
+			name:  "exceeding the max backoff",
+			value: "3600",
+			want:  maxThrottleBackoff,
+		},
+		{
+			name:  "date in the past",
+			value: "Mon, 02 Jan 2006 15:04:05 GMT",
+			want:  0,
+		},
+		{
+			name:  "invalid value",
+			value: "invalid",
+			want:  defaultThrottleBackoff,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tc.value != "" {
+				resp.Header.Set("Retry-After", tc.value)
+			}
+			if got := retryAfter(resp); got != tc.want {
+				t.Errorf("retryAfter() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package azureclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+const (
+	// DefaultReadinessCheckInterval is the default interval between two Azure connectivity probes.
+	DefaultReadinessCheckInterval = time.Minute
+	// DefaultReadinessStalenessThreshold is the default duration since the last successful Azure connectivity probe
+	// after which the readiness check fails.
+	DefaultReadinessStalenessThreshold = 5 * time.Minute
+)
+
+// ReadinessChecker periodically lists the Azure Traffic Manager profiles in a resource group of the default
+// subscription to verify the Azure credential and clients are functional, and fails the readiness check when the last
+// successful probe is older than the staleness threshold.
+type ReadinessChecker struct {
+	// ClientFactory provides the Azure Traffic Manager clients of the default subscription.
+	ClientFactory *TrafficManagerClientFactory
+	// ResourceGroup is the resource group in which the profiles are listed.
+	// The credential only needs the permission to read the Traffic Manager profiles in it.
+	ResourceGroup string
+
+	// Interval is the wait time between two probes.
+	// DefaultReadinessCheckInterval is used when it's not positive.
+	Interval time.Duration
+	// StalenessThreshold is the duration since the last successful probe after which the readiness check fails.
+	// DefaultReadinessStalenessThreshold is used when it's not positive.
+	StalenessThreshold time.Duration
+
+	mu sync.RWMutex
+	// lastSuccessTime is the time of the last successful probe, which is zero before the first one.
+	lastSuccessTime time.Time
+	// lastErr is the error of the last failed probe.
+	lastErr error
+}
+
+// Start runs the probes until the context is canceled.
+// It implements the manager.Runnable interface.
+func (c *ReadinessChecker) Start(ctx context.Context) error {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultReadinessCheckInterval
+	}
+	klog.V(1).InfoS("Starting the Azure connectivity readiness checker", "resourceGroup", c.ResourceGroup, "interval", interval)
+	wait.UntilWithContext(ctx, c.probe, interval)
+	klog.V(1).InfoS("Stopped the Azure connectivity readiness checker")
+	return nil
+}
+
+// NeedLeaderElection returns false so that every replica reports its own readiness.
+// It implements the manager.LeaderElectionRunnable interface.
+func (c *ReadinessChecker) NeedLeaderElection() bool {
+	return false
+}
+
+// Check returns an error when there is no successful probe within the staleness threshold.
+// It implements the healthz.Checker function.
+func (c *ReadinessChecker) Check(_ *http.Request) error {
+	threshold := c.StalenessThreshold
+	if threshold <= 0 {
+		threshold = DefaultReadinessStalenessThreshold
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.lastSuccessTime.IsZero() {
+		return fmt.Errorf("no successful Azure API call yet, last error: %v", c.lastErr)
+	}
+	if since := time.Since(c.lastSuccessTime); since > threshold {
+		return fmt.Errorf("last successful Azure API call was %v ago, exceeding %v, last error: %v", since.Round(time.Second), threshold, c.lastErr)
+	}
+	return nil
+}
+
+// probe lists the first page of the Azure Traffic Manager profiles in the resource group and records the result.
+func (c *ReadinessChecker) probe(ctx context.Context) {
+	err := c.listProfiles(ctx)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		klog.ErrorS(err, "Failed to probe the Azure connectivity", "resourceGroup", c.ResourceGroup, "lastSuccessTime", c.lastSuccessTime)
+		c.lastErr = err
+		return
+	}
+	klog.V(4).InfoS("Probed the Azure connectivity", "resourceGroup", c.ResourceGroup)
+	c.lastSuccessTime = time.Now()
+	c.lastErr = nil
+}
+
+func (c *ReadinessChecker) listProfiles(ctx context.Context) error {
+	clients, err := c.ClientFactory.Clients("")
+	if err != nil {
+		return err
+	}
+	startTime := time.Now()
+	_, err = clients.ProfilesClient.NewListByResourceGroupPager(c.ResourceGroup, nil).NextPage(ctx)
+	metrics.ObserveAzureAPICall(metrics.AzureAPIClientProfiles, metrics.AzureAPIOperationList, startTime, err)
+	return err
+}
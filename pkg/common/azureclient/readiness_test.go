@@ -0,0 +1,89 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package azureclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azcorefake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	armtrafficmanagerfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager/fake"
+)
+
+func newReadinessCheckerForTest(t *testing.T, statusCode int) *ReadinessChecker {
+	fakeServer := armtrafficmanagerfake.ProfilesServer{
+		NewListByResourceGroupPager: func(_ string, _ *armtrafficmanager.ProfilesClientListByResourceGroupOptions) (resp azcorefake.PagerResponder[armtrafficmanager.ProfilesClientListByResourceGroupResponse]) {
+			if statusCode != http.StatusOK {
+				resp.AddResponseError(statusCode, "Error")
+				return resp
+			}
+			resp.AddPage(http.StatusOK, armtrafficmanager.ProfilesClientListByResourceGroupResponse{}, nil)
+			return resp
+		},
+	}
+	clientFactory, err := armtrafficmanager.NewClientFactory(defaultSubscriptionID, &azcorefake.TokenCredential{},
+		&arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: armtrafficmanagerfake.NewProfilesServerTransport(&fakeServer),
+				Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+			},
+		})
+	if err != nil {
+		t.Fatalf("failed to create the client factory: %v", err)
+	}
+	return &ReadinessChecker{
+		ClientFactory:      NewTrafficManagerClientFactoryForClients(clientFactory.NewProfilesClient(), nil),
+		ResourceGroup:      "rg",
+		StalenessThreshold: time.Minute,
+	}
+}
+
+func TestReadinessChecker(t *testing.T) {
+	tests := []struct {
+		name            string
+		statusCode      int
+		lastSuccessTime time.Time
+		wantErr         bool
+	}{
+		{
+			name:       "successful probe",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "no successful probe yet",
+			statusCode: http.StatusForbidden,
+			wantErr:    true,
+		},
+		{
+			name:            "failed probe within the staleness threshold",
+			statusCode:      http.StatusInternalServerError,
+			lastSuccessTime: time.Now().Add(-30 * time.Second),
+		},
+		{
+			name:            "failed probe exceeding the staleness threshold",
+			statusCode:      http.StatusUnauthorized,
+			lastSuccessTime: time.Now().Add(-2 * time.Minute),
+			wantErr:         true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newReadinessCheckerForTest(t, tc.statusCode)
+			c.lastSuccessTime = tc.lastSuccessTime
+			c.probe(context.Background())
+			err := c.Check(nil)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("Check() got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
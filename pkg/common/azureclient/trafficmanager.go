@@ -0,0 +1,118 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package azureclient features the factory of the Azure clients used by the hub networking controllers.
+package azureclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	"k8s.io/klog/v2"
+)
+
+// ProfilesClientInterface is the subset of the Azure Traffic Manager profiles client used by the controllers, so that
+// the tests can inject a client which returns the desired errors, for example, the throttled or conflict ones.
+type ProfilesClientInterface interface {
+	Get(ctx context.Context, resourceGroupName string, profileName string, options *armtrafficmanager.ProfilesClientGetOptions) (armtrafficmanager.ProfilesClientGetResponse, error)
+	CreateOrUpdate(ctx context.Context, resourceGroupName string, profileName string, parameters armtrafficmanager.Profile, options *armtrafficmanager.ProfilesClientCreateOrUpdateOptions) (armtrafficmanager.ProfilesClientCreateOrUpdateResponse, error)
+	Delete(ctx context.Context, resourceGroupName string, profileName string, options *armtrafficmanager.ProfilesClientDeleteOptions) (armtrafficmanager.ProfilesClientDeleteResponse, error)
+	NewListByResourceGroupPager(resourceGroupName string, options *armtrafficmanager.ProfilesClientListByResourceGroupOptions) *runtime.Pager[armtrafficmanager.ProfilesClientListByResourceGroupResponse]
+}
+
+// EndpointsClientInterface is the subset of the Azure Traffic Manager endpoints client used by the controllers, so
+// that the tests can inject a client which returns the desired errors, for example, the throttled or conflict ones.
+type EndpointsClientInterface interface {
+	Get(ctx context.Context, resourceGroupName string, profileName string, endpointType armtrafficmanager.EndpointType, endpointName string, options *armtrafficmanager.EndpointsClientGetOptions) (armtrafficmanager.EndpointsClientGetResponse, error)
+	CreateOrUpdate(ctx context.Context, resourceGroupName string, profileName string, endpointType armtrafficmanager.EndpointType, endpointName string, parameters armtrafficmanager.Endpoint, options *armtrafficmanager.EndpointsClientCreateOrUpdateOptions) (armtrafficmanager.EndpointsClientCreateOrUpdateResponse, error)
+	Delete(ctx context.Context, resourceGroupName string, profileName string, endpointType armtrafficmanager.EndpointType, endpointName string, options *armtrafficmanager.EndpointsClientDeleteOptions) (armtrafficmanager.EndpointsClientDeleteResponse, error)
+}
+
+var (
+	_ ProfilesClientInterface  = &armtrafficmanager.ProfilesClient{}
+	_ EndpointsClientInterface = &armtrafficmanager.EndpointsClient{}
+)
+
+// TrafficManagerClients are the Azure Traffic Manager clients of a subscription.
+type TrafficManagerClients struct {
+	ProfilesClient  ProfilesClientInterface
+	EndpointsClient EndpointsClientInterface
+}
+
+// TrafficManagerClientFactory provides the Azure Traffic Manager clients per subscription.
+// The clients of the default subscription are created up front, while the clients of the other subscriptions are
+// created on the first use and cached.
+// All the clients share the same credential and client options, so that the access tokens and the rate limiter are
+// shared across the subscriptions as well.
+type TrafficManagerClientFactory struct {
+	defaultSubscriptionID string
+	credential            azcore.TokenCredential
+	options               *arm.ClientOptions
+
+	// staticClients are returned for all the subscriptions when set.
+	staticClients *TrafficManagerClients
+
+	mu sync.Mutex
+	// clients is keyed by the lowercase subscription ID.
+	clients map[string]*TrafficManagerClients
+}
+
+// NewTrafficManagerClientFactory creates a factory with the clients of the default subscription.
+func NewTrafficManagerClientFactory(defaultSubscriptionID string, credential azcore.TokenCredential, options *arm.ClientOptions) (*TrafficManagerClientFactory, error) {
+	f := &TrafficManagerClientFactory{
+		defaultSubscriptionID: defaultSubscriptionID,
+		credential:            credential,
+		options:               options,
+		clients:               make(map[string]*TrafficManagerClients),
+	}
+	if _, err := f.Clients(defaultSubscriptionID); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// NewTrafficManagerClientFactoryForClients creates a factory which returns the given clients for all the
+// subscriptions, for example, to use the fake clients in tests.
+func NewTrafficManagerClientFactoryForClients(profilesClient ProfilesClientInterface, endpointsClient EndpointsClientInterface) *TrafficManagerClientFactory {
+	return &TrafficManagerClientFactory{
+		staticClients: &TrafficManagerClients{ProfilesClient: profilesClient, EndpointsClient: endpointsClient},
+	}
+}
+
+// Clients returns the Azure Traffic Manager clients of the subscription.
+// The clients of the default subscription are returned when the subscription ID is empty.
+func (f *TrafficManagerClientFactory) Clients(subscriptionID string) (*TrafficManagerClients, error) {
+	if f.staticClients != nil {
+		return f.staticClients, nil
+	}
+	if subscriptionID == "" {
+		subscriptionID = f.defaultSubscriptionID
+	}
+	key := strings.ToLower(subscriptionID) // subscription IDs are case-insensitive
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if clients, ok := f.clients[key]; ok {
+		return clients, nil
+	}
+	profilesClient, err := armtrafficmanager.NewProfilesClient(subscriptionID, f.credential, f.options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure trafficManager profiles client for subscription %q: %w", subscriptionID, err)
+	}
+	endpointsClient, err := armtrafficmanager.NewEndpointsClient(subscriptionID, f.credential, f.options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure trafficManager endpoints client for subscription %q: %w", subscriptionID, err)
+	}
+	clients := &TrafficManagerClients{ProfilesClient: profilesClient, EndpointsClient: endpointsClient}
+	f.clients[key] = clients
+	klog.V(2).InfoS("Created Azure Traffic Manager clients", "subscriptionID", subscriptionID)
+	return clients, nil
+}
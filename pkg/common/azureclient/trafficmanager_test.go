@@ -0,0 +1,70 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package azureclient
+
+import (
+	"testing"
+
+	azcorefake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+)
+
+const (
+	defaultSubscriptionID = "00000000-0000-0000-0000-000000000000"
+	otherSubscriptionID   = "AAAAAAAA-0000-0000-0000-000000000000"
+)
+
+func TestTrafficManagerClientFactory_Clients(t *testing.T) {
+	f, err := NewTrafficManagerClientFactory(defaultSubscriptionID, &azcorefake.TokenCredential{}, nil)
+	if err != nil {
+		t.Fatalf("NewTrafficManagerClientFactory() got error %v, want nil", err)
+	}
+
+	defaultClients, err := f.Clients(defaultSubscriptionID)
+	if err != nil {
+		t.Fatalf("Clients(%q) got error %v, want nil", defaultSubscriptionID, err)
+	}
+	if defaultClients.ProfilesClient == nil || defaultClients.EndpointsClient == nil {
+		t.Fatalf("Clients(%q) = %+v, want non-nil clients", defaultSubscriptionID, defaultClients)
+	}
+	got, err := f.Clients("")
+	if err != nil {
+		t.Fatalf("Clients(\"\") got error %v, want nil", err)
+	}
+	if got != defaultClients {
+		t.Errorf("Clients(\"\") = %p, want the default subscription clients %p", got, defaultClients)
+	}
+
+	otherClients, err := f.Clients(otherSubscriptionID)
+	if err != nil {
+		t.Fatalf("Clients(%q) got error %v, want nil", otherSubscriptionID, err)
+	}
+	if otherClients == defaultClients {
+		t.Errorf("Clients(%q) returned the default subscription clients, want new clients", otherSubscriptionID)
+	}
+	lowercase := "aaaaaaaa-0000-0000-0000-000000000000"
+	got, err = f.Clients(lowercase)
+	if err != nil {
+		t.Fatalf("Clients(%q) got error %v, want nil", lowercase, err)
+	}
+	if got != otherClients {
+		t.Errorf("Clients(%q) = %p, want the cached clients %p", lowercase, got, otherClients)
+	}
+}
+
+func TestNewTrafficManagerClientFactoryForClients(t *testing.T) {
+	f := NewTrafficManagerClientFactoryForClients(nil, nil)
+	got1, err := f.Clients(defaultSubscriptionID)
+	if err != nil {
+		t.Fatalf("Clients(%q) got error %v, want nil", defaultSubscriptionID, err)
+	}
+	got2, err := f.Clients(otherSubscriptionID)
+	if err != nil {
+		t.Fatalf("Clients(%q) got error %v, want nil", otherSubscriptionID, err)
+	}
+	if got1 != got2 {
+		t.Errorf("Clients() returned different clients %p and %p, want the same static clients", got1, got2)
+	}
+}
@@ -9,10 +9,35 @@ package azureerrors
 import (
 	"errors"
 	"net/http"
+	"regexp"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 )
 
+// unrecoverableErrorCodes are the error codes returned by the azure server when the subscription or the credential
+// cannot be used anymore.
+var unrecoverableErrorCodes = map[string]bool{
+	"DisabledSubscription":             true,
+	"InvalidAuthenticationToken":       true,
+	"InvalidAuthenticationTokenTenant": true,
+	"InvalidSubscriptionId":            true,
+	"ReadOnlyDisabledSubscription":     true,
+	"SubscriptionNotFound":             true,
+}
+
+// authorizationFailedErrorCodes are the error codes returned by the azure server when the identity does not have the
+// permission to perform the action over the scope.
+var authorizationFailedErrorCodes = map[string]bool{
+	"AuthorizationFailed":       true,
+	"LinkedAuthorizationFailed": true,
+}
+
+// authorizationFailedDetailsRegexp matches the action and scope in the message of the authorization failures, for example,
+// "does not have authorization to perform action '{action}' over scope '{scope}' or the scope is invalid." or
+// "does not have permission to perform action(s) '{action}' on the linked scope(s) '{scope}' or the linked scope(s) are invalid."
+var authorizationFailedDetailsRegexp = regexp.MustCompile(`perform action(?:\(s\))? '([^']+)' (?:over scope|on the linked scope\(s\)) '([^']+)'`)
+
 // IsNotFound returns true if the error is a http 404 error returned by the azure server.
 func IsNotFound(err error) bool {
 	var responseError *azcore.ResponseError
@@ -43,3 +68,84 @@ func IsForbidden(err error) bool {
 	var responseError *azcore.ResponseError
 	return errors.As(err, &responseError) && responseError.StatusCode == http.StatusForbidden
 }
+
+// IsBadRequest determines if the error is a http 400 error returned by the azure server.
+func IsBadRequest(err error) bool {
+	var responseError *azcore.ResponseError
+	return errors.As(err, &responseError) && responseError.StatusCode == http.StatusBadRequest
+}
+
+// IsUnrecoverable returns true if the error is caused by an invalid credential or a subscription which cannot be used
+// anymore (for example, the subscription is deleted), and it cannot be resolved by retrying.
+func IsUnrecoverable(err error) bool {
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) {
+		return responseError.StatusCode == http.StatusUnauthorized || unrecoverableErrorCodes[responseError.ErrorCode]
+	}
+	// The credential is rejected by the identity provider, for example, the client secret is expired.
+	var authError *azidentity.AuthenticationFailedError
+	return errors.As(err, &authError) && authError.RawResponse != nil &&
+		(authError.RawResponse.StatusCode == http.StatusBadRequest || authError.RawResponse.StatusCode == http.StatusUnauthorized)
+}
+
+// IsAuthorizationFailed returns true if the error is a http 403 error returned by the azure server because the identity
+// does not have the permission to perform the action, which cannot be resolved by retrying until the permission is granted.
+func IsAuthorizationFailed(err error) bool {
+	var responseError *azcore.ResponseError
+	return errors.As(err, &responseError) && responseError.StatusCode == http.StatusForbidden &&
+		authorizationFailedErrorCodes[responseError.ErrorCode]
+}
+
+// AuthorizationFailedDetails returns the action and scope which the identity is not authorized to perform over, parsed
+// from the message of the authorization failure.
+// It returns empty strings when the error is not an authorization failure or the message cannot be parsed.
+func AuthorizationFailedDetails(err error) (action, scope string) {
+	if !IsAuthorizationFailed(err) {
+		return "", ""
+	}
+	matches := authorizationFailedDetailsRegexp.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return "", ""
+	}
+	return matches[1], matches[2]
+}
+
+// Error categories returned by Category.
+const (
+	CategoryAuthorizationFailed = "AuthorizationFailed"
+	CategoryUnrecoverable       = "Unrecoverable"
+	CategoryThrottled           = "Throttled"
+	CategoryNotFound            = "NotFound"
+	CategoryConflict            = "Conflict"
+	CategoryBadRequest          = "BadRequest"
+	CategoryClientError         = "ClientError"
+	CategoryServerError         = "ServerError"
+	CategoryUnknown             = "Unknown"
+)
+
+// Category classifies the error returned by the azure server, so that the errors of the same kind can be aggregated,
+// for example, when deduplicating the events.
+// The errors which are not returned by the azure server, such as the network errors, are categorized as Unknown.
+func Category(err error) string {
+	switch {
+	case IsAuthorizationFailed(err):
+		return CategoryAuthorizationFailed
+	case IsUnrecoverable(err):
+		return CategoryUnrecoverable
+	case IsThrottled(err):
+		return CategoryThrottled
+	case IsNotFound(err):
+		return CategoryNotFound
+	case IsConflict(err):
+		return CategoryConflict
+	case IsBadRequest(err):
+		return CategoryBadRequest
+	case IsClientError(err):
+		return CategoryClientError
+	}
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) && responseError.StatusCode >= http.StatusInternalServerError {
+		return CategoryServerError
+	}
+	return CategoryUnknown
+}
@@ -7,9 +7,15 @@ package azureerrors
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 )
 
 func TestIsNotFound(t *testing.T) {
@@ -196,3 +202,275 @@ func TestIsForbidden(t *testing.T) {
 		})
 	}
 }
+
+func TestIsBadRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "not azure error",
+			err:  errors.New("not azure error"),
+			want: false,
+		},
+		{
+			name: "bad request error",
+			err:  &azcore.ResponseError{StatusCode: 400},
+			want: true,
+		},
+		{
+			name: "conflict error",
+			err:  &azcore.ResponseError{StatusCode: 409},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := IsBadRequest(tc.err)
+			if got != tc.want {
+				t.Errorf("IsBadRequest() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsUnrecoverable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "not azure error",
+			err:  errors.New("not azure error"),
+			want: false,
+		},
+		{
+			name: "unauthorized error",
+			err:  &azcore.ResponseError{StatusCode: 401},
+			want: true,
+		},
+		{
+			name: "wrapped unauthorized error",
+			err:  fmt.Errorf("failed to get profile: %w", &azcore.ResponseError{StatusCode: 401}),
+			want: true,
+		},
+		{
+			name: "subscription not found error",
+			err:  &azcore.ResponseError{StatusCode: 404, ErrorCode: "SubscriptionNotFound"},
+			want: true,
+		},
+		{
+			name: "disabled subscription error",
+			err:  &azcore.ResponseError{StatusCode: 409, ErrorCode: "ReadOnlyDisabledSubscription"},
+			want: true,
+		},
+		{
+			name: "forbidden error",
+			err:  &azcore.ResponseError{StatusCode: 403, ErrorCode: "AuthorizationFailed"},
+			want: false,
+		},
+		{
+			name: "internal server error",
+			err:  &azcore.ResponseError{StatusCode: 500},
+			want: false,
+		},
+		{
+			name: "authentication failed error",
+			err:  &azidentity.AuthenticationFailedError{RawResponse: &http.Response{StatusCode: 401}},
+			want: true,
+		},
+		{
+			name: "authentication failed error without response",
+			err:  &azidentity.AuthenticationFailedError{},
+			want: false,
+		},
+		{
+			name: "authentication failed error with server error",
+			err:  &azidentity.AuthenticationFailedError{RawResponse: &http.Response{StatusCode: 503}},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := IsUnrecoverable(tc.err)
+			if got != tc.want {
+				t.Errorf("IsUnrecoverable() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsAuthorizationFailed(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "not azure error",
+			err:  errors.New("not azure error"),
+			want: false,
+		},
+		{
+			name: "authorization failed error",
+			err:  &azcore.ResponseError{StatusCode: 403, ErrorCode: "AuthorizationFailed"},
+			want: true,
+		},
+		{
+			name: "wrapped linked authorization failed error",
+			err:  fmt.Errorf("failed to create endpoint: %w", &azcore.ResponseError{StatusCode: 403, ErrorCode: "LinkedAuthorizationFailed"}),
+			want: true,
+		},
+		{
+			name: "forbidden error with other error code",
+			err:  &azcore.ResponseError{StatusCode: 403, ErrorCode: "RequestDisallowedByPolicy"},
+			want: false,
+		},
+		{
+			name: "bad request error",
+			err:  &azcore.ResponseError{StatusCode: 400, ErrorCode: "AuthorizationFailed"},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := IsAuthorizationFailed(tc.err)
+			if got != tc.want {
+				t.Errorf("IsAuthorizationFailed() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizationFailedDetails(t *testing.T) {
+	newResponseError := func(code, message string) error {
+		body := fmt.Sprintf(`{"error":{"code":%q,"message":%q}}`, code, message)
+		return runtime.NewResponseError(&http.Response{
+			StatusCode: http.StatusForbidden,
+			Status:     "403 Forbidden",
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		})
+	}
+	tests := []struct {
+		name       string
+		err        error
+		wantAction string
+		wantScope  string
+	}{
+		{
+			name: "authorization failed error",
+			err: newResponseError("AuthorizationFailed",
+				"The client 'client-id' with object id 'object-id' does not have authorization to perform action 'Microsoft.Network/trafficManagerProfiles/azureEndpoints/write' over scope '/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/trafficManagerProfiles/profile/azureEndpoints/endpoint' or the scope is invalid. If access was recently granted, please refresh your credentials."),
+			wantAction: "Microsoft.Network/trafficManagerProfiles/azureEndpoints/write",
+			wantScope:  "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/trafficManagerProfiles/profile/azureEndpoints/endpoint",
+		},
+		{
+			name: "linked authorization failed error",
+			err: newResponseError("LinkedAuthorizationFailed",
+				"The client 'client-id' with object id 'object-id' has permission to perform action 'Microsoft.Network/trafficManagerProfiles/azureEndpoints/write' on scope '/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/trafficManagerProfiles/profile/azureEndpoints/endpoint'; however, it does not have permission to perform action(s) 'Microsoft.Network/publicIPAddresses/read' on the linked scope(s) '/subscriptions/sub/resourceGroups/member-rg/providers/Microsoft.Network/publicIPAddresses/ip' (respectively) or the linked scope(s) are invalid."),
+			wantAction: "Microsoft.Network/publicIPAddresses/read",
+			wantScope:  "/subscriptions/sub/resourceGroups/member-rg/providers/Microsoft.Network/publicIPAddresses/ip",
+		},
+		{
+			name: "authorization failed error without message",
+			err:  &azcore.ResponseError{StatusCode: 403, ErrorCode: "AuthorizationFailed"},
+		},
+		{
+			name: "not authorization failed error",
+			err:  newResponseError("RequestDisallowedByPolicy", "perform action 'write' over scope 'scope'"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotAction, gotScope := AuthorizationFailedDetails(tc.err)
+			if gotAction != tc.wantAction || gotScope != tc.wantScope {
+				t.Errorf("AuthorizationFailedDetails() = (%q, %q), want (%q, %q)", gotAction, gotScope, tc.wantAction, tc.wantScope)
+			}
+		})
+	}
+}
+
+func TestCategory(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "not azure error",
+			err:  errors.New("not azure error"),
+			want: CategoryUnknown,
+		},
+		{
+			name: "authorization failed error",
+			err:  &azcore.ResponseError{StatusCode: 403, ErrorCode: "AuthorizationFailed"},
+			want: CategoryAuthorizationFailed,
+		},
+		{
+			name: "unrecoverable error",
+			err:  &azcore.ResponseError{StatusCode: 401},
+			want: CategoryUnrecoverable,
+		},
+		{
+			name: "throttled error",
+			err:  fmt.Errorf("wrapped: %w", &azcore.ResponseError{StatusCode: 429}),
+			want: CategoryThrottled,
+		},
+		{
+			name: "not found error",
+			err:  &azcore.ResponseError{StatusCode: 404},
+			want: CategoryNotFound,
+		},
+		{
+			name: "conflict error",
+			err:  &azcore.ResponseError{StatusCode: 409},
+			want: CategoryConflict,
+		},
+		{
+			name: "bad request error",
+			err:  &azcore.ResponseError{StatusCode: 400},
+			want: CategoryBadRequest,
+		},
+		{
+			name: "forbidden error without the authorization failed code",
+			err:  &azcore.ResponseError{StatusCode: 403},
+			want: CategoryClientError,
+		},
+		{
+			name: "internal server error",
+			err:  &azcore.ResponseError{StatusCode: 500},
+			want: CategoryServerError,
+		},
+		{
+			name: "service unavailable error",
+			err:  &azcore.ResponseError{StatusCode: 503},
+			want: CategoryServerError,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Category(tc.err)
+			if got != tc.want {
+				t.Errorf("Category() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -14,6 +14,10 @@ import (
 
 // SetDefaultsTrafficManagerProfile sets the default values for TrafficManagerProfile.
 func SetDefaultsTrafficManagerProfile(obj *fleetnetv1beta1.TrafficManagerProfile) {
+	if obj.Spec.RoutingMethod == "" {
+		obj.Spec.RoutingMethod = fleetnetv1beta1.TrafficManagerRoutingMethodWeighted
+	}
+
 	if obj.Spec.MonitorConfig == nil {
 		obj.Spec.MonitorConfig = &fleetnetv1beta1.MonitorConfig{}
 	}
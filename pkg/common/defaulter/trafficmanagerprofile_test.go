@@ -27,6 +27,7 @@ func TestSetTrafficManagerProfile(t *testing.T) {
 			},
 			want: &fleetnetv1beta1.TrafficManagerProfile{
 				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
 					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
 						IntervalInSeconds:         ptr.To(int64(30)),
 						Path:                      ptr.To("/"),
@@ -49,6 +50,7 @@ func TestSetTrafficManagerProfile(t *testing.T) {
 			},
 			want: &fleetnetv1beta1.TrafficManagerProfile{
 				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
 					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
 						IntervalInSeconds:         ptr.To(int64(10)),
 						Path:                      ptr.To("/"),
@@ -75,6 +77,7 @@ func TestSetTrafficManagerProfile(t *testing.T) {
 			},
 			want: &fleetnetv1beta1.TrafficManagerProfile{
 				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
 					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
 						IntervalInSeconds:         ptr.To(int64(40)),
 						Path:                      ptr.To("/healthz"),
@@ -100,6 +103,7 @@ func TestSetTrafficManagerProfile(t *testing.T) {
 			},
 			want: &fleetnetv1beta1.TrafficManagerProfile{
 				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
 					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
 						IntervalInSeconds:         ptr.To(int64(30)),
 						Path:                      ptr.To("/healthz"),
@@ -115,6 +119,7 @@ func TestSetTrafficManagerProfile(t *testing.T) {
 			name: "TrafficManagerProfile with values",
 			obj: &fleetnetv1beta1.TrafficManagerProfile{
 				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodGeographic,
 					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
 						IntervalInSeconds:         ptr.To(int64(10)),
 						Path:                      ptr.To("/healthz"),
@@ -127,6 +132,7 @@ func TestSetTrafficManagerProfile(t *testing.T) {
 			},
 			want: &fleetnetv1beta1.TrafficManagerProfile{
 				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodGeographic,
 					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
 						IntervalInSeconds:         ptr.To(int64(10)),
 						Path:                      ptr.To("/healthz"),
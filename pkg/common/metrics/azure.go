@@ -0,0 +1,83 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"go.goms.io/fleet-networking/pkg/common/azureerrors"
+)
+
+func init() {
+	// Register the Azure API call metrics with the controller runtime global metrics registry.
+	ctrlmetrics.Registry.MustRegister(AzureAPICallDurationSeconds, AzureAPICallsTotal)
+}
+
+// Azure API clients used as the "client" label of the Azure API call metrics.
+const (
+	AzureAPIClientProfiles  = "profiles"
+	AzureAPIClientEndpoints = "endpoints"
+)
+
+// Azure API operations used as the "operation" label of the Azure API call metrics.
+const (
+	AzureAPIOperationGet            = "get"
+	AzureAPIOperationCreateOrUpdate = "createOrUpdate"
+	AzureAPIOperationDelete         = "delete"
+	AzureAPIOperationList           = "list"
+)
+
+// Azure API call outcomes used as the "outcome" label of the Azure API call metrics.
+const (
+	AzureAPIOutcomeSuccess     = "success"
+	AzureAPIOutcomeThrottled   = "throttled"
+	AzureAPIOutcomeClientError = "client_error"
+	// AzureAPIOutcomeServerError is used for the 5xx errors returned by the Azure server and the failures which
+	// do not have a response at all, such as the network errors.
+	AzureAPIOutcomeServerError = "server_error"
+)
+
+var (
+	// AzureAPICallDurationSeconds is a prometheus metric that holds the duration of the Azure API calls in seconds.
+	AzureAPICallDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: MetricsNamespace,
+		Subsystem: MetricsSubsystem,
+		Name:      "azure_api_call_duration_seconds",
+		Help:      "Duration of the Azure API calls in seconds",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"client", "operation"})
+
+	// AzureAPICallsTotal is a prometheus metric that counts the Azure API calls by the outcome.
+	AzureAPICallsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Subsystem: MetricsSubsystem,
+		Name:      "azure_api_calls_total",
+		Help:      "Total number of the Azure API calls by the outcome",
+	}, []string{"client", "operation", "outcome"})
+)
+
+// ObserveAzureAPICall records the duration and the outcome of an Azure API call which starts at the startTime and
+// returns the err.
+func ObserveAzureAPICall(client, operation string, startTime time.Time, err error) {
+	AzureAPICallDurationSeconds.WithLabelValues(client, operation).Observe(time.Since(startTime).Seconds())
+	AzureAPICallsTotal.WithLabelValues(client, operation, azureAPICallOutcome(err)).Inc()
+}
+
+func azureAPICallOutcome(err error) string {
+	switch {
+	case err == nil:
+		return AzureAPIOutcomeSuccess
+	case azureerrors.IsThrottled(err):
+		return AzureAPIOutcomeThrottled
+	case azureerrors.IsClientError(err):
+		return AzureAPIOutcomeClientError
+	default:
+		return AzureAPIOutcomeServerError
+	}
+}
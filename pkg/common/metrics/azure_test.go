@@ -0,0 +1,60 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+func TestAzureAPICallOutcome(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "nil error",
+			err:  nil,
+			want: AzureAPIOutcomeSuccess,
+		},
+		{
+			name: "throttled error",
+			err:  &azcore.ResponseError{StatusCode: 429},
+			want: AzureAPIOutcomeThrottled,
+		},
+		{
+			name: "wrapped throttled error",
+			err:  fmt.Errorf("failed to delete: %w", &azcore.ResponseError{StatusCode: 429}),
+			want: AzureAPIOutcomeThrottled,
+		},
+		{
+			name: "not found error",
+			err:  &azcore.ResponseError{StatusCode: 404},
+			want: AzureAPIOutcomeClientError,
+		},
+		{
+			name: "internal server error",
+			err:  &azcore.ResponseError{StatusCode: 500},
+			want: AzureAPIOutcomeServerError,
+		},
+		{
+			name: "not azure error",
+			err:  errors.New("not azure error"),
+			want: AzureAPIOutcomeServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := azureAPICallOutcome(tt.err); got != tt.want {
+				t.Errorf("azureAPICallOutcome() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
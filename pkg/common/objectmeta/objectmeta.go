@@ -8,6 +8,7 @@ package objectmeta
 
 import (
 	"fmt"
+	"net/netip"
 	"strconv"
 	"strings"
 
@@ -57,8 +58,91 @@ const (
 	ExportedObjectAnnotationUniqueName = fleetNetworkingPrefix + "fleet-unique-name"
 
 	// ServiceExportAnnotationWeight is an annotation that marks the weight of the ServiceExport.
+	// The value is either an absolute weight in the range [0, 1000] (for example, "100") or a percentage of the
+	// TrafficManagerBackend weight in the range [0, 100] with the "%" suffix (for example, "30%").
 	ServiceExportAnnotationWeight = fleetNetworkingPrefix + "weight"
 
+	// ServiceExportAnnotationMinWeightPercentage is an annotation that marks the minimum percentage of the
+	// TrafficManagerBackend weight assigned to the ServiceExport, in the range [0, 100] with an optional "%" suffix
+	// (for example, "10%"). The weights of the other exported services are reduced to satisfy the minimum.
+	ServiceExportAnnotationMinWeightPercentage = fleetNetworkingPrefix + "min-weight-percentage"
+
+	// ServiceExportAnnotationMaxWeightPercentage is an annotation that marks the maximum percentage of the
+	// TrafficManagerBackend weight assigned to the ServiceExport, in the range [0, 100] with an optional "%" suffix
+	// (for example, "50%"). The weight exceeding the maximum is given to the other exported services.
+	ServiceExportAnnotationMaxWeightPercentage = fleetNetworkingPrefix + "max-weight-percentage"
+
+	// ServiceExportAnnotationGeoMapping is an annotation that marks the comma-separated list of geographic regions
+	// (for example, "GEO-EU,US-CA") whose DNS queries should be routed to the exported service when the Traffic Manager
+	// profile uses the "Geographic" routing method. The annotation is copied from the ServiceExport to the
+	// InternalServiceExport.
+	// A region cannot be mapped to more than one endpoint of the same profile. Only the regions with the same code are
+	// detected by the fleet controllers; a region contained by another one (for example, "DE" is part of "GEO-EU") is
+	// rejected by the Azure Traffic Manager when the endpoint is created or updated.
+	// https://learn.microsoft.com/en-us/azure/traffic-manager/traffic-manager-geographic-regions
+	ServiceExportAnnotationGeoMapping = fleetNetworkingPrefix + "geo-mapping"
+
+	// ServiceExportAnnotationSubnets is an annotation that marks the comma-separated list of subnets in the CIDR notation
+	// (for example, "10.1.0.0/16,2001:db8::/32") whose DNS queries should be routed to the exported service when the
+	// Traffic Manager profile uses the "Subnet" routing method. The annotation is copied from the ServiceExport to the
+	// InternalServiceExport, and is overridden by the clusterSubnets of the TrafficManagerBackend.
+	// A subnet cannot overlap with the subnets of the other endpoints of the same profile.
+	// https://learn.microsoft.com/en-us/azure/traffic-manager/traffic-manager-routing-methods#subnet-traffic-routing-method
+	ServiceExportAnnotationSubnets = fleetNetworkingPrefix + "subnets"
+
+	// ServiceExportAnnotationPriority is an annotation that marks the priority of the ServiceExport when the Traffic
+	// Manager profile uses the "Priority" routing method. The endpoint with the lowest value has the highest priority.
+	ServiceExportAnnotationPriority = fleetNetworkingPrefix + "priority"
+
+	// ServiceExportAnnotationExternalTargetFQDN is an annotation that marks the fully-qualified domain name which fronts
+	// the exported service, for example, a shared ingress. The service is exposed as an Azure Traffic Manager external
+	// endpoint targeting the domain name instead of the public IP address of the load balancer.
+	ServiceExportAnnotationExternalTargetFQDN = fleetNetworkingPrefix + "external-target-fqdn"
+
+	// ServiceExportAnnotationExternalTargetIP is an annotation that marks the public IP address which fronts the exported
+	// service. The service is exposed as an Azure Traffic Manager external endpoint targeting the IP address instead of
+	// the public IP address resource of the load balancer.
+	ServiceExportAnnotationExternalTargetIP = fleetNetworkingPrefix + "external-target-ip"
+
+	// ServiceExportAnnotationExternalTargetLocation is an annotation that marks the Azure region closest to the external
+	// target of the exported service, which is used as the location of the Azure Traffic Manager external endpoint.
+	ServiceExportAnnotationExternalTargetLocation = fleetNetworkingPrefix + "external-target-location"
+
+	// ServiceExportAnnotationAlwaysServe is an annotation that keeps the Azure Traffic Manager endpoint of the
+	// ServiceExport in rotation regardless of its health checks when the value is "true", so that the endpoint can be
+	// used as the last resort when all the other endpoints are unhealthy. The annotation is copied from the
+	// ServiceExport to the InternalServiceExport.
+	// https://learn.microsoft.com/en-us/azure/traffic-manager/traffic-manager-monitoring#always-serve
+	ServiceExportAnnotationAlwaysServe = fleetNetworkingPrefix + "always-serve"
+
+	// ServiceExportAnnotationWeightByReadyEndpoints is an annotation that uses the number of the ready endpoints of the
+	// exported Service, instead of the weight annotation, as the weight of its Azure Traffic Manager endpoint when the
+	// value is "true", so that the traffic is distributed across the clusters in proportion to their capacities.
+	ServiceExportAnnotationWeightByReadyEndpoints = fleetNetworkingPrefix + "weight-by-ready-endpoints"
+
+	// InternalServiceExportAnnotationEndpointDisabled is an annotation that marks the Azure Traffic Manager endpoint
+	// of the InternalServiceExport as disabled when the value is "true", so that the traffic is drained from the member
+	// cluster while the endpoint is kept in the Azure Traffic Manager profile.
+	InternalServiceExportAnnotationEndpointDisabled = fleetNetworkingPrefix + "endpoint-disabled"
+
+	// TrafficManagerBackendAnnotationAdoptEndpoints is an annotation that allows the TrafficManagerBackend to adopt the
+	// pre-existing Azure Traffic Manager endpoints which are not created by the fleet controllers when the value is
+	// "true", for example, when migrating a manually-created Azure Traffic Manager profile into the fleet management.
+	// An endpoint targeting the same Azure resource (or the same external target) as a desired endpoint of the backend
+	// is deleted and recreated under the name managed by the fleet controllers.
+	TrafficManagerBackendAnnotationAdoptEndpoints = fleetNetworkingPrefix + "adopt-endpoints"
+
+	// TrafficManagerBackendAnnotationDrainGracePeriod is an annotation that marks how long the Azure Traffic Manager
+	// endpoints of the TrafficManagerBackend are disabled before being deleted when the backend is deleted, in the format
+	// of the Go duration (for example, "5m"), so that the DNS resolvers stop returning the endpoints once the DNS TTL
+	// expires before the endpoints are gone.
+	TrafficManagerBackendAnnotationDrainGracePeriod = fleetNetworkingPrefix + "drain-grace-period"
+
+	// TrafficManagerProfileAnnotationForceResync is an annotation whose change (for example, to the current timestamp)
+	// re-triggers the reconciliation of all the TrafficManagerBackends referencing the TrafficManagerProfile, so that
+	// their Azure Traffic Manager endpoints can be re-synced without editing each backend.
+	TrafficManagerProfileAnnotationForceResync = fleetNetworkingPrefix + "force-resync"
+
 	// ServiceAnnotationAzureLoadBalancerInternal is an annotation that marks the Service as an internal load balancer by cloud-provider-azure.
 	ServiceAnnotationAzureLoadBalancerInternal = "service.beta.kubernetes.io/azure-load-balancer-internal"
 
@@ -78,9 +162,15 @@ var (
 	// AzureTrafficManagerProfileTagKey is the key of the Azure Traffic Manager profile tag when the controller creates it.
 	// Note: The tag name cannot have reserved characters '<,>,%,&,\\,?,/' or control characters.
 	AzureTrafficManagerProfileTagKey = strings.ReplaceAll(fleetNetworkingPrefix, "/", ".") + "trafficManagerProfile"
+
+	// AzureTrafficManagerProfileManagedTagsKey is the key of the Azure Traffic Manager profile tag whose value is the
+	// comma-separated names of the tags configured by the trafficManagerProfile, so that the controller can remove the
+	// tags which are no longer configured.
+	AzureTrafficManagerProfileManagedTagsKey = strings.ReplaceAll(fleetNetworkingPrefix, "/", ".") + "managedTags"
 )
 
 // ExtractWeightFromServiceExport gets the weight from the serviceExport annotation and validates it.
+// When the annotation is a percentage, the percentage value is returned and IsWeightPercentage reports true.
 func ExtractWeightFromServiceExport(svcExport *fleetnetv1beta1.ServiceExport) (int64, error) {
 	serviceKObj := klog.KObj(svcExport)
 	// Setup the weightAnno for the exported service on the hub cluster.
@@ -88,6 +178,21 @@ func ExtractWeightFromServiceExport(svcExport *fleetnetv1beta1.ServiceExport) (i
 	if !found {
 		return int64(1), nil
 	}
+	if percentageAnno, ok := strings.CutSuffix(weightAnno, "%"); ok {
+		// The percentage should be in the range [0, 100].
+		percentage, err := strconv.Atoi(percentageAnno)
+		if err != nil {
+			err = fmt.Errorf("the weight annotation is not a valid percentage: %s", weightAnno)
+			klog.ErrorS(err, "Failed to parse the weight annotation", "serviceExport", serviceKObj)
+			return -1, err
+		}
+		if percentage < 0 || percentage > 100 {
+			err = fmt.Errorf("the weight annotation is not in the range [0%%, 100%%]: %s", weightAnno)
+			klog.ErrorS(err, "The weight annotation is out of range", "serviceExport", serviceKObj)
+			return -1, err
+		}
+		return int64(percentage), nil
+	}
 	// check if the weightAnno on the serviceExport in the member cluster is valid
 	// The value should be in the range [0, 1000].
 	weight, err := strconv.Atoi(weightAnno)
@@ -103,3 +208,182 @@ func ExtractWeightFromServiceExport(svcExport *fleetnetv1beta1.ServiceExport) (i
 	}
 	return int64(weight), nil
 }
+
+// IsWeightPercentage returns true if the weight annotation of the serviceExport is a percentage of the
+// TrafficManagerBackend weight.
+func IsWeightPercentage(svcExport *fleetnetv1beta1.ServiceExport) bool {
+	return strings.HasSuffix(svcExport.Annotations[ServiceExportAnnotationWeight], "%")
+}
+
+// ExtractWeightBoundsFromServiceExport gets the minimum and maximum weight percentages from the serviceExport
+// annotations and validates them.
+// It returns nil for the bound whose annotation is not set.
+func ExtractWeightBoundsFromServiceExport(svcExport *fleetnetv1beta1.ServiceExport) (*int64, *int64, error) {
+	minPercentage, err := extractWeightPercentageBound(svcExport, ServiceExportAnnotationMinWeightPercentage)
+	if err != nil {
+		return nil, nil, err
+	}
+	maxPercentage, err := extractWeightPercentageBound(svcExport, ServiceExportAnnotationMaxWeightPercentage)
+	if err != nil {
+		return nil, nil, err
+	}
+	if minPercentage != nil && maxPercentage != nil && *minPercentage > *maxPercentage {
+		err = fmt.Errorf("the minimum weight percentage %d%% is greater than the maximum weight percentage %d%%", *minPercentage, *maxPercentage)
+		klog.ErrorS(err, "The weight bound annotations are inconsistent", "serviceExport", klog.KObj(svcExport))
+		return nil, nil, err
+	}
+	return minPercentage, maxPercentage, nil
+}
+
+func extractWeightPercentageBound(svcExport *fleetnetv1beta1.ServiceExport, annotation string) (*int64, error) {
+	boundAnno, found := svcExport.Annotations[annotation]
+	if !found {
+		return nil, nil
+	}
+	// The percentage should be in the range [0, 100].
+	percentage, err := strconv.Atoi(strings.TrimSuffix(boundAnno, "%"))
+	if err != nil {
+		err = fmt.Errorf("the %s annotation is not a valid percentage: %s", annotation, boundAnno)
+		klog.ErrorS(err, "Failed to parse the weight bound annotation", "serviceExport", klog.KObj(svcExport))
+		return nil, err
+	}
+	if percentage < 0 || percentage > 100 {
+		err = fmt.Errorf("the %s annotation is not in the range [0%%, 100%%]: %s", annotation, boundAnno)
+		klog.ErrorS(err, "The weight bound annotation is out of range", "serviceExport", klog.KObj(svcExport))
+		return nil, err
+	}
+	p := int64(percentage)
+	return &p, nil
+}
+
+// ExtractPriorityFromServiceExport gets the priority from the serviceExport annotation and validates it.
+// It returns nil when the annotation is not set.
+func ExtractPriorityFromServiceExport(svcExport *fleetnetv1beta1.ServiceExport) (*int64, error) {
+	serviceKObj := klog.KObj(svcExport)
+	priorityAnno, found := svcExport.Annotations[ServiceExportAnnotationPriority]
+	if !found {
+		return nil, nil
+	}
+	// check if the priorityAnno on the serviceExport in the member cluster is valid
+	// The value should be in the range [1, 1000].
+	priority, err := strconv.Atoi(priorityAnno)
+	if err != nil {
+		err = fmt.Errorf("the priority annotation is not a valid integer: %s", priorityAnno)
+		klog.ErrorS(err, "Failed to parse the priority annotation", "serviceExport", serviceKObj)
+		return nil, err
+	}
+	if priority < 1 || priority > 1000 {
+		err = fmt.Errorf("the priority annotation is not in the range [1, 1000]: %s", priorityAnno)
+		klog.ErrorS(err, "The priority annotation is out of range", "serviceExport", serviceKObj)
+		return nil, err
+	}
+	p := int64(priority)
+	return &p, nil
+}
+
+// ExtractGeoMappingFromServiceExport gets the geo mapping from the serviceExport annotation and validates it.
+// It returns the normalized comma-separated region codes, or an empty string when the annotation is not set.
+func ExtractGeoMappingFromServiceExport(svcExport *fleetnetv1beta1.ServiceExport) (string, error) {
+	serviceKObj := klog.KObj(svcExport)
+	geoMappingAnno, found := svcExport.Annotations[ServiceExportAnnotationGeoMapping]
+	if !found {
+		return "", nil
+	}
+	if len(strings.TrimSpace(geoMappingAnno)) == 0 {
+		err := fmt.Errorf("the geo mapping annotation is empty: %q", geoMappingAnno)
+		klog.ErrorS(err, "Failed to parse the geo mapping annotation", "serviceExport", serviceKObj)
+		return "", err
+	}
+	codes := strings.Split(geoMappingAnno, ",")
+	for i := range codes {
+		codes[i] = strings.TrimSpace(codes[i])
+		if len(codes[i]) == 0 {
+			err := fmt.Errorf("the geo mapping annotation contains an empty region code: %q", geoMappingAnno)
+			klog.ErrorS(err, "Failed to parse the geo mapping annotation", "serviceExport", serviceKObj)
+			return "", err
+		}
+	}
+	return strings.Join(codes, ","), nil
+}
+
+// ExtractSubnetsFromServiceExport gets the subnets from the serviceExport annotation and validates them.
+// It returns the normalized comma-separated subnets, or an empty string when the annotation is not set.
+func ExtractSubnetsFromServiceExport(svcExport *fleetnetv1beta1.ServiceExport) (string, error) {
+	subnetsAnno, found := svcExport.Annotations[ServiceExportAnnotationSubnets]
+	if !found {
+		return "", nil
+	}
+	if len(strings.TrimSpace(subnetsAnno)) == 0 {
+		err := fmt.Errorf("the subnets annotation is empty: %q", subnetsAnno)
+		klog.ErrorS(err, "Failed to parse the subnets annotation", "serviceExport", klog.KObj(svcExport))
+		return "", err
+	}
+	subnets, err := ParseSubnets(strings.Split(subnetsAnno, ","))
+	if err != nil {
+		err = fmt.Errorf("the subnets annotation is invalid: %w", err)
+		klog.ErrorS(err, "Failed to parse the subnets annotation", "serviceExport", klog.KObj(svcExport))
+		return "", err
+	}
+	res := make([]string, 0, len(subnets))
+	for _, subnet := range subnets {
+		res = append(res, subnet.String())
+	}
+	return strings.Join(res, ","), nil
+}
+
+// ParseSubnets parses the subnets in the CIDR notation, for example, "10.1.0.0/16", and drops the duplicates.
+// It returns an error when a subnet is not in the CIDR notation or its address has the bits set outside the prefix
+// (for example, "10.1.2.3/16"), which is likely a typo.
+func ParseSubnets(subnets []string) ([]netip.Prefix, error) {
+	res := make([]netip.Prefix, 0, len(subnets))
+	seen := make(map[netip.Prefix]bool, len(subnets))
+	for _, s := range subnets {
+		s = strings.TrimSpace(s)
+		subnet, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("subnet %q is not in the CIDR notation, for example, 10.1.0.0/16", s)
+		}
+		if masked := subnet.Masked(); masked != subnet {
+			return nil, fmt.Errorf("subnet %q has the address bits set outside the prefix, did you mean %q", s, masked)
+		}
+		if seen[subnet] {
+			continue
+		}
+		seen[subnet] = true
+		res = append(res, subnet)
+	}
+	return res, nil
+}
+
+// ExtractAlwaysServeFromServiceExport gets the always serve setting from the serviceExport annotation and validates it.
+// It returns false when the annotation is not set.
+func ExtractAlwaysServeFromServiceExport(svcExport *fleetnetv1beta1.ServiceExport) (bool, error) {
+	alwaysServeAnno, found := svcExport.Annotations[ServiceExportAnnotationAlwaysServe]
+	if !found {
+		return false, nil
+	}
+	alwaysServe, err := strconv.ParseBool(alwaysServeAnno)
+	if err != nil {
+		err = fmt.Errorf("the always serve annotation is not a valid boolean: %s", alwaysServeAnno)
+		klog.ErrorS(err, "Failed to parse the always serve annotation", "serviceExport", klog.KObj(svcExport))
+		return false, err
+	}
+	return alwaysServe, nil
+}
+
+// ExtractWeightByReadyEndpointsFromServiceExport gets the weight by ready endpoints setting from the serviceExport
+// annotation and validates it.
+// It returns false when the annotation is not set.
+func ExtractWeightByReadyEndpointsFromServiceExport(svcExport *fleetnetv1beta1.ServiceExport) (bool, error) {
+	anno, found := svcExport.Annotations[ServiceExportAnnotationWeightByReadyEndpoints]
+	if !found {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(anno)
+	if err != nil {
+		err = fmt.Errorf("the weight by ready endpoints annotation is not a valid boolean: %s", anno)
+		klog.ErrorS(err, "Failed to parse the weight by ready endpoints annotation", "serviceExport", klog.KObj(svcExport))
+		return false, err
+	}
+	return enabled, nil
+}
@@ -8,7 +8,9 @@ package objectmeta
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
 )
@@ -20,6 +22,13 @@ func TestAzureTrafficManagerProfileTagKey(t *testing.T) {
 	}
 }
 
+func TestAzureTrafficManagerProfileManagedTagsKey(t *testing.T) {
+	want := "networking.fleet.azure.com.managedTags"
+	if got := AzureTrafficManagerProfileManagedTagsKey; got != want {
+		t.Errorf("AzureTrafficManagerProfileManagedTagsKey = %v, want %v", got, want)
+	}
+}
+
 func TestExtractWeightFromServiceExport(t *testing.T) {
 	testCases := []struct {
 		name       string
@@ -100,6 +109,50 @@ func TestExtractWeightFromServiceExport(t *testing.T) {
 			},
 			wantError: true,
 		},
+		{
+			name: "valid percentage weight annotation",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationWeight: "30%",
+					},
+				},
+			},
+			wantWeight: 30,
+		},
+		{
+			name: "test 0% is valid weight annotation",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationWeight: "0%",
+					},
+				},
+			},
+			wantWeight: 0,
+		},
+		{
+			name: "invalid percentage weight annotation (non-integer)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationWeight: "12.5%",
+					},
+				},
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid percentage weight annotation (out of range)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationWeight: "101%",
+					},
+				},
+			},
+			wantError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -114,3 +167,514 @@ func TestExtractWeightFromServiceExport(t *testing.T) {
 		})
 	}
 }
+
+func TestIsWeightPercentage(t *testing.T) {
+	testCases := []struct {
+		name      string
+		svcExport *fleetnetv1beta1.ServiceExport
+		want      bool
+	}{
+		{
+			name: "annotation is missing",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{},
+			},
+			want: false,
+		},
+		{
+			name: "absolute weight",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationWeight: "30",
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "percentage weight",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationWeight: "30%",
+					},
+				},
+			},
+			want: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsWeightPercentage(tc.svcExport); got != tc.want {
+				t.Errorf("IsWeightPercentage() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtractGeoMappingFromServiceExport(t *testing.T) {
+	testCases := []struct {
+		name           string
+		svcExport      *fleetnetv1beta1.ServiceExport
+		wantGeoMapping string
+		wantError      bool
+	}{
+		{
+			name: "empty geo mapping when annotation is missing",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{},
+			},
+			wantGeoMapping: "",
+		},
+		{
+			name: "valid geo mapping annotation",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationGeoMapping: "GEO-EU,US-CA",
+					},
+				},
+			},
+			wantGeoMapping: "GEO-EU,US-CA",
+		},
+		{
+			name: "valid geo mapping annotation with spaces",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationGeoMapping: " GEO-EU , US-CA ",
+					},
+				},
+			},
+			wantGeoMapping: "GEO-EU,US-CA",
+		},
+		{
+			name: "invalid geo mapping annotation (empty)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationGeoMapping: "",
+					},
+				},
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid geo mapping annotation (whitespace only)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationGeoMapping: "  ",
+					},
+				},
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid geo mapping annotation (empty region code)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationGeoMapping: "GEO-EU, ,US-CA",
+					},
+				},
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotGeoMapping, err := ExtractGeoMappingFromServiceExport(tc.svcExport)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("ExtractGeoMappingFromServiceExport() error = %v, want %v", err, tc.wantError)
+			}
+			if !tc.wantError && gotGeoMapping != tc.wantGeoMapping {
+				t.Errorf("ExtractGeoMappingFromServiceExport() geoMapping = %q, want %q", gotGeoMapping, tc.wantGeoMapping)
+			}
+		})
+	}
+}
+
+func TestExtractSubnetsFromServiceExport(t *testing.T) {
+	testCases := []struct {
+		name        string
+		svcExport   *fleetnetv1beta1.ServiceExport
+		wantSubnets string
+		wantError   bool
+	}{
+		{
+			name: "empty subnets when annotation is missing",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{},
+			},
+			wantSubnets: "",
+		},
+		{
+			name: "valid subnets annotation",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationSubnets: "10.1.0.0/16,2001:db8::/32",
+					},
+				},
+			},
+			wantSubnets: "10.1.0.0/16,2001:db8::/32",
+		},
+		{
+			name: "valid subnets annotation with spaces and duplicates",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationSubnets: " 10.1.0.0/16 , 10.2.0.0/16,10.1.0.0/16 ",
+					},
+				},
+			},
+			wantSubnets: "10.1.0.0/16,10.2.0.0/16",
+		},
+		{
+			name: "invalid subnets annotation (whitespace only)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationSubnets: "  ",
+					},
+				},
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid subnets annotation (not CIDR)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationSubnets: "10.1.0.0",
+					},
+				},
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid subnets annotation (address bits set outside the prefix)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationSubnets: "10.1.2.3/16",
+					},
+				},
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid subnets annotation (empty subnet)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationSubnets: "10.1.0.0/16, ,10.2.0.0/16",
+					},
+				},
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotSubnets, err := ExtractSubnetsFromServiceExport(tc.svcExport)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("ExtractSubnetsFromServiceExport() error = %v, want %v", err, tc.wantError)
+			}
+			if !tc.wantError && gotSubnets != tc.wantSubnets {
+				t.Errorf("ExtractSubnetsFromServiceExport() subnets = %q, want %q", gotSubnets, tc.wantSubnets)
+			}
+		})
+	}
+}
+
+func TestExtractPriorityFromServiceExport(t *testing.T) {
+	testCases := []struct {
+		name         string
+		svcExport    *fleetnetv1beta1.ServiceExport
+		wantPriority *int64
+		wantError    bool
+	}{
+		{
+			name: "nil priority when annotation is missing",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{},
+			},
+		},
+		{
+			name: "valid priority annotation",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationPriority: "1",
+					},
+				},
+			},
+			wantPriority: ptr.To(int64(1)),
+		},
+		{
+			name: "test 1000 is valid priority annotation",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationPriority: "1000",
+					},
+				},
+			},
+			wantPriority: ptr.To(int64(1000)),
+		},
+		{
+			name: "invalid priority annotation (non-integer)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationPriority: "invalid",
+					},
+				},
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid priority annotation (0 is out of range)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationPriority: "0",
+					},
+				},
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid priority annotation (out of range)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationPriority: "1001",
+					},
+				},
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotPriority, err := ExtractPriorityFromServiceExport(tc.svcExport)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("ExtractPriorityFromServiceExport() error = %v, want %v", err, tc.wantError)
+			}
+			if !tc.wantError && !cmp.Equal(gotPriority, tc.wantPriority) {
+				t.Errorf("ExtractPriorityFromServiceExport() priority = %v, want %v", ptr.Deref(gotPriority, 0), ptr.Deref(tc.wantPriority, 0))
+			}
+		})
+	}
+}
+
+func TestExtractWeightBoundsFromServiceExport(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		wantMin     *int64
+		wantMax     *int64
+		wantError   bool
+	}{
+		{
+			name: "nil bounds when annotations are missing",
+		},
+		{
+			name: "valid minimum weight percentage",
+			annotations: map[string]string{
+				ServiceExportAnnotationMinWeightPercentage: "10",
+			},
+			wantMin: ptr.To(int64(10)),
+		},
+		{
+			name: "valid maximum weight percentage with the percent sign",
+			annotations: map[string]string{
+				ServiceExportAnnotationMaxWeightPercentage: "50%",
+			},
+			wantMax: ptr.To(int64(50)),
+		},
+		{
+			name: "valid minimum and maximum weight percentages",
+			annotations: map[string]string{
+				ServiceExportAnnotationMinWeightPercentage: "10%",
+				ServiceExportAnnotationMaxWeightPercentage: "10%",
+			},
+			wantMin: ptr.To(int64(10)),
+			wantMax: ptr.To(int64(10)),
+		},
+		{
+			name: "invalid minimum weight percentage (non-integer)",
+			annotations: map[string]string{
+				ServiceExportAnnotationMinWeightPercentage: "invalid",
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid maximum weight percentage (out of range)",
+			annotations: map[string]string{
+				ServiceExportAnnotationMaxWeightPercentage: "101%",
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid minimum weight percentage (negative)",
+			annotations: map[string]string{
+				ServiceExportAnnotationMinWeightPercentage: "-1",
+			},
+			wantError: true,
+		},
+		{
+			name: "minimum is greater than maximum",
+			annotations: map[string]string{
+				ServiceExportAnnotationMinWeightPercentage: "60%",
+				ServiceExportAnnotationMaxWeightPercentage: "50%",
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+				},
+			}
+			gotMin, gotMax, err := ExtractWeightBoundsFromServiceExport(svcExport)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("ExtractWeightBoundsFromServiceExport() error = %v, want %v", err, tc.wantError)
+			}
+			if !cmp.Equal(gotMin, tc.wantMin) || !cmp.Equal(gotMax, tc.wantMax) {
+				t.Errorf("ExtractWeightBoundsFromServiceExport() = (%v, %v), want (%v, %v)", ptr.Deref(gotMin, -1), ptr.Deref(gotMax, -1), ptr.Deref(tc.wantMin, -1), ptr.Deref(tc.wantMax, -1))
+			}
+		})
+	}
+}
+
+func TestExtractAlwaysServeFromServiceExport(t *testing.T) {
+	testCases := []struct {
+		name            string
+		svcExport       *fleetnetv1beta1.ServiceExport
+		wantAlwaysServe bool
+		wantError       bool
+	}{
+		{
+			name: "false when annotation is missing",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{},
+			},
+		},
+		{
+			name: "enabled always serve annotation",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationAlwaysServe: "true",
+					},
+				},
+			},
+			wantAlwaysServe: true,
+		},
+		{
+			name: "disabled always serve annotation",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationAlwaysServe: "false",
+					},
+				},
+			},
+		},
+		{
+			name: "invalid always serve annotation",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationAlwaysServe: "enabled",
+					},
+				},
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotAlwaysServe, err := ExtractAlwaysServeFromServiceExport(tc.svcExport)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("ExtractAlwaysServeFromServiceExport() error = %v, want %v", err, tc.wantError)
+			}
+			if gotAlwaysServe != tc.wantAlwaysServe {
+				t.Errorf("ExtractAlwaysServeFromServiceExport() alwaysServe = %v, want %v", gotAlwaysServe, tc.wantAlwaysServe)
+			}
+		})
+	}
+}
+
+func TestExtractWeightByReadyEndpointsFromServiceExport(t *testing.T) {
+	testCases := []struct {
+		name        string
+		svcExport   *fleetnetv1beta1.ServiceExport
+		wantEnabled bool
+		wantError   bool
+	}{
+		{
+			name: "false when annotation is missing",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{},
+			},
+		},
+		{
+			name: "enabled weight by ready endpoints annotation",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationWeightByReadyEndpoints: "true",
+					},
+				},
+			},
+			wantEnabled: true,
+		},
+		{
+			name: "disabled weight by ready endpoints annotation",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationWeightByReadyEndpoints: "false",
+					},
+				},
+			},
+		},
+		{
+			name: "invalid weight by ready endpoints annotation",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						ServiceExportAnnotationWeightByReadyEndpoints: "enabled",
+					},
+				},
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotEnabled, err := ExtractWeightByReadyEndpointsFromServiceExport(tc.svcExport)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("ExtractWeightByReadyEndpointsFromServiceExport() error = %v, want %v", err, tc.wantError)
+			}
+			if gotEnabled != tc.wantEnabled {
+				t.Errorf("ExtractWeightByReadyEndpointsFromServiceExport() enabled = %v, want %v", gotEnabled, tc.wantEnabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+// Package ratelimiter provides the configurable work queue rate limiter shared between networking controllers.
+package ratelimiter
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Mode decides how the failed requests are delayed before being retried.
+type Mode string
+
+const (
+	// ModePerItem delays each request exponentially by its own number of consecutive failures.
+	ModePerItem Mode = "per-item"
+	// ModeOverall limits the rate of the retries across all the requests by a token bucket, regardless of the number
+	// of failures of each request.
+	ModeOverall Mode = "overall"
+	// ModeBoth applies both the per-item and the overall rate limiters and uses the longer delay, which is the
+	// default behavior of the controller-runtime.
+	ModeBoth Mode = "both"
+)
+
+const (
+	// DefaultBaseDelay is the default delay of the first retry of a request in the per-item mode.
+	DefaultBaseDelay = 5 * time.Millisecond
+	// DefaultMaxDelay is the default cap of the per-item exponential delay.
+	DefaultMaxDelay = 1000 * time.Second
+	// DefaultQPS is the default number of retries per second allowed in the overall mode.
+	DefaultQPS = 10
+	// DefaultBurst is the default burst of the retries in the overall mode.
+	DefaultBurst = 100
+)
+
+// Options configures the work queue rate limiter, which delays the requests whose reconciliation returns an error or
+// ctrl.Result{Requeue: true}.
+// The requests returning ctrl.Result{RequeueAfter: d} are requeued after d regardless of the rate limiter, and the
+// per-item failures of a request are forgotten once its reconciliation succeeds.
+type Options struct {
+	// Mode decides how the failed requests are delayed.
+	Mode Mode
+	// BaseDelay is the delay of the first retry of a request in the per-item mode, which is doubled on each
+	// consecutive failure.
+	BaseDelay time.Duration
+	// MaxDelay caps the per-item exponential delay.
+	MaxDelay time.Duration
+	// QPS is the number of retries per second allowed across all the requests in the overall mode.
+	QPS float64
+	// Burst is the maximum burst of the retries in the overall mode.
+	Burst int
+}
+
+// Validate returns an error if the options are invalid.
+func (o *Options) Validate() error {
+	switch o.Mode {
+	case ModePerItem, ModeOverall, ModeBoth:
+	default:
+		return fmt.Errorf("invalid rate limiter mode %q, must be one of %q, %q or %q", o.Mode, ModePerItem, ModeOverall, ModeBoth)
+	}
+	if o.Mode != ModeOverall {
+		if o.BaseDelay <= 0 {
+			return fmt.Errorf("invalid base delay %v, must be positive", o.BaseDelay)
+		}
+		if o.MaxDelay < o.BaseDelay {
+			return fmt.Errorf("invalid max delay %v, must not be less than the base delay %v", o.MaxDelay, o.BaseDelay)
+		}
+	}
+	if o.Mode != ModePerItem {
+		if o.QPS <= 0 {
+			return fmt.Errorf("invalid qps %v, must be positive", o.QPS)
+		}
+		if o.Burst < 1 {
+			return fmt.Errorf("invalid burst %d, must be positive", o.Burst)
+		}
+	}
+	return nil
+}
+
+// New creates the work queue rate limiter of the options, which are expected to be validated.
+func New(o Options) workqueue.TypedRateLimiter[reconcile.Request] {
+	perItem := workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](o.BaseDelay, o.MaxDelay)
+	overall := &workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(o.QPS), o.Burst)}
+	switch o.Mode {
+	case ModePerItem:
+		return perItem
+	case ModeOverall:
+		return overall
+	default:
+		return workqueue.NewTypedMaxOfRateLimiter[reconcile.Request](perItem, overall)
+	}
+}
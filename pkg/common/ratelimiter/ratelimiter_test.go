@@ -0,0 +1,167 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		wantErr bool
+	}{
+		{
+			name: "valid both mode",
+			options: Options{
+				Mode:      ModeBoth,
+				BaseDelay: DefaultBaseDelay,
+				MaxDelay:  DefaultMaxDelay,
+				QPS:       DefaultQPS,
+				Burst:     DefaultBurst,
+			},
+		},
+		{
+			name: "valid per-item mode without the overall settings",
+			options: Options{
+				Mode:      ModePerItem,
+				BaseDelay: time.Second,
+				MaxDelay:  time.Second,
+			},
+		},
+		{
+			name: "valid overall mode without the per-item settings",
+			options: Options{
+				Mode:  ModeOverall,
+				QPS:   0.5,
+				Burst: 1,
+			},
+		},
+		{
+			name: "invalid mode",
+			options: Options{
+				Mode:      "invalid",
+				BaseDelay: DefaultBaseDelay,
+				MaxDelay:  DefaultMaxDelay,
+				QPS:       DefaultQPS,
+				Burst:     DefaultBurst,
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero base delay",
+			options: Options{
+				Mode:     ModePerItem,
+				MaxDelay: DefaultMaxDelay,
+			},
+			wantErr: true,
+		},
+		{
+			name: "max delay less than the base delay",
+			options: Options{
+				Mode:      ModePerItem,
+				BaseDelay: time.Minute,
+				MaxDelay:  time.Second,
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero qps",
+			options: Options{
+				Mode:  ModeOverall,
+				Burst: DefaultBurst,
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero burst",
+			options: Options{
+				Mode:      ModeBoth,
+				BaseDelay: DefaultBaseDelay,
+				MaxDelay:  DefaultMaxDelay,
+				QPS:       DefaultQPS,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.options.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("Validate() got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	item := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "name"}}
+	otherItem := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "other"}}
+	tests := []struct {
+		name    string
+		options Options
+		// want is the delays of the consecutive failures of the item, followed by the delay of the other item.
+		want []time.Duration
+	}{
+		{
+			name: "per-item mode",
+			options: Options{
+				Mode:      ModePerItem,
+				BaseDelay: time.Second,
+				MaxDelay:  3 * time.Second,
+			},
+			want: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, time.Second},
+		},
+		{
+			name: "overall mode",
+			options: Options{
+				Mode:  ModeOverall,
+				QPS:   1,
+				Burst: 2,
+			},
+			// the bucket is shared by all the items and refills at 1 token per second
+			want: []time.Duration{0, 0, time.Second, 2 * time.Second},
+		},
+		{
+			name: "both mode",
+			options: Options{
+				Mode:      ModeBoth,
+				BaseDelay: time.Millisecond,
+				MaxDelay:  time.Minute,
+				QPS:       1,
+				Burst:     1,
+			},
+			want: []time.Duration{time.Millisecond, time.Second, 2 * time.Second, 3 * time.Second},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l := New(tc.options)
+			var got []time.Duration
+			for i := 0; i < len(tc.want)-1; i++ {
+				got = append(got, l.When(item))
+			}
+			got = append(got, l.When(otherItem))
+			for i := range tc.want {
+				// the overall bucket refills while the test is running
+				if got[i] > tc.want[i] || got[i] < tc.want[i]-100*time.Millisecond {
+					t.Errorf("When() #%d = %v, want %v", i, got[i], tc.want[i])
+				}
+			}
+			if gotRequeues := l.NumRequeues(item); tc.options.Mode != ModeOverall && gotRequeues != len(tc.want)-1 {
+				t.Errorf("NumRequeues() = %d, want %d", gotRequeues, len(tc.want)-1)
+			}
+			l.Forget(item)
+			if gotRequeues := l.NumRequeues(item); gotRequeues != 0 {
+				t.Errorf("NumRequeues() after Forget() = %d, want 0", gotRequeues)
+			}
+		})
+	}
+}
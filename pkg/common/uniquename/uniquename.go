@@ -8,6 +8,8 @@ Licensed under the MIT license.
 package uniquename
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"unicode"
@@ -28,6 +30,8 @@ const (
 	DNS1035Label Format = 3
 
 	uuidLength = 5
+	// hashLength is the length of the hash suffix appended to the truncated DNS labels.
+	hashLength = 8
 )
 
 // minInt returns the smaller one of two integers.
@@ -205,3 +209,22 @@ func RandomLowerCaseAlphabeticString(n int) string {
 	}
 	return string(b)
 }
+
+// TruncateDNS1123Label returns the name if it is no longer than the max length of a RFC 1123 DNS label (63 characters);
+// otherwise, the name is truncated and suffixed with a hash of the whole name, so that the same long name is always
+// truncated to the same label while the long names sharing a prefix are still distinguished, e.g. a 70 character long
+// name is truncated to its first 54 characters followed by a dash and an 8 character long hash.
+// Note: this function assumes that the input name consists of the characters allowed in RFC 1123 DNS labels only.
+func TruncateDNS1123Label(name string) string {
+	if len(name) <= validation.DNS1123LabelMaxLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	hash := hex.EncodeToString(sum[:])[:hashLength]
+	// The truncated name must not end with a dash as the label cannot have consecutive dashes before the hash.
+	prefix := strings.TrimRight(name[:validation.DNS1123LabelMaxLength-hashLength-1], "-")
+	if prefix == "" {
+		return hash
+	}
+	return prefix + "-" + hash
+}
@@ -9,6 +9,8 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -283,3 +285,58 @@ func TestRandomLowerCaseAlphabeticString(t *testing.T) {
 		})
 	}
 }
+
+// TestTruncateDNS1123Label tests the TruncateDNS1123Label function.
+func TestTruncateDNS1123Label(t *testing.T) {
+	label63 := strings.Repeat("a", 63)
+	testCases := []struct {
+		name       string
+		input      string
+		wantPrefix string
+		wantLength int
+	}{
+		{
+			name:       "should keep the short name",
+			input:      "work-app-bravelion",
+			wantPrefix: "work-app-bravelion",
+			wantLength: 18,
+		},
+		{
+			name:       "should keep the name of the max length",
+			input:      label63,
+			wantPrefix: label63,
+			wantLength: 63,
+		},
+		{
+			name:       "should truncate the long name",
+			input:      longObjectNS + "-" + objectName,
+			wantPrefix: longObjectNS[:54] + "-",
+			wantLength: 63,
+		},
+		{
+			name:       "should not leave consecutive dashes",
+			input:      strings.Repeat("a", 53) + "--" + longObjectNS,
+			wantPrefix: strings.Repeat("a", 53) + "-",
+			wantLength: 62,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := TruncateDNS1123Label(tc.input)
+			if !strings.HasPrefix(got, tc.wantPrefix) || len(got) != tc.wantLength {
+				t.Errorf("TruncateDNS1123Label(%s)=%s, want prefix %s and length %d", tc.input, got, tc.wantPrefix, tc.wantLength)
+			}
+			if errs := validation.IsDNS1123Label(got); len(errs) != 0 {
+				t.Errorf("TruncateDNS1123Label(%s)=%s, want a valid RFC 1123 DNS label: %v", tc.input, got, errs)
+			}
+			if again := TruncateDNS1123Label(tc.input); again != got {
+				t.Errorf("TruncateDNS1123Label(%s)=%s, want the same label %s as the previous call", tc.input, again, got)
+			}
+		})
+	}
+
+	if a, b := TruncateDNS1123Label(label63+"-a"), TruncateDNS1123Label(label63+"-b"); a == b {
+		t.Errorf("TruncateDNS1123Label() returned the same label %s for different long names, want different labels", a)
+	}
+}
@@ -10,12 +10,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
+	"net"
+	"net/netip"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/errgroup"
@@ -24,14 +29,21 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -44,6 +56,7 @@ import (
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/azureclient"
 	"go.goms.io/fleet-networking/pkg/common/azureerrors"
 	"go.goms.io/fleet-networking/pkg/common/defaulter"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
@@ -53,36 +66,131 @@ import (
 
 func init() {
 	/// Register trafficManagerBackendStatusLastTimestampSeconds (fleet_networking_traffic_manager_backend_status_last_timestamp_seconds)
-	// metric with the controller runtime global metrics registry.
-	ctrlmetrics.Registry.MustRegister(trafficManagerBackendStatusLastTimestampSeconds)
+	// and trafficManagerBackendEndpoints (fleet_networking_traffic_manager_backend_endpoints) metrics with the controller
+	// runtime global metrics registry.
+	ctrlmetrics.Registry.MustRegister(trafficManagerBackendStatusLastTimestampSeconds, trafficManagerBackendEndpoints, orphanEndpointsDeletedTotal,
+		trafficManagerBackendEffectiveWeightTooLowTotal, trafficManagerBackendReconcileDurationSeconds)
 }
 
 const (
 	// ControllerName is the name of the TrafficManagerBackend controller.
 	ControllerName = "trafficmanagerbackend-controller"
+	// StatusFieldManager is the field manager of the TrafficManagerBackend status applied by the controller.
+	StatusFieldManager = ControllerName
 
-	trafficManagerBackendProfileFieldKey = ".spec.profile.name"
+	trafficManagerBackendProfileFieldKey = ".spec.profile.namespacedName"
+	// trafficManagerBackendBackendFieldKey indexes the backends by the names of all the serviceImports they reference,
+	// including spec.backend.additionalNames.
 	trafficManagerBackendBackendFieldKey = ".spec.backend.name"
 	// fields name used to filter resources
 	exportedServiceFieldNamespacedName = ".spec.serviceReference.namespacedName"
 
+	// DefaultAzureResourceEndpointNamePrefix is the default prefix of the Azure Traffic Manager endpoint names created
+	// by the fleet controller.
+	DefaultAzureResourceEndpointNamePrefix = "fleet-"
+
 	// AzureResourceEndpointNamePrefix is the prefix format of the Azure Traffic Manager Endpoint created by the fleet controller.
-	// The naming convention of a Traffic Manager Endpoint is fleet-{TrafficManagerBackendUUID}#.
-	// Using the UUID of the backend here in case to support cross namespace TrafficManagerBackend in the future.
-	AzureResourceEndpointNamePrefix = "fleet-%s#"
+	// The naming convention of a Traffic Manager Endpoint is {EndpointNamePrefix}{TrafficManagerBackendUUID}#, which
+	// is fleet-{TrafficManagerBackendUUID}# by default.
+	// Using the UUID of the backend here to support the TrafficManagerBackends from different namespaces referencing the same profile.
+	AzureResourceEndpointNamePrefix = "%s%s#"
 
 	// AzureResourceEndpointNameFormat is the name format of the Azure Traffic Manager Endpoint created by the fleet controller.
 	// The naming convention of a Traffic Manager Endpoint is {AzureResourceEndpointNamePrefix}{ServiceImportName}#{ClusterName}.
-	// which is fleet-{TrafficManagerBackendUUID}#{ServiceImportName}#{ClusterName}.
+	// which is fleet-{TrafficManagerBackendUUID}#{ServiceImportName}#{ClusterName} by default.
 	// ServiceImportName will be the same as the Service name, which is up to 63 characters (RFC 1035).
 	// https://github.com/kubernetes/kubernetes/pull/29523
 	// The cluster name length should be restricted to <= 63 characters.
 	// The endpoint name must contain no more than 260 characters, excluding the following characters "< > * % $ : \ ? + /".
 	AzureResourceEndpointNameFormat = "%s%s#%s"
 
+	// azureResourceEndpointNameMaxLength is the max length of the Azure Traffic Manager endpoint name.
+	azureResourceEndpointNameMaxLength = 260
+	// azureResourceEndpointNameInvalidCharacters are the characters which are not allowed in the Azure Traffic Manager endpoint name.
+	azureResourceEndpointNameInvalidCharacters = `<>*%$:\?+/`
+
+	// azureTrafficManagerEndpointTypePrefix is the prefix of the Azure Traffic Manager endpoint resource type.
+	azureTrafficManagerEndpointTypePrefix = "Microsoft.Network/trafficManagerProfiles/"
+
 	backendEventReasonAzureAPIError = "AzureAPIError"
 	backendEventReasonAccepted      = "Accepted"
 	backendEventReasonDeleted       = "Deleted"
+	// backendEventReasonDeletionSkipped is used when the endpoints cannot be deleted because of the unrecoverable error.
+	backendEventReasonDeletionSkipped = "DeletionSkipped"
+	// backendEventReasonWeightRecomputed is used when an existing endpoint is updated only because its weight is changed.
+	backendEventReasonWeightRecomputed = "WeightRecomputed"
+	// backendEventReasonEndpointAdopted is used when a pre-existing endpoint is adopted by the backend.
+	backendEventReasonEndpointAdopted = "EndpointAdopted"
+	// backendEventReasonExportNotFound is used when the internalServiceExport of a cluster listed in the serviceImport
+	// is not found.
+	backendEventReasonExportNotFound = "ExportNotFound"
+	// backendEventReasonDraining is used when the endpoints are disabled to drain the DNS traffic before being deleted.
+	backendEventReasonDraining = "Draining"
+	// backendEventReasonOrphaned is used when the endpoints are left behind on deletion so that they can be re-adopted.
+	backendEventReasonOrphaned = "Orphaned"
+	// backendEventReasonEffectiveWeightTooLow is used when the weight apportioned to an endpoint is below the minimum
+	// weight accepted by the Azure Traffic Manager, so that the cluster receives no traffic.
+	backendEventReasonEffectiveWeightTooLow = "EffectiveWeightTooLow"
+	// backendEventReasonUnknownClusterWeightOverride is used when the weight override of a cluster is ignored as the
+	// cluster is not listed in the serviceImports.
+	backendEventReasonUnknownClusterWeightOverride = "UnknownClusterWeightOverride"
+
+	// azureTrafficManagerEndpointMinWeight is the minimum weight of the Azure Traffic Manager endpoint.
+	azureTrafficManagerEndpointMinWeight = 1
+
+	// DefaultMaxConcurrentEndpointDeletes is the default number of Azure Traffic Manager endpoints which can be deleted
+	// concurrently by a single reconcile.
+	DefaultMaxConcurrentEndpointDeletes = 10
+
+	// DefaultMaxEndpointsPerProfile is the default maximum number of endpoints allowed in an Azure Traffic Manager profile.
+	DefaultMaxEndpointsPerProfile = 200
+
+	// DefaultAzureAPIErrorEventInterval is the default minimum interval between two AzureAPIError events of a backend
+	// caused by the same category of errors.
+	DefaultAzureAPIErrorEventInterval = time.Minute
+
+	// DefaultNoExportedServicesThreshold is the default duration the serviceImports can have no exported services
+	// before the Accepted condition becomes False.
+	DefaultNoExportedServicesThreshold = 10 * time.Minute
+	// noExportedServicesMessage is the message of the Unknown Accepted condition when the serviceImports have no
+	// exported services yet.
+	noExportedServicesMessage = "In the process of exporting the services"
+
+	// conflictRequeueDelay is the base delay to requeue the request after hitting a conflict when updating the
+	// trafficManagerBackend.
+	conflictRequeueDelay = time.Second
+	// conflictRequeueJitterFactor is the max jitter factor of the conflictRequeueDelay so that the backends which are
+	// reconciled at the same time (for example, triggered by a trafficManagerProfile event) won't conflict again.
+	conflictRequeueJitterFactor = 2.0
+
+	// missingExportRequeueDelay is the initial delay to requeue the request when the internalServiceExport of a cluster
+	// listed in the serviceImport is not found, which is usually transient while the serviceImport status is catching
+	// up with the internalServiceExports.
+	missingExportRequeueDelay = time.Second
+	// missingExportRequeueMaxDelay is the max delay to requeue the request for the missing internalServiceExport.
+	missingExportRequeueMaxDelay = 30 * time.Second
+	// missingExportRequeueTimeout is how long the request is requeued for the missing internalServiceExport, after which
+	// the controller relies on the serviceImport event only.
+	missingExportRequeueTimeout = 5 * time.Minute
+
+	// authorizationFailedRequeueDelay is the delay to requeue the request when the Azure identity is not authorized to
+	// manage the endpoints, which cannot be resolved by retrying until the permission is granted.
+	authorizationFailedRequeueDelay = 10 * time.Minute
+
+	// publicIPAddressResourceType is the Azure resource type of the public IP addresses of the exported services.
+	publicIPAddressResourceType = "Microsoft.Network/publicIPAddresses"
+
+	// pausedConfigMapKey is the key of the pause configMap data which pauses the reconciliation when set to "true".
+	pausedConfigMapKey = "paused"
+	// pausedRequeueDelay is the delay to requeue the request while the reconciliation is paused, in case the watch
+	// event of the pause configMap is missed.
+	pausedRequeueDelay = time.Minute
+
+	// The reasons of the ExposedAsTrafficManagerEndpoint condition set on the internalServiceExports.
+	exposedConditionReasonExposed               = "Exposed"
+	exposedConditionReasonInvalid               = "Invalid"
+	exposedConditionReasonEndpointLimitExceeded = "EndpointLimitExceeded"
+	exposedConditionReasonServiceConflict       = "ServiceConflict"
 )
 
 var (
@@ -90,27 +198,209 @@ var (
 	generateAzureTrafficManagerProfileNameFunc = func(profile *fleetnetv1beta1.TrafficManagerProfile) string {
 		return trafficmanagerprofile.GenerateAzureTrafficManagerProfileName(profile)
 	}
-	generateAzureTrafficManagerEndpointNamePrefixFunc = func(backend *fleetnetv1beta1.TrafficManagerBackend) string {
-		return fmt.Sprintf(AzureResourceEndpointNamePrefix, backend.UID)
+	generateAzureTrafficManagerEndpointNamePrefixFunc = func(namePrefix string, backend *fleetnetv1beta1.TrafficManagerBackend) string {
+		return GenerateAzureTrafficManagerEndpointNamePrefix(namePrefix, backend)
+	}
+
+	// azureResourceEndpointNamePrefixRegexp matches the configurable prefixes of the Azure Traffic Manager endpoint
+	// names, which end with the only "-" so that the prefix of a controller is never the prefix of another one.
+	azureResourceEndpointNamePrefixRegexp = regexp.MustCompile(`^[a-z0-9]{1,31}-$`)
+
+	// errInternalServiceExportNotFound is returned by the validateAndProcessServiceImportForBackend when the
+	// internalServiceExport of a cluster listed in the serviceImport is not found.
+	errInternalServiceExportNotFound = errors.New("internalServiceExport not found")
+
+	// errAuthorizationFailed is returned by the updateTrafficManagerEndpointsAndUpdateStatusIfUnknown when the Azure
+	// identity is not authorized to manage the endpoints and the status has been updated.
+	errAuthorizationFailed = errors.New("azure identity is not authorized to manage the endpoints")
+
+	// errNoExportedServices is returned by the validateAndProcessServiceImportForBackend when no member cluster
+	// exports the services behind the serviceImports and the status has been updated.
+	errNoExportedServices = errors.New("no exported services behind the serviceImports")
+
+	// errServiceConflict is recorded as the invalid service when the service exported from the cluster is in conflict
+	// with the services exported from other clusters.
+	errServiceConflict = errors.New("service is in conflict with the services exported from other clusters")
+
+	// deleteEndpointThrottledBackoff is the backoff to retry the endpoint deletion when the request is throttled by Azure.
+	deleteEndpointThrottledBackoff = wait.Backoff{
+		Steps:    5,
+		Duration: time.Second,
+		Factor:   2,
+		Jitter:   0.5,
+		Cap:      30 * time.Second,
 	}
 
 	// trafficManagerBackendStatusLastTimestampSeconds is a prometheus metric that holds the last update timestamp of
 	// traffic manager backend status in seconds.
+	// The generation is not a label as it grows unbounded over the lifetime of a backend and every generation would
+	// leave a stale series behind.
 	trafficManagerBackendStatusLastTimestampSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: metrics.MetricsNamespace,
 		Subsystem: metrics.MetricsSubsystem,
 		Name:      "traffic_manager_backend_status_last_timestamp_seconds",
 		Help:      "Last update timestamp of traffic manager backend status in seconds",
-	}, []string{"namespace", "name", "generation", "condition", "status", "reason"})
+	}, []string{"namespace", "name", "condition", "status", "reason"})
+
+	// trafficManagerBackendEndpoints is a prometheus metric that holds the number of the endpoints of the traffic manager
+	// backend in each state:
+	// * "accepted": the endpoints which are created or updated in the Azure Traffic Manager profile.
+	// * "invalid": the exported services which cannot be exposed as the endpoints.
+	// * "bad": the endpoints which failed to be created or updated in the Azure Traffic Manager profile.
+	trafficManagerBackendEndpoints = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.MetricsNamespace,
+		Subsystem: metrics.MetricsSubsystem,
+		Name:      "traffic_manager_backend_endpoints",
+		Help:      "Number of the endpoints of traffic manager backend in each state",
+	}, []string{"namespace", "name", "state"})
+
+	// orphanEndpointsDeletedTotal is a prometheus metric that counts the orphaned Azure Traffic Manager endpoints
+	// deleted by the garbage collector.
+	orphanEndpointsDeletedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.MetricsNamespace,
+		Subsystem: metrics.MetricsSubsystem,
+		Name:      "traffic_manager_orphan_endpoints_deleted_total",
+		Help:      "Total number of the orphaned Azure Traffic Manager endpoints deleted by the garbage collector",
+	})
+
+	// trafficManagerBackendEffectiveWeightTooLowTotal is a prometheus metric that counts the endpoints of the traffic
+	// manager backend whose apportioned weight is below the minimum weight of the Azure Traffic Manager endpoint.
+	trafficManagerBackendEffectiveWeightTooLowTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.MetricsNamespace,
+		Subsystem: metrics.MetricsSubsystem,
+		Name:      "traffic_manager_backend_effective_weight_too_low_total",
+		Help:      "Total number of the endpoints of traffic manager backend whose apportioned weight is below the Azure Traffic Manager minimum",
+	}, []string{"namespace", "name"})
+
+	// trafficManagerBackendReconcileDurationSeconds is a prometheus metric that holds the duration of the traffic
+	// manager backend reconciliations by the outcome:
+	// - success: the reconciliation succeeded without requeue.
+	// - requeue: the reconciliation succeeded and the backend is requeued, for example, to refresh the monitor status.
+	// - error: the reconciliation failed.
+	trafficManagerBackendReconcileDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.MetricsNamespace,
+		Subsystem: metrics.MetricsSubsystem,
+		Name:      "traffic_manager_backend_reconcile_duration_seconds",
+		Help:      "Duration of the traffic manager backend reconciliations in seconds",
+		// A reconciliation makes a few Azure API calls per endpoint, which takes longer than a single call.
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+	}, []string{"outcome"})
 )
 
+const (
+	reconcileOutcomeSuccess = "success"
+	reconcileOutcomeRequeue = "requeue"
+	reconcileOutcomeError   = "error"
+)
+
+// ValidateAzureResourceEndpointNamePrefix returns error if the prefix cannot be used as the prefix of the Azure Traffic
+// Manager endpoint names.
+// The prefix must consist of up to 31 lower case alphanumeric characters followed by a "-", for example, "staging-",
+// so that the controllers with different prefixes sharing an Azure Traffic Manager profile never treat the endpoints
+// of each other as their own.
+func ValidateAzureResourceEndpointNamePrefix(namePrefix string) error {
+	if !azureResourceEndpointNamePrefixRegexp.MatchString(namePrefix) {
+		return fmt.Errorf("invalid endpoint name prefix %q: must match the regex %s", namePrefix, azureResourceEndpointNamePrefixRegexp)
+	}
+	return nil
+}
+
+// GenerateAzureTrafficManagerEndpointNamePrefix generates the prefix of the Azure Traffic Manager endpoint names
+// created for the backend by the controller with the endpoint name prefix, which is used to identify the endpoints
+// owned by the backend.
+func GenerateAzureTrafficManagerEndpointNamePrefix(namePrefix string, backend *fleetnetv1beta1.TrafficManagerBackend) string {
+	return fmt.Sprintf(AzureResourceEndpointNamePrefix, namePrefix, backend.UID)
+}
+
+// GenerateAzureTrafficManagerEndpointName generates the Azure Traffic Manager endpoint name for the service exported
+// from the cluster behind the backend by the controller with the endpoint name prefix.
+func GenerateAzureTrafficManagerEndpointName(namePrefix string, backend *fleetnetv1beta1.TrafficManagerBackend, serviceImportName, clusterID string) string {
+	return formatAzureTrafficManagerEndpointName(GenerateAzureTrafficManagerEndpointNamePrefix(namePrefix, backend), serviceImportName, clusterID)
+}
+
+func formatAzureTrafficManagerEndpointName(prefix, serviceImportName, clusterID string) string {
+	return fmt.Sprintf(AzureResourceEndpointNameFormat, prefix, serviceImportName, clusterID)
+}
+
+// ParseAzureTrafficManagerEndpointName decodes the Azure Traffic Manager endpoint name generated by
+// GenerateAzureTrafficManagerEndpointName with the endpoint name prefix into the backend UID, service import name and
+// cluster ID.
+// It returns false if the name does not follow the naming convention of the fleet controller with the prefix.
+func ParseAzureTrafficManagerEndpointName(namePrefix, endpointName string) (backendUID, serviceImportName, clusterID string, ok bool) {
+	rest, found := strings.CutPrefix(endpointName, namePrefix)
+	if !found {
+		return "", "", "", false
+	}
+	parts := strings.Split(rest, "#")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
 // Reconciler reconciles a trafficManagerBackend object.
 type Reconciler struct {
 	client.Client
 
-	ProfilesClient  *armtrafficmanager.ProfilesClient
-	EndpointsClient *armtrafficmanager.EndpointsClient
-	Recorder        record.EventRecorder
+	// AzureClientFactory provides the Azure Traffic Manager clients of the subscription of each profile.
+	AzureClientFactory *azureclient.TrafficManagerClientFactory
+	Recorder           record.EventRecorder
+
+	// AzureAPIErrorEventInterval is the minimum interval between two AzureAPIError events of a backend caused by the
+	// same category of errors, while every error is still logged.
+	// DefaultAzureAPIErrorEventInterval is used when it's zero and the events are not limited when it's negative.
+	AzureAPIErrorEventInterval time.Duration
+
+	// EndpointMonitorResyncInterval is the wait time for the controller to requeue the request and to refresh the
+	// monitor status of the endpoints, which is changed by the Azure Traffic Manager asynchronously.
+	// The periodic resync is disabled when it's zero.
+	EndpointMonitorResyncInterval time.Duration
+
+	// MaxConcurrentEndpointDeletes is the maximum number of Azure Traffic Manager endpoints which can be deleted
+	// concurrently when cleaning up the endpoints of a backend.
+	// DefaultMaxConcurrentEndpointDeletes is used when it's not positive.
+	MaxConcurrentEndpointDeletes int
+
+	// MaxEndpointsPerProfile is the maximum number of endpoints which can be created in an Azure Traffic Manager
+	// profile, including the endpoints created by other backends of the same profile.
+	// DefaultMaxEndpointsPerProfile is used when it's not positive.
+	MaxEndpointsPerProfile int
+
+	// NoExportedServicesThreshold is the duration the serviceImports can have no exported services before the Accepted
+	// condition becomes False with the NoExportedServices reason instead of staying Unknown.
+	// DefaultNoExportedServicesThreshold is used when it's not positive.
+	NoExportedServicesThreshold time.Duration
+
+	// OrphanAzureResourcesOnDelete leaves the Azure Traffic Manager endpoints of the deleting backends untouched and
+	// only removes the finalizers, for example, when the controller is uninstalled for an upgrade.
+	// The orphaned endpoints can be adopted by the backends created later, see isEndpointAdoptionEnabled.
+	OrphanAzureResourcesOnDelete bool
+
+	// PauseConfigMap is the namespaced name of the configMap which pauses the reconciliation of all the backends when
+	// its "paused" key is set to "true", for example, during an emergency change freeze.
+	// While paused, no Azure Traffic Manager endpoints are created, updated or deleted.
+	// The kill switch is disabled when the name is empty.
+	PauseConfigMap types.NamespacedName
+
+	// PublicIPSubscriptions are the IDs of the subscriptions whose public IP addresses can be accessed by the Azure
+	// identity of the controller.
+	// When set, the services whose public IP addresses are in other subscriptions are rejected before calling the Azure
+	// Traffic Manager, which otherwise returns an opaque error. The public IP resource IDs are not validated when empty.
+	PublicIPSubscriptions []string
+
+	// RateLimiter delays the requests whose reconciliation returns an error or ctrl.Result{Requeue: true}, so that the
+	// retries back off when the Azure Resource Manager is failing.
+	// The default rate limiter of the controller-runtime is used when it's nil.
+	RateLimiter workqueue.TypedRateLimiter[reconcile.Request]
+
+	// EndpointNamePrefix is the prefix of the Azure Traffic Manager endpoint names created by the controller, so that
+	// the controllers of different fleets (for example, staging and prod) can share an Azure Traffic Manager profile
+	// without deleting the endpoints of each other. It must be validated by ValidateAzureResourceEndpointNamePrefix.
+	// DefaultAzureResourceEndpointNamePrefix is used when it's empty.
+	EndpointNamePrefix string
+
+	// azureAPIErrorEvents limits the AzureAPIError events by AzureAPIErrorEventInterval.
+	azureAPIErrorEvents azureAPIErrorEventLimiter
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=trafficmanagerbackends,verbs=get;list;watch;create;update;patch;delete
@@ -118,32 +408,75 @@ type Reconciler struct {
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=trafficmanagerbackends/finalizers,verbs=get;update
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=trafficmanagerprofiles,verbs=get;list;watch
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceimports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 
 // Reconcile triggers a single reconcile round.
 func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
-	name := req.NamespacedName
+	_, result, err := r.reconcileBackend(ctx, req.NamespacedName)
+	return result, err
+}
+
+// reconcileOutcome returns the outcome label of the reconcile duration metric.
+func reconcileOutcome(result ctrl.Result, err error) string {
+	switch {
+	case err != nil:
+		return reconcileOutcomeError
+	case result.Requeue || result.RequeueAfter > 0:
+		return reconcileOutcomeRequeue
+	default:
+		return reconcileOutcomeSuccess
+	}
+}
+
+// ReconcileOnce reconciles the trafficManagerBackend synchronously without going through the work queue of the
+// controller, for example, to force a reconciliation from the tools or to write focused tests.
+// It goes through the same path as Reconcile, including the paused reconciliation and the deletion, and returns the
+// backend with the conditions and the endpoints computed by the reconciliation, together with the result telling
+// when the backend should be reconciled again. The returned backend is nil if the backend is not found.
+func (r *Reconciler) ReconcileOnce(ctx context.Context, name types.NamespacedName) (*fleetnetv1beta1.TrafficManagerBackend, ctrl.Result, error) {
+	return r.reconcileBackend(ctx, name)
+}
+
+// reconcileBackend reconciles the trafficManagerBackend and returns the reconciled backend, which is nil if the
+// backend is not found.
+func (r *Reconciler) reconcileBackend(ctx context.Context, name types.NamespacedName) (_ *fleetnetv1beta1.TrafficManagerBackend, result ctrl.Result, err error) {
 	backendKRef := klog.KRef(name.Namespace, name.Name)
 
 	startTime := time.Now()
 	klog.V(2).InfoS("Reconciliation starts", "trafficManagerBackend", backendKRef)
 	defer func() {
-		latency := time.Since(startTime).Milliseconds()
-		klog.V(2).InfoS("Reconciliation ends", "trafficManagerBackend", backendKRef, "latency", latency)
+		latency := time.Since(startTime)
+		trafficManagerBackendReconcileDurationSeconds.WithLabelValues(reconcileOutcome(result, err)).Observe(latency.Seconds())
+		klog.V(2).InfoS("Reconciliation ends", "trafficManagerBackend", backendKRef, "latency", latency.Milliseconds())
 	}()
 
 	backend := &fleetnetv1beta1.TrafficManagerBackend{}
 	if err := r.Client.Get(ctx, name, backend); err != nil {
 		if apierrors.IsNotFound(err) {
 			klog.V(2).InfoS("Ignoring NotFound trafficManagerBackend", "trafficManagerBackend", backendKRef)
-			return ctrl.Result{}, nil
+			return nil, ctrl.Result{}, nil
 		}
 		klog.ErrorS(err, "Failed to get trafficManagerBackend", "trafficManagerBackend", backendKRef)
-		return ctrl.Result{}, controller.NewAPIServerError(true, err)
+		return nil, ctrl.Result{}, controller.NewAPIServerError(true, err)
+	}
+
+	paused, err := r.isReconciliationPaused(ctx)
+	if err != nil {
+		return backend, ctrl.Result{}, err
+	}
+	if paused {
+		result, err = r.handlePaused(ctx, backend)
+		result, err = requeueWithJitterIfConflict(backendKRef, result, err)
+		return backend, result, err
 	}
 
 	if !backend.ObjectMeta.DeletionTimestamp.IsZero() {
-		return r.handleDelete(ctx, backend)
+		result, err = r.handleDelete(ctx, backend)
+		result, err = requeueWithJitterIfConflict(backendKRef, result, err)
+		return backend, result, err
 	}
 
 	// register metrics finalizer
@@ -151,7 +484,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		controllerutil.AddFinalizer(backend, objectmeta.MetricsFinalizer)
 		if err := r.Update(ctx, backend); err != nil {
 			klog.ErrorS(err, "Failed to add trafficManagerBackend metrics finalizer", "trafficManagerBackend", backendKRef)
-			return ctrl.Result{}, err
+			return backend, ctrl.Result{}, err
 		}
 	}
 
@@ -159,82 +492,381 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 
 	// TODO: replace the following with defaulter webhook
 	defaulter.SetDefaultsTrafficManagerBackend(backend)
-	return r.handleUpdate(ctx, backend)
+	result, err = r.handleUpdate(ctx, backend)
+	result, err = requeueWithJitterIfConflict(backendKRef, result, err)
+	return backend, result, err
 }
 
-func (r *Reconciler) handleDelete(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend) (ctrl.Result, error) {
+// isReconciliationPaused returns true if the pause configMap exists and its "paused" key is set to "true".
+// The configMap is read from the cache of the manager, which is kept up to date by the watch.
+func (r *Reconciler) isReconciliationPaused(ctx context.Context) (bool, error) {
+	if r.PauseConfigMap.Name == "" {
+		return false, nil
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, r.PauseConfigMap, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		klog.ErrorS(err, "Failed to get the pause configMap", "configMap", klog.KRef(r.PauseConfigMap.Namespace, r.PauseConfigMap.Name))
+		return false, controller.NewAPIServerError(true, err)
+	}
+	paused, err := strconv.ParseBool(configMap.Data[pausedConfigMapKey])
+	return err == nil && paused, nil
+}
+
+// handlePaused leaves the Azure Traffic Manager endpoints untouched while the reconciliation is paused, including the
+// deleting backends whose endpoints are deleted once the reconciliation is resumed.
+func (r *Reconciler) handlePaused(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend) (ctrl.Result, error) {
 	backendKObj := klog.KObj(backend)
-	needUpdate := false
-	// The backend is being deleted
-	if controllerutil.ContainsFinalizer(backend, objectmeta.MetricsFinalizer) {
-		klog.V(2).InfoS("TrafficManagerBackend is being deleted and cleaning up its metrics", "trafficManagerBackend", backendKObj)
-		// The controller registers backend finalizer only before creating atm backend to avoid the deletion stuck for the 403 error.
-		// We use a separate finalizer to clean up the metrics for the backend.
-		trafficManagerBackendStatusLastTimestampSeconds.DeletePartialMatch(prometheus.Labels{"namespace": backend.GetNamespace(), "name": backend.GetName()})
-		controllerutil.RemoveFinalizer(backend, objectmeta.MetricsFinalizer)
-		needUpdate = true
+	klog.V(2).InfoS("Reconciliation is paused and skipping reconciling the endpoints", "trafficManagerBackend", backendKObj, "configMap", klog.KRef(r.PauseConfigMap.Namespace, r.PauseConfigMap.Name), "requeueAfter", pausedRequeueDelay)
+	if !backend.DeletionTimestamp.IsZero() {
+		return ctrl.Result{RequeueAfter: pausedRequeueDelay}, nil
 	}
+	message := fmt.Sprintf("Reconciliation is paused by the configMap %q and the existing Azure Traffic Manager endpoints are left untouched", r.PauseConfigMap.String())
+	if hasAcceptedCondition(backend, metav1.ConditionFalse, fleetnetv1beta1.TrafficManagerBackendReasonReconciliationPaused, message) {
+		return ctrl.Result{RequeueAfter: pausedRequeueDelay}, nil
+	}
+	// Keep reporting the endpoints accepted before the pause as they are left untouched.
+	setFalseConditionWithReason(backend, backend.Status.Endpoints, fleetnetv1beta1.TrafficManagerBackendReasonReconciliationPaused, message)
+	if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: pausedRequeueDelay}, nil
+}
+
+// requeueWithJitterIfConflict replaces the conflict error returned by updating the trafficManagerBackend with a
+// requeue after a randomized delay.
+// Returning the error will requeue the request by the rate limiter, whose delay is the same for all the backends
+// conflicting at the same time and causes them to conflict again.
+func requeueWithJitterIfConflict(backendKRef klog.ObjectRef, result ctrl.Result, err error) (ctrl.Result, error) {
+	// The conflict error is converted to the ErrExpectedBehavior error by the controller.NewUpdateIgnoreConflictError.
+	if err == nil || !errors.Is(err, controller.ErrExpectedBehavior) {
+		return result, err
+	}
+	requeueAfter := wait.Jitter(conflictRequeueDelay, conflictRequeueJitterFactor)
+	klog.V(2).InfoS("Requeue the trafficManagerBackend after the conflict", "trafficManagerBackend", backendKRef, "requeueAfter", requeueAfter)
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
 
+func (r *Reconciler) handleDelete(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend) (ctrl.Result, error) {
+	backendKObj := klog.KObj(backend)
+	// The backend is being deleted.
+	// The Azure resources are cleaned up and the backend finalizer is removed first, so that the metrics are kept while
+	// the deletion is retried, for example, when the update conflicts after the Azure endpoints are deleted.
+	if controllerutil.ContainsFinalizer(backend, objectmeta.TrafficManagerBackendFinalizer) && r.OrphanAzureResourcesOnDelete {
+		klog.V(1).InfoS("Orphaning the Azure Traffic Manager endpoints and removing the trafficManagerBackend finalizer", "trafficManagerBackend", backendKObj, "endpoints", backend.Status.Endpoints)
+		controllerutil.RemoveFinalizer(backend, objectmeta.TrafficManagerBackendFinalizer)
+		if err := r.Client.Update(ctx, backend); err != nil {
+			klog.ErrorS(err, "Failed to remove trafficManagerBackend finalizer", "trafficManagerBackend", backendKObj)
+			return ctrl.Result{}, controller.NewUpdateIgnoreConflictError(err)
+		}
+		r.Recorder.Event(backend, corev1.EventTypeNormal, backendEventReasonOrphaned, "Left the Azure Traffic Manager endpoints behind as the Azure resources are orphaned on deletion")
+		klog.V(2).InfoS("Removed trafficManagerBackend finalizer", "trafficManagerBackend", backendKObj)
+	}
 	if controllerutil.ContainsFinalizer(backend, objectmeta.TrafficManagerBackendFinalizer) {
-		if err := r.deleteAzureTrafficManagerEndpoints(ctx, backend); err != nil {
-			r.Recorder.Eventf(backend, corev1.EventTypeWarning, backendEventReasonAzureAPIError, "Failed to delete Azure Traffic Manager endpoints: %v", err)
-			klog.ErrorS(err, "Failed to delete Azure Traffic Manager endpoints", "trafficManagerBackend", backendKObj)
+		if gracePeriod, ok := drainGracePeriod(backend); ok && !hasNoEndpointsCreated(backend) {
+			requeueAfter, err := r.drainAzureTrafficManagerEndpoints(ctx, backend, gracePeriod)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if requeueAfter > 0 {
+				klog.V(2).InfoS("Draining Azure Traffic Manager endpoints before deleting them", "trafficManagerBackend", backendKObj, "requeueAfter", requeueAfter)
+				return ctrl.Result{RequeueAfter: requeueAfter}, nil
+			}
+		}
+		eventType, eventReason, eventMessage := corev1.EventTypeNormal, backendEventReasonDeleted, "Deleted Azure Traffic Manager endpoints"
+		if hasNoEndpointsCreated(backend) {
+			// The Azure calls are likely to keep failing in the same way as creating the endpoints, for example, because
+			// of the authorization failure, while there is nothing to delete.
+			klog.V(2).InfoS("No Azure Traffic Manager endpoints were created and skipping deleting them", "trafficManagerBackend", backendKObj)
+			eventMessage = "Skipped deleting Azure Traffic Manager endpoints as none was created"
+		} else if err := r.deleteAzureTrafficManagerEndpoints(ctx, backend); err != nil {
+			if !azureerrors.IsUnrecoverable(err) {
+				r.recordAzureAPIErrorEvent(backend, err, "Failed to delete Azure Traffic Manager endpoints: %v", err)
+				klog.ErrorS(err, "Failed to delete Azure Traffic Manager endpoints", "trafficManagerBackend", backendKObj)
+				return ctrl.Result{}, err
+			}
+			// The credential or the subscription cannot be used anymore and retrying won't help.
+			// Remove the finalizer anyway so that the backend is not stuck in the deleting state forever.
+			eventType, eventReason = corev1.EventTypeWarning, backendEventReasonDeletionSkipped
+			eventMessage = fmt.Sprintf("Skipped deleting Azure Traffic Manager endpoints because of the unrecoverable error and the endpoints may be left behind: %v", err)
+			klog.ErrorS(err, "Failed to delete Azure Traffic Manager endpoints because of the unrecoverable error and removing the finalizer anyway", "trafficManagerBackend", backendKObj)
+		}
+		if err := r.updateInternalServiceExportsExposedCondition(ctx, backend, nil, nil, nil); err != nil {
 			return ctrl.Result{}, err
 		}
-		r.Recorder.Eventf(backend, corev1.EventTypeNormal, backendEventReasonDeleted, "Deleted Azure Traffic Manager endpoints")
 		controllerutil.RemoveFinalizer(backend, objectmeta.TrafficManagerBackendFinalizer)
-		needUpdate = true
+		if err := r.Client.Update(ctx, backend); err != nil {
+			klog.ErrorS(err, "Failed to remove trafficManagerBackend finalizer", "trafficManagerBackend", backendKObj)
+			return ctrl.Result{}, controller.NewUpdateIgnoreConflictError(err)
+		}
+		// Emit the event only once the finalizer is removed, so that the retries won't emit duplicate events.
+		r.Recorder.Event(backend, eventType, eventReason, eventMessage)
+		klog.V(2).InfoS("Removed trafficManagerBackend finalizer", "trafficManagerBackend", backendKObj)
 	}
 
-	if !needUpdate {
+	if !controllerutil.ContainsFinalizer(backend, objectmeta.MetricsFinalizer) {
 		klog.V(2).InfoS("No need to remove finalizer", "trafficManagerBackend", backendKObj)
 		return ctrl.Result{}, nil
 	}
-
+	// The controller registers backend finalizer only before creating atm backend to avoid the deletion stuck for the 403 error.
+	// We use a separate finalizer to clean up the metrics for the backend.
+	controllerutil.RemoveFinalizer(backend, objectmeta.MetricsFinalizer)
 	if err := r.Client.Update(ctx, backend); err != nil {
-		klog.ErrorS(err, "Failed to remove trafficManagerBackend finalizers", "trafficManagerBackend", backendKObj)
+		klog.ErrorS(err, "Failed to remove trafficManagerBackend metrics finalizer", "trafficManagerBackend", backendKObj)
 		return ctrl.Result{}, controller.NewUpdateIgnoreConflictError(err)
 	}
-	klog.V(2).InfoS("Removed trafficManagerBackend finalizers", "trafficManagerBackend", backendKObj)
+	klog.V(2).InfoS("Removed trafficManagerBackend metrics finalizer and cleaning up its metrics", "trafficManagerBackend", backendKObj)
+	trafficManagerBackendStatusLastTimestampSeconds.DeletePartialMatch(prometheus.Labels{"namespace": backend.GetNamespace(), "name": backend.GetName()})
+	trafficManagerBackendEndpoints.DeletePartialMatch(prometheus.Labels{"namespace": backend.GetNamespace(), "name": backend.GetName()})
+	trafficManagerBackendEffectiveWeightTooLowTotal.DeletePartialMatch(prometheus.Labels{"namespace": backend.GetNamespace(), "name": backend.GetName()})
 	return ctrl.Result{}, nil
 }
 
+// hasNoEndpointsCreated returns true if the backend records that none of its Azure Traffic Manager endpoints has been
+// created since its finalizer was added.
+// The backends which do not record it, for example, the ones created by the previous versions, are assumed to have
+// created the endpoints.
+func hasNoEndpointsCreated(backend *fleetnetv1beta1.TrafficManagerBackend) bool {
+	return backend.Status.EndpointsCreated != nil && !*backend.Status.EndpointsCreated && len(backend.Status.Endpoints) == 0
+}
+
+// drainGracePeriod returns the drain grace period set by the annotation of the backend and whether the endpoints should
+// be drained before being deleted.
+// The endpoints are deleted without draining when the annotation is invalid.
+func drainGracePeriod(backend *fleetnetv1beta1.TrafficManagerBackend) (time.Duration, bool) {
+	value, ok := backend.Annotations[objectmeta.TrafficManagerBackendAnnotationDrainGracePeriod]
+	if !ok {
+		return 0, false
+	}
+	gracePeriod, err := time.ParseDuration(value)
+	if err != nil || gracePeriod <= 0 {
+		klog.ErrorS(err, "Invalid drain grace period and deleting the endpoints without draining", "trafficManagerBackend", klog.KObj(backend), "drainGracePeriod", value)
+		return 0, false
+	}
+	return gracePeriod, true
+}
+
+// drainAzureTrafficManagerEndpoints disables the endpoints of the backend and records the drain start time in the
+// backend status, so that the DNS resolvers stop returning the endpoints before they are deleted.
+// It returns how long to wait before deleting the endpoints, which is not positive once the grace period elapses or
+// there is nothing to drain.
+func (r *Reconciler) drainAzureTrafficManagerEndpoints(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend, gracePeriod time.Duration) (time.Duration, error) {
+	backendKObj := klog.KObj(backend)
+	if start := backend.Status.DrainStartTime; start != nil {
+		return time.Until(start.Add(gracePeriod)), nil
+	}
+	disabled, err := r.disableAzureTrafficManagerEndpoints(ctx, backend)
+	if err != nil {
+		if azureerrors.IsUnrecoverable(err) {
+			// Leave the unrecoverable error to the deletion which removes the finalizer anyway.
+			klog.ErrorS(err, "Failed to disable Azure Traffic Manager endpoints because of the unrecoverable error and skipping draining", "trafficManagerBackend", backendKObj)
+			return 0, nil
+		}
+		r.recordAzureAPIErrorEvent(backend, err, "Failed to disable Azure Traffic Manager endpoints: %v", err)
+		klog.ErrorS(err, "Failed to disable Azure Traffic Manager endpoints", "trafficManagerBackend", backendKObj)
+		return 0, err
+	}
+	if disabled == 0 {
+		klog.V(2).InfoS("No Azure Traffic Manager endpoints to drain", "trafficManagerBackend", backendKObj)
+		return 0, nil
+	}
+	backend.Status.DrainStartTime = ptr.To(metav1.Now())
+	if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
+		return 0, err
+	}
+	r.Recorder.Eventf(backend, corev1.EventTypeNormal, backendEventReasonDraining, "Disabled %d Azure Traffic Manager endpoint(s) and deleting them after %v", disabled, gracePeriod)
+	return gracePeriod, nil
+}
+
+// disableAzureTrafficManagerEndpoints disables the endpoints created by the backend under the Azure Traffic Manager
+// profile and returns the number of the endpoints, skipping the ones which have been disabled already.
+func (r *Reconciler) disableAzureTrafficManagerEndpoints(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend) (int, error) {
+	backendKObj := klog.KObj(backend)
+	profile := &fleetnetv1beta1.TrafficManagerProfile{}
+	profileName := trafficManagerProfileNamespacedName(backend)
+	if err := r.Client.Get(ctx, profileName, profile); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(2).InfoS("NotFound trafficManagerProfile and no endpoints to disable", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileName)
+			return 0, nil
+		}
+		klog.ErrorS(err, "Failed to get trafficManagerProfile", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileName)
+		return 0, controller.NewAPIServerError(true, err)
+	}
+
+	profileKObj := klog.KObj(profile)
+	clients, err := r.azureClients(profile.Spec.SubscriptionID)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get Azure Traffic Manager clients", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileKObj, "subscriptionID", profile.Spec.SubscriptionID)
+		return 0, err
+	}
+	resourceGroup := profile.Spec.ResourceGroup
+	atmProfileName := generateAzureTrafficManagerProfileNameFunc(profile)
+	startTime := time.Now()
+	getRes, getErr := clients.ProfilesClient.Get(ctx, resourceGroup, atmProfileName, nil)
+	metrics.ObserveAzureAPICall(metrics.AzureAPIClientProfiles, metrics.AzureAPIOperationGet, startTime, getErr)
+	if getErr != nil {
+		if azureerrors.IsNotFound(getErr) {
+			klog.V(2).InfoS("Azure Traffic Manager profile does not exist and no endpoints to disable", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
+			return 0, nil
+		}
+		klog.ErrorS(getErr, "Failed to get the Traffic Manager profile", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
+		return 0, getErr
+	}
+	if getRes.Properties == nil {
+		return 0, nil
+	}
+
+	disabled := 0
+	for _, endpoint := range getRes.Properties.Endpoints {
+		if endpoint == nil || endpoint.Name == nil || !isEndpointOwnedByBackend(r.endpointNamePrefix(), backend, *endpoint.Name) {
+			continue
+		}
+		disabled++
+		if endpoint.Properties == nil || ptr.Deref(endpoint.Properties.EndpointStatus, "") == armtrafficmanager.EndpointStatusDisabled {
+			continue
+		}
+		desired := *endpoint
+		desiredProperties := *endpoint.Properties
+		desiredProperties.EndpointStatus = ptr.To(armtrafficmanager.EndpointStatusDisabled)
+		desired.Properties = &desiredProperties
+		startTime := time.Now()
+		_, updateErr := clients.EndpointsClient.CreateOrUpdate(ctx, resourceGroup, atmProfileName, azureTrafficManagerEndpointType(desired), *endpoint.Name, desired, nil)
+		metrics.ObserveAzureAPICall(metrics.AzureAPIClientEndpoints, metrics.AzureAPIOperationCreateOrUpdate, startTime, updateErr)
+		if updateErr != nil {
+			if azureerrors.IsNotFound(updateErr) {
+				continue
+			}
+			klog.ErrorS(updateErr, "Failed to disable the endpoint", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfileName", atmProfileName, "atmEndpoint", *endpoint.Name)
+			return 0, updateErr
+		}
+		klog.V(2).InfoS("Disabled Azure Traffic Manager endpoint", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfileName", atmProfileName, "atmEndpoint", *endpoint.Name)
+	}
+	return disabled, nil
+}
+
 func (r *Reconciler) deleteAzureTrafficManagerEndpoints(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend) error {
 	backendKObj := klog.KObj(backend)
 	profile := &fleetnetv1beta1.TrafficManagerProfile{}
-	if err := r.Client.Get(ctx, types.NamespacedName{Name: backend.Spec.Profile.Name, Namespace: backend.Namespace}, profile); err != nil {
+	profileName := trafficManagerProfileNamespacedName(backend)
+	if err := r.Client.Get(ctx, profileName, profile); err != nil {
 		if apierrors.IsNotFound(err) {
-			klog.V(2).InfoS("NotFound trafficManagerProfile and Azure resources should be deleted ", "trafficManagerBackend", backendKObj, "trafficManagerProfile", backend.Spec.Profile.Name)
+			klog.V(2).InfoS("NotFound trafficManagerProfile and Azure resources should be deleted ", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileName)
 			return nil
 		}
-		klog.ErrorS(err, "Failed to get trafficManagerProfile", "trafficManagerBackend", backendKObj, "trafficManagerProfile", backend.Spec.Profile.Name)
+		klog.ErrorS(err, "Failed to get trafficManagerProfile", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileName)
 		return controller.NewAPIServerError(true, err)
 	}
 
 	profileKObj := klog.KObj(profile)
+	clients, err := r.azureClients(profile.Spec.SubscriptionID)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get Azure Traffic Manager clients", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileKObj, "subscriptionID", profile.Spec.SubscriptionID)
+		return err
+	}
 	atmProfileName := generateAzureTrafficManagerProfileNameFunc(profile)
-	getRes, getErr := r.ProfilesClient.Get(ctx, profile.Spec.ResourceGroup, atmProfileName, nil)
+	startTime := time.Now()
+	getRes, getErr := clients.ProfilesClient.Get(ctx, profile.Spec.ResourceGroup, atmProfileName, nil)
+	metrics.ObserveAzureAPICall(metrics.AzureAPIClientProfiles, metrics.AzureAPIOperationGet, startTime, getErr)
 	if getErr != nil {
 		if !azureerrors.IsNotFound(getErr) {
 			klog.ErrorS(getErr, "Failed to get the Traffic Manager profile", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
 			return getErr
 		}
 		klog.V(2).InfoS("Azure Traffic Manager profile does not exist", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
-		return nil // skip handling endpoints deletion
+	} else if err := r.cleanupEndpoints(ctx, clients, profile.Spec.ResourceGroup, backend, &getRes.Profile); err != nil {
+		return err
 	}
-	return r.cleanupEndpoints(ctx, profile.Spec.ResourceGroup, backend, &getRes.Profile)
+	// The endpoints may have been created under the Azure Traffic Manager profile of a previous trafficManagerProfile,
+	// for example, in another resource group.
+	if staleID := backend.Status.ProfileResourceID; staleID != "" && (getErr != nil || getRes.ID == nil || !strings.EqualFold(staleID, *getRes.ID)) {
+		return r.cleanupEndpointsByProfileResourceID(ctx, backend, staleID)
+	}
+	return nil
+}
+
+// cleanupEndpointsInStaleAzureTrafficManagerProfile deletes the endpoints under the Azure Traffic Manager profile
+// recorded in the backend status when it differs from the current one, for example, the trafficManagerProfile is
+// recreated under another resource group, so that the endpoints under the previous profile are not leaked.
+// The current Azure Traffic Manager profile is then recorded in the backend status and persisted with the next status
+// update.
+func (r *Reconciler) cleanupEndpointsInStaleAzureTrafficManagerProfile(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend, atmProfile *armtrafficmanager.Profile) error {
+	if atmProfile.ID == nil {
+		return nil
+	}
+	if staleID := backend.Status.ProfileResourceID; staleID != "" && !strings.EqualFold(staleID, *atmProfile.ID) {
+		klog.V(2).InfoS("Azure Traffic Manager profile is changed and deleting the endpoints under the previous profile", "trafficManagerBackend", klog.KObj(backend), "previousProfileResourceID", staleID, "profileResourceID", *atmProfile.ID)
+		if err := r.cleanupEndpointsByProfileResourceID(ctx, backend, staleID); err != nil {
+			r.recordAzureAPIErrorEvent(backend, err, "Failed to delete Azure Traffic Manager endpoints under the previous profile %s: %v", staleID, err)
+			klog.ErrorS(err, "Failed to delete Azure Traffic Manager endpoints under the previous profile", "trafficManagerBackend", klog.KObj(backend), "previousProfileResourceID", staleID)
+			return err
+		}
+	}
+	backend.Status.ProfileResourceID = *atmProfile.ID
+	return nil
+}
+
+// cleanupEndpointsByProfileResourceID deletes the endpoints created by the backend under the Azure Traffic Manager
+// profile with the given resource ID, and skips the deletion when the profile does not exist.
+func (r *Reconciler) cleanupEndpointsByProfileResourceID(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend, profileResourceID string) error {
+	backendKObj := klog.KObj(backend)
+	resourceID, err := arm.ParseResourceID(profileResourceID)
+	if err != nil {
+		// The resource ID is returned by the Azure Traffic Manager and should always be valid.
+		klog.ErrorS(controller.NewUnexpectedBehaviorError(err), "Failed to parse the Azure Traffic Manager profile resource ID", "trafficManagerBackend", backendKObj, "profileResourceID", profileResourceID)
+		return nil
+	}
+	clients, err := r.azureClients(resourceID.SubscriptionID)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get Azure Traffic Manager clients", "trafficManagerBackend", backendKObj, "subscriptionID", resourceID.SubscriptionID)
+		return err
+	}
+	startTime := time.Now()
+	getRes, getErr := clients.ProfilesClient.Get(ctx, resourceID.ResourceGroupName, resourceID.Name, nil)
+	metrics.ObserveAzureAPICall(metrics.AzureAPIClientProfiles, metrics.AzureAPIOperationGet, startTime, getErr)
+	if getErr != nil {
+		if !azureerrors.IsNotFound(getErr) {
+			klog.ErrorS(getErr, "Failed to get the Traffic Manager profile", "trafficManagerBackend", backendKObj, "resourceGroup", resourceID.ResourceGroupName, "atmProfileName", resourceID.Name)
+			return getErr
+		}
+		klog.V(2).InfoS("Azure Traffic Manager profile does not exist", "trafficManagerBackend", backendKObj, "resourceGroup", resourceID.ResourceGroupName, "atmProfileName", resourceID.Name)
+		return nil
+	}
+	return r.cleanupEndpoints(ctx, clients, resourceID.ResourceGroupName, backend, &getRes.Profile)
+}
+
+// azureClients returns the Azure Traffic Manager clients of the subscription.
+// The clients of the default subscription are returned when the subscription ID is empty.
+func (r *Reconciler) azureClients(subscriptionID string) (*azureclient.TrafficManagerClients, error) {
+	clients, err := r.AzureClientFactory.Clients(subscriptionID)
+	if err != nil {
+		// The clients can always be created with a valid subscription ID.
+		return nil, controller.NewUnexpectedBehaviorError(err)
+	}
+	return clients, nil
 }
 
-func (r *Reconciler) cleanupEndpoints(ctx context.Context, resourceGroup string, backend *fleetnetv1beta1.TrafficManagerBackend, atmProfile *armtrafficmanager.Profile) error {
+// cleanupEndpoints deletes the endpoints created by the backend under the Azure Traffic Manager profile.
+// The Azure Traffic Manager API does not provide a list operation for the endpoints, and the profile returned by the
+// profiles client Get contains all of its endpoints without paging (an Azure Traffic Manager profile has at most 200
+// endpoints), so that none of the endpoints are missed.
+func (r *Reconciler) cleanupEndpoints(ctx context.Context, clients *azureclient.TrafficManagerClients, resourceGroup string, backend *fleetnetv1beta1.TrafficManagerBackend, atmProfile *armtrafficmanager.Profile) error {
 	backendKObj := klog.KObj(backend)
 	if atmProfile.Properties == nil {
 		klog.V(2).InfoS("Azure Traffic Manager profile has nil properties and skipping handling endpoints deletion", "trafficManagerBackend", backendKObj, "atmProfileName", atmProfile.Name)
 		return nil
 	}
 
-	klog.V(2).InfoS("Deleting Azure Traffic Manager endpoints", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "trafficManagerProfile", backend.Spec.Profile.Name)
+	klog.V(2).InfoS("Deleting Azure Traffic Manager endpoints", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "trafficManagerProfile", trafficManagerProfileNamespacedName(backend))
 	atmProfileName := *atmProfile.Name
 	errs, cctx := errgroup.WithContext(ctx)
+	// Limit the number of concurrent requests so that a profile with a lot of endpoints won't be throttled by Azure.
+	maxConcurrentDeletes := r.MaxConcurrentEndpointDeletes
+	if maxConcurrentDeletes <= 0 {
+		maxConcurrentDeletes = DefaultMaxConcurrentEndpointDeletes
+	}
+	errs.SetLimit(maxConcurrentDeletes)
 	for i := range atmProfile.Properties.Endpoints {
 		endpoint := atmProfile.Properties.Endpoints[i]
 		if endpoint.Name == nil {
@@ -243,11 +875,18 @@ func (r *Reconciler) cleanupEndpoints(ctx context.Context, resourceGroup string,
 			continue
 		}
 		// Traffic manager endpoint name is case-insensitive.
-		if !isEndpointOwnedByBackend(backend, *endpoint.Name) {
+		if !isEndpointOwnedByBackend(r.endpointNamePrefix(), backend, *endpoint.Name) {
 			continue // skipping deleting the endpoints which are not created by this backend
 		}
 		errs.Go(func() error {
-			if _, err := r.EndpointsClient.Delete(cctx, resourceGroup, atmProfileName, armtrafficmanager.EndpointTypeAzureEndpoints, *endpoint.Name, nil); err != nil {
+			// Retry the throttled requests with the exponential backoff and jitter; other errors are returned directly.
+			err := retry.OnError(deleteEndpointThrottledBackoff, azureerrors.IsThrottled, func() error {
+				startTime := time.Now()
+				_, deleteErr := clients.EndpointsClient.Delete(cctx, resourceGroup, atmProfileName, azureTrafficManagerEndpointType(*endpoint), *endpoint.Name, nil)
+				metrics.ObserveAzureAPICall(metrics.AzureAPIClientEndpoints, metrics.AzureAPIOperationDelete, startTime, deleteErr)
+				return deleteErr
+			})
+			if err != nil {
 				if azureerrors.IsNotFound(err) {
 					klog.V(2).InfoS("Ignoring NotFound Azure Traffic Manager endpoint", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfileName", atmProfileName, "atmEndpoint", *endpoint.Name)
 					return nil
@@ -262,12 +901,42 @@ func (r *Reconciler) cleanupEndpoints(ctx context.Context, resourceGroup string,
 	return errs.Wait()
 }
 
-func isEndpointOwnedByBackend(backend *fleetnetv1beta1.TrafficManagerBackend, endpoint string) bool {
-	return strings.HasPrefix(endpoint, generateAzureTrafficManagerEndpointNamePrefixFunc(backend))
+// trafficManagerProfileNamespacedName returns the namespaced name of the trafficManagerProfile referenced by the backend.
+// The profile is in the same namespace as the backend when the namespace is not specified.
+func trafficManagerProfileNamespacedName(backend *fleetnetv1beta1.TrafficManagerBackend) types.NamespacedName {
+	namespace := backend.Spec.Profile.Namespace
+	if namespace == "" {
+		namespace = backend.Namespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: backend.Spec.Profile.Name}
+}
+
+// endpointNamePrefix returns the prefix of the Azure Traffic Manager endpoint names created by the controller.
+func (r *Reconciler) endpointNamePrefix() string {
+	if r.EndpointNamePrefix == "" {
+		return DefaultAzureResourceEndpointNamePrefix
+	}
+	return r.EndpointNamePrefix
+}
+
+// isEndpointOwnedByBackend returns true if the endpoint name has the endpoint name prefix of the backend created by the
+// controller with the endpoint name prefix.
+// The comparison is case-insensitive as the Azure Traffic Manager endpoint names are case-insensitive, while the names
+// are lowercased in some code paths and the backend UID may contain uppercase characters.
+func isEndpointOwnedByBackend(namePrefix string, backend *fleetnetv1beta1.TrafficManagerBackend, endpoint string) bool {
+	return strings.HasPrefix(strings.ToLower(endpoint), strings.ToLower(generateAzureTrafficManagerEndpointNamePrefixFunc(namePrefix, backend)))
 }
 
 func (r *Reconciler) handleUpdate(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend) (ctrl.Result, error) {
 	backendKObj := klog.KObj(backend)
+	if isSuspended(backend) {
+		klog.V(2).InfoS("TrafficManagerBackend is suspended and skipping reconciling the endpoints", "trafficManagerBackend", backendKObj)
+		// Keep reporting the endpoints accepted before the suspension as they are left untouched.
+		setFalseConditionWithReason(backend, backend.Status.Endpoints, fleetnetv1beta1.TrafficManagerBackendReasonSuspended,
+			"Reconciliation is suspended and the existing Azure Traffic Manager endpoints are left untouched")
+		return ctrl.Result{}, r.updateTrafficManagerBackendStatus(ctx, backend)
+	}
+
 	profile, err := r.validateTrafficManagerProfile(ctx, backend)
 	if err != nil || profile == nil {
 		// We don't need to requeue the invalid Profile (err == nil and profile == nil) because when the profile becomes
@@ -278,7 +947,12 @@ func (r *Reconciler) handleUpdate(ctx context.Context, backend *fleetnetv1beta1.
 	profileKObj := klog.KObj(profile)
 	klog.V(2).InfoS("Found the valid trafficManagerProfile", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileKObj)
 
-	atmProfile, err := r.validateAzureTrafficManagerProfile(ctx, backend, profile)
+	clients, err := r.azureClients(profile.Spec.SubscriptionID)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get Azure Traffic Manager clients", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileKObj, "subscriptionID", profile.Spec.SubscriptionID)
+		return ctrl.Result{}, err
+	}
+	atmProfile, err := r.validateAzureTrafficManagerProfile(ctx, clients, backend, profile)
 	if err != nil || atmProfile == nil {
 		// We don't need to requeue the invalid Azure Traffic Manager profile (err == nil and atmProfile == nil) as when
 		// the profile becomes valid, the controller will be re-triggered again.
@@ -287,7 +961,11 @@ func (r *Reconciler) handleUpdate(ctx context.Context, backend *fleetnetv1beta1.
 	}
 	klog.V(2).InfoS("Found the valid Azure Traffic Manager Profile", "resourceGroup", profile.Spec.ResourceGroup, "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileKObj, "atmProfileName", atmProfile.Name)
 
-	serviceImport, err := r.validateServiceImportAndCleanupEndpointsIfInvalid(ctx, profile.Spec.ResourceGroup, backend, atmProfile)
+	if err := r.cleanupEndpointsInStaleAzureTrafficManagerProfile(ctx, backend, atmProfile); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	serviceImport, err := r.validateServiceImportAndCleanupEndpointsIfInvalid(ctx, clients, profile.Spec.ResourceGroup, backend, atmProfile)
 	if err != nil || serviceImport == nil {
 		// We don't need to requeue the invalid serviceImport (err == nil and serviceImport == nil) as when the serviceImport
 		// becomes valid, the controller will be re-triggered again.
@@ -297,25 +975,63 @@ func (r *Reconciler) handleUpdate(ctx context.Context, backend *fleetnetv1beta1.
 
 	klog.V(2).InfoS("Found the serviceImport", "trafficManagerBackend", backendKObj, "serviceImport", klog.KObj(serviceImport), "clusters", serviceImport.Status.Clusters)
 
+	additionalServiceImports, err := r.getAdditionalServiceImports(ctx, backend)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	serviceImports := append([]*fleetnetv1alpha1.ServiceImport{serviceImport}, additionalServiceImports...)
+
 	if *backend.Spec.Weight == 0 {
 		klog.V(2).InfoS("Weight is 0, deleting all the endpoints", "trafficManagerBackend", backendKObj)
-		if err := r.cleanupEndpoints(ctx, profile.Spec.ResourceGroup, backend, atmProfile); err != nil {
-			r.Recorder.Eventf(backend, corev1.EventTypeWarning, backendEventReasonAzureAPIError, "Failed to delete Azure Traffic Manager endpoints: %v", err)
+		if err := r.cleanupEndpoints(ctx, clients, profile.Spec.ResourceGroup, backend, atmProfile); err != nil {
+			r.recordAzureAPIErrorEvent(backend, err, "Failed to delete Azure Traffic Manager endpoints: %v", err)
+			klog.ErrorS(err, "Failed to delete Azure Traffic Manager endpoints for the zero weight", "trafficManagerBackend", backendKObj)
 			return ctrl.Result{}, err
 		}
 		r.Recorder.Eventf(backend, corev1.EventTypeNormal, backendEventReasonAccepted, "Successfully removed all endpoints from Azure Traffic Manager due to zero weight")
+		if err := r.updateInternalServiceExportsExposedCondition(ctx, backend, nil, nil, nil); err != nil {
+			return ctrl.Result{}, err
+		}
 		setTrueCondition(backend, nil)
 		return ctrl.Result{}, r.updateTrafficManagerBackendStatus(ctx, backend)
 	}
 
-	desiredEndpointsMaps, invalidServicesMaps, err := r.validateAndProcessServiceImportForBackend(ctx, backend, serviceImport)
+	desiredEndpointsMaps, invalidServicesMaps, err := r.validateAndProcessServiceImportForBackend(ctx, profile, atmProfile, backend, serviceImports...)
+	if errors.Is(err, errNoExportedServices) {
+		// The serviceImport event re-triggers the controller once the services are exported, while requeue the request
+		// to turn the Unknown condition into False when the threshold is exceeded.
+		cond := meta.FindStatusCondition(backend.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted))
+		result := noExportedServicesRequeueResult(cond, r.noExportedServicesThreshold(), time.Now())
+		klog.V(2).InfoS("Requeue the trafficManagerBackend for the serviceImports without exported services", "trafficManagerBackend", backendKObj, "requeueAfter", result.RequeueAfter)
+		return result, nil
+	}
+	if errors.Is(err, errInternalServiceExportNotFound) {
+		// The serviceImport event usually re-triggers the controller, while requeue the request with a bounded backoff
+		// in case the serviceImport status has been updated before the internalServiceExport is created.
+		cond := meta.FindStatusCondition(backend.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted))
+		result := missingExportRequeueResult(cond, time.Now())
+		klog.V(2).InfoS("Requeue the trafficManagerBackend for the missing internalServiceExport", "trafficManagerBackend", backendKObj, "requeueAfter", result.RequeueAfter)
+		return result, nil
+	}
 	if err != nil || (desiredEndpointsMaps == nil && invalidServicesMaps == nil) {
 		// We don't need to requeue not found internalServiceExport(err == nil and desiredEndpointsMaps == nil && invalidServicesMaps == nil)
 		// as when the serviceImport is updated, the controller will be re-triggered again.
 		// The controller will retry when err is not nil.
 		return ctrl.Result{}, err
 	}
-	klog.V(2).InfoS("Found the exported services behind the serviceImport", "trafficManagerBackend", backendKObj, "serviceImport", klog.KObj(serviceImport), "numberOfDesiredEndpoints", len(desiredEndpointsMaps), "numberOfInvalidServices", len(invalidServicesMaps))
+	klog.V(2).InfoS("Found the exported services behind the serviceImports", "trafficManagerBackend", backendKObj, "serviceImports", klog.KObjSlice(serviceImports), "numberOfDesiredEndpoints", len(desiredEndpointsMaps), "numberOfInvalidServices", len(invalidServicesMaps))
+
+	maxEndpoints := r.MaxEndpointsPerProfile
+	if maxEndpoints <= 0 {
+		maxEndpoints = DefaultMaxEndpointsPerProfile
+	}
+	droppedClusters := limitDesiredEndpoints(r.endpointNamePrefix(), backend, atmProfile, desiredEndpointsMaps, maxEndpoints)
+	if len(droppedClusters) > 0 {
+		klog.V(2).InfoS("Exceeded the maximum number of endpoints in the Azure Traffic Manager profile", "trafficManagerBackend", backendKObj, "atmProfileName", atmProfile.Name, "maxEndpointsPerProfile", maxEndpoints, "droppedClusters", droppedClusters)
+	}
+	if err := r.updateInternalServiceExportsExposedCondition(ctx, backend, desiredEndpointsMaps, invalidServicesMaps, droppedClusters); err != nil {
+		return ctrl.Result{}, err
+	}
 
 	// register finalizer only before creating atm endpoints
 	// So that when a user specifies an invalid resource group of the profile, the controller will fail to create the endpoint because of the 403 error.
@@ -326,18 +1042,37 @@ func (r *Reconciler) handleUpdate(ctx context.Context, backend *fleetnetv1beta1.
 			klog.ErrorS(err, "Failed to add finalizer to trafficManagerBackend", "trafficManagerBackend", backend)
 			return ctrl.Result{}, controller.NewUpdateIgnoreConflictError(err)
 		}
+		// It's persisted with the next status update, so that the deletion can skip calling Azure when none of the
+		// endpoints is ever created, for example, the first createOrUpdate request fails with 403.
+		backend.Status.EndpointsCreated = ptr.To(false)
 	}
 
-	acceptedEndpoints, badEndpointsErr, err := r.updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(ctx, profile.Spec.ResourceGroup, backend, atmProfile, desiredEndpointsMaps)
+	acceptedEndpoints, badEndpointsErr, err := r.updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(ctx, clients, profile.Spec.ResourceGroup, backend, atmProfile, desiredEndpointsMaps)
+	if errors.Is(err, errAuthorizationFailed) {
+		// Retrying at a high frequency does not help until the permission is granted, so that the request is requeued
+		// with a long delay instead of the rate limiter.
+		klog.V(2).InfoS("Requeue the trafficManagerBackend for the authorization failure", "trafficManagerBackend", backendKObj, "requeueAfter", authorizationFailedRequeueDelay)
+		return ctrl.Result{RequeueAfter: authorizationFailedRequeueDelay}, nil
+	}
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	if len(invalidServicesMaps) == 0 && len(badEndpointsErr) == 0 {
+	if profile.Spec.RoutingMethod == "" || profile.Spec.RoutingMethod == fleetnetv1beta1.TrafficManagerRoutingMethodWeighted {
+		setEndpointPercentages(acceptedEndpoints)
+	}
+	if len(invalidServicesMaps) == 0 && len(badEndpointsErr) == 0 && len(droppedClusters) == 0 {
 		setTrueCondition(backend, acceptedEndpoints)
 	} else {
+		// The endpoints have been synced with Azure even though some of them are not accepted.
+		backend.Status.LastSyncTime = ptr.To(metav1.Now())
 		var invalidEndpointErrMessage string
+		reason := fleetnetv1beta1.TrafficManagerBackendReasonInvalid
+		if len(droppedClusters) > 0 {
+			reason = fleetnetv1beta1.TrafficManagerBackendReasonEndpointLimitExceeded
+			invalidEndpointErrMessage = fmt.Sprintf("%d service(s) exported from clusters %v are not exposed because the Azure Traffic Manager profile allows at most %d endpoints; ", len(droppedClusters), droppedClusters, maxEndpoints)
+		}
 		if len(badEndpointsErr) > 0 {
-			invalidEndpointErrMessage = fmt.Sprintf("%d endpoint(s) failed to be created/updated in the Azure Traffic Manager, for example, %v; ", len(badEndpointsErr), badEndpointsErr[0])
+			invalidEndpointErrMessage += fmt.Sprintf("%d endpoint(s) failed to be created/updated in the Azure Traffic Manager, for example, %v; ", len(badEndpointsErr), badEndpointsErr[0])
 		}
 		if len(invalidServicesMaps) > 0 {
 			for clusterID, invalidServiceErr := range invalidServicesMaps {
@@ -347,17 +1082,116 @@ func (r *Reconciler) handleUpdate(ctx context.Context, backend *fleetnetv1beta1.
 				break
 			}
 		}
-		setFalseCondition(backend, acceptedEndpoints, invalidEndpointErrMessage)
+		setFalseConditionWithReason(backend, acceptedEndpoints, reason, invalidEndpointErrMessage)
 	}
+	backend.Status.InvalidEndpoints = buildInvalidEndpointStatuses(backend, invalidServicesMaps, badEndpointsErr)
+	emitTrafficManagerBackendEndpointsMetric(backend, len(acceptedEndpoints), len(invalidServicesMaps), len(badEndpointsErr))
 	klog.V(2).InfoS("Updated Traffic Manager endpoints for the serviceImport and updating the condition", "trafficManagerBackend", backendKObj, "status", backend.Status)
 	if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	// If there are any failed endpoints, we need to requeue the request to retry.
-	// For any invalidService, we don't need to requeue the request as the controller will be re-triggered when the
-	// serviceImport or internalServiceExport is updated.
-	return ctrl.Result{}, errors.Join(badEndpointsErr...)
+	// For any invalidService or user error (for example, priority collision), we don't need to requeue the request as
+	// the controller will be re-triggered when the serviceImport or internalServiceExport is updated.
+	retriableErrs := make([]error, 0, len(badEndpointsErr))
+	for _, err := range badEndpointsErr {
+		if !errors.Is(err, controller.ErrUserError) {
+			retriableErrs = append(retriableErrs, err)
+		}
+	}
+	if err := errors.Join(retriableErrs...); err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(acceptedEndpoints) == 0 {
+		return ctrl.Result{}, nil
+	}
+	// Requeue the request to refresh the monitor status of the accepted endpoints even when nothing is changed.
+	return ctrl.Result{RequeueAfter: r.EndpointMonitorResyncInterval}, nil
+}
+
+// updateInternalServiceExportsExposedCondition sets the ExposedAsTrafficManagerEndpoint condition on the
+// internalServiceExports behind the backend, so that the member cluster owners can find out why their services are not
+// exposed without accessing the trafficManagerBackend.
+// The condition is removed from the internalServiceExports which are neither desired, invalid nor dropped, for
+// example, the ones excluded by the cluster selector or all of them when the backend is being deleted.
+// The keys of the invalidServices and droppedClusters are the exportedServiceKeys.
+func (r *Reconciler) updateInternalServiceExportsExposedCondition(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend, desiredEndpoints map[string]desiredEndpoint, invalidServices map[string]error, droppedClusters []string) error {
+	backendKObj := klog.KObj(backend)
+	exposedServices := make(map[string]bool, len(desiredEndpoints)) // key is the exportedServiceKey
+	for _, dp := range desiredEndpoints {
+		exposedServices[dp.key()] = true
+	}
+
+	var errs []error
+	for _, serviceImportName := range serviceImportNames(backend) {
+		internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+		namespacedName := types.NamespacedName{Namespace: backend.Namespace, Name: serviceImportName}
+		listOpts := client.MatchingFields{
+			exportedServiceFieldNamespacedName: namespacedName.String(),
+		}
+		if err := r.Client.List(ctx, internalServiceExportList, &listOpts); err != nil {
+			klog.ErrorS(err, "Failed to list internalServiceExports used by the trafficManagerBackend", "trafficManagerBackend", backendKObj, "service", namespacedName)
+			return err
+		}
+		for i := range internalServiceExportList.Items {
+			export := &internalServiceExportList.Items[i]
+			if err := r.updateInternalServiceExportExposedCondition(ctx, backend, export, exportedServiceKey(backend, serviceImportName, export.Spec.ServiceReference.ClusterID), exposedServices, invalidServices, droppedClusters); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// updateInternalServiceExportExposedCondition sets or removes the ExposedAsTrafficManagerEndpoint condition of the
+// internalServiceExport whose exportedServiceKey is the key.
+func (r *Reconciler) updateInternalServiceExportExposedCondition(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend, export *fleetnetv1alpha1.InternalServiceExport, key string, exposedServices map[string]bool, invalidServices map[string]error, droppedServices []string) error {
+	backendKObj := klog.KObj(backend)
+	var desired *metav1.Condition
+	switch {
+	case errors.Is(invalidServices[key], errServiceConflict):
+		desired = buildExposedCondition(export, metav1.ConditionFalse, exposedConditionReasonServiceConflict,
+			fmt.Sprintf("Service cannot be exposed as an Azure Traffic Manager endpoint by trafficManagerBackend %q: %v", backend.Name, invalidServices[key]))
+	case invalidServices[key] != nil:
+		desired = buildExposedCondition(export, metav1.ConditionFalse, exposedConditionReasonInvalid,
+			fmt.Sprintf("Service cannot be exposed as an Azure Traffic Manager endpoint by trafficManagerBackend %q: %v", backend.Name, invalidServices[key]))
+	case slices.Contains(droppedServices, key):
+		desired = buildExposedCondition(export, metav1.ConditionFalse, exposedConditionReasonEndpointLimitExceeded,
+			fmt.Sprintf("Service is not exposed by trafficManagerBackend %q because the Azure Traffic Manager profile has reached the maximum number of endpoints", backend.Name))
+	case exposedServices[key]:
+		desired = buildExposedCondition(export, metav1.ConditionTrue, exposedConditionReasonExposed,
+			fmt.Sprintf("Service is exposed as an Azure Traffic Manager endpoint by trafficManagerBackend %q", backend.Name))
+	}
+
+	current := meta.FindStatusCondition(export.Status.Conditions, string(fleetnetv1beta1.ServiceExportExposedAsTrafficManagerEndpoint))
+	switch {
+	case desired == nil && current == nil:
+		return nil
+	case desired == nil:
+		meta.RemoveStatusCondition(&export.Status.Conditions, string(fleetnetv1beta1.ServiceExportExposedAsTrafficManagerEndpoint))
+	case current != nil && current.Status == desired.Status && current.Reason == desired.Reason &&
+		current.Message == desired.Message && current.ObservedGeneration == desired.ObservedGeneration:
+		return nil
+	default:
+		meta.SetStatusCondition(&export.Status.Conditions, *desired)
+	}
+	klog.V(2).InfoS("Updating the internalServiceExport status", "trafficManagerBackend", backendKObj, "internalServiceExport", klog.KObj(export), "condition", desired)
+	if err := r.Client.Status().Update(ctx, export); err != nil {
+		klog.ErrorS(err, "Failed to update the internalServiceExport status", "trafficManagerBackend", backendKObj, "internalServiceExport", klog.KObj(export))
+		return controller.NewUpdateIgnoreConflictError(err)
+	}
+	return nil
+}
+
+func buildExposedCondition(export *fleetnetv1alpha1.InternalServiceExport, status metav1.ConditionStatus, reason, message string) *metav1.Condition {
+	return &metav1.Condition{
+		Type:               string(fleetnetv1beta1.ServiceExportExposedAsTrafficManagerEndpoint),
+		Status:             status,
+		ObservedGeneration: export.Generation,
+		Reason:             reason,
+		Message:            message,
+	}
 }
 
 // validateTrafficManagerProfile returns not nil profile when the profile is valid.
@@ -365,13 +1199,14 @@ func (r *Reconciler) validateTrafficManagerProfile(ctx context.Context, backend
 	backendKObj := klog.KObj(backend)
 	var cond metav1.Condition
 	profile := &fleetnetv1beta1.TrafficManagerProfile{}
-	if getProfileErr := r.Client.Get(ctx, types.NamespacedName{Name: backend.Spec.Profile.Name, Namespace: backend.Namespace}, profile); getProfileErr != nil {
+	profileName := trafficManagerProfileNamespacedName(backend)
+	if getProfileErr := r.Client.Get(ctx, profileName, profile); getProfileErr != nil {
 		if apierrors.IsNotFound(getProfileErr) {
-			klog.V(2).InfoS("NotFound trafficManagerProfile", "trafficManagerBackend", backendKObj, "trafficManagerProfile", backend.Spec.Profile.Name)
+			klog.V(2).InfoS("NotFound trafficManagerProfile", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileName)
 			setFalseCondition(backend, nil, fmt.Sprintf("TrafficManagerProfile %q is not found", backend.Spec.Profile.Name))
 			return nil, r.updateTrafficManagerBackendStatus(ctx, backend)
 		}
-		klog.ErrorS(getProfileErr, "Failed to get trafficManagerProfile", "trafficManagerBackend", backendKObj, "trafficManagerProfile", backend.Spec.Profile.Name)
+		klog.ErrorS(getProfileErr, "Failed to get trafficManagerProfile", "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileName)
 		setUnknownCondition(backend, fmt.Sprintf("Failed to get the trafficManagerProfile %q: %v", backend.Spec.Profile.Name, getProfileErr))
 		if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
 			return nil, err
@@ -391,14 +1226,16 @@ func (r *Reconciler) validateTrafficManagerProfile(ctx context.Context, backend
 }
 
 // validateAzureTrafficManagerProfile returns not nil Azure Traffic Manager profile when the atm profile is valid.
-func (r *Reconciler) validateAzureTrafficManagerProfile(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend, profile *fleetnetv1beta1.TrafficManagerProfile) (*armtrafficmanager.Profile, error) {
+func (r *Reconciler) validateAzureTrafficManagerProfile(ctx context.Context, clients *azureclient.TrafficManagerClients, backend *fleetnetv1beta1.TrafficManagerBackend, profile *fleetnetv1beta1.TrafficManagerProfile) (*armtrafficmanager.Profile, error) {
 	atmProfileName := generateAzureTrafficManagerProfileNameFunc(profile)
 	backendKObj := klog.KObj(backend)
 	profileKObj := klog.KObj(profile)
-	getRes, getErr := r.ProfilesClient.Get(ctx, profile.Spec.ResourceGroup, atmProfileName, nil)
+	startTime := time.Now()
+	getRes, getErr := clients.ProfilesClient.Get(ctx, profile.Spec.ResourceGroup, atmProfileName, nil)
+	metrics.ObserveAzureAPICall(metrics.AzureAPIClientProfiles, metrics.AzureAPIOperationGet, startTime, getErr)
 	if getErr != nil {
 		klog.ErrorS(getErr, "Failed to get Azure Traffic Manager profile", "resourceGroup", profile.Spec.ResourceGroup, "trafficManagerBackend", backendKObj, "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
-		r.Recorder.Eventf(backend, corev1.EventTypeWarning, backendEventReasonAzureAPIError, "Failed to get Azure Traffic Manager profile %q under %q: %v", atmProfileName, profile.Spec.ResourceGroup, getErr)
+		r.recordAzureAPIErrorEvent(backend, getErr, "Failed to get Azure Traffic Manager profile %q under %q: %v", atmProfileName, profile.Spec.ResourceGroup, getErr)
 		if azureerrors.IsNotFound(getErr) {
 			// We've already checked the TrafficManagerProfile condition before getting Azure resource.
 			// It may happen when
@@ -410,213 +1247,1753 @@ func (r *Reconciler) validateAzureTrafficManagerProfile(ctx context.Context, bac
 			setFalseCondition(backend, nil, fmt.Sprintf("Azure Traffic Manager profile %q under %q is not found", atmProfileName, profile.Spec.ResourceGroup))
 			return nil, r.updateTrafficManagerBackendStatus(ctx, backend)
 		}
-		setUnknownCondition(backend, fmt.Sprintf("Failed to get the Azure Traffic Manager profile %q under %q: %v", atmProfileName, profile.Spec.ResourceGroup, getErr))
-		if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
-			return nil, err
+		setUnknownCondition(backend, fmt.Sprintf("Failed to get the Azure Traffic Manager profile %q under %q: %v", atmProfileName, profile.Spec.ResourceGroup, getErr))
+		if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
+			return nil, err
+		}
+		return nil, getErr // need to return the error to requeue the request
+	}
+	setProfileInSyncCondition(backend, profile, &getRes.Profile)
+	return &getRes.Profile, nil
+}
+
+// setProfileInSyncCondition sets the ProfileInSync condition as false when the monitor settings of the Azure Traffic
+// Manager profile differ from the trafficManagerProfile, otherwise removes the condition.
+func setProfileInSyncCondition(backend *fleetnetv1beta1.TrafficManagerBackend, profile *fleetnetv1beta1.TrafficManagerProfile, atmProfile *armtrafficmanager.Profile) {
+	var current *armtrafficmanager.MonitorConfig
+	if atmProfile.Properties != nil {
+		current = atmProfile.Properties.MonitorConfig
+	}
+	// Apply the same defaults as the trafficManagerProfile controller does when configuring the Azure profile.
+	desiredProfile := profile.DeepCopy()
+	defaulter.SetDefaultsTrafficManagerProfile(desiredProfile)
+	diffs := diffAzureTrafficManagerMonitorConfig(desiredProfile.Spec.MonitorConfig, current)
+	if len(diffs) == 0 {
+		meta.RemoveStatusCondition(&backend.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionProfileInSync))
+		return
+	}
+	klog.V(2).InfoS("Azure Traffic Manager profile monitor config drifts from the trafficManagerProfile", "trafficManagerBackend", klog.KObj(backend), "trafficManagerProfile", klog.KObj(profile), "diffs", diffs)
+	meta.SetStatusCondition(&backend.Status.Conditions, metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionProfileInSync),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: backend.Generation,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonProfileDrift),
+		Message:            fmt.Sprintf("The monitor config of the Azure Traffic Manager profile differs from trafficManagerProfile %q: %s", profile.Name, strings.Join(diffs, ", ")),
+	})
+}
+
+// diffAzureTrafficManagerMonitorConfig returns the fields which differ between the desired monitor config and the
+// current one of the Azure Traffic Manager profile, in the format of "field (desired: x, actual: y)".
+// The fields which are not set in the desired monitor config are ignored.
+func diffAzureTrafficManagerMonitorConfig(desired *fleetnetv1beta1.MonitorConfig, current *armtrafficmanager.MonitorConfig) []string {
+	if desired == nil {
+		return nil
+	}
+	if current == nil {
+		current = &armtrafficmanager.MonitorConfig{}
+	}
+	var diffs []string
+	if desired.Protocol != nil && (current.Protocol == nil || !strings.EqualFold(string(*desired.Protocol), string(*current.Protocol))) {
+		diffs = append(diffs, formatFieldDiff("protocol", *desired.Protocol, current.Protocol))
+	}
+	if desired.Port != nil && (current.Port == nil || *desired.Port != *current.Port) {
+		diffs = append(diffs, formatFieldDiff("port", *desired.Port, current.Port))
+	}
+	// The path is not used when probing with TCP.
+	isTCP := desired.Protocol != nil && *desired.Protocol == fleetnetv1beta1.TrafficManagerMonitorProtocolTCP
+	if !isTCP && desired.Path != nil && (current.Path == nil || *desired.Path != *current.Path) {
+		diffs = append(diffs, formatFieldDiff("path", *desired.Path, current.Path))
+	}
+	if desired.IntervalInSeconds != nil && (current.IntervalInSeconds == nil || *desired.IntervalInSeconds != *current.IntervalInSeconds) {
+		diffs = append(diffs, formatFieldDiff("intervalInSeconds", *desired.IntervalInSeconds, current.IntervalInSeconds))
+	}
+	if desired.TimeoutInSeconds != nil && (current.TimeoutInSeconds == nil || *desired.TimeoutInSeconds != *current.TimeoutInSeconds) {
+		diffs = append(diffs, formatFieldDiff("timeoutInSeconds", *desired.TimeoutInSeconds, current.TimeoutInSeconds))
+	}
+	if desired.ToleratedNumberOfFailures != nil && (current.ToleratedNumberOfFailures == nil || *desired.ToleratedNumberOfFailures != *current.ToleratedNumberOfFailures) {
+		diffs = append(diffs, formatFieldDiff("toleratedNumberOfFailures", *desired.ToleratedNumberOfFailures, current.ToleratedNumberOfFailures))
+	}
+	return diffs
+}
+
+// formatFieldDiff formats the field which differs in the format of "field (desired: x, actual: y)".
+func formatFieldDiff[D any, C any](field string, desired D, current *C) string {
+	if current == nil {
+		return fmt.Sprintf("%s (desired: %v, actual: <unset>)", field, desired)
+	}
+	return fmt.Sprintf("%s (desired: %v, actual: %v)", field, desired, *current)
+}
+
+// validateServiceImportAndCleanupEndpointsIfInvalid returns not nil serviceImport when the serviceImport is valid.
+func (r *Reconciler) validateServiceImportAndCleanupEndpointsIfInvalid(ctx context.Context, clients *azureclient.TrafficManagerClients, resourceGroup string, backend *fleetnetv1beta1.TrafficManagerBackend, azureProfile *armtrafficmanager.Profile) (*fleetnetv1alpha1.ServiceImport, error) {
+	backendKObj := klog.KObj(backend)
+	var cond metav1.Condition
+	serviceImport := &fleetnetv1alpha1.ServiceImport{}
+	if getServiceImportErr := r.Client.Get(ctx, types.NamespacedName{Name: backend.Spec.Backend.Name, Namespace: backend.Namespace}, serviceImport); getServiceImportErr != nil {
+		if apierrors.IsNotFound(getServiceImportErr) {
+			klog.V(2).InfoS("NotFound serviceImport and starting deleting any stale endpoints", "trafficManagerBackend", backendKObj, "serviceImport", backend.Spec.Backend.Name)
+			if err := r.cleanupEndpoints(ctx, clients, resourceGroup, backend, azureProfile); err != nil {
+				r.recordAzureAPIErrorEvent(backend, err, "Failed to delete stale endpoints for an invalid serviceImport: %v", err)
+				klog.ErrorS(err, "Failed to delete stale endpoints for an invalid serviceImport", "trafficManagerBackend", backendKObj, "serviceImport", backend.Spec.Backend.Name)
+				return nil, err
+			}
+			message := fmt.Sprintf("ServiceImport %q is not found", backend.Spec.Backend.Name)
+			if len(backend.Status.Endpoints) == 0 && hasAcceptedCondition(backend, metav1.ConditionFalse, fleetnetv1beta1.TrafficManagerBackendReasonInvalid, message) {
+				// Skip the duplicate status update while the serviceImport is still missing.
+				return nil, nil
+			}
+			cond = metav1.Condition{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: backend.Generation,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonInvalid),
+				Message:            message,
+			}
+			meta.SetStatusCondition(&backend.Status.Conditions, cond)
+			backend.Status.Endpoints = []fleetnetv1beta1.TrafficManagerEndpointStatus{} // none of the endpoints are accepted by the TrafficManager
+			return nil, r.updateTrafficManagerBackendStatus(ctx, backend)
+		}
+		klog.ErrorS(getServiceImportErr, "Failed to get serviceImport", "trafficManagerBackend", backendKObj, "serviceImport", backend.Spec.Backend.Name)
+		setUnknownCondition(backend, fmt.Sprintf("Failed to get the serviceImport %q: %v", backend.Spec.Profile.Name, getServiceImportErr))
+		if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
+			return nil, err
+		}
+		return nil, getServiceImportErr // need to return the error to requeue the request
+	}
+	if serviceImport.DeletionTimestamp != nil {
+		// The serviceImport may be recreated right after the deletion, for example, when the service is re-exported.
+		// Leave the endpoints untouched instead of deleting and recreating them, and the controller will be re-triggered
+		// once the serviceImport is gone or recreated.
+		klog.V(2).InfoS("ServiceImport is being deleted and skipping reconciling the endpoints", "trafficManagerBackend", backendKObj, "serviceImport", klog.KObj(serviceImport))
+		message := fmt.Sprintf("ServiceImport %q is being deleted", backend.Spec.Backend.Name)
+		if hasAcceptedCondition(backend, metav1.ConditionUnknown, fleetnetv1beta1.TrafficManagerBackendReasonPending, message) {
+			// Skip the duplicate status update while the serviceImport is still being deleted.
+			return nil, nil
+		}
+		setUnknownCondition(backend, message)
+		return nil, r.updateTrafficManagerBackendStatus(ctx, backend)
+	}
+	return serviceImport, nil
+}
+
+// getAdditionalServiceImports returns the serviceImports listed in spec.backend.additionalNames which are found.
+// The missing serviceImports are skipped so that the traffic keeps flowing to the others, for example, before the green
+// deployment is exported, and the endpoints previously created for them are deleted as they are not desired.
+func (r *Reconciler) getAdditionalServiceImports(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend) ([]*fleetnetv1alpha1.ServiceImport, error) {
+	backendKObj := klog.KObj(backend)
+	serviceImports := make([]*fleetnetv1alpha1.ServiceImport, 0, len(backend.Spec.Backend.AdditionalNames))
+	for _, name := range backend.Spec.Backend.AdditionalNames {
+		serviceImport := &fleetnetv1alpha1.ServiceImport{}
+		if getServiceImportErr := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: backend.Namespace}, serviceImport); getServiceImportErr != nil {
+			if apierrors.IsNotFound(getServiceImportErr) {
+				klog.V(2).InfoS("NotFound additional serviceImport and skipping it", "trafficManagerBackend", backendKObj, "serviceImport", name)
+				continue
+			}
+			klog.ErrorS(getServiceImportErr, "Failed to get serviceImport", "trafficManagerBackend", backendKObj, "serviceImport", name)
+			setUnknownCondition(backend, fmt.Sprintf("Failed to get the serviceImport %q: %v", name, getServiceImportErr))
+			if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
+				return nil, err
+			}
+			return nil, getServiceImportErr // need to return the error to requeue the request
+		}
+		serviceImports = append(serviceImports, serviceImport)
+	}
+	return serviceImports, nil
+}
+
+func setFalseCondition(backend *fleetnetv1beta1.TrafficManagerBackend, acceptedEndpoints []fleetnetv1beta1.TrafficManagerEndpointStatus, message string) {
+	setFalseConditionWithReason(backend, acceptedEndpoints, fleetnetv1beta1.TrafficManagerBackendReasonInvalid, message)
+}
+
+func setFalseConditionWithReason(backend *fleetnetv1beta1.TrafficManagerBackend, acceptedEndpoints []fleetnetv1beta1.TrafficManagerEndpointStatus, reason fleetnetv1beta1.TrafficManagerBackendConditionReason, message string) {
+	cond := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: backend.Generation,
+		Reason:             string(reason),
+		Message:            message,
+	}
+	if len(acceptedEndpoints) == 0 {
+		backend.Status.Endpoints = []fleetnetv1beta1.TrafficManagerEndpointStatus{}
+	} else {
+		backend.Status.Endpoints = acceptedEndpoints
+	}
+	backend.Status.InvalidEndpoints = nil
+	meta.SetStatusCondition(&backend.Status.Conditions, cond)
+}
+
+// setAuthorizationFailedCondition sets the Accepted condition to false with the action and scope which the Azure
+// identity is not authorized to perform over, and returns the errAuthorizationFailed once the status is updated.
+func (r *Reconciler) setAuthorizationFailedCondition(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend, acceptedEndpoints []fleetnetv1beta1.TrafficManagerEndpointStatus, authErr error) error {
+	var message string
+	if action, scope := azureerrors.AuthorizationFailedDetails(authErr); action != "" {
+		message = fmt.Sprintf("The Azure identity is not authorized to perform action %q over scope %q, please grant the permission", action, scope)
+	} else {
+		message = fmt.Sprintf("The Azure identity is not authorized to manage the Azure Traffic Manager endpoints, please grant the permission: %v", authErr)
+	}
+	setFalseConditionWithReason(backend, acceptedEndpoints, fleetnetv1beta1.TrafficManagerBackendReasonAuthorizationFailed, message)
+	if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
+		return err
+	}
+	return errAuthorizationFailed
+}
+
+// setUnknownCondition sets the accepted condition to unknown while keeping the last-known accepted endpoints, as the
+// Azure endpoints still exist and serve the traffic during the transient errors.
+func setUnknownCondition(backend *fleetnetv1beta1.TrafficManagerBackend, message string) {
+	cond := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: backend.Generation,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
+		Message:            message,
+	}
+	backend.Status.InvalidEndpoints = nil
+	meta.SetStatusCondition(&backend.Status.Conditions, cond)
+}
+
+// hasAcceptedCondition returns true if the backend already has the Accepted condition with the same status, reason and
+// message for the current generation.
+func hasAcceptedCondition(backend *fleetnetv1beta1.TrafficManagerBackend, status metav1.ConditionStatus, reason fleetnetv1beta1.TrafficManagerBackendConditionReason, message string) bool {
+	cond := meta.FindStatusCondition(backend.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted))
+	return cond != nil && cond.ObservedGeneration == backend.Generation && cond.Status == status &&
+		cond.Reason == string(reason) && cond.Message == message
+}
+
+// missingExportRequeueResult returns the result to requeue the request for the missing internalServiceExport.
+// The delay is the time elapsed since the Accepted condition became Unknown so that the total wait doubles on each
+// retry, bounded by the missingExportRequeueDelay and missingExportRequeueMaxDelay, and the request is no longer
+// requeued after the missingExportRequeueTimeout.
+func missingExportRequeueResult(cond *metav1.Condition, now time.Time) ctrl.Result {
+	if cond == nil || cond.Status != metav1.ConditionUnknown {
+		return ctrl.Result{RequeueAfter: missingExportRequeueDelay}
+	}
+	elapsed := now.Sub(cond.LastTransitionTime.Time)
+	if elapsed > missingExportRequeueTimeout {
+		return ctrl.Result{}
+	}
+	delay := elapsed
+	if delay < missingExportRequeueDelay {
+		delay = missingExportRequeueDelay
+	}
+	if delay > missingExportRequeueMaxDelay {
+		delay = missingExportRequeueMaxDelay
+	}
+	return ctrl.Result{RequeueAfter: delay}
+}
+
+func (r *Reconciler) azureAPIErrorEventInterval() time.Duration {
+	if r.AzureAPIErrorEventInterval == 0 {
+		return DefaultAzureAPIErrorEventInterval
+	}
+	return r.AzureAPIErrorEventInterval
+}
+
+// recordAzureAPIErrorEvent emits the AzureAPIError warning event of the backend at most once per
+// AzureAPIErrorEventInterval for each category of the errors, so that the events are not flooded by the retries during
+// a sustained failure, for example, the throttling. The callers are expected to log every error.
+func (r *Reconciler) recordAzureAPIErrorEvent(backend *fleetnetv1beta1.TrafficManagerBackend, err error, messageFmt string, args ...interface{}) {
+	category := azureerrors.Category(err)
+	if !r.azureAPIErrorEvents.allow(backend.UID, category, r.azureAPIErrorEventInterval(), time.Now()) {
+		klog.V(2).InfoS("Suppressed the AzureAPIError event as an event of the same error category was emitted recently",
+			"trafficManagerBackend", klog.KObj(backend), "category", category, "message", fmt.Sprintf(messageFmt, args...))
+		return
+	}
+	r.Recorder.Eventf(backend, corev1.EventTypeWarning, backendEventReasonAzureAPIError, messageFmt, args...)
+}
+
+func (r *Reconciler) noExportedServicesThreshold() time.Duration {
+	if r.NoExportedServicesThreshold <= 0 {
+		return DefaultNoExportedServicesThreshold
+	}
+	return r.NoExportedServicesThreshold
+}
+
+// hasNoExportedServicesExceededThreshold returns true if the Accepted condition has been Unknown because of no exported
+// services for longer than the threshold, or has already become False with the NoExportedServices reason.
+func hasNoExportedServicesExceededThreshold(backend *fleetnetv1beta1.TrafficManagerBackend, threshold time.Duration, now time.Time) bool {
+	cond := meta.FindStatusCondition(backend.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted))
+	switch {
+	case cond == nil:
+		return false
+	case cond.Status == metav1.ConditionFalse && cond.Reason == string(fleetnetv1beta1.TrafficManagerBackendReasonNoExportedServices):
+		return true
+	case cond.Status == metav1.ConditionUnknown && cond.Message == noExportedServicesMessage:
+		return now.Sub(cond.LastTransitionTime.Time) >= threshold
+	default:
+		return false
+	}
+}
+
+// noExportedServicesRequeueResult returns the result to requeue the request when the serviceImports have no exported
+// services, so that the Unknown Accepted condition becomes False once the threshold is exceeded.
+func noExportedServicesRequeueResult(cond *metav1.Condition, threshold time.Duration, now time.Time) ctrl.Result {
+	if cond == nil || cond.Status != metav1.ConditionUnknown {
+		return ctrl.Result{}
+	}
+	delay := threshold - now.Sub(cond.LastTransitionTime.Time)
+	if delay < missingExportRequeueDelay {
+		delay = missingExportRequeueDelay
+	}
+	return ctrl.Result{RequeueAfter: delay}
+}
+
+func setTrueCondition(backend *fleetnetv1beta1.TrafficManagerBackend, acceptedEndpoints []fleetnetv1beta1.TrafficManagerEndpointStatus) {
+	cond := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: backend.Generation,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonAccepted),
+		Message:            fmt.Sprintf("%v service(s) exported from clusters have been accepted as Traffic Manager endpoints", len(acceptedEndpoints)),
+	}
+	backend.Status.Endpoints = acceptedEndpoints
+	backend.Status.InvalidEndpoints = nil
+	backend.Status.LastSyncTime = ptr.To(metav1.Now())
+	meta.SetStatusCondition(&backend.Status.Conditions, cond)
+}
+
+// buildInvalidEndpointStatuses builds the status of the exported services which cannot be exposed from the invalid
+// services keyed by the exportedServiceKeys and the errors of the endpoints which failed to be created or updated,
+// sorted by the serviceImport and cluster.
+func buildInvalidEndpointStatuses(backend *fleetnetv1beta1.TrafficManagerBackend, invalidServices map[string]error, badEndpointsErr []error) []fleetnetv1beta1.InvalidEndpointStatus {
+	if len(invalidServices) == 0 && len(badEndpointsErr) == 0 {
+		return nil
+	}
+	res := make([]fleetnetv1beta1.InvalidEndpointStatus, 0, len(invalidServices)+len(badEndpointsErr))
+	for key, err := range invalidServices {
+		serviceImportName, cluster, found := strings.Cut(key, "/")
+		if !found {
+			serviceImportName, cluster = backend.Spec.Backend.Name, key
+		}
+		reason := fleetnetv1beta1.InvalidEndpointReasonInvalidService
+		if errors.Is(err, errServiceConflict) {
+			reason = fleetnetv1beta1.InvalidEndpointReasonServiceConflict
+		}
+		res = append(res, fleetnetv1beta1.InvalidEndpointStatus{
+			Cluster:       cluster,
+			ServiceImport: serviceImportName,
+			Reason:        reason,
+			Message:       err.Error(),
+		})
+	}
+	for _, err := range badEndpointsErr {
+		var badErr *badEndpointError
+		if !errors.As(err, &badErr) {
+			continue // should never happen
+		}
+		serviceImportName := badErr.endpoint.AdditionalServiceImportName
+		if serviceImportName == "" {
+			serviceImportName = backend.Spec.Backend.Name
+		}
+		res = append(res, fleetnetv1beta1.InvalidEndpointStatus{
+			Cluster:       badErr.endpoint.FromCluster.Cluster,
+			ServiceImport: serviceImportName,
+			Name:          ptr.Deref(badErr.endpoint.Endpoint.Name, ""),
+			Reason:        fleetnetv1beta1.InvalidEndpointReasonEndpointFailed,
+			Message:       badErr.err.Error(),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].ServiceImport != res[j].ServiceImport {
+			return res[i].ServiceImport < res[j].ServiceImport
+		}
+		return res[i].Cluster < res[j].Cluster
+	})
+	return res
+}
+
+// setMinimumHealthyEndpointsCondition sets the MinimumHealthyEndpointsMet condition based on the monitor status of the
+// accepted endpoints when the minHealthyEndpoints is set, otherwise removes the condition.
+// The condition is left unchanged while the Accepted condition is Unknown, as the endpoints are being reconciled and
+// not reported in the meantime.
+func setMinimumHealthyEndpointsCondition(backend *fleetnetv1beta1.TrafficManagerBackend) {
+	if backend.Spec.MinHealthyEndpoints == nil {
+		meta.RemoveStatusCondition(&backend.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionMinimumHealthyEndpointsMet))
+		return
+	}
+	if meta.IsStatusConditionPresentAndEqual(backend.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted), metav1.ConditionUnknown) {
+		return
+	}
+	healthy := 0
+	for _, endpoint := range backend.Status.Endpoints {
+		if endpoint.MonitorStatus != nil && *endpoint.MonitorStatus == fleetnetv1beta1.TrafficManagerEndpointMonitorStatusOnline {
+			healthy++
+		}
+	}
+	minHealthy := *backend.Spec.MinHealthyEndpoints
+	cond := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionMinimumHealthyEndpointsMet),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: backend.Generation,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonMinimumHealthyEndpointsMet),
+		Message:            fmt.Sprintf("%d of %d accepted endpoint(s) are online, meeting the minimum of %d", healthy, len(backend.Status.Endpoints), minHealthy),
+	}
+	if int64(healthy) < minHealthy {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(fleetnetv1beta1.TrafficManagerBackendReasonInsufficientHealthyEndpoints)
+		cond.Message = fmt.Sprintf("%d of %d accepted endpoint(s) are online, fewer than the minimum of %d", healthy, len(backend.Status.Endpoints), minHealthy)
+	}
+	meta.SetStatusCondition(&backend.Status.Conditions, cond)
+}
+
+// setReadyCondition sets the Ready condition summarizing the Accepted condition, which is only True when the profile is
+// programmed, the serviceImports are valid and all the desired endpoints are accepted without any invalid endpoints.
+func setReadyCondition(backend *fleetnetv1beta1.TrafficManagerBackend) {
+	cond := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: backend.Generation,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
+		Message:            "The trafficManagerBackend is being reconciled",
+	}
+	accepted := meta.FindStatusCondition(backend.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted))
+	switch {
+	case accepted == nil || accepted.ObservedGeneration != backend.Generation || accepted.Status == metav1.ConditionUnknown:
+	case accepted.Status == metav1.ConditionTrue && len(backend.Status.InvalidEndpoints) == 0:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = string(fleetnetv1beta1.TrafficManagerBackendReasonReady)
+		cond.Message = accepted.Message
+	default:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(fleetnetv1beta1.TrafficManagerBackendReasonNotAccepted)
+		cond.Message = fmt.Sprintf("The Accepted condition is %s with reason %q: %s", accepted.Status, accepted.Reason, accepted.Message)
+	}
+	meta.SetStatusCondition(&backend.Status.Conditions, cond)
+}
+
+// updateTrafficManagerBackendStatus applies the status of the trafficManagerBackend using the server-side apply, so that
+// the status fields owned by other field managers are not overwritten and no resource version conflict is raised when
+// the backend is updated concurrently.
+func (r *Reconciler) updateTrafficManagerBackendStatus(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend) error {
+	backendKObj := klog.KObj(backend)
+	setMinimumHealthyEndpointsCondition(backend)
+	setReadyCondition(backend)
+	obj, err := buildTrafficManagerBackendStatusApplyObject(backend)
+	if err != nil {
+		klog.ErrorS(err, "Failed to build the trafficManagerBackend status to apply", "trafficManagerBackend", backendKObj)
+		return controller.NewUnexpectedBehaviorError(err)
+	}
+	// The controller is the only writer of the fields it applies, so it takes over the fields from the other field
+	// managers (for example, the ones which were set by the update before switching to the server-side apply) instead
+	// of failing with the apply conflict.
+	// The conflict error is still returned when the backend is deleted and recreated as the UID is set as the
+	// precondition, and the request will be requeued.
+	if err := r.Client.Status().Patch(ctx, obj, client.Apply, client.FieldOwner(StatusFieldManager), client.ForceOwnership); err != nil {
+		klog.ErrorS(err, "Failed to apply trafficManagerBackend status", "trafficManagerBackend", backendKObj)
+		return controller.NewUpdateIgnoreConflictError(err)
+	}
+	updated := &fleetnetv1beta1.TrafficManagerBackend{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, updated); err != nil {
+		klog.ErrorS(err, "Failed to convert the applied trafficManagerBackend", "trafficManagerBackend", backendKObj)
+		return controller.NewUnexpectedBehaviorError(err)
+	}
+	updated.DeepCopyInto(backend)
+	klog.V(2).InfoS("Applied trafficManagerBackend status", "trafficManagerBackend", backendKObj, "status", backend.Status)
+	return nil
+}
+
+// buildTrafficManagerBackendStatusApplyObject builds the object to apply which only contains the identity and status of
+// the backend, so that the controller does not own any spec or metadata fields.
+func buildTrafficManagerBackendStatusApplyObject(backend *fleetnetv1beta1.TrafficManagerBackend) (*unstructured.Unstructured, error) {
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&backend.Status)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"status": status}}
+	obj.SetGroupVersionKind(fleetnetv1beta1.GroupVersion.WithKind(fleetnetv1beta1.TrafficManagerBackendKind))
+	obj.SetNamespace(backend.Namespace)
+	obj.SetName(backend.Name)
+	obj.SetUID(backend.UID)
+	return obj, nil
+}
+
+type desiredEndpoint struct {
+	Endpoint    armtrafficmanager.Endpoint
+	FromCluster fleetnetv1beta1.FromCluster
+	// WeightPercentage is the percentage of the backend weight assigned to the endpoint when the service is exported
+	// with a percentage weight.
+	WeightPercentage *int64
+	// MinWeightPercentage and MaxWeightPercentage are the bounds of the percentage of the backend weight assigned to
+	// the endpoint.
+	MinWeightPercentage *int64
+	MaxWeightPercentage *int64
+	// AdditionalServiceImportName is the name of the serviceImport listed in spec.backend.additionalNames which the
+	// endpoint is generated for, or empty for the serviceImport referenced by spec.backend.name.
+	AdditionalServiceImportName string
+	// FromGeneration is the generation of the internalServiceExport which the endpoint is generated from.
+	FromGeneration int64
+}
+
+// badEndpointError is the error of the desired endpoint which failed to be created or updated in the Azure Traffic
+// Manager, so that the failure can be reported per exported service.
+type badEndpointError struct {
+	endpoint desiredEndpoint
+	err      error
+}
+
+func (e *badEndpointError) Error() string {
+	return e.err.Error()
+}
+
+func (e *badEndpointError) Unwrap() error {
+	return e.err
+}
+
+// key returns the key of the exported service behind the endpoint, see exportedServiceKey.
+func (dp desiredEndpoint) key() string {
+	return formatExportedServiceKey(dp.AdditionalServiceImportName, dp.FromCluster.Cluster)
+}
+
+// description returns the human-readable description of the exported service behind the endpoint.
+func (dp desiredEndpoint) description() string {
+	if dp.AdditionalServiceImportName == "" {
+		return fmt.Sprintf("the service exported from cluster %q", dp.FromCluster.Cluster)
+	}
+	return fmt.Sprintf("the service of serviceImport %q exported from cluster %q", dp.AdditionalServiceImportName, dp.FromCluster.Cluster)
+}
+
+// exportedServiceKey returns the key of the service exported from the cluster behind the serviceImport, which is used
+// to track the invalid services and to distribute the weight of the backend.
+// The services behind the serviceImport referenced by spec.backend.name are keyed by the cluster name, while the ones
+// behind the additional serviceImports are keyed by "<serviceImportName>/<cluster>" as the same cluster may export the
+// services of multiple serviceImports.
+func exportedServiceKey(backend *fleetnetv1beta1.TrafficManagerBackend, serviceImportName, cluster string) string {
+	if serviceImportName == backend.Spec.Backend.Name {
+		return cluster
+	}
+	return formatExportedServiceKey(serviceImportName, cluster)
+}
+
+func formatExportedServiceKey(additionalServiceImportName, cluster string) string {
+	if additionalServiceImportName == "" {
+		return cluster
+	}
+	return additionalServiceImportName + "/" + cluster
+}
+
+// serviceImportNames returns the names of all the serviceImports referenced by the backend.
+func serviceImportNames(backend *fleetnetv1beta1.TrafficManagerBackend) []string {
+	return append([]string{backend.Spec.Backend.Name}, backend.Spec.Backend.AdditionalNames...)
+}
+
+// validateAndProcessServiceImportForBackend validates the serviceImports and generates the desired endpoints for the backend from the serviceExports.
+// The exported services behind all the serviceImports are combined and the weight of the backend is distributed among them.
+// it returns two maps and an error:
+// * a map of desired endpoints for the serviceImports (key is the endpoint name).
+// * a map of invalid services which cannot be exposed as the trafficManagerEndpoints (key is the exportedServiceKey,
+// which is the cluster name for the serviceImport referenced by spec.backend.name).
+// * an error if we encounter any error during the process, or errInternalServiceExportNotFound when the
+// internalServiceExport of a cluster listed in the serviceImports is not found.
+func (r *Reconciler) validateAndProcessServiceImportForBackend(ctx context.Context, profile *fleetnetv1beta1.TrafficManagerProfile, atmProfile *armtrafficmanager.Profile, backend *fleetnetv1beta1.TrafficManagerBackend, serviceImports ...*fleetnetv1alpha1.ServiceImport) (map[string]desiredEndpoint, map[string]error, error) {
+	backendKObj := klog.KObj(backend)
+	serviceImportsKObj := klog.KObjSlice(serviceImports)
+
+	var numberOfClusters int
+	for _, serviceImport := range serviceImports {
+		numberOfClusters += len(serviceImport.Status.Clusters)
+	}
+	if numberOfClusters == 0 {
+		klog.V(2).InfoS("No clusters found in the serviceImports", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj)
+		// Controller will only create the serviceImport when there is a cluster exposing their services.
+		// Updating the status will be in a separate call and could fail.
+		// The serviceImports staying empty for long usually means the serviceExports are never created or are invalid,
+		// which is reported as False so that the alerts can fire.
+		threshold := r.noExportedServicesThreshold()
+		if hasNoExportedServicesExceededThreshold(backend, threshold, time.Now()) {
+			klog.V(2).InfoS("No clusters found in the serviceImports for longer than the threshold", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj, "threshold", threshold)
+			// Keep reporting the endpoints accepted before as they are left untouched.
+			setFalseConditionWithReason(backend, backend.Status.Endpoints, fleetnetv1beta1.TrafficManagerBackendReasonNoExportedServices,
+				fmt.Sprintf("No member cluster has exported the services behind the serviceImports %v for more than %v", serviceImportNames(backend), threshold))
+		} else {
+			setUnknownCondition(backend, noExportedServicesMessage)
+		}
+		if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, errNoExportedServices
+	}
+
+	isGeographic := profile.Spec.RoutingMethod == fleetnetv1beta1.TrafficManagerRoutingMethodGeographic
+	isPriority := profile.Spec.RoutingMethod == fleetnetv1beta1.TrafficManagerRoutingMethodPriority
+	isPerformance := profile.Spec.RoutingMethod == fleetnetv1beta1.TrafficManagerRoutingMethodPerformance
+	isSubnet := profile.Spec.RoutingMethod == fleetnetv1beta1.TrafficManagerRoutingMethodSubnet
+	isMultiValue := profile.Spec.RoutingMethod == fleetnetv1beta1.TrafficManagerRoutingMethodMultiValue
+	isWeighted := !isGeographic && !isPriority && !isPerformance && !isSubnet && !isMultiValue
+	// Apply the same defaults as the trafficManagerProfile controller does to find the port probed by the Azure Traffic
+	// Manager.
+	defaultedProfile := profile.DeepCopy()
+	defaulter.SetDefaultsTrafficManagerProfile(defaultedProfile)
+	monitorPort := *defaultedProfile.Spec.MonitorConfig.Port
+	desiredEndpoints := make(map[string]desiredEndpoint, numberOfClusters) // key is the endpoint name
+	invalidServices := make(map[string]error, numberOfClusters)            // key is the exportedServiceKey
+	var totalWeight int64
+	for _, serviceImport := range serviceImports {
+		serviceImportKObj := klog.KObj(serviceImport)
+		internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
+		namespaceName := types.NamespacedName{Namespace: serviceImport.Namespace, Name: serviceImport.Name}
+		listOpts := client.MatchingFields{
+			exportedServiceFieldNamespacedName: namespaceName.String(),
+		}
+		if listErr := r.Client.List(ctx, internalServiceExportList, &listOpts); listErr != nil {
+			klog.ErrorS(listErr, "Failed to list internalServiceExports used by the serviceImport", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj)
+			setUnknownCondition(backend, fmt.Sprintf("Failed to list the exported service %q: %v", namespaceName, listErr))
+			if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, listErr
+		}
+		internalServiceExportMap := make(map[string]*fleetnetv1alpha1.InternalServiceExport, len(internalServiceExportList.Items))
+		for i, export := range internalServiceExportList.Items {
+			internalServiceExportMap[export.Spec.ServiceReference.ClusterID] = &internalServiceExportList.Items[i]
+		}
+		var additionalServiceImportName string
+		if serviceImport.Name != backend.Spec.Backend.Name {
+			additionalServiceImportName = serviceImport.Name
+		}
+
+		// Process the clusters in the order of the cluster IDs so that the same inputs always produce the same endpoints and
+		// conditions regardless of the order in the serviceImport status.
+		clusters := make([]fleetnetv1alpha1.ClusterStatus, len(serviceImport.Status.Clusters))
+		copy(clusters, serviceImport.Status.Clusters)
+		sort.Slice(clusters, func(i, j int) bool {
+			return clusters[i].Cluster < clusters[j].Cluster
+		})
+		for _, clusterStatus := range clusters {
+			if !isClusterSelected(backend, clusterStatus.Cluster) {
+				// The endpoint of the excluded cluster will be deleted as it is not desired.
+				klog.V(2).InfoS("Skipping the cluster excluded by the cluster selector", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster)
+				continue
+			}
+			key := exportedServiceKey(backend, serviceImport.Name, clusterStatus.Cluster)
+			internalServiceExport, ok := internalServiceExportMap[clusterStatus.Cluster]
+			if !ok {
+				getErr := fmt.Errorf("failed to find the internalServiceExport for the cluster %q", clusterStatus.Cluster)
+				// Usually controller should update the serviceImport status first before deleting the internalServiceImport.
+				// It could happen that the current serviceImport has stale information.
+				// The controller will be re-triggered when the serviceImport is updated.
+				klog.ErrorS(getErr, "InternalServiceExport not found for the cluster", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster)
+				message := fmt.Sprintf("Failed to find the exported service %q for %q: %v", namespaceName, clusterStatus.Cluster, getErr)
+				if hasAcceptedCondition(backend, metav1.ConditionUnknown, fleetnetv1beta1.TrafficManagerBackendReasonPending, message) {
+					// Skip the duplicate event and status update while the same internalServiceExport is still missing.
+					return nil, nil, errInternalServiceExportNotFound
+				}
+				r.Recorder.Eventf(backend, corev1.EventTypeWarning, backendEventReasonExportNotFound, "Exported service %q is not found for cluster %q", namespaceName, clusterStatus.Cluster)
+				setUnknownCondition(backend, message)
+				if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
+					return nil, nil, err
+				}
+				return nil, nil, errInternalServiceExportNotFound
+			}
+			if meta.IsStatusConditionTrue(internalServiceExport.Status.Conditions, string(fleetnetv1beta1.ServiceExportConflict)) {
+				// The serviceImport status may be stale and still list the cluster whose service spec diverges from the
+				// others, which should not receive the traffic.
+				invalidServices[key] = errServiceConflict
+				klog.V(2).InfoS("Service is in conflict with the other exported services", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster)
+				continue
+			}
+			if err := isValidTrafficManagerEndpoint(backend, internalServiceExport); err != nil {
+				invalidServices[key] = err
+				klog.V(2).InfoS("Invalid service for TrafficManager endpoint", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "error", err)
+				continue
+			}
+			if isMultiValue && internalServiceExport.Spec.ExternalTargetIP == nil {
+				// The "MultiValue" routing method supports the external endpoints with the IPv4 or IPv6 addresses only.
+				err := fmt.Errorf("the %q routing method requires the external target IP address configured by the %q annotation", profile.Spec.RoutingMethod, objectmeta.ServiceExportAnnotationExternalTargetIP)
+				invalidServices[key] = err
+				klog.V(2).InfoS("Invalid service for TrafficManager endpoint", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "error", err)
+				continue
+			}
+			if err := r.validatePublicIPResourceID(backend, internalServiceExport); err != nil {
+				invalidServices[key] = err
+				klog.V(2).InfoS("Public IP is not accessible by the Azure Traffic Manager", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "error", err)
+				continue
+			}
+			if err := validateMonitorPort(profile, internalServiceExport, monitorPort); err != nil {
+				invalidServices[key] = err
+				klog.V(2).InfoS("Monitor port is not exposed by the service", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "error", err)
+				continue
+			}
+			endpoint := generateAzureTrafficManagerEndpointForServiceImport(r.endpointNamePrefix(), profile, backend, serviceImport.Name, internalServiceExport)
+			if err := validateAzureTrafficManagerEndpointName(*endpoint.Name); err != nil {
+				invalidServices[key] = err
+				klog.V(2).InfoS("Invalid Traffic Manager endpoint name", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "error", err)
+				continue
+			}
+			if isGeographic && len(endpoint.Properties.GeoMapping) == 0 {
+				err := fmt.Errorf("geographic mapping is not configured by the %q annotation", objectmeta.ServiceExportAnnotationGeoMapping)
+				invalidServices[key] = err
+				klog.V(2).InfoS("Invalid service for TrafficManager endpoint", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "error", err)
+				continue
+			}
+			if isPriority && endpoint.Properties.Priority == nil {
+				err := fmt.Errorf("priority is not configured by the %q annotation", objectmeta.ServiceExportAnnotationPriority)
+				invalidServices[key] = err
+				klog.V(2).InfoS("Invalid service for TrafficManager endpoint", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "error", err)
+				continue
+			}
+			if isSubnet {
+				subnets, err := extractSubnets(backend, internalServiceExport)
+				if err == nil && len(subnets) == 0 {
+					err = fmt.Errorf("subnets are not configured by the %q annotation or the spec.backend.clusterSubnets", objectmeta.ServiceExportAnnotationSubnets)
+				}
+				if err != nil {
+					invalidServices[key] = err
+					klog.V(2).InfoS("Invalid service for TrafficManager endpoint", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "error", err)
+					continue
+				}
+			}
+			if isPerformance && endpoint.Properties.EndpointLocation == nil {
+				err := errors.New("the Azure region of the cluster is not reported by the member cluster")
+				if hasExternalTarget(internalServiceExport) {
+					err = fmt.Errorf("location is not configured by the %q annotation", objectmeta.ServiceExportAnnotationExternalTargetLocation)
+				}
+				invalidServices[key] = err
+				klog.V(2).InfoS("Invalid service for TrafficManager endpoint", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "error", err)
+				continue
+			}
+			if isAlwaysServeEnabled(internalServiceExport) && !isEndpointMonitoringEnabled(atmProfile) {
+				err := fmt.Errorf("the %q annotation requires the endpoint monitoring of the Azure Traffic Manager profile to be enabled", objectmeta.ServiceExportAnnotationAlwaysServe)
+				invalidServices[key] = err
+				klog.V(2).InfoS("Invalid service for TrafficManager endpoint", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "error", err)
+				continue
+			}
+			weightPercentage := internalServiceExport.Spec.WeightPercentage
+			if internalServiceExport.Spec.ReadyEndpointCount != nil {
+				// The ready endpoint count replaces the weight of the serviceExport, including the weight percentage.
+				weightPercentage = nil
+			}
+			if weight, ok := clusterWeightOverride(backend, clusterStatus.Cluster); ok && isWeighted {
+				// The weight override of the backend takes precedence over the weight and the weight percentage of the
+				// serviceExport, and the endpoint with the zero weight override is excluded below.
+				klog.V(2).InfoS("Overriding the weight of the exported service", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "weight", weight)
+				endpoint.Properties.Weight = ptr.To(weight)
+				weightPercentage = nil
+			}
+			desiredEndpoints[*endpoint.Name] = desiredEndpoint{
+				Endpoint: endpoint,
+				FromCluster: fleetnetv1beta1.FromCluster{
+					ClusterStatus: fleetnetv1beta1.ClusterStatus{
+						Cluster: clusterStatus.Cluster,
+					},
+					Weight: endpoint.Properties.Weight,
+				},
+				WeightPercentage:            weightPercentage,
+				MinWeightPercentage:         internalServiceExport.Spec.MinWeightPercentage,
+				MaxWeightPercentage:         internalServiceExport.Spec.MaxWeightPercentage,
+				AdditionalServiceImportName: additionalServiceImportName,
+				FromGeneration:              internalServiceExport.Generation,
+			}
+			if endpoint.Properties.Weight != nil {
+				totalWeight += *endpoint.Properties.Weight
+			}
+		}
+	}
+	if isWeighted {
+		if unknown := unknownClusterWeightOverrides(backend, serviceImports); len(unknown) > 0 {
+			klog.V(2).InfoS("Ignoring the weight overrides of the clusters not listed in the serviceImports", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj, "clusterIDs", unknown)
+			r.Recorder.Eventf(backend, corev1.EventTypeWarning, backendEventReasonUnknownClusterWeightOverride,
+				"The weight overrides of clusters %v are ignored as the clusters are not listed in the serviceImports %v", unknown, serviceImportNames(backend))
+		}
+	}
+	if isGeographic {
+		// The weight is not used by the "Geographic" routing method and instead, the geo mappings of the endpoints
+		// must not overlap with each other, including the endpoints created by other backends of the same profile.
+		invalidateOverlappingGeoMappings(r.endpointNamePrefix(), backend, atmProfile, desiredEndpoints, invalidServices)
+		klog.V(2).InfoS("Finishing validating services and setup geographic endpoints", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj, "numberOfDesiredEndpoints", len(desiredEndpoints), "numberOfInvalidServices", len(invalidServices))
+		return desiredEndpoints, invalidServices, nil
+	}
+	if isPriority {
+		// The weight is not used by the "Priority" routing method and instead, the priorities of the endpoints must be
+		// unique in the profile, including the endpoints created by other backends of the same profile.
+		invalidateDuplicatePriorities(r.endpointNamePrefix(), backend, atmProfile, desiredEndpoints, invalidServices)
+		klog.V(2).InfoS("Finishing validating services and setup priority endpoints", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj, "numberOfDesiredEndpoints", len(desiredEndpoints), "numberOfInvalidServices", len(invalidServices))
+		return desiredEndpoints, invalidServices, nil
+	}
+	if isSubnet {
+		// The weight is not used by the "Subnet" routing method and instead, the subnets of the endpoints must not
+		// overlap with each other, including the endpoints created by other backends of the same profile.
+		invalidateOverlappingSubnets(r.endpointNamePrefix(), backend, atmProfile, desiredEndpoints, invalidServices)
+		klog.V(2).InfoS("Finishing validating services and setup subnet endpoints", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj, "numberOfDesiredEndpoints", len(desiredEndpoints), "numberOfInvalidServices", len(invalidServices))
+		return desiredEndpoints, invalidServices, nil
+	}
+	if isMultiValue {
+		// The weight is not used by the "MultiValue" routing method and instead, the addresses of the healthy endpoints
+		// are returned up to the maxReturn of the profile.
+		klog.V(2).InfoS("Finishing validating services and setup multi-value endpoints", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj, "numberOfDesiredEndpoints", len(desiredEndpoints), "numberOfInvalidServices", len(invalidServices))
+		return desiredEndpoints, invalidServices, nil
+	}
+	if isPerformance {
+		// The weight is not used by the "Performance" routing method and instead, the traffic is routed by the
+		// locations of the endpoints.
+		klog.V(2).InfoS("Finishing validating services and setup performance endpoints", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj, "numberOfDesiredEndpoints", len(desiredEndpoints), "numberOfInvalidServices", len(invalidServices))
+		return desiredEndpoints, invalidServices, nil
+	}
+	if totalWeight == 0 && len(desiredEndpoints) > 0 {
+		// All the valid services are exported with zero weight and the endpoint weights cannot be calculated.
+		// Skip creating or updating the endpoints instead of sending invalid weights to the Azure Traffic Manager.
+		// The controller will be re-triggered when the weight of the internalServiceExport is updated.
+		klog.V(2).InfoS("Total weight of the exported services is 0 and skipping setting up endpoints", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj, "numberOfDesiredEndpoints", len(desiredEndpoints), "numberOfInvalidServices", len(invalidServices))
+		setFalseConditionWithReason(backend, nil, fleetnetv1beta1.TrafficManagerBackendReasonZeroTotalWeight,
+			fmt.Sprintf("%d service(s) exported from clusters cannot be exposed as the Azure Traffic Manager endpoints because the total weight of the services is 0", len(desiredEndpoints)))
+		return nil, nil, r.updateTrafficManagerBackendStatus(ctx, backend)
+	}
+	if excluded := excludeZeroWeightEndpoints(desiredEndpoints); len(excluded) > 0 {
+		// The endpoints of these clusters will be deleted as they are not desired.
+		klog.V(2).InfoS("Skipping the clusters exported with zero weight", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj, "clusterIDs", excluded)
+	}
+	invalidateExceededWeightPercentages(desiredEndpoints, invalidServices)
+	percentages := make(map[string]int64, len(desiredEndpoints)) // key is the exportedServiceKey
+	weights := make(map[string]int64, len(desiredEndpoints))     // key is the exportedServiceKey
+	bounds := make(map[string]weightBounds)                      // key is the exportedServiceKey
+	for _, dp := range desiredEndpoints {
+		if dp.MinWeightPercentage != nil || dp.MaxWeightPercentage != nil {
+			bounds[dp.key()] = weightBoundsFromPercentages(*backend.Spec.Weight, dp.MinWeightPercentage, dp.MaxWeightPercentage)
+		}
+		if dp.WeightPercentage != nil {
+			percentages[dp.key()] = *dp.WeightPercentage
+			continue
+		}
+		weights[dp.key()] = *dp.Endpoint.Properties.Weight
+	}
+	desiredWeights, err := clampWeightsToBounds(*backend.Spec.Weight, apportionWeightsWithPercentages(*backend.Spec.Weight, percentages, weights), bounds)
+	if err != nil {
+		// Skip creating or updating the endpoints instead of violating the bounds, as the existing endpoints still
+		// serve the traffic with the weights satisfying the bounds before.
+		// The controller will be re-triggered when the bounds of the internalServiceExports are updated.
+		klog.V(2).InfoS("Weight bounds of the exported services cannot be satisfied and skipping setting up endpoints", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj, "error", err)
+		setFalseConditionWithReason(backend, backend.Status.Endpoints, fleetnetv1beta1.TrafficManagerBackendReasonInfeasibleWeightBounds,
+			fmt.Sprintf("%d service(s) exported from clusters cannot be exposed as the Azure Traffic Manager endpoints because the weight bounds cannot be satisfied: %v", len(desiredEndpoints), err))
+		return nil, nil, r.updateTrafficManagerBackendStatus(ctx, backend)
+	}
+	for _, dp := range desiredEndpoints {
+		dp.Endpoint.Properties.Weight = ptr.To(desiredWeights[dp.key()])
+	}
+	if tooLow := findEffectiveWeightTooLowEndpoints(desiredEndpoints); len(tooLow) > 0 {
+		// The cluster is listed with a positive weight while the backend weight is too small to give it a share, for
+		// example, the backend weight is 1 and there are two clusters.
+		klog.V(2).InfoS("Apportioned weights of the endpoints are below the Azure Traffic Manager minimum", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj, "totalWeight", *backend.Spec.Weight, "exportedServices", tooLow)
+		r.Recorder.Eventf(backend, corev1.EventTypeWarning, backendEventReasonEffectiveWeightTooLow,
+			"The weights of the endpoints for %v are below the Azure Traffic Manager minimum %d and the clusters receive no traffic, please increase the weight of the backend", tooLow, azureTrafficManagerEndpointMinWeight)
+		trafficManagerBackendEffectiveWeightTooLowTotal.WithLabelValues(backend.GetNamespace(), backend.GetName()).Add(float64(len(tooLow)))
+	}
+	klog.V(2).InfoS("Finishing validating services and setup endpoints", "trafficManagerBackend", backendKObj, "serviceImports", serviceImportsKObj, "numberOfDesiredEndpoints", len(desiredEndpoints), "numberOfInvalidServices", len(invalidServices), "totalWeight", totalWeight)
+	return desiredEndpoints, invalidServices, nil
+}
+
+// apportionWeights distributes the total weight among the clusters in proportion to their weights by using the
+// largest remainder (Hamilton) method, so that the sum of the returned weights equals the total weight exactly.
+// Each cluster gets the integer part of its quota first, and the remaining weight is given one by one to the clusters
+// with the largest fractional parts. The ties are broken by the cluster name so that the result is deterministic.
+// The key of the weights is the cluster name and all the clusters get zero weight when the sum of the weights is zero.
+func apportionWeights(total int64, weights map[string]int64) map[string]int64 {
+	var sum int64
+	for _, weight := range weights {
+		sum += weight
+	}
+
+	res := make(map[string]int64, len(weights))
+	if sum == 0 {
+		for cluster := range weights {
+			res[cluster] = 0
+		}
+		return res
+	}
+	remainders := make(map[string]int64, len(weights))
+	clusters := make([]string, 0, len(weights))
+	remaining := total
+	for cluster, weight := range weights {
+		// The quota of the cluster is total*weight/sum and the remainder is compared using the same denominator.
+		res[cluster] = total * weight / sum
+		remainders[cluster] = total * weight % sum
+		remaining -= res[cluster]
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if remainders[clusters[i]] != remainders[clusters[j]] {
+			return remainders[clusters[i]] > remainders[clusters[j]]
+		}
+		return clusters[i] < clusters[j]
+	})
+	// The remaining weight is always less than the number of the clusters.
+	for i := int64(0); i < remaining; i++ {
+		res[clusters[i]]++
+	}
+	return res
+}
+
+// excludeZeroWeightEndpoints removes the desired endpoints whose services are exported with zero weight (or zero
+// percentage), so that the traffic to a single cluster can be disabled while the other clusters keep receiving the
+// traffic. The Azure Traffic Manager does not accept the zero weight, so the endpoints of these clusters are absent
+// instead. It returns the sorted names of the excluded clusters.
+func excludeZeroWeightEndpoints(desiredEndpoints map[string]desiredEndpoint) []string {
+	var excluded []string
+	for name, dp := range desiredEndpoints {
+		if ptr.Deref(dp.Endpoint.Properties.Weight, 0) != 0 {
+			continue
+		}
+		delete(desiredEndpoints, name)
+		excluded = append(excluded, dp.key())
+	}
+	sort.Strings(excluded)
+	return excluded
+}
+
+// findEffectiveWeightTooLowEndpoints returns the sorted keys of the exported services whose apportioned weights are
+// below the minimum weight of the Azure Traffic Manager endpoint.
+// The endpoints exported with zero weight are excluded before apportioning, so that the returned endpoints are the ones
+// whose shares are rounded down to zero.
+func findEffectiveWeightTooLowEndpoints(desiredEndpoints map[string]desiredEndpoint) []string {
+	var tooLow []string
+	for _, dp := range desiredEndpoints {
+		if ptr.Deref(dp.Endpoint.Properties.Weight, 0) < azureTrafficManagerEndpointMinWeight {
+			tooLow = append(tooLow, dp.key())
+		}
+	}
+	sort.Strings(tooLow)
+	return tooLow
+}
+
+// invalidateExceededWeightPercentages removes the desired endpoints exported with percentage weights and records them
+// as invalid services when the sum of the percentages exceeds 100%, as the percentages cannot be satisfied at the same
+// time.
+func invalidateExceededWeightPercentages(desiredEndpoints map[string]desiredEndpoint, invalidServices map[string]error) {
+	var sum int64
+	for _, dp := range desiredEndpoints {
+		if dp.WeightPercentage != nil {
+			sum += *dp.WeightPercentage
+		}
+	}
+	if sum <= 100 {
+		return
+	}
+	for name, dp := range desiredEndpoints {
+		if dp.WeightPercentage == nil {
+			continue
+		}
+		delete(desiredEndpoints, name)
+		invalidServices[dp.key()] = fmt.Errorf("the sum of the weight percentages of the exported services is %d%%, which exceeds 100%%", sum)
+	}
+}
+
+// apportionWeightsWithPercentages distributes the total weight among the clusters with the weight percentages first,
+// and then distributes the remaining weight among the clusters with the absolute weights in proportion to their weights.
+// When there are no clusters with positive absolute weights, the total weight is distributed among the clusters with
+// the weight percentages in proportion to their percentages.
+// The key of the percentages and weights is the cluster name and the sum of the percentages must not exceed 100.
+func apportionWeightsWithPercentages(total int64, percentages, weights map[string]int64) map[string]int64 {
+	var weightSum int64
+	for _, weight := range weights {
+		weightSum += weight
+	}
+	if len(percentages) == 0 {
+		return apportionWeights(total, weights)
+	}
+	if weightSum == 0 {
+		res := apportionWeights(total, percentages)
+		for cluster := range weights {
+			res[cluster] = 0
+		}
+		return res
+	}
+
+	// The remaining percentage is apportioned together with the percentages so that the sum of the results equals the
+	// total weight; the empty key cannot conflict with any cluster name.
+	shares := make(map[string]int64, len(percentages)+1)
+	remainingPercentage := int64(100)
+	for cluster, percentage := range percentages {
+		shares[cluster] = percentage
+		remainingPercentage -= percentage
+	}
+	shares[""] = remainingPercentage
+	res := apportionWeights(total, shares)
+	remaining := res[""]
+	delete(res, "")
+	for cluster, weight := range apportionWeights(remaining, weights) {
+		res[cluster] = weight
+	}
+	return res
+}
+
+// weightBounds are the minimum and maximum weights of an endpoint.
+type weightBounds struct {
+	min, max int64
+}
+
+// weightBoundsFromPercentages converts the weight percentage bounds to the weights of the total weight.
+// The minimum is rounded up and the maximum is rounded down so that the returned bounds never violate the percentages.
+func weightBoundsFromPercentages(total int64, minPercentage, maxPercentage *int64) weightBounds {
+	b := weightBounds{max: total}
+	if minPercentage != nil {
+		b.min = (total*ptr.Deref(minPercentage, 0) + 99) / 100
+	}
+	if maxPercentage != nil {
+		b.max = total * ptr.Deref(maxPercentage, 100) / 100
+	}
+	return b
+}
+
+// clampWeightsToBounds adjusts the apportioned weights so that each cluster gets a weight within its bounds, while
+// the weight given to or taken from the clamped clusters is taken from or given to the other clusters in proportion to
+// their apportioned weights, so that the sum of the returned weights still equals the total weight.
+// The key of the weights and bounds is the cluster name and the clusters without bounds can get any weight.
+// It returns an error when the bounds cannot be satisfied at the same time.
+func clampWeightsToBounds(total int64, weights map[string]int64, bounds map[string]weightBounds) (map[string]int64, error) {
+	if len(bounds) == 0 {
+		return weights, nil
+	}
+	clusters := make([]string, 0, len(weights))
+	for cluster := range weights {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	boundsOf := func(cluster string) weightBounds {
+		if b, ok := bounds[cluster]; ok {
+			return b
+		}
+		return weightBounds{max: total}
+	}
+	var minSum, maxSum int64
+	for _, cluster := range clusters {
+		b := boundsOf(cluster)
+		if b.min > b.max {
+			return nil, fmt.Errorf("the minimum weight %d of %q is greater than its maximum weight %d of the total weight %d", b.min, cluster, b.max, total)
+		}
+		minSum += b.min
+		maxSum += b.max
+	}
+	if minSum > total {
+		return nil, fmt.Errorf("the sum of the minimum weights %d exceeds the total weight %d", minSum, total)
+	}
+	if maxSum < total {
+		return nil, fmt.Errorf("the sum of the maximum weights %d is less than the total weight %d", maxSum, total)
+	}
+
+	res := make(map[string]int64, len(weights))
+	fixed := make(map[string]bool, len(weights))
+	for {
+		remaining := total
+		proportions := make(map[string]int64, len(weights))
+		var proportionSum int64
+		for _, cluster := range clusters {
+			if fixed[cluster] {
+				remaining -= res[cluster]
+				continue
+			}
+			proportions[cluster] = weights[cluster]
+			proportionSum += weights[cluster]
+		}
+		if len(proportions) == 0 {
+			break
+		}
+		if proportionSum == 0 {
+			// The clusters without weights share the remaining weight evenly.
+			for cluster := range proportions {
+				proportions[cluster] = 1
+			}
+		}
+		for cluster, weight := range apportionWeights(remaining, proportions) {
+			res[cluster] = weight
+		}
+
+		// Only the clusters clamped in the same direction as the net adjustment are fixed in each round, as the
+		// redistribution moves the other clusters towards their bounds.
+		var adjustment int64
+		var belowMin, aboveMax []string
+		for _, cluster := range clusters {
+			if _, ok := proportions[cluster]; !ok {
+				continue
+			}
+			b := boundsOf(cluster)
+			switch {
+			case res[cluster] < b.min:
+				adjustment += b.min - res[cluster]
+				belowMin = append(belowMin, cluster)
+			case res[cluster] > b.max:
+				adjustment -= res[cluster] - b.max
+				aboveMax = append(aboveMax, cluster)
+			}
+		}
+		if len(belowMin) == 0 && len(aboveMax) == 0 {
+			return res, nil
+		}
+		if adjustment >= 0 {
+			for _, cluster := range belowMin {
+				res[cluster] = boundsOf(cluster).min
+				fixed[cluster] = true
+			}
+		}
+		if adjustment <= 0 {
+			for _, cluster := range aboveMax {
+				res[cluster] = boundsOf(cluster).max
+				fixed[cluster] = true
+			}
+		}
+	}
+
+	var sum int64
+	for _, weight := range res {
+		sum += weight
+	}
+	if sum != total {
+		return nil, fmt.Errorf("the weights of the clusters cannot add up to the total weight %d within their bounds", total)
+	}
+	return res, nil
+}
+
+// isSuspended returns true if the reconciliation of the backend endpoints is suspended.
+func isSuspended(backend *fleetnetv1beta1.TrafficManagerBackend) bool {
+	return ptr.Deref(backend.Spec.Suspend, false)
+}
+
+// isClusterSelected returns true if the services exported from the cluster can be exposed by the backend according to
+// its cluster selector.
+func isClusterSelected(backend *fleetnetv1beta1.TrafficManagerBackend, cluster string) bool {
+	selector := backend.Spec.Backend.ClusterSelector
+	if selector == nil {
+		return true
+	}
+	return slices.Contains(selector.Clusters, cluster)
+}
+
+// clusterWeightOverride returns the weight override of the cluster in the backend spec, if any.
+func clusterWeightOverride(backend *fleetnetv1beta1.TrafficManagerBackend, cluster string) (int64, bool) {
+	for _, override := range backend.Spec.Backend.ClusterWeightOverrides {
+		if override.Cluster == cluster {
+			return override.Weight, true
+		}
+	}
+	return 0, false
+}
+
+// unknownClusterWeightOverrides returns the sorted names of the clusters which have the weight overrides in the backend
+// spec but are not listed in any of the serviceImports.
+func unknownClusterWeightOverrides(backend *fleetnetv1beta1.TrafficManagerBackend, serviceImports []*fleetnetv1alpha1.ServiceImport) []string {
+	var unknown []string
+	for _, override := range backend.Spec.Backend.ClusterWeightOverrides {
+		found := false
+		for _, serviceImport := range serviceImports {
+			if slices.ContainsFunc(serviceImport.Status.Clusters, func(cs fleetnetv1alpha1.ClusterStatus) bool {
+				return cs.Cluster == override.Cluster
+			}) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, override.Cluster)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// isValidTrafficManagerEndpoint returns error if the service cannot be added as a TrafficManager endpoint.
+// The service with an external target is exposed as an external endpoint and bypasses the load balancer requirements.
+func isValidTrafficManagerEndpoint(backend *fleetnetv1beta1.TrafficManagerBackend, export *fleetnetv1alpha1.InternalServiceExport) error {
+	if hasExternalTarget(export) {
+		return validateExternalTarget(export)
+	}
+	if export.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return fmt.Errorf("unsupported service type %q", export.Spec.Type)
+	}
+	if export.Spec.IsInternalLoadBalancer {
+		return fmt.Errorf("internal load balancer is not supported")
+	}
+	if export.Spec.PublicIPResourceID == nil {
+		return fmt.Errorf("in the processing of configuring public IP")
+	}
+	pip := selectPublicIPAddress(backend, export)
+	if pip == nil {
+		return fmt.Errorf("no %s public IP is assigned to the service", *backend.Spec.IPFamily)
+	}
+	if !pip.IsDNSLabelConfigured {
+		return fmt.Errorf("DNS label is not configured to the public IP")
+	}
+	return nil
+}
+
+// validatePublicIPResourceID returns error if the public IP address of the service is not a public IP resource in one
+// of the subscriptions accessible by the controller.
+// It's a no-op when the accessible subscriptions are not configured or the service has an external target.
+func (r *Reconciler) validatePublicIPResourceID(backend *fleetnetv1beta1.TrafficManagerBackend, export *fleetnetv1alpha1.InternalServiceExport) error {
+	if len(r.PublicIPSubscriptions) == 0 || hasExternalTarget(export) {
+		return nil
+	}
+	pip := selectPublicIPAddress(backend, export)
+	if pip == nil {
+		return nil
+	}
+	resourceID, err := arm.ParseResourceID(pip.ResourceID)
+	if err != nil {
+		return fmt.Errorf("invalid public IP resource ID %q: %w", pip.ResourceID, err)
+	}
+	if !strings.EqualFold(resourceID.ResourceType.String(), publicIPAddressResourceType) {
+		return fmt.Errorf("resource %q is a %s instead of a public IP address", pip.ResourceID, resourceID.ResourceType.String())
+	}
+	if !slices.ContainsFunc(r.PublicIPSubscriptions, func(s string) bool { return strings.EqualFold(s, resourceID.SubscriptionID) }) {
+		return fmt.Errorf("public IP in subscription %s is not accessible", resourceID.SubscriptionID)
+	}
+	return nil
+}
+
+// selectPublicIPAddress returns the public IP address of the service which matches the preferred IP family of the
+// backend, or nil if there is no such public IP address.
+// When the IP family is not set, the public IP address of the first load balancer ingress IP is returned.
+func selectPublicIPAddress(backend *fleetnetv1beta1.TrafficManagerBackend, export *fleetnetv1alpha1.InternalServiceExport) *fleetnetv1alpha1.PublicIPAddress {
+	if backend.Spec.IPFamily == nil {
+		if export.Spec.PublicIPResourceID == nil {
+			return nil
+		}
+		return &fleetnetv1alpha1.PublicIPAddress{
+			ResourceID:           *export.Spec.PublicIPResourceID,
+			IsDNSLabelConfigured: export.Spec.IsDNSLabelConfigured,
+		}
+	}
+	for i := range export.Spec.PublicIPAddresses {
+		if export.Spec.PublicIPAddresses[i].IPFamily == *backend.Spec.IPFamily {
+			return &export.Spec.PublicIPAddresses[i]
+		}
+	}
+	return nil
+}
+
+// validateMonitorPort returns error if the port probed by the Azure Traffic Manager is not exposed by the service.
+// The service with an external target is not checked as the ports are exposed by the external target instead.
+func validateMonitorPort(profile *fleetnetv1beta1.TrafficManagerProfile, export *fleetnetv1alpha1.InternalServiceExport, monitorPort int64) error {
+	if hasExternalTarget(export) {
+		return nil
+	}
+	ports := make([]int32, 0, len(export.Spec.Ports))
+	for _, port := range export.Spec.Ports {
+		if int64(port.Port) == monitorPort {
+			return nil
+		}
+		ports = append(ports, port.Port)
+	}
+	return fmt.Errorf("monitor port %d of trafficManagerProfile %q is not exposed by the service, exposed ports: %v", monitorPort, profile.Name, ports)
+}
+
+// hasExternalTarget returns true if the internalServiceExport is fronted by an external target instead of the public
+// IP address of its load balancer.
+func hasExternalTarget(export *fleetnetv1alpha1.InternalServiceExport) bool {
+	return export.Spec.ExternalTargetFQDN != nil || export.Spec.ExternalTargetIP != nil
+}
+
+// validateExternalTarget returns error if the external target of the internalServiceExport cannot be used as the
+// target of an Azure Traffic Manager external endpoint.
+func validateExternalTarget(export *fleetnetv1alpha1.InternalServiceExport) error {
+	fqdn, ip := export.Spec.ExternalTargetFQDN, export.Spec.ExternalTargetIP
+	if fqdn != nil && ip != nil {
+		return fmt.Errorf("only one of the %q and %q annotations can be set", objectmeta.ServiceExportAnnotationExternalTargetFQDN, objectmeta.ServiceExportAnnotationExternalTargetIP)
+	}
+	if fqdn != nil {
+		if errs := validation.IsFullyQualifiedDomainName(field.NewPath("spec", "externalTargetFQDN"), *fqdn); len(errs) > 0 {
+			return fmt.Errorf("invalid external target FQDN %q: %w", *fqdn, errs.ToAggregate())
+		}
+		return nil
+	}
+	if net.ParseIP(*ip) == nil {
+		return fmt.Errorf("invalid external target IP %q", *ip)
+	}
+	return nil
+}
+
+// generateAzureTrafficManagerEndpoint generates the endpoint of the service exported behind the serviceImport referenced
+// by spec.backend.name with the default endpoint name prefix.
+func generateAzureTrafficManagerEndpoint(profile *fleetnetv1beta1.TrafficManagerProfile, backend *fleetnetv1beta1.TrafficManagerBackend, serviceExport *fleetnetv1alpha1.InternalServiceExport) armtrafficmanager.Endpoint {
+	return generateAzureTrafficManagerEndpointForServiceImport(DefaultAzureResourceEndpointNamePrefix, profile, backend, backend.Spec.Backend.Name, serviceExport)
+}
+
+// generateAzureTrafficManagerEndpointForServiceImport generates the endpoint of the service exported behind the
+// serviceImport, whose name is part of the endpoint name so that the endpoints of the same cluster behind different
+// serviceImports of the backend do not collide.
+func generateAzureTrafficManagerEndpointForServiceImport(namePrefix string, profile *fleetnetv1beta1.TrafficManagerProfile, backend *fleetnetv1beta1.TrafficManagerBackend, serviceImportName string, serviceExport *fleetnetv1alpha1.InternalServiceExport) armtrafficmanager.Endpoint {
+	endpointName := formatAzureTrafficManagerEndpointName(generateAzureTrafficManagerEndpointNamePrefixFunc(namePrefix, backend), serviceImportName, serviceExport.Spec.ServiceReference.ClusterID)
+	endpointStatus := armtrafficmanager.EndpointStatusEnabled
+	if isEndpointDisabled(serviceExport) {
+		endpointStatus = armtrafficmanager.EndpointStatusDisabled
+	}
+	endpoint := armtrafficmanager.Endpoint{
+		Name: &endpointName,
+		Type: ptr.To(string(azureTrafficManagerEndpointTypePrefix + armtrafficmanager.EndpointTypeAzureEndpoints)),
+		Properties: &armtrafficmanager.EndpointProperties{
+			EndpointStatus: ptr.To(endpointStatus),
+		},
+	}
+	if isAlwaysServeEnabled(serviceExport) {
+		endpoint.Properties.AlwaysServe = ptr.To(armtrafficmanager.AlwaysServeEnabled)
+	}
+	if pip := selectPublicIPAddress(backend, serviceExport); pip != nil {
+		endpoint.Properties.TargetResourceID = ptr.To(pip.ResourceID)
+	}
+	if hasExternalTarget(serviceExport) {
+		target := serviceExport.Spec.ExternalTargetFQDN
+		if target == nil {
+			target = serviceExport.Spec.ExternalTargetIP
+		}
+		endpoint.Type = ptr.To(string(azureTrafficManagerEndpointTypePrefix + armtrafficmanager.EndpointTypeExternalEndpoints))
+		endpoint.Properties.TargetResourceID = nil
+		endpoint.Properties.Target = target
+		endpoint.Properties.EndpointLocation = serviceExport.Spec.ExternalTargetLocation
+	}
+	if profile.Spec.RoutingMethod == fleetnetv1beta1.TrafficManagerRoutingMethodGeographic {
+		endpoint.Properties.GeoMapping = extractGeoMapping(serviceExport)
+		return endpoint
+	}
+	if profile.Spec.RoutingMethod == fleetnetv1beta1.TrafficManagerRoutingMethodPriority {
+		endpoint.Properties.Priority = serviceExport.Spec.Priority
+		return endpoint
+	}
+	if profile.Spec.RoutingMethod == fleetnetv1beta1.TrafficManagerRoutingMethodSubnet {
+		// The invalid subnets are reported when validating the exported service.
+		subnets, _ := extractSubnets(backend, serviceExport)
+		endpoint.Properties.Subnets = buildAzureTrafficManagerEndpointSubnets(subnets)
+		return endpoint
+	}
+	if profile.Spec.RoutingMethod == fleetnetv1beta1.TrafficManagerRoutingMethodMultiValue {
+		return endpoint
+	}
+	if profile.Spec.RoutingMethod == fleetnetv1beta1.TrafficManagerRoutingMethodPerformance {
+		// The external endpoint is located by the external target location instead of the region of the cluster.
+		if !hasExternalTarget(serviceExport) {
+			endpoint.Properties.EndpointLocation = serviceExport.Spec.Region
+		}
+		return endpoint
+	}
+
+	weight := serviceExport.Spec.Weight
+	// existing internalServiceExport object might not have this field set.
+	if serviceExport.Spec.Weight == nil {
+		weight = ptr.To(int64(1))
+	}
+	if serviceExport.Spec.ReadyEndpointCount != nil {
+		// The service weighted by its ready endpoints uses the count as the base weight before the proportioning.
+		weight = ptr.To(*serviceExport.Spec.ReadyEndpointCount)
+	}
+	endpoint.Properties.Weight = weight
+	return endpoint
+}
+
+// validateAzureTrafficManagerEndpointName returns error if the endpoint name will be rejected by the Azure Traffic Manager.
+// The endpoint name consists of the backend UID, the serviceImport name and the cluster name, and only the last two
+// are controlled by the user.
+func validateAzureTrafficManagerEndpointName(name string) error {
+	if len(name) > azureResourceEndpointNameMaxLength {
+		return fmt.Errorf("the generated Azure Traffic Manager endpoint name %q is too long (%d characters, the limit is %d), please use a shorter cluster or service name", name, len(name), azureResourceEndpointNameMaxLength)
+	}
+	if i := strings.IndexAny(name, azureResourceEndpointNameInvalidCharacters); i >= 0 {
+		return fmt.Errorf("the generated Azure Traffic Manager endpoint name %q contains an invalid character %q, please remove it from the cluster or service name", name, name[i])
+	}
+	return nil
+}
+
+// isEndpointAdoptionEnabled returns true if the backend is allowed to adopt the pre-existing Azure Traffic Manager
+// endpoints by the annotation.
+func isEndpointAdoptionEnabled(backend *fleetnetv1beta1.TrafficManagerBackend) bool {
+	adopt, err := strconv.ParseBool(backend.Annotations[objectmeta.TrafficManagerBackendAnnotationAdoptEndpoints])
+	return err == nil && adopt
+}
+
+// azureTrafficManagerEndpointTarget returns the lower-cased target which identifies the Azure Traffic Manager endpoint,
+// which is the target resource ID of the azure endpoint or the target of the external endpoint.
+func azureTrafficManagerEndpointTarget(endpoint armtrafficmanager.Endpoint) string {
+	if endpoint.Properties == nil {
+		return ""
+	}
+	if endpoint.Properties.TargetResourceID != nil {
+		return strings.ToLower(*endpoint.Properties.TargetResourceID)
+	}
+	return strings.ToLower(ptr.Deref(endpoint.Properties.Target, ""))
+}
+
+// buildAdoptableEndpointTargets returns the targets of the desired endpoints, keyed by the target and valued by the
+// desired endpoint name.
+func buildAdoptableEndpointTargets(desiredEndpoints map[string]desiredEndpoint) map[string]string {
+	targets := make(map[string]string, len(desiredEndpoints))
+	for name, desired := range desiredEndpoints {
+		if target := azureTrafficManagerEndpointTarget(desired.Endpoint); target != "" {
+			targets[target] = name
+		}
+	}
+	return targets
+}
+
+// findAdoptingEndpoint returns the name of the desired endpoint which adopts the pre-existing endpoint, or an empty
+// string if the endpoint cannot be adopted.
+// The endpoints created by the fleet controllers are adopted only when none of the backends owns them, for example,
+// they are orphaned when the controller is uninstalled with the --orphan-azure-resources-on-delete flag.
+func findAdoptingEndpoint(namePrefix, endpointName string, endpoint armtrafficmanager.Endpoint, adoptableTargets map[string]string, backends []fleetnetv1beta1.TrafficManagerBackend) string {
+	if len(adoptableTargets) == 0 {
+		return ""
+	}
+	if isFleetManagedEndpoint(namePrefix, endpointName) {
+		for i := range backends {
+			if isEndpointOwnedByBackend(namePrefix, &backends[i], endpointName) {
+				return ""
+			}
+		}
+	}
+	target := azureTrafficManagerEndpointTarget(endpoint)
+	if target == "" {
+		return ""
+	}
+	return adoptableTargets[target]
+}
+
+// isEndpointDisabled returns true if the endpoint of the internalServiceExport is disabled by the annotation.
+// The disabled endpoint is still owned by the backend and won't be deleted from the Azure Traffic Manager profile.
+func isEndpointDisabled(serviceExport *fleetnetv1alpha1.InternalServiceExport) bool {
+	disabled, err := strconv.ParseBool(serviceExport.Annotations[objectmeta.InternalServiceExportAnnotationEndpointDisabled])
+	return err == nil && disabled
+}
+
+// isAlwaysServeEnabled returns true if the endpoint of the internalServiceExport is kept in rotation regardless of its
+// health checks by the annotation.
+func isAlwaysServeEnabled(serviceExport *fleetnetv1alpha1.InternalServiceExport) bool {
+	alwaysServe, err := strconv.ParseBool(serviceExport.Annotations[objectmeta.ServiceExportAnnotationAlwaysServe])
+	return err == nil && alwaysServe
+}
+
+// isEndpointMonitoringEnabled returns true if the Azure Traffic Manager profile probes the health of its endpoints,
+// which is required by the always serve endpoints to fall back to.
+func isEndpointMonitoringEnabled(atmProfile *armtrafficmanager.Profile) bool {
+	if atmProfile == nil || atmProfile.Properties == nil || atmProfile.Properties.MonitorConfig == nil {
+		return false
+	}
+	switch ptr.Deref(atmProfile.Properties.MonitorConfig.ProfileMonitorStatus, "") {
+	case armtrafficmanager.ProfileMonitorStatusDisabled, armtrafficmanager.ProfileMonitorStatusInactive:
+		return false
+	}
+	return true
+}
+
+// alwaysServeOf returns the always serve setting of the Azure Traffic Manager endpoint, which is disabled when unset.
+func alwaysServeOf(properties *armtrafficmanager.EndpointProperties) armtrafficmanager.AlwaysServe {
+	if properties == nil || properties.AlwaysServe == nil {
+		return armtrafficmanager.AlwaysServeDisabled
+	}
+	return *properties.AlwaysServe
+}
+
+// extractGeoMapping returns the geographic region codes configured by the geo mapping annotation of the
+// internalServiceExport, ignoring empty and duplicate (case-insensitive) codes.
+func extractGeoMapping(serviceExport *fleetnetv1alpha1.InternalServiceExport) []*string {
+	anno := serviceExport.Annotations[objectmeta.ServiceExportAnnotationGeoMapping]
+	if len(anno) == 0 {
+		return nil
+	}
+	var res []*string
+	seen := make(map[string]bool)
+	for _, code := range strings.Split(anno, ",") {
+		code = strings.TrimSpace(code)
+		key := strings.ToUpper(code)
+		if len(code) == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		res = append(res, ptr.To(code))
+	}
+	return res
+}
+
+// invalidateOverlappingGeoMappings removes the desired endpoints whose geographic regions have been claimed by other
+// endpoints and records them as invalid services.
+// The regions of the existing endpoints in the Azure Traffic Manager profile which are not owned by this backend are
+// claimed first. The desired endpoints are then processed in the order of their names, which end with the cluster
+// name, so that the result is deterministic.
+// Note, Azure Traffic Manager also rejects the endpoints whose regions are contained by the regions of other endpoints
+// (for example, "GEO-EU" and "DE"), which is not validated here and will be reported by the Azure API.
+func invalidateOverlappingGeoMappings(namePrefix string, backend *fleetnetv1beta1.TrafficManagerBackend, atmProfile *armtrafficmanager.Profile, desiredEndpoints map[string]desiredEndpoint, invalidServices map[string]error) {
+	owners := make(map[string]string) // key is the region code in upper case and value describes the owner endpoint
+	if atmProfile != nil && atmProfile.Properties != nil {
+		for _, endpoint := range atmProfile.Properties.Endpoints {
+			if endpoint == nil || endpoint.Name == nil || endpoint.Properties == nil {
+				continue
+			}
+			if isEndpointOwnedByBackend(namePrefix, backend, strings.ToLower(*endpoint.Name)) {
+				continue // the endpoints owned by this backend will be replaced by the desired ones
+			}
+			for _, code := range endpoint.Properties.GeoMapping {
+				if code != nil {
+					owners[strings.ToUpper(*code)] = fmt.Sprintf("the existing Azure Traffic Manager endpoint %q", *endpoint.Name)
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(desiredEndpoints))
+	for name := range desiredEndpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		dp := desiredEndpoints[name]
+		var overlapErr error
+		for _, code := range dp.Endpoint.Properties.GeoMapping {
+			if owner, ok := owners[strings.ToUpper(*code)]; ok {
+				overlapErr = fmt.Errorf("geographic region %q overlaps with %s", *code, owner)
+				break
+			}
+		}
+		if overlapErr != nil {
+			delete(desiredEndpoints, name)
+			invalidServices[dp.key()] = overlapErr
+			continue
+		}
+		for _, code := range dp.Endpoint.Properties.GeoMapping {
+			owners[strings.ToUpper(*code)] = dp.description()
 		}
-		return nil, getErr // need to return the error to requeue the request
 	}
-	return &getRes.Profile, nil
 }
 
-// validateServiceImportAndCleanupEndpointsIfInvalid returns not nil serviceImport when the serviceImport is valid.
-func (r *Reconciler) validateServiceImportAndCleanupEndpointsIfInvalid(ctx context.Context, resourceGroup string, backend *fleetnetv1beta1.TrafficManagerBackend, azureProfile *armtrafficmanager.Profile) (*fleetnetv1alpha1.ServiceImport, error) {
-	backendKObj := klog.KObj(backend)
-	var cond metav1.Condition
-	serviceImport := &fleetnetv1alpha1.ServiceImport{}
-	if getServiceImportErr := r.Client.Get(ctx, types.NamespacedName{Name: backend.Spec.Backend.Name, Namespace: backend.Namespace}, serviceImport); getServiceImportErr != nil {
-		if apierrors.IsNotFound(getServiceImportErr) {
-			klog.V(2).InfoS("NotFound serviceImport and starting deleting any stale endpoints", "trafficManagerBackend", backendKObj, "serviceImport", backend.Spec.Backend.Name)
-			if err := r.cleanupEndpoints(ctx, resourceGroup, backend, azureProfile); err != nil {
-				r.Recorder.Eventf(backend, corev1.EventTypeWarning, backendEventReasonAzureAPIError, "Failed to delete stale endpoints for an invalid serviceImport: %v", err)
-				klog.ErrorS(err, "Failed to delete stale endpoints for an invalid serviceImport", "trafficManagerBackend", backendKObj, "serviceImport", backend.Spec.Backend.Name)
-				return nil, err
-			}
-			cond = metav1.Condition{
-				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
-				Status:             metav1.ConditionFalse,
-				ObservedGeneration: backend.Generation,
-				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonInvalid),
-				Message:            fmt.Sprintf("ServiceImport %q is not found", backend.Spec.Backend.Name),
-			}
-			meta.SetStatusCondition(&backend.Status.Conditions, cond)
-			backend.Status.Endpoints = []fleetnetv1beta1.TrafficManagerEndpointStatus{} // none of the endpoints are accepted by the TrafficManager
-			return nil, r.updateTrafficManagerBackendStatus(ctx, backend)
+// extractSubnets returns the subnets of the exported service, which are configured by the spec.backend.clusterSubnets
+// of the backend for the cluster, or by the subnets annotation of the internalServiceExport otherwise.
+func extractSubnets(backend *fleetnetv1beta1.TrafficManagerBackend, serviceExport *fleetnetv1alpha1.InternalServiceExport) ([]netip.Prefix, error) {
+	cluster := serviceExport.Spec.ServiceReference.ClusterID
+	for _, clusterSubnets := range backend.Spec.Backend.ClusterSubnets {
+		if clusterSubnets.Cluster != cluster {
+			continue
 		}
-		klog.ErrorS(getServiceImportErr, "Failed to get serviceImport", "trafficManagerBackend", backendKObj, "serviceImport", backend.Spec.Backend.Name)
-		setUnknownCondition(backend, fmt.Sprintf("Failed to get the serviceImport %q: %v", backend.Spec.Profile.Name, getServiceImportErr))
-		if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
-			return nil, err
+		subnets, err := objectmeta.ParseSubnets(clusterSubnets.Subnets)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec.backend.clusterSubnets of the cluster %q: %w", cluster, err)
 		}
-		return nil, getServiceImportErr // need to return the error to requeue the request
+		return subnets, nil
 	}
-	return serviceImport, nil
+	anno := serviceExport.Annotations[objectmeta.ServiceExportAnnotationSubnets]
+	if len(anno) == 0 {
+		return nil, nil
+	}
+	subnets, err := objectmeta.ParseSubnets(strings.Split(anno, ","))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %q annotation: %w", objectmeta.ServiceExportAnnotationSubnets, err)
+	}
+	return subnets, nil
 }
 
-func setFalseCondition(backend *fleetnetv1beta1.TrafficManagerBackend, acceptedEndpoints []fleetnetv1beta1.TrafficManagerEndpointStatus, message string) {
-	cond := metav1.Condition{
-		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
-		Status:             metav1.ConditionFalse,
-		ObservedGeneration: backend.Generation,
-		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonInvalid),
-		Message:            message,
+// buildAzureTrafficManagerEndpointSubnets converts the subnets into the first addresses and the prefix lengths (scopes)
+// of the Azure Traffic Manager endpoint subnets.
+func buildAzureTrafficManagerEndpointSubnets(subnets []netip.Prefix) []*armtrafficmanager.EndpointPropertiesSubnetsItem {
+	if len(subnets) == 0 {
+		return nil
 	}
-	if len(acceptedEndpoints) == 0 {
-		backend.Status.Endpoints = []fleetnetv1beta1.TrafficManagerEndpointStatus{}
-	} else {
-		backend.Status.Endpoints = acceptedEndpoints
+	res := make([]*armtrafficmanager.EndpointPropertiesSubnetsItem, 0, len(subnets))
+	for _, subnet := range subnets {
+		res = append(res, &armtrafficmanager.EndpointPropertiesSubnetsItem{
+			First: ptr.To(subnet.Addr().String()),
+			Scope: ptr.To(int32(subnet.Bits())),
+		})
 	}
-	meta.SetStatusCondition(&backend.Status.Conditions, cond)
+	return res
 }
 
-func setUnknownCondition(backend *fleetnetv1beta1.TrafficManagerBackend, message string) {
-	cond := metav1.Condition{
-		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
-		Status:             metav1.ConditionUnknown,
-		ObservedGeneration: backend.Generation,
-		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
-		Message:            message,
-	}
-	backend.Status.Endpoints = []fleetnetv1beta1.TrafficManagerEndpointStatus{}
-	meta.SetStatusCondition(&backend.Status.Conditions, cond)
+// addressRange is the inclusive range of the IP addresses covered by a subnet of the Azure Traffic Manager endpoint.
+type addressRange struct {
+	first, last netip.Addr
 }
 
-func setTrueCondition(backend *fleetnetv1beta1.TrafficManagerBackend, acceptedEndpoints []fleetnetv1beta1.TrafficManagerEndpointStatus) {
-	cond := metav1.Condition{
-		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
-		Status:             metav1.ConditionTrue,
-		ObservedGeneration: backend.Generation,
-		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonAccepted),
-		Message:            fmt.Sprintf("%v service(s) exported from clusters have been accepted as Traffic Manager endpoints", len(acceptedEndpoints)),
-	}
-	backend.Status.Endpoints = acceptedEndpoints
-	meta.SetStatusCondition(&backend.Status.Conditions, cond)
+// overlaps returns true if the two ranges share any IP address.
+// The IPv4 addresses are ordered before the IPv6 addresses, so that the ranges of different IP families never overlap.
+func (r addressRange) overlaps(other addressRange) bool {
+	return r.first.Compare(other.last) <= 0 && other.first.Compare(r.last) <= 0
 }
 
-func (r *Reconciler) updateTrafficManagerBackendStatus(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend) error {
-	backendKObj := klog.KObj(backend)
-	if err := r.Client.Status().Update(ctx, backend); err != nil {
-		klog.ErrorS(err, "Failed to update trafficManagerBackend status", "trafficManagerBackend", backendKObj)
-		return controller.NewUpdateIgnoreConflictError(err)
+// parseAzureTrafficManagerEndpointSubnet returns the range of the Azure Traffic Manager endpoint subnet, which is
+// either defined by the first address and the scope, the first and the last addresses, or the first address only.
+// It returns false when the subnet is invalid.
+func parseAzureTrafficManagerEndpointSubnet(subnet *armtrafficmanager.EndpointPropertiesSubnetsItem) (addressRange, bool) {
+	if subnet == nil || subnet.First == nil {
+		return addressRange{}, false
+	}
+	first, err := netip.ParseAddr(*subnet.First)
+	if err != nil {
+		return addressRange{}, false
+	}
+	switch {
+	case subnet.Scope != nil:
+		prefix, err := first.Prefix(int(*subnet.Scope))
+		if err != nil {
+			return addressRange{}, false
+		}
+		return addressRange{first: prefix.Addr(), last: lastAddress(prefix)}, true
+	case subnet.Last != nil:
+		last, err := netip.ParseAddr(*subnet.Last)
+		if err != nil || last.BitLen() != first.BitLen() || last.Less(first) {
+			return addressRange{}, false
+		}
+		return addressRange{first: first, last: last}, true
+	default:
+		return addressRange{first: first, last: first}, true
 	}
-	klog.V(2).InfoS("Updated trafficManagerBackend status", "trafficManagerBackend", backendKObj, "status", backend.Status)
-	return nil
 }
 
-type desiredEndpoint struct {
-	Endpoint    armtrafficmanager.Endpoint
-	FromCluster fleetnetv1beta1.FromCluster
+// lastAddress returns the last IP address of the subnet.
+func lastAddress(subnet netip.Prefix) netip.Addr {
+	b := subnet.Masked().Addr().AsSlice()
+	for i := subnet.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	last, _ := netip.AddrFromSlice(b)
+	return last
 }
 
-// validateAndProcessServiceImportForBackend validates the serviceImport and generates the desired endpoints for the backend from the serviceExports.
-// it returns two maps and an error:
-// * a map of desired endpoints for the serviceImport (key is the endpoint name).
-// * a map of invalid services which cannot be exposed as the trafficManagerEndpoints (key is the cluster name).
-// * an error if we encounter any error during the process
-func (r *Reconciler) validateAndProcessServiceImportForBackend(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend, serviceImport *fleetnetv1alpha1.ServiceImport) (map[string]desiredEndpoint, map[string]error, error) {
-	backendKObj := klog.KObj(backend)
-	serviceImportKObj := klog.KObj(serviceImport)
-
-	if len(serviceImport.Status.Clusters) == 0 {
-		klog.V(2).InfoS("No clusters found in the serviceImport", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj)
-		// Controller will only create the serviceImport when there is a cluster exposing their services.
-		// Updating the status will be in a separate call and could fail.
-		setUnknownCondition(backend, "In the process of exporting the services")
-		// We don't need to requeue the request and when the serviceImport status is set, the controller will be re-triggered.
-		return nil, nil, r.updateTrafficManagerBackendStatus(ctx, backend)
+// formatAzureTrafficManagerEndpointSubnet formats the Azure Traffic Manager endpoint subnet in the CIDR notation, or
+// as "{first}-{last}" when it is defined by the address range.
+func formatAzureTrafficManagerEndpointSubnet(subnet *armtrafficmanager.EndpointPropertiesSubnetsItem) string {
+	if subnet == nil {
+		return ""
+	}
+	first := ptr.Deref(subnet.First, "")
+	switch {
+	case subnet.Scope != nil:
+		return fmt.Sprintf("%s/%d", first, *subnet.Scope)
+	case subnet.Last != nil:
+		return fmt.Sprintf("%s-%s", first, *subnet.Last)
+	default:
+		return first
 	}
+}
 
-	internalServiceExportList := &fleetnetv1alpha1.InternalServiceExportList{}
-	namespaceName := types.NamespacedName{Namespace: serviceImport.Namespace, Name: serviceImport.Name}
-	listOpts := client.MatchingFields{
-		exportedServiceFieldNamespacedName: namespaceName.String(),
-	}
-	if listErr := r.Client.List(ctx, internalServiceExportList, &listOpts); listErr != nil {
-		klog.ErrorS(listErr, "Failed to list internalServiceExports used by the serviceImport", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj)
-		setUnknownCondition(backend, fmt.Sprintf("Failed to list the exported service %q: %v", namespaceName, listErr))
-		if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
-			return nil, nil, err
+// invalidateOverlappingSubnets removes the desired endpoints whose subnets overlap with the subnets claimed by other
+// endpoints and records them as invalid services.
+// Similar to the geographic regions, the subnets of the existing endpoints in the Azure Traffic Manager profile which
+// are not owned by this backend are claimed first, and then the desired endpoints in the order of their names.
+func invalidateOverlappingSubnets(namePrefix string, backend *fleetnetv1beta1.TrafficManagerBackend, atmProfile *armtrafficmanager.Profile, desiredEndpoints map[string]desiredEndpoint, invalidServices map[string]error) {
+	type claim struct {
+		addressRange
+		owner string
+	}
+	var claims []claim
+	if atmProfile != nil && atmProfile.Properties != nil {
+		for _, endpoint := range atmProfile.Properties.Endpoints {
+			if endpoint == nil || endpoint.Name == nil || endpoint.Properties == nil {
+				continue
+			}
+			if isEndpointOwnedByBackend(namePrefix, backend, strings.ToLower(*endpoint.Name)) {
+				continue // the endpoints owned by this backend will be replaced by the desired ones
+			}
+			for _, subnet := range endpoint.Properties.Subnets {
+				if r, ok := parseAzureTrafficManagerEndpointSubnet(subnet); ok {
+					claims = append(claims, claim{addressRange: r, owner: fmt.Sprintf("the existing Azure Traffic Manager endpoint %q", *endpoint.Name)})
+				}
+			}
 		}
-		return nil, nil, listErr
 	}
-	internalServiceExportMap := make(map[string]*fleetnetv1alpha1.InternalServiceExport, len(internalServiceExportList.Items))
-	for i, export := range internalServiceExportList.Items {
-		internalServiceExportMap[export.Spec.ServiceReference.ClusterID] = &internalServiceExportList.Items[i]
+
+	names := make([]string, 0, len(desiredEndpoints))
+	for name := range desiredEndpoints {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	desiredEndpoints := make(map[string]desiredEndpoint, len(serviceImport.Status.Clusters)) // key is the endpoint name
-	invalidServices := make(map[string]error, len(serviceImport.Status.Clusters))            // key is cluster name
-	var totalWeight int64
-	for _, clusterStatus := range serviceImport.Status.Clusters {
-		internalServiceExport, ok := internalServiceExportMap[clusterStatus.Cluster]
-		if !ok {
-			getErr := fmt.Errorf("failed to find the internalServiceExport for the cluster %q", clusterStatus.Cluster)
-			// Usually controller should update the serviceImport status first before deleting the internalServiceImport.
-			// It could happen that the current serviceImport has stale information.
-			// The controller will be re-triggered when the serviceImport is updated.
-			klog.ErrorS(getErr, "InternalServiceExport not found for the cluster", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster)
-			setUnknownCondition(backend, fmt.Sprintf("Failed to find the exported service %q for %q: %v", namespaceName, clusterStatus.Cluster, getErr))
-			return nil, nil, r.updateTrafficManagerBackendStatus(ctx, backend)
-		}
-		if err := isValidTrafficManagerEndpoint(internalServiceExport); err != nil {
-			invalidServices[clusterStatus.Cluster] = err
-			klog.V(2).InfoS("Invalid service for TrafficManager endpoint", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "clusterID", clusterStatus.Cluster, "error", err)
+	for _, name := range names {
+		dp := desiredEndpoints[name]
+		var overlapErr error
+		ranges := make([]addressRange, 0, len(dp.Endpoint.Properties.Subnets))
+		for _, subnet := range dp.Endpoint.Properties.Subnets {
+			r, _ := parseAzureTrafficManagerEndpointSubnet(subnet)
+			for _, c := range claims {
+				if c.overlaps(r) {
+					overlapErr = fmt.Errorf("subnet %q overlaps with %s", formatAzureTrafficManagerEndpointSubnet(subnet), c.owner)
+					break
+				}
+			}
+			if overlapErr != nil {
+				break
+			}
+			ranges = append(ranges, r)
+		}
+		if overlapErr != nil {
+			delete(desiredEndpoints, name)
+			invalidServices[dp.key()] = overlapErr
 			continue
 		}
-		endpoint := generateAzureTrafficManagerEndpoint(backend, internalServiceExport)
-		desiredEndpoints[*endpoint.Name] = desiredEndpoint{
-			Endpoint: endpoint,
-			FromCluster: fleetnetv1beta1.FromCluster{
-				ClusterStatus: fleetnetv1beta1.ClusterStatus{
-					Cluster: clusterStatus.Cluster,
-				},
-				Weight: endpoint.Properties.Weight,
-			},
+		for _, r := range ranges {
+			claims = append(claims, claim{addressRange: r, owner: dp.description()})
 		}
-		totalWeight += *endpoint.Properties.Weight
-	}
-	for _, dp := range desiredEndpoints {
-		// Calculate the desired weight for the endpoint as the proportion of the total weight.
-		desiredWeight := math.Ceil(float64(*backend.Spec.Weight**dp.Endpoint.Properties.Weight) / float64(totalWeight))
-		dp.Endpoint.Properties.Weight = ptr.To(int64(desiredWeight))
 	}
-	klog.V(2).InfoS("Finishing validating services and setup endpoints", "trafficManagerBackend", backendKObj, "serviceImport", serviceImportKObj, "numberOfDesiredEndpoints", len(desiredEndpoints), "numberOfInvalidServices", len(invalidServices), "totalWeight", totalWeight)
-	return desiredEndpoints, invalidServices, nil
 }
 
-// isValidTrafficManagerEndpoint returns error if the service cannot be added as a TrafficManager endpoint.
-func isValidTrafficManagerEndpoint(export *fleetnetv1alpha1.InternalServiceExport) error {
-	if export.Spec.Type != corev1.ServiceTypeLoadBalancer {
-		return fmt.Errorf("unsupported service type %q", export.Spec.Type)
-	}
-	if export.Spec.IsInternalLoadBalancer {
-		return fmt.Errorf("internal load balancer is not supported")
+// invalidateDuplicatePriorities removes the desired endpoints whose priorities have been used by other endpoints and
+// records them as invalid services.
+// Similar to the geographic regions, the priorities of the existing endpoints in the Azure Traffic Manager profile which
+// are not owned by this backend are claimed first, and then the desired endpoints in the order of their names.
+func invalidateDuplicatePriorities(namePrefix string, backend *fleetnetv1beta1.TrafficManagerBackend, atmProfile *armtrafficmanager.Profile, desiredEndpoints map[string]desiredEndpoint, invalidServices map[string]error) {
+	owners := make(map[int64]string) // key is the priority and value describes the owner endpoint
+	if atmProfile != nil && atmProfile.Properties != nil {
+		for _, endpoint := range atmProfile.Properties.Endpoints {
+			if endpoint == nil || endpoint.Name == nil || endpoint.Properties == nil || endpoint.Properties.Priority == nil {
+				continue
+			}
+			if isEndpointOwnedByBackend(namePrefix, backend, strings.ToLower(*endpoint.Name)) {
+				continue // the endpoints owned by this backend will be replaced by the desired ones
+			}
+			owners[*endpoint.Properties.Priority] = fmt.Sprintf("the existing Azure Traffic Manager endpoint %q", *endpoint.Name)
+		}
 	}
-	if export.Spec.PublicIPResourceID == nil {
-		return fmt.Errorf("in the processing of configuring public IP")
+
+	names := make([]string, 0, len(desiredEndpoints))
+	for name := range desiredEndpoints {
+		names = append(names, name)
 	}
-	if !export.Spec.IsDNSLabelConfigured {
-		return fmt.Errorf("DNS label is not configured to the public IP")
+	sort.Strings(names)
+
+	for _, name := range names {
+		dp := desiredEndpoints[name]
+		priority := *dp.Endpoint.Properties.Priority
+		if owner, ok := owners[priority]; ok {
+			delete(desiredEndpoints, name)
+			invalidServices[dp.key()] = fmt.Errorf("priority %d is already used by %s", priority, owner)
+			continue
+		}
+		owners[priority] = dp.description()
 	}
-	return nil
 }
 
-func generateAzureTrafficManagerEndpoint(backend *fleetnetv1beta1.TrafficManagerBackend, serviceExport *fleetnetv1alpha1.InternalServiceExport) armtrafficmanager.Endpoint {
-	endpointName := fmt.Sprintf(AzureResourceEndpointNameFormat, generateAzureTrafficManagerEndpointNamePrefixFunc(backend), backend.Spec.Backend.Name, serviceExport.Spec.ServiceReference.ClusterID)
-	weight := serviceExport.Spec.Weight
-	// existing internalServiceExport object might not have this field set.
-	if serviceExport.Spec.Weight == nil {
-		weight = ptr.To(int64(1))
+// limitDesiredEndpoints removes the desired endpoints which exceed the maximum number of endpoints allowed in the
+// Azure Traffic Manager profile and returns the exportedServiceKeys (the cluster names for the serviceImport referenced
+// by spec.backend.name) of the removed endpoints in the sorted order.
+// The existing endpoints in the profile which are not owned by this backend count towards the limit first. The desired
+// endpoints are then kept in the descending order of their weights, and the ties are broken by the exportedServiceKey
+// so that the result is deterministic.
+func limitDesiredEndpoints(namePrefix string, backend *fleetnetv1beta1.TrafficManagerBackend, atmProfile *armtrafficmanager.Profile, desiredEndpoints map[string]desiredEndpoint, maxEndpoints int) []string {
+	available := maxEndpoints
+	if atmProfile != nil && atmProfile.Properties != nil {
+		for _, endpoint := range atmProfile.Properties.Endpoints {
+			if endpoint == nil || endpoint.Name == nil {
+				continue
+			}
+			if isEndpointOwnedByBackend(namePrefix, backend, strings.ToLower(*endpoint.Name)) {
+				continue // the endpoints owned by this backend will be replaced by the desired ones
+			}
+			available--
+		}
 	}
-	return armtrafficmanager.Endpoint{
-		Name: &endpointName,
-		Type: ptr.To(string("Microsoft.Network/trafficManagerProfiles/" + armtrafficmanager.EndpointTypeAzureEndpoints)),
-		Properties: &armtrafficmanager.EndpointProperties{
-			TargetResourceID: serviceExport.Spec.PublicIPResourceID,
-			EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
-			Weight:           weight,
-		},
+	if len(desiredEndpoints) <= available {
+		return nil
+	}
+
+	names := make([]string, 0, len(desiredEndpoints))
+	for name := range desiredEndpoints {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		wi, wj := ptr.Deref(desiredEndpoints[names[i]].Endpoint.Properties.Weight, 0), ptr.Deref(desiredEndpoints[names[j]].Endpoint.Properties.Weight, 0)
+		if wi != wj {
+			return wi > wj
+		}
+		return desiredEndpoints[names[i]].key() < desiredEndpoints[names[j]].key()
+	})
+
+	available = max(available, 0)
+	droppedClusters := make([]string, 0, len(names)-available)
+	for _, name := range names[available:] {
+		droppedClusters = append(droppedClusters, desiredEndpoints[name].key())
+		delete(desiredEndpoints, name)
 	}
+	sort.Strings(droppedClusters)
+	return droppedClusters
 }
 
 func buildAcceptedEndpointStatus(endpoint *armtrafficmanager.Endpoint, desiredEndpoint desiredEndpoint) fleetnetv1beta1.TrafficManagerEndpointStatus {
@@ -628,13 +3005,76 @@ func buildAcceptedEndpointStatus(endpoint *armtrafficmanager.Endpoint, desiredEn
 		resourceID = *endpoint.ID
 	}
 
+	var geoMapping []string
+	for _, code := range endpoint.Properties.GeoMapping {
+		if code != nil {
+			geoMapping = append(geoMapping, *code)
+		}
+	}
+
+	var subnets []string
+	for _, subnet := range endpoint.Properties.Subnets {
+		if subnet != nil {
+			subnets = append(subnets, formatAzureTrafficManagerEndpointSubnet(subnet))
+		}
+	}
+
+	var monitorStatus *fleetnetv1beta1.TrafficManagerEndpointMonitorStatus
+	if endpoint.Properties.EndpointMonitorStatus != nil {
+		monitorStatus = ptr.To(fleetnetv1beta1.TrafficManagerEndpointMonitorStatus(*endpoint.Properties.EndpointMonitorStatus))
+	}
+
 	return fleetnetv1beta1.TrafficManagerEndpointStatus{
-		Name:       strings.ToLower(*endpoint.Name), // name is case-insensitive
-		Target:     endpoint.Properties.Target,
-		Weight:     endpoint.Properties.Weight, // the calculated weight
-		From:       &desiredEndpoint.FromCluster,
-		ResourceID: resourceID,
+		Name:           strings.ToLower(*endpoint.Name), // name is case-insensitive
+		Target:         endpoint.Properties.Target,
+		Weight:         endpoint.Properties.Weight, // the calculated weight
+		GeoMapping:     geoMapping,
+		Priority:       endpoint.Properties.Priority,
+		Subnets:        subnets,
+		MonitorStatus:  monitorStatus,
+		From:           &desiredEndpoint.FromCluster,
+		FromGeneration: desiredEndpoint.FromGeneration,
+		ResourceID:     resourceID,
+	}
+}
+
+// setEndpointPercentages sets the percentage of the traffic routed to each accepted endpoint, which is its weight divided
+// by the sum of the weights of all the accepted endpoints.
+// The percentages are left unset when any weight is missing or all the weights are 0.
+func setEndpointPercentages(endpoints []fleetnetv1beta1.TrafficManagerEndpointStatus) {
+	var total int64
+	for i := range endpoints {
+		if endpoints[i].Weight == nil {
+			return
+		}
+		total += *endpoints[i].Weight
+	}
+	if total == 0 {
+		return
+	}
+	for i := range endpoints {
+		endpoints[i].Percentage = ptr.To((*endpoints[i].Weight*100 + total/2) / total) // rounded to the nearest integer
+	}
+}
+
+// azureTrafficManagerEndpointType returns the endpoint type parsed from the resource type of the endpoint, for example,
+// "ExternalEndpoints" for "Microsoft.Network/trafficManagerProfiles/externalEndpoints".
+// It defaults to "AzureEndpoints" when the type is unknown.
+func azureTrafficManagerEndpointType(endpoint armtrafficmanager.Endpoint) armtrafficmanager.EndpointType {
+	if endpoint.Type == nil {
+		return armtrafficmanager.EndpointTypeAzureEndpoints
+	}
+	// Note: ATM server returns the type in camel case, for example, "azureEndpoints".
+	t := *endpoint.Type
+	if len(t) >= len(azureTrafficManagerEndpointTypePrefix) && strings.EqualFold(t[:len(azureTrafficManagerEndpointTypePrefix)], azureTrafficManagerEndpointTypePrefix) {
+		t = t[len(azureTrafficManagerEndpointTypePrefix):]
+	}
+	for _, endpointType := range armtrafficmanager.PossibleEndpointTypeValues() {
+		if strings.EqualFold(t, string(endpointType)) {
+			return endpointType
+		}
 	}
+	return armtrafficmanager.EndpointTypeAzureEndpoints
 }
 
 // equalAzureTrafficManagerEndpoint compares only few fields of the current and desired Azure Traffic Manager endpoints
@@ -645,19 +3085,189 @@ func equalAzureTrafficManagerEndpoint(current, desired armtrafficmanager.Endpoin
 	if current.Type == nil || !strings.EqualFold(*current.Type, *desired.Type) {
 		return false
 	}
-	if current.Properties == nil || current.Properties.TargetResourceID == nil || current.Properties.Weight == nil || current.Properties.EndpointStatus == nil {
+	if current.Properties == nil || current.Properties.EndpointStatus == nil {
+		return false
+	}
+	// The azure endpoint is identified by the target resource while the external endpoint is identified by the target.
+	// The ATM server populates the target of the azure endpoint from the target resource, so that the target is ignored
+	// when the target resource is desired, while an endpoint having only the target (for example, an adopted endpoint
+	// created with the domain name) needs to be updated to reference the target resource.
+	if desired.Properties.TargetResourceID != nil && (current.Properties.TargetResourceID == nil || !strings.EqualFold(*current.Properties.TargetResourceID, *desired.Properties.TargetResourceID)) {
+		return false
+	}
+	if desired.Properties.TargetResourceID == nil && current.Properties.TargetResourceID != nil {
+		return false
+	}
+	if desired.Properties.Target != nil && (current.Properties.Target == nil || !strings.EqualFold(*current.Properties.Target, *desired.Properties.Target)) {
+		return false
+	}
+	// The location is only set for the external endpoints.
+	if desired.Properties.EndpointLocation != nil && (current.Properties.EndpointLocation == nil || !equalAzureLocation(*current.Properties.EndpointLocation, *desired.Properties.EndpointLocation)) {
+		return false
+	}
+	// The weight is only set when using the "Weighted" routing method.
+	if desired.Properties.Weight != nil && (current.Properties.Weight == nil || *current.Properties.Weight != *desired.Properties.Weight) {
+		return false
+	}
+	// The priority is only set when using the "Priority" routing method.
+	if desired.Properties.Priority != nil && (current.Properties.Priority == nil || *current.Properties.Priority != *desired.Properties.Priority) {
+		return false
+	}
+	return *current.Properties.EndpointStatus == *desired.Properties.EndpointStatus &&
+		alwaysServeOf(current.Properties) == alwaysServeOf(desired.Properties) &&
+		equalGeoMapping(current.Properties.GeoMapping, desired.Properties.GeoMapping) &&
+		equalSubnets(current.Properties.Subnets, desired.Properties.Subnets)
+}
+
+// diffAzureTrafficManagerEndpoint returns the fields which differ between the current Azure Traffic Manager endpoint
+// and the desired one, in the format of "field (desired: x, actual: y)", so that the operators can find out which field
+// triggers the update of the endpoint.
+// The fields are compared in the same way as equalAzureTrafficManagerEndpoint.
+func diffAzureTrafficManagerEndpoint(current, desired armtrafficmanager.Endpoint) []string {
+	var diffs []string
+	if current.Type == nil || !strings.EqualFold(*current.Type, ptr.Deref(desired.Type, "")) {
+		diffs = append(diffs, formatFieldDiff("type", ptr.Deref(desired.Type, ""), current.Type))
+	}
+	currentProperties := current.Properties
+	if currentProperties == nil {
+		currentProperties = &armtrafficmanager.EndpointProperties{}
+	}
+	desiredProperties := desired.Properties
+	if desiredProperties == nil {
+		desiredProperties = &armtrafficmanager.EndpointProperties{}
+	}
+	if desiredProperties.TargetResourceID != nil && (currentProperties.TargetResourceID == nil || !strings.EqualFold(*currentProperties.TargetResourceID, *desiredProperties.TargetResourceID)) {
+		diffs = append(diffs, formatFieldDiff("targetResourceID", *desiredProperties.TargetResourceID, currentProperties.TargetResourceID))
+	}
+	if desiredProperties.TargetResourceID == nil && currentProperties.TargetResourceID != nil {
+		diffs = append(diffs, formatFieldDiff("targetResourceID", "<unset>", currentProperties.TargetResourceID))
+	}
+	if desiredProperties.Target != nil && (currentProperties.Target == nil || !strings.EqualFold(*currentProperties.Target, *desiredProperties.Target)) {
+		diffs = append(diffs, formatFieldDiff("target", *desiredProperties.Target, currentProperties.Target))
+	}
+	if desiredProperties.EndpointLocation != nil && (currentProperties.EndpointLocation == nil || !equalAzureLocation(*currentProperties.EndpointLocation, *desiredProperties.EndpointLocation)) {
+		diffs = append(diffs, formatFieldDiff("endpointLocation", *desiredProperties.EndpointLocation, currentProperties.EndpointLocation))
+	}
+	if desiredProperties.Weight != nil && (currentProperties.Weight == nil || *currentProperties.Weight != *desiredProperties.Weight) {
+		diffs = append(diffs, formatFieldDiff("weight", *desiredProperties.Weight, currentProperties.Weight))
+	}
+	if desiredProperties.Priority != nil && (currentProperties.Priority == nil || *currentProperties.Priority != *desiredProperties.Priority) {
+		diffs = append(diffs, formatFieldDiff("priority", *desiredProperties.Priority, currentProperties.Priority))
+	}
+	if desiredProperties.EndpointStatus != nil && (currentProperties.EndpointStatus == nil || *currentProperties.EndpointStatus != *desiredProperties.EndpointStatus) {
+		diffs = append(diffs, formatFieldDiff("endpointStatus", *desiredProperties.EndpointStatus, currentProperties.EndpointStatus))
+	}
+	if currentAlwaysServe := alwaysServeOf(currentProperties); currentAlwaysServe != alwaysServeOf(desiredProperties) {
+		diffs = append(diffs, formatFieldDiff("alwaysServe", alwaysServeOf(desiredProperties), &currentAlwaysServe))
+	}
+	if !equalGeoMapping(currentProperties.GeoMapping, desiredProperties.GeoMapping) {
+		currentGeoMapping := formatGeoMapping(currentProperties.GeoMapping)
+		diffs = append(diffs, formatFieldDiff("geoMapping", formatGeoMapping(desiredProperties.GeoMapping), &currentGeoMapping))
+	}
+	if !equalSubnets(currentProperties.Subnets, desiredProperties.Subnets) {
+		currentSubnets := formatSubnets(currentProperties.Subnets)
+		diffs = append(diffs, formatFieldDiff("subnets", formatSubnets(desiredProperties.Subnets), &currentSubnets))
+	}
+	return diffs
+}
+
+// formatSubnets joins the subnets with commas.
+func formatSubnets(subnets []*armtrafficmanager.EndpointPropertiesSubnetsItem) string {
+	res := make([]string, 0, len(subnets))
+	for _, subnet := range subnets {
+		res = append(res, formatAzureTrafficManagerEndpointSubnet(subnet))
+	}
+	return strings.Join(res, ",")
+}
+
+// formatGeoMapping joins the geographic region codes with commas.
+func formatGeoMapping(geoMapping []*string) string {
+	codes := make([]string, 0, len(geoMapping))
+	for _, code := range geoMapping {
+		codes = append(codes, ptr.Deref(code, ""))
+	}
+	return strings.Join(codes, ",")
+}
+
+// isOnlyWeightChanged returns true if the current endpoint differs from the desired one only by the weight.
+func isOnlyWeightChanged(current, desired armtrafficmanager.Endpoint) bool {
+	if current.Properties == nil || desired.Properties == nil || desired.Properties.Weight == nil {
+		return false
+	}
+	if ptr.Deref(current.Properties.Weight, 0) == ptr.Deref(desired.Properties.Weight, 0) {
+		return false
+	}
+	properties := *desired.Properties
+	properties.Weight = current.Properties.Weight
+	desired.Properties = &properties
+	return equalAzureTrafficManagerEndpoint(current, desired)
+}
+
+// equalAzureLocation compares the Azure region names ignoring the case and spaces, as ATM server returns the display
+// name of the region, for example, "East US" for "eastus".
+func equalAzureLocation(current, desired string) bool {
+	return strings.EqualFold(strings.ReplaceAll(current, " ", ""), strings.ReplaceAll(desired, " ", ""))
+}
+
+// equalGeoMapping compares the geographic region codes by ignoring the order and case.
+func equalGeoMapping(current, desired []*string) bool {
+	if len(current) != len(desired) {
+		return false
+	}
+	codes := make(map[string]bool, len(desired))
+	for _, code := range desired {
+		codes[strings.ToUpper(*code)] = true
+	}
+	for _, code := range current {
+		if code == nil || !codes[strings.ToUpper(*code)] {
+			return false
+		}
+	}
+	return true
+}
+
+// equalSubnets compares the address ranges of the subnets by ignoring the order and the notation.
+func equalSubnets(current, desired []*armtrafficmanager.EndpointPropertiesSubnetsItem) bool {
+	if len(current) != len(desired) {
 		return false
 	}
-	return strings.EqualFold(*current.Properties.TargetResourceID, *desired.Properties.TargetResourceID) &&
-		*current.Properties.Weight == *desired.Properties.Weight &&
-		*current.Properties.EndpointStatus == *desired.Properties.EndpointStatus
+	ranges := make(map[addressRange]bool, len(desired))
+	for _, subnet := range desired {
+		r, ok := parseAzureTrafficManagerEndpointSubnet(subnet)
+		if !ok {
+			return false
+		}
+		ranges[r] = true
+	}
+	for _, subnet := range current {
+		r, ok := parseAzureTrafficManagerEndpointSubnet(subnet)
+		if !ok || !ranges[r] {
+			return false
+		}
+	}
+	return true
 }
 
 // updateTrafficManagerEndpointsAndUpdateStatusIfUnknown updates the Azure Traffic Manager endpoints and updates the status of the backend if its Unknown.
 // Returns the accepted endpoints and a list of bad endpoints error when it fails to create/update endpoint or not because of bad request.
-func (r *Reconciler) updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(ctx context.Context, resourceGroup string, backend *fleetnetv1beta1.TrafficManagerBackend, profile *armtrafficmanager.Profile, desiredEndpoints map[string]desiredEndpoint) ([]fleetnetv1beta1.TrafficManagerEndpointStatus, []error, error) {
+// The existing endpoints are read from the profile, which contains all of its endpoints as described in cleanupEndpoints.
+func (r *Reconciler) updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(ctx context.Context, clients *azureclient.TrafficManagerClients, resourceGroup string, backend *fleetnetv1beta1.TrafficManagerBackend, profile *armtrafficmanager.Profile, desiredEndpoints map[string]desiredEndpoint) ([]fleetnetv1beta1.TrafficManagerEndpointStatus, []error, error) {
 	backendKObj := klog.KObj(backend)
 	acceptedEndpoints := make([]fleetnetv1beta1.TrafficManagerEndpointStatus, 0, len(desiredEndpoints))
+	recomputedWeights := make(map[string]int64) // key is the endpoint name and value is the weight before the update
+	var adoptableTargets map[string]string
+	var backends []fleetnetv1beta1.TrafficManagerBackend
+	if isEndpointAdoptionEnabled(backend) {
+		adoptableTargets = buildAdoptableEndpointTargets(desiredEndpoints)
+		// The backends are listed to tell the orphaned endpoints created by the fleet controllers from the ones owned
+		// by other backends.
+		backendList := &fleetnetv1beta1.TrafficManagerBackendList{}
+		if err := r.Client.List(ctx, backendList); err != nil {
+			klog.ErrorS(err, "Failed to list trafficManagerBackends", "trafficManagerBackend", backendKObj)
+			return nil, nil, controller.NewAPIServerError(true, err)
+		}
+		backends = backendList.Items
+	}
 	for _, endpoint := range profile.Properties.Endpoints {
 		if endpoint.Name == nil {
 			err := controller.NewUnexpectedBehaviorError(errors.New("azure Traffic Manager endpoint name is nil"))
@@ -666,20 +3276,38 @@ func (r *Reconciler) updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(ctx c
 		}
 
 		endpointName := strings.ToLower(*endpoint.Name) // resource name are case-insensitive
-		if !isEndpointOwnedByBackend(backend, endpointName) {
-			continue // skipping the endpoint which is not owned by this backend
+		adoptedBy := ""
+		if !isEndpointOwnedByBackend(r.endpointNamePrefix(), backend, endpointName) {
+			if adoptedBy = findAdoptingEndpoint(r.endpointNamePrefix(), endpointName, *endpoint, adoptableTargets, backends); adoptedBy == "" {
+				continue // skipping the endpoint which is not owned by this backend
+			}
+			// The adopted endpoint is never desired as its name differs from the managed one, so that it is deleted
+			// below and recreated under the managed name, as Azure Traffic Manager does not allow two endpoints with
+			// the same target in a profile.
+			klog.V(2).InfoS("Adopting the pre-existing Azure Traffic Manager endpoint", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfile", profile.Name, "atmEndpoint", endpointName, "managedAtmEndpoint", adoptedBy)
+		} else {
+			backend.Status.EndpointsCreated = ptr.To(true) // the endpoint has been created by the backend before
 		}
 
 		desired, ok := desiredEndpoints[endpointName]
-		if !ok {
-			klog.V(2).InfoS("Deleting the Azure Traffic Manager endpoint", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfile", profile.Name, "atmEndpoint", endpointName)
-			if _, deleteErr := r.EndpointsClient.Delete(ctx, resourceGroup, *profile.Name, armtrafficmanager.EndpointTypeAzureEndpoints, *endpoint.Name, nil); deleteErr != nil {
+		if !ok || azureTrafficManagerEndpointType(*endpoint) != azureTrafficManagerEndpointType(desired.Endpoint) {
+			// Delete the endpoint which is not desired anymore.
+			// The endpoint type cannot be updated in place either, so the endpoint whose type is changed is deleted
+			// first and the desired one will be created later.
+			klog.V(2).InfoS("Deleting the Azure Traffic Manager endpoint", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfile", profile.Name, "atmEndpoint", endpointName, "atmEndpointType", azureTrafficManagerEndpointType(*endpoint))
+			startTime := time.Now()
+			_, deleteErr := clients.EndpointsClient.Delete(ctx, resourceGroup, *profile.Name, azureTrafficManagerEndpointType(*endpoint), *endpoint.Name, nil)
+			metrics.ObserveAzureAPICall(metrics.AzureAPIClientEndpoints, metrics.AzureAPIOperationDelete, startTime, deleteErr)
+			if deleteErr != nil {
 				if azureerrors.IsNotFound(deleteErr) {
 					klog.V(2).InfoS("Ignoring NotFound Azure Traffic Manager endpoint", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfile", profile.Name, "atmEndpoint", endpointName)
 					continue
 				}
 				klog.ErrorS(deleteErr, "Failed to delete the Azure Traffic Manager endpoint", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfile", profile.Name, "atmEndpoint", endpointName)
-				r.Recorder.Eventf(backend, corev1.EventTypeWarning, backendEventReasonAzureAPIError, "Failed to delete Azure Traffic Manager endpoint %q: %v", endpointName, deleteErr)
+				r.recordAzureAPIErrorEvent(backend, deleteErr, "Failed to delete Azure Traffic Manager endpoint %q: %v", endpointName, deleteErr)
+				if azureerrors.IsAuthorizationFailed(deleteErr) {
+					return nil, nil, r.setAuthorizationFailedCondition(ctx, backend, acceptedEndpoints, deleteErr)
+				}
 				setUnknownCondition(backend, fmt.Sprintf("Failed to cleanup the existing %q for %q: %v", endpointName, *profile.Name, deleteErr))
 				if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
 					return nil, nil, err
@@ -687,6 +3315,9 @@ func (r *Reconciler) updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(ctx c
 				return nil, nil, deleteErr
 			}
 			klog.V(2).InfoS("Deleted the Azure Traffic Manager endpoint", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfile", profile.Name, "atmEndpoint", endpointName)
+			if adoptedBy != "" {
+				r.Recorder.Eventf(backend, corev1.EventTypeNormal, backendEventReasonEndpointAdopted, "Adopted the pre-existing Azure Traffic Manager endpoint %q and recreating it as %q", endpointName, adoptedBy)
+			}
 			continue
 		}
 		if equalAzureTrafficManagerEndpoint(*endpoint, desired.Endpoint) {
@@ -695,6 +3326,12 @@ func (r *Reconciler) updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(ctx c
 			acceptedEndpoints = append(acceptedEndpoints, buildAcceptedEndpointStatus(endpoint, desired))
 			continue
 		} // no need to update the endpoint if it's the same
+		if klogV := klog.V(4); klogV.Enabled() {
+			klogV.InfoS("Azure Traffic Manager endpoint differs from the desired one", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfile", profile.Name, "atmEndpoint", endpointName, "diffs", diffAzureTrafficManagerEndpoint(*endpoint, desired.Endpoint))
+		}
+		if isOnlyWeightChanged(*endpoint, desired.Endpoint) {
+			recomputedWeights[endpointName] = ptr.Deref(endpoint.Properties.Weight, 0)
+		}
 	}
 	badEndpointsError := make([]error, 0, len(desiredEndpoints))
 	// The remaining endpoints in the desiredEndpoints should be created or updated.
@@ -702,20 +3339,33 @@ func (r *Reconciler) updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(ctx c
 		klog.V(2).InfoS("Creating new Traffic Manager endpoint", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfile", profile.Name, "atmEndpoint", endpoint)
 		var responseError *azcore.ResponseError
 		endpointName := *endpoint.Endpoint.Name
-		res, updateErr := r.EndpointsClient.CreateOrUpdate(ctx, resourceGroup, *profile.Name, armtrafficmanager.EndpointTypeAzureEndpoints, endpointName, endpoint.Endpoint, nil)
+		startTime := time.Now()
+		res, updateErr := clients.EndpointsClient.CreateOrUpdate(ctx, resourceGroup, *profile.Name, azureTrafficManagerEndpointType(endpoint.Endpoint), endpointName, endpoint.Endpoint, nil)
+		metrics.ObserveAzureAPICall(metrics.AzureAPIClientEndpoints, metrics.AzureAPIOperationCreateOrUpdate, startTime, updateErr)
 		if updateErr != nil {
-			r.Recorder.Eventf(backend, corev1.EventTypeWarning, backendEventReasonAzureAPIError, "Failed to create or update Azure Traffic Manager endpoint %q: %v", endpointName, updateErr)
+			r.recordAzureAPIErrorEvent(backend, updateErr, "Failed to create or update Azure Traffic Manager endpoint %q: %v", endpointName, updateErr)
 			if !errors.As(updateErr, &responseError) {
 				klog.ErrorS(updateErr, "Failed to send the createOrUpdate request", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfile", *profile.Name, "atmEndpoint", endpointName)
 				return nil, nil, updateErr
 			}
 			klog.ErrorS(updateErr, "Failed to create or update the Traffic Manager endpoint", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfile", *profile.Name, "atmEndpoint", endpointName)
-			if azureerrors.IsClientError(updateErr) && !azureerrors.IsThrottled(updateErr) {
+			if azureerrors.IsAuthorizationFailed(updateErr) {
+				// All the other endpoints are likely to fail in the same way, so that stop processing them.
+				return nil, nil, r.setAuthorizationFailedCondition(ctx, backend, acceptedEndpoints, updateErr)
+			}
+			// The conflict is usually caused by the concurrent modification of the same profile and can be resolved by
+			// retrying, so that it's not treated as a bad endpoint.
+			if azureerrors.IsClientError(updateErr) && !azureerrors.IsThrottled(updateErr) && !azureerrors.IsConflict(updateErr) {
+				if endpoint.Endpoint.Properties.Priority != nil && azureerrors.IsBadRequest(updateErr) {
+					// The priority collides with another endpoint in the profile, which cannot be resolved by retrying
+					// until the user changes the priority of the exported service.
+					updateErr = controller.NewUserError(updateErr)
+				}
 				// When the failure is caused by the client error, will continue to process others.
-				badEndpointsError = append(badEndpointsError, updateErr)
+				badEndpointsError = append(badEndpointsError, &badEndpointError{endpoint: endpoint, err: updateErr})
 				continue
 			}
-			// For any internal error, we'll retry the request using the backoff.
+			// For any internal, throttled or conflict error, we'll retry the request using the backoff.
 			setUnknownCondition(backend, fmt.Sprintf("Failed to create or update %q for %q: %v", *endpoint.Endpoint.Name, *profile.Name, updateErr))
 			if err := r.updateTrafficManagerBackendStatus(ctx, backend); err != nil {
 				return nil, nil, err
@@ -723,7 +3373,11 @@ func (r *Reconciler) updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(ctx c
 			return nil, nil, updateErr
 		}
 		r.Recorder.Eventf(backend, corev1.EventTypeNormal, backendEventReasonAccepted, "Successfully created or updated Azure Traffic Manager endpoint %q", endpointName)
+		if oldWeight, ok := recomputedWeights[strings.ToLower(endpointName)]; ok {
+			r.Recorder.Eventf(backend, corev1.EventTypeNormal, backendEventReasonWeightRecomputed, "Updated the weight of Azure Traffic Manager endpoint %q for cluster %q from %d to %d", endpointName, endpoint.FromCluster.Cluster, oldWeight, ptr.Deref(endpoint.Endpoint.Properties.Weight, 0))
+		}
 		klog.V(2).InfoS("Created or updated Traffic Manager endpoint", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfile", profile.Name, "atmEndpoint", endpointName)
+		backend.Status.EndpointsCreated = ptr.To(true)
 		acceptedEndpoints = append(acceptedEndpoints, buildAcceptedEndpointStatus(&res.Endpoint, endpoint))
 	}
 	klog.V(2).InfoS("Successfully updated the Traffic Manager endpoints", "resourceGroup", resourceGroup, "trafficManagerBackend", backendKObj, "atmProfile", profile.Name, "numberOfAcceptedEndpoints", len(acceptedEndpoints), "numberOfBadEndpoints", len(badEndpointsError))
@@ -738,7 +3392,7 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, dis
 		if !ok {
 			return []string{}
 		}
-		return []string{tmb.Spec.Profile.Name}
+		return []string{trafficManagerProfileNamespacedName(tmb).String()}
 	}
 	if err := mgr.GetFieldIndexer().IndexField(ctx, &fleetnetv1beta1.TrafficManagerBackend{}, trafficManagerBackendProfileFieldKey, profileIndexerFunc); err != nil {
 		klog.ErrorS(err, "Failed to setup profile field indexer for TrafficManagerBackend")
@@ -750,7 +3404,7 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, dis
 		if !ok {
 			return []string{}
 		}
-		return []string{tmb.Spec.Backend.Name}
+		return serviceImportNames(tmb)
 	}
 	if err := mgr.GetFieldIndexer().IndexField(ctx, &fleetnetv1beta1.TrafficManagerBackend{}, trafficManagerBackendBackendFieldKey, backendIndexerFunc); err != nil {
 		klog.ErrorS(err, "Failed to setup backend field indexer for TrafficManagerBackend")
@@ -772,8 +3426,9 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, dis
 		}
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1beta1.TrafficManagerBackend{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(ctrlcontroller.Options{RateLimiter: r.RateLimiter}).
 		Watches(
 			&fleetnetv1beta1.TrafficManagerProfile{},
 			handler.Funcs{
@@ -798,7 +3453,7 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, dis
 					}
 					if !shouldHandleTrafficManagerProfileUpdateEvent(oldProfile, newProfile) {
 						klog.V(2).InfoS("Skipping requeueing trafficManagerProfile update event", "trafficManagerProfile", klog.KObj(e.ObjectNew))
-						return // no need to requeue if the clusters haven't changed
+						return // no need to requeue if neither the programmed condition nor the force resync annotation has changed
 					}
 					r.handleTrafficManagerProfileEvent(ctx, e.ObjectNew, q)
 				},
@@ -836,7 +3491,7 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, dis
 					}
 					if !shouldHandleServiceImportUpateEvent(oldServiceImport, newServiceImport) {
 						klog.V(2).InfoS("Skipping requeueing serviceImport update event", "serviceImport", klog.KObj(e.ObjectNew))
-						return // no need to requeue if the clusters haven't changed
+						return // no need to requeue if neither the programmed condition nor the force resync annotation has changed
 					}
 					r.handleServiceImportEvent(ctx, e.ObjectNew, q)
 				},
@@ -878,16 +3533,50 @@ func (r *Reconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, dis
 					}
 					r.handleInternalServiceExportEvent(ctx, e.ObjectNew, q)
 				},
+				DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+					klog.V(2).InfoS("Received internalServiceExport delete event", "internalServiceExport", klog.KObj(e.Object))
+					r.handleInternalServiceExportDeleteEvent(ctx, e.Object, q)
+				},
 				GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 					klog.V(2).InfoS("Received internalServiceExport generic event", "internalServiceExport", klog.KObj(e.Object))
 					r.handleInternalServiceExportEvent(ctx, e.Object, q)
 				},
 			},
-		).
-		Complete(r)
+		)
+	if r.PauseConfigMap.Name != "" {
+		// Re-trigger all the backends when the reconciliation is paused or resumed.
+		b = b.Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.handlePauseConfigMapEvent),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+				return o.GetNamespace() == r.PauseConfigMap.Namespace && o.GetName() == r.PauseConfigMap.Name
+			})),
+		)
+	}
+	return b.Complete(r)
+}
+
+// handlePauseConfigMapEvent returns the requests of all the backends when the pause configMap is changed.
+func (r *Reconciler) handlePauseConfigMapEvent(ctx context.Context, object client.Object) []reconcile.Request {
+	klog.V(2).InfoS("Received pause configMap event", "configMap", klog.KObj(object))
+	backendList := &fleetnetv1beta1.TrafficManagerBackendList{}
+	if err := r.Client.List(ctx, backendList); err != nil {
+		klog.ErrorS(err, "Failed to list trafficManagerBackends for the pause configMap", "configMap", klog.KObj(object))
+		return nil
+	}
+	res := make([]reconcile.Request, 0, len(backendList.Items))
+	for i := range backendList.Items {
+		res = append(res, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: backendList.Items[i].Namespace, Name: backendList.Items[i].Name}})
+	}
+	return res
 }
 
+// shouldHandleTrafficManagerProfileUpdateEvent returns true if the Programmed condition of the profile is changed or the
+// backends are forced to be re-synced by the annotation.
 func shouldHandleTrafficManagerProfileUpdateEvent(old, new *fleetnetv1beta1.TrafficManagerProfile) bool {
+	if old.Annotations[objectmeta.TrafficManagerProfileAnnotationForceResync] != new.Annotations[objectmeta.TrafficManagerProfileAnnotationForceResync] {
+		return true
+	}
 	oldCondition := meta.FindStatusCondition(old.Status.Conditions, string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed))
 	newCondition := meta.FindStatusCondition(new.Status.Conditions, string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed))
 	return !condition.EqualConditionIgnoreReason(oldCondition, newCondition)
@@ -902,17 +3591,32 @@ func shouldHandleInternalServiceExportUpdateEvent(old, new *fleetnetv1alpha1.Int
 	return old.Spec.Type != new.Spec.Type ||
 		old.Spec.IsDNSLabelConfigured != new.Spec.IsDNSLabelConfigured ||
 		old.Spec.IsInternalLoadBalancer != new.Spec.IsInternalLoadBalancer ||
+		!equality.Semantic.DeepEqual(old.Spec.Ports, new.Spec.Ports) ||
 		!equality.Semantic.DeepEqual(old.Spec.PublicIPResourceID, new.Spec.PublicIPResourceID) ||
-		!equality.Semantic.DeepEqual(old.Spec.Weight, new.Spec.Weight)
+		!equality.Semantic.DeepEqual(old.Spec.PublicIPAddresses, new.Spec.PublicIPAddresses) ||
+		!equality.Semantic.DeepEqual(old.Spec.Weight, new.Spec.Weight) ||
+		!equality.Semantic.DeepEqual(old.Spec.WeightPercentage, new.Spec.WeightPercentage) ||
+		!equality.Semantic.DeepEqual(old.Spec.MinWeightPercentage, new.Spec.MinWeightPercentage) ||
+		!equality.Semantic.DeepEqual(old.Spec.MaxWeightPercentage, new.Spec.MaxWeightPercentage) ||
+		!equality.Semantic.DeepEqual(old.Spec.ReadyEndpointCount, new.Spec.ReadyEndpointCount) ||
+		!equality.Semantic.DeepEqual(old.Spec.Priority, new.Spec.Priority) ||
+		!equality.Semantic.DeepEqual(old.Spec.ExternalTargetFQDN, new.Spec.ExternalTargetFQDN) ||
+		!equality.Semantic.DeepEqual(old.Spec.ExternalTargetIP, new.Spec.ExternalTargetIP) ||
+		!equality.Semantic.DeepEqual(old.Spec.ExternalTargetLocation, new.Spec.ExternalTargetLocation) ||
+		!equality.Semantic.DeepEqual(old.Spec.Region, new.Spec.Region) ||
+		old.Annotations[objectmeta.ServiceExportAnnotationGeoMapping] != new.Annotations[objectmeta.ServiceExportAnnotationGeoMapping] ||
+		old.Annotations[objectmeta.ServiceExportAnnotationSubnets] != new.Annotations[objectmeta.ServiceExportAnnotationSubnets] ||
+		old.Annotations[objectmeta.ServiceExportAnnotationAlwaysServe] != new.Annotations[objectmeta.ServiceExportAnnotationAlwaysServe] ||
+		old.Annotations[objectmeta.InternalServiceExportAnnotationEndpointDisabled] != new.Annotations[objectmeta.InternalServiceExportAnnotationEndpointDisabled]
 }
 
 func (r *Reconciler) handleTrafficManagerProfileEvent(ctx context.Context, object client.Object, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
 	trafficManagerBackendList := &fleetnetv1beta1.TrafficManagerBackendList{}
 	fieldMatcher := client.MatchingFields{
-		trafficManagerBackendProfileFieldKey: object.GetName(),
+		trafficManagerBackendProfileFieldKey: types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()}.String(),
 	}
-	// For now, we only support the backend and profile in the same namespace.
-	if err := r.Client.List(ctx, trafficManagerBackendList, client.InNamespace(object.GetNamespace()), fieldMatcher); err != nil {
+	// The backends referencing the profile could be in any namespace.
+	if err := r.Client.List(ctx, trafficManagerBackendList, fieldMatcher); err != nil {
 		klog.ErrorS(err,
 			"Failed to list trafficManagerBackends for the profile",
 			"trafficManagerProfile", klog.KObj(object))
@@ -920,6 +3624,10 @@ func (r *Reconciler) handleTrafficManagerProfileEvent(ctx context.Context, objec
 	}
 
 	for _, backend := range trafficManagerBackendList.Items {
+		if isSuspended(&backend) {
+			// The suspended backend will be reconciled when it is resumed.
+			continue
+		}
 		q.Add(reconcile.Request{
 			NamespacedName: types.NamespacedName{
 				Namespace: backend.Namespace,
@@ -943,6 +3651,10 @@ func (r *Reconciler) handleServiceImportEvent(ctx context.Context, object client
 	}
 
 	for _, backend := range trafficManagerBackendList.Items {
+		if isSuspended(&backend) {
+			// The suspended backend will be reconciled when it is resumed.
+			continue
+		}
 		q.Add(reconcile.Request{
 			NamespacedName: types.NamespacedName{
 				Namespace: backend.Namespace,
@@ -980,14 +3692,41 @@ func (r *Reconciler) handleInternalServiceExportEvent(ctx context.Context, objec
 	}
 }
 
+// handleInternalServiceExportDeleteEvent enqueues the backends of the exported service when the internalServiceExport
+// is deleted, for example, when the member cluster leaves the fleet.
+// Unlike handleInternalServiceExportEvent, the backends are enqueued regardless of the serviceImport status, as the
+// cluster may have been removed from the serviceImport status already, or the serviceImport may not be updated yet.
+// In the latter case, the backend keeps requeueing the request for the missing internalServiceExport until the
+// serviceImport is updated, so that the orphaned endpoint is cleaned up promptly.
+func (r *Reconciler) handleInternalServiceExportDeleteEvent(ctx context.Context, object client.Object, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	internalServiceExport, ok := object.(*fleetnetv1alpha1.InternalServiceExport)
+	if !ok {
+		return
+	}
+	// The serviceImport may be deleted together with the last internalServiceExport, so that the backends are found by
+	// the serviceImport name without reading the serviceImport.
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: internalServiceExport.Spec.ServiceReference.Namespace,
+			Name:      internalServiceExport.Spec.ServiceReference.Name,
+		},
+	}
+	r.handleServiceImportEvent(ctx, serviceImport, q)
+}
+
+// emitTrafficManagerBackendEndpointsMetric emits the number of the accepted endpoints, invalid services and bad
+// endpoints of the traffic manager backend.
+func emitTrafficManagerBackendEndpointsMetric(backend *fleetnetv1beta1.TrafficManagerBackend, accepted, invalid, bad int) {
+	trafficManagerBackendEndpoints.WithLabelValues(backend.GetNamespace(), backend.GetName(), "accepted").Set(float64(accepted))
+	trafficManagerBackendEndpoints.WithLabelValues(backend.GetNamespace(), backend.GetName(), "invalid").Set(float64(invalid))
+	trafficManagerBackendEndpoints.WithLabelValues(backend.GetNamespace(), backend.GetName(), "bad").Set(float64(bad))
+}
+
 // emitTrafficManagerBackendStatusMetric emits the traffic manager backend status metric based on status conditions.
 func emitTrafficManagerBackendStatusMetric(backend *fleetnetv1beta1.TrafficManagerBackend) {
-	generation := backend.Generation
-	genStr := strconv.FormatInt(generation, 10)
-
 	cond := meta.FindStatusCondition(backend.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted))
-	if cond != nil && cond.ObservedGeneration == generation {
-		trafficManagerBackendStatusLastTimestampSeconds.WithLabelValues(backend.GetNamespace(), backend.GetName(), genStr,
+	if cond != nil && cond.ObservedGeneration == backend.Generation {
+		trafficManagerBackendStatusLastTimestampSeconds.WithLabelValues(backend.GetNamespace(), backend.GetName(),
 			string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted), string(cond.Status), cond.Reason).SetToCurrentTime()
 		return
 	}
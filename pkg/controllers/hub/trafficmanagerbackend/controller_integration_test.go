@@ -8,7 +8,6 @@ package trafficmanagerbackend
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -52,6 +51,7 @@ var (
 
 func resetTrafficManagerBackendMetricsRegistry() {
 	trafficManagerBackendStatusLastTimestampSeconds.Reset()
+	trafficManagerBackendEndpoints.Reset()
 }
 
 func trafficManagerBackendForTest(name, profileName, serviceImportName string) *fleetnetv1beta1.TrafficManagerBackend {
@@ -80,6 +80,15 @@ func trafficManagerProfileForTest(name string) *fleetnetv1beta1.TrafficManagerPr
 		},
 		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
 			ResourceGroup: fakeprovider.DefaultResourceGroupName,
+			// matches the monitor config of the Azure Traffic Manager profile returned by the fake provider
+			MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+				IntervalInSeconds:         ptr.To(int64(10)),
+				Path:                      ptr.To("/healthz"),
+				Port:                      ptr.To(int64(8080)),
+				Protocol:                  ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolHTTP),
+				TimeoutInSeconds:          ptr.To(int64(9)),
+				ToleratedNumberOfFailures: ptr.To(int64(4)),
+			},
 		},
 	}
 }
@@ -92,6 +101,12 @@ func buildFalseCondition(generation int64) []metav1.Condition {
 			Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonInvalid),
 			ObservedGeneration: generation,
 		},
+		{
+			Status:             metav1.ConditionFalse,
+			Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+			Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonNotAccepted),
+			ObservedGeneration: generation,
+		},
 	}
 }
 
@@ -103,6 +118,12 @@ func buildUnknownCondition(generation int64) []metav1.Condition {
 			Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
 			ObservedGeneration: generation,
 		},
+		{
+			Status:             metav1.ConditionUnknown,
+			Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+			Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
+			ObservedGeneration: generation,
+		},
 	}
 }
 
@@ -114,6 +135,12 @@ func buildTrueCondition(generation int64) []metav1.Condition {
 			Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonAccepted),
 			ObservedGeneration: generation,
 		},
+		{
+			Status:             metav1.ConditionTrue,
+			Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+			Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonReady),
+			ObservedGeneration: generation,
+		},
 	}
 }
 
@@ -158,7 +185,6 @@ func generateMetrics(
 		Label: []*prometheusclientmodel.LabelPair{
 			{Name: ptr.To("namespace"), Value: &backend.Namespace},
 			{Name: ptr.To("name"), Value: &backend.Name},
-			{Name: ptr.To("generation"), Value: ptr.To(strconv.FormatInt(backend.Generation, 10))},
 			{Name: ptr.To("condition"), Value: ptr.To(condition.Type)},
 			{Name: ptr.To("status"), Value: ptr.To(string(condition.Status))},
 			{Name: ptr.To("reason"), Value: ptr.To(condition.Reason)},
@@ -227,6 +253,20 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 			validateTrafficManagerBackendMetricsEmitted(wantMetrics...)
 		})
 
+		It("Validating the trafficManagerBackend status is owned by the controller", func() {
+			got := &fleetnetv1beta1.TrafficManagerBackend{}
+			Expect(k8sClient.Get(ctx, namespacedName, got)).Should(Succeed(), "failed to get trafficManagerBackend")
+			var statusManagers []metav1.ManagedFieldsEntry
+			for _, entry := range got.ManagedFields {
+				if entry.Subresource == "status" {
+					statusManagers = append(statusManagers, entry)
+				}
+			}
+			Expect(statusManagers).Should(HaveLen(1), "want the status to be managed by a single field manager")
+			Expect(statusManagers[0].Manager).Should(Equal(StatusFieldManager))
+			Expect(statusManagers[0].Operation).Should(Equal(metav1.ManagedFieldsOperationApply))
+		})
+
 		It("Deleting trafficManagerBackend", func() {
 			err := k8sClient.Delete(ctx, backend)
 			Expect(err).Should(Succeed(), "failed to delete trafficManagerBackend")
@@ -1073,7 +1113,7 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 								},
 								Weight: ptr.To(int64(1)),
 							},
-							Weight:     ptr.To(int64(4)), // 1/3 of 10
+							Weight:     ptr.To(int64(3)), // 1/3 of 10 and the sum of the endpoint weights is 10
 							Target:     ptr.To(fakeprovider.ValidEndpointTarget),
 							ResourceID: fmt.Sprintf(fakeprovider.EndpointResourceIDFormat, fakeprovider.DefaultSubscriptionID, fakeprovider.DefaultResourceGroupName, profileName, atmEndpointNames[1]),
 						},
@@ -1159,6 +1199,7 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 		})
 
 		It("Validating trafficManagerBackend", func() {
+			atmEndpointName := fmt.Sprintf(AzureResourceEndpointNameFormat, backendName+"#", serviceName, memberClusterNames[0])
 			want := fleetnetv1beta1.TrafficManagerBackend{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:       backendName,
@@ -1168,6 +1209,21 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 				Spec: backend.Spec,
 				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
 					Conditions: buildUnknownCondition(backend.Generation),
+					// The last accepted endpoints are kept as the existing Azure endpoints are still serving the traffic.
+					Endpoints: []fleetnetv1beta1.TrafficManagerEndpointStatus{
+						{
+							Name: atmEndpointName,
+							From: &fleetnetv1beta1.FromCluster{
+								ClusterStatus: fleetnetv1beta1.ClusterStatus{
+									Cluster: memberClusterNames[0],
+								},
+								Weight: ptr.To(int64(2)),
+							},
+							Weight:     ptr.To(int64(7)),
+							Target:     ptr.To(fakeprovider.ValidEndpointTarget),
+							ResourceID: fmt.Sprintf(fakeprovider.EndpointResourceIDFormat, fakeprovider.DefaultSubscriptionID, fakeprovider.DefaultResourceGroupName, profileName, atmEndpointName),
+						},
+					},
 				},
 			}
 			validator.ValidateTrafficManagerBackend(ctx, k8sClient, &want, timeout)
@@ -1338,7 +1394,7 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 			// * unknown
 			// * false
 			// * true
-			// * true with new generation
+			wantMetrics = wantMetrics[:len(wantMetrics)-1] // The new generation overwrites the last one as the generation is not a label.
 			wantMetrics = append(wantMetrics, generateMetrics(backend, want.Status.Conditions[0]))
 			validateTrafficManagerBackendMetricsEmitted(wantMetrics...)
 		})
@@ -1369,6 +1425,227 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 		})
 	})
 
+	Context("When creating trafficManagerBackend with the Geographic routing method", Ordered, func() {
+		profileName := fakeprovider.ValidProfileWithGeographicRoutingName
+		profileNamespacedName := types.NamespacedName{Namespace: testNamespace, Name: profileName}
+		var profile *fleetnetv1beta1.TrafficManagerProfile
+		backendName := fakeprovider.ValidBackendName
+		backendNamespacedName := types.NamespacedName{Namespace: testNamespace, Name: backendName}
+		var backend *fleetnetv1beta1.TrafficManagerBackend
+		atmEndpointNames := []string{
+			fmt.Sprintf(AzureResourceEndpointNameFormat, backendName+"#", serviceName, memberClusterNames[0]),
+			fmt.Sprintf(AzureResourceEndpointNameFormat, backendName+"#", serviceName, memberClusterNames[3]),
+		}
+
+		var serviceImport *fleetnetv1alpha1.ServiceImport
+
+		var wantMetrics []*prometheusclientmodel.Metric
+
+		It("Creating a new TrafficManagerProfile", func() {
+			By("By creating a new TrafficManagerProfile")
+			profile = trafficManagerProfileForTest(profileName)
+			profile.Spec.RoutingMethod = fleetnetv1beta1.TrafficManagerRoutingMethodGeographic
+			Expect(k8sClient.Create(ctx, profile)).Should(Succeed())
+		})
+
+		It("Updating TrafficManagerProfile status to programmed true", func() {
+			By("By updating TrafficManagerProfile status")
+			updateTrafficManagerProfileStatusToTrue(ctx, profile)
+		})
+
+		It("Creating TrafficManagerBackend", func() {
+			backend = trafficManagerBackendForTest(backendName, profileName, serviceName)
+			Expect(k8sClient.Create(ctx, backend)).Should(Succeed())
+		})
+
+		It("Validating trafficManagerBackend", func() {
+			want := fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       backendName,
+					Namespace:  testNamespace,
+					Finalizers: []string{objectmeta.MetricsFinalizer},
+				},
+				Spec: backend.Spec,
+				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+					Conditions: buildFalseCondition(backend.Generation),
+				},
+			}
+			validator.ValidateTrafficManagerBackend(ctx, k8sClient, &want, timeout)
+			validator.ValidateTrafficManagerBackendConsistently(ctx, k8sClient, &want)
+
+			By("By validating the status metrics")
+			// Metrics are sorted by timestamp
+			// * false
+			wantMetrics = append(wantMetrics, generateMetrics(backend, want.Status.Conditions[0]))
+			validateTrafficManagerBackendMetricsEmitted(wantMetrics...)
+		})
+
+		It("Creating a new ServiceImport", func() {
+			serviceImport = &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      serviceName,
+					Namespace: testNamespace,
+				},
+			}
+			Expect(k8sClient.Create(ctx, serviceImport)).Should(Succeed(), "failed to create serviceImport")
+		})
+
+		It("Updating the ServiceImport status", func() {
+			serviceImport.Status = fleetnetv1alpha1.ServiceImportStatus{
+				Clusters: []fleetnetv1alpha1.ClusterStatus{
+					{
+						Cluster: memberClusterNames[0],
+					},
+					{
+						Cluster: memberClusterNames[3],
+					},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, serviceImport)).Should(Succeed(), "failed to create serviceImport")
+		})
+
+		It("Validating trafficManagerBackend when the geo mapping is not configured", func() {
+			want := fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       backendName,
+					Namespace:  testNamespace,
+					Finalizers: []string{objectmeta.TrafficManagerBackendFinalizer, objectmeta.MetricsFinalizer},
+				},
+				Spec: backend.Spec,
+				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+					Conditions: buildFalseCondition(backend.Generation),
+				},
+			}
+			validator.ValidateTrafficManagerBackend(ctx, k8sClient, &want, timeout)
+			validator.ValidateTrafficManagerBackendConsistently(ctx, k8sClient, &want)
+
+			By("By validating the status metrics")
+			// Metrics are sorted by timestamp
+			// * false
+			validateTrafficManagerBackendMetricsEmitted(wantMetrics...)
+		})
+
+		It("Updating the geo mapping of the internalServiceExports with overlapping regions", func() {
+			updateInternalServiceExportGeoMapping(&internalServiceExports[0], "GEO-EU")
+			updateInternalServiceExportGeoMapping(&internalServiceExports[3], "geo-eu,US")
+		})
+
+		It("Validating trafficManagerBackend when the geo mappings are overlapping", func() {
+			want := fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       backendName,
+					Namespace:  testNamespace,
+					Finalizers: []string{objectmeta.TrafficManagerBackendFinalizer, objectmeta.MetricsFinalizer},
+				},
+				Spec: backend.Spec,
+				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+					Conditions: buildFalseCondition(backend.Generation),
+					Endpoints: []fleetnetv1beta1.TrafficManagerEndpointStatus{
+						{
+							Name: atmEndpointNames[0],
+							From: &fleetnetv1beta1.FromCluster{
+								ClusterStatus: fleetnetv1beta1.ClusterStatus{
+									Cluster: memberClusterNames[0],
+								},
+							},
+							GeoMapping: []string{"GEO-EU"}, // the weight is not set for the "Geographic" routing method
+							Target:     ptr.To(fakeprovider.ValidEndpointTarget),
+							ResourceID: fmt.Sprintf(fakeprovider.EndpointResourceIDFormat, fakeprovider.DefaultSubscriptionID, fakeprovider.DefaultResourceGroupName, profileName, atmEndpointNames[0]),
+						},
+					},
+				},
+			}
+			validator.ValidateTrafficManagerBackend(ctx, k8sClient, &want, timeout)
+			validator.ValidateTrafficManagerBackendConsistently(ctx, k8sClient, &want)
+
+			By("By validating the status metrics")
+			// Metrics are sorted by timestamp
+			// * false
+			validateTrafficManagerBackendMetricsEmitted(wantMetrics...)
+		})
+
+		It("Updating the geo mapping of the internalServiceExport to remove the overlapping regions", func() {
+			updateInternalServiceExportGeoMapping(&internalServiceExports[3], "US")
+		})
+
+		It("Validating trafficManagerBackend", func() {
+			want := fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       backendName,
+					Namespace:  testNamespace,
+					Finalizers: []string{objectmeta.TrafficManagerBackendFinalizer, objectmeta.MetricsFinalizer},
+				},
+				Spec: backend.Spec,
+				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+					Conditions: buildTrueCondition(backend.Generation),
+					Endpoints: []fleetnetv1beta1.TrafficManagerEndpointStatus{
+						{
+							Name: atmEndpointNames[0],
+							From: &fleetnetv1beta1.FromCluster{
+								ClusterStatus: fleetnetv1beta1.ClusterStatus{
+									Cluster: memberClusterNames[0],
+								},
+							},
+							GeoMapping: []string{"GEO-EU"},
+							Target:     ptr.To(fakeprovider.ValidEndpointTarget),
+							ResourceID: fmt.Sprintf(fakeprovider.EndpointResourceIDFormat, fakeprovider.DefaultSubscriptionID, fakeprovider.DefaultResourceGroupName, profileName, atmEndpointNames[0]),
+						},
+						{
+							Name: atmEndpointNames[1],
+							From: &fleetnetv1beta1.FromCluster{
+								ClusterStatus: fleetnetv1beta1.ClusterStatus{
+									Cluster: memberClusterNames[3],
+								},
+							},
+							GeoMapping: []string{"US"},
+							Target:     ptr.To(fakeprovider.ValidEndpointTarget),
+							ResourceID: fmt.Sprintf(fakeprovider.EndpointResourceIDFormat, fakeprovider.DefaultSubscriptionID, fakeprovider.DefaultResourceGroupName, profileName, atmEndpointNames[1]),
+						},
+					},
+				},
+			}
+			validator.ValidateTrafficManagerBackend(ctx, k8sClient, &want, timeout)
+			validator.ValidateTrafficManagerBackendConsistently(ctx, k8sClient, &want)
+
+			By("By validating the status metrics")
+			// Metrics are sorted by timestamp
+			// * false
+			// * true
+			wantMetrics = append(wantMetrics, generateMetrics(backend, want.Status.Conditions[0]))
+			validateTrafficManagerBackendMetricsEmitted(wantMetrics...)
+		})
+
+		It("Deleting trafficManagerBackend", func() {
+			err := k8sClient.Delete(ctx, backend)
+			Expect(err).Should(Succeed(), "failed to delete trafficManagerBackend")
+		})
+
+		It("Validating trafficManagerBackend is deleted", func() {
+			validator.IsTrafficManagerBackendDeleted(ctx, k8sClient, backendNamespacedName, timeout)
+
+			By("By validating the status metrics")
+			validateTrafficManagerBackendMetricsEmitted()
+		})
+
+		It("Deleting trafficManagerProfile", func() {
+			err := k8sClient.Delete(ctx, profile)
+			Expect(err).Should(Succeed(), "failed to delete trafficManagerProfile")
+		})
+
+		It("Validating trafficManagerProfile is deleted", func() {
+			validator.IsTrafficManagerProfileDeleted(ctx, k8sClient, profileNamespacedName, timeout)
+		})
+
+		It("Deleting serviceImport", func() {
+			deleteServiceImport(types.NamespacedName{Namespace: testNamespace, Name: serviceName})
+		})
+
+		It("Removing the geo mapping of the internalServiceExports", func() {
+			updateInternalServiceExportGeoMapping(&internalServiceExports[0], "")
+			updateInternalServiceExportGeoMapping(&internalServiceExports[3], "")
+		})
+	})
+
 	Context("When creating trafficManagerBackend with valid serviceImport and internalServiceExports (403 error)", Ordered, func() {
 		profileName := fakeprovider.ValidProfileWithEndpointsName
 		profileNamespacedName := types.NamespacedName{Namespace: testNamespace, Name: profileName}
@@ -1523,6 +1800,25 @@ var _ = Describe("Test TrafficManagerBackend Controller", func() {
 	})
 })
 
+// updateInternalServiceExportGeoMapping sets the geo mapping annotation of the internalServiceExport, or removes the
+// annotation when the geoMapping is empty.
+func updateInternalServiceExportGeoMapping(export *fleetnetv1alpha1.InternalServiceExport, geoMapping string) {
+	Eventually(func() error {
+		if err := k8sClient.Get(ctx, types.NamespacedName{Namespace: export.Namespace, Name: export.Name}, export); err != nil {
+			return err
+		}
+		if geoMapping == "" {
+			delete(export.Annotations, objectmeta.ServiceExportAnnotationGeoMapping)
+		} else {
+			if export.Annotations == nil {
+				export.Annotations = map[string]string{}
+			}
+			export.Annotations[objectmeta.ServiceExportAnnotationGeoMapping] = geoMapping
+		}
+		return k8sClient.Update(ctx, export)
+	}, timeout, interval).Should(Succeed(), "failed to update the geo mapping of internalServiceExport")
+}
+
 func deleteServiceImport(name types.NamespacedName) {
 	serviceImport := &fleetnetv1alpha1.ServiceImport{
 		ObjectMeta: metav1.ObjectMeta{
@@ -6,22 +6,79 @@ Licensed under the MIT license.
 package trafficmanagerbackend
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azcorefake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	armtrafficmanagerfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager/fake"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	prometheusclientmodel "github.com/prometheus/client_model/go"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"go.goms.io/fleet/pkg/utils/controller"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/azureclient"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
+	"go.goms.io/fleet-networking/test/common/trafficmanager/fakeprovider"
 )
 
 func TestIsValidTrafficManagerEndpoint(t *testing.T) {
+	dualStackExport := &fleetnetv1alpha1.InternalServiceExport{
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			Type:                 corev1.ServiceTypeLoadBalancer,
+			PublicIPResourceID:   ptr.To("ipv4"),
+			IsDNSLabelConfigured: true,
+			PublicIPAddresses: []fleetnetv1alpha1.PublicIPAddress{
+				{
+					IPFamily:             corev1.IPv4Protocol,
+					ResourceID:           "ipv4",
+					IsDNSLabelConfigured: true,
+				},
+				{
+					IPFamily:   corev1.IPv6Protocol,
+					ResourceID: "ipv6",
+				},
+			},
+		},
+	}
 	tests := []struct {
-		name    string
-		export  *fleetnetv1alpha1.InternalServiceExport
-		wantErr bool
+		name     string
+		ipFamily *corev1.IPFamily
+		export   *fleetnetv1alpha1.InternalServiceExport
+		wantErr  bool
 	}{
 		{
 			name: "valid endpoint",
@@ -78,10 +135,96 @@ func TestIsValidTrafficManagerEndpoint(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "node port type with external target FQDN",
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:               corev1.ServiceTypeNodePort,
+					ExternalTargetFQDN: ptr.To("app.example.com"),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "internal load balancer type with external target IP",
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					IsInternalLoadBalancer: true,
+					ExternalTargetIP:       ptr.To("20.1.2.3"),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "both external target FQDN and IP are set",
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:               corev1.ServiceTypeNodePort,
+					ExternalTargetFQDN: ptr.To("app.example.com"),
+					ExternalTargetIP:   ptr.To("20.1.2.3"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid external target FQDN",
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:               corev1.ServiceTypeNodePort,
+					ExternalTargetFQDN: ptr.To("app_example"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid external target IP",
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:             corev1.ServiceTypeNodePort,
+					ExternalTargetIP: ptr.To("20.1.2"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:     "dual-stack load balancer type with IPv4 family",
+			ipFamily: ptr.To(corev1.IPv4Protocol),
+			export:   dualStackExport,
+			wantErr:  false,
+		},
+		{
+			name:     "dual-stack load balancer type with IPv6 family but dns label not configured",
+			ipFamily: ptr.To(corev1.IPv6Protocol),
+			export:   dualStackExport,
+			wantErr:  true,
+		},
+		{
+			name:     "single-stack load balancer type without public ip of the IP family",
+			ipFamily: ptr.To(corev1.IPv6Protocol),
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                 corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:   ptr.To("ipv4"),
+					IsDNSLabelConfigured: true,
+					PublicIPAddresses: []fleetnetv1alpha1.PublicIPAddress{
+						{
+							IPFamily:             corev1.IPv4Protocol,
+							ResourceID:           "ipv4",
+							IsDNSLabelConfigured: true,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := isValidTrafficManagerEndpoint(tt.export)
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				Spec: fleetnetv1beta1.TrafficManagerBackendSpec{IPFamily: tt.ipFamily},
+			}
+			err := isValidTrafficManagerEndpoint(backend, tt.export)
 			if got := err != nil; got != tt.wantErr {
 				t.Errorf("isValidTrafficManagerEndpoint() = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -89,6 +232,147 @@ func TestIsValidTrafficManagerEndpoint(t *testing.T) {
 	}
 }
 
+func TestValidatePublicIPResourceID(t *testing.T) {
+	pipID := "/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip"
+	tests := []struct {
+		name                  string
+		publicIPSubscriptions []string
+		export                *fleetnetv1alpha1.InternalServiceExport
+		wantErr               string
+	}{
+		{
+			name: "accessible subscriptions not configured",
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{PublicIPResourceID: ptr.To("invalid-resource-id")},
+			},
+		},
+		{
+			name:                  "external target",
+			publicIPSubscriptions: []string{"sub2"},
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					PublicIPResourceID: ptr.To(pipID),
+					ExternalTargetFQDN: ptr.To("app.contoso.com"),
+				},
+			},
+		},
+		{
+			name:                  "public IP in an accessible subscription",
+			publicIPSubscriptions: []string{"sub2", "SUB1"},
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{PublicIPResourceID: ptr.To(pipID)},
+			},
+		},
+		{
+			name:                  "public IP in an inaccessible subscription",
+			publicIPSubscriptions: []string{"sub2"},
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{PublicIPResourceID: ptr.To(pipID)},
+			},
+			wantErr: "public IP in subscription sub1 is not accessible",
+		},
+		{
+			name:                  "invalid resource ID",
+			publicIPSubscriptions: []string{"sub1"},
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{PublicIPResourceID: ptr.To("invalid-resource-id")},
+			},
+			wantErr: "invalid public IP resource ID",
+		},
+		{
+			name:                  "not a public IP resource",
+			publicIPSubscriptions: []string{"sub1"},
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					PublicIPResourceID: ptr.To("/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/loadBalancers/lb"),
+				},
+			},
+			wantErr: "instead of a public IP address",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reconciler{PublicIPSubscriptions: tt.publicIPSubscriptions}
+			err := r.validatePublicIPResourceID(&fleetnetv1beta1.TrafficManagerBackend{}, tt.export)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validatePublicIPResourceID() got error %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validatePublicIPResourceID() got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMonitorPort(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-profile",
+		},
+	}
+	tests := []struct {
+		name        string
+		export      *fleetnetv1alpha1.InternalServiceExport
+		monitorPort int64
+		wantErr     string
+	}{
+		{
+			name: "monitor port is exposed",
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Ports: []fleetnetv1alpha1.ServicePort{
+						{Protocol: corev1.ProtocolTCP, Port: 80},
+						{Protocol: corev1.ProtocolTCP, Port: 443},
+					},
+				},
+			},
+			monitorPort: 443,
+		},
+		{
+			name: "monitor port is not exposed",
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Ports: []fleetnetv1alpha1.ServicePort{
+						{Protocol: corev1.ProtocolTCP, Port: 80},
+						{Protocol: corev1.ProtocolTCP, Port: 443},
+					},
+				},
+			},
+			monitorPort: 8080,
+			wantErr:     `monitor port 8080 of trafficManagerProfile "test-profile" is not exposed by the service, exposed ports: [80 443]`,
+		},
+		{
+			name: "external target is not checked",
+			export: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Ports: []fleetnetv1alpha1.ServicePort{
+						{Protocol: corev1.ProtocolTCP, Port: 80},
+					},
+					ExternalTargetFQDN: ptr.To("app.contoso.com"),
+				},
+			},
+			monitorPort: 443,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMonitorPort(profile, tt.export, tt.monitorPort)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateMonitorPort() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("validateMonitorPort() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestEqualAzureTrafficManagerEndpoint(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -216,525 +500,8817 @@ func TestEqualAzureTrafficManagerEndpoint(t *testing.T) {
 	}
 }
 
-func TestShouldHandleServiceImportUpateEvent(t *testing.T) {
+func TestEqualAzureTrafficManagerEndpoint_GeoMapping(t *testing.T) {
 	tests := []struct {
-		name string
-		old  *fleetnetv1alpha1.ServiceImport
-		new  *fleetnetv1alpha1.ServiceImport
-		want bool
+		name       string
+		geoMapping []*string
+		want       bool
 	}{
 		{
-			name: "clusters are the same",
-			old: &fleetnetv1alpha1.ServiceImport{
-				Status: fleetnetv1alpha1.ServiceImportStatus{
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
-						{Cluster: "cluster1"},
-						{Cluster: "cluster2"},
-					},
-				},
-			},
-			new: &fleetnetv1alpha1.ServiceImport{
-				Status: fleetnetv1alpha1.ServiceImportStatus{
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
-						{Cluster: "cluster1"},
-						{Cluster: "cluster2"},
-					},
-				},
-			},
-			want: false,
+			name:       "same regions with different order and case",
+			geoMapping: []*string{ptr.To("us"), ptr.To("GEO-EU")},
+			want:       true,
 		},
 		{
-			name: "clusters are different",
-			old: &fleetnetv1alpha1.ServiceImport{
-				Status: fleetnetv1alpha1.ServiceImportStatus{
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
-						{Cluster: "cluster1"},
-					},
-				},
-			},
-			new: &fleetnetv1alpha1.ServiceImport{
-				Status: fleetnetv1alpha1.ServiceImportStatus{
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
-						{Cluster: "cluster1"},
-						{Cluster: "cluster2"},
-					},
-				},
-			},
-			want: true,
+			name: "geo mapping is nil",
 		},
 		{
-			name: "empty clusters in both",
-			old: &fleetnetv1alpha1.ServiceImport{
-				Status: fleetnetv1alpha1.ServiceImportStatus{
-					Clusters: []fleetnetv1alpha1.ClusterStatus{},
-				},
-			},
-			new: &fleetnetv1alpha1.ServiceImport{
-				Status: fleetnetv1alpha1.ServiceImportStatus{
-					Clusters: []fleetnetv1alpha1.ClusterStatus{},
-				},
-			},
-			want: false,
+			name:       "different regions",
+			geoMapping: []*string{ptr.To("US"), ptr.To("GEO-AS")},
 		},
 		{
-			name: "nil clusters in old, empty in new",
-			old: &fleetnetv1alpha1.ServiceImport{
-				Status: fleetnetv1alpha1.ServiceImportStatus{
-					Clusters: nil,
-				},
-			},
-			new: &fleetnetv1alpha1.ServiceImport{
-				Status: fleetnetv1alpha1.ServiceImportStatus{
-					Clusters: []fleetnetv1alpha1.ClusterStatus{},
-				},
-			},
-			want: false,
+			name:       "fewer regions",
+			geoMapping: []*string{ptr.To("US")},
 		},
-		{
-			name: "same clusters but different order",
-			old: &fleetnetv1alpha1.ServiceImport{
-				Status: fleetnetv1alpha1.ServiceImportStatus{
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
-						{Cluster: "cluster1"},
-						{Cluster: "cluster2"},
-					},
-				},
-			},
-			new: &fleetnetv1alpha1.ServiceImport{
-				Status: fleetnetv1alpha1.ServiceImportStatus{
-					Clusters: []fleetnetv1alpha1.ClusterStatus{
-						{Cluster: "cluster2"},
-						{Cluster: "cluster1"},
-					},
-				},
-			},
-			want: true,
+	}
+	desired := armtrafficmanager.Endpoint{
+		Type: ptr.To(string(armtrafficmanager.EndpointTypeAzureEndpoints)),
+		Properties: &armtrafficmanager.EndpointProperties{
+			TargetResourceID: ptr.To("resourceID"),
+			EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+			GeoMapping:       []*string{ptr.To("GEO-EU"), ptr.To("US")},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := shouldHandleServiceImportUpateEvent(tt.old, tt.new); got != tt.want {
-				t.Errorf("shouldHandleServiceImportUpateEvent() = %v, want %v", got, tt.want)
+			current := armtrafficmanager.Endpoint{
+				Type: ptr.To(string(armtrafficmanager.EndpointTypeAzureEndpoints)),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(1)), // weight is ignored when using geographic routing method
+					GeoMapping:       tt.geoMapping,
+				},
+			}
+			if got := equalAzureTrafficManagerEndpoint(current, desired); got != tt.want {
+				t.Errorf("equalAzureTrafficManagerEndpoint() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
-func TestShouldHandleTrafficManagerProfileUpdateEvent(t *testing.T) {
+
+func TestEqualAzureTrafficManagerEndpoint_Subnets(t *testing.T) {
 	tests := []struct {
-		name string
-		old  *fleetnetv1beta1.TrafficManagerProfile
-		new  *fleetnetv1beta1.TrafficManagerProfile
-		want bool
+		name    string
+		subnets []*armtrafficmanager.EndpointPropertiesSubnetsItem
+		want    bool
 	}{
 		{
-			name: "both profiles have no conditions",
-			old: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{},
-				},
+			name: "same subnets with different order and format",
+			subnets: []*armtrafficmanager.EndpointPropertiesSubnetsItem{
+				{First: ptr.To("10.1.0.0"), Last: ptr.To("10.1.255.255")},
+				{First: ptr.To("10.0.0.0"), Scope: ptr.To(int32(24))},
 			},
-			new: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{},
-				},
+			want: true,
+		},
+		{
+			name: "subnets are nil",
+		},
+		{
+			name: "different subnets",
+			subnets: []*armtrafficmanager.EndpointPropertiesSubnetsItem{
+				{First: ptr.To("10.0.0.0"), Scope: ptr.To(int32(24))},
+				{First: ptr.To("10.2.0.0"), Scope: ptr.To(int32(16))},
 			},
-			want: false,
 		},
 		{
-			name: "old profile has no programmed condition, new profile has programmed condition",
-			old: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{},
-				},
+			name: "fewer subnets",
+			subnets: []*armtrafficmanager.EndpointPropertiesSubnetsItem{
+				{First: ptr.To("10.0.0.0"), Scope: ptr.To(int32(24))},
 			},
-			new: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
-							Status: metav1.ConditionTrue,
-							Reason: "Programmed",
-						},
-					},
-				},
+		},
+	}
+	desired := armtrafficmanager.Endpoint{
+		Type: ptr.To(string(armtrafficmanager.EndpointTypeAzureEndpoints)),
+		Properties: &armtrafficmanager.EndpointProperties{
+			TargetResourceID: ptr.To("resourceID"),
+			EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+			Subnets: []*armtrafficmanager.EndpointPropertiesSubnetsItem{
+				{First: ptr.To("10.0.0.0"), Scope: ptr.To(int32(24))},
+				{First: ptr.To("10.1.0.0"), Scope: ptr.To(int32(16))},
 			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := armtrafficmanager.Endpoint{
+				Type: ptr.To(string(armtrafficmanager.EndpointTypeAzureEndpoints)),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(1)), // weight is ignored when using subnet routing method
+					Subnets:          tt.subnets,
+				},
+			}
+			if got := equalAzureTrafficManagerEndpoint(current, desired); got != tt.want {
+				t.Errorf("equalAzureTrafficManagerEndpoint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualAzureTrafficManagerEndpoint_Priority(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority *int64
+		want     bool
+	}{
+		{
+			name:     "same priority",
+			priority: ptr.To(int64(1)),
+			want:     true,
+		},
+		{
+			name: "priority is nil",
+		},
+		{
+			name:     "different priority",
+			priority: ptr.To(int64(2)),
+		},
+	}
+	desired := armtrafficmanager.Endpoint{
+		Type: ptr.To(string(armtrafficmanager.EndpointTypeAzureEndpoints)),
+		Properties: &armtrafficmanager.EndpointProperties{
+			TargetResourceID: ptr.To("resourceID"),
+			EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+			Priority:         ptr.To(int64(1)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := armtrafficmanager.Endpoint{
+				Type: ptr.To(string(armtrafficmanager.EndpointTypeAzureEndpoints)),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(1)), // weight is ignored when using priority routing method
+					Priority:         tt.priority,
+				},
+			}
+			if got := equalAzureTrafficManagerEndpoint(current, desired); got != tt.want {
+				t.Errorf("equalAzureTrafficManagerEndpoint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualAzureTrafficManagerEndpoint_AlwaysServe(t *testing.T) {
+	tests := []struct {
+		name               string
+		currentAlwaysServe *armtrafficmanager.AlwaysServe
+		desiredAlwaysServe *armtrafficmanager.AlwaysServe
+		want               bool
+	}{
+		{
+			name: "always serve is not set",
 			want: true,
 		},
 		{
-			name: "old profile has programmed condition, new profile has no programmed condition",
-			old: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
-							Status: metav1.ConditionTrue,
-							Reason: "Programmed",
-						},
-					},
+			name:               "same always serve",
+			currentAlwaysServe: ptr.To(armtrafficmanager.AlwaysServeEnabled),
+			desiredAlwaysServe: ptr.To(armtrafficmanager.AlwaysServeEnabled),
+			want:               true,
+		},
+		{
+			name:               "unset always serve is disabled",
+			currentAlwaysServe: ptr.To(armtrafficmanager.AlwaysServeDisabled),
+			want:               true,
+		},
+		{
+			name:               "always serve is enabled",
+			currentAlwaysServe: ptr.To(armtrafficmanager.AlwaysServeDisabled),
+			desiredAlwaysServe: ptr.To(armtrafficmanager.AlwaysServeEnabled),
+		},
+		{
+			name:               "always serve is removed",
+			currentAlwaysServe: ptr.To(armtrafficmanager.AlwaysServeEnabled),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := armtrafficmanager.Endpoint{
+				Type: ptr.To(string(armtrafficmanager.EndpointTypeAzureEndpoints)),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					AlwaysServe:      tt.currentAlwaysServe,
 				},
-			},
-			new: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{},
+			}
+			desired := armtrafficmanager.Endpoint{
+				Type: ptr.To(string(armtrafficmanager.EndpointTypeAzureEndpoints)),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					AlwaysServe:      tt.desiredAlwaysServe,
 				},
-			},
-			want: true,
+			}
+			if got := equalAzureTrafficManagerEndpoint(current, desired); got != tt.want {
+				t.Errorf("equalAzureTrafficManagerEndpoint() = %v, want %v", got, tt.want)
+			}
+			if gotDiffs := diffAzureTrafficManagerEndpoint(current, desired); (len(gotDiffs) == 0) != tt.want {
+				t.Errorf("diffAzureTrafficManagerEndpoint() = %v, want no diffs %v", gotDiffs, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualAzureTrafficManagerEndpoint_TargetAndTargetResourceID(t *testing.T) {
+	azureEndpoint := armtrafficmanager.Endpoint{
+		Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+		Properties: &armtrafficmanager.EndpointProperties{
+			TargetResourceID: ptr.To("resourceID"),
+			EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+			Weight:           ptr.To(int64(1)),
 		},
+	}
+	externalEndpoint := armtrafficmanager.Endpoint{
+		Type: ptr.To("Microsoft.Network/trafficManagerProfiles/externalEndpoints"),
+		Properties: &armtrafficmanager.EndpointProperties{
+			Target:         ptr.To("app.example.com"),
+			EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+			Weight:         ptr.To(int64(1)),
+		},
+	}
+	tests := []struct {
+		name    string
+		current armtrafficmanager.Endpoint
+		desired armtrafficmanager.Endpoint
+		want    bool
+	}{
 		{
-			name: "same programmed condition status and reason",
-			old: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
-							Status: metav1.ConditionTrue,
-							Reason: "Programmed",
-						},
-					},
+			name: "azure endpoint with the target populated by the server",
+			current: armtrafficmanager.Endpoint{
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					Target:           ptr.To("app.example.com"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(1)),
 				},
 			},
-			new: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
-							Status: metav1.ConditionTrue,
-							Reason: "Programmed",
-						},
-					},
+			desired: azureEndpoint,
+			want:    true,
+		},
+		{
+			name: "azure endpoint having only the target",
+			current: armtrafficmanager.Endpoint{
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:         ptr.To("app.example.com"),
+					EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:         ptr.To(int64(1)),
 				},
 			},
-			want: false,
+			desired: azureEndpoint,
 		},
 		{
-			name: "different programmed condition status",
-			old: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
-							Status: metav1.ConditionFalse,
-							Reason: "Invalid",
-						},
-					},
+			name: "external endpoint having the target resource",
+			current: armtrafficmanager.Endpoint{
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/externalEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					Target:           ptr.To("app.example.com"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(1)),
 				},
 			},
-			new: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
-							Status: metav1.ConditionTrue,
-							Reason: "Programmed",
-						},
-					},
+			desired: externalEndpoint,
+		},
+		{
+			name: "external endpoint having only the target",
+			current: armtrafficmanager.Endpoint{
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/externalEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:         ptr.To("app.example.com"),
+					EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:         ptr.To(int64(1)),
 				},
 			},
-			want: true,
+			desired: externalEndpoint,
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := equalAzureTrafficManagerEndpoint(tt.current, tt.desired); got != tt.want {
+				t.Errorf("equalAzureTrafficManagerEndpoint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualAzureTrafficManagerEndpoint_ExternalTarget(t *testing.T) {
+	desired := armtrafficmanager.Endpoint{
+		Type: ptr.To("Microsoft.Network/trafficManagerProfiles/ExternalEndpoints"),
+		Properties: &armtrafficmanager.EndpointProperties{
+			Target:           ptr.To("app.example.com"),
+			EndpointLocation: ptr.To("eastus"),
+			EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+			Weight:           ptr.To(int64(1)),
 		},
+	}
+	tests := []struct {
+		name    string
+		current armtrafficmanager.Endpoint
+		want    bool
+	}{
 		{
-			name: "same status but different reason - should not requeue",
-			old: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
-							Status: metav1.ConditionTrue,
-							Reason: "Programmed",
-						},
-					},
+			name: "same target with different case",
+			current: armtrafficmanager.Endpoint{
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/externalEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:           ptr.To("APP.example.com"),
+					EndpointLocation: ptr.To("East US"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(1)),
 				},
 			},
-			new: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
-							Status: metav1.ConditionTrue,
-							Reason: "Updated",
-						},
-					},
+			want: true,
+		},
+		{
+			name: "location is nil",
+			current: armtrafficmanager.Endpoint{
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/externalEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:         ptr.To("app.example.com"),
+					EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:         ptr.To(int64(1)),
 				},
 			},
-			want: false,
 		},
 		{
-			name: "different condition types - should not requeue",
-			old: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   "SomeOtherCondition",
-							Status: metav1.ConditionTrue,
-							Reason: "Ready",
-						},
-					},
+			name: "different location",
+			current: armtrafficmanager.Endpoint{
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/externalEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:           ptr.To("app.example.com"),
+					EndpointLocation: ptr.To("West US"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(1)),
 				},
 			},
-			new: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   "AnotherCondition",
-							Status: metav1.ConditionTrue,
-							Reason: "Ready",
-						},
-					},
+		},
+		{
+			name: "target is nil",
+			current: armtrafficmanager.Endpoint{
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/externalEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:         ptr.To(int64(1)),
 				},
 			},
-			want: false,
 		},
 		{
-			name: "condition status changes from unknown to true",
+			name: "different target",
+			current: armtrafficmanager.Endpoint{
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/externalEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:         ptr.To("other.example.com"),
+					EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:         ptr.To(int64(1)),
+				},
+			},
+		},
+		{
+			name: "azure endpoint with the same target",
+			current: armtrafficmanager.Endpoint{
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					Target:           ptr.To("app.example.com"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(1)),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := equalAzureTrafficManagerEndpoint(tt.current, desired); got != tt.want {
+				t.Errorf("equalAzureTrafficManagerEndpoint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAzureTrafficManagerEndpointType(t *testing.T) {
+	tests := []struct {
+		name         string
+		endpointType *string
+		want         armtrafficmanager.EndpointType
+	}{
+		{
+			name: "nil type",
+			want: armtrafficmanager.EndpointTypeAzureEndpoints,
+		},
+		{
+			name:         "azure endpoints",
+			endpointType: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+			want:         armtrafficmanager.EndpointTypeAzureEndpoints,
+		},
+		{
+			name:         "external endpoints returned by the server",
+			endpointType: ptr.To("Microsoft.Network/trafficManagerProfiles/externalEndpoints"),
+			want:         armtrafficmanager.EndpointTypeExternalEndpoints,
+		},
+		{
+			name:         "type without prefix",
+			endpointType: ptr.To("ExternalEndpoints"),
+			want:         armtrafficmanager.EndpointTypeExternalEndpoints,
+		},
+		{
+			name:         "unknown type",
+			endpointType: ptr.To("Microsoft.Network/trafficManagerProfiles/unknown"),
+			want:         armtrafficmanager.EndpointTypeAzureEndpoints,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := azureTrafficManagerEndpointType(armtrafficmanager.Endpoint{Type: tt.endpointType})
+			if got != tt.want {
+				t.Errorf("azureTrafficManagerEndpointType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateAzureTrafficManagerEndpoint_ExternalTarget(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "backend-uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "service",
+			},
+		},
+	}
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	tests := []struct {
+		name string
+		spec fleetnetv1alpha1.InternalServiceExportSpec
+		want armtrafficmanager.Endpoint
+	}{
+		{
+			name: "external target FQDN",
+			spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				Type:               corev1.ServiceTypeNodePort,
+				ExternalTargetFQDN: ptr.To("app.example.com"),
+				Weight:             ptr.To(int64(10)),
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+					ClusterID: "cluster-1",
+				},
+			},
+			want: armtrafficmanager.Endpoint{
+				Name: ptr.To("fleet-backend-uid#service#cluster-1"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/ExternalEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:         ptr.To("app.example.com"),
+					EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:         ptr.To(int64(10)),
+				},
+			},
+		},
+		{
+			name: "external target IP ignores the public IP resource",
+			spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				Type:                   corev1.ServiceTypeLoadBalancer,
+				PublicIPResourceID:     ptr.To("resourceID"),
+				ExternalTargetIP:       ptr.To("20.1.2.3"),
+				ExternalTargetLocation: ptr.To("eastus"),
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+					ClusterID: "cluster-1",
+				},
+			},
+			want: armtrafficmanager.Endpoint{
+				Name: ptr.To("fleet-backend-uid#service#cluster-1"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/ExternalEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:           ptr.To("20.1.2.3"),
+					EndpointLocation: ptr.To("eastus"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(1)),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export := &fleetnetv1alpha1.InternalServiceExport{Spec: tt.spec}
+			got := generateAzureTrafficManagerEndpoint(profile, backend, export)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("generateAzureTrafficManagerEndpoint() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateAzureTrafficManagerEndpoint(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "backend-uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "service",
+			},
+		},
+	}
+	export := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				objectmeta.ServiceExportAnnotationGeoMapping: " GEO-EU, us ,,US",
+			},
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			PublicIPResourceID: ptr.To("resourceID"),
+			Weight:             ptr.To(int64(10)),
+			Priority:           ptr.To(int64(2)),
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: "cluster-1",
+			},
+		},
+	}
+	tests := []struct {
+		name          string
+		routingMethod fleetnetv1beta1.TrafficManagerRoutingMethod
+		want          armtrafficmanager.Endpoint
+	}{
+		{
+			name:          "weighted routing method",
+			routingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+			want: armtrafficmanager.Endpoint{
+				Name: ptr.To("fleet-backend-uid#service#cluster-1"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(10)),
+				},
+			},
+		},
+		{
+			name:          "geographic routing method",
+			routingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodGeographic,
+			want: armtrafficmanager.Endpoint{
+				Name: ptr.To("fleet-backend-uid#service#cluster-1"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					GeoMapping:       []*string{ptr.To("GEO-EU"), ptr.To("us")},
+				},
+			},
+		},
+		{
+			name:          "priority routing method",
+			routingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodPriority,
+			want: armtrafficmanager.Endpoint{
+				Name: ptr.To("fleet-backend-uid#service#cluster-1"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Priority:         ptr.To(int64(2)),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					RoutingMethod: tt.routingMethod,
+				},
+			}
+			got := generateAzureTrafficManagerEndpoint(profile, backend, export)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("generateAzureTrafficManagerEndpoint() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateAzureTrafficManagerEndpoint_IPFamily(t *testing.T) {
+	export := &fleetnetv1alpha1.InternalServiceExport{
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			PublicIPResourceID: ptr.To("ipv4"),
+			PublicIPAddresses: []fleetnetv1alpha1.PublicIPAddress{
+				{IPFamily: corev1.IPv4Protocol, ResourceID: "ipv4"},
+				{IPFamily: corev1.IPv6Protocol, ResourceID: "ipv6"},
+			},
+			Weight: ptr.To(int64(10)),
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: "cluster-1",
+			},
+		},
+	}
+	tests := []struct {
+		name       string
+		ipFamily   *corev1.IPFamily
+		wantTarget string
+	}{
+		{
+			name:       "IP family is not set",
+			wantTarget: "ipv4",
+		},
+		{
+			name:       "IPv4 family",
+			ipFamily:   ptr.To(corev1.IPv4Protocol),
+			wantTarget: "ipv4",
+		},
+		{
+			name:       "IPv6 family",
+			ipFamily:   ptr.To(corev1.IPv6Protocol),
+			wantTarget: "ipv6",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{UID: "backend-uid"},
+				Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+					Backend:  fleetnetv1beta1.TrafficManagerBackendRef{Name: "service"},
+					IPFamily: tt.ipFamily,
+				},
+			}
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+				},
+			}
+			got := generateAzureTrafficManagerEndpoint(profile, backend, export)
+			if diff := cmp.Diff(tt.wantTarget, ptr.Deref(got.Properties.TargetResourceID, "")); diff != "" {
+				t.Errorf("generateAzureTrafficManagerEndpoint() targetResourceID mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsEndpointOwnedByBackend(t *testing.T) {
+	tests := []struct {
+		name       string
+		namePrefix string // DefaultAzureResourceEndpointNamePrefix is used when it's empty
+		uid        types.UID
+		endpoint   string
+		want       bool
+	}{
+		{
+			name:     "lowercase UID and endpoint name",
+			uid:      "f1e2d3c4-0000-1111-2222-333344445555",
+			endpoint: "fleet-f1e2d3c4-0000-1111-2222-333344445555#test-import#member-1",
+			want:     true,
+		},
+		{
+			name:     "mixed-case UID and lowercase endpoint name",
+			uid:      "F1E2d3c4-0000-1111-2222-333344445555",
+			endpoint: "fleet-f1e2d3c4-0000-1111-2222-333344445555#test-import#member-1",
+			want:     true,
+		},
+		{
+			name:     "lowercase UID and mixed-case endpoint name",
+			uid:      "f1e2d3c4-0000-1111-2222-333344445555",
+			endpoint: "Fleet-F1E2D3C4-0000-1111-2222-333344445555#Test-Import#member-1",
+			want:     true,
+		},
+		{
+			name:     "endpoint of another backend",
+			uid:      "F1E2d3c4-0000-1111-2222-333344445555",
+			endpoint: "fleet-a1e2d3c4-0000-1111-2222-333344445555#test-import#member-1",
+			want:     false,
+		},
+		{
+			name:     "endpoint not created by the fleet",
+			uid:      "f1e2d3c4-0000-1111-2222-333344445555",
+			endpoint: "f1e2d3c4-0000-1111-2222-333344445555#test-import#member-1",
+			want:     false,
+		},
+		{
+			name:       "endpoint created with the custom endpoint name prefix",
+			namePrefix: "staging-",
+			uid:        "f1e2d3c4-0000-1111-2222-333344445555",
+			endpoint:   "staging-f1e2d3c4-0000-1111-2222-333344445555#test-import#member-1",
+			want:       true,
+		},
+		{
+			name:       "endpoint created by the controller with another endpoint name prefix",
+			namePrefix: "staging-",
+			uid:        "f1e2d3c4-0000-1111-2222-333344445555",
+			endpoint:   "fleet-f1e2d3c4-0000-1111-2222-333344445555#test-import#member-1",
+			want:       false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-backend",
+					Namespace: "test-ns",
+					UID:       tc.uid,
+				},
+			}
+			namePrefix := tc.namePrefix
+			if namePrefix == "" {
+				namePrefix = DefaultAzureResourceEndpointNamePrefix
+			}
+			if got := isEndpointOwnedByBackend(namePrefix, backend, tc.endpoint); got != tc.want {
+				t.Errorf("isEndpointOwnedByBackend(%q) = %v, want %v", tc.endpoint, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGenerateAzureTrafficManagerEndpointName(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "f1e2d3c4-0000-1111-2222-333344445555",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{Name: "test-import"},
+		},
+	}
+	wantPrefix := "fleet-f1e2d3c4-0000-1111-2222-333344445555#"
+	if got := GenerateAzureTrafficManagerEndpointNamePrefix(DefaultAzureResourceEndpointNamePrefix, backend); got != wantPrefix {
+		t.Errorf("GenerateAzureTrafficManagerEndpointNamePrefix() = %q, want %q", got, wantPrefix)
+	}
+	want := "fleet-f1e2d3c4-0000-1111-2222-333344445555#test-import#member-1"
+	got := GenerateAzureTrafficManagerEndpointName(DefaultAzureResourceEndpointNamePrefix, backend, "test-import", "member-1")
+	if got != want {
+		t.Errorf("GenerateAzureTrafficManagerEndpointName() = %q, want %q", got, want)
+	}
+	if !isEndpointOwnedByBackend(DefaultAzureResourceEndpointNamePrefix, backend, got) {
+		t.Errorf("isEndpointOwnedByBackend(%q) = false, want true", got)
+	}
+
+	// The controller must generate the same endpoint name as the exported function.
+	profile := &fleetnetv1beta1.TrafficManagerProfile{}
+	export := &fleetnetv1alpha1.InternalServiceExport{
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{ClusterID: "member-1"},
+		},
+	}
+	endpoint := generateAzureTrafficManagerEndpoint(profile, backend, export)
+	if diff := cmp.Diff(want, ptr.Deref(endpoint.Name, "")); diff != "" {
+		t.Errorf("generateAzureTrafficManagerEndpoint() name mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateAzureResourceEndpointNamePrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		namePrefix string
+		wantErr    bool
+	}{
+		{
+			name:       "default prefix",
+			namePrefix: DefaultAzureResourceEndpointNamePrefix,
+		},
+		{
+			name:       "custom prefix",
+			namePrefix: "staging01-",
+		},
+		{
+			name:       "empty prefix",
+			namePrefix: "",
+			wantErr:    true,
+		},
+		{
+			name:       "prefix without the trailing dash",
+			namePrefix: "staging",
+			wantErr:    true,
+		},
+		{
+			name:       "prefix with another dash, which starts with the prefix of another controller",
+			namePrefix: "fleet-staging-",
+			wantErr:    true,
+		},
+		{
+			name:       "prefix with upper case characters",
+			namePrefix: "Staging-",
+			wantErr:    true,
+		},
+		{
+			name:       "prefix with the separator of the endpoint name",
+			namePrefix: "staging#-",
+			wantErr:    true,
+		},
+		{
+			name:       "too long prefix",
+			namePrefix: strings.Repeat("a", 32) + "-",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAzureResourceEndpointNamePrefix(tt.namePrefix)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("ValidateAzureResourceEndpointNamePrefix() got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseAzureTrafficManagerEndpointName(t *testing.T) {
+	tests := []struct {
+		name                  string
+		namePrefix            string // DefaultAzureResourceEndpointNamePrefix is used when it's empty
+		endpoint              string
+		wantBackendUID        string
+		wantServiceImportName string
+		wantClusterID         string
+		wantOK                bool
+	}{
+		{
+			name:                  "fleet managed endpoint",
+			endpoint:              "fleet-f1e2d3c4-0000-1111-2222-333344445555#test-import#member-1",
+			wantBackendUID:        "f1e2d3c4-0000-1111-2222-333344445555",
+			wantServiceImportName: "test-import",
+			wantClusterID:         "member-1",
+			wantOK:                true,
+		},
+		{
+			name:     "endpoint without the fleet prefix",
+			endpoint: "backend-uid#test-import#member-1",
+		},
+		{
+			name:     "nested endpoint",
+			endpoint: "fleet-nested-profile-uid",
+		},
+		{
+			name:     "endpoint with missing cluster",
+			endpoint: "fleet-backend-uid#test-import#",
+		},
+		{
+			name:     "endpoint with too many parts",
+			endpoint: "fleet-backend-uid#test-import#member-1#extra",
+		},
+		{
+			name:                  "endpoint with the custom endpoint name prefix",
+			namePrefix:            "staging-",
+			endpoint:              "staging-f1e2d3c4-0000-1111-2222-333344445555#test-import#member-1",
+			wantBackendUID:        "f1e2d3c4-0000-1111-2222-333344445555",
+			wantServiceImportName: "test-import",
+			wantClusterID:         "member-1",
+			wantOK:                true,
+		},
+		{
+			name:       "endpoint created by the controller with another endpoint name prefix",
+			namePrefix: "staging-",
+			endpoint:   "fleet-f1e2d3c4-0000-1111-2222-333344445555#test-import#member-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namePrefix := tt.namePrefix
+			if namePrefix == "" {
+				namePrefix = DefaultAzureResourceEndpointNamePrefix
+			}
+			gotBackendUID, gotServiceImportName, gotClusterID, gotOK := ParseAzureTrafficManagerEndpointName(namePrefix, tt.endpoint)
+			if gotOK != tt.wantOK {
+				t.Fatalf("ParseAzureTrafficManagerEndpointName() ok = %v, want %v", gotOK, tt.wantOK)
+			}
+			if gotBackendUID != tt.wantBackendUID || gotServiceImportName != tt.wantServiceImportName || gotClusterID != tt.wantClusterID {
+				t.Errorf("ParseAzureTrafficManagerEndpointName() = (%q, %q, %q), want (%q, %q, %q)",
+					gotBackendUID, gotServiceImportName, gotClusterID, tt.wantBackendUID, tt.wantServiceImportName, tt.wantClusterID)
+			}
+		})
+	}
+}
+
+func TestValidateAzureTrafficManagerEndpointName(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		wantErr  bool
+	}{
+		{
+			name:     "valid name",
+			endpoint: "fleet-backend-uid#service#cluster-1",
+		},
+		{
+			name:     "name with the max length",
+			endpoint: "fleet-backend-uid#service#" + strings.Repeat("a", 260-len("fleet-backend-uid#service#")),
+		},
+		{
+			name:     "name is too long",
+			endpoint: "fleet-backend-uid#service#" + strings.Repeat("a", 261-len("fleet-backend-uid#service#")),
+			wantErr:  true,
+		},
+		{
+			name:     "name contains colon",
+			endpoint: "fleet-backend-uid#service#cluster:1",
+			wantErr:  true,
+		},
+		{
+			name:     "name contains backslash",
+			endpoint: `fleet-backend-uid#service#cluster\1`,
+			wantErr:  true,
+		},
+		{
+			name:     "name contains slash",
+			endpoint: "fleet-backend-uid#service#cluster/1",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAzureTrafficManagerEndpointName(tt.endpoint)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("validateAzureTrafficManagerEndpointName() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateAzureTrafficManagerEndpoint_Disabled(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "backend-uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "service",
+			},
+		},
+	}
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        armtrafficmanager.EndpointStatus
+	}{
+		{
+			name: "no annotation",
+			want: armtrafficmanager.EndpointStatusEnabled,
+		},
+		{
+			name: "endpoint is disabled",
+			annotations: map[string]string{
+				objectmeta.InternalServiceExportAnnotationEndpointDisabled: "true",
+			},
+			want: armtrafficmanager.EndpointStatusDisabled,
+		},
+		{
+			name: "endpoint is explicitly enabled",
+			annotations: map[string]string{
+				objectmeta.InternalServiceExportAnnotationEndpointDisabled: "false",
+			},
+			want: armtrafficmanager.EndpointStatusEnabled,
+		},
+		{
+			name: "invalid annotation value",
+			annotations: map[string]string{
+				objectmeta.InternalServiceExportAnnotationEndpointDisabled: "yes",
+			},
+			want: armtrafficmanager.EndpointStatusEnabled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export := &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tt.annotations,
+				},
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					PublicIPResourceID: ptr.To("resourceID"),
+					ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID: "cluster-1",
+					},
+				},
+			}
+			got := generateAzureTrafficManagerEndpoint(profile, backend, export)
+			if *got.Properties.EndpointStatus != tt.want {
+				t.Errorf("generateAzureTrafficManagerEndpoint() endpointStatus = %v, want %v", *got.Properties.EndpointStatus, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateAzureTrafficManagerEndpoint_AlwaysServe(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "backend-uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "service",
+			},
+		},
+	}
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *armtrafficmanager.AlwaysServe
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name: "always serve is enabled",
+			annotations: map[string]string{
+				objectmeta.ServiceExportAnnotationAlwaysServe: "true",
+			},
+			want: ptr.To(armtrafficmanager.AlwaysServeEnabled),
+		},
+		{
+			name: "always serve is explicitly disabled",
+			annotations: map[string]string{
+				objectmeta.ServiceExportAnnotationAlwaysServe: "false",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export := &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tt.annotations,
+				},
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					PublicIPResourceID: ptr.To("resourceID"),
+					ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID: "cluster-1",
+					},
+				},
+			}
+			got := generateAzureTrafficManagerEndpoint(profile, backend, export)
+			if diff := cmp.Diff(tt.want, got.Properties.AlwaysServe); diff != "" {
+				t.Errorf("generateAzureTrafficManagerEndpoint() alwaysServe mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateAzureTrafficManagerEndpoint_Performance(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "backend-uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "service",
+			},
+		},
+	}
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodPerformance,
+		},
+	}
+	tests := []struct {
+		name string
+		spec fleetnetv1alpha1.InternalServiceExportSpec
+		want armtrafficmanager.Endpoint
+	}{
+		{
+			name: "azure endpoint located by the cluster region",
+			spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				Type:               corev1.ServiceTypeLoadBalancer,
+				PublicIPResourceID: ptr.To("resourceID"),
+				Weight:             ptr.To(int64(10)),
+				Region:             ptr.To("westus"),
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+					ClusterID: "cluster-1",
+				},
+			},
+			want: armtrafficmanager.Endpoint{
+				Name: ptr.To("fleet-backend-uid#service#cluster-1"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointLocation: ptr.To("westus"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+				},
+			},
+		},
+		{
+			name: "azure endpoint without the cluster region",
+			spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				Type:               corev1.ServiceTypeLoadBalancer,
+				PublicIPResourceID: ptr.To("resourceID"),
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+					ClusterID: "cluster-1",
+				},
+			},
+			want: armtrafficmanager.Endpoint{
+				Name: ptr.To("fleet-backend-uid#service#cluster-1"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+				},
+			},
+		},
+		{
+			name: "external endpoint located by the external target location",
+			spec: fleetnetv1alpha1.InternalServiceExportSpec{
+				Type:                   corev1.ServiceTypeLoadBalancer,
+				ExternalTargetIP:       ptr.To("20.1.2.3"),
+				ExternalTargetLocation: ptr.To("eastus"),
+				Region:                 ptr.To("westus"),
+				ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+					ClusterID: "cluster-1",
+				},
+			},
+			want: armtrafficmanager.Endpoint{
+				Name: ptr.To("fleet-backend-uid#service#cluster-1"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/ExternalEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:           ptr.To("20.1.2.3"),
+					EndpointLocation: ptr.To("eastus"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export := &fleetnetv1alpha1.InternalServiceExport{Spec: tt.spec}
+			got := generateAzureTrafficManagerEndpoint(profile, backend, export)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("generateAzureTrafficManagerEndpoint() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestEqualAzureTrafficManagerEndpoint_RegionMoved covers the member cluster recreated in another region, whose public
+// IP address may keep the same resource ID, so that only the endpoint location tells the endpoint needs to be updated.
+func TestEqualAzureTrafficManagerEndpoint_RegionMoved(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "backend-uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "service",
+			},
+		},
+	}
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodPerformance,
+		},
+	}
+	export := &fleetnetv1alpha1.InternalServiceExport{
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			Type:               corev1.ServiceTypeLoadBalancer,
+			PublicIPResourceID: ptr.To("resourceID"),
+			Region:             ptr.To("westus"),
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: "cluster-1",
+			},
+		},
+	}
+	desired := generateAzureTrafficManagerEndpoint(profile, backend, export)
+	tests := []struct {
+		name            string
+		currentLocation *string
+		want            bool
+	}{
+		{
+			name:            "same region returned as the display name",
+			currentLocation: ptr.To("West US"),
+			want:            true,
+		},
+		{
+			name:            "region moved",
+			currentLocation: ptr.To("East US"),
+		},
+		{
+			name: "current location is nil",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := armtrafficmanager.Endpoint{
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointLocation: tt.currentLocation,
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+				},
+			}
+			if got := equalAzureTrafficManagerEndpoint(current, desired); got != tt.want {
+				t.Errorf("equalAzureTrafficManagerEndpoint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateAzureTrafficManagerEndpoint_Subnet(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "backend-uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "service",
+			},
+		},
+	}
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodSubnet,
+		},
+	}
+	export := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				objectmeta.ServiceExportAnnotationSubnets: "10.0.0.0/24,2001:db8::/32",
+			},
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			Type:               corev1.ServiceTypeLoadBalancer,
+			PublicIPResourceID: ptr.To("resourceID"),
+			Weight:             ptr.To(int64(10)),
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: "cluster-1",
+			},
+		},
+	}
+	want := armtrafficmanager.Endpoint{
+		Name: ptr.To("fleet-backend-uid#service#cluster-1"),
+		Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+		Properties: &armtrafficmanager.EndpointProperties{
+			TargetResourceID: ptr.To("resourceID"),
+			EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+			Subnets: []*armtrafficmanager.EndpointPropertiesSubnetsItem{
+				{First: ptr.To("10.0.0.0"), Scope: ptr.To(int32(24))},
+				{First: ptr.To("2001:db8::"), Scope: ptr.To(int32(32))},
+			},
+		},
+	}
+	got := generateAzureTrafficManagerEndpoint(profile, backend, export)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("generateAzureTrafficManagerEndpoint() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestIsEndpointMonitoringEnabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		atmProfile *armtrafficmanager.Profile
+		want       bool
+	}{
+		{
+			name:       "no monitor config",
+			atmProfile: &armtrafficmanager.Profile{Properties: &armtrafficmanager.ProfileProperties{}},
+		},
+		{
+			name: "monitor status is not reported yet",
+			atmProfile: &armtrafficmanager.Profile{Properties: &armtrafficmanager.ProfileProperties{
+				MonitorConfig: &armtrafficmanager.MonitorConfig{Protocol: ptr.To(armtrafficmanager.MonitorProtocolHTTP)},
+			}},
+			want: true,
+		},
+		{
+			name: "monitor status is online",
+			atmProfile: &armtrafficmanager.Profile{Properties: &armtrafficmanager.ProfileProperties{
+				MonitorConfig: &armtrafficmanager.MonitorConfig{ProfileMonitorStatus: ptr.To(armtrafficmanager.ProfileMonitorStatusOnline)},
+			}},
+			want: true,
+		},
+		{
+			name: "monitoring is disabled",
+			atmProfile: &armtrafficmanager.Profile{Properties: &armtrafficmanager.ProfileProperties{
+				MonitorConfig: &armtrafficmanager.MonitorConfig{ProfileMonitorStatus: ptr.To(armtrafficmanager.ProfileMonitorStatusDisabled)},
+			}},
+		},
+		{
+			name: "monitoring is inactive",
+			atmProfile: &armtrafficmanager.Profile{Properties: &armtrafficmanager.ProfileProperties{
+				MonitorConfig: &armtrafficmanager.MonitorConfig{ProfileMonitorStatus: ptr.To(armtrafficmanager.ProfileMonitorStatusInactive)},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isEndpointMonitoringEnabled(tt.atmProfile); got != tt.want {
+				t.Errorf("isEndpointMonitoringEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidateOverlappingGeoMappings(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "uid",
+		},
+	}
+	tests := []struct {
+		name                string
+		atmProfile          *armtrafficmanager.Profile
+		desiredEndpoints    map[string]desiredEndpoint
+		wantEndpointNames   []string
+		wantInvalidServices map[string]string // key is the cluster name and value is the error message
+	}{
+		{
+			name:       "no overlapping regions",
+			atmProfile: &armtrafficmanager.Profile{},
+			desiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#service#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-1"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							GeoMapping: []*string{ptr.To("US")},
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+				"fleet-uid#service#cluster-2": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-2"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							GeoMapping: []*string{ptr.To("GEO-EU"), ptr.To("CA")},
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-2"},
+					},
+				},
+			},
+			wantEndpointNames: []string{"fleet-uid#service#cluster-1", "fleet-uid#service#cluster-2"},
+		},
+		{
+			name:       "overlapping regions with different case",
+			atmProfile: &armtrafficmanager.Profile{},
+			desiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#service#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-1"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							GeoMapping: []*string{ptr.To("US")},
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+				"fleet-uid#service#cluster-2": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-2"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							GeoMapping: []*string{ptr.To("GEO-EU"), ptr.To("us")},
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-2"},
+					},
+				},
+				"fleet-uid#service#cluster-3": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-3"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							GeoMapping: []*string{ptr.To("GEO-EU")},
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-3"},
+					},
+				},
+			},
+			wantEndpointNames: []string{"fleet-uid#service#cluster-3", "fleet-uid#service#cluster-1"},
+			wantInvalidServices: map[string]string{
+				"cluster-2": `geographic region "us" overlaps with the service exported from cluster "cluster-1"`,
+			},
+		},
+		{
+			name: "overlapping regions with the endpoints of other backends",
+			atmProfile: &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					Endpoints: []*armtrafficmanager.Endpoint{
+						{
+							Name: ptr.To("fleet-other-uid#other-service#cluster-1"),
+							Properties: &armtrafficmanager.EndpointProperties{
+								GeoMapping: []*string{ptr.To("GEO-EU")},
+							},
+						},
+						{
+							Name: ptr.To("fleet-uid#service#cluster-1"), // owned by the backend and will be replaced
+							Properties: &armtrafficmanager.EndpointProperties{
+								GeoMapping: []*string{ptr.To("US")},
+							},
+						},
+					},
+				},
+			},
+			desiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#service#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-1"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							GeoMapping: []*string{ptr.To("US")},
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+				"fleet-uid#service#cluster-2": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-2"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							GeoMapping: []*string{ptr.To("CA"), ptr.To("geo-eu")},
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-2"},
+					},
+				},
+			},
+			wantEndpointNames: []string{"fleet-uid#service#cluster-1"},
+			wantInvalidServices: map[string]string{
+				"cluster-2": `geographic region "geo-eu" overlaps with the existing Azure Traffic Manager endpoint "fleet-other-uid#other-service#cluster-1"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalidServices := make(map[string]error)
+			invalidateOverlappingGeoMappings(DefaultAzureResourceEndpointNamePrefix, backend, tt.atmProfile, tt.desiredEndpoints, invalidServices)
+			gotEndpointNames := make([]string, 0, len(tt.desiredEndpoints))
+			for name := range tt.desiredEndpoints {
+				gotEndpointNames = append(gotEndpointNames, name)
+			}
+			if diff := cmp.Diff(tt.wantEndpointNames, gotEndpointNames, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("invalidateOverlappingGeoMappings() desiredEndpoints mismatch (-want +got):\n%s", diff)
+			}
+			gotInvalidServices := make(map[string]string, len(invalidServices))
+			for cluster, err := range invalidServices {
+				gotInvalidServices[cluster] = err.Error()
+			}
+			if diff := cmp.Diff(tt.wantInvalidServices, gotInvalidServices, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("invalidateOverlappingGeoMappings() invalidServices mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func geographicInternalServiceExportForTest(cluster, geoMapping string) *fleetnetv1alpha1.InternalServiceExport {
+	export := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ns-test-import",
+			Namespace: cluster + "-ns",
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			Ports: []fleetnetv1alpha1.ServicePort{
+				{
+					Protocol: corev1.ProtocolTCP,
+					Port:     80,
+				},
+			},
+			Type:                 corev1.ServiceTypeLoadBalancer,
+			PublicIPResourceID:   ptr.To(cluster + "-ip"),
+			IsDNSLabelConfigured: true,
+			Weight:               ptr.To(int64(100)),
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID:      cluster,
+				Kind:           "Service",
+				Namespace:      "test-ns",
+				Name:           "test-import",
+				NamespacedName: "test-ns/test-import",
+			},
+		},
+	}
+	if geoMapping != "" {
+		export.Annotations = map[string]string{
+			objectmeta.ServiceExportAnnotationGeoMapping: geoMapping,
+		}
+	}
+	return export
+}
+
+func TestValidateAndProcessServiceImportForBackend_Geographic(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodGeographic,
+		},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(500)),
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+			},
+		},
+	}
+	tests := []struct {
+		name                 string
+		atmProfile           *armtrafficmanager.Profile
+		exports              []client.Object
+		wantDesiredEndpoints map[string]desiredEndpoint
+		wantInvalidServices  map[string]string // key is the cluster name and value is the error message
+	}{
+		{
+			name:       "missing geo mapping annotation",
+			atmProfile: &armtrafficmanager.Profile{},
+			exports: []client.Object{
+				geographicInternalServiceExportForTest("cluster-1", "US,CA"),
+				geographicInternalServiceExportForTest("cluster-2", ""),
+			},
+			wantDesiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#test-import#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-1"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							TargetResourceID: ptr.To("cluster-1-ip"),
+							EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+							GeoMapping:       []*string{ptr.To("US"), ptr.To("CA")},
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+			},
+			wantInvalidServices: map[string]string{
+				"cluster-2": `geographic mapping is not configured by the "networking.fleet.azure.com/geo-mapping" annotation`,
+			},
+		},
+		{
+			name:       "skipping weight proportioning",
+			atmProfile: &armtrafficmanager.Profile{},
+			exports: []client.Object{
+				geographicInternalServiceExportForTest("cluster-1", "US"),
+				geographicInternalServiceExportForTest("cluster-2", "GEO-EU"),
+			},
+			wantDesiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#test-import#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-1"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							TargetResourceID: ptr.To("cluster-1-ip"),
+							EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+							GeoMapping:       []*string{ptr.To("US")},
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+				"fleet-uid#test-import#cluster-2": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-2"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							TargetResourceID: ptr.To("cluster-2-ip"),
+							EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+							GeoMapping:       []*string{ptr.To("GEO-EU")},
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-2"},
+					},
+				},
+			},
+		},
+		{
+			name: "overlapping geo mappings",
+			atmProfile: &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					Endpoints: []*armtrafficmanager.Endpoint{
+						{
+							Name: ptr.To("fleet-other-uid#other-service#cluster-3"),
+							Properties: &armtrafficmanager.EndpointProperties{
+								GeoMapping: []*string{ptr.To("GEO-AS")},
+							},
+						},
+					},
+				},
+			},
+			exports: []client.Object{
+				geographicInternalServiceExportForTest("cluster-1", "US"),
+				geographicInternalServiceExportForTest("cluster-2", "geo-as"),
+			},
+			wantDesiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#test-import#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-1"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							TargetResourceID: ptr.To("cluster-1-ip"),
+							EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+							GeoMapping:       []*string{ptr.To("US")},
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+			},
+			wantInvalidServices: map[string]string{
+				"cluster-2": `geographic region "geo-as" overlaps with the existing Azure Traffic Manager endpoint "fleet-other-uid#other-service#cluster-3"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tt.exports...).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			gotDesiredEndpoints, gotInvalidServicesErr, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, tt.atmProfile, backend, serviceImport)
+			if err != nil {
+				t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+			}
+			if diff := cmp.Diff(tt.wantDesiredEndpoints, gotDesiredEndpoints, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() desiredEndpoints mismatch (-want +got):\n%s", diff)
+			}
+			gotInvalidServices := make(map[string]string, len(gotInvalidServicesErr))
+			for cluster, err := range gotInvalidServicesErr {
+				gotInvalidServices[cluster] = err.Error()
+			}
+			if diff := cmp.Diff(tt.wantInvalidServices, gotInvalidServices, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() invalidServices mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExtractSubnets(t *testing.T) {
+	tests := []struct {
+		name           string
+		clusterSubnets []fleetnetv1beta1.ClusterSubnets
+		annotation     string
+		want           []netip.Prefix
+		wantErr        bool
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:       "configured by the annotation",
+			annotation: "10.0.0.0/24, 10.1.0.0/16",
+			want:       []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24"), netip.MustParsePrefix("10.1.0.0/16")},
+		},
+		{
+			name: "clusterSubnets take precedence over the annotation",
+			clusterSubnets: []fleetnetv1beta1.ClusterSubnets{
+				{Cluster: "cluster-2", Subnets: []string{"10.2.0.0/16"}},
+				{Cluster: "cluster-1", Subnets: []string{"2001:db8::/32"}},
+			},
+			annotation: "10.0.0.0/24",
+			want:       []netip.Prefix{netip.MustParsePrefix("2001:db8::/32")},
+		},
+		{
+			name: "clusterSubnets of other clusters",
+			clusterSubnets: []fleetnetv1beta1.ClusterSubnets{
+				{Cluster: "cluster-2", Subnets: []string{"10.2.0.0/16"}},
+			},
+			annotation: "10.0.0.0/24",
+			want:       []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")},
+		},
+		{
+			name:       "invalid annotation",
+			annotation: "10.0.0.1/24",
+			wantErr:    true,
+		},
+		{
+			name: "invalid clusterSubnets",
+			clusterSubnets: []fleetnetv1beta1.ClusterSubnets{
+				{Cluster: "cluster-1", Subnets: []string{"invalid"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+					Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+						Name:           "test-import",
+						ClusterSubnets: tt.clusterSubnets,
+					},
+				},
+			}
+			export := subnetInternalServiceExportForTest("cluster-1", tt.annotation)
+			got, err := extractSubnets(backend, export)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("extractSubnets() got error %v, want error %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got, cmpopts.EquateEmpty(), cmpopts.EquateComparable(netip.Prefix{})); diff != "" {
+				t.Errorf("extractSubnets() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInvalidateOverlappingSubnets(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "uid",
+		},
+	}
+	endpointForTest := func(cluster string, subnets ...string) desiredEndpoint {
+		var items []*armtrafficmanager.EndpointPropertiesSubnetsItem
+		for _, subnet := range subnets {
+			items = append(items, buildAzureTrafficManagerEndpointSubnets([]netip.Prefix{netip.MustParsePrefix(subnet)})...)
+		}
+		return desiredEndpoint{
+			Endpoint: armtrafficmanager.Endpoint{
+				Name: ptr.To("fleet-uid#service#" + cluster),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Subnets: items,
+				},
+			},
+			FromCluster: fleetnetv1beta1.FromCluster{
+				ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: cluster},
+			},
+		}
+	}
+	tests := []struct {
+		name                 string
+		atmProfile           *armtrafficmanager.Profile
+		desiredEndpoints     map[string]desiredEndpoint
+		wantDesiredEndpoints []string
+		wantInvalidServices  map[string]string // key is the cluster name and value is the error message
+	}{
+		{
+			name:       "no overlapping subnets",
+			atmProfile: &armtrafficmanager.Profile{},
+			desiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#service#cluster-1": endpointForTest("cluster-1", "10.0.0.0/16", "2001:db8::/32"),
+				"fleet-uid#service#cluster-2": endpointForTest("cluster-2", "10.1.0.0/16"),
+			},
+			wantDesiredEndpoints: []string{"fleet-uid#service#cluster-1", "fleet-uid#service#cluster-2"},
+		},
+		{
+			name:       "overlapping subnets of the desired endpoints",
+			atmProfile: &armtrafficmanager.Profile{},
+			desiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#service#cluster-1": endpointForTest("cluster-1", "10.0.0.0/8"),
+				"fleet-uid#service#cluster-2": endpointForTest("cluster-2", "192.168.0.0/16", "10.1.0.0/16"),
+			},
+			wantDesiredEndpoints: []string{"fleet-uid#service#cluster-1"},
+			wantInvalidServices: map[string]string{
+				"cluster-2": `subnet "10.1.0.0/16" overlaps with the service exported from cluster "cluster-1"`,
+			},
+		},
+		{
+			name: "overlapping subnets of the existing endpoints owned by other backends",
+			atmProfile: &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					Endpoints: []*armtrafficmanager.Endpoint{
+						{
+							Name: ptr.To("fleet-other-uid#other-service#cluster-3"),
+							Properties: &armtrafficmanager.EndpointProperties{
+								Subnets: []*armtrafficmanager.EndpointPropertiesSubnetsItem{
+									{First: ptr.To("10.0.0.100"), Last: ptr.To("10.0.0.200")},
+								},
+							},
+						},
+						{
+							// replaced by the desired endpoint
+							Name: ptr.To("fleet-uid#service#cluster-2"),
+							Properties: &armtrafficmanager.EndpointProperties{
+								Subnets: []*armtrafficmanager.EndpointPropertiesSubnetsItem{
+									{First: ptr.To("10.1.0.0"), Scope: ptr.To(int32(16))},
+								},
+							},
+						},
+					},
+				},
+			},
+			desiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#service#cluster-1": endpointForTest("cluster-1", "10.0.0.0/24"),
+				"fleet-uid#service#cluster-2": endpointForTest("cluster-2", "10.1.0.0/16"),
+			},
+			wantDesiredEndpoints: []string{"fleet-uid#service#cluster-2"},
+			wantInvalidServices: map[string]string{
+				"cluster-1": `subnet "10.0.0.0/24" overlaps with the existing Azure Traffic Manager endpoint "fleet-other-uid#other-service#cluster-3"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalidServices := make(map[string]error)
+			invalidateOverlappingSubnets(DefaultAzureResourceEndpointNamePrefix, backend, tt.atmProfile, tt.desiredEndpoints, invalidServices)
+			gotDesiredEndpoints := make([]string, 0, len(tt.desiredEndpoints))
+			for name := range tt.desiredEndpoints {
+				gotDesiredEndpoints = append(gotDesiredEndpoints, name)
+			}
+			if diff := cmp.Diff(tt.wantDesiredEndpoints, gotDesiredEndpoints, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("invalidateOverlappingSubnets() desiredEndpoints mismatch (-want +got):\n%s", diff)
+			}
+			gotInvalidServices := make(map[string]string, len(invalidServices))
+			for cluster, err := range invalidServices {
+				gotInvalidServices[cluster] = err.Error()
+			}
+			if diff := cmp.Diff(tt.wantInvalidServices, gotInvalidServices, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("invalidateOverlappingSubnets() invalidServices mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func subnetInternalServiceExportForTest(cluster, subnets string) *fleetnetv1alpha1.InternalServiceExport {
+	export := geographicInternalServiceExportForTest(cluster, "")
+	if subnets != "" {
+		export.Annotations = map[string]string{
+			objectmeta.ServiceExportAnnotationSubnets: subnets,
+		}
+	}
+	return export
+}
+
+func TestInvalidateDuplicatePriorities(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "uid",
+		},
+	}
+	tests := []struct {
+		name                string
+		atmProfile          *armtrafficmanager.Profile
+		desiredEndpoints    map[string]desiredEndpoint
+		wantEndpointNames   []string
+		wantInvalidServices map[string]string // key is the cluster name and value is the error message
+	}{
+		{
+			name:       "unique priorities",
+			atmProfile: &armtrafficmanager.Profile{},
+			desiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#service#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-1"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							Priority: ptr.To(int64(1)),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+				"fleet-uid#service#cluster-2": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-2"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							Priority: ptr.To(int64(2)),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-2"},
+					},
+				},
+			},
+			wantEndpointNames: []string{"fleet-uid#service#cluster-1", "fleet-uid#service#cluster-2"},
+		},
+		{
+			name:       "duplicate priorities within the backend",
+			atmProfile: &armtrafficmanager.Profile{},
+			desiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#service#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-1"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							Priority: ptr.To(int64(1)),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+				"fleet-uid#service#cluster-2": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-2"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							Priority: ptr.To(int64(1)),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-2"},
+					},
+				},
+			},
+			wantEndpointNames: []string{"fleet-uid#service#cluster-1"},
+			wantInvalidServices: map[string]string{
+				"cluster-2": `priority 1 is already used by the service exported from cluster "cluster-1"`,
+			},
+		},
+		{
+			name: "duplicate priorities with the endpoints of other backends",
+			atmProfile: &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					Endpoints: []*armtrafficmanager.Endpoint{
+						{
+							Name: ptr.To("fleet-other-uid#other-service#cluster-1"),
+							Properties: &armtrafficmanager.EndpointProperties{
+								Priority: ptr.To(int64(2)),
+							},
+						},
+						{
+							Name: ptr.To("fleet-uid#service#cluster-1"), // owned by the backend and will be replaced
+							Properties: &armtrafficmanager.EndpointProperties{
+								Priority: ptr.To(int64(1)),
+							},
+						},
+					},
+				},
+			},
+			desiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#service#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-1"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							Priority: ptr.To(int64(1)),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+				"fleet-uid#service#cluster-2": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#service#cluster-2"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							Priority: ptr.To(int64(2)),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-2"},
+					},
+				},
+			},
+			wantEndpointNames: []string{"fleet-uid#service#cluster-1"},
+			wantInvalidServices: map[string]string{
+				"cluster-2": `priority 2 is already used by the existing Azure Traffic Manager endpoint "fleet-other-uid#other-service#cluster-1"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalidServices := make(map[string]error)
+			invalidateDuplicatePriorities(DefaultAzureResourceEndpointNamePrefix, backend, tt.atmProfile, tt.desiredEndpoints, invalidServices)
+			gotEndpointNames := make([]string, 0, len(tt.desiredEndpoints))
+			for name := range tt.desiredEndpoints {
+				gotEndpointNames = append(gotEndpointNames, name)
+			}
+			if diff := cmp.Diff(tt.wantEndpointNames, gotEndpointNames, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("invalidateDuplicatePriorities() desiredEndpoints mismatch (-want +got):\n%s", diff)
+			}
+			gotInvalidServices := make(map[string]string, len(invalidServices))
+			for cluster, err := range invalidServices {
+				gotInvalidServices[cluster] = err.Error()
+			}
+			if diff := cmp.Diff(tt.wantInvalidServices, gotInvalidServices, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("invalidateDuplicatePriorities() invalidServices mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLimitDesiredEndpoints(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: "uid",
+		},
+	}
+	newDesiredEndpoints := func(weights map[string]int64) map[string]desiredEndpoint {
+		desiredEndpoints := make(map[string]desiredEndpoint, len(weights))
+		for cluster, weight := range weights {
+			name := "fleet-uid#service#" + cluster
+			desiredEndpoints[name] = desiredEndpoint{
+				Endpoint: armtrafficmanager.Endpoint{
+					Name: ptr.To(name),
+					Properties: &armtrafficmanager.EndpointProperties{
+						Weight: ptr.To(weight),
+					},
+				},
+				FromCluster: fleetnetv1beta1.FromCluster{
+					ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: cluster},
+				},
+			}
+		}
+		return desiredEndpoints
+	}
+	tests := []struct {
+		name                string
+		atmProfile          *armtrafficmanager.Profile
+		desiredEndpoints    map[string]desiredEndpoint
+		maxEndpoints        int
+		wantEndpointNames   []string
+		wantDroppedClusters []string
+	}{
+		{
+			name:              "within the limit",
+			atmProfile:        &armtrafficmanager.Profile{},
+			desiredEndpoints:  newDesiredEndpoints(map[string]int64{"cluster-1": 1, "cluster-2": 2}),
+			maxEndpoints:      2,
+			wantEndpointNames: []string{"fleet-uid#service#cluster-1", "fleet-uid#service#cluster-2"},
+		},
+		{
+			name:                "exceeding the limit drops the endpoints with lower weights",
+			atmProfile:          &armtrafficmanager.Profile{},
+			desiredEndpoints:    newDesiredEndpoints(map[string]int64{"cluster-1": 1, "cluster-2": 3, "cluster-3": 2, "cluster-4": 1}),
+			maxEndpoints:        2,
+			wantEndpointNames:   []string{"fleet-uid#service#cluster-2", "fleet-uid#service#cluster-3"},
+			wantDroppedClusters: []string{"cluster-1", "cluster-4"},
+		},
+		{
+			name:                "ties are broken by the cluster name",
+			atmProfile:          &armtrafficmanager.Profile{},
+			desiredEndpoints:    newDesiredEndpoints(map[string]int64{"cluster-3": 1, "cluster-1": 1, "cluster-2": 1}),
+			maxEndpoints:        2,
+			wantEndpointNames:   []string{"fleet-uid#service#cluster-1", "fleet-uid#service#cluster-2"},
+			wantDroppedClusters: []string{"cluster-3"},
+		},
+		{
+			name: "endpoints of other backends count towards the limit",
+			atmProfile: &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					Endpoints: []*armtrafficmanager.Endpoint{
+						{
+							Name: ptr.To("fleet-other-uid#other-service#cluster-1"),
+						},
+						{
+							Name: ptr.To("fleet-uid#service#cluster-1"), // owned by the backend and will be replaced
+						},
+					},
+				},
+			},
+			desiredEndpoints:    newDesiredEndpoints(map[string]int64{"cluster-1": 1, "cluster-2": 2}),
+			maxEndpoints:        2,
+			wantEndpointNames:   []string{"fleet-uid#service#cluster-2"},
+			wantDroppedClusters: []string{"cluster-1"},
+		},
+		{
+			name: "profile is already full",
+			atmProfile: &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					Endpoints: []*armtrafficmanager.Endpoint{
+						{
+							Name: ptr.To("fleet-other-uid#other-service#cluster-1"),
+						},
+						{
+							Name: ptr.To("fleet-other-uid#other-service#cluster-2"),
+						},
+					},
+				},
+			},
+			desiredEndpoints:    newDesiredEndpoints(map[string]int64{"cluster-1": 1}),
+			maxEndpoints:        1,
+			wantEndpointNames:   []string{},
+			wantDroppedClusters: []string{"cluster-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDroppedClusters := limitDesiredEndpoints(DefaultAzureResourceEndpointNamePrefix, backend, tt.atmProfile, tt.desiredEndpoints, tt.maxEndpoints)
+			if diff := cmp.Diff(tt.wantDroppedClusters, gotDroppedClusters); diff != "" {
+				t.Errorf("limitDesiredEndpoints() mismatch (-want +got):\n%s", diff)
+			}
+			gotEndpointNames := make([]string, 0, len(tt.desiredEndpoints))
+			for name := range tt.desiredEndpoints {
+				gotEndpointNames = append(gotEndpointNames, name)
+			}
+			if diff := cmp.Diff(tt.wantEndpointNames, gotEndpointNames, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("limitDesiredEndpoints() desiredEndpoints mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_Priority(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodPriority,
+		},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(500)),
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+			},
+		},
+	}
+	priorityExport := func(cluster string, priority *int64) client.Object {
+		export := geographicInternalServiceExportForTest(cluster, "")
+		export.Spec.Priority = priority
+		return export
+	}
+	tests := []struct {
+		name                 string
+		exports              []client.Object
+		wantDesiredEndpoints map[string]desiredEndpoint
+		wantInvalidServices  map[string]string // key is the cluster name and value is the error message
+	}{
+		{
+			name: "missing priority",
+			exports: []client.Object{
+				priorityExport("cluster-1", ptr.To(int64(1))),
+				priorityExport("cluster-2", nil),
+			},
+			wantDesiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#test-import#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-1"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							TargetResourceID: ptr.To("cluster-1-ip"),
+							EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+							Priority:         ptr.To(int64(1)),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+			},
+			wantInvalidServices: map[string]string{
+				"cluster-2": `priority is not configured by the "networking.fleet.azure.com/priority" annotation`,
+			},
+		},
+		{
+			name: "skipping weight proportioning",
+			exports: []client.Object{
+				priorityExport("cluster-1", ptr.To(int64(1))),
+				priorityExport("cluster-2", ptr.To(int64(2))),
+			},
+			wantDesiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#test-import#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-1"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							TargetResourceID: ptr.To("cluster-1-ip"),
+							EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+							Priority:         ptr.To(int64(1)),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+				"fleet-uid#test-import#cluster-2": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-2"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							TargetResourceID: ptr.To("cluster-2-ip"),
+							EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+							Priority:         ptr.To(int64(2)),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-2"},
+					},
+				},
+			},
+		},
+		{
+			name: "duplicate priorities",
+			exports: []client.Object{
+				priorityExport("cluster-1", ptr.To(int64(1))),
+				priorityExport("cluster-2", ptr.To(int64(1))),
+			},
+			wantDesiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#test-import#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-1"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							TargetResourceID: ptr.To("cluster-1-ip"),
+							EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+							Priority:         ptr.To(int64(1)),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+			},
+			wantInvalidServices: map[string]string{
+				"cluster-2": `priority 1 is already used by the service exported from cluster "cluster-1"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tt.exports...).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			gotDesiredEndpoints, gotInvalidServicesErr, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+			if err != nil {
+				t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+			}
+			if diff := cmp.Diff(tt.wantDesiredEndpoints, gotDesiredEndpoints, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() desiredEndpoints mismatch (-want +got):\n%s", diff)
+			}
+			gotInvalidServices := make(map[string]string, len(gotInvalidServicesErr))
+			for cluster, err := range gotInvalidServicesErr {
+				gotInvalidServices[cluster] = err.Error()
+			}
+			if diff := cmp.Diff(tt.wantInvalidServices, gotInvalidServices, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() invalidServices mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_Performance(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodPerformance,
+		},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(500)),
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+			},
+		},
+	}
+	performanceExport := func(cluster string, region *string) *fleetnetv1alpha1.InternalServiceExport {
+		export := geographicInternalServiceExportForTest(cluster, "")
+		export.Spec.Region = region
+		return export
+	}
+	externalExport := performanceExport("cluster-2", ptr.To("westus"))
+	externalExport.Spec.PublicIPResourceID = nil
+	externalExport.Spec.ExternalTargetIP = ptr.To("20.1.2.3")
+	tests := []struct {
+		name                 string
+		exports              []client.Object
+		wantDesiredEndpoints map[string]desiredEndpoint
+		wantInvalidServices  map[string]string // key is the cluster name and value is the error message
+	}{
+		{
+			name: "missing region and skipping weight proportioning",
+			exports: []client.Object{
+				performanceExport("cluster-1", ptr.To("eastus")),
+				performanceExport("cluster-2", nil),
+			},
+			wantDesiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#test-import#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-1"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							TargetResourceID: ptr.To("cluster-1-ip"),
+							EndpointLocation: ptr.To("eastus"),
+							EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+			},
+			wantInvalidServices: map[string]string{
+				"cluster-2": "the Azure region of the cluster is not reported by the member cluster",
+			},
+		},
+		{
+			name: "missing external target location",
+			exports: []client.Object{
+				performanceExport("cluster-1", ptr.To("eastus")),
+				externalExport,
+			},
+			wantDesiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#test-import#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-1"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							TargetResourceID: ptr.To("cluster-1-ip"),
+							EndpointLocation: ptr.To("eastus"),
+							EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+			},
+			wantInvalidServices: map[string]string{
+				"cluster-2": `location is not configured by the "networking.fleet.azure.com/external-target-location" annotation`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tt.exports...).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			gotDesiredEndpoints, gotInvalidServicesErr, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+			if err != nil {
+				t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+			}
+			if diff := cmp.Diff(tt.wantDesiredEndpoints, gotDesiredEndpoints, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() desiredEndpoints mismatch (-want +got):\n%s", diff)
+			}
+			gotInvalidServices := make(map[string]string, len(gotInvalidServicesErr))
+			for cluster, err := range gotInvalidServicesErr {
+				gotInvalidServices[cluster] = err.Error()
+			}
+			if diff := cmp.Diff(tt.wantInvalidServices, gotInvalidServices, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() invalidServices mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_MultiValue(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodMultiValue,
+			MaxReturn:     ptr.To(int64(2)),
+		},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(500)),
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+			},
+		},
+	}
+	externalIPExport := func(cluster, ip string) *fleetnetv1alpha1.InternalServiceExport {
+		export := geographicInternalServiceExportForTest(cluster, "")
+		export.Spec.PublicIPResourceID = nil
+		export.Spec.ExternalTargetIP = ptr.To(ip)
+		return export
+	}
+	externalFQDNExport := geographicInternalServiceExportForTest("cluster-2", "")
+	externalFQDNExport.Spec.PublicIPResourceID = nil
+	externalFQDNExport.Spec.ExternalTargetFQDN = ptr.To("app.example.com")
+	tests := []struct {
+		name                 string
+		exports              []client.Object
+		wantDesiredEndpoints map[string]desiredEndpoint
+		wantInvalidServices  map[string]string // key is the cluster name and value is the error message
+	}{
+		{
+			name: "external target IP addresses and skipping weight proportioning",
+			exports: []client.Object{
+				externalIPExport("cluster-1", "20.1.2.3"),
+				externalIPExport("cluster-2", "2001:db8::1"),
+			},
+			wantDesiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#test-import#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-1"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/ExternalEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							Target:         ptr.To("20.1.2.3"),
+							EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+				"fleet-uid#test-import#cluster-2": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-2"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/ExternalEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							Target:         ptr.To("2001:db8::1"),
+							EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-2"},
+					},
+				},
+			},
+		},
+		{
+			name: "azure endpoint is rejected",
+			exports: []client.Object{
+				externalIPExport("cluster-1", "20.1.2.3"),
+				geographicInternalServiceExportForTest("cluster-2", ""),
+			},
+			wantDesiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#test-import#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-1"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/ExternalEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							Target:         ptr.To("20.1.2.3"),
+							EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+			},
+			wantInvalidServices: map[string]string{
+				"cluster-2": `the "MultiValue" routing method requires the external target IP address configured by the "networking.fleet.azure.com/external-target-ip" annotation`,
+			},
+		},
+		{
+			name: "external target FQDN is rejected",
+			exports: []client.Object{
+				externalIPExport("cluster-1", "20.1.2.3"),
+				externalFQDNExport,
+			},
+			wantDesiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#test-import#cluster-1": {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To("fleet-uid#test-import#cluster-1"),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/ExternalEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							Target:         ptr.To("20.1.2.3"),
+							EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+			},
+			wantInvalidServices: map[string]string{
+				"cluster-2": `the "MultiValue" routing method requires the external target IP address configured by the "networking.fleet.azure.com/external-target-ip" annotation`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tt.exports...).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			gotDesiredEndpoints, gotInvalidServicesErr, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+			if err != nil {
+				t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+			}
+			if diff := cmp.Diff(tt.wantDesiredEndpoints, gotDesiredEndpoints, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() desiredEndpoints mismatch (-want +got):\n%s", diff)
+			}
+			gotInvalidServices := make(map[string]string, len(gotInvalidServicesErr))
+			for cluster, err := range gotInvalidServicesErr {
+				gotInvalidServices[cluster] = err.Error()
+			}
+			if diff := cmp.Diff(tt.wantInvalidServices, gotInvalidServices, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() invalidServices mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateTrafficManagerEndpoints_PriorityCollision(t *testing.T) {
+	endpointsClient, err := fakeprovider.NewEndpointsClient()
+	if err != nil {
+		t.Fatalf("failed to create the fake endpoints client: %v", err)
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fakeprovider.ValidBackendName,
+			Namespace: "test-ns",
+		},
+	}
+	profile := &armtrafficmanager.Profile{
+		Name:       ptr.To(fakeprovider.ValidProfileName),
+		Properties: &armtrafficmanager.ProfileProperties{},
+	}
+	tests := []struct {
+		name          string
+		priority      *int64
+		wantUserError bool
+	}{
+		{
+			name:          "bad request with priority is a user error",
+			priority:      ptr.To(int64(1)),
+			wantUserError: true,
+		},
+		{
+			name: "bad request without priority is retriable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			r := &Reconciler{
+				Recorder: recorder,
+			}
+			clients := &azureclient.TrafficManagerClients{EndpointsClient: endpointsClient}
+			desiredEndpoints := map[string]desiredEndpoint{
+				fakeprovider.CreateBadRequestErrEndpointName: {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To(fakeprovider.CreateBadRequestErrEndpointName),
+						Properties: &armtrafficmanager.EndpointProperties{
+							Priority: tt.priority,
+						},
+					},
+				},
+			}
+			accepted, badEndpointsErr, err := r.updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(context.Background(), clients, fakeprovider.DefaultResourceGroupName, backend, profile, desiredEndpoints)
+			if err != nil {
+				t.Fatalf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got error %v, want nil", err)
+			}
+			if len(accepted) != 0 {
+				t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got %d accepted endpoints, want 0", len(accepted))
+			}
+			if len(badEndpointsErr) != 1 {
+				t.Fatalf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got %d bad endpoints, want 1", len(badEndpointsErr))
+			}
+			if got := errors.Is(badEndpointsErr[0], controller.ErrUserError); got != tt.wantUserError {
+				t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got user error %v, want %v", got, tt.wantUserError)
+			}
+			if len(recorder.Events) != 1 {
+				t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() emitted %d events, want 1", len(recorder.Events))
+			}
+		})
+	}
+}
+
+func TestUpdateTrafficManagerEndpoints_EndpointTypeChanged(t *testing.T) {
+	var calls []string
+	fakeServer := armtrafficmanagerfake.EndpointsServer{
+		Delete: func(_ context.Context, _ string, _ string, endpointType armtrafficmanager.EndpointType, endpointName string, _ *armtrafficmanager.EndpointsClientDeleteOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientDeleteResponse], errResp azcorefake.ErrorResponder) {
+			calls = append(calls, fmt.Sprintf("delete %s/%s", endpointType, endpointName))
+			resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientDeleteResponse{}, nil)
+			return resp, errResp
+		},
+		CreateOrUpdate: func(_ context.Context, _ string, _ string, endpointType armtrafficmanager.EndpointType, endpointName string, parameters armtrafficmanager.Endpoint, _ *armtrafficmanager.EndpointsClientCreateOrUpdateOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientCreateOrUpdateResponse], errResp azcorefake.ErrorResponder) {
+			calls = append(calls, fmt.Sprintf("createOrUpdate %s/%s", endpointType, endpointName))
+			parameters.ID = ptr.To("endpoint-id")
+			resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientCreateOrUpdateResponse{Endpoint: parameters}, nil)
+			return resp, errResp
+		},
+	}
+	clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+		&arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: armtrafficmanagerfake.NewEndpointsServerTransport(&fakeServer),
+				Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+			},
+		})
+	if err != nil {
+		t.Fatalf("failed to create the client factory: %v", err)
+	}
+	r := &Reconciler{
+		Recorder: record.NewFakeRecorder(10),
+	}
+	clients := &azureclient.TrafficManagerClients{EndpointsClient: clientFactory.NewEndpointsClient()}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+	}
+	endpointName := "fleet-uid#test-import#cluster-1"
+	profile := &armtrafficmanager.Profile{
+		Name: ptr.To("test-profile"),
+		Properties: &armtrafficmanager.ProfileProperties{
+			Endpoints: []*armtrafficmanager.Endpoint{
+				{
+					Name: ptr.To(endpointName),
+					Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+					Properties: &armtrafficmanager.EndpointProperties{
+						TargetResourceID: ptr.To("resourceID"),
+						EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+						Weight:           ptr.To(int64(1)),
+					},
+				},
+			},
+		},
+	}
+	desiredEndpoints := map[string]desiredEndpoint{
+		endpointName: {
+			Endpoint: armtrafficmanager.Endpoint{
+				Name: ptr.To(endpointName),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/ExternalEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:           ptr.To("app.example.com"),
+					EndpointLocation: ptr.To("eastus"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(1)),
+				},
+			},
+		},
+	}
+	accepted, badEndpointsErr, err := r.updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(context.Background(), clients, "test-rg", backend, profile, desiredEndpoints)
+	if err != nil {
+		t.Fatalf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got error %v, want nil", err)
+	}
+	if len(badEndpointsErr) != 0 {
+		t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got bad endpoints %v, want none", badEndpointsErr)
+	}
+	if len(accepted) != 1 {
+		t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got %d accepted endpoints, want 1", len(accepted))
+	}
+	if !ptr.Deref(backend.Status.EndpointsCreated, false) {
+		t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got status.endpointsCreated %v, want true", backend.Status.EndpointsCreated)
+	}
+	wantCalls := []string{
+		"delete AzureEndpoints/" + endpointName,
+		"createOrUpdate ExternalEndpoints/" + endpointName,
+	}
+	if diff := cmp.Diff(wantCalls, calls); diff != "" {
+		t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() Azure calls mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUpdateTrafficManagerEndpoints_AuthorizationFailed(t *testing.T) {
+	var calls int
+	fakeServer := armtrafficmanagerfake.EndpointsServer{
+		CreateOrUpdate: func(_ context.Context, _ string, _ string, _ armtrafficmanager.EndpointType, _ string, _ armtrafficmanager.Endpoint, _ *armtrafficmanager.EndpointsClientCreateOrUpdateOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientCreateOrUpdateResponse], errResp azcorefake.ErrorResponder) {
+			calls++
+			errResp.SetResponseError(http.StatusForbidden, "AuthorizationFailed")
+			return resp, errResp
+		},
+	}
+	clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+		&arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: armtrafficmanagerfake.NewEndpointsServerTransport(&fakeServer),
+				Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+			},
+		})
+	if err != nil {
+		t.Fatalf("failed to create the client factory: %v", err)
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-backend",
+			Namespace:  "test-ns",
+			UID:        "uid",
+			Generation: 1,
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(backend).
+		WithStatusSubresource(backend).
+		WithInterceptorFuncs(interceptor.Funcs{SubResourcePatch: applyStatusForTest}).
+		Build()
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	clients := &azureclient.TrafficManagerClients{EndpointsClient: clientFactory.NewEndpointsClient()}
+	profile := &armtrafficmanager.Profile{
+		Name:       ptr.To("test-profile"),
+		Properties: &armtrafficmanager.ProfileProperties{},
+	}
+	desiredEndpoints := make(map[string]desiredEndpoint)
+	for _, cluster := range []string{"cluster-1", "cluster-2"} {
+		name := fmt.Sprintf("fleet-uid#test-import#%s", cluster)
+		desiredEndpoints[name] = desiredEndpoint{
+			Endpoint: armtrafficmanager.Endpoint{
+				Name:       ptr.To(name),
+				Type:       ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{Weight: ptr.To(int64(1))},
+			},
+		}
+	}
+
+	_, _, err = r.updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(context.Background(), clients, "test-rg", backend, profile, desiredEndpoints)
+	if !errors.Is(err, errAuthorizationFailed) {
+		t.Fatalf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got error %v, want %v", err, errAuthorizationFailed)
+	}
+	if calls != 1 {
+		t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() sent %d createOrUpdate requests, want 1", calls)
+	}
+	got := &fleetnetv1beta1.TrafficManagerBackend{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}, got); err != nil {
+		t.Fatalf("failed to get the trafficManagerBackend: %v", err)
+	}
+	wantConditions := []metav1.Condition{
+		{
+			Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: 1,
+			Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonAuthorizationFailed),
+		},
+		{
+			Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: 1,
+			Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonNotAccepted),
+		},
+	}
+	if diff := cmp.Diff(wantConditions, got.Status.Conditions, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message")); diff != "" {
+		t.Errorf("trafficManagerBackend conditions mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// fakeEndpointsClient returns the configured error for all the createOrUpdate requests, to simulate the errors which
+// cannot be returned by the fake server, for example, the transport errors or the errors wrapped by the SDK policies.
+type fakeEndpointsClient struct {
+	azureclient.EndpointsClientInterface
+	createOrUpdateErr   error
+	createOrUpdateCalls int
+}
+
+func (c *fakeEndpointsClient) CreateOrUpdate(_ context.Context, _ string, _ string, _ armtrafficmanager.EndpointType, _ string, _ armtrafficmanager.Endpoint, _ *armtrafficmanager.EndpointsClientCreateOrUpdateOptions) (armtrafficmanager.EndpointsClientCreateOrUpdateResponse, error) {
+	c.createOrUpdateCalls++
+	return armtrafficmanager.EndpointsClientCreateOrUpdateResponse{}, c.createOrUpdateErr
+}
+
+func TestUpdateTrafficManagerEndpoints_InjectedAzureError(t *testing.T) {
+	transportErr := errors.New("connection reset by peer")
+	throttledErr := fmt.Errorf("retry attempts exhausted: %w", &azcore.ResponseError{StatusCode: http.StatusTooManyRequests, ErrorCode: "TooManyRequests"})
+	tests := []struct {
+		name       string
+		err        error
+		wantErr    error
+		wantStatus metav1.ConditionStatus // empty when the Accepted condition should not be set
+		wantReason fleetnetv1beta1.TrafficManagerBackendConditionReason
+	}{
+		{
+			name:    "transport error is returned without updating the status",
+			err:     transportErr,
+			wantErr: transportErr,
+		},
+		{
+			name:       "wrapped throttled error is retried",
+			err:        throttledErr,
+			wantErr:    throttledErr,
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: fleetnetv1beta1.TrafficManagerBackendReasonPending,
+		},
+		{
+			name:       "wrapped authorization failure is returned",
+			err:        fmt.Errorf("request failed: %w", &azcore.ResponseError{StatusCode: http.StatusForbidden, ErrorCode: "AuthorizationFailed"}),
+			wantErr:    errAuthorizationFailed,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: fleetnetv1beta1.TrafficManagerBackendReasonAuthorizationFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-backend",
+					Namespace:  "test-ns",
+					UID:        "uid",
+					Generation: 1,
+				},
+			}
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(backend).
+				WithStatusSubresource(backend).
+				WithInterceptorFuncs(interceptor.Funcs{SubResourcePatch: applyStatusForTest}).
+				Build()
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			endpointsClient := &fakeEndpointsClient{createOrUpdateErr: tt.err}
+			clients := &azureclient.TrafficManagerClients{EndpointsClient: endpointsClient}
+			profile := &armtrafficmanager.Profile{
+				Name:       ptr.To("test-profile"),
+				Properties: &armtrafficmanager.ProfileProperties{},
+			}
+			endpointName := "fleet-uid#test-import#cluster-1"
+			desiredEndpoints := map[string]desiredEndpoint{
+				endpointName: {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name:       ptr.To(endpointName),
+						Type:       ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{Weight: ptr.To(int64(1))},
+					},
+				},
+			}
+
+			_, badEndpointsErr, err := r.updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(context.Background(), clients, "test-rg", backend, profile, desiredEndpoints)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got error %v, want %v", err, tt.wantErr)
+			}
+			if len(badEndpointsErr) != 0 {
+				t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got bad endpoints %v, want none", badEndpointsErr)
+			}
+			if endpointsClient.createOrUpdateCalls != 1 {
+				t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() sent %d createOrUpdate requests, want 1", endpointsClient.createOrUpdateCalls)
+			}
+			got := &fleetnetv1beta1.TrafficManagerBackend{}
+			if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}, got); err != nil {
+				t.Fatalf("failed to get the trafficManagerBackend: %v", err)
+			}
+			cond := meta.FindStatusCondition(got.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted))
+			if tt.wantStatus == "" {
+				if cond != nil {
+					t.Errorf("trafficManagerBackend Accepted condition = %+v, want nil", cond)
+				}
+				return
+			}
+			if cond == nil || cond.Status != tt.wantStatus || cond.Reason != string(tt.wantReason) {
+				t.Errorf("trafficManagerBackend Accepted condition = %+v, want status %q and reason %q", cond, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCleanupEndpointsInStaleAzureTrafficManagerProfile(t *testing.T) {
+	currentID := "/subscriptions/sub/resourceGroups/new-rg/providers/Microsoft.Network/trafficManagerProfiles/new-profile"
+	staleID := "/subscriptions/sub/resourceGroups/old-rg/providers/Microsoft.Network/trafficManagerProfiles/old-profile"
+	ownedEndpoint := "fleet-uid#test-import#cluster-1"
+	tests := []struct {
+		name              string
+		profileResourceID string
+		getStatusCode     int
+		wantErr           bool
+		wantDeleted       []string
+		wantResourceID    string
+	}{
+		{
+			name:           "no profile recorded",
+			wantResourceID: currentID,
+		},
+		{
+			name:              "same profile recorded in different case",
+			profileResourceID: strings.ToUpper(currentID),
+			wantResourceID:    currentID,
+		},
+		{
+			name:              "profile moved to another resource group",
+			profileResourceID: staleID,
+			getStatusCode:     http.StatusOK,
+			wantDeleted:       []string{"old-rg/old-profile/" + ownedEndpoint},
+			wantResourceID:    currentID,
+		},
+		{
+			name:              "previous profile does not exist",
+			profileResourceID: staleID,
+			getStatusCode:     http.StatusNotFound,
+			wantResourceID:    currentID,
+		},
+		{
+			name:              "failed to get the previous profile",
+			profileResourceID: staleID,
+			getStatusCode:     http.StatusInternalServerError,
+			wantErr:           true,
+			wantResourceID:    staleID,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var deleted []string
+			fakeServer := armtrafficmanagerfake.ServerFactory{
+				ProfilesServer: armtrafficmanagerfake.ProfilesServer{
+					Get: func(_ context.Context, resourceGroupName string, profileName string, _ *armtrafficmanager.ProfilesClientGetOptions) (resp azcorefake.Responder[armtrafficmanager.ProfilesClientGetResponse], errResp azcorefake.ErrorResponder) {
+						if tc.getStatusCode != http.StatusOK {
+							errResp.SetResponseError(tc.getStatusCode, http.StatusText(tc.getStatusCode))
+							return resp, errResp
+						}
+						profile := armtrafficmanager.Profile{
+							ID:   ptr.To(staleID),
+							Name: ptr.To(profileName),
+							Properties: &armtrafficmanager.ProfileProperties{
+								Endpoints: []*armtrafficmanager.Endpoint{
+									{
+										Name: ptr.To(ownedEndpoint),
+										Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+									},
+									{
+										Name: ptr.To("fleet-other-uid#test-import#cluster-1"),
+										Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+									},
+								},
+							},
+						}
+						resp.SetResponse(http.StatusOK, armtrafficmanager.ProfilesClientGetResponse{Profile: profile}, nil)
+						return resp, errResp
+					},
+				},
+				EndpointsServer: armtrafficmanagerfake.EndpointsServer{
+					Delete: func(_ context.Context, resourceGroupName string, profileName string, _ armtrafficmanager.EndpointType, endpointName string, _ *armtrafficmanager.EndpointsClientDeleteOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientDeleteResponse], errResp azcorefake.ErrorResponder) {
+						deleted = append(deleted, fmt.Sprintf("%s/%s/%s", resourceGroupName, profileName, endpointName))
+						resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientDeleteResponse{}, nil)
+						return resp, errResp
+					},
+				},
+			}
+			clientFactory, err := armtrafficmanager.NewClientFactory("sub", &azcorefake.TokenCredential{},
+				&arm.ClientOptions{
+					ClientOptions: azcore.ClientOptions{
+						Transport: armtrafficmanagerfake.NewServerFactoryTransport(&fakeServer),
+						Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+					},
+				})
+			if err != nil {
+				t.Fatalf("failed to create the client factory: %v", err)
+			}
+			r := &Reconciler{
+				AzureClientFactory: azureclient.NewTrafficManagerClientFactoryForClients(clientFactory.NewProfilesClient(), clientFactory.NewEndpointsClient()),
+				Recorder:           record.NewFakeRecorder(10),
+			}
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-backend",
+					Namespace: "test-ns",
+					UID:       "uid",
+				},
+				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+					ProfileResourceID: tc.profileResourceID,
+				},
+			}
+			atmProfile := &armtrafficmanager.Profile{
+				ID:   ptr.To(currentID),
+				Name: ptr.To("new-profile"),
+			}
+			err = r.cleanupEndpointsInStaleAzureTrafficManagerProfile(context.Background(), backend, atmProfile)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("cleanupEndpointsInStaleAzureTrafficManagerProfile() got error %v, want error %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, deleted); diff != "" {
+				t.Errorf("cleanupEndpointsInStaleAzureTrafficManagerProfile() deleted endpoints mismatch (-want +got):\n%s", diff)
+			}
+			if backend.Status.ProfileResourceID != tc.wantResourceID {
+				t.Errorf("cleanupEndpointsInStaleAzureTrafficManagerProfile() got profileResourceID %q, want %q", backend.Status.ProfileResourceID, tc.wantResourceID)
+			}
+		})
+	}
+}
+
+func TestIsOnlyWeightChanged(t *testing.T) {
+	current := armtrafficmanager.Endpoint{
+		Name: ptr.To("endpoint"),
+		Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+		Properties: &armtrafficmanager.EndpointProperties{
+			TargetResourceID: ptr.To("resourceID"),
+			EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+			Weight:           ptr.To(int64(5)),
+		},
+	}
+	tests := []struct {
+		name    string
+		desired armtrafficmanager.Endpoint
+		want    bool
+	}{
+		{
+			name: "same endpoint",
+			desired: armtrafficmanager.Endpoint{
+				Name: ptr.To("endpoint"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(5)),
+				},
+			},
+			want: false,
+		},
+		{
+			name: "only weight is changed",
+			desired: armtrafficmanager.Endpoint{
+				Name: ptr.To("endpoint"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(10)),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "weight and endpoint status are changed",
+			desired: armtrafficmanager.Endpoint{
+				Name: ptr.To("endpoint"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusDisabled),
+					Weight:           ptr.To(int64(10)),
+				},
+			},
+			want: false,
+		},
+		{
+			name: "weight is not set",
+			desired: armtrafficmanager.Endpoint{
+				Name: ptr.To("endpoint"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Priority:         ptr.To(int64(1)),
+				},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desiredWeight := tt.desired.Properties.Weight
+			if got := isOnlyWeightChanged(current, tt.desired); got != tt.want {
+				t.Errorf("isOnlyWeightChanged() = %v, want %v", got, tt.want)
+			}
+			if tt.desired.Properties.Weight != desiredWeight {
+				t.Errorf("isOnlyWeightChanged() modified the weight of the desired endpoint")
+			}
+		})
+	}
+}
+
+func TestDiffAzureTrafficManagerEndpoint(t *testing.T) {
+	desired := armtrafficmanager.Endpoint{
+		Name: ptr.To("endpoint"),
+		Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+		Properties: &armtrafficmanager.EndpointProperties{
+			TargetResourceID: ptr.To("resource-id"),
+			EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+			Weight:           ptr.To(int64(100)),
+		},
+	}
+	tests := []struct {
+		name    string
+		current armtrafficmanager.Endpoint
+		desired armtrafficmanager.Endpoint
+		want    []string
+	}{
+		{
+			name: "same endpoint",
+			current: armtrafficmanager.Endpoint{
+				Name: ptr.To("endpoint"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/AzureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:           ptr.To("target"),
+					TargetResourceID: ptr.To("RESOURCE-ID"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(100)),
+				},
+			},
+			desired: desired,
+		},
+		{
+			name: "weight and status differ",
+			current: armtrafficmanager.Endpoint{
+				Name: ptr.To("endpoint"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resource-id"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusDisabled),
+					Weight:           ptr.To(int64(50)),
+				},
+			},
+			desired: desired,
+			want: []string{
+				"weight (desired: 100, actual: 50)",
+				"endpointStatus (desired: Enabled, actual: Disabled)",
+			},
+		},
+		{
+			name: "target resource and type differ",
+			current: armtrafficmanager.Endpoint{
+				Name: ptr.To("endpoint"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/externalEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:         ptr.To("target"),
+					EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:         ptr.To(int64(100)),
+				},
+			},
+			desired: desired,
+			want: []string{
+				"type (desired: Microsoft.Network/trafficManagerProfiles/azureEndpoints, actual: Microsoft.Network/trafficManagerProfiles/externalEndpoints)",
+				"targetResourceID (desired: resource-id, actual: <unset>)",
+			},
+		},
+		{
+			name: "priority and geo mapping differ",
+			current: armtrafficmanager.Endpoint{
+				Name: ptr.To("endpoint"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resource-id"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Priority:         ptr.To(int64(2)),
+					GeoMapping:       []*string{ptr.To("US")},
+				},
+			},
+			desired: armtrafficmanager.Endpoint{
+				Name: ptr.To("endpoint"),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resource-id"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Priority:         ptr.To(int64(1)),
+					GeoMapping:       []*string{ptr.To("US"), ptr.To("CA")},
+				},
+			},
+			want: []string{
+				"priority (desired: 1, actual: 2)",
+				"geoMapping (desired: US,CA, actual: US)",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := diffAzureTrafficManagerEndpoint(tc.current, tc.desired)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("diffAzureTrafficManagerEndpoint() mismatch (-want, +got):\n%s", diff)
+			}
+			if gotEqual, wantEqual := equalAzureTrafficManagerEndpoint(tc.current, tc.desired), len(tc.want) == 0; gotEqual != wantEqual {
+				t.Errorf("equalAzureTrafficManagerEndpoint() = %v, want %v", gotEqual, wantEqual)
+			}
+		})
+	}
+}
+
+func TestUpdateTrafficManagerEndpoints_WeightRecomputed(t *testing.T) {
+	fakeServer := armtrafficmanagerfake.EndpointsServer{
+		CreateOrUpdate: func(_ context.Context, _ string, _ string, _ armtrafficmanager.EndpointType, _ string, parameters armtrafficmanager.Endpoint, _ *armtrafficmanager.EndpointsClientCreateOrUpdateOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientCreateOrUpdateResponse], errResp azcorefake.ErrorResponder) {
+			parameters.ID = ptr.To("endpoint-id")
+			resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientCreateOrUpdateResponse{Endpoint: parameters}, nil)
+			return resp, errResp
+		},
+	}
+	clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+		&arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: armtrafficmanagerfake.NewEndpointsServerTransport(&fakeServer),
+				Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+			},
+		})
+	if err != nil {
+		t.Fatalf("failed to create the client factory: %v", err)
+	}
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Recorder: recorder,
+	}
+	clients := &azureclient.TrafficManagerClients{EndpointsClient: clientFactory.NewEndpointsClient()}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+	}
+	endpointNames := []string{"fleet-uid#test-import#cluster-1", "fleet-uid#test-import#cluster-2"}
+	profile := &armtrafficmanager.Profile{
+		Name: ptr.To("test-profile"),
+		Properties: &armtrafficmanager.ProfileProperties{
+			Endpoints: []*armtrafficmanager.Endpoint{
+				{
+					Name: ptr.To(endpointNames[0]),
+					Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+					Properties: &armtrafficmanager.EndpointProperties{
+						TargetResourceID: ptr.To("resourceID-1"),
+						EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+						Weight:           ptr.To(int64(5)),
+					},
+				},
+				{
+					Name: ptr.To(endpointNames[1]),
+					Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+					Properties: &armtrafficmanager.EndpointProperties{
+						TargetResourceID: ptr.To("resourceID-2"),
+						EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+						Weight:           ptr.To(int64(5)),
+					},
+				},
+			},
+		},
+	}
+	desiredEndpoints := map[string]desiredEndpoint{
+		endpointNames[0]: {
+			Endpoint: armtrafficmanager.Endpoint{
+				Name: ptr.To(endpointNames[0]),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID-1"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					Weight:           ptr.To(int64(7)),
+				},
+			},
+			FromCluster: fleetnetv1beta1.FromCluster{
+				ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+			},
+		},
+		endpointNames[1]: {
+			Endpoint: armtrafficmanager.Endpoint{
+				Name: ptr.To(endpointNames[1]),
+				Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID: ptr.To("resourceID-2"),
+					EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusDisabled), // not only the weight is changed
+					Weight:           ptr.To(int64(3)),
+				},
+			},
+			FromCluster: fleetnetv1beta1.FromCluster{
+				ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-2"},
+			},
+		},
+	}
+	if _, _, err := r.updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(context.Background(), clients, "test-rg", backend, profile, desiredEndpoints); err != nil {
+		t.Fatalf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got error %v, want nil", err)
+	}
+	close(recorder.Events)
+	var gotEvents []string
+	for event := range recorder.Events {
+		if strings.Contains(event, backendEventReasonWeightRecomputed) {
+			gotEvents = append(gotEvents, event)
+		}
+	}
+	wantEvents := []string{
+		fmt.Sprintf("Normal %s Updated the weight of Azure Traffic Manager endpoint %q for cluster %q from 5 to 7", backendEventReasonWeightRecomputed, endpointNames[0], "cluster-1"),
+	}
+	if diff := cmp.Diff(wantEvents, gotEvents); diff != "" {
+		t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuildAcceptedEndpointStatus(t *testing.T) {
+	desired := desiredEndpoint{
+		FromCluster: fleetnetv1beta1.FromCluster{
+			ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+			Weight:        ptr.To(int64(1)),
+		},
+		FromGeneration: 3,
+	}
+	tests := []struct {
+		name     string
+		endpoint *armtrafficmanager.Endpoint
+		want     fleetnetv1beta1.TrafficManagerEndpointStatus
+	}{
+		{
+			name: "endpoint with monitor status",
+			endpoint: &armtrafficmanager.Endpoint{
+				ID:   ptr.To("endpoint-id"),
+				Name: ptr.To("Fleet-UID#Service#Cluster-1"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:                ptr.To("target"),
+					Weight:                ptr.To(int64(100)),
+					EndpointMonitorStatus: ptr.To(armtrafficmanager.EndpointMonitorStatusDegraded),
+				},
+			},
+			want: fleetnetv1beta1.TrafficManagerEndpointStatus{
+				Name:           "fleet-uid#service#cluster-1",
+				ResourceID:     "endpoint-id",
+				Target:         ptr.To("target"),
+				Weight:         ptr.To(int64(100)),
+				MonitorStatus:  ptr.To(fleetnetv1beta1.TrafficManagerEndpointMonitorStatusDegraded),
+				From:           &desired.FromCluster,
+				FromGeneration: 3,
+			},
+		},
+		{
+			name: "endpoint without monitor status",
+			endpoint: &armtrafficmanager.Endpoint{
+				ID:   ptr.To("endpoint-id"),
+				Name: ptr.To("fleet-uid#service#cluster-1"),
+				Properties: &armtrafficmanager.EndpointProperties{
+					Target:     ptr.To("target"),
+					GeoMapping: []*string{ptr.To("US")},
+				},
+			},
+			want: fleetnetv1beta1.TrafficManagerEndpointStatus{
+				Name:           "fleet-uid#service#cluster-1",
+				ResourceID:     "endpoint-id",
+				Target:         ptr.To("target"),
+				GeoMapping:     []string{"US"},
+				From:           &desired.FromCluster,
+				FromGeneration: 3,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildAcceptedEndpointStatus(tt.endpoint, desired)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("buildAcceptedEndpointStatus() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetEndpointPercentages(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []*int64
+		want    []*int64
+	}{
+		{
+			name: "no endpoints",
+		},
+		{
+			name:    "single endpoint",
+			weights: []*int64{ptr.To(int64(100))},
+			want:    []*int64{ptr.To(int64(100))},
+		},
+		{
+			name:    "rounded to the nearest integer",
+			weights: []*int64{ptr.To(int64(1)), ptr.To(int64(1)), ptr.To(int64(1))},
+			want:    []*int64{ptr.To(int64(33)), ptr.To(int64(33)), ptr.To(int64(33))},
+		},
+		{
+			name:    "uneven weights",
+			weights: []*int64{ptr.To(int64(375)), ptr.To(int64(125)), ptr.To(int64(0))},
+			want:    []*int64{ptr.To(int64(75)), ptr.To(int64(25)), ptr.To(int64(0))},
+		},
+		{
+			name:    "rounded up",
+			weights: []*int64{ptr.To(int64(2)), ptr.To(int64(1))},
+			want:    []*int64{ptr.To(int64(67)), ptr.To(int64(33))},
+		},
+		{
+			name:    "all the weights are 0",
+			weights: []*int64{ptr.To(int64(0)), ptr.To(int64(0))},
+			want:    []*int64{nil, nil},
+		},
+		{
+			name:    "missing weight",
+			weights: []*int64{ptr.To(int64(1)), nil},
+			want:    []*int64{nil, nil},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoints := make([]fleetnetv1beta1.TrafficManagerEndpointStatus, len(tc.weights))
+			for i, weight := range tc.weights {
+				endpoints[i] = fleetnetv1beta1.TrafficManagerEndpointStatus{Name: fmt.Sprintf("endpoint-%d", i), Weight: weight}
+			}
+			setEndpointPercentages(endpoints)
+			got := make([]*int64, len(endpoints))
+			for i := range endpoints {
+				got[i] = endpoints[i].Percentage
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("setEndpointPercentages() percentages mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetTrueCondition(t *testing.T) {
+	lastSyncTime := metav1.NewTime(time.Now().Add(-time.Hour))
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+			InvalidEndpoints: []fleetnetv1beta1.InvalidEndpointStatus{{ServiceImport: "test-import", Cluster: "cluster-1"}},
+			LastSyncTime:     &lastSyncTime,
+		},
+	}
+	acceptedEndpoints := []fleetnetv1beta1.TrafficManagerEndpointStatus{{Name: "endpoint-1"}}
+	setTrueCondition(backend, acceptedEndpoints)
+
+	if diff := cmp.Diff(acceptedEndpoints, backend.Status.Endpoints); diff != "" {
+		t.Errorf("setTrueCondition() endpoints mismatch (-want, +got):\n%s", diff)
+	}
+	if backend.Status.InvalidEndpoints != nil {
+		t.Errorf("setTrueCondition() invalid endpoints = %v, want nil", backend.Status.InvalidEndpoints)
+	}
+	if backend.Status.LastSyncTime == nil || !backend.Status.LastSyncTime.After(lastSyncTime.Time) {
+		t.Errorf("setTrueCondition() last sync time = %v, want after %v", backend.Status.LastSyncTime, lastSyncTime)
+	}
+	cond := meta.FindStatusCondition(backend.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted))
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.ObservedGeneration != 2 {
+		t.Errorf("setTrueCondition() accepted condition = %+v, want true with observed generation 2", cond)
+	}
+}
+
+// applyStatusForTest emulates the server-side apply of the trafficManagerBackend status, which is not supported by the
+// fake client, by replacing the whole status with the applied one.
+func applyStatusForTest(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+	}
+	applied := &fleetnetv1beta1.TrafficManagerBackend{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, applied); err != nil {
+		return err
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: applied.Namespace, Name: applied.Name}, backend); err != nil {
+		return err
+	}
+	backend.Status = applied.Status
+	if err := c.SubResource(subResourceName).Update(ctx, backend); err != nil {
+		return err
+	}
+	updated, err := runtime.DefaultUnstructuredConverter.ToUnstructured(backend)
+	if err != nil {
+		return err
+	}
+	obj.(*unstructured.Unstructured).Object = updated
+	return nil
+}
+
+func TestBuildTrafficManagerBackendStatusApplyObject(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-backend",
+			Namespace:  "test-ns",
+			UID:        "test-uid",
+			Generation: 2,
+			Finalizers: []string{objectmeta.TrafficManagerBackendFinalizer},
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "test-profile"},
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{Name: "test-import"},
+			Weight:  ptr.To(int64(10)),
+		},
+		Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+			ProfileResourceID: "test-profile-id",
+		},
+	}
+	got, err := buildTrafficManagerBackendStatusApplyObject(backend)
+	if err != nil {
+		t.Fatalf("buildTrafficManagerBackendStatusApplyObject() got error %v, want nil", err)
+	}
+	want := map[string]interface{}{
+		"apiVersion": "networking.fleet.azure.com/v1beta1",
+		"kind":       "TrafficManagerBackend",
+		"metadata": map[string]interface{}{
+			"name":      "test-backend",
+			"namespace": "test-ns",
+			"uid":       "test-uid",
+		},
+		"status": map[string]interface{}{
+			"profileResourceID": "test-profile-id",
+		},
+	}
+	if diff := cmp.Diff(want, got.Object); diff != "" {
+		t.Errorf("buildTrafficManagerBackendStatusApplyObject() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBuildInvalidEndpointStatuses(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name:            "blue",
+				AdditionalNames: []string{"green"},
+			},
+		},
+	}
+	badEndpointErr := errors.New("bad request")
+	tests := []struct {
+		name            string
+		invalidServices map[string]error
+		badEndpointsErr []error
+		want            []fleetnetv1beta1.InvalidEndpointStatus
+	}{
+		{
+			name: "no invalid services or bad endpoints",
+		},
+		{
+			name: "invalid services and bad endpoints",
+			invalidServices: map[string]error{
+				"cluster-2":       errors.New("not a load balancer service"),
+				"green/cluster-1": errServiceConflict,
+			},
+			badEndpointsErr: []error{
+				&badEndpointError{
+					endpoint: desiredEndpoint{
+						Endpoint:    armtrafficmanager.Endpoint{Name: ptr.To("fleet-uid#blue#cluster-1")},
+						FromCluster: fleetnetv1beta1.FromCluster{ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"}},
+					},
+					err: controller.NewUserError(badEndpointErr),
+				},
+				&badEndpointError{
+					endpoint: desiredEndpoint{
+						Endpoint:                    armtrafficmanager.Endpoint{Name: ptr.To("fleet-uid#green#cluster-3")},
+						FromCluster:                 fleetnetv1beta1.FromCluster{ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-3"}},
+						AdditionalServiceImportName: "green",
+					},
+					err: badEndpointErr,
+				},
+			},
+			want: []fleetnetv1beta1.InvalidEndpointStatus{
+				{
+					Cluster:       "cluster-1",
+					ServiceImport: "blue",
+					Name:          "fleet-uid#blue#cluster-1",
+					Reason:        fleetnetv1beta1.InvalidEndpointReasonEndpointFailed,
+					Message:       controller.NewUserError(badEndpointErr).Error(),
+				},
+				{
+					Cluster:       "cluster-2",
+					ServiceImport: "blue",
+					Reason:        fleetnetv1beta1.InvalidEndpointReasonInvalidService,
+					Message:       "not a load balancer service",
+				},
+				{
+					Cluster:       "cluster-1",
+					ServiceImport: "green",
+					Reason:        fleetnetv1beta1.InvalidEndpointReasonServiceConflict,
+					Message:       errServiceConflict.Error(),
+				},
+				{
+					Cluster:       "cluster-3",
+					ServiceImport: "green",
+					Name:          "fleet-uid#green#cluster-3",
+					Reason:        fleetnetv1beta1.InvalidEndpointReasonEndpointFailed,
+					Message:       "bad request",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildInvalidEndpointStatuses(backend, tt.invalidServices, tt.badEndpointsErr)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("buildInvalidEndpointStatuses() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestShouldHandleServiceImportUpateEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		old  *fleetnetv1alpha1.ServiceImport
+		new  *fleetnetv1alpha1.ServiceImport
+		want bool
+	}{
+		{
+			name: "clusters are the same",
+			old: &fleetnetv1alpha1.ServiceImport{
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Clusters: []fleetnetv1alpha1.ClusterStatus{
+						{Cluster: "cluster1"},
+						{Cluster: "cluster2"},
+					},
+				},
+			},
+			new: &fleetnetv1alpha1.ServiceImport{
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Clusters: []fleetnetv1alpha1.ClusterStatus{
+						{Cluster: "cluster1"},
+						{Cluster: "cluster2"},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "clusters are different",
+			old: &fleetnetv1alpha1.ServiceImport{
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Clusters: []fleetnetv1alpha1.ClusterStatus{
+						{Cluster: "cluster1"},
+					},
+				},
+			},
+			new: &fleetnetv1alpha1.ServiceImport{
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Clusters: []fleetnetv1alpha1.ClusterStatus{
+						{Cluster: "cluster1"},
+						{Cluster: "cluster2"},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "empty clusters in both",
+			old: &fleetnetv1alpha1.ServiceImport{
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Clusters: []fleetnetv1alpha1.ClusterStatus{},
+				},
+			},
+			new: &fleetnetv1alpha1.ServiceImport{
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Clusters: []fleetnetv1alpha1.ClusterStatus{},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "nil clusters in old, empty in new",
+			old: &fleetnetv1alpha1.ServiceImport{
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Clusters: nil,
+				},
+			},
+			new: &fleetnetv1alpha1.ServiceImport{
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Clusters: []fleetnetv1alpha1.ClusterStatus{},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "same clusters but different order",
+			old: &fleetnetv1alpha1.ServiceImport{
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Clusters: []fleetnetv1alpha1.ClusterStatus{
+						{Cluster: "cluster1"},
+						{Cluster: "cluster2"},
+					},
+				},
+			},
+			new: &fleetnetv1alpha1.ServiceImport{
+				Status: fleetnetv1alpha1.ServiceImportStatus{
+					Clusters: []fleetnetv1alpha1.ClusterStatus{
+						{Cluster: "cluster2"},
+						{Cluster: "cluster1"},
+					},
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldHandleServiceImportUpateEvent(tt.old, tt.new); got != tt.want {
+				t.Errorf("shouldHandleServiceImportUpateEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+func TestShouldHandleTrafficManagerProfileUpdateEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		old  *fleetnetv1beta1.TrafficManagerProfile
+		new  *fleetnetv1beta1.TrafficManagerProfile
+		want bool
+	}{
+		{
+			name: "both profiles have no conditions",
 			old: &fleetnetv1beta1.TrafficManagerProfile{
 				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{},
+				},
+			},
+			new: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "old profile has no programmed condition, new profile has programmed condition",
+			old: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{},
+				},
+			},
+			new: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
+							Status: metav1.ConditionTrue,
+							Reason: "Programmed",
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "old profile has programmed condition, new profile has no programmed condition",
+			old: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
+							Status: metav1.ConditionTrue,
+							Reason: "Programmed",
+						},
+					},
+				},
+			},
+			new: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "same programmed condition status and reason",
+			old: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
+							Status: metav1.ConditionTrue,
+							Reason: "Programmed",
+						},
+					},
+				},
+			},
+			new: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
+							Status: metav1.ConditionTrue,
+							Reason: "Programmed",
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "different programmed condition status",
+			old: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
+							Status: metav1.ConditionFalse,
+							Reason: "Invalid",
+						},
+					},
+				},
+			},
+			new: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
+							Status: metav1.ConditionTrue,
+							Reason: "Programmed",
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "same status but different reason - should not requeue",
+			old: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
+							Status: metav1.ConditionTrue,
+							Reason: "Programmed",
+						},
+					},
+				},
+			},
+			new: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
+							Status: metav1.ConditionTrue,
+							Reason: "Updated",
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "different condition types - should not requeue",
+			old: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   "SomeOtherCondition",
+							Status: metav1.ConditionTrue,
+							Reason: "Ready",
+						},
+					},
+				},
+			},
+			new: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   "AnotherCondition",
+							Status: metav1.ConditionTrue,
+							Reason: "Ready",
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "condition status changes from unknown to true",
+			old: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
+							Status: metav1.ConditionUnknown,
+							Reason: "Pending",
+						},
+					},
+				},
+			},
+			new: &fleetnetv1beta1.TrafficManagerProfile{
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
+							Status: metav1.ConditionTrue,
+							Reason: "Programmed",
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "force resync annotation is added",
+			old:  &fleetnetv1beta1.TrafficManagerProfile{},
+			new: &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						objectmeta.TrafficManagerProfileAnnotationForceResync: "2024-01-01T00:00:00Z",
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "force resync annotation is changed",
+			old: &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						objectmeta.TrafficManagerProfileAnnotationForceResync: "2024-01-01T00:00:00Z",
+					},
+				},
+			},
+			new: &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						objectmeta.TrafficManagerProfileAnnotationForceResync: "2024-01-02T00:00:00Z",
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "force resync annotation is unchanged",
+			old: &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						objectmeta.TrafficManagerProfileAnnotationForceResync: "2024-01-01T00:00:00Z",
+					},
+				},
+			},
+			new: &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						objectmeta.TrafficManagerProfileAnnotationForceResync: "2024-01-01T00:00:00Z",
+					},
+				},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldHandleTrafficManagerProfileUpdateEvent(tt.old, tt.new); got != tt.want {
+				t.Errorf("shouldHandleTrafficManagerProfileUpdateEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+func TestShouldHandleInternalServiceExportUpdateEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		old  *fleetnetv1alpha1.InternalServiceExport
+		new  *fleetnetv1alpha1.InternalServiceExport
+		want bool
+	}{
+		{
+			name: "specs are the same",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+					Weight:                 ptr.To(int64(100)),
+					ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID:       "cluster-1",
+						Kind:            "Service",
+						Namespace:       "default",
+						Name:            "service-1",
+						NamespacedName:  "default/service-1",
+						ResourceVersion: "123",
+						Generation:      1,
+						UID:             "uid-1",
+					},
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+					Weight:                 ptr.To(int64(100)),
+					ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+						ClusterID:       "cluster-1",
+						Kind:            "Service",
+						Namespace:       "default",
+						Name:            "service-1",
+						NamespacedName:  "default/service-1",
+						ResourceVersion: "123",
+						Generation:      1,
+						UID:             "uid-1",
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "service type changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeClusterIP,
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "ports changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Ports: []fleetnetv1alpha1.ServicePort{
+						{Protocol: corev1.ProtocolTCP, Port: 80},
+					},
+					Type: corev1.ServiceTypeLoadBalancer,
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Ports: []fleetnetv1alpha1.ServicePort{
+						{Protocol: corev1.ProtocolTCP, Port: 443},
+					},
+					Type: corev1.ServiceTypeLoadBalancer,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "public IP resource ID changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-2"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "DNS label configuration changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   false,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "internal load balancer flag changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: true,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "weight changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+					Weight:                 ptr.To(int64(100)),
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+					Weight:                 ptr.To(int64(200)),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "weight changed from nil to value",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+					Weight:                 nil,
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+					Weight:                 ptr.To(int64(100)),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "ready endpoint count changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                 corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:   ptr.To("resource-id-1"),
+					IsDNSLabelConfigured: true,
+					Weight:               ptr.To(int64(100)),
+					ReadyEndpointCount:   ptr.To(int64(3)),
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                 corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:   ptr.To("resource-id-1"),
+					IsDNSLabelConfigured: true,
+					Weight:               ptr.To(int64(100)),
+					ReadyEndpointCount:   ptr.To(int64(5)),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "endpoint disabled annotation changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                 corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:   ptr.To("resource-id-1"),
+					IsDNSLabelConfigured: true,
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						objectmeta.InternalServiceExportAnnotationEndpointDisabled: "true",
+					},
+				},
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                 corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:   ptr.To("resource-id-1"),
+					IsDNSLabelConfigured: true,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "always serve annotation changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                 corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:   ptr.To("resource-id-1"),
+					IsDNSLabelConfigured: true,
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						objectmeta.ServiceExportAnnotationAlwaysServe: "true",
+					},
+				},
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                 corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:   ptr.To("resource-id-1"),
+					IsDNSLabelConfigured: true,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "priority changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+					Priority:               ptr.To(int64(1)),
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+					Priority:               ptr.To(int64(2)),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "geo mapping annotation changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						objectmeta.ServiceExportAnnotationGeoMapping: "US",
+					},
+				},
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						objectmeta.ServiceExportAnnotationGeoMapping: "US,CA",
+					},
+				},
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "public IP resource ID changed from nil to value",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     nil,
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			want: true,
+		},
+		{
+			name: "external target FQDN changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:               corev1.ServiceTypeNodePort,
+					ExternalTargetFQDN: ptr.To("app.example.com"),
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:               corev1.ServiceTypeNodePort,
+					ExternalTargetFQDN: ptr.To("other.example.com"),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "external target IP changed from nil to value",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type: corev1.ServiceTypeNodePort,
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:             corev1.ServiceTypeNodePort,
+					ExternalTargetIP: ptr.To("20.1.2.3"),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "region changed",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                 corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:   ptr.To("resource-id-1"),
+					IsDNSLabelConfigured: true,
+					Region:               ptr.To("eastus"),
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                 corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:   ptr.To("resource-id-1"),
+					IsDNSLabelConfigured: true,
+					Region:               ptr.To("westus"),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "weight changed from absolute to percentage",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:   corev1.ServiceTypeLoadBalancer,
+					Weight: ptr.To(int64(30)),
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:             corev1.ServiceTypeLoadBalancer,
+					Weight:           ptr.To(int64(30)),
+					WeightPercentage: ptr.To(int64(30)),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "public IP resource ID changed from value to nil",
+			old: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     ptr.To("resource-id-1"),
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			new: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                   corev1.ServiceTypeLoadBalancer,
+					PublicIPResourceID:     nil,
+					IsDNSLabelConfigured:   true,
+					IsInternalLoadBalancer: false,
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldHandleInternalServiceExportUpdateEvent(tt.old, tt.new); got != tt.want {
+				t.Errorf("shouldHandleInternalServiceExportUpdateEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanupEndpoints(t *testing.T) {
+	originalBackoff := deleteEndpointThrottledBackoff
+	deleteEndpointThrottledBackoff = wait.Backoff{Steps: 3, Duration: time.Millisecond}
+	defer func() { deleteEndpointThrottledBackoff = originalBackoff }()
+
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+	}
+	tests := []struct {
+		name                 string
+		maxConcurrentDeletes int
+		numberOfEndpoints    int
+		throttledAttempts    int // number of throttled responses before the endpoint is deleted
+		wantErr              bool
+	}{
+		{
+			name:              "delete endpoints using the default concurrency",
+			numberOfEndpoints: 25,
+		},
+		{
+			name:                 "retry throttled requests",
+			maxConcurrentDeletes: 3,
+			numberOfEndpoints:    10,
+			throttledAttempts:    2,
+		},
+		{
+			name:                 "give up when requests are always throttled",
+			maxConcurrentDeletes: 3,
+			numberOfEndpoints:    2,
+			throttledAttempts:    3,
+			wantErr:              true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var inflight, maxInflight int
+			attempts := make(map[string]int)
+			deleted := make(map[string]armtrafficmanager.EndpointType)
+			fakeServer := armtrafficmanagerfake.EndpointsServer{
+				Delete: func(_ context.Context, _ string, _ string, endpointType armtrafficmanager.EndpointType, endpointName string, _ *armtrafficmanager.EndpointsClientDeleteOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientDeleteResponse], errResp azcorefake.ErrorResponder) {
+					mu.Lock()
+					inflight++
+					maxInflight = max(maxInflight, inflight)
+					attempts[endpointName]++
+					attempt := attempts[endpointName]
+					mu.Unlock()
+
+					time.Sleep(5 * time.Millisecond) // make sure the requests overlap with each other
+					mu.Lock()
+					defer mu.Unlock()
+					inflight--
+					if attempt <= tt.throttledAttempts {
+						errResp.SetResponseError(http.StatusTooManyRequests, "TooManyRequests")
+						return resp, errResp
+					}
+					deleted[endpointName] = endpointType
+					resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientDeleteResponse{}, nil)
+					return resp, errResp
+				},
+			}
+			clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+				&arm.ClientOptions{
+					ClientOptions: azcore.ClientOptions{
+						Transport: armtrafficmanagerfake.NewEndpointsServerTransport(&fakeServer),
+						Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+					},
+				})
+			if err != nil {
+				t.Fatalf("failed to create the client factory: %v", err)
+			}
+			r := &Reconciler{
+				MaxConcurrentEndpointDeletes: tt.maxConcurrentDeletes,
+			}
+			clients := &azureclient.TrafficManagerClients{EndpointsClient: clientFactory.NewEndpointsClient()}
+
+			atmProfile := &armtrafficmanager.Profile{
+				Name: ptr.To("test-profile"),
+				Properties: &armtrafficmanager.ProfileProperties{
+					Endpoints: []*armtrafficmanager.Endpoint{
+						{Name: ptr.To("fleet-other-uid#test-import#cluster")}, // not owned by the backend
+					},
+				},
+			}
+			wantDeleted := make(map[string]armtrafficmanager.EndpointType, tt.numberOfEndpoints)
+			for i := 0; i < tt.numberOfEndpoints; i++ {
+				name := fmt.Sprintf("fleet-uid#test-import#cluster-%d", i)
+				// The endpoints must be deleted by their own types.
+				endpointType, resourceType := armtrafficmanager.EndpointTypeAzureEndpoints, "Microsoft.Network/trafficManagerProfiles/azureEndpoints"
+				if i%2 == 1 {
+					endpointType, resourceType = armtrafficmanager.EndpointTypeExternalEndpoints, "Microsoft.Network/trafficManagerProfiles/externalEndpoints"
+				}
+				atmProfile.Properties.Endpoints = append(atmProfile.Properties.Endpoints, &armtrafficmanager.Endpoint{
+					Name: ptr.To(name),
+					Type: ptr.To(resourceType),
+				})
+				wantDeleted[name] = endpointType
+			}
+
+			err = r.cleanupEndpoints(context.Background(), clients, "test-rg", backend, atmProfile)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("cleanupEndpoints() got error %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(wantDeleted, deleted); diff != "" {
+				t.Errorf("cleanupEndpoints() deleted endpoints mismatch (-want +got):\n%s", diff)
+			}
+			wantMaxConcurrentDeletes := tt.maxConcurrentDeletes
+			if wantMaxConcurrentDeletes == 0 {
+				wantMaxConcurrentDeletes = DefaultMaxConcurrentEndpointDeletes
+			}
+			if maxInflight > wantMaxConcurrentDeletes {
+				t.Errorf("cleanupEndpoints() sent %d concurrent requests, want no more than %d", maxInflight, wantMaxConcurrentDeletes)
+			}
+		})
+	}
+}
+
+func TestHandleDelete_AzureError(t *testing.T) {
+	tests := []struct {
+		name          string
+		getErr        *azcore.ResponseError
+		wantErr       bool
+		wantFinalizer bool
+		wantEvent     string
+	}{
+		{
+			name:          "unrecoverable error",
+			getErr:        &azcore.ResponseError{StatusCode: http.StatusUnauthorized, ErrorCode: "InvalidAuthenticationToken"},
+			wantErr:       false,
+			wantFinalizer: false,
+			wantEvent:     backendEventReasonDeletionSkipped,
+		},
+		{
+			name:          "retriable error",
+			getErr:        &azcore.ResponseError{StatusCode: http.StatusInternalServerError},
+			wantErr:       true,
+			wantFinalizer: true,
+			wantEvent:     backendEventReasonAzureAPIError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeServer := armtrafficmanagerfake.ProfilesServer{
+				Get: func(_ context.Context, _ string, _ string, _ *armtrafficmanager.ProfilesClientGetOptions) (resp azcorefake.Responder[armtrafficmanager.ProfilesClientGetResponse], errResp azcorefake.ErrorResponder) {
+					errResp.SetResponseError(tt.getErr.StatusCode, tt.getErr.ErrorCode)
+					return resp, errResp
+				},
+			}
+			clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+				&arm.ClientOptions{
+					ClientOptions: azcore.ClientOptions{
+						Transport: armtrafficmanagerfake.NewProfilesServerTransport(&fakeServer),
+						Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+					},
+				})
+			if err != nil {
+				t.Fatalf("failed to create the client factory: %v", err)
+			}
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-backend",
+					Namespace:         "test-ns",
+					UID:               "uid",
+					DeletionTimestamp: ptr.To(metav1.Now()),
+					Finalizers:        []string{objectmeta.TrafficManagerBackendFinalizer},
+				},
+				Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+					Profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "test-profile"},
+				},
+			}
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-profile",
+					Namespace: "test-ns",
+				},
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					ResourceGroup: "test-rg",
+				},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(backend, profile).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &Reconciler{
+				Client:             fakeClient,
+				AzureClientFactory: azureclient.NewTrafficManagerClientFactoryForClients(clientFactory.NewProfilesClient(), nil),
+				Recorder:           recorder,
+			}
+
+			_, err = r.handleDelete(context.Background(), backend)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("handleDelete() got error %v, want error %v", err, tt.wantErr)
+			}
+			got := &fleetnetv1beta1.TrafficManagerBackend{}
+			getErr := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}, got)
+			if client.IgnoreNotFound(getErr) != nil {
+				t.Fatalf("failed to get the backend: %v", getErr)
+			}
+			// The fake client deletes the object when all the finalizers are removed.
+			if gotFinalizer := getErr == nil; gotFinalizer != tt.wantFinalizer {
+				t.Errorf("handleDelete() got finalizer %v, want %v", gotFinalizer, tt.wantFinalizer)
+			}
+			select {
+			case event := <-recorder.Events:
+				if !strings.Contains(event, tt.wantEvent) {
+					t.Errorf("handleDelete() got event %q, want reason %q", event, tt.wantEvent)
+				}
+			default:
+				t.Errorf("handleDelete() got no event, want reason %q", tt.wantEvent)
+			}
+		})
+	}
+}
+
+func TestHandleDelete_NoEndpointsCreated(t *testing.T) {
+	tests := []struct {
+		name             string
+		endpointsCreated *bool
+		endpoints        []fleetnetv1beta1.TrafficManagerEndpointStatus
+		wantAzureCalls   int
+		wantErr          bool
+		wantFinalizer    bool
+		wantEvent        string
+	}{
+		{
+			name:             "no endpoints created",
+			endpointsCreated: ptr.To(false),
+			wantAzureCalls:   0,
+			wantFinalizer:    false,
+			wantEvent:        backendEventReasonDeleted,
+		},
+		{
+			name:             "endpoints created",
+			endpointsCreated: ptr.To(true),
+			wantAzureCalls:   1,
+			wantErr:          true,
+			wantFinalizer:    true,
+			wantEvent:        backendEventReasonAzureAPIError,
+		},
+		{
+			name:           "not recorded by the previous versions",
+			wantAzureCalls: 1,
+			wantErr:        true,
+			wantFinalizer:  true,
+			wantEvent:      backendEventReasonAzureAPIError,
+		},
+		{
+			name:             "accepted endpoints in the status",
+			endpointsCreated: ptr.To(false),
+			endpoints:        []fleetnetv1beta1.TrafficManagerEndpointStatus{{Name: "fleet-uid#test-import#member-1"}},
+			wantAzureCalls:   1,
+			wantErr:          true,
+			wantFinalizer:    true,
+			wantEvent:        backendEventReasonAzureAPIError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			fakeServer := armtrafficmanagerfake.ProfilesServer{
+				Get: func(_ context.Context, _ string, _ string, _ *armtrafficmanager.ProfilesClientGetOptions) (resp azcorefake.Responder[armtrafficmanager.ProfilesClientGetResponse], errResp azcorefake.ErrorResponder) {
+					calls++
+					errResp.SetResponseError(http.StatusForbidden, "AuthorizationFailed")
+					return resp, errResp
+				},
+			}
+			clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+				&arm.ClientOptions{
+					ClientOptions: azcore.ClientOptions{
+						Transport: armtrafficmanagerfake.NewProfilesServerTransport(&fakeServer),
+						Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+					},
+				})
+			if err != nil {
+				t.Fatalf("failed to create the client factory: %v", err)
+			}
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-backend",
+					Namespace:         "test-ns",
+					UID:               "uid",
+					DeletionTimestamp: ptr.To(metav1.Now()),
+					Finalizers:        []string{objectmeta.TrafficManagerBackendFinalizer},
+				},
+				Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+					Profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "test-profile"},
+				},
+				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+					EndpointsCreated: tt.endpointsCreated,
+					Endpoints:        tt.endpoints,
+				},
+			}
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-profile",
+					Namespace: "test-ns",
+				},
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					ResourceGroup: "test-rg",
+				},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(backend, profile).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &Reconciler{
+				Client:             fakeClient,
+				AzureClientFactory: azureclient.NewTrafficManagerClientFactoryForClients(clientFactory.NewProfilesClient(), nil),
+				Recorder:           recorder,
+			}
+
+			_, err = r.handleDelete(context.Background(), backend)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("handleDelete() got error %v, want error %v", err, tt.wantErr)
+			}
+			if calls != tt.wantAzureCalls {
+				t.Errorf("handleDelete() sent %d Azure requests, want %d", calls, tt.wantAzureCalls)
+			}
+			got := &fleetnetv1beta1.TrafficManagerBackend{}
+			getErr := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}, got)
+			if client.IgnoreNotFound(getErr) != nil {
+				t.Fatalf("failed to get the backend: %v", getErr)
+			}
+			// The fake client deletes the object when all the finalizers are removed.
+			if gotFinalizer := getErr == nil; gotFinalizer != tt.wantFinalizer {
+				t.Errorf("handleDelete() got finalizer %v, want %v", gotFinalizer, tt.wantFinalizer)
+			}
+			select {
+			case event := <-recorder.Events:
+				if !strings.Contains(event, tt.wantEvent) {
+					t.Errorf("handleDelete() got event %q, want reason %q", event, tt.wantEvent)
+				}
+			default:
+				t.Errorf("handleDelete() got no event, want reason %q", tt.wantEvent)
+			}
+		})
+	}
+}
+
+func TestHandleDelete_Drain(t *testing.T) {
+	endpointName := "fleet-uid#test-import#member-1"
+	tests := []struct {
+		name              string
+		drainGracePeriod  string
+		drainStartTime    *metav1.Time
+		endpointStatus    armtrafficmanager.EndpointStatus
+		wantDisabled      bool
+		wantDeleted       bool
+		wantRequeue       bool
+		wantFinalizer     bool
+		wantDrainStarted  bool
+		wantRequeueAtMost time.Duration
+	}{
+		{
+			name:              "disabling the endpoints and waiting for the grace period",
+			drainGracePeriod:  "5m",
+			endpointStatus:    armtrafficmanager.EndpointStatusEnabled,
+			wantDisabled:      true,
+			wantRequeue:       true,
+			wantFinalizer:     true,
+			wantDrainStarted:  true,
+			wantRequeueAtMost: 5 * time.Minute,
+		},
+		{
+			name:              "waiting for the remaining grace period",
+			drainGracePeriod:  "5m",
+			drainStartTime:    ptr.To(metav1.NewTime(time.Now().Add(-2 * time.Minute))),
+			endpointStatus:    armtrafficmanager.EndpointStatusDisabled,
+			wantRequeue:       true,
+			wantFinalizer:     true,
+			wantDrainStarted:  true,
+			wantRequeueAtMost: 3 * time.Minute,
+		},
+		{
+			name:             "deleting the endpoints after the grace period",
+			drainGracePeriod: "5m",
+			drainStartTime:   ptr.To(metav1.NewTime(time.Now().Add(-6 * time.Minute))),
+			endpointStatus:   armtrafficmanager.EndpointStatusDisabled,
+			wantDeleted:      true,
+		},
+		{
+			name:             "deleting the endpoints without draining when the grace period is invalid",
+			drainGracePeriod: "invalid",
+			endpointStatus:   armtrafficmanager.EndpointStatusEnabled,
+			wantDeleted:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotDisabled, gotDeleted bool
+			profilesServer := armtrafficmanagerfake.ProfilesServer{
+				Get: func(_ context.Context, _ string, profileName string, _ *armtrafficmanager.ProfilesClientGetOptions) (resp azcorefake.Responder[armtrafficmanager.ProfilesClientGetResponse], errResp azcorefake.ErrorResponder) {
+					resp.SetResponse(http.StatusOK, armtrafficmanager.ProfilesClientGetResponse{
+						Profile: armtrafficmanager.Profile{
+							Name: ptr.To(profileName),
+							Properties: &armtrafficmanager.ProfileProperties{
+								Endpoints: []*armtrafficmanager.Endpoint{
+									{
+										Name: ptr.To(endpointName),
+										Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+										Properties: &armtrafficmanager.EndpointProperties{
+											EndpointStatus: ptr.To(tt.endpointStatus),
+											Weight:         ptr.To(int64(100)),
+										},
+									},
+									{
+										Name: ptr.To("other-endpoint"),
+										Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+										Properties: &armtrafficmanager.EndpointProperties{
+											EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled),
+										},
+									},
+								},
+							},
+						},
+					}, nil)
+					return resp, errResp
+				},
+			}
+			endpointsServer := armtrafficmanagerfake.EndpointsServer{
+				CreateOrUpdate: func(_ context.Context, _ string, _ string, _ armtrafficmanager.EndpointType, name string, parameters armtrafficmanager.Endpoint, _ *armtrafficmanager.EndpointsClientCreateOrUpdateOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientCreateOrUpdateResponse], errResp azcorefake.ErrorResponder) {
+					if name != endpointName {
+						t.Errorf("CreateOrUpdate() got endpoint %q, want %q", name, endpointName)
+					}
+					if got := ptr.Deref(parameters.Properties.EndpointStatus, ""); got != armtrafficmanager.EndpointStatusDisabled {
+						t.Errorf("CreateOrUpdate() got endpoint status %q, want %q", got, armtrafficmanager.EndpointStatusDisabled)
+					}
+					if got := ptr.Deref(parameters.Properties.Weight, 0); got != 100 {
+						t.Errorf("CreateOrUpdate() got endpoint weight %d, want 100", got)
+					}
+					gotDisabled = true
+					resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientCreateOrUpdateResponse{Endpoint: parameters}, nil)
+					return resp, errResp
+				},
+				Delete: func(_ context.Context, _ string, _ string, _ armtrafficmanager.EndpointType, name string, _ *armtrafficmanager.EndpointsClientDeleteOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientDeleteResponse], errResp azcorefake.ErrorResponder) {
+					if name != endpointName {
+						t.Errorf("Delete() got endpoint %q, want %q", name, endpointName)
+					}
+					gotDeleted = true
+					resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientDeleteResponse{}, nil)
+					return resp, errResp
+				},
+			}
+			clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+				&arm.ClientOptions{
+					ClientOptions: azcore.ClientOptions{
+						Transport: armtrafficmanagerfake.NewServerFactoryTransport(&armtrafficmanagerfake.ServerFactory{
+							ProfilesServer:  profilesServer,
+							EndpointsServer: endpointsServer,
+						}),
+						Retry: policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+					},
+				})
+			if err != nil {
+				t.Fatalf("failed to create the client factory: %v", err)
+			}
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-backend",
+					Namespace:         "test-ns",
+					UID:               "uid",
+					DeletionTimestamp: ptr.To(metav1.Now()),
+					Finalizers:        []string{objectmeta.TrafficManagerBackendFinalizer},
+					Annotations:       map[string]string{objectmeta.TrafficManagerBackendAnnotationDrainGracePeriod: tt.drainGracePeriod},
+				},
+				Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+					Profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "test-profile"},
+				},
+				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+					DrainStartTime: tt.drainStartTime,
+				},
+			}
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-profile",
+					Namespace: "test-ns",
+				},
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					ResourceGroup: "test-rg",
+				},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(backend, profile).
+				WithStatusSubresource(backend).
+				WithInterceptorFuncs(interceptor.Funcs{SubResourcePatch: applyStatusForTest}).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{
+				Client:             fakeClient,
+				AzureClientFactory: azureclient.NewTrafficManagerClientFactoryForClients(clientFactory.NewProfilesClient(), clientFactory.NewEndpointsClient()),
+				Recorder:           record.NewFakeRecorder(10),
+			}
+
+			res, err := r.handleDelete(context.Background(), backend)
+			if err != nil {
+				t.Fatalf("handleDelete() got error %v, want nil", err)
+			}
+			if gotRequeue := res.RequeueAfter > 0; gotRequeue != tt.wantRequeue {
+				t.Errorf("handleDelete() got requeueAfter %v, want requeue %v", res.RequeueAfter, tt.wantRequeue)
+			}
+			if tt.wantRequeue && res.RequeueAfter > tt.wantRequeueAtMost {
+				t.Errorf("handleDelete() got requeueAfter %v, want at most %v", res.RequeueAfter, tt.wantRequeueAtMost)
+			}
+			if gotDisabled != tt.wantDisabled {
+				t.Errorf("handleDelete() disabled the endpoint %v, want %v", gotDisabled, tt.wantDisabled)
+			}
+			if gotDeleted != tt.wantDeleted {
+				t.Errorf("handleDelete() deleted the endpoint %v, want %v", gotDeleted, tt.wantDeleted)
+			}
+			got := &fleetnetv1beta1.TrafficManagerBackend{}
+			getErr := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}, got)
+			if client.IgnoreNotFound(getErr) != nil {
+				t.Fatalf("failed to get the backend: %v", getErr)
+			}
+			// The fake client deletes the object when all the finalizers are removed.
+			if gotFinalizer := getErr == nil; gotFinalizer != tt.wantFinalizer {
+				t.Errorf("handleDelete() got finalizer %v, want %v", gotFinalizer, tt.wantFinalizer)
+			}
+			if getErr == nil {
+				if gotDrainStarted := got.Status.DrainStartTime != nil; gotDrainStarted != tt.wantDrainStarted {
+					t.Errorf("handleDelete() got drainStartTime %v, want set %v", got.Status.DrainStartTime, tt.wantDrainStarted)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleDelete_UpdateConflict(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-backend",
+			Namespace:         "test-ns",
+			UID:               "uid",
+			DeletionTimestamp: ptr.To(metav1.Now()),
+			Finalizers:        []string{objectmeta.MetricsFinalizer, objectmeta.TrafficManagerBackendFinalizer},
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			// The profile does not exist and there are no Azure resources to delete.
+			Profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "test-profile"},
+		},
+	}
+	updateCalls := 0
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(backend).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				updateCalls++
+				if updateCalls == 1 {
+					return apierrors.NewConflict(schema.GroupResource{Resource: "trafficmanagerbackends"}, obj.GetName(), errors.New("conflict"))
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: recorder,
+	}
+	defer trafficManagerBackendEndpoints.Reset()
+	emitTrafficManagerBackendEndpointsMetric(backend, 1, 0, 0)
+
+	// The first attempt fails to remove the backend finalizer because of the conflict.
+	if _, err := r.handleDelete(context.Background(), backend.DeepCopy()); err == nil {
+		t.Fatalf("handleDelete() got nil error, want conflict error")
+	}
+	if got := len(recorder.Events); got != 0 {
+		t.Errorf("handleDelete() got %d events after the conflict, want 0", got)
+	}
+	if got := testutil.CollectAndCount(trafficManagerBackendEndpoints); got == 0 {
+		t.Errorf("handleDelete() deleted the metrics before the backend finalizer is removed")
+	}
+
+	// The retry removes both finalizers and emits the event once.
+	latest := &fleetnetv1beta1.TrafficManagerBackend{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}, latest); err != nil {
+		t.Fatalf("failed to get the backend: %v", err)
+	}
+	if _, err := r.handleDelete(context.Background(), latest); err != nil {
+		t.Fatalf("handleDelete() got error %v, want nil", err)
+	}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}, latest); !apierrors.IsNotFound(err) {
+		t.Errorf("handleDelete() got backend with finalizers %v, want the backend deleted", latest.Finalizers)
+	}
+	if got := len(recorder.Events); got != 1 {
+		t.Fatalf("handleDelete() got %d events, want 1", got)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, backendEventReasonDeleted) {
+		t.Errorf("handleDelete() got event %q, want reason %q", event, backendEventReasonDeleted)
+	}
+	if got := testutil.CollectAndCount(trafficManagerBackendEndpoints); got != 0 {
+		t.Errorf("handleDelete() left %d metrics behind, want 0", got)
+	}
+}
+
+func TestHandleDelete_OrphanAzureResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-profile",
+			Namespace: "test-ns",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{ResourceGroup: "rg"},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-backend",
+			Namespace:         "test-ns",
+			UID:               "uid",
+			DeletionTimestamp: ptr.To(metav1.Now()),
+			Finalizers:        []string{objectmeta.MetricsFinalizer, objectmeta.TrafficManagerBackendFinalizer},
+			Annotations:       map[string]string{objectmeta.TrafficManagerBackendAnnotationDrainGracePeriod: "5m"},
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "test-profile"},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile, backend).Build()
+	recorder := record.NewFakeRecorder(10)
+	// The Azure clients are not set so that any call to the Azure Traffic Manager fails the test.
+	r := &Reconciler{
+		Client:                       fakeClient,
+		Recorder:                     recorder,
+		OrphanAzureResourcesOnDelete: true,
+	}
+	res, err := r.handleDelete(context.Background(), backend)
+	if err != nil {
+		t.Fatalf("handleDelete() got error %v, want nil", err)
+	}
+	if res.RequeueAfter != 0 {
+		t.Errorf("handleDelete() got requeueAfter %v, want 0", res.RequeueAfter)
+	}
+	got := &fleetnetv1beta1.TrafficManagerBackend{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}, got); !apierrors.IsNotFound(err) {
+		t.Errorf("handleDelete() got backend with finalizers %v, want the backend deleted", got.Finalizers)
+	}
+	if got := len(recorder.Events); got != 1 {
+		t.Fatalf("handleDelete() got %d events, want 1", got)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, backendEventReasonOrphaned) {
+		t.Errorf("handleDelete() got event %q, want reason %q", event, backendEventReasonOrphaned)
+	}
+}
+
+func TestIsReconciliationPaused(t *testing.T) {
+	pauseConfigMap := types.NamespacedName{Namespace: "fleet-system", Name: "pause"}
+	tests := []struct {
+		name           string
+		pauseConfigMap types.NamespacedName
+		configMap      *corev1.ConfigMap
+		want           bool
+	}{
+		{
+			name: "kill switch disabled",
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-system", Name: "pause"},
+				Data:       map[string]string{pausedConfigMapKey: "true"},
+			},
+		},
+		{
+			name:           "configMap not found",
+			pauseConfigMap: pauseConfigMap,
+		},
+		{
+			name:           "paused key not set",
+			pauseConfigMap: pauseConfigMap,
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-system", Name: "pause"},
+			},
+		},
+		{
+			name:           "paused key set to false",
+			pauseConfigMap: pauseConfigMap,
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-system", Name: "pause"},
+				Data:       map[string]string{pausedConfigMapKey: "false"},
+			},
+		},
+		{
+			name:           "paused key set to an invalid value",
+			pauseConfigMap: pauseConfigMap,
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-system", Name: "pause"},
+				Data:       map[string]string{pausedConfigMapKey: "yes"},
+			},
+		},
+		{
+			name:           "paused key set to true",
+			pauseConfigMap: pauseConfigMap,
+			configMap: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-system", Name: "pause"},
+				Data:       map[string]string{pausedConfigMapKey: "true"},
+			},
+			want: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			if tc.configMap != nil {
+				builder = builder.WithObjects(tc.configMap)
+			}
+			r := &Reconciler{Client: builder.Build(), PauseConfigMap: tc.pauseConfigMap}
+			got, err := r.isReconciliationPaused(context.Background())
+			if err != nil {
+				t.Fatalf("isReconciliationPaused() got error %v, want nil", err)
+			}
+			if got != tc.want {
+				t.Errorf("isReconciliationPaused() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReconcile_Paused(t *testing.T) {
+	pauseConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "fleet-system", Name: "pause"},
+		Data:       map[string]string{pausedConfigMapKey: "true"},
+	}
+	endpoints := []fleetnetv1beta1.TrafficManagerEndpointStatus{
+		{
+			Name:   "fleet-uid#test-import#member-1",
+			Weight: ptr.To[int64](100),
+			From: &fleetnetv1beta1.FromCluster{
+				ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "member-1"},
+			},
+		},
+	}
+	message := fmt.Sprintf("Reconciliation is paused by the configMap %q and the existing Azure Traffic Manager endpoints are left untouched", "fleet-system/pause")
+	tests := []struct {
+		name            string
+		backend         *fleetnetv1beta1.TrafficManagerBackend
+		wantStatus      *fleetnetv1beta1.TrafficManagerBackendStatus
+		wantStatusWrite bool
+	}{
+		{
+			name: "accepted backend",
+			backend: &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-backend",
+					Namespace:  "test-ns",
+					UID:        "uid",
+					Generation: 2,
+					Finalizers: []string{objectmeta.TrafficManagerBackendFinalizer},
+				},
+				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+							Status:             metav1.ConditionTrue,
+							Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonAccepted),
+							ObservedGeneration: 1,
+						},
+					},
+					Endpoints: endpoints,
+				},
+			},
+			wantStatus: &fleetnetv1beta1.TrafficManagerBackendStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+						Status:             metav1.ConditionFalse,
+						Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonReconciliationPaused),
+						Message:            message,
+						ObservedGeneration: 2,
+					},
+					{
+						Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+						Status:             metav1.ConditionFalse,
+						Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonNotAccepted),
+						Message:            fmt.Sprintf("The Accepted condition is False with reason %q: %s", fleetnetv1beta1.TrafficManagerBackendReasonReconciliationPaused, message),
+						ObservedGeneration: 2,
+					},
+				},
+				Endpoints: endpoints,
+			},
+			wantStatusWrite: true,
+		},
+		{
+			name: "paused condition already reported",
+			backend: &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-backend",
+					Namespace:  "test-ns",
+					UID:        "uid",
+					Generation: 2,
+					Finalizers: []string{objectmeta.TrafficManagerBackendFinalizer},
+				},
+				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
 					Conditions: []metav1.Condition{
 						{
-							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
-							Status: metav1.ConditionUnknown,
-							Reason: "Pending",
+							Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+							Status:             metav1.ConditionFalse,
+							Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonReconciliationPaused),
+							Message:            message,
+							ObservedGeneration: 2,
+						},
+					},
+					Endpoints: endpoints,
+				},
+			},
+			wantStatus: &fleetnetv1beta1.TrafficManagerBackendStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+						Status:             metav1.ConditionFalse,
+						Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonReconciliationPaused),
+						Message:            message,
+						ObservedGeneration: 2,
+					},
+				},
+				Endpoints: endpoints,
+			},
+		},
+		{
+			name: "deleting backend",
+			backend: &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-backend",
+					Namespace:         "test-ns",
+					UID:               "uid",
+					Generation:        1,
+					DeletionTimestamp: ptr.To(metav1.Now()),
+					Finalizers:        []string{objectmeta.TrafficManagerBackendFinalizer},
+				},
+				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+					Endpoints: endpoints,
+				},
+			},
+			wantStatus: &fleetnetv1beta1.TrafficManagerBackendStatus{
+				Endpoints: endpoints,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			statusWrites := 0
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(tc.backend, pauseConfigMap).
+				WithStatusSubresource(tc.backend).
+				WithInterceptorFuncs(interceptor.Funcs{
+					SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+						statusWrites++
+						return applyStatusForTest(ctx, c, subResourceName, obj, patch, opts...)
+					},
+				}).
+				Build()
+			// The Azure clients are not set so that any call to the Azure Traffic Manager fails the test.
+			r := &Reconciler{
+				Client:         fakeClient,
+				Recorder:       record.NewFakeRecorder(10),
+				PauseConfigMap: types.NamespacedName{Namespace: pauseConfigMap.Namespace, Name: pauseConfigMap.Name},
+			}
+			name := types.NamespacedName{Namespace: tc.backend.Namespace, Name: tc.backend.Name}
+			res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: name})
+			if err != nil {
+				t.Fatalf("Reconcile() got error %v, want nil", err)
+			}
+			if want := (ctrl.Result{RequeueAfter: pausedRequeueDelay}); res != want {
+				t.Errorf("Reconcile() = %+v, want %+v", res, want)
+			}
+			if gotStatusWrite := statusWrites > 0; gotStatusWrite != tc.wantStatusWrite {
+				t.Errorf("Reconcile() got status write %v, want %v", gotStatusWrite, tc.wantStatusWrite)
+			}
+			got := &fleetnetv1beta1.TrafficManagerBackend{}
+			if err := fakeClient.Get(context.Background(), name, got); err != nil {
+				t.Fatalf("failed to get backend: %v", err)
+			}
+			if diff := cmp.Diff(tc.wantStatus, &got.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Reconcile() status mismatch (-want, +got):\n%s", diff)
+			}
+			if !cmp.Equal(got.Finalizers, tc.backend.Finalizers) {
+				t.Errorf("Reconcile() got finalizers %v, want %v", got.Finalizers, tc.backend.Finalizers)
+			}
+		})
+	}
+}
+
+func TestReconcileOnce(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-backend",
+			Namespace:  "test-ns",
+			UID:        "uid",
+			Generation: 1,
+			Finalizers: []string{objectmeta.TrafficManagerBackendFinalizer},
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Profile: fleetnetv1beta1.TrafficManagerProfileRef{
+				Name: "not-found-profile",
+			},
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(100)),
+		},
+	}
+	tests := []struct {
+		name        string
+		backendName string
+		wantBackend bool
+	}{
+		{
+			name:        "backend is not found",
+			backendName: "not-found-backend",
+		},
+		{
+			name:        "backend with the profile not found",
+			backendName: backend.Name,
+			wantBackend: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(backend.DeepCopy()).
+				WithStatusSubresource(backend).
+				WithInterceptorFuncs(interceptor.Funcs{SubResourcePatch: applyStatusForTest}).
+				Build()
+			// The Azure clients are not set so that any call to the Azure Traffic Manager fails the test.
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			name := types.NamespacedName{Namespace: backend.Namespace, Name: tc.backendName}
+			countBefore := reconcileDurationSampleCountForTest(t, reconcileOutcomeSuccess)
+			got, res, err := r.ReconcileOnce(context.Background(), name)
+			if err != nil {
+				t.Fatalf("ReconcileOnce() got error %v, want nil", err)
+			}
+			if countAfter := reconcileDurationSampleCountForTest(t, reconcileOutcomeSuccess); countAfter != countBefore+1 {
+				t.Errorf("ReconcileOnce() observed %d successful reconcile durations, want 1", countAfter-countBefore)
+			}
+			if res != (ctrl.Result{}) {
+				t.Errorf("ReconcileOnce() got result %+v, want empty", res)
+			}
+			if gotBackend := got != nil; gotBackend != tc.wantBackend {
+				t.Fatalf("ReconcileOnce() got backend %v, want backend %v", gotBackend, tc.wantBackend)
+			}
+			if got == nil {
+				return
+			}
+			wantCondition := metav1.Condition{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+				Status:             metav1.ConditionFalse,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonInvalid),
+				Message:            `TrafficManagerProfile "not-found-profile" is not found`,
+				ObservedGeneration: 1,
+			}
+			gotCondition := meta.FindStatusCondition(got.Status.Conditions, wantCondition.Type)
+			if diff := cmp.Diff(&wantCondition, gotCondition, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("ReconcileOnce() accepted condition mismatch (-want, +got):\n%s", diff)
+			}
+			// The returned status is the same as the one written to the backend.
+			stored := &fleetnetv1beta1.TrafficManagerBackend{}
+			if err := fakeClient.Get(context.Background(), name, stored); err != nil {
+				t.Fatalf("failed to get backend: %v", err)
+			}
+			if diff := cmp.Diff(stored.Status, got.Status, cmpopts.EquateEmpty(), cmpopts.EquateApproxTime(time.Second)); diff != "" {
+				t.Errorf("ReconcileOnce() status mismatch with the stored backend (-stored, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func reconcileDurationSampleCountForTest(t *testing.T, outcome string) uint64 {
+	m := &prometheusclientmodel.Metric{}
+	if err := trafficManagerBackendReconcileDurationSeconds.WithLabelValues(outcome).(prometheus.Histogram).Write(m); err != nil {
+		t.Fatalf("failed to write the reconcile duration metric: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestReconcileOutcome(t *testing.T) {
+	tests := []struct {
+		name   string
+		result ctrl.Result
+		err    error
+		want   string
+	}{
+		{
+			name: "success",
+			want: reconcileOutcomeSuccess,
+		},
+		{
+			name:   "requeue after",
+			result: ctrl.Result{RequeueAfter: time.Minute},
+			want:   reconcileOutcomeRequeue,
+		},
+		{
+			name:   "requeue",
+			result: ctrl.Result{Requeue: true},
+			want:   reconcileOutcomeRequeue,
+		},
+		{
+			name:   "error",
+			result: ctrl.Result{RequeueAfter: time.Minute},
+			err:    errors.New("test error"),
+			want:   reconcileOutcomeError,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := reconcileOutcome(tc.result, tc.err); got != tc.want {
+				t.Errorf("reconcileOutcome() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTrafficManagerProfileNamespacedName(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile fleetnetv1beta1.TrafficManagerProfileRef
+		want    types.NamespacedName
+	}{
+		{
+			name:    "profile in the same namespace",
+			profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "profile"},
+			want:    types.NamespacedName{Namespace: "backend-ns", Name: "profile"},
+		},
+		{
+			name:    "profile in another namespace",
+			profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "profile", Namespace: "profile-ns"},
+			want:    types.NamespacedName{Namespace: "profile-ns", Name: "profile"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "backend",
+					Namespace: "backend-ns",
+				},
+				Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+					Profile: tt.profile,
+				},
+			}
+			if got := trafficManagerProfileNamespacedName(backend); got != tt.want {
+				t.Errorf("trafficManagerProfileNamespacedName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleTrafficManagerProfileEvent(t *testing.T) {
+	backendForTest := func(namespace, name string, profile fleetnetv1beta1.TrafficManagerProfileRef) client.Object {
+		return &fleetnetv1beta1.TrafficManagerBackend{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+				Profile: profile,
+			},
+		}
+	}
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			backendForTest("profile-ns", "same-namespace", fleetnetv1beta1.TrafficManagerProfileRef{Name: "profile"}),
+			backendForTest("team-ns", "cross-namespace", fleetnetv1beta1.TrafficManagerProfileRef{Name: "profile", Namespace: "profile-ns"}),
+			backendForTest("team-ns", "same-name-in-another-namespace", fleetnetv1beta1.TrafficManagerProfileRef{Name: "profile"}),
+			backendForTest("profile-ns", "another-profile", fleetnetv1beta1.TrafficManagerProfileRef{Name: "other-profile"}),
+			&fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "suspended",
+					Namespace: "profile-ns",
+				},
+				Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+					Profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "profile"},
+					Suspend: ptr.To(true),
+				},
+			},
+		).
+		WithIndex(&fleetnetv1beta1.TrafficManagerBackend{}, trafficManagerBackendProfileFieldKey, func(o client.Object) []string {
+			return []string{trafficManagerProfileNamespacedName(o.(*fleetnetv1beta1.TrafficManagerBackend)).String()}
+		}).
+		Build()
+	r := &Reconciler{Client: fakeClient}
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "profile",
+			Namespace: "profile-ns",
+		},
+	}
+	q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer q.ShutDown()
+	r.handleTrafficManagerProfileEvent(context.Background(), profile, q)
+
+	var got []reconcile.Request
+	for q.Len() > 0 {
+		item, _ := q.Get()
+		got = append(got, item)
+		q.Done(item)
+	}
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "profile-ns", Name: "same-namespace"}},
+		{NamespacedName: types.NamespacedName{Namespace: "team-ns", Name: "cross-namespace"}},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.SortSlices(func(a, b reconcile.Request) bool { return a.String() < b.String() })); diff != "" {
+		t.Errorf("handleTrafficManagerProfileEvent() requests mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHandleInternalServiceExportDeleteEvent(t *testing.T) {
+	backendForTest := func(name, serviceImport string, suspend *bool) client.Object {
+		return &fleetnetv1beta1.TrafficManagerBackend{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-ns",
+			},
+			Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+				Backend: fleetnetv1beta1.TrafficManagerBackendRef{Name: serviceImport},
+				Suspend: suspend,
+			},
+		}
+	}
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	// The serviceImport does not exist, as it may be deleted together with the last internalServiceExport.
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			backendForTest("backend", "test-import", nil),
+			backendForTest("another-backend", "other-import", nil),
+			backendForTest("suspended", "test-import", ptr.To(true)),
+		).
+		WithIndex(&fleetnetv1beta1.TrafficManagerBackend{}, trafficManagerBackendBackendFieldKey, func(o client.Object) []string {
+			return serviceImportNames(o.(*fleetnetv1beta1.TrafficManagerBackend))
+		}).
+		Build()
+	r := &Reconciler{Client: fakeClient}
+	internalServiceExport := &fleetnetv1alpha1.InternalServiceExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ns-test-import",
+			Namespace: "fleet-member-cluster-1",
+		},
+		Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+			ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
+				ClusterID: "cluster-1",
+				Namespace: "test-ns",
+				Name:      "test-import",
+			},
+		},
+	}
+	q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer q.ShutDown()
+	r.handleInternalServiceExportDeleteEvent(context.Background(), internalServiceExport, q)
+
+	var got []reconcile.Request
+	for q.Len() > 0 {
+		item, _ := q.Get()
+		got = append(got, item)
+		q.Done(item)
+	}
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "test-ns", Name: "backend"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("handleInternalServiceExportDeleteEvent() requests mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestApportionWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		weights map[string]int64
+		want    map[string]int64
+	}{
+		{
+			name:    "single cluster",
+			total:   10,
+			weights: map[string]int64{"cluster-1": 3},
+			want:    map[string]int64{"cluster-1": 10},
+		},
+		{
+			name:    "evenly divisible",
+			total:   100,
+			weights: map[string]int64{"cluster-1": 1, "cluster-2": 1},
+			want:    map[string]int64{"cluster-1": 50, "cluster-2": 50},
+		},
+		{
+			name:    "largest remainder gets the remaining weight",
+			total:   10,
+			weights: map[string]int64{"cluster-1": 2, "cluster-2": 1},
+			want:    map[string]int64{"cluster-1": 7, "cluster-2": 3},
+		},
+		{
+			name:    "multiple remaining weights",
+			total:   500,
+			weights: map[string]int64{"cluster-1": 100, "cluster-2": 200, "cluster-3": 400},
+			want:    map[string]int64{"cluster-1": 71, "cluster-2": 143, "cluster-3": 286},
+		},
+		{
+			name:    "ties are broken by the cluster name",
+			total:   10,
+			weights: map[string]int64{"cluster-3": 1, "cluster-2": 1, "cluster-1": 1},
+			want:    map[string]int64{"cluster-1": 4, "cluster-2": 3, "cluster-3": 3},
+		},
+		{
+			name:    "total weight less than the number of clusters",
+			total:   1,
+			weights: map[string]int64{"cluster-2": 1, "cluster-1": 1, "cluster-3": 1},
+			want:    map[string]int64{"cluster-1": 1, "cluster-2": 0, "cluster-3": 0},
+		},
+		{
+			name:    "cluster with zero weight",
+			total:   10,
+			weights: map[string]int64{"cluster-1": 0, "cluster-2": 1, "cluster-3": 2},
+			want:    map[string]int64{"cluster-1": 0, "cluster-2": 3, "cluster-3": 7},
+		},
+		{
+			name:    "zero total weight",
+			total:   0,
+			weights: map[string]int64{"cluster-1": 1, "cluster-2": 2},
+			want:    map[string]int64{"cluster-1": 0, "cluster-2": 0},
+		},
+		{
+			name:    "maximum weights",
+			total:   1000,
+			weights: map[string]int64{"cluster-1": 1000, "cluster-2": 999, "cluster-3": 1},
+			want:    map[string]int64{"cluster-1": 500, "cluster-2": 500, "cluster-3": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := apportionWeights(tt.total, tt.weights)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("apportionWeights() mismatch (-want +got):\n%s", diff)
+			}
+			var sum int64
+			for _, weight := range got {
+				sum += weight
+			}
+			if sum != tt.total {
+				t.Errorf("apportionWeights() sum of the weights = %d, want %d", sum, tt.total)
+			}
+		})
+	}
+}
+
+// TestApportionWeights_AzureMaxWeight verifies no endpoint weight exceeds the Azure Traffic Manager cap of 1000, as
+// the weights always add up to the backend weight, which is at most 1000.
+func TestApportionWeights_AzureMaxWeight(t *testing.T) {
+	const azureMaxEndpointWeight = 1000
+	manyClusters := make(map[string]int64, 200)
+	for i := 0; i < 200; i++ {
+		manyClusters[fmt.Sprintf("cluster-%d", i)] = int64(i%1000 + 1)
+	}
+	tests := []struct {
+		name        string
+		weights     map[string]int64
+		percentages map[string]int64
+	}{
+		{
+			name:    "single cluster with the maximum weight",
+			weights: map[string]int64{"cluster-1": 1000},
+		},
+		{
+			name:    "skewed weights",
+			weights: map[string]int64{"cluster-1": 1000, "cluster-2": 1},
+		},
+		{
+			name:    "many clusters",
+			weights: manyClusters,
+		},
+		{
+			name:        "single cluster with the full percentage",
+			percentages: map[string]int64{"cluster-1": 100},
+			weights:     map[string]int64{"cluster-2": 0},
+		},
+		{
+			name:        "percentages and weights",
+			percentages: map[string]int64{"cluster-1": 99},
+			weights:     map[string]int64{"cluster-2": 1000, "cluster-3": 1000},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := apportionWeightsWithPercentages(azureMaxEndpointWeight, tc.percentages, tc.weights)
+			var sum int64
+			for cluster, weight := range got {
+				if weight > azureMaxEndpointWeight {
+					t.Errorf("apportionWeightsWithPercentages() weight of %s = %d, want at most %d", cluster, weight, azureMaxEndpointWeight)
+				}
+				sum += weight
+			}
+			if sum != azureMaxEndpointWeight {
+				t.Errorf("apportionWeightsWithPercentages() sum of the weights = %d, want %d", sum, azureMaxEndpointWeight)
+			}
+		})
+	}
+}
+
+func TestApportionWeightsWithPercentages(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       int64
+		percentages map[string]int64
+		weights     map[string]int64
+		want        map[string]int64
+	}{
+		{
+			name:    "absolute weights only",
+			total:   10,
+			weights: map[string]int64{"cluster-1": 2, "cluster-2": 1},
+			want:    map[string]int64{"cluster-1": 7, "cluster-2": 3},
+		},
+		{
+			name:        "percentages only",
+			total:       100,
+			percentages: map[string]int64{"cluster-1": 30, "cluster-2": 70},
+			want:        map[string]int64{"cluster-1": 30, "cluster-2": 70},
+		},
+		{
+			name:        "percentages only and the sum is less than 100",
+			total:       100,
+			percentages: map[string]int64{"cluster-1": 10, "cluster-2": 30},
+			want:        map[string]int64{"cluster-1": 25, "cluster-2": 75},
+		},
+		{
+			name:        "absolute weights fill the remaining weight",
+			total:       100,
+			percentages: map[string]int64{"cluster-1": 30},
+			weights:     map[string]int64{"cluster-2": 1, "cluster-3": 1},
+			want:        map[string]int64{"cluster-1": 30, "cluster-2": 35, "cluster-3": 35},
+		},
+		{
+			name:        "remaining weight with rounding",
+			total:       10,
+			percentages: map[string]int64{"cluster-1": 25},
+			weights:     map[string]int64{"cluster-2": 2, "cluster-3": 1},
+			want:        map[string]int64{"cluster-1": 2, "cluster-2": 5, "cluster-3": 3},
+		},
+		{
+			name:        "percentages take the whole weight",
+			total:       100,
+			percentages: map[string]int64{"cluster-1": 40, "cluster-2": 60},
+			weights:     map[string]int64{"cluster-3": 5},
+			want:        map[string]int64{"cluster-1": 40, "cluster-2": 60, "cluster-3": 0},
+		},
+		{
+			name:        "absolute weights are zero",
+			total:       100,
+			percentages: map[string]int64{"cluster-1": 20},
+			weights:     map[string]int64{"cluster-2": 0},
+			want:        map[string]int64{"cluster-1": 100, "cluster-2": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := apportionWeightsWithPercentages(tt.total, tt.percentages, tt.weights)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("apportionWeightsWithPercentages() mismatch (-want +got):\n%s", diff)
+			}
+			var sum int64
+			for _, weight := range got {
+				sum += weight
+			}
+			if sum != tt.total {
+				t.Errorf("apportionWeightsWithPercentages() sum of the weights = %d, want %d", sum, tt.total)
+			}
+		})
+	}
+}
+
+func TestWeightBoundsFromPercentages(t *testing.T) {
+	tests := []struct {
+		name          string
+		total         int64
+		minPercentage *int64
+		maxPercentage *int64
+		want          weightBounds
+	}{
+		{
+			name:  "no bounds",
+			total: 10,
+			want:  weightBounds{min: 0, max: 10},
+		},
+		{
+			name:          "minimum is rounded up",
+			total:         10,
+			minPercentage: ptr.To(int64(15)),
+			want:          weightBounds{min: 2, max: 10},
+		},
+		{
+			name:          "maximum is rounded down",
+			total:         10,
+			maxPercentage: ptr.To(int64(55)),
+			want:          weightBounds{min: 0, max: 5},
+		},
+		{
+			name:          "both bounds",
+			total:         200,
+			minPercentage: ptr.To(int64(10)),
+			maxPercentage: ptr.To(int64(50)),
+			want:          weightBounds{min: 20, max: 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := weightBoundsFromPercentages(tt.total, tt.minPercentage, tt.maxPercentage)
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(weightBounds{})); diff != "" {
+				t.Errorf("weightBoundsFromPercentages() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClampWeightsToBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		weights map[string]int64
+		bounds  map[string]weightBounds
+		want    map[string]int64
+		wantErr bool
+	}{
+		{
+			name:    "no bounds",
+			total:   10,
+			weights: map[string]int64{"cluster-1": 7, "cluster-2": 3},
+			want:    map[string]int64{"cluster-1": 7, "cluster-2": 3},
+		},
+		{
+			name:    "weights within the bounds",
+			total:   10,
+			weights: map[string]int64{"cluster-1": 7, "cluster-2": 3},
+			bounds:  map[string]weightBounds{"cluster-2": {min: 1, max: 5}},
+			want:    map[string]int64{"cluster-1": 7, "cluster-2": 3},
+		},
+		{
+			name:    "weight is raised to the minimum",
+			total:   100,
+			weights: map[string]int64{"cluster-1": 90, "cluster-2": 10},
+			bounds:  map[string]weightBounds{"cluster-2": {min: 20, max: 100}},
+			want:    map[string]int64{"cluster-1": 80, "cluster-2": 20},
+		},
+		{
+			name:    "weight exceeding the maximum is redistributed in proportion",
+			total:   100,
+			weights: map[string]int64{"cluster-1": 60, "cluster-2": 30, "cluster-3": 10},
+			bounds:  map[string]weightBounds{"cluster-1": {min: 0, max: 50}},
+			want:    map[string]int64{"cluster-1": 50, "cluster-2": 38, "cluster-3": 12},
+		},
+		{
+			name:    "redistribution exceeds another maximum",
+			total:   100,
+			weights: map[string]int64{"cluster-1": 50, "cluster-2": 30, "cluster-3": 20},
+			bounds: map[string]weightBounds{
+				"cluster-1": {min: 0, max: 40},
+				"cluster-2": {min: 0, max: 35},
+			},
+			want: map[string]int64{"cluster-1": 40, "cluster-2": 35, "cluster-3": 25},
+		},
+		{
+			name:    "both minimum and maximum are violated",
+			total:   100,
+			weights: map[string]int64{"cluster-1": 80, "cluster-2": 15, "cluster-3": 5},
+			bounds: map[string]weightBounds{
+				"cluster-1": {min: 0, max: 60},
+				"cluster-3": {min: 10, max: 100},
+			},
+			want: map[string]int64{"cluster-1": 60, "cluster-2": 30, "cluster-3": 10},
+		},
+		{
+			name:    "all the clusters are clamped",
+			total:   100,
+			weights: map[string]int64{"cluster-1": 1, "cluster-2": 1},
+			bounds: map[string]weightBounds{
+				"cluster-1": {min: 70, max: 100},
+				"cluster-2": {min: 0, max: 30},
+			},
+			want: map[string]int64{"cluster-1": 70, "cluster-2": 30},
+		},
+		{
+			name:    "sum of the minimums exceeds the total",
+			total:   100,
+			weights: map[string]int64{"cluster-1": 1, "cluster-2": 1},
+			bounds: map[string]weightBounds{
+				"cluster-1": {min: 60, max: 100},
+				"cluster-2": {min: 50, max: 100},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "sum of the maximums is less than the total",
+			total:   100,
+			weights: map[string]int64{"cluster-1": 1, "cluster-2": 1},
+			bounds: map[string]weightBounds{
+				"cluster-1": {min: 0, max: 40},
+				"cluster-2": {min: 0, max: 50},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "minimum exceeds the maximum after rounding",
+			total:   1,
+			weights: map[string]int64{"cluster-1": 1, "cluster-2": 1},
+			bounds:  map[string]weightBounds{"cluster-1": {min: 1, max: 0}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := clampWeightsToBounds(tt.total, tt.weights, tt.bounds)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("clampWeightsToBounds() got error %v, want error %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("clampWeightsToBounds() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInvalidateExceededWeightPercentages(t *testing.T) {
+	newDesiredEndpoint := func(cluster string, percentage *int64) desiredEndpoint {
+		return desiredEndpoint{
+			Endpoint: armtrafficmanager.Endpoint{
+				Name: ptr.To("fleet-uid#service#" + cluster),
+			},
+			FromCluster: fleetnetv1beta1.FromCluster{
+				ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: cluster},
+			},
+			WeightPercentage: percentage,
+		}
+	}
+	tests := []struct {
+		name                string
+		desiredEndpoints    map[string]desiredEndpoint
+		wantEndpointNames   []string
+		wantInvalidServices map[string]string // key is the cluster name and value is the error message
+	}{
+		{
+			name: "sum of percentages is 100",
+			desiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#service#cluster-1": newDesiredEndpoint("cluster-1", ptr.To(int64(40))),
+				"fleet-uid#service#cluster-2": newDesiredEndpoint("cluster-2", ptr.To(int64(60))),
+				"fleet-uid#service#cluster-3": newDesiredEndpoint("cluster-3", nil),
+			},
+			wantEndpointNames: []string{"fleet-uid#service#cluster-1", "fleet-uid#service#cluster-2", "fleet-uid#service#cluster-3"},
+		},
+		{
+			name: "sum of percentages exceeds 100",
+			desiredEndpoints: map[string]desiredEndpoint{
+				"fleet-uid#service#cluster-1": newDesiredEndpoint("cluster-1", ptr.To(int64(40))),
+				"fleet-uid#service#cluster-2": newDesiredEndpoint("cluster-2", ptr.To(int64(70))),
+				"fleet-uid#service#cluster-3": newDesiredEndpoint("cluster-3", nil),
+			},
+			wantEndpointNames: []string{"fleet-uid#service#cluster-3"},
+			wantInvalidServices: map[string]string{
+				"cluster-1": "the sum of the weight percentages of the exported services is 110%, which exceeds 100%",
+				"cluster-2": "the sum of the weight percentages of the exported services is 110%, which exceeds 100%",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalidServices := make(map[string]error)
+			invalidateExceededWeightPercentages(tt.desiredEndpoints, invalidServices)
+			gotEndpointNames := make([]string, 0, len(tt.desiredEndpoints))
+			for name := range tt.desiredEndpoints {
+				gotEndpointNames = append(gotEndpointNames, name)
+			}
+			if diff := cmp.Diff(tt.wantEndpointNames, gotEndpointNames, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("invalidateExceededWeightPercentages() desiredEndpoints mismatch (-want +got):\n%s", diff)
+			}
+			gotInvalidServices := make(map[string]string, len(invalidServices))
+			for cluster, err := range invalidServices {
+				gotInvalidServices[cluster] = err.Error()
+			}
+			if diff := cmp.Diff(tt.wantInvalidServices, gotInvalidServices, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("invalidateExceededWeightPercentages() invalidServices mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_ZeroTotalWeight(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-backend",
+			Namespace:  "test-ns",
+			UID:        "uid",
+			Generation: 2,
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(500)),
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+			},
+		},
+	}
+	zeroWeightExport := func(cluster string) client.Object {
+		export := geographicInternalServiceExportForTest(cluster, "")
+		export.Spec.Weight = ptr.To(int64(0))
+		return export
+	}
+
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(backend, zeroWeightExport("cluster-1"), zeroWeightExport("cluster-2")).
+		WithStatusSubresource(backend).
+		WithInterceptorFuncs(interceptor.Funcs{SubResourcePatch: applyStatusForTest}).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	gotDesiredEndpoints, gotInvalidServices, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+	if err != nil {
+		t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+	}
+	if gotDesiredEndpoints != nil || gotInvalidServices != nil {
+		t.Errorf("validateAndProcessServiceImportForBackend() = %v, %v, want nil, nil", gotDesiredEndpoints, gotInvalidServices)
+	}
+
+	got := &fleetnetv1beta1.TrafficManagerBackend{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}, got); err != nil {
+		t.Fatalf("failed to get the trafficManagerBackend: %v", err)
+	}
+	wantStatus := fleetnetv1beta1.TrafficManagerBackendStatus{
+		Endpoints: []fleetnetv1beta1.TrafficManagerEndpointStatus{},
+		Conditions: []metav1.Condition{
+			{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: 2,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonZeroTotalWeight),
+			},
+			{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: 2,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonNotAccepted),
+			},
+		},
+	}
+	if diff := cmp.Diff(wantStatus, got.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message"), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("trafficManagerBackend status mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_PartialZeroWeight(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-backend",
+			Namespace:  "test-ns",
+			UID:        "uid",
+			Generation: 2,
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(500)),
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+				{Cluster: "cluster-3"},
+			},
+		},
+	}
+	weightedExport := func(cluster string, weight int64, isPercentage bool) client.Object {
+		export := geographicInternalServiceExportForTest(cluster, "")
+		export.Spec.Weight = ptr.To(weight)
+		if isPercentage {
+			export.Spec.WeightPercentage = ptr.To(weight)
+		}
+		return export
+	}
+
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(backend, weightedExport("cluster-1", 0, false), weightedExport("cluster-2", 1, false), weightedExport("cluster-3", 0, true)).
+		WithStatusSubresource(backend).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	gotDesiredEndpoints, gotInvalidServices, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+	if err != nil {
+		t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+	}
+	if len(gotInvalidServices) != 0 {
+		t.Errorf("validateAndProcessServiceImportForBackend() got invalid services %v, want none", gotInvalidServices)
+	}
+	got := make(map[string]int64, len(gotDesiredEndpoints)) // key is the cluster name
+	for _, dp := range gotDesiredEndpoints {
+		got[dp.FromCluster.Cluster] = ptr.Deref(dp.Endpoint.Properties.Weight, 0)
+	}
+	want := map[string]int64{"cluster-2": 500}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("validateAndProcessServiceImportForBackend() endpoint weights mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_ReadyEndpointCount(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-backend",
+			Namespace:  "test-ns",
+			UID:        "uid",
+			Generation: 2,
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(500)),
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+				{Cluster: "cluster-3"},
+			},
+		},
+	}
+	readyEndpointsExport := func(cluster string, weight int64, isPercentage bool, readyEndpointCount int64) client.Object {
+		export := geographicInternalServiceExportForTest(cluster, "")
+		export.Spec.Weight = ptr.To(weight)
+		if isPercentage {
+			export.Spec.WeightPercentage = ptr.To(weight)
+		}
+		export.Spec.ReadyEndpointCount = ptr.To(readyEndpointCount)
+		return export
+	}
+
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(backend, readyEndpointsExport("cluster-1", 1, false, 3), readyEndpointsExport("cluster-2", 90, true, 1), readyEndpointsExport("cluster-3", 100, false, 0)).
+		WithStatusSubresource(backend).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	gotDesiredEndpoints, gotInvalidServices, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+	if err != nil {
+		t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+	}
+	if len(gotInvalidServices) != 0 {
+		t.Errorf("validateAndProcessServiceImportForBackend() got invalid services %v, want none", gotInvalidServices)
+	}
+	got := make(map[string]int64, len(gotDesiredEndpoints)) // key is the cluster name
+	for _, dp := range gotDesiredEndpoints {
+		got[dp.FromCluster.Cluster] = ptr.Deref(dp.Endpoint.Properties.Weight, 0)
+	}
+	// The weights and the weight percentage are replaced by the ready endpoint counts, and the cluster without ready
+	// endpoints is excluded.
+	want := map[string]int64{"cluster-1": 375, "cluster-2": 125}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("validateAndProcessServiceImportForBackend() endpoint weights mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_EffectiveWeightTooLow(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-backend",
+			Namespace:  "test-ns",
+			UID:        "uid",
+			Generation: 2,
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(2)),
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+				{Cluster: "cluster-3"},
+			},
+		},
+	}
+	weightedExport := func(cluster string, weight int64) client.Object {
+		export := geographicInternalServiceExportForTest(cluster, "")
+		export.Spec.Weight = ptr.To(weight)
+		return export
+	}
+
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(backend, weightedExport("cluster-1", 1), weightedExport("cluster-2", 1), weightedExport("cluster-3", 1)).
+		WithStatusSubresource(backend).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: recorder,
+	}
+	defer trafficManagerBackendEffectiveWeightTooLowTotal.Reset()
+	gotDesiredEndpoints, _, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+	if err != nil {
+		t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+	}
+	got := make(map[string]int64, len(gotDesiredEndpoints)) // key is the cluster name
+	for _, dp := range gotDesiredEndpoints {
+		got[dp.FromCluster.Cluster] = ptr.Deref(dp.Endpoint.Properties.Weight, 0)
+	}
+	// The remaining weight is given to the clusters with the smallest names as the fractional parts are the same.
+	want := map[string]int64{"cluster-1": 1, "cluster-2": 1, "cluster-3": 0}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("validateAndProcessServiceImportForBackend() endpoint weights mismatch (-want +got):\n%s", diff)
+	}
+	if got := len(recorder.Events); got != 1 {
+		t.Fatalf("validateAndProcessServiceImportForBackend() got %d events, want 1", got)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, backendEventReasonEffectiveWeightTooLow) || !strings.Contains(event, "cluster-3") {
+		t.Errorf("validateAndProcessServiceImportForBackend() got event %q, want reason %q for cluster-3", event, backendEventReasonEffectiveWeightTooLow)
+	}
+	if got := testutil.ToFloat64(trafficManagerBackendEffectiveWeightTooLowTotal.WithLabelValues("test-ns", "test-backend")); got != 1 {
+		t.Errorf("validateAndProcessServiceImportForBackend() got effective weight too low counter %v, want 1", got)
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_WeightBounds(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+				{Cluster: "cluster-3"},
+			},
+		},
+	}
+	boundedExport := func(cluster string, minPercentage, maxPercentage *int64) client.Object {
+		export := geographicInternalServiceExportForTest(cluster, "")
+		export.Spec.Weight = ptr.To(int64(1))
+		export.Spec.MinWeightPercentage = minPercentage
+		export.Spec.MaxWeightPercentage = maxPercentage
+		return export
+	}
+	tests := []struct {
+		name        string
+		exports     []client.Object
+		wantWeights map[string]int64 // key is the cluster name
+		wantStatus  *fleetnetv1beta1.TrafficManagerBackendStatus
+	}{
+		{
+			name: "weights are clamped to the bounds",
+			exports: []client.Object{
+				boundedExport("cluster-1", nil, ptr.To(int64(20))),
+				boundedExport("cluster-2", nil, nil),
+				boundedExport("cluster-3", ptr.To(int64(50)), nil),
+			},
+			wantWeights: map[string]int64{"cluster-1": 100, "cluster-2": 150, "cluster-3": 250},
+		},
+		{
+			name: "infeasible bounds",
+			exports: []client.Object{
+				boundedExport("cluster-1", ptr.To(int64(60)), nil),
+				boundedExport("cluster-2", nil, nil),
+				boundedExport("cluster-3", ptr.To(int64(50)), nil),
+			},
+			wantStatus: &fleetnetv1beta1.TrafficManagerBackendStatus{
+				Endpoints: []fleetnetv1beta1.TrafficManagerEndpointStatus{},
+				Conditions: []metav1.Condition{
+					{
+						Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+						Status:             metav1.ConditionFalse,
+						ObservedGeneration: 2,
+						Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonInfeasibleWeightBounds),
+					},
+					{
+						Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+						Status:             metav1.ConditionFalse,
+						ObservedGeneration: 2,
+						Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonNotAccepted),
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-backend",
+					Namespace:  "test-ns",
+					UID:        "uid",
+					Generation: 2,
+				},
+				Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+					Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+						Name: "test-import",
+					},
+					Weight: ptr.To(int64(500)),
+				},
+			}
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(append([]client.Object{backend}, tc.exports...)...).
+				WithStatusSubresource(backend).
+				WithInterceptorFuncs(interceptor.Funcs{SubResourcePatch: applyStatusForTest}).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			gotDesiredEndpoints, gotInvalidServices, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+			if err != nil {
+				t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+			}
+			if len(gotInvalidServices) != 0 {
+				t.Errorf("validateAndProcessServiceImportForBackend() got invalid services %v, want none", gotInvalidServices)
+			}
+			if tc.wantStatus != nil {
+				if gotDesiredEndpoints != nil {
+					t.Errorf("validateAndProcessServiceImportForBackend() got desired endpoints %v, want nil", gotDesiredEndpoints)
+				}
+				got := &fleetnetv1beta1.TrafficManagerBackend{}
+				if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}, got); err != nil {
+					t.Fatalf("failed to get the trafficManagerBackend: %v", err)
+				}
+				if diff := cmp.Diff(*tc.wantStatus, got.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message"), cmpopts.EquateEmpty()); diff != "" {
+					t.Errorf("trafficManagerBackend status mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+			got := make(map[string]int64, len(gotDesiredEndpoints)) // key is the cluster name
+			for _, dp := range gotDesiredEndpoints {
+				got[dp.FromCluster.Cluster] = ptr.Deref(dp.Endpoint.Properties.Weight, 0)
+			}
+			if diff := cmp.Diff(tc.wantWeights, got); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() endpoint weights mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExcludeZeroWeightEndpoints(t *testing.T) {
+	endpoint := func(cluster string, weight *int64) desiredEndpoint {
+		return desiredEndpoint{
+			Endpoint: armtrafficmanager.Endpoint{
+				Properties: &armtrafficmanager.EndpointProperties{Weight: weight},
+			},
+			FromCluster: fleetnetv1beta1.FromCluster{
+				ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: cluster},
+				Weight:        weight,
+			},
+		}
+	}
+	desiredEndpoints := map[string]desiredEndpoint{
+		"endpoint-1": endpoint("cluster-1", ptr.To(int64(0))),
+		"endpoint-2": endpoint("cluster-2", ptr.To(int64(1))),
+		"endpoint-3": endpoint("cluster-3", ptr.To(int64(0))),
+	}
+	got := excludeZeroWeightEndpoints(desiredEndpoints)
+	if diff := cmp.Diff([]string{"cluster-1", "cluster-3"}, got); diff != "" {
+		t.Errorf("excludeZeroWeightEndpoints() mismatch (-want +got):\n%s", diff)
+	}
+	if _, ok := desiredEndpoints["endpoint-2"]; !ok || len(desiredEndpoints) != 1 {
+		t.Errorf("excludeZeroWeightEndpoints() got desired endpoints %v, want only endpoint-2", desiredEndpoints)
+	}
+}
+
+func TestDiffAzureTrafficManagerMonitorConfig(t *testing.T) {
+	desired := &fleetnetv1beta1.MonitorConfig{
+		IntervalInSeconds:         ptr.To(int64(30)),
+		Path:                      ptr.To("/"),
+		Port:                      ptr.To(int64(80)),
+		Protocol:                  ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolHTTP),
+		TimeoutInSeconds:          ptr.To(int64(10)),
+		ToleratedNumberOfFailures: ptr.To(int64(3)),
+	}
+	tests := []struct {
+		name    string
+		desired *fleetnetv1beta1.MonitorConfig
+		current *armtrafficmanager.MonitorConfig
+		want    []string
+	}{
+		{
+			name:    "in sync",
+			desired: desired,
+			current: &armtrafficmanager.MonitorConfig{
+				IntervalInSeconds:         ptr.To(int64(30)),
+				Path:                      ptr.To("/"),
+				Port:                      ptr.To(int64(80)),
+				Protocol:                  ptr.To(armtrafficmanager.MonitorProtocol("http")), // protocol is case-insensitive
+				TimeoutInSeconds:          ptr.To(int64(10)),
+				ToleratedNumberOfFailures: ptr.To(int64(3)),
+			},
+		},
+		{
+			name:    "protocol and port differ",
+			desired: desired,
+			current: &armtrafficmanager.MonitorConfig{
+				IntervalInSeconds:         ptr.To(int64(30)),
+				Path:                      ptr.To("/"),
+				Port:                      ptr.To(int64(8080)),
+				Protocol:                  ptr.To(armtrafficmanager.MonitorProtocolHTTPS),
+				TimeoutInSeconds:          ptr.To(int64(10)),
+				ToleratedNumberOfFailures: ptr.To(int64(3)),
+			},
+			want: []string{
+				"protocol (desired: HTTP, actual: HTTPS)",
+				"port (desired: 80, actual: 8080)",
+			},
+		},
+		{
+			name:    "all fields differ",
+			desired: desired,
+			current: &armtrafficmanager.MonitorConfig{
+				IntervalInSeconds:         ptr.To(int64(10)),
+				Path:                      ptr.To("/healthz"),
+				Port:                      ptr.To(int64(443)),
+				Protocol:                  ptr.To(armtrafficmanager.MonitorProtocolTCP),
+				TimeoutInSeconds:          ptr.To(int64(9)),
+				ToleratedNumberOfFailures: ptr.To(int64(4)),
+			},
+			want: []string{
+				"protocol (desired: HTTP, actual: TCP)",
+				"port (desired: 80, actual: 443)",
+				"path (desired: /, actual: /healthz)",
+				"intervalInSeconds (desired: 30, actual: 10)",
+				"timeoutInSeconds (desired: 10, actual: 9)",
+				"toleratedNumberOfFailures (desired: 3, actual: 4)",
+			},
+		},
+		{
+			name: "path is ignored for TCP",
+			desired: &fleetnetv1beta1.MonitorConfig{
+				Path:     ptr.To("/"),
+				Port:     ptr.To(int64(80)),
+				Protocol: ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolTCP),
+			},
+			current: &armtrafficmanager.MonitorConfig{
+				Port:     ptr.To(int64(80)),
+				Protocol: ptr.To(armtrafficmanager.MonitorProtocolTCP),
+			},
+		},
+		{
+			name:    "nil current monitor config",
+			desired: &fleetnetv1beta1.MonitorConfig{Port: ptr.To(int64(80))},
+			want:    []string{"port (desired: 80, actual: <unset>)"},
+		},
+		{
+			name: "nil desired monitor config",
+			current: &armtrafficmanager.MonitorConfig{
+				Port: ptr.To(int64(80)),
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := diffAzureTrafficManagerMonitorConfig(tc.desired, tc.current)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("diffAzureTrafficManagerMonitorConfig() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetProfileInSyncCondition(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "profile", Namespace: "ns"},
+	}
+	driftCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionProfileInSync),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 1,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonProfileDrift),
+		Message:            `The monitor config of the Azure Traffic Manager profile differs from trafficManagerProfile "profile": port (desired: 80, actual: 8080)`,
+	}
+	tests := []struct {
+		name       string
+		conditions []metav1.Condition
+		atmProfile *armtrafficmanager.Profile
+		want       []metav1.Condition
+	}{
+		{
+			name: "drift detected against the defaulted profile",
+			atmProfile: &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					MonitorConfig: &armtrafficmanager.MonitorConfig{
+						IntervalInSeconds:         ptr.To(int64(30)),
+						Path:                      ptr.To("/"),
+						Port:                      ptr.To(int64(8080)),
+						Protocol:                  ptr.To(armtrafficmanager.MonitorProtocolHTTP),
+						TimeoutInSeconds:          ptr.To(int64(10)),
+						ToleratedNumberOfFailures: ptr.To(int64(3)),
+					},
+				},
+			},
+			want: []metav1.Condition{driftCondition},
+		},
+		{
+			name:       "condition is removed when in sync",
+			conditions: []metav1.Condition{driftCondition},
+			atmProfile: &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					MonitorConfig: &armtrafficmanager.MonitorConfig{
+						IntervalInSeconds:         ptr.To(int64(30)),
+						Path:                      ptr.To("/"),
+						Port:                      ptr.To(int64(80)),
+						Protocol:                  ptr.To(armtrafficmanager.MonitorProtocolHTTP),
+						TimeoutInSeconds:          ptr.To(int64(10)),
+						ToleratedNumberOfFailures: ptr.To(int64(3)),
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "ns", Generation: 1},
+				Status:     fleetnetv1beta1.TrafficManagerBackendStatus{Conditions: tc.conditions},
+			}
+			setProfileInSyncCondition(backend, profile, tc.atmProfile)
+			if diff := cmp.Diff(tc.want, backend.Status.Conditions, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("setProfileInSyncCondition() conditions mismatch (-want, +got):\n%s", diff)
+			}
+			if profile.Spec.MonitorConfig != nil {
+				t.Errorf("setProfileInSyncCondition() mutated the trafficManagerProfile spec")
+			}
+		})
+	}
+}
+
+func TestSetUnknownCondition(t *testing.T) {
+	endpoints := []fleetnetv1beta1.TrafficManagerEndpointStatus{
+		{Name: "endpoint-1", Weight: ptr.To(int64(50))},
+		{Name: "endpoint-2", Weight: ptr.To(int64(50))},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation: 2,
+		},
+		Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+					Status:             metav1.ConditionTrue,
+					ObservedGeneration: 1,
+					Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonAccepted),
+				},
+			},
+			Endpoints: endpoints,
+			InvalidEndpoints: []fleetnetv1beta1.InvalidEndpointStatus{
+				{Cluster: "cluster-1", ServiceImport: "test-import", Reason: fleetnetv1beta1.InvalidEndpointReasonInvalidService},
+			},
+		},
+	}
+	setUnknownCondition(backend, "Failed to get the trafficManagerProfile")
+
+	want := fleetnetv1beta1.TrafficManagerBackendStatus{
+		Conditions: []metav1.Condition{
+			{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+				Status:             metav1.ConditionUnknown,
+				ObservedGeneration: 2,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
+				Message:            "Failed to get the trafficManagerProfile",
+			},
+		},
+		Endpoints: endpoints,
+	}
+	if diff := cmp.Diff(want, backend.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+		t.Errorf("setUnknownCondition() status mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSetMinimumHealthyEndpointsCondition(t *testing.T) {
+	endpoints := []fleetnetv1beta1.TrafficManagerEndpointStatus{
+		{Name: "endpoint-1", MonitorStatus: ptr.To(fleetnetv1beta1.TrafficManagerEndpointMonitorStatusOnline)},
+		{Name: "endpoint-2", MonitorStatus: ptr.To(fleetnetv1beta1.TrafficManagerEndpointMonitorStatusDegraded)},
+		{Name: "endpoint-3"},
+	}
+	acceptedCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 1,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonAccepted),
+	}
+	pendingCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: 1,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
+	}
+	metCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionMinimumHealthyEndpointsMet),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 1,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonMinimumHealthyEndpointsMet),
+		Message:            "1 of 3 accepted endpoint(s) are online, meeting the minimum of 1",
+	}
+	tests := []struct {
+		name                string
+		minHealthyEndpoints *int64
+		conditions          []metav1.Condition
+		endpoints           []fleetnetv1beta1.TrafficManagerEndpointStatus
+		want                []metav1.Condition
+	}{
+		{
+			name:       "condition is removed when minHealthyEndpoints is not set",
+			conditions: []metav1.Condition{acceptedCondition, metCondition},
+			endpoints:  endpoints,
+			want:       []metav1.Condition{acceptedCondition},
+		},
+		{
+			name:                "enough endpoints are online",
+			minHealthyEndpoints: ptr.To(int64(1)),
+			conditions:          []metav1.Condition{acceptedCondition},
+			endpoints:           endpoints,
+			want:                []metav1.Condition{acceptedCondition, metCondition},
+		},
+		{
+			name:                "fewer endpoints are online than the minimum",
+			minHealthyEndpoints: ptr.To(int64(2)),
+			conditions:          []metav1.Condition{acceptedCondition, metCondition},
+			endpoints:           endpoints,
+			want: []metav1.Condition{
+				acceptedCondition,
+				{
+					Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionMinimumHealthyEndpointsMet),
+					Status:             metav1.ConditionFalse,
+					ObservedGeneration: 1,
+					Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonInsufficientHealthyEndpoints),
+					Message:            "1 of 3 accepted endpoint(s) are online, fewer than the minimum of 2",
+				},
+			},
+		},
+		{
+			name:                "no accepted endpoints",
+			minHealthyEndpoints: ptr.To(int64(1)),
+			conditions:          []metav1.Condition{acceptedCondition},
+			want: []metav1.Condition{
+				acceptedCondition,
+				{
+					Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionMinimumHealthyEndpointsMet),
+					Status:             metav1.ConditionFalse,
+					ObservedGeneration: 1,
+					Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonInsufficientHealthyEndpoints),
+					Message:            "0 of 0 accepted endpoint(s) are online, fewer than the minimum of 1",
+				},
+			},
+		},
+		{
+			name:                "condition is unchanged while the endpoints are being reconciled",
+			minHealthyEndpoints: ptr.To(int64(1)),
+			conditions:          []metav1.Condition{pendingCondition, metCondition},
+			want:                []metav1.Condition{pendingCondition, metCondition},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "ns", Generation: 1},
+				Spec:       fleetnetv1beta1.TrafficManagerBackendSpec{MinHealthyEndpoints: tc.minHealthyEndpoints},
+				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+					Conditions: tc.conditions,
+					Endpoints:  tc.endpoints,
+				},
+			}
+			setMinimumHealthyEndpointsCondition(backend)
+			if diff := cmp.Diff(tc.want, backend.Status.Conditions, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("setMinimumHealthyEndpointsCondition() conditions mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetReadyCondition(t *testing.T) {
+	acceptedCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 2,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonAccepted),
+		Message:            "1 service(s) exported from clusters have been accepted as Traffic Manager endpoints",
+	}
+	invalidCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 2,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonInvalid),
+		Message:            "invalid profile",
+	}
+	pendingCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: 2,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
+	}
+	staleAcceptedCondition := acceptedCondition
+	staleAcceptedCondition.ObservedGeneration = 1
+	readyCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 2,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonReady),
+		Message:            acceptedCondition.Message,
+	}
+	notAcceptedCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 2,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonNotAccepted),
+		Message:            `The Accepted condition is False with reason "Invalid": invalid profile`,
+	}
+	unknownCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: 2,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
+		Message:            "The trafficManagerBackend is being reconciled",
+	}
+	tests := []struct {
+		name             string
+		conditions       []metav1.Condition
+		invalidEndpoints []fleetnetv1beta1.InvalidEndpointStatus
+		want             []metav1.Condition
+	}{
+		{
+			name: "no accepted condition",
+			want: []metav1.Condition{unknownCondition},
+		},
+		{
+			name:       "accepted",
+			conditions: []metav1.Condition{acceptedCondition, notAcceptedCondition},
+			want:       []metav1.Condition{acceptedCondition, readyCondition},
+		},
+		{
+			name:       "accepted condition of the previous generation",
+			conditions: []metav1.Condition{staleAcceptedCondition, readyCondition},
+			want:       []metav1.Condition{staleAcceptedCondition, unknownCondition},
+		},
+		{
+			name:       "accepted with invalid endpoints",
+			conditions: []metav1.Condition{acceptedCondition},
+			invalidEndpoints: []fleetnetv1beta1.InvalidEndpointStatus{
+				{Cluster: "member-1", ServiceImport: "test-import", Reason: fleetnetv1beta1.InvalidEndpointReasonInvalidService},
+			},
+			want: []metav1.Condition{
+				acceptedCondition,
+				{
+					Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+					Status:             metav1.ConditionFalse,
+					ObservedGeneration: 2,
+					Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonNotAccepted),
+					Message:            `The Accepted condition is True with reason "Accepted": ` + acceptedCondition.Message,
+				},
+			},
+		},
+		{
+			name:       "not accepted",
+			conditions: []metav1.Condition{invalidCondition, readyCondition},
+			want:       []metav1.Condition{invalidCondition, notAcceptedCondition},
+		},
+		{
+			name:       "pending",
+			conditions: []metav1.Condition{pendingCondition},
+			want:       []metav1.Condition{pendingCondition, unknownCondition},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "ns", Generation: 2},
+				Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+					Conditions:       tc.conditions,
+					InvalidEndpoints: tc.invalidEndpoints,
+				},
+			}
+			setReadyCondition(backend)
+			if diff := cmp.Diff(tc.want, backend.Status.Conditions, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("setReadyCondition() conditions mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRequeueWithJitterIfConflict(t *testing.T) {
+	backendKRef := klog.KRef("ns", "backend")
+	conflictErr := controller.NewUpdateIgnoreConflictError(apierrors.NewConflict(fleetnetv1beta1.GroupVersion.WithResource("trafficmanagerbackends").GroupResource(), "backend", errors.New("conflict")))
+	otherErr := controller.NewAPIServerError(false, errors.New("other error"))
+
+	t.Run("conflict error", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			got, err := requeueWithJitterIfConflict(backendKRef, ctrl.Result{}, conflictErr)
+			if err != nil {
+				t.Fatalf("requeueWithJitterIfConflict() got error %v, want nil", err)
+			}
+			maxDelay := time.Duration(float64(conflictRequeueDelay) * (1 + conflictRequeueJitterFactor))
+			if got.RequeueAfter < conflictRequeueDelay || got.RequeueAfter > maxDelay {
+				t.Errorf("requeueWithJitterIfConflict() got requeueAfter %v, want in [%v, %v]", got.RequeueAfter, conflictRequeueDelay, maxDelay)
+			}
+		}
+	})
+	t.Run("other error", func(t *testing.T) {
+		got, err := requeueWithJitterIfConflict(backendKRef, ctrl.Result{}, otherErr)
+		if !errors.Is(err, otherErr) {
+			t.Errorf("requeueWithJitterIfConflict() got error %v, want %v", err, otherErr)
+		}
+		if diff := cmp.Diff(ctrl.Result{}, got); diff != "" {
+			t.Errorf("requeueWithJitterIfConflict() result mismatch (-want, +got):\n%s", diff)
+		}
+	})
+	t.Run("no error", func(t *testing.T) {
+		want := ctrl.Result{RequeueAfter: time.Minute}
+		got, err := requeueWithJitterIfConflict(backendKRef, want, nil)
+		if err != nil {
+			t.Fatalf("requeueWithJitterIfConflict() got error %v, want nil", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("requeueWithJitterIfConflict() result mismatch (-want, +got):\n%s", diff)
+		}
+	})
+}
+
+func TestValidateAndProcessServiceImportForBackend_DeterministicWeights(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(100)),
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			geographicInternalServiceExportForTest("cluster-1", ""),
+			geographicInternalServiceExportForTest("cluster-2", ""),
+			geographicInternalServiceExportForTest("cluster-3", ""),
+		).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	serviceImportWithClusters := func(clusters ...string) *fleetnetv1alpha1.ServiceImport {
+		serviceImport := &fleetnetv1alpha1.ServiceImport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-import",
+				Namespace: "test-ns",
+			},
+		}
+		for _, cluster := range clusters {
+			serviceImport.Status.Clusters = append(serviceImport.Status.Clusters, fleetnetv1alpha1.ClusterStatus{Cluster: cluster})
+		}
+		return serviceImport
+	}
+
+	// The same inputs are reconciled repeatedly while the order of the clusters in the serviceImport status changes.
+	serviceImports := []*fleetnetv1alpha1.ServiceImport{
+		serviceImportWithClusters("cluster-1", "cluster-2", "cluster-3"),
+		serviceImportWithClusters("cluster-1", "cluster-2", "cluster-3"),
+		serviceImportWithClusters("cluster-3", "cluster-1", "cluster-2"),
+		serviceImportWithClusters("cluster-2", "cluster-3", "cluster-1"),
+	}
+	var first map[string]desiredEndpoint
+	for i, serviceImport := range serviceImports {
+		got, gotInvalidServices, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+		if err != nil {
+			t.Fatalf("validateAndProcessServiceImportForBackend() #%d got error %v, want nil", i, err)
+		}
+		if len(gotInvalidServices) != 0 {
+			t.Fatalf("validateAndProcessServiceImportForBackend() #%d got invalid services %v, want none", i, gotInvalidServices)
+		}
+		gotWeights := make(map[string]int64, len(got))
+		for _, dp := range got {
+			gotWeights[dp.FromCluster.Cluster] = *dp.Endpoint.Properties.Weight
+		}
+		wantWeights := map[string]int64{"cluster-1": 34, "cluster-2": 33, "cluster-3": 33}
+		if diff := cmp.Diff(wantWeights, gotWeights); diff != "" {
+			t.Errorf("validateAndProcessServiceImportForBackend() #%d weights mismatch (-want, +got):\n%s", i, diff)
+		}
+		if i == 0 {
+			first = got
+			continue
+		}
+		if diff := cmp.Diff(first, got); diff != "" {
+			t.Errorf("validateAndProcessServiceImportForBackend() #%d desired endpoints differ from the first reconcile (-first, +got):\n%s", i, diff)
+		}
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_ClusterSelector(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+				{Cluster: "cluster-3"},
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			geographicInternalServiceExportForTest("cluster-1", ""),
+			geographicInternalServiceExportForTest("cluster-2", ""),
+			geographicInternalServiceExportForTest("cluster-3", ""),
+		).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: record.NewFakeRecorder(10),
+	}
+
+	tests := []struct {
+		name            string
+		clusterSelector *fleetnetv1beta1.TrafficManagerBackendClusterSelector
+		wantWeights     map[string]int64 // key is the cluster name
+	}{
+		{
+			name:        "no cluster selector",
+			wantWeights: map[string]int64{"cluster-1": 34, "cluster-2": 33, "cluster-3": 33},
+		},
+		{
+			name: "excluded cluster is not counted in the total weight",
+			clusterSelector: &fleetnetv1beta1.TrafficManagerBackendClusterSelector{
+				Clusters: []string{"cluster-1", "cluster-3", "not-exist"},
+			},
+			wantWeights: map[string]int64{"cluster-1": 50, "cluster-3": 50},
+		},
+		{
+			name: "all the clusters are excluded",
+			clusterSelector: &fleetnetv1beta1.TrafficManagerBackendClusterSelector{
+				Clusters: []string{},
+			},
+			wantWeights: map[string]int64{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-backend",
+					Namespace: "test-ns",
+					UID:       "uid",
+				},
+				Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+					Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+						Name:            "test-import",
+						ClusterSelector: tc.clusterSelector,
+					},
+					Weight: ptr.To(int64(100)),
+				},
+			}
+			got, gotInvalidServices, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+			if err != nil {
+				t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+			}
+			if len(gotInvalidServices) != 0 {
+				t.Errorf("validateAndProcessServiceImportForBackend() got invalid services %v, want none", gotInvalidServices)
+			}
+			gotWeights := make(map[string]int64, len(got))
+			for _, dp := range got {
+				gotWeights[dp.FromCluster.Cluster] = *dp.Endpoint.Properties.Weight
+			}
+			if diff := cmp.Diff(tc.wantWeights, gotWeights); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() weights mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_ClusterWeightOverrides(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+				{Cluster: "cluster-3"},
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			geographicInternalServiceExportForTest("cluster-1", ""),
+			geographicInternalServiceExportForTest("cluster-2", ""),
+			geographicInternalServiceExportForTest("cluster-3", ""),
+		).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+
+	tests := []struct {
+		name            string
+		overrides       []fleetnetv1beta1.ClusterWeight
+		clusterSelector *fleetnetv1beta1.TrafficManagerBackendClusterSelector
+		wantWeights     map[string]int64 // key is the cluster name
+		wantEvents      int
+	}{
+		{
+			name:        "no overrides",
+			wantWeights: map[string]int64{"cluster-1": 34, "cluster-2": 33, "cluster-3": 33},
+		},
+		{
+			name:        "override the weight of a cluster",
+			overrides:   []fleetnetv1beta1.ClusterWeight{{Cluster: "cluster-1", Weight: 200}},
+			wantWeights: map[string]int64{"cluster-1": 50, "cluster-2": 25, "cluster-3": 25},
+		},
+		{
+			name:        "zero weight override excludes the cluster",
+			overrides:   []fleetnetv1beta1.ClusterWeight{{Cluster: "cluster-2", Weight: 0}},
+			wantWeights: map[string]int64{"cluster-1": 50, "cluster-3": 50},
+		},
+		{
+			name:        "override of the cluster not in the serviceImport is ignored",
+			overrides:   []fleetnetv1beta1.ClusterWeight{{Cluster: "not-exist", Weight: 0}},
+			wantWeights: map[string]int64{"cluster-1": 34, "cluster-2": 33, "cluster-3": 33},
+			wantEvents:  1,
+		},
+		{
+			name:      "override of the cluster excluded by the cluster selector is ignored",
+			overrides: []fleetnetv1beta1.ClusterWeight{{Cluster: "cluster-2", Weight: 1000}},
+			clusterSelector: &fleetnetv1beta1.TrafficManagerBackendClusterSelector{
+				Clusters: []string{"cluster-1", "cluster-3"},
+			},
+			wantWeights: map[string]int64{"cluster-1": 50, "cluster-3": 50},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: recorder,
+			}
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-backend",
+					Namespace: "test-ns",
+					UID:       "uid",
+				},
+				Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+					Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+						Name:                   "test-import",
+						ClusterSelector:        tc.clusterSelector,
+						ClusterWeightOverrides: tc.overrides,
+					},
+					Weight: ptr.To(int64(100)),
+				},
+			}
+			got, gotInvalidServices, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+			if err != nil {
+				t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+			}
+			if len(gotInvalidServices) != 0 {
+				t.Errorf("validateAndProcessServiceImportForBackend() got invalid services %v, want none", gotInvalidServices)
+			}
+			gotWeights := make(map[string]int64, len(got))
+			for _, dp := range got {
+				gotWeights[dp.FromCluster.Cluster] = *dp.Endpoint.Properties.Weight
+			}
+			if diff := cmp.Diff(tc.wantWeights, gotWeights); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() weights mismatch (-want, +got):\n%s", diff)
+			}
+			if got := len(recorder.Events); got != tc.wantEvents {
+				t.Errorf("validateAndProcessServiceImportForBackend() emitted %d events, want %d", got, tc.wantEvents)
+			}
+		})
+	}
+}
+
+func TestUnknownClusterWeightOverrides(t *testing.T) {
+	serviceImports := []*fleetnetv1alpha1.ServiceImport{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-import"},
+			Status: fleetnetv1alpha1.ServiceImportStatus{
+				Clusters: []fleetnetv1alpha1.ClusterStatus{{Cluster: "cluster-1"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "additional-import"},
+			Status: fleetnetv1alpha1.ServiceImportStatus{
+				Clusters: []fleetnetv1alpha1.ClusterStatus{{Cluster: "cluster-2"}},
+			},
+		},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+				ClusterWeightOverrides: []fleetnetv1beta1.ClusterWeight{
+					{Cluster: "cluster-4", Weight: 1},
+					{Cluster: "cluster-2", Weight: 1},
+					{Cluster: "cluster-3", Weight: 1},
+					{Cluster: "cluster-1", Weight: 1},
+				},
+			},
+		},
+	}
+	want := []string{"cluster-3", "cluster-4"}
+	if diff := cmp.Diff(want, unknownClusterWeightOverrides(backend, serviceImports)); diff != "" {
+		t.Errorf("unknownClusterWeightOverrides() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestUpdateTrafficManagerEndpoints_AzureError(t *testing.T) {
+	endpointName := "fleet-uid#test-import#cluster-1"
+	tests := []struct {
+		name              string
+		statusCode        int
+		errorCode         string
+		wantErr           bool
+		wantBadEndpoints  int
+		wantUnknownStatus bool
+	}{
+		{
+			name:             "bad request is a bad endpoint",
+			statusCode:       http.StatusBadRequest,
+			errorCode:        "BadRequest",
+			wantBadEndpoints: 1,
+		},
+		{
+			name:             "forbidden by policy is a bad endpoint",
+			statusCode:       http.StatusForbidden,
+			errorCode:        "RequestDisallowedByPolicy",
+			wantBadEndpoints: 1,
+		},
+		{
+			name:       "authorization failed is returned",
+			statusCode: http.StatusForbidden,
+			errorCode:  "AuthorizationFailed",
+			wantErr:    true,
+		},
+		{
+			name:              "conflict is retried",
+			statusCode:        http.StatusConflict,
+			errorCode:         "Conflict",
+			wantErr:           true,
+			wantUnknownStatus: true,
+		},
+		{
+			name:              "throttled is retried",
+			statusCode:        http.StatusTooManyRequests,
+			errorCode:         "TooManyRequests",
+			wantErr:           true,
+			wantUnknownStatus: true,
+		},
+		{
+			name:              "internal server error is retried",
+			statusCode:        http.StatusInternalServerError,
+			errorCode:         "InternalServerError",
+			wantErr:           true,
+			wantUnknownStatus: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeServer := armtrafficmanagerfake.EndpointsServer{
+				CreateOrUpdate: func(_ context.Context, _ string, _ string, _ armtrafficmanager.EndpointType, _ string, _ armtrafficmanager.Endpoint, _ *armtrafficmanager.EndpointsClientCreateOrUpdateOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientCreateOrUpdateResponse], errResp azcorefake.ErrorResponder) {
+					errResp.SetResponseError(tc.statusCode, tc.errorCode)
+					return resp, errResp
+				},
+			}
+			clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+				&arm.ClientOptions{
+					ClientOptions: azcore.ClientOptions{
+						Transport: armtrafficmanagerfake.NewEndpointsServerTransport(&fakeServer),
+						Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+					},
+				})
+			if err != nil {
+				t.Fatalf("failed to create the client factory: %v", err)
+			}
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-backend",
+					Namespace: "test-ns",
+					UID:       "uid",
+				},
+			}
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(backend).
+				WithStatusSubresource(backend).
+				Build()
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			clients := &azureclient.TrafficManagerClients{EndpointsClient: clientFactory.NewEndpointsClient()}
+			profile := &armtrafficmanager.Profile{
+				Name:       ptr.To("test-profile"),
+				Properties: &armtrafficmanager.ProfileProperties{},
+			}
+			desiredEndpoints := map[string]desiredEndpoint{
+				endpointName: {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To(endpointName),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							TargetResourceID: ptr.To("resourceID-1"),
+							EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+							Weight:           ptr.To(int64(1)),
+						},
+					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
+				},
+			}
+			_, gotBadEndpoints, err := r.updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(context.Background(), clients, "test-rg", backend, profile, desiredEndpoints)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got error %v, want error %v", err, tc.wantErr)
+			}
+			if len(gotBadEndpoints) != tc.wantBadEndpoints {
+				t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got %d bad endpoints, want %d", len(gotBadEndpoints), tc.wantBadEndpoints)
+			}
+			cond := meta.FindStatusCondition(backend.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted))
+			if gotUnknown := cond != nil && cond.Status == metav1.ConditionUnknown; gotUnknown != tc.wantUnknownStatus {
+				t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got condition %+v, want unknown %v", cond, tc.wantUnknownStatus)
+			}
+		})
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_ServiceConflict(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+				{Cluster: "cluster-3"},
+			},
+		},
+	}
+	conflictedExport := geographicInternalServiceExportForTest("cluster-2", "")
+	conflictedExport.Status.Conditions = []metav1.Condition{
+		{
+			Type:   string(fleetnetv1beta1.ServiceExportConflict),
+			Status: metav1.ConditionTrue,
+			Reason: "ConflictFound",
+		},
+	}
+	unconflictedExport := geographicInternalServiceExportForTest("cluster-3", "")
+	unconflictedExport.Status.Conditions = []metav1.Condition{
+		{
+			Type:   string(fleetnetv1beta1.ServiceExportConflict),
+			Status: metav1.ConditionFalse,
+			Reason: "NoConflictFound",
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			geographicInternalServiceExportForTest("cluster-1", ""),
+			conflictedExport,
+			unconflictedExport,
+		).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(100)),
+		},
+	}
+
+	got, gotInvalidServices, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+	if err != nil {
+		t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+	}
+	wantInvalidServices := map[string]error{"cluster-2": errServiceConflict}
+	if diff := cmp.Diff(wantInvalidServices, gotInvalidServices, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("validateAndProcessServiceImportForBackend() invalid services mismatch (-want, +got):\n%s", diff)
+	}
+	gotWeights := make(map[string]int64, len(got))
+	for _, dp := range got {
+		gotWeights[dp.FromCluster.Cluster] = *dp.Endpoint.Properties.Weight
+	}
+	wantWeights := map[string]int64{"cluster-1": 50, "cluster-3": 50}
+	if diff := cmp.Diff(wantWeights, gotWeights); diff != "" {
+		t.Errorf("validateAndProcessServiceImportForBackend() weights mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_AlwaysServe(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+			},
+		},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(100)),
+		},
+	}
+	tests := []struct {
+		name                string
+		monitorStatus       armtrafficmanager.ProfileMonitorStatus
+		wantAlwaysServe     map[string]armtrafficmanager.AlwaysServe
+		wantInvalidClusters []string
+	}{
+		{
+			name:          "monitoring is enabled",
+			monitorStatus: armtrafficmanager.ProfileMonitorStatusOnline,
+			wantAlwaysServe: map[string]armtrafficmanager.AlwaysServe{
+				"cluster-1": armtrafficmanager.AlwaysServeEnabled,
+				"cluster-2": armtrafficmanager.AlwaysServeDisabled,
+			},
+		},
+		{
+			name:          "monitoring is disabled",
+			monitorStatus: armtrafficmanager.ProfileMonitorStatusDisabled,
+			wantAlwaysServe: map[string]armtrafficmanager.AlwaysServe{
+				"cluster-2": armtrafficmanager.AlwaysServeDisabled,
+			},
+			wantInvalidClusters: []string{"cluster-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alwaysServeExport := geographicInternalServiceExportForTest("cluster-1", "")
+			alwaysServeExport.Annotations = map[string]string{objectmeta.ServiceExportAnnotationAlwaysServe: "true"}
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(alwaysServeExport, geographicInternalServiceExportForTest("cluster-2", "")).
+				WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+					return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+				}).
+				Build()
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			atmProfile := &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					MonitorConfig: &armtrafficmanager.MonitorConfig{ProfileMonitorStatus: ptr.To(tt.monitorStatus)},
+				},
+			}
+
+			got, gotInvalidServices, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, atmProfile, backend, serviceImport)
+			if err != nil {
+				t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+			}
+			var gotInvalidClusters []string
+			for cluster := range gotInvalidServices {
+				gotInvalidClusters = append(gotInvalidClusters, cluster)
+			}
+			if diff := cmp.Diff(tt.wantInvalidClusters, gotInvalidClusters, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() invalid services mismatch (-want, +got):\n%s", diff)
+			}
+			gotAlwaysServe := make(map[string]armtrafficmanager.AlwaysServe, len(got))
+			for _, dp := range got {
+				gotAlwaysServe[dp.FromCluster.Cluster] = alwaysServeOf(dp.Endpoint.Properties)
+			}
+			if diff := cmp.Diff(tt.wantAlwaysServe, gotAlwaysServe); diff != "" {
+				t.Errorf("validateAndProcessServiceImportForBackend() always serve mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// greenInternalServiceExportForTest returns the internalServiceExport of the cluster behind the "test-import-green"
+// serviceImport.
+func greenInternalServiceExportForTest(cluster string) *fleetnetv1alpha1.InternalServiceExport {
+	export := geographicInternalServiceExportForTest(cluster, "")
+	export.Name = "test-ns-test-import-green"
+	export.Spec.ServiceReference.Name = "test-import-green"
+	export.Spec.ServiceReference.NamespacedName = "test-ns/test-import-green"
+	return export
+}
+
+func TestValidateAndProcessServiceImportForBackend_AdditionalServiceImports(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+			},
+		},
+	}
+	greenServiceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import-green",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+			},
+		},
+	}
+	invalidGreenExport := greenInternalServiceExportForTest("cluster-2")
+	invalidGreenExport.Spec.IsDNSLabelConfigured = false
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			geographicInternalServiceExportForTest("cluster-1", ""),
+			geographicInternalServiceExportForTest("cluster-2", ""),
+			greenInternalServiceExportForTest("cluster-1"),
+			invalidGreenExport,
+		).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: record.NewFakeRecorder(10),
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name:            "test-import",
+				AdditionalNames: []string{"test-import-green"},
+			},
+			Weight: ptr.To(int64(90)),
+		},
+	}
+
+	got, gotInvalidServices, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport, greenServiceImport)
+	if err != nil {
+		t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want nil", err)
+	}
+	if _, ok := gotInvalidServices["test-import-green/cluster-2"]; !ok || len(gotInvalidServices) != 1 {
+		t.Errorf("validateAndProcessServiceImportForBackend() invalid services = %v, want the service of test-import-green from cluster-2 only", gotInvalidServices)
+	}
+	gotWeights := make(map[string]int64, len(got)) // key is the endpoint name
+	for name, dp := range got {
+		gotWeights[name] = *dp.Endpoint.Properties.Weight
+	}
+	// The weight of the backend is distributed among the services behind both serviceImports.
+	wantWeights := map[string]int64{
+		"fleet-uid#test-import#cluster-1":       30,
+		"fleet-uid#test-import#cluster-2":       30,
+		"fleet-uid#test-import-green#cluster-1": 30,
+	}
+	if diff := cmp.Diff(wantWeights, gotWeights); diff != "" {
+		t.Errorf("validateAndProcessServiceImportForBackend() weights mismatch (-want, +got):\n%s", diff)
+	}
+	if gotGreen := got["fleet-uid#test-import-green#cluster-1"]; gotGreen.key() != "test-import-green/cluster-1" {
+		t.Errorf("validateAndProcessServiceImportForBackend() key of the green endpoint = %q, want %q", gotGreen.key(), "test-import-green/cluster-1")
+	}
+}
+
+func TestUpdateInternalServiceExportsExposedCondition_AdditionalServiceImports(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name:            "test-import",
+				AdditionalNames: []string{"test-import-green"},
+			},
+			Weight: ptr.To(int64(100)),
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			geographicInternalServiceExportForTest("cluster-1", ""),
+			greenInternalServiceExportForTest("cluster-1"),
+		).
+		WithStatusSubresource(&fleetnetv1alpha1.InternalServiceExport{}).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	r := &Reconciler{Client: fakeClient}
+
+	desiredEndpoints := map[string]desiredEndpoint{
+		"fleet-uid#test-import#cluster-1": {
+			FromCluster: fleetnetv1beta1.FromCluster{
+				ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+			},
+		},
+	}
+	invalidServices := map[string]error{
+		"test-import-green/cluster-1": errors.New("DNS label is not configured to the public IP"),
+	}
+	if err := r.updateInternalServiceExportsExposedCondition(context.Background(), backend, desiredEndpoints, invalidServices, nil); err != nil {
+		t.Fatalf("updateInternalServiceExportsExposedCondition() got error %v, want nil", err)
+	}
+
+	want := map[string][]metav1.Condition{
+		"test-ns-test-import": {
+			{
+				Type:    string(fleetnetv1beta1.ServiceExportExposedAsTrafficManagerEndpoint),
+				Status:  metav1.ConditionTrue,
+				Reason:  exposedConditionReasonExposed,
+				Message: `Service is exposed as an Azure Traffic Manager endpoint by trafficManagerBackend "test-backend"`,
+			},
+		},
+		"test-ns-test-import-green": {
+			{
+				Type:    string(fleetnetv1beta1.ServiceExportExposedAsTrafficManagerEndpoint),
+				Status:  metav1.ConditionFalse,
+				Reason:  exposedConditionReasonInvalid,
+				Message: `Service cannot be exposed as an Azure Traffic Manager endpoint by trafficManagerBackend "test-backend": DNS label is not configured to the public IP`,
+			},
+		},
+	}
+	for name, wantConditions := range want {
+		got := &fleetnetv1alpha1.InternalServiceExport{}
+		if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "cluster-1-ns", Name: name}, got); err != nil {
+			t.Fatalf("failed to get internalServiceExport %s: %v", name, err)
+		}
+		if diff := cmp.Diff(wantConditions, got.Status.Conditions, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"), cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("updateInternalServiceExportsExposedCondition() %s conditions mismatch (-want, +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestUpdateInternalServiceExportsExposedCondition(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backend",
+			Namespace: "test-ns",
+			UID:       "uid",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name: "test-import",
+			},
+			Weight: ptr.To(int64(100)),
+		},
+	}
+	staleExport := geographicInternalServiceExportForTest("cluster-4", "")
+	staleExport.Status.Conditions = []metav1.Condition{
+		{
+			Type:   string(fleetnetv1beta1.ServiceExportExposedAsTrafficManagerEndpoint),
+			Status: metav1.ConditionTrue,
+			Reason: exposedConditionReasonExposed,
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			geographicInternalServiceExportForTest("cluster-1", ""),
+			geographicInternalServiceExportForTest("cluster-2", ""),
+			geographicInternalServiceExportForTest("cluster-3", ""),
+			staleExport,
+		).
+		WithStatusSubresource(&fleetnetv1alpha1.InternalServiceExport{}).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	r := &Reconciler{Client: fakeClient}
+
+	desiredEndpoints := map[string]desiredEndpoint{
+		"fleet-uid#test-import#cluster-1": {
+			FromCluster: fleetnetv1beta1.FromCluster{
+				ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+			},
+		},
+	}
+	invalidServices := map[string]error{
+		"cluster-2": errors.New("DNS label is not configured to the public IP"),
+	}
+	if err := r.updateInternalServiceExportsExposedCondition(context.Background(), backend, desiredEndpoints, invalidServices, []string{"cluster-3"}); err != nil {
+		t.Fatalf("updateInternalServiceExportsExposedCondition() got error %v, want nil", err)
+	}
+
+	want := map[string][]metav1.Condition{
+		"cluster-1": {
+			{
+				Type:    string(fleetnetv1beta1.ServiceExportExposedAsTrafficManagerEndpoint),
+				Status:  metav1.ConditionTrue,
+				Reason:  exposedConditionReasonExposed,
+				Message: `Service is exposed as an Azure Traffic Manager endpoint by trafficManagerBackend "test-backend"`,
+			},
+		},
+		"cluster-2": {
+			{
+				Type:    string(fleetnetv1beta1.ServiceExportExposedAsTrafficManagerEndpoint),
+				Status:  metav1.ConditionFalse,
+				Reason:  exposedConditionReasonInvalid,
+				Message: `Service cannot be exposed as an Azure Traffic Manager endpoint by trafficManagerBackend "test-backend": DNS label is not configured to the public IP`,
+			},
+		},
+		"cluster-3": {
+			{
+				Type:    string(fleetnetv1beta1.ServiceExportExposedAsTrafficManagerEndpoint),
+				Status:  metav1.ConditionFalse,
+				Reason:  exposedConditionReasonEndpointLimitExceeded,
+				Message: `Service is not exposed by trafficManagerBackend "test-backend" because the Azure Traffic Manager profile has reached the maximum number of endpoints`,
+			},
+		},
+		"cluster-4": nil, // the cluster is no longer behind the backend
+	}
+	for cluster, wantConditions := range want {
+		got := &fleetnetv1alpha1.InternalServiceExport{}
+		if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: cluster + "-ns", Name: "test-ns-test-import"}, got); err != nil {
+			t.Fatalf("failed to get internalServiceExport of %s: %v", cluster, err)
+		}
+		if diff := cmp.Diff(wantConditions, got.Status.Conditions, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime"), cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("updateInternalServiceExportsExposedCondition() %s conditions mismatch (-want, +got):\n%s", cluster, diff)
+		}
+	}
+}
+
+func TestHandleUpdate_EndpointLimitExceededAndBadEndpoint(t *testing.T) {
+	profilesServer := armtrafficmanagerfake.ProfilesServer{
+		Get: func(_ context.Context, _ string, profileName string, _ *armtrafficmanager.ProfilesClientGetOptions) (resp azcorefake.Responder[armtrafficmanager.ProfilesClientGetResponse], errResp azcorefake.ErrorResponder) {
+			resp.SetResponse(http.StatusOK, armtrafficmanager.ProfilesClientGetResponse{
+				Profile: armtrafficmanager.Profile{
+					Name:       ptr.To(profileName),
+					Properties: &armtrafficmanager.ProfileProperties{},
+				},
+			}, nil)
+			return resp, errResp
+		},
+	}
+	endpointsServer := armtrafficmanagerfake.EndpointsServer{
+		CreateOrUpdate: func(_ context.Context, _ string, _ string, _ armtrafficmanager.EndpointType, name string, parameters armtrafficmanager.Endpoint, _ *armtrafficmanager.EndpointsClientCreateOrUpdateOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientCreateOrUpdateResponse], errResp azcorefake.ErrorResponder) {
+			if strings.HasSuffix(name, "cluster-2") {
+				errResp.SetResponseError(http.StatusBadRequest, "BadRequest")
+				return resp, errResp
+			}
+			parameters.ID = ptr.To("endpoint-id")
+			resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientCreateOrUpdateResponse{Endpoint: parameters}, nil)
+			return resp, errResp
+		},
+	}
+	clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+		&arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: armtrafficmanagerfake.NewServerFactoryTransport(&armtrafficmanagerfake.ServerFactory{
+					ProfilesServer:  profilesServer,
+					EndpointsServer: endpointsServer,
+				}),
+				Retry: policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+			},
+		})
+	if err != nil {
+		t.Fatalf("failed to create the client factory: %v", err)
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-backend",
+			Namespace:  "test-ns",
+			UID:        "uid",
+			Generation: 1,
+			Finalizers: []string{objectmeta.TrafficManagerBackendFinalizer},
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "test-profile"},
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{Name: "test-import"},
+			Weight:  ptr.To(int64(100)),
+		},
+	}
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-profile",
+			Namespace:  "test-ns",
+			Generation: 1,
+		},
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			ResourceGroup: "test-rg",
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+		Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:               string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
+					Status:             metav1.ConditionTrue,
+					Reason:             "Programmed",
+					ObservedGeneration: 1,
+				},
+			},
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
+				{Cluster: "cluster-3"},
+			},
+		},
+	}
+	// The service exported from cluster-3 has the lowest weight and is dropped first when exceeding the limit.
+	lowWeightExport := geographicInternalServiceExportForTest("cluster-3", "")
+	lowWeightExport.Spec.Weight = ptr.To(int64(50))
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			backend,
+			profile,
+			serviceImport,
+			geographicInternalServiceExportForTest("cluster-1", ""),
+			geographicInternalServiceExportForTest("cluster-2", ""),
+			lowWeightExport,
+		).
+		WithStatusSubresource(backend, &fleetnetv1alpha1.InternalServiceExport{}).
+		WithInterceptorFuncs(interceptor.Funcs{SubResourcePatch: applyStatusForTest}).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	r := &Reconciler{
+		Client:                 fakeClient,
+		AzureClientFactory:     azureclient.NewTrafficManagerClientFactoryForClients(clientFactory.NewProfilesClient(), clientFactory.NewEndpointsClient()),
+		Recorder:               record.NewFakeRecorder(100),
+		MaxEndpointsPerProfile: 2,
+	}
+	// The error of the bad endpoint is returned to requeue the request.
+	if _, err := r.handleUpdate(context.Background(), backend); err == nil {
+		t.Fatalf("handleUpdate() got nil error, want the error of the bad endpoint")
+	}
+
+	got := &fleetnetv1beta1.TrafficManagerBackend{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "test-ns", Name: "test-backend"}, got); err != nil {
+		t.Fatalf("failed to get trafficManagerBackend: %v", err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted))
+	if cond == nil {
+		t.Fatalf("handleUpdate() got no accepted condition, want one")
+	}
+	if cond.Status != metav1.ConditionFalse || cond.Reason != string(fleetnetv1beta1.TrafficManagerBackendReasonEndpointLimitExceeded) {
+		t.Errorf("handleUpdate() got accepted condition %s/%s, want %s/%s", cond.Status, cond.Reason, metav1.ConditionFalse, fleetnetv1beta1.TrafficManagerBackendReasonEndpointLimitExceeded)
+	}
+	// The message reports both the dropped clusters and the endpoints which failed to be created.
+	wantMessageParts := []string{
+		"1 service(s) exported from clusters [cluster-3] are not exposed because the Azure Traffic Manager profile allows at most 2 endpoints",
+		"1 endpoint(s) failed to be created/updated in the Azure Traffic Manager",
+	}
+	for _, part := range wantMessageParts {
+		if !strings.Contains(cond.Message, part) {
+			t.Errorf("handleUpdate() got accepted condition message %q, want it to contain %q", cond.Message, part)
+		}
+	}
+}
+
+func TestHandleUpdate_Suspended(t *testing.T) {
+	endpoints := []fleetnetv1beta1.TrafficManagerEndpointStatus{
+		{
+			Name:   "fleet-uid#test-import#cluster-1",
+			Weight: ptr.To(int64(100)),
+			From: &fleetnetv1beta1.FromCluster{
+				ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+				Weight:        ptr.To(int64(1)),
+			},
+		},
+	}
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-backend",
+			Namespace:  "test-ns",
+			UID:        "uid",
+			Generation: 2,
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "test-profile"},
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{Name: "test-import"},
+			Weight:  ptr.To(int64(100)),
+			Suspend: ptr.To(true),
+		},
+		Status: fleetnetv1beta1.TrafficManagerBackendStatus{
+			Endpoints: endpoints,
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(backend).
+		WithStatusSubresource(backend).
+		WithInterceptorFuncs(interceptor.Funcs{SubResourcePatch: applyStatusForTest}).
+		Build()
+	// The Azure clients are not set so that any call to the Azure Traffic Manager fails the test.
+	r := &Reconciler{Client: fakeClient}
+	if _, err := r.handleUpdate(context.Background(), backend); err != nil {
+		t.Fatalf("handleUpdate() got error %v, want nil", err)
+	}
+
+	got := &fleetnetv1beta1.TrafficManagerBackend{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "test-ns", Name: "test-backend"}, got); err != nil {
+		t.Fatalf("failed to get trafficManagerBackend: %v", err)
+	}
+	want := fleetnetv1beta1.TrafficManagerBackendStatus{
+		Endpoints: endpoints,
+		Conditions: []metav1.Condition{
+			{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: 2,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonSuspended),
+			},
+			{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: 2,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonNotAccepted),
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got.Status, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime", "Message")); diff != "" {
+		t.Errorf("handleUpdate() status mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestValidateServiceImportAndCleanupEndpointsIfInvalid(t *testing.T) {
+	endpoints := []fleetnetv1beta1.TrafficManagerEndpointStatus{
+		{
+			Name:   "fleet-uid#test-import#cluster-1",
+			Weight: ptr.To(int64(100)),
+			From: &fleetnetv1beta1.FromCluster{
+				ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+				Weight:        ptr.To(int64(1)),
+			},
+		},
+	}
+	deletingServiceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-import",
+			Namespace:         "test-ns",
+			DeletionTimestamp: ptr.To(metav1.Now()),
+			Finalizers:        []string{"test-finalizer"},
+		},
+	}
+	deletingCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: 1,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
+		Message:            `ServiceImport "test-import" is being deleted`,
+	}
+	notFoundCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 1,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonInvalid),
+		Message:            `ServiceImport "test-import" is not found`,
+	}
+	deletingReadyCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+		Status:             metav1.ConditionUnknown,
+		ObservedGeneration: 1,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
+		Message:            "The trafficManagerBackend is being reconciled",
+	}
+	notFoundReadyCondition := metav1.Condition{
+		Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 1,
+		Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonNotAccepted),
+		Message:            `The Accepted condition is False with reason "Invalid": ServiceImport "test-import" is not found`,
+	}
+	tests := []struct {
+		name              string
+		serviceImport     *fleetnetv1alpha1.ServiceImport
+		status            fleetnetv1beta1.TrafficManagerBackendStatus
+		wantServiceImport bool
+		wantStatusUpdate  bool
+		wantStatus        fleetnetv1beta1.TrafficManagerBackendStatus
+	}{
+		{
+			name: "valid serviceImport",
+			serviceImport: &fleetnetv1alpha1.ServiceImport{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-import", Namespace: "test-ns"},
+			},
+			status:            fleetnetv1beta1.TrafficManagerBackendStatus{Endpoints: endpoints},
+			wantServiceImport: true,
+			wantStatus:        fleetnetv1beta1.TrafficManagerBackendStatus{Endpoints: endpoints},
+		},
+		{
+			name:             "serviceImport is being deleted",
+			serviceImport:    deletingServiceImport,
+			status:           fleetnetv1beta1.TrafficManagerBackendStatus{Endpoints: endpoints},
+			wantStatusUpdate: true,
+			wantStatus: fleetnetv1beta1.TrafficManagerBackendStatus{
+				Endpoints:  endpoints, // the endpoints are left untouched
+				Conditions: []metav1.Condition{deletingCondition, deletingReadyCondition},
+			},
+		},
+		{
+			name:          "serviceImport is still being deleted",
+			serviceImport: deletingServiceImport,
+			status: fleetnetv1beta1.TrafficManagerBackendStatus{
+				Endpoints:  endpoints,
+				Conditions: []metav1.Condition{deletingCondition, deletingReadyCondition},
+			},
+			wantStatus: fleetnetv1beta1.TrafficManagerBackendStatus{
+				Endpoints:  endpoints,
+				Conditions: []metav1.Condition{deletingCondition, deletingReadyCondition},
+			},
+		},
+		{
+			name:             "serviceImport is not found",
+			status:           fleetnetv1beta1.TrafficManagerBackendStatus{Endpoints: endpoints},
+			wantStatusUpdate: true,
+			wantStatus: fleetnetv1beta1.TrafficManagerBackendStatus{
+				Endpoints:  []fleetnetv1beta1.TrafficManagerEndpointStatus{},
+				Conditions: []metav1.Condition{notFoundCondition, notFoundReadyCondition},
+			},
+		},
+		{
+			name: "serviceImport is still not found",
+			status: fleetnetv1beta1.TrafficManagerBackendStatus{
+				Endpoints:  []fleetnetv1beta1.TrafficManagerEndpointStatus{},
+				Conditions: []metav1.Condition{notFoundCondition, notFoundReadyCondition},
+			},
+			wantStatus: fleetnetv1beta1.TrafficManagerBackendStatus{
+				Endpoints:  []fleetnetv1beta1.TrafficManagerEndpointStatus{},
+				Conditions: []metav1.Condition{notFoundCondition, notFoundReadyCondition},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-backend",
+					Namespace:  "test-ns",
+					UID:        "uid",
+					Generation: 1,
+				},
+				Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+					Profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "test-profile"},
+					Backend: fleetnetv1beta1.TrafficManagerBackendRef{Name: "test-import"},
+					Weight:  ptr.To(int64(100)),
+				},
+				Status: tc.status,
+			}
+			objs := []client.Object{backend}
+			if tc.serviceImport != nil {
+				objs = append(objs, tc.serviceImport.DeepCopy())
+			}
+			statusUpdateCalls := 0
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objs...).
+				WithStatusSubresource(backend).
+				WithInterceptorFuncs(interceptor.Funcs{
+					SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+						statusUpdateCalls++
+						return applyStatusForTest(ctx, c, subResourceName, obj, patch, opts...)
+					},
+				}).
+				Build()
+			// The Azure clients are not set as the Azure Traffic Manager profile has no endpoints to delete.
+			r := &Reconciler{Client: fakeClient, Recorder: record.NewFakeRecorder(10)}
+			atmProfile := &armtrafficmanager.Profile{
+				Name:       ptr.To("test-atm-profile"),
+				Properties: &armtrafficmanager.ProfileProperties{},
+			}
+			got, err := r.validateServiceImportAndCleanupEndpointsIfInvalid(context.Background(), nil, "rg", backend, atmProfile)
+			if err != nil {
+				t.Fatalf("validateServiceImportAndCleanupEndpointsIfInvalid() got error %v, want nil", err)
+			}
+			if gotServiceImport := got != nil; gotServiceImport != tc.wantServiceImport {
+				t.Errorf("validateServiceImportAndCleanupEndpointsIfInvalid() got serviceImport %v, want serviceImport %v", got, tc.wantServiceImport)
+			}
+			if gotStatusUpdate := statusUpdateCalls > 0; gotStatusUpdate != tc.wantStatusUpdate {
+				t.Errorf("validateServiceImportAndCleanupEndpointsIfInvalid() got %d status updates, want status update %v", statusUpdateCalls, tc.wantStatusUpdate)
+			}
+			if diff := cmp.Diff(tc.wantStatus, backend.Status, cmpopts.EquateEmpty(), cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("validateServiceImportAndCleanupEndpointsIfInvalid() status mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEmitTrafficManagerBackendEndpointsMetric(t *testing.T) {
+	metricMetadata := `
+		# HELP fleet_networking_traffic_manager_backend_endpoints Number of the endpoints of traffic manager backend in each state
+		# TYPE fleet_networking_traffic_manager_backend_endpoints gauge
+	`
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "ns"},
+	}
+	trafficManagerBackendEndpoints.Reset()
+	defer trafficManagerBackendEndpoints.Reset()
+
+	emitTrafficManagerBackendEndpointsMetric(backend, 2, 0, 1)
+	emitTrafficManagerBackendEndpointsMetric(backend, 3, 1, 0) // overrides the previous values
+	want := `
+		fleet_networking_traffic_manager_backend_endpoints{name="backend",namespace="ns",state="accepted"} 3
+		fleet_networking_traffic_manager_backend_endpoints{name="backend",namespace="ns",state="bad"} 0
+		fleet_networking_traffic_manager_backend_endpoints{name="backend",namespace="ns",state="invalid"} 1
+	`
+	if err := testutil.CollectAndCompare(trafficManagerBackendEndpoints, strings.NewReader(metricMetadata+want)); err != nil {
+		t.Errorf("%s", err)
+	}
+
+	trafficManagerBackendEndpoints.DeletePartialMatch(prometheus.Labels{"namespace": backend.GetNamespace(), "name": backend.GetName()})
+	if c := testutil.CollectAndCount(trafficManagerBackendEndpoints); c != 0 {
+		t.Errorf("metric counts after deletion, got %d, want 0", c)
+	}
+}
+
+func TestFindAdoptingEndpoint(t *testing.T) {
+	adoptableTargets := map[string]string{
+		"resourceid-1":    "fleet-uid#test-import#cluster-1",
+		"www.contoso.com": "fleet-uid#test-import#cluster-2",
+	}
+	backends := []fleetnetv1beta1.TrafficManagerBackend{
+		{ObjectMeta: metav1.ObjectMeta{Name: "other-backend", Namespace: "test-ns", UID: "other-uid"}},
+	}
+	tests := []struct {
+		name             string
+		endpointName     string
+		endpoint         armtrafficmanager.Endpoint
+		adoptableTargets map[string]string
+		backends         []fleetnetv1beta1.TrafficManagerBackend
+		want             string
+	}{
+		{
+			name:         "azure endpoint with the same target resource",
+			endpointName: "manual-endpoint",
+			endpoint: armtrafficmanager.Endpoint{
+				Properties: &armtrafficmanager.EndpointProperties{TargetResourceID: ptr.To("ResourceID-1")},
+			},
+			adoptableTargets: adoptableTargets,
+			want:             "fleet-uid#test-import#cluster-1",
+		},
+		{
+			name:         "external endpoint with the same target",
+			endpointName: "manual-endpoint",
+			endpoint: armtrafficmanager.Endpoint{
+				Properties: &armtrafficmanager.EndpointProperties{Target: ptr.To("WWW.contoso.com")},
+			},
+			adoptableTargets: adoptableTargets,
+			want:             "fleet-uid#test-import#cluster-2",
+		},
+		{
+			name:         "endpoint with a different target",
+			endpointName: "manual-endpoint",
+			endpoint: armtrafficmanager.Endpoint{
+				Properties: &armtrafficmanager.EndpointProperties{TargetResourceID: ptr.To("resourceID-2")},
+			},
+			adoptableTargets: adoptableTargets,
+		},
+		{
+			name:         "endpoint without target",
+			endpointName: "manual-endpoint",
+			endpoint: armtrafficmanager.Endpoint{
+				Properties: &armtrafficmanager.EndpointProperties{},
+			},
+			adoptableTargets: adoptableTargets,
+		},
+		{
+			name:         "endpoint owned by another backend",
+			endpointName: "fleet-other-uid#test-import#cluster-1",
+			endpoint: armtrafficmanager.Endpoint{
+				Properties: &armtrafficmanager.EndpointProperties{TargetResourceID: ptr.To("resourceID-1")},
+			},
+			adoptableTargets: adoptableTargets,
+			backends:         backends,
+		},
+		{
+			name:         "orphaned endpoint created by the fleet controller",
+			endpointName: "fleet-deleted-uid#test-import#cluster-1",
+			endpoint: armtrafficmanager.Endpoint{
+				Properties: &armtrafficmanager.EndpointProperties{TargetResourceID: ptr.To("resourceID-1")},
+			},
+			adoptableTargets: adoptableTargets,
+			backends:         backends,
+			want:             "fleet-uid#test-import#cluster-1",
+		},
+		{
+			name:         "adoption is disabled",
+			endpointName: "manual-endpoint",
+			endpoint: armtrafficmanager.Endpoint{
+				Properties: &armtrafficmanager.EndpointProperties{TargetResourceID: ptr.To("resourceID-1")},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := findAdoptingEndpoint(DefaultAzureResourceEndpointNamePrefix, tc.endpointName, tc.endpoint, tc.adoptableTargets, tc.backends); got != tc.want {
+				t.Errorf("findAdoptingEndpoint() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUpdateTrafficManagerEndpoints_Adoption(t *testing.T) {
+	endpointName := "fleet-uid#test-import#cluster-1"
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantDeleted []string
+	}{
+		{
+			name:        "adoption is enabled",
+			annotations: map[string]string{objectmeta.TrafficManagerBackendAnnotationAdoptEndpoints: "true"},
+			wantDeleted: []string{"Manual-Endpoint"},
+		},
+		{
+			name:        "adoption is disabled by the annotation",
+			annotations: map[string]string{objectmeta.TrafficManagerBackendAnnotationAdoptEndpoints: "false"},
+		},
+		{
+			name: "adoption is not configured",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotDeleted, gotCreated []string
+			fakeServer := armtrafficmanagerfake.EndpointsServer{
+				CreateOrUpdate: func(_ context.Context, _ string, _ string, _ armtrafficmanager.EndpointType, name string, endpoint armtrafficmanager.Endpoint, _ *armtrafficmanager.EndpointsClientCreateOrUpdateOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientCreateOrUpdateResponse], errResp azcorefake.ErrorResponder) {
+					gotCreated = append(gotCreated, name)
+					endpoint.ID = ptr.To("endpoint-id")
+					resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientCreateOrUpdateResponse{Endpoint: endpoint}, nil)
+					return resp, errResp
+				},
+				Delete: func(_ context.Context, _ string, _ string, _ armtrafficmanager.EndpointType, name string, _ *armtrafficmanager.EndpointsClientDeleteOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientDeleteResponse], errResp azcorefake.ErrorResponder) {
+					gotDeleted = append(gotDeleted, name)
+					resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientDeleteResponse{}, nil)
+					return resp, errResp
+				},
+			}
+			clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+				&arm.ClientOptions{
+					ClientOptions: azcore.ClientOptions{
+						Transport: armtrafficmanagerfake.NewEndpointsServerTransport(&fakeServer),
+						Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+					},
+				})
+			if err != nil {
+				t.Fatalf("failed to create the client factory: %v", err)
+			}
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-backend",
+					Namespace:   "test-ns",
+					UID:         "uid",
+					Annotations: tc.annotations,
+				},
+			}
+			otherBackend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "other-backend",
+					Namespace: "test-ns",
+					UID:       "other-uid",
+				},
+			}
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			r := &Reconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(backend, otherBackend).Build(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+			clients := &azureclient.TrafficManagerClients{EndpointsClient: clientFactory.NewEndpointsClient()}
+			profile := &armtrafficmanager.Profile{
+				Name: ptr.To("test-profile"),
+				Properties: &armtrafficmanager.ProfileProperties{
+					Endpoints: []*armtrafficmanager.Endpoint{
+						{
+							Name: ptr.To("Manual-Endpoint"),
+							Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+							Properties: &armtrafficmanager.EndpointProperties{
+								TargetResourceID: ptr.To("ResourceID-1"),
+							},
+						},
+						{
+							Name: ptr.To("fleet-other-uid#test-import#cluster-1"),
+							Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+							Properties: &armtrafficmanager.EndpointProperties{
+								TargetResourceID: ptr.To("resourceID-1"),
+							},
+						},
+						{
+							Name: ptr.To("another-endpoint"),
+							Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+							Properties: &armtrafficmanager.EndpointProperties{
+								TargetResourceID: ptr.To("resourceID-2"),
+							},
 						},
 					},
 				},
-			},
-			new: &fleetnetv1beta1.TrafficManagerProfile{
-				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					Conditions: []metav1.Condition{
-						{
-							Type:   string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
-							Status: metav1.ConditionTrue,
-							Reason: "Programmed",
+			}
+			desiredEndpoints := map[string]desiredEndpoint{
+				endpointName: {
+					Endpoint: armtrafficmanager.Endpoint{
+						Name: ptr.To(endpointName),
+						Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+						Properties: &armtrafficmanager.EndpointProperties{
+							TargetResourceID: ptr.To("resourceID-1"),
+							EndpointStatus:   ptr.To(armtrafficmanager.EndpointStatusEnabled),
+							Weight:           ptr.To(int64(1)),
 						},
 					},
+					FromCluster: fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: "cluster-1"},
+					},
 				},
+			}
+			gotAccepted, gotBadEndpoints, err := r.updateTrafficManagerEndpointsAndUpdateStatusIfUnknown(context.Background(), clients, "test-rg", backend, profile, desiredEndpoints)
+			if err != nil {
+				t.Fatalf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got error %v, want nil", err)
+			}
+			if len(gotAccepted) != 1 || len(gotBadEndpoints) != 0 {
+				t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() got %d accepted and %d bad endpoints, want 1 accepted and 0 bad", len(gotAccepted), len(gotBadEndpoints))
+			}
+			if diff := cmp.Diff(tc.wantDeleted, gotDeleted); diff != "" {
+				t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() deleted endpoints mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]string{endpointName}, gotCreated); diff != "" {
+				t.Errorf("updateTrafficManagerEndpointsAndUpdateStatusIfUnknown() created endpoints mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateAndProcessServiceImportForBackend_MissingExport(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+	}
+	serviceImport := &fleetnetv1alpha1.ServiceImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-import",
+			Namespace: "test-ns",
+		},
+		Status: fleetnetv1alpha1.ServiceImportStatus{
+			Clusters: []fleetnetv1alpha1.ClusterStatus{
+				{Cluster: "cluster-1"},
+				{Cluster: "cluster-2"},
 			},
-			want: true,
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := shouldHandleTrafficManagerProfileUpdateEvent(tt.old, tt.new); got != tt.want {
-				t.Errorf("shouldHandleTrafficManagerProfileUpdateEvent() = %v, want %v", got, tt.want)
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-backend",
+			Namespace:  "test-ns",
+			UID:        "uid",
+			Generation: 1,
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{Name: "test-import"},
+			Weight:  ptr.To(int64(100)),
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(backend, geographicInternalServiceExportForTest("cluster-1", "")).
+		WithStatusSubresource(backend).
+		WithInterceptorFuncs(interceptor.Funcs{SubResourcePatch: applyStatusForTest}).
+		WithIndex(&fleetnetv1alpha1.InternalServiceExport{}, exportedServiceFieldNamespacedName, func(o client.Object) []string {
+			return []string{o.(*fleetnetv1alpha1.InternalServiceExport).Spec.ServiceReference.NamespacedName}
+		}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: recorder,
+	}
+
+	// The same internalServiceExport is missing in both of the reconciliations.
+	for i := 0; i < 2; i++ {
+		_, _, err := r.validateAndProcessServiceImportForBackend(context.Background(), profile, &armtrafficmanager.Profile{}, backend, serviceImport)
+		if !errors.Is(err, errInternalServiceExportNotFound) {
+			t.Fatalf("validateAndProcessServiceImportForBackend() got error %v, want %v", err, errInternalServiceExportNotFound)
+		}
+	}
+	if got := len(recorder.Events); got != 1 {
+		t.Errorf("validateAndProcessServiceImportForBackend() emitted %d events, want 1", got)
+	}
+	cond := meta.FindStatusCondition(backend.Status.Conditions, string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted))
+	if cond == nil || cond.Status != metav1.ConditionUnknown || cond.Reason != string(fleetnetv1beta1.TrafficManagerBackendReasonPending) {
+		t.Errorf("validateAndProcessServiceImportForBackend() got condition %+v, want Unknown with Pending reason", cond)
+	}
+}
+
+func TestMissingExportRequeueResult(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		cond *metav1.Condition
+		want ctrl.Result
+	}{
+		{
+			name: "no condition",
+			want: ctrl.Result{RequeueAfter: missingExportRequeueDelay},
+		},
+		{
+			name: "condition is not unknown",
+			cond: &metav1.Condition{Status: metav1.ConditionTrue, LastTransitionTime: metav1.NewTime(now.Add(-time.Hour))},
+			want: ctrl.Result{RequeueAfter: missingExportRequeueDelay},
+		},
+		{
+			name: "just became unknown",
+			cond: &metav1.Condition{Status: metav1.ConditionUnknown, LastTransitionTime: metav1.NewTime(now)},
+			want: ctrl.Result{RequeueAfter: missingExportRequeueDelay},
+		},
+		{
+			name: "unknown for a while",
+			cond: &metav1.Condition{Status: metav1.ConditionUnknown, LastTransitionTime: metav1.NewTime(now.Add(-4 * time.Second))},
+			want: ctrl.Result{RequeueAfter: 4 * time.Second},
+		},
+		{
+			name: "delay is capped",
+			cond: &metav1.Condition{Status: metav1.ConditionUnknown, LastTransitionTime: metav1.NewTime(now.Add(-2 * time.Minute))},
+			want: ctrl.Result{RequeueAfter: missingExportRequeueMaxDelay},
+		},
+		{
+			name: "stop requeueing after the timeout",
+			cond: &metav1.Condition{Status: metav1.ConditionUnknown, LastTransitionTime: metav1.NewTime(now.Add(-time.Hour))},
+			want: ctrl.Result{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := missingExportRequeueResult(tc.cond, now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("missingExportRequeueResult() mismatch (-want, +got):\n%s", diff)
 			}
 		})
 	}
 }
-func TestShouldHandleInternalServiceExportUpdateEvent(t *testing.T) {
+func TestHasNoExportedServicesExceededThreshold(t *testing.T) {
+	now := time.Now()
+	threshold := 10 * time.Minute
 	tests := []struct {
 		name string
-		old  *fleetnetv1alpha1.InternalServiceExport
-		new  *fleetnetv1alpha1.InternalServiceExport
+		cond *metav1.Condition
 		want bool
 	}{
 		{
-			name: "specs are the same",
-			old: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-					Weight:                 ptr.To(int64(100)),
-					ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
-						ClusterID:       "cluster-1",
-						Kind:            "Service",
-						Namespace:       "default",
-						Name:            "service-1",
-						NamespacedName:  "default/service-1",
-						ResourceVersion: "123",
-						Generation:      1,
-						UID:             "uid-1",
-					},
-				},
-			},
-			new: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-					Weight:                 ptr.To(int64(100)),
-					ServiceReference: fleetnetv1alpha1.ExportedObjectReference{
-						ClusterID:       "cluster-1",
-						Kind:            "Service",
-						Namespace:       "default",
-						Name:            "service-1",
-						NamespacedName:  "default/service-1",
-						ResourceVersion: "123",
-						Generation:      1,
-						UID:             "uid-1",
-					},
-				},
-			},
-			want: false,
+			name: "no condition",
 		},
 		{
-			name: "service type changed",
-			old: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeClusterIP,
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-				},
-			},
-			new: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-				},
+			name: "condition is true",
+			cond: &metav1.Condition{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+				Status:             metav1.ConditionTrue,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonAccepted),
+				LastTransitionTime: metav1.NewTime(now.Add(-time.Hour)),
 			},
-			want: true,
 		},
 		{
-			name: "public IP resource ID changed",
-			old: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-				},
-			},
-			new: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-2"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-				},
+			name: "unknown without exported services within the threshold",
+			cond: &metav1.Condition{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+				Status:             metav1.ConditionUnknown,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
+				Message:            noExportedServicesMessage,
+				LastTransitionTime: metav1.NewTime(now.Add(-time.Minute)),
 			},
-			want: true,
 		},
 		{
-			name: "DNS label configuration changed",
-			old: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   false,
-					IsInternalLoadBalancer: false,
-				},
-			},
-			new: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-				},
+			name: "unknown without exported services exceeding the threshold",
+			cond: &metav1.Condition{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+				Status:             metav1.ConditionUnknown,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
+				Message:            noExportedServicesMessage,
+				LastTransitionTime: metav1.NewTime(now.Add(-time.Hour)),
 			},
 			want: true,
 		},
 		{
-			name: "internal load balancer flag changed",
-			old: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-				},
-			},
-			new: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: true,
-				},
+			name: "unknown for other reasons exceeding the threshold",
+			cond: &metav1.Condition{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+				Status:             metav1.ConditionUnknown,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonPending),
+				Message:            "other reasons",
+				LastTransitionTime: metav1.NewTime(now.Add(-time.Hour)),
 			},
-			want: true,
 		},
 		{
-			name: "weight changed",
-			old: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-					Weight:                 ptr.To(int64(100)),
-				},
-			},
-			new: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-					Weight:                 ptr.To(int64(200)),
-				},
+			name: "already false without exported services",
+			cond: &metav1.Condition{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+				Status:             metav1.ConditionFalse,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonNoExportedServices),
+				LastTransitionTime: metav1.NewTime(now),
 			},
 			want: true,
 		},
 		{
-			name: "weight changed from nil to value",
-			old: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-					Weight:                 nil,
-				},
-			},
-			new: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-					Weight:                 ptr.To(int64(100)),
-				},
+			name: "false for other reasons",
+			cond: &metav1.Condition{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+				Status:             metav1.ConditionFalse,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonInvalid),
+				LastTransitionTime: metav1.NewTime(now.Add(-time.Hour)),
 			},
-			want: true,
 		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := &fleetnetv1beta1.TrafficManagerBackend{}
+			if tc.cond != nil {
+				backend.Status.Conditions = []metav1.Condition{*tc.cond}
+			}
+			if got := hasNoExportedServicesExceededThreshold(backend, threshold, now); got != tc.want {
+				t.Errorf("hasNoExportedServicesExceededThreshold() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNoExportedServicesRequeueResult(t *testing.T) {
+	now := time.Now()
+	threshold := 10 * time.Minute
+	tests := []struct {
+		name string
+		cond *metav1.Condition
+		want ctrl.Result
+	}{
 		{
-			name: "public IP resource ID changed from nil to value",
-			old: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     nil,
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-				},
-			},
-			new: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-				},
-			},
-			want: true,
+			name: "no condition",
+			want: ctrl.Result{},
 		},
 		{
-			name: "public IP resource ID changed from value to nil",
-			old: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     ptr.To("resource-id-1"),
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-				},
-			},
-			new: &fleetnetv1alpha1.InternalServiceExport{
-				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
-					Type:                   corev1.ServiceTypeLoadBalancer,
-					PublicIPResourceID:     nil,
-					IsDNSLabelConfigured:   true,
-					IsInternalLoadBalancer: false,
-				},
-			},
-			want: true,
+			name: "condition is false",
+			cond: &metav1.Condition{Status: metav1.ConditionFalse, LastTransitionTime: metav1.NewTime(now)},
+			want: ctrl.Result{},
+		},
+		{
+			name: "just became unknown",
+			cond: &metav1.Condition{Status: metav1.ConditionUnknown, LastTransitionTime: metav1.NewTime(now)},
+			want: ctrl.Result{RequeueAfter: threshold},
+		},
+		{
+			name: "unknown for a while",
+			cond: &metav1.Condition{Status: metav1.ConditionUnknown, LastTransitionTime: metav1.NewTime(now.Add(-4 * time.Minute))},
+			want: ctrl.Result{RequeueAfter: 6 * time.Minute},
+		},
+		{
+			name: "threshold is exceeded",
+			cond: &metav1.Condition{Status: metav1.ConditionUnknown, LastTransitionTime: metav1.NewTime(now.Add(-time.Hour))},
+			want: ctrl.Result{RequeueAfter: missingExportRequeueDelay},
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := shouldHandleInternalServiceExportUpdateEvent(tt.old, tt.new); got != tt.want {
-				t.Errorf("shouldHandleInternalServiceExportUpdateEvent() = %v, want %v", got, tt.want)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := noExportedServicesRequeueResult(tc.cond, threshold, now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("noExportedServicesRequeueResult() mismatch (-want, +got):\n%s", diff)
 			}
 		})
 	}
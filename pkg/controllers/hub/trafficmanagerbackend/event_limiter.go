@@ -0,0 +1,56 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package trafficmanagerbackend
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// azureAPIErrorEventKey identifies the AzureAPIError events of a backend caused by the same category of errors.
+type azureAPIErrorEventKey struct {
+	backendUID types.UID
+	category   string
+}
+
+// azureAPIErrorEventLimiter limits the AzureAPIError events emitted for each backend and error category, so that the
+// retries during a sustained failure, for example, while the Azure Resource Manager is throttling the requests, do not
+// flood the events.
+// The zero value is ready to use.
+type azureAPIErrorEventLimiter struct {
+	mu sync.Mutex
+	// lastEmitted is the time when the last event of each backend and error category was emitted.
+	lastEmitted map[azureAPIErrorEventKey]time.Time
+}
+
+// allow returns true and records the time if no event of the backend and error category has been emitted within the
+// interval before now.
+// It always returns true when the interval is not positive.
+func (l *azureAPIErrorEventLimiter) allow(backendUID types.UID, category string, interval time.Duration, now time.Time) bool {
+	if interval <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := azureAPIErrorEventKey{backendUID: backendUID, category: category}
+	if last, ok := l.lastEmitted[key]; ok && now.Sub(last) < interval {
+		return false
+	}
+	if l.lastEmitted == nil {
+		l.lastEmitted = make(map[azureAPIErrorEventKey]time.Time)
+	}
+	// Drop the expired records so that the map won't grow with the backends which have recovered or been deleted.
+	for k, last := range l.lastEmitted {
+		if now.Sub(last) >= interval {
+			delete(l.lastEmitted, k)
+		}
+	}
+	l.lastEmitted[key] = now
+	return true
+}
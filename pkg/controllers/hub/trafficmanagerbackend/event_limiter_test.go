@@ -0,0 +1,113 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package trafficmanagerbackend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+)
+
+func TestAzureAPIErrorEventLimiter_Allow(t *testing.T) {
+	now := time.Now()
+	type call struct {
+		backendUID types.UID
+		category   string
+		at         time.Duration
+		want       bool
+	}
+	tests := []struct {
+		name     string
+		interval time.Duration
+		calls    []call
+	}{
+		{
+			name:     "duplicate events within the interval are suppressed",
+			interval: time.Minute,
+			calls: []call{
+				{backendUID: "backend-1", category: "Throttled", at: 0, want: true},
+				{backendUID: "backend-1", category: "Throttled", at: time.Second, want: false},
+				{backendUID: "backend-1", category: "Throttled", at: 59 * time.Second, want: false},
+				{backendUID: "backend-1", category: "Throttled", at: time.Minute, want: true},
+			},
+		},
+		{
+			name:     "different categories are limited independently",
+			interval: time.Minute,
+			calls: []call{
+				{backendUID: "backend-1", category: "Throttled", at: 0, want: true},
+				{backendUID: "backend-1", category: "ServerError", at: time.Second, want: true},
+				{backendUID: "backend-1", category: "Throttled", at: 2 * time.Second, want: false},
+				{backendUID: "backend-1", category: "ServerError", at: 3 * time.Second, want: false},
+			},
+		},
+		{
+			name:     "different backends are limited independently",
+			interval: time.Minute,
+			calls: []call{
+				{backendUID: "backend-1", category: "Throttled", at: 0, want: true},
+				{backendUID: "backend-2", category: "Throttled", at: time.Second, want: true},
+				{backendUID: "backend-2", category: "Throttled", at: 2 * time.Second, want: false},
+			},
+		},
+		{
+			name:     "not positive interval disables the limit",
+			interval: 0,
+			calls: []call{
+				{backendUID: "backend-1", category: "Throttled", at: 0, want: true},
+				{backendUID: "backend-1", category: "Throttled", at: 0, want: true},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l := &azureAPIErrorEventLimiter{}
+			for i, c := range tc.calls {
+				if got := l.allow(c.backendUID, c.category, tc.interval, now.Add(c.at)); got != c.want {
+					t.Errorf("allow() of call %d = %v, want %v", i, got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAzureAPIErrorEventLimiter_DropsExpiredRecords(t *testing.T) {
+	now := time.Now()
+	l := &azureAPIErrorEventLimiter{}
+	l.allow("backend-1", "Throttled", time.Minute, now)
+	l.allow("backend-2", "Throttled", time.Minute, now.Add(30*time.Second))
+	l.allow("backend-3", "Throttled", time.Minute, now.Add(time.Minute))
+	if got, want := len(l.lastEmitted), 2; got != want {
+		t.Errorf("allow() kept %d records, want %d", got, want)
+	}
+}
+
+func TestRecordAzureAPIErrorEvent(t *testing.T) {
+	backend := &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backend",
+			Namespace: "default",
+			UID:       "backend-uid",
+		},
+	}
+	throttledErr := &azcore.ResponseError{StatusCode: 429}
+	serverErr := &azcore.ResponseError{StatusCode: 500}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{Recorder: recorder}
+	r.recordAzureAPIErrorEvent(backend, throttledErr, "Failed to create or update Azure Traffic Manager endpoint %q: %v", "endpoint-1", throttledErr)
+	r.recordAzureAPIErrorEvent(backend, throttledErr, "Failed to create or update Azure Traffic Manager endpoint %q: %v", "endpoint-2", throttledErr)
+	r.recordAzureAPIErrorEvent(backend, serverErr, "Failed to create or update Azure Traffic Manager endpoint %q: %v", "endpoint-1", serverErr)
+	if got, want := len(recorder.Events), 2; got != want {
+		t.Errorf("recordAzureAPIErrorEvent() emitted %d events, want %d", got, want)
+	}
+}
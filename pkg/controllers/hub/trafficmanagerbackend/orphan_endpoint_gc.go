@@ -0,0 +1,181 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package trafficmanagerbackend
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/azureclient"
+	"go.goms.io/fleet-networking/pkg/common/azureerrors"
+	"go.goms.io/fleet-networking/pkg/common/metrics"
+)
+
+const (
+	// DefaultOrphanEndpointGCInterval is the default interval between two garbage collection passes of the orphaned
+	// Azure Traffic Manager endpoints.
+	DefaultOrphanEndpointGCInterval = time.Hour
+)
+
+// OrphanEndpointCollector periodically deletes the Azure Traffic Manager endpoints which were created by the fleet
+// controller while their owner trafficManagerBackends no longer exist, for example, the trafficManagerBackend is
+// force-deleted by removing its finalizer manually.
+// Only the Azure Traffic Manager profiles managed by the trafficManagerProfiles in the hub cluster are checked.
+type OrphanEndpointCollector struct {
+	client.Client
+
+	// AzureClientFactory provides the Azure Traffic Manager clients of the subscription of each profile.
+	AzureClientFactory *azureclient.TrafficManagerClientFactory
+
+	// Interval is the wait time between two garbage collection passes.
+	// DefaultOrphanEndpointGCInterval is used when it's not positive.
+	Interval time.Duration
+
+	// EndpointNamePrefix is the prefix of the Azure Traffic Manager endpoint names created by the trafficManagerBackend
+	// controller, and only the endpoints with this prefix are collected.
+	// DefaultAzureResourceEndpointNamePrefix is used when it's empty.
+	EndpointNamePrefix string
+}
+
+// Start runs the garbage collection passes until the context is canceled.
+// It implements the manager.Runnable interface.
+func (c *OrphanEndpointCollector) Start(ctx context.Context) error {
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultOrphanEndpointGCInterval
+	}
+	klog.V(1).InfoS("Starting the orphaned Azure Traffic Manager endpoint garbage collector", "interval", interval)
+	wait.JitterUntilWithContext(ctx, func(ctx context.Context) {
+		if err := c.collect(ctx); err != nil {
+			klog.ErrorS(err, "Failed to collect the orphaned Azure Traffic Manager endpoints")
+		}
+	}, interval, 0.1, true)
+	klog.V(1).InfoS("Stopped the orphaned Azure Traffic Manager endpoint garbage collector")
+	return nil
+}
+
+// NeedLeaderElection returns true so that only the leader deletes the orphaned endpoints.
+// It implements the manager.LeaderElectionRunnable interface.
+func (c *OrphanEndpointCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// collect runs a single garbage collection pass over all the trafficManagerProfiles.
+func (c *OrphanEndpointCollector) collect(ctx context.Context) error {
+	profileList := &fleetnetv1beta1.TrafficManagerProfileList{}
+	if err := c.Client.List(ctx, profileList); err != nil {
+		klog.ErrorS(err, "Failed to list trafficManagerProfiles")
+		return err
+	}
+	var errs []error
+	for i := range profileList.Items {
+		profile := &profileList.Items[i]
+		if profile.DeletionTimestamp != nil {
+			continue // the Azure Traffic Manager profile will be deleted together with its endpoints
+		}
+		if err := c.collectProfile(ctx, profile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *OrphanEndpointCollector) collectProfile(ctx context.Context, profile *fleetnetv1beta1.TrafficManagerProfile) error {
+	profileKObj := klog.KObj(profile)
+	clients, err := c.AzureClientFactory.Clients(profile.Spec.SubscriptionID)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get Azure Traffic Manager clients", "trafficManagerProfile", profileKObj, "subscriptionID", profile.Spec.SubscriptionID)
+		return err
+	}
+	atmProfileName := generateAzureTrafficManagerProfileNameFunc(profile)
+	startTime := time.Now()
+	getRes, getErr := clients.ProfilesClient.Get(ctx, profile.Spec.ResourceGroup, atmProfileName, nil)
+	metrics.ObserveAzureAPICall(metrics.AzureAPIClientProfiles, metrics.AzureAPIOperationGet, startTime, getErr)
+	if getErr != nil {
+		if azureerrors.IsNotFound(getErr) {
+			klog.V(2).InfoS("Azure Traffic Manager profile does not exist", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
+			return nil
+		}
+		klog.ErrorS(getErr, "Failed to get the Azure Traffic Manager profile", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
+		return getErr
+	}
+	if getRes.Properties == nil || len(getRes.Properties.Endpoints) == 0 {
+		return nil
+	}
+
+	// List the backends after getting the Azure Traffic Manager profile so that the endpoints created by a new backend
+	// won't be treated as orphaned.
+	backendList := &fleetnetv1beta1.TrafficManagerBackendList{}
+	if err := c.Client.List(ctx, backendList); err != nil {
+		klog.ErrorS(err, "Failed to list trafficManagerBackends")
+		return err
+	}
+	namePrefix := c.EndpointNamePrefix
+	if namePrefix == "" {
+		namePrefix = DefaultAzureResourceEndpointNamePrefix
+	}
+	orphans := findOrphanedEndpoints(namePrefix, getRes.Properties.Endpoints, backendList.Items)
+
+	var errs []error
+	for _, endpoint := range orphans {
+		startTime := time.Now()
+		_, deleteErr := clients.EndpointsClient.Delete(ctx, profile.Spec.ResourceGroup, atmProfileName, azureTrafficManagerEndpointType(*endpoint), *endpoint.Name, nil)
+		metrics.ObserveAzureAPICall(metrics.AzureAPIClientEndpoints, metrics.AzureAPIOperationDelete, startTime, deleteErr)
+		if deleteErr != nil {
+			if azureerrors.IsNotFound(deleteErr) {
+				continue
+			}
+			klog.ErrorS(deleteErr, "Failed to delete the orphaned Azure Traffic Manager endpoint", "resourceGroup", profile.Spec.ResourceGroup, "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName, "atmEndpoint", *endpoint.Name)
+			errs = append(errs, deleteErr)
+			continue
+		}
+		orphanEndpointsDeletedTotal.Inc()
+		klog.V(2).InfoS("Deleted the orphaned Azure Traffic Manager endpoint", "resourceGroup", profile.Spec.ResourceGroup, "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName, "atmEndpoint", *endpoint.Name)
+	}
+	return errors.Join(errs...)
+}
+
+// findOrphanedEndpoints returns the endpoints which were created by the fleet controller with the endpoint name prefix
+// while none of the backends owns them.
+// The endpoints which are not created by the fleet controller with the prefix are never returned.
+func findOrphanedEndpoints(namePrefix string, endpoints []*armtrafficmanager.Endpoint, backends []fleetnetv1beta1.TrafficManagerBackend) []*armtrafficmanager.Endpoint {
+	var orphans []*armtrafficmanager.Endpoint
+	for _, endpoint := range endpoints {
+		if endpoint == nil || endpoint.Name == nil {
+			continue
+		}
+		endpointName := strings.ToLower(*endpoint.Name) // resource name are case-insensitive
+		if !isFleetManagedEndpoint(namePrefix, endpointName) {
+			continue
+		}
+		owned := false
+		for i := range backends {
+			if isEndpointOwnedByBackend(namePrefix, &backends[i], endpointName) {
+				owned = true
+				break
+			}
+		}
+		if !owned {
+			orphans = append(orphans, endpoint)
+		}
+	}
+	return orphans
+}
+
+// isFleetManagedEndpoint returns true if the endpoint name follows the naming convention of the fleet controller with
+// the endpoint name prefix, which is {EndpointNamePrefix}{TrafficManagerBackendUUID}#{ServiceImportName}#{ClusterName}.
+func isFleetManagedEndpoint(namePrefix, endpoint string) bool {
+	_, _, _, ok := ParseAzureTrafficManagerEndpointName(namePrefix, endpoint)
+	return ok
+}
@@ -0,0 +1,207 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package trafficmanagerbackend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azcorefake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	armtrafficmanagerfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager/fake"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/azureclient"
+)
+
+func TestIsFleetManagedEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		want     bool
+	}{
+		{
+			name:     "fleet managed endpoint",
+			endpoint: "fleet-uid#service#cluster",
+			want:     true,
+		},
+		{
+			name:     "endpoint created by others",
+			endpoint: "my-endpoint",
+			want:     false,
+		},
+		{
+			name:     "endpoint with fleet prefix but not the naming convention",
+			endpoint: "fleet-endpoint",
+			want:     false,
+		},
+		{
+			name:     "endpoint with empty uid",
+			endpoint: "fleet-#service#cluster",
+			want:     false,
+		},
+		{
+			name:     "endpoint created by the controller with another endpoint name prefix",
+			endpoint: "staging-uid#service#cluster",
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFleetManagedEndpoint(DefaultAzureResourceEndpointNamePrefix, tt.endpoint); got != tt.want {
+				t.Errorf("isFleetManagedEndpoint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindOrphanedEndpoints(t *testing.T) {
+	backends := []fleetnetv1beta1.TrafficManagerBackend{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "backend-1",
+				UID:  "uid-1",
+			},
+		},
+	}
+	endpoints := []*armtrafficmanager.Endpoint{
+		{
+			Name: ptr.To("fleet-uid-1#service#cluster-1"),
+		},
+		{
+			Name: ptr.To("FLEET-UID-2#service#cluster-1"), // the name is case-insensitive
+		},
+		{
+			Name: ptr.To("my-endpoint"),
+		},
+		{
+			Name: ptr.To("staging-uid-3#service#cluster-1"), // created by the controller with another prefix
+		},
+		{
+			Name: nil,
+		},
+	}
+	got := findOrphanedEndpoints(DefaultAzureResourceEndpointNamePrefix, endpoints, backends)
+	want := []*armtrafficmanager.Endpoint{
+		{
+			Name: ptr.To("FLEET-UID-2#service#cluster-1"),
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("findOrphanedEndpoints() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOrphanEndpointCollector_Collect(t *testing.T) {
+	var deleted []string
+	fakeServer := armtrafficmanagerfake.ServerFactory{
+		ProfilesServer: armtrafficmanagerfake.ProfilesServer{
+			Get: func(_ context.Context, _ string, profileName string, _ *armtrafficmanager.ProfilesClientGetOptions) (resp azcorefake.Responder[armtrafficmanager.ProfilesClientGetResponse], errResp azcorefake.ErrorResponder) {
+				if profileName != "profile-1" {
+					errResp.SetResponseError(http.StatusNotFound, "NotFound")
+					return resp, errResp
+				}
+				resp.SetResponse(http.StatusOK, armtrafficmanager.ProfilesClientGetResponse{
+					Profile: armtrafficmanager.Profile{
+						Name: ptr.To(profileName),
+						Properties: &armtrafficmanager.ProfileProperties{
+							Endpoints: []*armtrafficmanager.Endpoint{
+								{
+									Name: ptr.To("fleet-uid-1#service#cluster-1"),
+									Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+								},
+								{
+									Name: ptr.To("fleet-uid-2#service#cluster-1"),
+									Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+								},
+								{
+									Name: ptr.To("fleet-uid-2#service#cluster-2"),
+									Type: ptr.To("Microsoft.Network/trafficManagerProfiles/externalEndpoints"),
+								},
+								{
+									Name: ptr.To("my-endpoint"),
+									Type: ptr.To("Microsoft.Network/trafficManagerProfiles/azureEndpoints"),
+								},
+							},
+						},
+					},
+				}, nil)
+				return resp, errResp
+			},
+		},
+		EndpointsServer: armtrafficmanagerfake.EndpointsServer{
+			Delete: func(_ context.Context, _ string, _ string, endpointType armtrafficmanager.EndpointType, endpointName string, _ *armtrafficmanager.EndpointsClientDeleteOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientDeleteResponse], errResp azcorefake.ErrorResponder) {
+				deleted = append(deleted, fmt.Sprintf("%s/%s", endpointType, endpointName))
+				resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientDeleteResponse{}, nil)
+				return resp, errResp
+			},
+		},
+	}
+	clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+		&arm.ClientOptions{
+			ClientOptions: azcore.ClientOptions{
+				Transport: armtrafficmanagerfake.NewServerFactoryTransport(&fakeServer),
+				Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+			},
+		})
+	if err != nil {
+		t.Fatalf("failed to create the client factory: %v", err)
+	}
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "profile-1", Namespace: "ns"},
+				Spec:       fleetnetv1beta1.TrafficManagerProfileSpec{ResourceGroup: "rg"},
+			},
+			&fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "not-found-profile", Namespace: "ns"},
+				Spec:       fleetnetv1beta1.TrafficManagerProfileSpec{ResourceGroup: "rg"},
+			},
+			&fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend-1", Namespace: "ns", UID: "uid-1"},
+			},
+		).
+		Build()
+
+	originalGenerateAzureTrafficManagerProfileNameFunc := generateAzureTrafficManagerProfileNameFunc
+	generateAzureTrafficManagerProfileNameFunc = func(profile *fleetnetv1beta1.TrafficManagerProfile) string {
+		return profile.Name
+	}
+	defer func() {
+		generateAzureTrafficManagerProfileNameFunc = originalGenerateAzureTrafficManagerProfileNameFunc
+	}()
+
+	c := &OrphanEndpointCollector{
+		Client:             fakeClient,
+		AzureClientFactory: azureclient.NewTrafficManagerClientFactoryForClients(clientFactory.NewProfilesClient(), clientFactory.NewEndpointsClient()),
+	}
+	if err := c.collect(context.Background()); err != nil {
+		t.Fatalf("collect() got error %v, want nil", err)
+	}
+	want := []string{
+		"AzureEndpoints/fleet-uid-2#service#cluster-1",
+		"ExternalEndpoints/fleet-uid-2#service#cluster-2",
+	}
+	if diff := cmp.Diff(want, deleted, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("collect() deleted endpoints mismatch (-want +got):\n%s", diff)
+	}
+}
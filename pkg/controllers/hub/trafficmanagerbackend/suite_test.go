@@ -30,6 +30,7 @@ import (
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/azureclient"
 	"go.goms.io/fleet-networking/test/common/trafficmanager/fakeprovider"
 )
 
@@ -311,16 +312,15 @@ var _ = BeforeSuite(func() {
 	generateAzureTrafficManagerProfileNameFunc = func(profile *fleetnetv1beta1.TrafficManagerProfile) string {
 		return profile.Name
 	}
-	generateAzureTrafficManagerEndpointNamePrefixFunc = func(backend *fleetnetv1beta1.TrafficManagerBackend) string {
+	generateAzureTrafficManagerEndpointNamePrefixFunc = func(_ string, backend *fleetnetv1beta1.TrafficManagerBackend) string {
 		return backend.Name + "#"
 	}
 
 	ctx, cancel = context.WithCancel(context.TODO())
 	err = (&Reconciler{
-		Client:          mgr.GetClient(),
-		ProfilesClient:  profileClient,
-		EndpointsClient: endpointClient,
-		Recorder:        mgr.GetEventRecorderFor(ControllerName),
+		Client:             mgr.GetClient(),
+		AzureClientFactory: azureclient.NewTrafficManagerClientFactoryForClients(profileClient, endpointClient),
+		Recorder:           mgr.GetEventRecorderFor(ControllerName),
 	}).SetupWithManager(ctx, mgr, false)
 	Expect(err).ToNot(HaveOccurred())
 
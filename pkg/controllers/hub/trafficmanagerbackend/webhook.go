@@ -0,0 +1,112 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package trafficmanagerbackend
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+)
+
+//+kubebuilder:webhook:path=/validate-networking-fleet-azure-com-v1beta1-trafficmanagerbackend,mutating=false,failurePolicy=ignore,sideEffects=None,groups=networking.fleet.azure.com,resources=trafficmanagerbackends,verbs=create;update,versions=v1beta1,name=vtrafficmanagerbackend.networking.fleet.azure.com,admissionReviewVersions=v1
+
+// Validator rejects creating a trafficManagerBackend which references the same trafficManagerProfile and serviceImport
+// as another trafficManagerBackend in the same namespace, as both backends would create the Azure Traffic Manager
+// endpoints for the same exported services and duplicate their traffic.
+// Updating such a backend is only warned so that the backends created before the validator is enabled can still be
+// updated, for example, to remove their finalizers on deletion.
+// The validator relies on the field indexes set up by Reconciler.SetupWithManager.
+type Validator struct {
+	client.Client
+}
+
+var _ admission.CustomValidator = &Validator{}
+
+// SetupWebhookWithManager registers the validating webhook of the trafficManagerBackend with the manager.
+func (v *Validator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&fleetnetv1beta1.TrafficManagerBackend{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate implements the admission.CustomValidator interface.
+func (v *Validator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	backend, ok := obj.(*fleetnetv1beta1.TrafficManagerBackend)
+	if !ok {
+		return nil, fmt.Errorf("expected a trafficManagerBackend object but got %T", obj)
+	}
+	duplicates, err := v.findDuplicateBackends(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+	if len(duplicates) > 0 {
+		return nil, fmt.Errorf("trafficManagerBackends %v have already exposed the same serviceImport in trafficManagerProfile %q", duplicates, trafficManagerProfileNamespacedName(backend))
+	}
+	return nil, nil
+}
+
+// ValidateUpdate implements the admission.CustomValidator interface.
+func (v *Validator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	backend, ok := newObj.(*fleetnetv1beta1.TrafficManagerBackend)
+	if !ok {
+		return nil, fmt.Errorf("expected a trafficManagerBackend object but got %T", newObj)
+	}
+	if backend.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	duplicates, err := v.findDuplicateBackends(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+	if len(duplicates) > 0 {
+		return admission.Warnings{fmt.Sprintf("trafficManagerBackends %v expose the same serviceImport in trafficManagerProfile %q, which duplicates the traffic", duplicates, trafficManagerProfileNamespacedName(backend))}, nil
+	}
+	return nil, nil
+}
+
+// ValidateDelete implements the admission.CustomValidator interface.
+func (v *Validator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// findDuplicateBackends returns the sorted names of the other trafficManagerBackends in the same namespace which
+// reference the same trafficManagerProfile and any of the serviceImports of the backend.
+// The backends being deleted are ignored as their endpoints are going away.
+func (v *Validator) findDuplicateBackends(ctx context.Context, backend *fleetnetv1beta1.TrafficManagerBackend) ([]string, error) {
+	profileName := trafficManagerProfileNamespacedName(backend)
+	backendList := &fleetnetv1beta1.TrafficManagerBackendList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(backend.Namespace),
+		client.MatchingFields{trafficManagerBackendProfileFieldKey: profileName.String()},
+	}
+	if err := v.Client.List(ctx, backendList, listOpts...); err != nil {
+		klog.ErrorS(err, "Failed to list trafficManagerBackends of the trafficManagerProfile", "trafficManagerBackend", klog.KObj(backend), "trafficManagerProfile", profileName)
+		return nil, fmt.Errorf("failed to list the trafficManagerBackends of trafficManagerProfile %q: %w", profileName, err)
+	}
+	names := serviceImportNames(backend)
+	var duplicates []string
+	for i := range backendList.Items {
+		other := &backendList.Items[i]
+		if other.Name == backend.Name || other.DeletionTimestamp != nil {
+			continue
+		}
+		if slices.ContainsFunc(serviceImportNames(other), func(name string) bool { return slices.Contains(names, name) }) {
+			duplicates = append(duplicates, other.Name)
+		}
+	}
+	sort.Strings(duplicates)
+	return duplicates, nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package trafficmanagerbackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+)
+
+func backendForValidatorTest(name, profileName, serviceImportName string, additionalNames ...string) *fleetnetv1beta1.TrafficManagerBackend {
+	return &fleetnetv1beta1.TrafficManagerBackend{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test-ns",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
+			Profile: fleetnetv1beta1.TrafficManagerProfileRef{
+				Name: profileName,
+			},
+			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
+				Name:            serviceImportName,
+				AdditionalNames: additionalNames,
+			},
+		},
+	}
+}
+
+func TestValidator(t *testing.T) {
+	deletingBackend := backendForValidatorTest("deleting-backend", "test-profile", "deleting-import")
+	deletingBackend.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+	deletingBackend.Finalizers = []string{"test-finalizer"}
+	existing := []client.Object{
+		backendForValidatorTest("existing-backend", "test-profile", "test-import"),
+		backendForValidatorTest("green-backend", "test-profile", "other-import", "green-import"),
+		backendForValidatorTest("other-profile-backend", "other-profile", "test-import"),
+		deletingBackend,
+	}
+
+	tests := []struct {
+		name    string
+		backend *fleetnetv1beta1.TrafficManagerBackend
+		// wantDuplicates is true if other backends expose the same serviceImport, which fails the creation and warns the update.
+		wantDuplicates bool
+	}{
+		{
+			name:    "different serviceImport",
+			backend: backendForValidatorTest("new-backend", "test-profile", "new-import"),
+		},
+		{
+			name:    "same serviceImport in a different profile",
+			backend: backendForValidatorTest("new-backend", "another-profile", "test-import"),
+		},
+		{
+			name:           "same profile and serviceImport",
+			backend:        backendForValidatorTest("new-backend", "test-profile", "test-import"),
+			wantDuplicates: true,
+		},
+		{
+			name:           "additional serviceImport referenced by another backend",
+			backend:        backendForValidatorTest("new-backend", "test-profile", "new-import", "test-import"),
+			wantDuplicates: true,
+		},
+		{
+			name:           "serviceImport referenced by the additional serviceImports of another backend",
+			backend:        backendForValidatorTest("new-backend", "test-profile", "green-import"),
+			wantDuplicates: true,
+		},
+		{
+			name:    "serviceImport referenced by a backend being deleted",
+			backend: backendForValidatorTest("new-backend", "test-profile", "deleting-import"),
+		},
+		{
+			name:    "itself",
+			backend: backendForValidatorTest("existing-backend", "test-profile", "test-import"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(existing...).
+				WithIndex(&fleetnetv1beta1.TrafficManagerBackend{}, trafficManagerBackendProfileFieldKey, func(o client.Object) []string {
+					return []string{trafficManagerProfileNamespacedName(o.(*fleetnetv1beta1.TrafficManagerBackend)).String()}
+				}).
+				Build()
+			v := &Validator{Client: fakeClient}
+
+			_, err := v.ValidateCreate(context.Background(), tc.backend)
+			if gotErr := err != nil; gotErr != tc.wantDuplicates {
+				t.Errorf("ValidateCreate() got error %v, want error %v", err, tc.wantDuplicates)
+			}
+
+			warnings, err := v.ValidateUpdate(context.Background(), tc.backend, tc.backend)
+			if err != nil {
+				t.Fatalf("ValidateUpdate() got error %v, want nil", err)
+			}
+			if gotWarnings := len(warnings) > 0; gotWarnings != tc.wantDuplicates {
+				t.Errorf("ValidateUpdate() got warnings %v, want warnings %v", warnings, tc.wantDuplicates)
+			}
+		})
+	}
+}
+
+func TestValidator_UpdateDeletingBackend(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(backendForValidatorTest("existing-backend", "test-profile", "test-import")).
+		WithIndex(&fleetnetv1beta1.TrafficManagerBackend{}, trafficManagerBackendProfileFieldKey, func(o client.Object) []string {
+			return []string{trafficManagerProfileNamespacedName(o.(*fleetnetv1beta1.TrafficManagerBackend)).String()}
+		}).
+		Build()
+	v := &Validator{Client: fakeClient}
+
+	backend := backendForValidatorTest("new-backend", "test-profile", "test-import")
+	backend.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+	warnings, err := v.ValidateUpdate(context.Background(), backend, backend)
+	if err != nil {
+		t.Fatalf("ValidateUpdate() got error %v, want nil", err)
+	}
+	if diff := cmp.Diff(admission.Warnings(nil), warnings); diff != "" {
+		t.Errorf("ValidateUpdate() warnings mismatch (-want, +got):\n%s", diff)
+	}
+}
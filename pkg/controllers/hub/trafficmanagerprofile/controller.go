@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
@@ -24,18 +25,23 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"go.goms.io/fleet/pkg/utils/controller"
 
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/azureclient"
 	"go.goms.io/fleet-networking/pkg/common/azureerrors"
 	"go.goms.io/fleet-networking/pkg/common/defaulter"
 	"go.goms.io/fleet-networking/pkg/common/metrics"
@@ -57,16 +63,33 @@ const (
 	// AzureResourceProfileNameFormat is the name format of the Azure Traffic Manager Profile created by the fleet controller.
 	AzureResourceProfileNameFormat = "fleet-%s"
 
+	// defaultAzureResourceBackendEndpointNamePrefix is the default prefix of the Azure Traffic Manager endpoint names
+	// created by the trafficManagerBackend controller, which are fleet-{TrafficManagerBackendUUID}#{ServiceImportName}#{ClusterName}.
+	defaultAzureResourceBackendEndpointNamePrefix = "fleet-"
+
 	// DefaultDNSTTL is in seconds. This informs the local DNS resolvers and DNS clients how long to cache DNS responses
 	// provided by this Traffic Manager profile.
 	// Defaults to 60 which is the same as the portal's default config.
 	DefaultDNSTTL = int64(60)
 
+	// minRecommendedDNSTTL is the DNS TTL in seconds below which a warning event is emitted, as the low TTL increases the
+	// number of DNS queries and the cost of the Azure Traffic Manager.
+	minRecommendedDNSTTL = int64(30)
+
 	profileEventReasonAzureAPIError = "AzureAPIError"
 	profileEventReasonProgrammed    = "Programmed"
 	profileEventReasonDeleted       = "Deleted"
+	profileEventReasonRetained      = "Retained"
+	profileEventReasonLowDNSTTL     = "LowDNSTTL"
+	profileEventReasonInvalid       = "Invalid"
+
+	// minMonitorTimeoutInSeconds is the minimum monitor timeout allowed by the Azure Traffic Manager.
+	minMonitorTimeoutInSeconds = int64(5)
 )
 
+// errInvalidMonitorConfig is returned when the monitor config is rejected before calling the Azure Traffic Manager.
+var errInvalidMonitorConfig = errors.New("invalid monitor config")
+
 var (
 	// create the func as a variable so that the integration test can use a customized function.
 	generateAzureTrafficManagerProfileNameFunc = func(profile *fleetnetv1beta1.TrafficManagerProfile) string {
@@ -92,13 +115,26 @@ func GenerateAzureTrafficManagerProfileName(profile *fleetnetv1beta1.TrafficMana
 type Reconciler struct {
 	client.Client
 
-	ProfilesClient *armtrafficmanager.ProfilesClient
-	Recorder       record.EventRecorder
+	// AzureClientFactory provides the Azure Traffic Manager clients of the subscription of each profile.
+	// The endpoints clients are used to register the profile as a nested endpoint of its parent profile.
+	AzureClientFactory *azureclient.TrafficManagerClientFactory
+	Recorder           record.EventRecorder
+
+	// RateLimiter delays the requests whose reconciliation returns an error or ctrl.Result{Requeue: true}, so that the
+	// retries back off when the Azure Resource Manager is failing.
+	// The default rate limiter of the controller-runtime is used when it's nil.
+	RateLimiter workqueue.TypedRateLimiter[reconcile.Request]
+
+	// BackendEndpointNamePrefix is the prefix of the Azure Traffic Manager endpoint names created by the
+	// trafficManagerBackend controller, which are counted in the endpoint summary of the profile.
+	// "fleet-" is used when it's empty.
+	BackendEndpointNamePrefix string
 }
 
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=trafficmanagerprofiles,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=trafficmanagerprofiles/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=trafficmanagerprofiles/finalizers,verbs=get;update
+//+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=trafficmanagerbackends,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile triggers a single reconcile round.
@@ -139,13 +175,24 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	defer emitTrafficManagerProfileStatusMetric(profile)
 
-	// TODO: replace the following with defaulter wehbook
+	// The defaulter webhook is optional, so that the profiles admitted without it are still defaulted here.
 	defaulter.SetDefaultsTrafficManagerProfile(profile)
 	return r.handleUpdate(ctx, profile)
 }
 
 func (r *Reconciler) handleDelete(ctx context.Context, profile *fleetnetv1beta1.TrafficManagerProfile) (ctrl.Result, error) {
 	profileKObj := klog.KObj(profile)
+	if profile.Spec.RetentionPolicy != nil && controllerutil.ContainsFinalizer(profile, objectmeta.TrafficManagerProfileFinalizer) {
+		retainedFor, err := r.retainAzureTrafficManagerProfile(ctx, profile)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if retainedFor > 0 {
+			// Requeue the request to delete the Azure Traffic Manager profile once the retention period elapses.
+			return ctrl.Result{RequeueAfter: retainedFor}, nil
+		}
+	}
+
 	needUpdate := false
 	// The profile is being deleted
 	if controllerutil.ContainsFinalizer(profile, objectmeta.MetricsFinalizer) {
@@ -158,9 +205,19 @@ func (r *Reconciler) handleDelete(ctx context.Context, profile *fleetnetv1beta1.
 	}
 
 	if controllerutil.ContainsFinalizer(profile, objectmeta.TrafficManagerProfileFinalizer) {
+		// Remove the nested endpoint from the parent profile first so that the parent profile won't route the traffic
+		// to the deleted profile.
+		if err := r.deleteNestedEndpoint(ctx, profile); err != nil {
+			return ctrl.Result{}, err
+		}
+		clients, err := r.azureClients(profile.Spec.SubscriptionID)
+		if err != nil {
+			klog.ErrorS(err, "Failed to get Azure Traffic Manager clients", "trafficManagerProfile", profileKObj, "subscriptionID", profile.Spec.SubscriptionID)
+			return ctrl.Result{}, err
+		}
 		atmProfileName := generateAzureTrafficManagerProfileNameFunc(profile)
 		klog.V(2).InfoS("Deleting Azure Traffic Manager profile", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
-		if _, err := r.ProfilesClient.Delete(ctx, profile.Spec.ResourceGroup, atmProfileName, nil); err != nil {
+		if _, err := clients.ProfilesClient.Delete(ctx, profile.Spec.ResourceGroup, atmProfileName, nil); err != nil {
 			if !azureerrors.IsNotFound(err) {
 				r.Recorder.Eventf(profile, corev1.EventTypeWarning, profileEventReasonAzureAPIError, "Failed to delete Azure Traffic Manager profile %s: %v", atmProfileName, err)
 				klog.ErrorS(err, "Failed to delete Azure Traffic Manager profile", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
@@ -186,11 +243,91 @@ func (r *Reconciler) handleDelete(ctx context.Context, profile *fleetnetv1beta1.
 	return ctrl.Result{}, nil
 }
 
+// retainAzureTrafficManagerProfile disables the Azure Traffic Manager profile of the deleting profile and keeps it until
+// the retention period elapses.
+// It returns the remaining retention period, which is zero when the Azure Traffic Manager profile can be deleted.
+func (r *Reconciler) retainAzureTrafficManagerProfile(ctx context.Context, profile *fleetnetv1beta1.TrafficManagerProfile) (time.Duration, error) {
+	profileKObj := klog.KObj(profile)
+	scheduledDeletionTime := metav1.NewTime(profile.DeletionTimestamp.Add(time.Duration(profile.Spec.RetentionPolicy.RetentionHours) * time.Hour))
+	retainedFor := time.Until(scheduledDeletionTime.Time)
+	if retainedFor <= 0 {
+		klog.V(2).InfoS("Retention period of the Azure Traffic Manager profile has elapsed", "trafficManagerProfile", profileKObj, "scheduledDeletionTime", scheduledDeletionTime)
+		return 0, nil
+	}
+
+	// Remove the nested endpoint from the parent profile first so that the parent profile won't route the traffic
+	// to the retained profile.
+	if err := r.deleteNestedEndpoint(ctx, profile); err != nil {
+		return 0, err
+	}
+	clients, err := r.azureClients(profile.Spec.SubscriptionID)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get Azure Traffic Manager clients", "trafficManagerProfile", profileKObj, "subscriptionID", profile.Spec.SubscriptionID)
+		return 0, err
+	}
+	atmProfileName := generateAzureTrafficManagerProfileNameFunc(profile)
+	getRes, getErr := clients.ProfilesClient.Get(ctx, profile.Spec.ResourceGroup, atmProfileName, nil)
+	if getErr != nil {
+		if azureerrors.IsNotFound(getErr) {
+			klog.V(2).InfoS("Azure Traffic Manager profile does not exist and there is nothing to retain", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
+			return 0, nil
+		}
+		r.Recorder.Eventf(profile, corev1.EventTypeWarning, profileEventReasonAzureAPIError, "Failed to get Azure Traffic Manager profile %s: %v", atmProfileName, getErr)
+		klog.ErrorS(getErr, "Failed to get the profile", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
+		return 0, getErr
+	}
+	if getRes.Properties == nil || ptr.Deref(getRes.Properties.ProfileStatus, "") != armtrafficmanager.ProfileStatusDisabled {
+		defaulter.SetDefaultsTrafficManagerProfile(profile)
+		desiredATMProfile := generateAzureTrafficManagerProfile(profile)
+		desiredATMProfile.Properties.ProfileStatus = ptr.To(armtrafficmanager.ProfileStatusDisabled)
+		desiredATMProfile = buildAzureTrafficManagerProfileRequest(getRes.Profile, desiredATMProfile)
+		if _, err := clients.ProfilesClient.CreateOrUpdate(ctx, profile.Spec.ResourceGroup, atmProfileName, desiredATMProfile, nil); err != nil {
+			r.Recorder.Eventf(profile, corev1.EventTypeWarning, profileEventReasonAzureAPIError, "Failed to disable Azure Traffic Manager profile %s: %v", atmProfileName, err)
+			klog.ErrorS(err, "Failed to disable Azure Traffic Manager profile", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName)
+			return 0, err
+		}
+		r.Recorder.Eventf(profile, corev1.EventTypeNormal, profileEventReasonRetained, "Disabled Azure Traffic Manager profile %s and retained it until %s", atmProfileName, scheduledDeletionTime.UTC().Format(time.RFC3339))
+		klog.V(2).InfoS("Disabled and retained Azure Traffic Manager profile", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName, "scheduledDeletionTime", scheduledDeletionTime)
+	}
+
+	if profile.Status.ScheduledDeletionTime == nil || !profile.Status.ScheduledDeletionTime.Equal(&scheduledDeletionTime) {
+		profile.Status.ScheduledDeletionTime = &scheduledDeletionTime
+		if err := r.Client.Status().Update(ctx, profile); err != nil {
+			klog.ErrorS(err, "Failed to update trafficManagerProfile status", "trafficManagerProfile", profileKObj)
+			return 0, controller.NewUpdateIgnoreConflictError(err)
+		}
+	}
+	return retainedFor, nil
+}
+
+// azureClients returns the Azure Traffic Manager clients of the subscription.
+// The clients of the default subscription are returned when the subscription ID is empty.
+func (r *Reconciler) azureClients(subscriptionID string) (*azureclient.TrafficManagerClients, error) {
+	clients, err := r.AzureClientFactory.Clients(subscriptionID)
+	if err != nil {
+		// The clients can always be created with a valid subscription ID.
+		return nil, controller.NewUnexpectedBehaviorError(err)
+	}
+	return clients, nil
+}
+
 func (r *Reconciler) handleUpdate(ctx context.Context, profile *fleetnetv1beta1.TrafficManagerProfile) (ctrl.Result, error) {
 	profileKObj := klog.KObj(profile)
+	// The CRD validation rules may be bypassed, for example, by the profiles created before the rules are added, and
+	// the Azure Traffic Manager rejects the invalid monitor config with a less clear error.
+	if err := validateMonitorConfig(profile.Spec.MonitorConfig); err != nil {
+		klog.V(2).InfoS("Invalid monitor config of trafficManagerProfile", "trafficManagerProfile", profileKObj, "err", err)
+		r.Recorder.Eventf(profile, corev1.EventTypeWarning, profileEventReasonInvalid, "Invalid monitor config: %v", errors.Unwrap(err))
+		return r.updateProfileStatus(ctx, profile, nil, err)
+	}
 	atmProfileName := generateAzureTrafficManagerProfileNameFunc(profile)
 	desiredATMProfile := generateAzureTrafficManagerProfile(profile)
-	getRes, getErr := r.ProfilesClient.Get(ctx, profile.Spec.ResourceGroup, atmProfileName, nil)
+	clients, err := r.azureClients(profile.Spec.SubscriptionID)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get Azure Traffic Manager clients", "trafficManagerProfile", profileKObj, "subscriptionID", profile.Spec.SubscriptionID)
+		return ctrl.Result{}, err
+	}
+	getRes, getErr := clients.ProfilesClient.Get(ctx, profile.Spec.ResourceGroup, atmProfileName, nil)
 	if getErr != nil {
 		if !azureerrors.IsNotFound(getErr) {
 			r.Recorder.Eventf(profile, corev1.EventTypeWarning, profileEventReasonAzureAPIError, "Failed to get Azure Traffic Manager profile %s: %v", atmProfileName, getErr)
@@ -224,7 +361,12 @@ func (r *Reconciler) handleUpdate(ctx context.Context, profile *fleetnetv1beta1.
 		}
 	}
 
-	res, updateErr := r.ProfilesClient.CreateOrUpdate(ctx, profile.Spec.ResourceGroup, atmProfileName, desiredATMProfile, nil)
+	if ttl := *desiredATMProfile.Properties.DNSConfig.TTL; ttl < minRecommendedDNSTTL {
+		klog.V(2).InfoS("DNS TTL is lower than the recommended value", "trafficManagerProfile", profileKObj, "atmProfileName", atmProfileName, "ttlInSeconds", ttl)
+		r.Recorder.Eventf(profile, corev1.EventTypeWarning, profileEventReasonLowDNSTTL, "DNS TTL %d seconds is lower than the recommended %d seconds, which increases the number of DNS queries", ttl, minRecommendedDNSTTL)
+	}
+
+	res, updateErr := clients.ProfilesClient.CreateOrUpdate(ctx, profile.Spec.ResourceGroup, atmProfileName, desiredATMProfile, nil)
 	if updateErr != nil {
 		r.Recorder.Eventf(profile, corev1.EventTypeWarning, profileEventReasonAzureAPIError, "Failed to create or update Azure Traffic Manager profile %s: %v", atmProfileName, updateErr)
 		var responseError *azcore.ResponseError
@@ -264,6 +406,11 @@ func equalAzureTrafficManagerProfile(current, desired armtrafficmanager.Profile)
 		return false
 	}
 
+	// The maxReturn is only desired when using the "MultiValue" routing method and is ignored otherwise.
+	if desired.Properties.MaxReturn != nil && ptr.Deref(current.Properties.MaxReturn, 0) != *desired.Properties.MaxReturn {
+		return false
+	}
+
 	if current.Properties.DNSConfig.TTL == nil || *current.Properties.DNSConfig.TTL != *desired.Properties.DNSConfig.TTL {
 		return false
 	}
@@ -272,6 +419,9 @@ func equalAzureTrafficManagerProfile(current, desired armtrafficmanager.Profile)
 	if !desiredTagsExistInCurrentTags(current.Tags, desired.Tags) {
 		return false
 	}
+	if len(staleManagedTags(current.Tags, desired.Tags)) > 0 {
+		return false
+	}
 
 	return true
 }
@@ -303,8 +453,9 @@ func equalMonitorConfig(current, desired *armtrafficmanager.MonitorConfig) bool
 		return false
 	}
 
-	// Also check custom headers
-	return equalMonitorConfigWithCustomHeaders(current.CustomHeaders, desired.CustomHeaders)
+	// Also check custom headers and expected status code ranges
+	return equalMonitorConfigWithCustomHeaders(current.CustomHeaders, desired.CustomHeaders) &&
+		equalMonitorConfigWithExpectedStatusCodeRanges(current.ExpectedStatusCodeRanges, desired.ExpectedStatusCodeRanges)
 }
 
 func equalMonitorConfigWithCustomHeaders(current, desired []*armtrafficmanager.MonitorConfigCustomHeadersItem) bool {
@@ -319,6 +470,21 @@ func equalMonitorConfigWithCustomHeaders(current, desired []*armtrafficmanager.M
 	return equality.Semantic.DeepEqual(current, desired)
 }
 
+func equalMonitorConfigWithExpectedStatusCodeRanges(current, desired []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem) bool {
+	less := func(items []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem) func(i, j int) bool {
+		return func(i, j int) bool {
+			if ptr.Deref(items[i].Min, 0) != ptr.Deref(items[j].Min, 0) {
+				return ptr.Deref(items[i].Min, 0) < ptr.Deref(items[j].Min, 0)
+			}
+			return ptr.Deref(items[i].Max, 0) < ptr.Deref(items[j].Max, 0)
+		}
+	}
+	// Sort the slices to ensure the order does not affect the comparison.
+	sort.Slice(current, less(current))
+	sort.Slice(desired, less(desired))
+	return equality.Semantic.DeepEqual(current, desired)
+}
+
 // desiredTagsExistInCurrentTags checks if all desired tags exist in current tags with the same value.
 func desiredTagsExistInCurrentTags(currentTags, desiredTags map[string]*string) bool {
 	if currentTags == nil {
@@ -336,6 +502,28 @@ func desiredTagsExistInCurrentTags(currentTags, desiredTags map[string]*string)
 	return true
 }
 
+// staleManagedTags returns the names of the current tags which were configured by the trafficManagerProfile but are
+// not desired anymore, including the tag which records the configured tag names.
+func staleManagedTags(currentTags, desiredTags map[string]*string) []string {
+	managedTags := currentTags[objectmeta.AzureTrafficManagerProfileManagedTagsKey]
+	if managedTags == nil {
+		return nil
+	}
+	var stale []string
+	if _, ok := desiredTags[objectmeta.AzureTrafficManagerProfileManagedTagsKey]; !ok {
+		stale = append(stale, objectmeta.AzureTrafficManagerProfileManagedTagsKey)
+	}
+	for _, key := range strings.Split(*managedTags, ",") {
+		if _, ok := desiredTags[key]; ok {
+			continue
+		}
+		if _, ok := currentTags[key]; ok {
+			stale = append(stale, key)
+		}
+	}
+	return stale
+}
+
 func (r *Reconciler) updateProfileStatus(ctx context.Context, profile *fleetnetv1beta1.TrafficManagerProfile, atmProfile *armtrafficmanager.Profile, armErr error) (ctrl.Result, error) {
 	profileKObj := klog.KObj(profile)
 	if armErr == nil {
@@ -354,9 +542,18 @@ func (r *Reconciler) updateProfileStatus(ctx context.Context, profile *fleetnetv
 			err := controller.NewUnexpectedBehaviorError(fmt.Errorf("got nil ID for Azure Traffic Manager profile"))
 			klog.ErrorS(err, "Unexpected value returned by the Azure Traffic Manager", "trafficManagerProfile", profileKObj, "resourceGroup", profile.Spec.ResourceGroup, "atmProfileName", atmProfile.Name)
 		}
+		profile.Status.MonitorStatus = profileMonitorStatus(atmProfile)
+		profile.Status.EndpointSummary = summarizeBackendEndpoints(r.backendEndpointNamePrefix(), atmProfile)
 	} else {
-		profile.Status.DNSName = nil   // reset the DNS name
-		profile.Status.ResourceID = "" // reset the resource ID
+		profile.Status.DNSName = nil         // reset the DNS name
+		profile.Status.ResourceID = ""       // reset the resource ID
+		profile.Status.MonitorStatus = ""    // reset the monitor status
+		profile.Status.EndpointSummary = nil // reset the endpoint summary
+	}
+	// Register the programmed profile as a nested endpoint of its parent profile, if any.
+	var nestedErr error
+	if armErr == nil && profile.Status.ResourceID != "" {
+		nestedErr = r.reconcileNestedEndpoint(ctx, profile, atmProfile)
 	}
 	cond := metav1.Condition{
 		Type:               string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
@@ -365,7 +562,10 @@ func (r *Reconciler) updateProfileStatus(ctx context.Context, profile *fleetnetv
 		Reason:             string(fleetnetv1beta1.TrafficManagerProfileReasonProgrammed),
 		Message:            "Successfully configured the Azure Traffic Manager profile",
 	}
-	if azureerrors.IsConflict(armErr) {
+	if armErr == nil && profile.Status.MonitorStatus == fleetnetv1beta1.TrafficManagerProfileMonitorStatusDegraded {
+		cond.Reason = string(fleetnetv1beta1.TrafficManagerProfileReasonDegraded)
+		cond.Message = "Successfully configured the Azure Traffic Manager profile, while the profile monitor status is degraded as one or more endpoints are unhealthy"
+	} else if azureerrors.IsConflict(armErr) {
 		cond = metav1.Condition{
 			Type:               string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
 			Status:             metav1.ConditionFalse,
@@ -373,7 +573,7 @@ func (r *Reconciler) updateProfileStatus(ctx context.Context, profile *fleetnetv
 			Reason:             string(fleetnetv1beta1.TrafficManagerProfileReasonDNSNameNotAvailable),
 			Message:            "Domain name is not available. Please choose a different profile name or namespace",
 		}
-	} else if azureerrors.IsClientError(armErr) && !azureerrors.IsThrottled(armErr) {
+	} else if errors.Is(armErr, errInvalidMonitorConfig) || (azureerrors.IsClientError(armErr) && !azureerrors.IsThrottled(armErr)) {
 		cond = metav1.Condition{
 			Type:               string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
 			Status:             metav1.ConditionFalse,
@@ -396,12 +596,100 @@ func (r *Reconciler) updateProfileStatus(ctx context.Context, profile *fleetnetv
 		return ctrl.Result{}, controller.NewUpdateIgnoreConflictError(err)
 	}
 	klog.V(2).InfoS("Updated the trafficProfile status", "trafficManagerProfile", profileKObj, "status", profile.Status)
-	return ctrl.Result{}, armErr // return the error to retry the reconciliation
+	if errors.Is(armErr, errInvalidMonitorConfig) {
+		// There is no need to retry until the profile spec is changed.
+		return ctrl.Result{}, nil
+	}
+	if armErr != nil {
+		return ctrl.Result{}, armErr // return the error to retry the reconciliation
+	}
+	return ctrl.Result{}, nestedErr
+}
+
+// validateMonitorConfig validates the monitor timeout against the monitor interval of the defaulted monitor config, as
+// the timeout must be less than the interval.
+// * If the IntervalInSeconds is set to 30 seconds, then the TimeoutInSeconds must be between 5 and 10 seconds.
+// * If the IntervalInSeconds is set to 10 seconds, then the TimeoutInSeconds must be between 5 and 9 seconds.
+// Reference link: https://learn.microsoft.com/en-us/azure/traffic-manager/traffic-manager-monitoring#configure-endpoint-monitoring
+func validateMonitorConfig(mc *fleetnetv1beta1.MonitorConfig) error {
+	if mc == nil || mc.IntervalInSeconds == nil {
+		return nil
+	}
+	var maxTimeout int64
+	switch interval := *mc.IntervalInSeconds; interval {
+	case 30:
+		maxTimeout = 10
+	case 10:
+		maxTimeout = 9
+	default:
+		return fmt.Errorf("%w: intervalInSeconds must be 10 or 30, got %d", errInvalidMonitorConfig, interval)
+	}
+	if mc.TimeoutInSeconds == nil {
+		return nil
+	}
+	if timeout := *mc.TimeoutInSeconds; timeout < minMonitorTimeoutInSeconds || timeout > maxTimeout {
+		return fmt.Errorf("%w: timeoutInSeconds must be between %d and %d when intervalInSeconds is %d, got %d",
+			errInvalidMonitorConfig, minMonitorTimeoutInSeconds, maxTimeout, *mc.IntervalInSeconds, timeout)
+	}
+	return nil
+}
+
+// profileMonitorStatus returns the profile-level monitor status of the Azure Traffic Manager profile, if reported.
+func profileMonitorStatus(atmProfile *armtrafficmanager.Profile) fleetnetv1beta1.TrafficManagerProfileMonitorStatus {
+	if atmProfile.Properties == nil || atmProfile.Properties.MonitorConfig == nil || atmProfile.Properties.MonitorConfig.ProfileMonitorStatus == nil {
+		return ""
+	}
+	return fleetnetv1beta1.TrafficManagerProfileMonitorStatus(*atmProfile.Properties.MonitorConfig.ProfileMonitorStatus)
+}
+
+// backendEndpointNamePrefix returns the prefix of the Azure Traffic Manager endpoint names created by the
+// trafficManagerBackend controller.
+func (r *Reconciler) backendEndpointNamePrefix() string {
+	if r.BackendEndpointNamePrefix == "" {
+		return defaultAzureResourceBackendEndpointNamePrefix
+	}
+	return r.BackendEndpointNamePrefix
+}
+
+// summarizeBackendEndpoints counts the endpoints created by the trafficManagerBackend controller with the endpoint name
+// prefix in the Azure Traffic Manager profile and sums up their weights, while the endpoints created by others,
+// including the nested endpoints of the child profiles, are ignored.
+func summarizeBackendEndpoints(namePrefix string, atmProfile *armtrafficmanager.Profile) *fleetnetv1beta1.TrafficManagerProfileEndpointSummary {
+	if atmProfile.Properties == nil {
+		return nil
+	}
+	summary := &fleetnetv1beta1.TrafficManagerProfileEndpointSummary{}
+	for _, endpoint := range atmProfile.Properties.Endpoints {
+		if endpoint == nil || endpoint.Name == nil || !isBackendEndpointName(namePrefix, *endpoint.Name) {
+			continue
+		}
+		summary.Count++
+		if endpoint.Properties != nil {
+			summary.TotalWeight += ptr.Deref(endpoint.Properties.Weight, 0)
+		}
+	}
+	return summary
+}
+
+// isBackendEndpointName returns true if the endpoint name follows the naming convention of the trafficManagerBackend
+// controller with the endpoint name prefix. The names are compared case-insensitively as the Azure resource names are
+// case-insensitive.
+func isBackendEndpointName(namePrefix, name string) bool {
+	rest, found := strings.CutPrefix(strings.ToLower(name), namePrefix)
+	if !found {
+		return false
+	}
+	parts := strings.Split(rest, "#")
+	return len(parts) == 3 && parts[0] != "" && parts[1] != "" && parts[2] != ""
 }
 
 func generateAzureTrafficManagerProfile(profile *fleetnetv1beta1.TrafficManagerProfile) armtrafficmanager.Profile {
 	mc := profile.Spec.MonitorConfig
 	namespacedName := types.NamespacedName{Name: profile.Name, Namespace: profile.Namespace}
+	ttl := DefaultDNSTTL // no default value on the server side, using 60s same as portal's default config
+	if profile.Spec.DNSConfig != nil && profile.Spec.DNSConfig.TTLInSeconds != nil {
+		ttl = *profile.Spec.DNSConfig.TTLInSeconds
+	}
 
 	// Build the Azure Traffic Manager profile
 	tmProfile := armtrafficmanager.Profile{
@@ -409,7 +697,7 @@ func generateAzureTrafficManagerProfile(profile *fleetnetv1beta1.TrafficManagerP
 		Properties: &armtrafficmanager.ProfileProperties{
 			DNSConfig: &armtrafficmanager.DNSConfig{
 				RelativeName: ptr.To(fmt.Sprintf(DNSRelativeNameFormat, profile.Namespace, profile.Name)),
-				TTL:          ptr.To(DefaultDNSTTL), // no default value on the server side, using 60s same as portal's default config
+				TTL:          ptr.To(ttl),
 			},
 			MonitorConfig: &armtrafficmanager.MonitorConfig{
 				IntervalInSeconds:         mc.IntervalInSeconds,
@@ -419,15 +707,26 @@ func generateAzureTrafficManagerProfile(profile *fleetnetv1beta1.TrafficManagerP
 				TimeoutInSeconds:          mc.TimeoutInSeconds,
 				ToleratedNumberOfFailures: mc.ToleratedNumberOfFailures,
 			},
-			ProfileStatus: ptr.To(armtrafficmanager.ProfileStatusEnabled),
-			// By default, the routing method is set to Weighted.
-			TrafficRoutingMethod: ptr.To(armtrafficmanager.TrafficRoutingMethodWeighted),
+			ProfileStatus:        ptr.To(armtrafficmanager.ProfileStatusEnabled),
+			TrafficRoutingMethod: ptr.To(armtrafficmanager.TrafficRoutingMethod(profile.Spec.RoutingMethod)),
+			MaxReturn:            profile.Spec.MaxReturn,
 		},
 		Tags: map[string]*string{
 			objectmeta.AzureTrafficManagerProfileTagKey: ptr.To(namespacedName.String()),
 		},
 	}
 
+	// Add tags if specified and record their names so that the removed ones can be deleted later
+	if len(profile.Spec.Tags) > 0 {
+		names := make([]string, 0, len(profile.Spec.Tags))
+		for key, value := range profile.Spec.Tags {
+			tmProfile.Tags[key] = ptr.To(value)
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		tmProfile.Tags[objectmeta.AzureTrafficManagerProfileManagedTagsKey] = ptr.To(strings.Join(names, ","))
+	}
+
 	// Add custom headers if specified
 	if len(mc.CustomHeaders) > 0 {
 		customHeaders := make([]*armtrafficmanager.MonitorConfigCustomHeadersItem, 0, len(mc.CustomHeaders))
@@ -440,6 +739,18 @@ func generateAzureTrafficManagerProfile(profile *fleetnetv1beta1.TrafficManagerP
 		tmProfile.Properties.MonitorConfig.CustomHeaders = customHeaders
 	}
 
+	// Add expected status code ranges if specified
+	if len(mc.ExpectedStatusCodeRanges) > 0 {
+		ranges := make([]*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem, 0, len(mc.ExpectedStatusCodeRanges))
+		for _, r := range mc.ExpectedStatusCodeRanges {
+			ranges = append(ranges, &armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+				Min: ptr.To(r.Min),
+				Max: ptr.To(r.Max),
+			})
+		}
+		tmProfile.Properties.MonitorConfig.ExpectedStatusCodeRanges = ranges
+	}
+
 	return tmProfile
 }
 
@@ -461,13 +772,20 @@ func buildAzureTrafficManagerProfileRequest(current, desired armtrafficmanager.P
 			current.Properties.MonitorConfig.TimeoutInSeconds = desired.Properties.MonitorConfig.TimeoutInSeconds
 			current.Properties.MonitorConfig.ToleratedNumberOfFailures = desired.Properties.MonitorConfig.ToleratedNumberOfFailures
 			current.Properties.MonitorConfig.CustomHeaders = desired.Properties.MonitorConfig.CustomHeaders
+			current.Properties.MonitorConfig.ExpectedStatusCodeRanges = desired.Properties.MonitorConfig.ExpectedStatusCodeRanges
 		}
 		current.Properties.ProfileStatus = desired.Properties.ProfileStatus
 		current.Properties.TrafficRoutingMethod = desired.Properties.TrafficRoutingMethod
+		if desired.Properties.MaxReturn != nil {
+			current.Properties.MaxReturn = desired.Properties.MaxReturn
+		}
 	}
 	if current.Tags == nil {
 		current.Tags = desired.Tags
 	} else {
+		for _, key := range staleManagedTags(current.Tags, desired.Tags) {
+			delete(current.Tags, key)
+		}
 		for key, value := range desired.Tags {
 			current.Tags[key] = value
 		}
@@ -494,5 +812,12 @@ func emitTrafficManagerProfileStatusMetric(profile *fleetnetv1beta1.TrafficManag
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&fleetnetv1beta1.TrafficManagerProfile{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(ctrlcontroller.Options{RateLimiter: r.RateLimiter}).
+		// Watch the parent profiles so that the nested endpoints of the child profiles are reconciled when the parent
+		// profiles are programmed or deleted.
+		Watches(&fleetnetv1beta1.TrafficManagerProfile{}, handler.EnqueueRequestsFromMapFunc(r.handleParentProfileEvent), builder.WithPredicates(parentProfileEventPredicate())).
+		// Watch the backends so that the minChildEndpoints of the nested endpoints falls back to the number of the
+		// endpoints when the endpoints of the child profiles are added or removed.
+		Watches(&fleetnetv1beta1.TrafficManagerBackend{}, handler.EnqueueRequestsFromMapFunc(r.handleBackendEvent), builder.WithPredicates(backendEventPredicate())).
 		Complete(r)
 }
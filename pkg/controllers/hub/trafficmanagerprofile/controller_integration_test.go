@@ -56,6 +56,9 @@ func trafficManagerProfileForTest(name string) *fleetnetv1beta1.TrafficManagerPr
 				ToleratedNumberOfFailures: ptr.To[int64](5),
 			},
 			ResourceGroup: fakeprovider.DefaultResourceGroupName,
+			Tags: map[string]string{
+				"costCenter": "1234",
+			},
 		},
 	}
 }
@@ -151,8 +154,9 @@ var _ = Describe("Test TrafficManagerProfile Controller", func() {
 				},
 				Spec: profile.Spec,
 				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					DNSName:    ptr.To(fqdn),
-					ResourceID: profileResourceID,
+					EndpointSummary: &fleetnetv1beta1.TrafficManagerProfileEndpointSummary{},
+					DNSName:         ptr.To(fqdn),
+					ResourceID:      profileResourceID,
 					Conditions: []metav1.Condition{
 						{
 							Status:             metav1.ConditionTrue,
@@ -202,8 +206,9 @@ var _ = Describe("Test TrafficManagerProfile Controller", func() {
 				},
 				Spec: profile.Spec,
 				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
-					DNSName:    ptr.To(fqdn),
-					ResourceID: profileResourceID,
+					EndpointSummary: &fleetnetv1beta1.TrafficManagerProfileEndpointSummary{},
+					DNSName:         ptr.To(fqdn),
+					ResourceID:      profileResourceID,
 					Conditions: []metav1.Condition{
 						{
 							Status:             metav1.ConditionTrue,
@@ -243,6 +248,89 @@ var _ = Describe("Test TrafficManagerProfile Controller", func() {
 		})
 	})
 
+	Context("When creating valid trafficManagerProfile with the Geographic routing method", Ordered, func() {
+		name := fakeprovider.ValidProfileWithGeographicRoutingName
+		var profile *fleetnetv1beta1.TrafficManagerProfile
+		profileResourceID := fmt.Sprintf(fakeprovider.ProfileResourceIDFormat, fakeprovider.DefaultSubscriptionID, fakeprovider.DefaultResourceGroupName, name)
+
+		relativeDNSName := fmt.Sprintf(DNSRelativeNameFormat, testNamespace, name)
+		fqdn := fmt.Sprintf(fakeprovider.ProfileDNSNameFormat, relativeDNSName)
+		var wantMetrics []*prometheusclientmodel.Metric
+		var wantEvents []corev1.Event
+
+		BeforeAll(func() {
+			By("By Reset the metrics in registry")
+			resetTrafficManagerProfileMetricsRegistry()
+
+			By("By deleting all the events")
+			Expect(k8sClient.DeleteAllOf(ctx, &corev1.Event{}, client.InNamespace(testNamespace))).Should(Succeed(), "failed to delete the events")
+		})
+
+		It("AzureTrafficManager should be configured", func() {
+			By("By creating a new TrafficManagerProfile")
+			profile = trafficManagerProfileForTest(name)
+			profile.Spec.RoutingMethod = fleetnetv1beta1.TrafficManagerRoutingMethodGeographic
+			Expect(k8sClient.Create(ctx, profile)).Should(Succeed())
+
+			By("By checking profile")
+			// The fake Azure server rejects the request if the traffic routing method is not "Geographic".
+			want := fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       name,
+					Namespace:  testNamespace,
+					Finalizers: []string{objectmeta.TrafficManagerProfileFinalizer, objectmeta.MetricsFinalizer},
+				},
+				Spec: profile.Spec,
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					EndpointSummary: &fleetnetv1beta1.TrafficManagerProfileEndpointSummary{},
+					DNSName:         ptr.To(fqdn),
+					ResourceID:      profileResourceID,
+					Conditions: []metav1.Condition{
+						{
+							Status:             metav1.ConditionTrue,
+							Type:               string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed),
+							Reason:             string(fleetnetv1beta1.TrafficManagerProfileReasonProgrammed),
+							ObservedGeneration: profile.Generation,
+						},
+					},
+				},
+			}
+			validator.ValidateTrafficManagerProfile(ctx, k8sClient, &want, timeout)
+
+			By("By validating the status metrics")
+			wantMetrics = append(wantMetrics, generateMetrics(profile, want.Status.Conditions[0]))
+			validateTrafficManagerProfileMetricsEmitted(wantMetrics...)
+
+			By("By validating events")
+			event := corev1.Event{Type: corev1.EventTypeNormal, Reason: profileEventReasonProgrammed, ReportingController: ControllerName}
+			wantEvents = append(wantEvents, event)
+			validateEmittedEvents(profile, wantEvents)
+		})
+
+		It("Update the trafficManagerProfile routing method and should fail", func() {
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: name}, profile)).Should(Succeed(), "failed to get the trafficManagerProfile")
+			profile.Spec.RoutingMethod = fleetnetv1beta1.TrafficManagerRoutingMethodWeighted
+			Expect(k8sClient.Update(ctx, profile)).ShouldNot(Succeed(), "routingMethod should be immutable")
+		})
+
+		It("Deleting trafficManagerProfile", func() {
+			err := k8sClient.Delete(ctx, profile)
+			Expect(err).Should(Succeed(), "failed to delete trafficManagerProfile")
+		})
+
+		It("Validating trafficManagerProfile is deleted", func() {
+			validator.IsTrafficManagerProfileDeleted(ctx, k8sClient, types.NamespacedName{Namespace: testNamespace, Name: name}, timeout)
+
+			By("By validating the status metrics")
+			validateTrafficManagerProfileMetricsEmitted()
+
+			By("By validating event for deletion")
+			event := corev1.Event{Type: corev1.EventTypeNormal, Reason: profileEventReasonDeleted, ReportingController: ControllerName}
+			wantEvents = append(wantEvents, event)
+			validateEmittedEvents(profile, wantEvents)
+		})
+	})
+
 	Context("When updating existing valid trafficManagerProfile with no changes", Ordered, func() {
 		name := fakeprovider.ValidProfileName
 		var profile *fleetnetv1beta1.TrafficManagerProfile
@@ -288,6 +376,7 @@ var _ = Describe("Test TrafficManagerProfile Controller", func() {
 				},
 				Spec: profile.Spec,
 				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					EndpointSummary: &fleetnetv1beta1.TrafficManagerProfileEndpointSummary{},
 					// The DNS name is returned by the fake Azure GET call.
 					DNSName:    ptr.To(fmt.Sprintf(fakeprovider.ProfileDNSNameFormat, name)),
 					ResourceID: profileResourceID,
@@ -371,6 +460,7 @@ var _ = Describe("Test TrafficManagerProfile Controller", func() {
 				},
 				Spec: profile.Spec,
 				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					EndpointSummary: &fleetnetv1beta1.TrafficManagerProfileEndpointSummary{},
 					Conditions: []metav1.Condition{
 						{
 							Status:             metav1.ConditionTrue,
@@ -6,14 +6,32 @@ Licensed under the MIT license.
 package trafficmanagerprofile
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azcorefake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	armtrafficmanagerfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager/fake"
 	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/azureclient"
+	"go.goms.io/fleet-networking/pkg/common/objectmeta"
 )
 
 func TestGenerateAzureTrafficManagerProfileName(t *testing.T) {
@@ -29,6 +47,188 @@ func TestGenerateAzureTrafficManagerProfileName(t *testing.T) {
 	}
 }
 
+func TestGenerateAzureTrafficManagerProfile(t *testing.T) {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+				IntervalInSeconds: ptr.To[int64](30),
+				Path:              ptr.To("/path"),
+				Port:              ptr.To[int64](80),
+				Protocol:          ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolHTTP),
+				CustomHeaders: []fleetnetv1beta1.MonitorConfigCustomHeader{
+					{
+						Name:  "Host",
+						Value: "example.com",
+					},
+				},
+				ExpectedStatusCodeRanges: []fleetnetv1beta1.MonitorConfigExpectedStatusCodeRange{
+					{
+						Min: 200,
+						Max: 299,
+					},
+					{
+						Min: 301,
+						Max: 302,
+					},
+				},
+				TimeoutInSeconds:          ptr.To[int64](10),
+				ToleratedNumberOfFailures: ptr.To[int64](3),
+			},
+			RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+			Tags: map[string]string{
+				"team":       "networking",
+				"costCenter": "1234",
+			},
+		},
+	}
+	want := armtrafficmanager.Profile{
+		Location: ptr.To("global"),
+		Properties: &armtrafficmanager.ProfileProperties{
+			DNSConfig: &armtrafficmanager.DNSConfig{
+				RelativeName: ptr.To("namespace-name"),
+				TTL:          ptr.To(DefaultDNSTTL),
+			},
+			MonitorConfig: &armtrafficmanager.MonitorConfig{
+				CustomHeaders: []*armtrafficmanager.MonitorConfigCustomHeadersItem{
+					{
+						Name:  ptr.To("Host"),
+						Value: ptr.To("example.com"),
+					},
+				},
+				ExpectedStatusCodeRanges: []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+					{
+						Min: ptr.To[int32](200),
+						Max: ptr.To[int32](299),
+					},
+					{
+						Min: ptr.To[int32](301),
+						Max: ptr.To[int32](302),
+					},
+				},
+				IntervalInSeconds:         ptr.To[int64](30),
+				Path:                      ptr.To("/path"),
+				Port:                      ptr.To[int64](80),
+				Protocol:                  ptr.To(armtrafficmanager.MonitorProtocolHTTP),
+				TimeoutInSeconds:          ptr.To[int64](10),
+				ToleratedNumberOfFailures: ptr.To[int64](3),
+			},
+			ProfileStatus:        ptr.To(armtrafficmanager.ProfileStatusEnabled),
+			TrafficRoutingMethod: ptr.To(armtrafficmanager.TrafficRoutingMethodWeighted),
+		},
+		Tags: map[string]*string{
+			objectmeta.AzureTrafficManagerProfileTagKey:         ptr.To("namespace/name"),
+			objectmeta.AzureTrafficManagerProfileManagedTagsKey: ptr.To("costCenter,team"),
+			"costCenter": ptr.To("1234"),
+			"team":       ptr.To("networking"),
+		},
+	}
+	got := generateAzureTrafficManagerProfile(profile)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("generateAzureTrafficManagerProfile() mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestGenerateAzureTrafficManagerProfile_DNSTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		dnsConfig *fleetnetv1beta1.DNSConfig
+		want      int64
+	}{
+		{
+			name: "dns config is not specified",
+			want: DefaultDNSTTL,
+		},
+		{
+			name:      "ttl is not specified",
+			dnsConfig: &fleetnetv1beta1.DNSConfig{},
+			want:      DefaultDNSTTL,
+		},
+		{
+			name: "ttl is specified",
+			dnsConfig: &fleetnetv1beta1.DNSConfig{
+				TTLInSeconds: ptr.To[int64](300),
+			},
+			want: 300,
+		},
+		{
+			name: "ttl is zero",
+			dnsConfig: &fleetnetv1beta1.DNSConfig{
+				TTLInSeconds: ptr.To[int64](0),
+			},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "name",
+					Namespace: "namespace",
+				},
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+						Protocol: ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolHTTP),
+					},
+					DNSConfig:     tt.dnsConfig,
+					RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+				},
+			}
+			got := generateAzureTrafficManagerProfile(profile)
+			if got.Properties.DNSConfig.TTL == nil || *got.Properties.DNSConfig.TTL != tt.want {
+				t.Errorf("generateAzureTrafficManagerProfile() got TTL %v, want %d", ptr.Deref(got.Properties.DNSConfig.TTL, -1), tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateAzureTrafficManagerProfile_MaxReturn(t *testing.T) {
+	tests := []struct {
+		name          string
+		routingMethod fleetnetv1beta1.TrafficManagerRoutingMethod
+		maxReturn     *int64
+		want          *int64
+	}{
+		{
+			name:          "weighted routing method",
+			routingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+		{
+			name:          "multi-value routing method",
+			routingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodMultiValue,
+			maxReturn:     ptr.To[int64](3),
+			want:          ptr.To[int64](3),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "name",
+					Namespace: "namespace",
+				},
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+						Protocol: ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolHTTP),
+					},
+					RoutingMethod: tt.routingMethod,
+					MaxReturn:     tt.maxReturn,
+				},
+			}
+			got := generateAzureTrafficManagerProfile(profile)
+			if diff := cmp.Diff(tt.want, got.Properties.MaxReturn); diff != "" {
+				t.Errorf("generateAzureTrafficManagerProfile() maxReturn mismatch (-want, +got):\n%s", diff)
+			}
+			if got := ptr.Deref(got.Properties.TrafficRoutingMethod, ""); got != armtrafficmanager.TrafficRoutingMethod(tt.routingMethod) {
+				t.Errorf("generateAzureTrafficManagerProfile() got routing method %q, want %q", got, tt.routingMethod)
+			}
+		})
+	}
+}
+
 func buildDesiredProfile() armtrafficmanager.Profile {
 	return armtrafficmanager.Profile{
 		Location: ptr.To("global"),
@@ -203,6 +403,93 @@ func TestEqualAzureTrafficManagerProfile(t *testing.T) {
 				return res
 			},
 		},
+		{
+			name: "ExpectedStatusCodeRanges are equal with different order",
+			buildDesiredProfile: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.MonitorConfig.ExpectedStatusCodeRanges = []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+					{
+						Min: ptr.To[int32](200),
+						Max: ptr.To[int32](299),
+					},
+					{
+						Min: ptr.To[int32](301),
+						Max: ptr.To[int32](302),
+					},
+				}
+				return res
+			},
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.MonitorConfig.ExpectedStatusCodeRanges = []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+					{
+						Min: ptr.To[int32](301),
+						Max: ptr.To[int32](302),
+					},
+					{
+						Min: ptr.To[int32](200),
+						Max: ptr.To[int32](299),
+					},
+				}
+				return res
+			},
+			want: true,
+		},
+		{
+			name: "ExpectedStatusCodeRanges are equal (empty ranges)",
+			buildDesiredProfile: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.MonitorConfig.ExpectedStatusCodeRanges = []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{}
+				return res
+			},
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.MonitorConfig.ExpectedStatusCodeRanges = nil
+				return res
+			},
+			want: true,
+		},
+		{
+			name: "ExpectedStatusCodeRanges are different (different max)",
+			buildDesiredProfile: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.MonitorConfig.ExpectedStatusCodeRanges = []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+					{
+						Min: ptr.To[int32](200),
+						Max: ptr.To[int32](299),
+					},
+				}
+				return res
+			},
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.MonitorConfig.ExpectedStatusCodeRanges = []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+					{
+						Min: ptr.To[int32](200),
+						Max: ptr.To[int32](202),
+					},
+				}
+				return res
+			},
+		},
+		{
+			name: "ExpectedStatusCodeRanges is nil",
+			buildDesiredProfile: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.MonitorConfig.ExpectedStatusCodeRanges = []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+					{
+						Min: ptr.To[int32](200),
+						Max: ptr.To[int32](299),
+					},
+				}
+				return res
+			},
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.MonitorConfig.ExpectedStatusCodeRanges = nil
+				return res
+			},
+		},
 		{
 			name: "properties is nil",
 			buildCurrentFunc: func() armtrafficmanager.Profile {
@@ -355,6 +642,76 @@ func TestEqualAzureTrafficManagerProfile(t *testing.T) {
 				return res
 			},
 		},
+		{
+			name: "TrafficMethod is equal (Geographic)",
+			buildDesiredProfile: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.TrafficRoutingMethod = ptr.To(armtrafficmanager.TrafficRoutingMethodGeographic)
+				return res
+			},
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.TrafficRoutingMethod = ptr.To(armtrafficmanager.TrafficRoutingMethodGeographic)
+				return res
+			},
+			want: true,
+		},
+		{
+			name: "TrafficMethod is different (Weighted and Geographic)",
+			buildDesiredProfile: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.TrafficRoutingMethod = ptr.To(armtrafficmanager.TrafficRoutingMethodGeographic)
+				return res
+			},
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				return buildDesiredProfile()
+			},
+		},
+		{
+			name: "MaxReturn is equal (MultiValue)",
+			buildDesiredProfile: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.TrafficRoutingMethod = ptr.To(armtrafficmanager.TrafficRoutingMethodMultiValue)
+				res.Properties.MaxReturn = ptr.To(int64(3))
+				return res
+			},
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.TrafficRoutingMethod = ptr.To(armtrafficmanager.TrafficRoutingMethodMultiValue)
+				res.Properties.MaxReturn = ptr.To(int64(3))
+				return res
+			},
+			want: true,
+		},
+		{
+			name: "MaxReturn is different (MultiValue)",
+			buildDesiredProfile: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.TrafficRoutingMethod = ptr.To(armtrafficmanager.TrafficRoutingMethodMultiValue)
+				res.Properties.MaxReturn = ptr.To(int64(3))
+				return res
+			},
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.TrafficRoutingMethod = ptr.To(armtrafficmanager.TrafficRoutingMethodMultiValue)
+				res.Properties.MaxReturn = ptr.To(int64(2))
+				return res
+			},
+		},
+		{
+			name: "MaxReturn is nil (MultiValue)",
+			buildDesiredProfile: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.TrafficRoutingMethod = ptr.To(armtrafficmanager.TrafficRoutingMethodMultiValue)
+				res.Properties.MaxReturn = ptr.To(int64(3))
+				return res
+			},
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.TrafficRoutingMethod = ptr.To(armtrafficmanager.TrafficRoutingMethodMultiValue)
+				return res
+			},
+		},
 		{
 			name: "DNS TTL is nil",
 			buildCurrentFunc: func() armtrafficmanager.Profile {
@@ -397,6 +754,15 @@ func TestEqualAzureTrafficManagerProfile(t *testing.T) {
 				return res
 			},
 		},
+		{
+			name: "Managed tag is not desired anymore",
+			buildCurrentFunc: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Tags["oldKey"] = ptr.To("oldValue")
+				res.Tags[objectmeta.AzureTrafficManagerProfileManagedTagsKey] = ptr.To("oldKey,tagKey")
+				return res
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -423,9 +789,10 @@ func TestBuildAzureTrafficManagerProfileRequest(t *testing.T) {
 		},
 	}
 	tests := []struct {
-		name    string
-		current armtrafficmanager.Profile
-		want    armtrafficmanager.Profile
+		name                string
+		buildDesiredProfile func() armtrafficmanager.Profile
+		current             armtrafficmanager.Profile
+		want                armtrafficmanager.Profile
 	}{
 		{
 			name: "different location, nil properties and nil tags",
@@ -542,16 +909,6 @@ func TestBuildAzureTrafficManagerProfileRequest(t *testing.T) {
 								Value: ptr.To("HeaderValue"),
 							},
 						},
-						ExpectedStatusCodeRanges: []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
-							{
-								Min: ptr.To[int32](200),
-								Max: ptr.To[int32](299),
-							},
-							{
-								Min: ptr.To[int32](300),
-								Max: ptr.To[int32](399),
-							},
-						},
 						IntervalInSeconds:         ptr.To[int64](30),
 						Path:                      ptr.To("/path"),
 						Port:                      ptr.To[int64](80),
@@ -596,10 +953,143 @@ func TestBuildAzureTrafficManagerProfileRequest(t *testing.T) {
 			},
 			want: desired,
 		},
+		{
+			name: "different expected status code ranges",
+			buildDesiredProfile: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.MonitorConfig.ExpectedStatusCodeRanges = []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+					{
+						Min: ptr.To[int32](200),
+						Max: ptr.To[int32](299),
+					},
+				}
+				return res
+			},
+			current: armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					DNSConfig: &armtrafficmanager.DNSConfig{
+						RelativeName: ptr.To("namespace-name"),
+						TTL:          ptr.To(int64(60)),
+					},
+					MonitorConfig: &armtrafficmanager.MonitorConfig{
+						ExpectedStatusCodeRanges: []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+							{
+								Min: ptr.To[int32](300),
+								Max: ptr.To[int32](399),
+							},
+						},
+						IntervalInSeconds:         ptr.To[int64](30),
+						Path:                      ptr.To("/path"),
+						Port:                      ptr.To[int64](80),
+						Protocol:                  ptr.To(armtrafficmanager.MonitorProtocolHTTP),
+						TimeoutInSeconds:          ptr.To[int64](10),
+						ToleratedNumberOfFailures: ptr.To[int64](3),
+					},
+					ProfileStatus:        ptr.To(armtrafficmanager.ProfileStatusEnabled),
+					TrafficRoutingMethod: ptr.To(armtrafficmanager.TrafficRoutingMethodWeighted),
+				},
+			},
+			want: armtrafficmanager.Profile{
+				Location: ptr.To("global"),
+				Properties: &armtrafficmanager.ProfileProperties{
+					DNSConfig: &armtrafficmanager.DNSConfig{
+						RelativeName: ptr.To("namespace-name"),
+						TTL:          ptr.To(int64(60)),
+					},
+					MonitorConfig: &armtrafficmanager.MonitorConfig{
+						ExpectedStatusCodeRanges: []*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+							{
+								Min: ptr.To[int32](200),
+								Max: ptr.To[int32](299),
+							},
+						},
+						IntervalInSeconds:         ptr.To[int64](30),
+						Path:                      ptr.To("/path"),
+						Port:                      ptr.To[int64](80),
+						Protocol:                  ptr.To(armtrafficmanager.MonitorProtocolHTTP),
+						TimeoutInSeconds:          ptr.To[int64](10),
+						ToleratedNumberOfFailures: ptr.To[int64](3),
+					},
+					ProfileStatus:        ptr.To(armtrafficmanager.ProfileStatusEnabled),
+					TrafficRoutingMethod: ptr.To(armtrafficmanager.TrafficRoutingMethodWeighted),
+				},
+				Tags: map[string]*string{
+					"tagKey": ptr.To("tagValue"),
+				},
+			},
+		},
+		{
+			name: "geographic routing method with nil routing method and geo-mapped endpoints",
+			buildDesiredProfile: func() armtrafficmanager.Profile {
+				res := buildDesiredProfile()
+				res.Properties.TrafficRoutingMethod = ptr.To(armtrafficmanager.TrafficRoutingMethodGeographic)
+				return res
+			},
+			current: armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					DNSConfig: &armtrafficmanager.DNSConfig{
+						RelativeName: ptr.To("namespace-name"),
+						TTL:          ptr.To(int64(60)),
+					},
+					Endpoints: []*armtrafficmanager.Endpoint{
+						{
+							Name: ptr.To("endpoint-name"),
+							Properties: &armtrafficmanager.EndpointProperties{
+								GeoMapping: []*string{ptr.To("GEO-EU")},
+							},
+						},
+					},
+					MonitorConfig: &armtrafficmanager.MonitorConfig{
+						IntervalInSeconds:         ptr.To[int64](30),
+						Path:                      ptr.To("/path"),
+						Port:                      ptr.To[int64](80),
+						Protocol:                  ptr.To(armtrafficmanager.MonitorProtocolHTTP),
+						TimeoutInSeconds:          ptr.To[int64](10),
+						ToleratedNumberOfFailures: ptr.To[int64](3),
+					},
+					ProfileStatus:        ptr.To(armtrafficmanager.ProfileStatusEnabled),
+					TrafficRoutingMethod: nil,
+				},
+			},
+			want: armtrafficmanager.Profile{
+				Location: ptr.To("global"),
+				Properties: &armtrafficmanager.ProfileProperties{
+					DNSConfig: &armtrafficmanager.DNSConfig{
+						RelativeName: ptr.To("namespace-name"),
+						TTL:          ptr.To(int64(60)),
+					},
+					Endpoints: []*armtrafficmanager.Endpoint{
+						{
+							Name: ptr.To("endpoint-name"),
+							Properties: &armtrafficmanager.EndpointProperties{
+								GeoMapping: []*string{ptr.To("GEO-EU")},
+							},
+						},
+					},
+					MonitorConfig: &armtrafficmanager.MonitorConfig{
+						IntervalInSeconds:         ptr.To[int64](30),
+						Path:                      ptr.To("/path"),
+						Port:                      ptr.To[int64](80),
+						Protocol:                  ptr.To(armtrafficmanager.MonitorProtocolHTTP),
+						TimeoutInSeconds:          ptr.To[int64](10),
+						ToleratedNumberOfFailures: ptr.To[int64](3),
+					},
+					ProfileStatus:        ptr.To(armtrafficmanager.ProfileStatusEnabled),
+					TrafficRoutingMethod: ptr.To(armtrafficmanager.TrafficRoutingMethodGeographic),
+				},
+				Tags: map[string]*string{
+					"tagKey": ptr.To("tagValue"),
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			desired := desired
+			if tt.buildDesiredProfile != nil {
+				desired = tt.buildDesiredProfile()
+			}
 			got := buildAzureTrafficManagerProfileRequest(tt.current, desired)
 			if diff := cmp.Diff(tt.want, got); diff != "" {
 				t.Errorf("buildAzureTrafficManagerProfileRequest()  mismatch (-want, +got):\n%s", diff)
@@ -608,6 +1098,78 @@ func TestBuildAzureTrafficManagerProfileRequest(t *testing.T) {
 	}
 }
 
+func TestBuildAzureTrafficManagerProfileRequest_Tags(t *testing.T) {
+	tests := []struct {
+		name        string
+		currentTags map[string]*string
+		desiredTags map[string]*string
+		want        map[string]*string
+	}{
+		{
+			name: "removed tag is deleted while the tags added outside of the fleet are preserved",
+			currentTags: map[string]*string{
+				objectmeta.AzureTrafficManagerProfileManagedTagsKey: ptr.To("oldKey,tagKey"),
+				"oldKey":   ptr.To("oldValue"),
+				"tagKey":   ptr.To("tagValue"),
+				"otherKey": ptr.To("otherValue"),
+			},
+			desiredTags: map[string]*string{
+				objectmeta.AzureTrafficManagerProfileManagedTagsKey: ptr.To("tagKey"),
+				"tagKey": ptr.To("newValue"),
+			},
+			want: map[string]*string{
+				objectmeta.AzureTrafficManagerProfileManagedTagsKey: ptr.To("tagKey"),
+				"tagKey":   ptr.To("newValue"),
+				"otherKey": ptr.To("otherValue"),
+			},
+		},
+		{
+			name: "all the tags are removed",
+			currentTags: map[string]*string{
+				objectmeta.AzureTrafficManagerProfileTagKey:         ptr.To("namespace/name"),
+				objectmeta.AzureTrafficManagerProfileManagedTagsKey: ptr.To("tagKey"),
+				"tagKey":   ptr.To("tagValue"),
+				"otherKey": ptr.To("otherValue"),
+			},
+			desiredTags: map[string]*string{
+				objectmeta.AzureTrafficManagerProfileTagKey: ptr.To("namespace/name"),
+			},
+			want: map[string]*string{
+				objectmeta.AzureTrafficManagerProfileTagKey: ptr.To("namespace/name"),
+				"otherKey": ptr.To("otherValue"),
+			},
+		},
+		{
+			name: "no managed tags",
+			currentTags: map[string]*string{
+				"otherKey": ptr.To("otherValue"),
+			},
+			desiredTags: map[string]*string{
+				objectmeta.AzureTrafficManagerProfileTagKey: ptr.To("namespace/name"),
+			},
+			want: map[string]*string{
+				objectmeta.AzureTrafficManagerProfileTagKey: ptr.To("namespace/name"),
+				"otherKey": ptr.To("otherValue"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := buildDesiredProfile()
+			current.Tags = tt.currentTags
+			desired := buildDesiredProfile()
+			desired.Tags = tt.desiredTags
+			got := buildAzureTrafficManagerProfileRequest(current, desired)
+			if diff := cmp.Diff(tt.want, got.Tags); diff != "" {
+				t.Errorf("buildAzureTrafficManagerProfileRequest() tags mismatch (-want, +got):\n%s", diff)
+			}
+			if !equalAzureTrafficManagerProfile(got, desired) {
+				t.Errorf("equalAzureTrafficManagerProfile() = false, want true after building the request")
+			}
+		})
+	}
+}
+
 func TestHasRequiredProperties(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -714,3 +1276,372 @@ func TestHasRequiredProperties(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateProfileStatus_MonitorStatus(t *testing.T) {
+	profileID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/trafficManagerProfiles/profile"
+	tests := []struct {
+		name              string
+		monitorStatus     *armtrafficmanager.ProfileMonitorStatus
+		armErr            error
+		wantErr           bool
+		wantMonitorStatus fleetnetv1beta1.TrafficManagerProfileMonitorStatus
+		wantStatus        metav1.ConditionStatus
+		wantReason        fleetnetv1beta1.TrafficManagerProfileConditionReason
+	}{
+		{
+			name:              "online profile",
+			monitorStatus:     ptr.To(armtrafficmanager.ProfileMonitorStatusOnline),
+			wantMonitorStatus: fleetnetv1beta1.TrafficManagerProfileMonitorStatusOnline,
+			wantStatus:        metav1.ConditionTrue,
+			wantReason:        fleetnetv1beta1.TrafficManagerProfileReasonProgrammed,
+		},
+		{
+			name:              "degraded profile",
+			monitorStatus:     ptr.To(armtrafficmanager.ProfileMonitorStatusDegraded),
+			wantMonitorStatus: fleetnetv1beta1.TrafficManagerProfileMonitorStatusDegraded,
+			wantStatus:        metav1.ConditionTrue,
+			wantReason:        fleetnetv1beta1.TrafficManagerProfileReasonDegraded,
+		},
+		{
+			name:       "monitor status is not reported",
+			wantStatus: metav1.ConditionTrue,
+			wantReason: fleetnetv1beta1.TrafficManagerProfileReasonProgrammed,
+		},
+		{
+			name:       "failed to configure the profile",
+			armErr:     errors.New("internal error"),
+			wantErr:    true,
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: fleetnetv1beta1.TrafficManagerProfileReasonPending,
+		},
+		{
+			name:       "invalid monitor config",
+			armErr:     fmt.Errorf("%w: timeoutInSeconds must be between 5 and 9 when intervalInSeconds is 10, got 10", errInvalidMonitorConfig),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: fleetnetv1beta1.TrafficManagerProfileReasonInvalid,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "profile",
+					Namespace:  "test-ns",
+					Generation: 1,
+				},
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					MonitorStatus: fleetnetv1beta1.TrafficManagerProfileMonitorStatusOnline,
+				},
+			}
+			r := &Reconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile).WithStatusSubresource(profile).Build(),
+				Recorder: record.NewFakeRecorder(10),
+			}
+			atmProfile := &armtrafficmanager.Profile{
+				ID: ptr.To(profileID),
+				Properties: &armtrafficmanager.ProfileProperties{
+					DNSConfig:     &armtrafficmanager.DNSConfig{Fqdn: ptr.To("test-ns-profile.trafficmanager.net")},
+					MonitorConfig: &armtrafficmanager.MonitorConfig{ProfileMonitorStatus: tc.monitorStatus},
+				},
+			}
+			_, err := r.updateProfileStatus(context.Background(), profile, atmProfile, tc.armErr)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("updateProfileStatus() got error %v, want error %v", err, tc.wantErr)
+			}
+			if profile.Status.MonitorStatus != tc.wantMonitorStatus {
+				t.Errorf("updateProfileStatus() got monitorStatus %q, want %q", profile.Status.MonitorStatus, tc.wantMonitorStatus)
+			}
+			cond := meta.FindStatusCondition(profile.Status.Conditions, string(fleetnetv1beta1.TrafficManagerProfileConditionProgrammed))
+			if cond == nil {
+				t.Fatalf("updateProfileStatus() got nil programmed condition")
+			}
+			if cond.Status != tc.wantStatus || cond.Reason != string(tc.wantReason) {
+				t.Errorf("updateProfileStatus() got programmed condition %s/%s, want %s/%s", cond.Status, cond.Reason, tc.wantStatus, tc.wantReason)
+			}
+		})
+	}
+}
+
+func TestValidateMonitorConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		mc      *fleetnetv1beta1.MonitorConfig
+		wantErr bool
+	}{
+		{
+			name: "nil monitor config",
+		},
+		{
+			name: "normal probing with the max timeout",
+			mc:   &fleetnetv1beta1.MonitorConfig{IntervalInSeconds: ptr.To(int64(30)), TimeoutInSeconds: ptr.To(int64(10))},
+		},
+		{
+			name: "normal probing with the min timeout",
+			mc:   &fleetnetv1beta1.MonitorConfig{IntervalInSeconds: ptr.To(int64(30)), TimeoutInSeconds: ptr.To(int64(5))},
+		},
+		{
+			name:    "normal probing with too long timeout",
+			mc:      &fleetnetv1beta1.MonitorConfig{IntervalInSeconds: ptr.To(int64(30)), TimeoutInSeconds: ptr.To(int64(11))},
+			wantErr: true,
+		},
+		{
+			name:    "normal probing with too short timeout",
+			mc:      &fleetnetv1beta1.MonitorConfig{IntervalInSeconds: ptr.To(int64(30)), TimeoutInSeconds: ptr.To(int64(4))},
+			wantErr: true,
+		},
+		{
+			name: "fast probing with the max timeout",
+			mc:   &fleetnetv1beta1.MonitorConfig{IntervalInSeconds: ptr.To(int64(10)), TimeoutInSeconds: ptr.To(int64(9))},
+		},
+		{
+			name:    "fast probing with the timeout equal to the interval",
+			mc:      &fleetnetv1beta1.MonitorConfig{IntervalInSeconds: ptr.To(int64(10)), TimeoutInSeconds: ptr.To(int64(10))},
+			wantErr: true,
+		},
+		{
+			name: "fast probing without timeout",
+			mc:   &fleetnetv1beta1.MonitorConfig{IntervalInSeconds: ptr.To(int64(10))},
+		},
+		{
+			name:    "unsupported interval",
+			mc:      &fleetnetv1beta1.MonitorConfig{IntervalInSeconds: ptr.To(int64(20)), TimeoutInSeconds: ptr.To(int64(10))},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateMonitorConfig(tc.mc)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("validateMonitorConfig() got error %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil && !errors.Is(err, errInvalidMonitorConfig) {
+				t.Errorf("validateMonitorConfig() got error %v, want %v", err, errInvalidMonitorConfig)
+			}
+		})
+	}
+}
+
+func TestSummarizeBackendEndpoints(t *testing.T) {
+	tests := []struct {
+		name       string
+		atmProfile *armtrafficmanager.Profile
+		want       *fleetnetv1beta1.TrafficManagerProfileEndpointSummary
+	}{
+		{
+			name:       "nil properties",
+			atmProfile: &armtrafficmanager.Profile{},
+		},
+		{
+			name: "no endpoints",
+			atmProfile: &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{},
+			},
+			want: &fleetnetv1beta1.TrafficManagerProfileEndpointSummary{},
+		},
+		{
+			name: "only fleet-owned endpoints are counted",
+			atmProfile: &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					Endpoints: []*armtrafficmanager.Endpoint{
+						{
+							Name:       ptr.To("fleet-0b4b0ca4-f5c1-4e0e-9ae6-4e4b5d8e0d61#service#member-1"),
+							Properties: &armtrafficmanager.EndpointProperties{Weight: ptr.To[int64](30)},
+						},
+						{
+							Name:       ptr.To("FLEET-0B4B0CA4-F5C1-4E0E-9AE6-4E4B5D8E0D61#SERVICE#MEMBER-2"), // case-insensitive
+							Properties: &armtrafficmanager.EndpointProperties{Weight: ptr.To[int64](70)},
+						},
+						{
+							Name: ptr.To("fleet-0b4b0ca4-f5c1-4e0e-9ae6-4e4b5d8e0d61#service#member-3"), // no weight
+						},
+						{
+							Name:       ptr.To("fleet-child-profile"), // nested endpoint
+							Properties: &armtrafficmanager.EndpointProperties{Weight: ptr.To[int64](100)},
+						},
+						{
+							Name:       ptr.To("other-endpoint"),
+							Properties: &armtrafficmanager.EndpointProperties{Weight: ptr.To[int64](100)},
+						},
+						{
+							Name:       ptr.To("staging-0b4b0ca4-f5c1-4e0e-9ae6-4e4b5d8e0d61#service#member-1"), // created by another fleet
+							Properties: &armtrafficmanager.EndpointProperties{Weight: ptr.To[int64](100)},
+						},
+						{
+							Name:       ptr.To("fleet-##member-4"),
+							Properties: &armtrafficmanager.EndpointProperties{Weight: ptr.To[int64](100)},
+						},
+						{
+							Properties: &armtrafficmanager.EndpointProperties{Weight: ptr.To[int64](100)},
+						},
+						nil,
+					},
+				},
+			},
+			want: &fleetnetv1beta1.TrafficManagerProfileEndpointSummary{
+				Count:       3,
+				TotalWeight: 100,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := summarizeBackendEndpoints(defaultAzureResourceBackendEndpointNamePrefix, tc.atmProfile)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("summarizeBackendEndpoints() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// fakeProfilesServer stores the Azure Traffic Manager profiles keyed by the profile name.
+type fakeProfilesServer struct {
+	profiles map[string]armtrafficmanager.Profile
+	deleted  []string
+}
+
+func (f *fakeProfilesServer) server() armtrafficmanagerfake.ProfilesServer {
+	return armtrafficmanagerfake.ProfilesServer{
+		Get: func(_ context.Context, _ string, profileName string, _ *armtrafficmanager.ProfilesClientGetOptions) (resp azcorefake.Responder[armtrafficmanager.ProfilesClientGetResponse], errResp azcorefake.ErrorResponder) {
+			profile, ok := f.profiles[profileName]
+			if !ok {
+				errResp.SetResponseError(http.StatusNotFound, "NotFound")
+				return resp, errResp
+			}
+			resp.SetResponse(http.StatusOK, armtrafficmanager.ProfilesClientGetResponse{Profile: profile}, nil)
+			return resp, errResp
+		},
+		CreateOrUpdate: func(_ context.Context, _ string, profileName string, parameters armtrafficmanager.Profile, _ *armtrafficmanager.ProfilesClientCreateOrUpdateOptions) (resp azcorefake.Responder[armtrafficmanager.ProfilesClientCreateOrUpdateResponse], errResp azcorefake.ErrorResponder) {
+			f.profiles[profileName] = parameters
+			resp.SetResponse(http.StatusOK, armtrafficmanager.ProfilesClientCreateOrUpdateResponse{Profile: parameters}, nil)
+			return resp, errResp
+		},
+		Delete: func(_ context.Context, _ string, profileName string, _ *armtrafficmanager.ProfilesClientDeleteOptions) (resp azcorefake.Responder[armtrafficmanager.ProfilesClientDeleteResponse], errResp azcorefake.ErrorResponder) {
+			f.deleted = append(f.deleted, profileName)
+			if _, ok := f.profiles[profileName]; !ok {
+				errResp.SetResponseError(http.StatusNotFound, "NotFound")
+				return resp, errResp
+			}
+			delete(f.profiles, profileName)
+			resp.SetResponse(http.StatusOK, armtrafficmanager.ProfilesClientDeleteResponse{}, nil)
+			return resp, errResp
+		},
+	}
+}
+
+func TestHandleDelete_RetentionPolicy(t *testing.T) {
+	atmProfileName := "fleet-profile-uid"
+	enabledATMProfile := armtrafficmanager.Profile{
+		Location: ptr.To("global"),
+		Properties: &armtrafficmanager.ProfileProperties{
+			ProfileStatus:        ptr.To(armtrafficmanager.ProfileStatusEnabled),
+			TrafficRoutingMethod: ptr.To(armtrafficmanager.TrafficRoutingMethodWeighted),
+		},
+	}
+	tests := []struct {
+		name              string
+		deletedFor        time.Duration
+		atmProfiles       map[string]armtrafficmanager.Profile
+		wantRequeue       bool
+		wantProfileStatus *armtrafficmanager.ProfileStatus // nil when the Azure Traffic Manager profile is deleted
+		wantDeleted       []string
+	}{
+		{
+			name:              "within the retention period",
+			deletedFor:        time.Hour,
+			atmProfiles:       map[string]armtrafficmanager.Profile{atmProfileName: enabledATMProfile},
+			wantRequeue:       true,
+			wantProfileStatus: ptr.To(armtrafficmanager.ProfileStatusDisabled),
+		},
+		{
+			name:        "retention period has elapsed",
+			deletedFor:  3 * time.Hour,
+			atmProfiles: map[string]armtrafficmanager.Profile{atmProfileName: enabledATMProfile},
+			wantDeleted: []string{atmProfileName},
+		},
+		{
+			name:        "Azure Traffic Manager profile does not exist",
+			deletedFor:  time.Hour,
+			atmProfiles: map[string]armtrafficmanager.Profile{},
+			wantDeleted: []string{atmProfileName},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeProfiles := &fakeProfilesServer{profiles: map[string]armtrafficmanager.Profile{}}
+			for name, profile := range tc.atmProfiles {
+				fakeProfiles.profiles[name] = profile
+			}
+			fakeServer := fakeProfiles.server()
+			clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+				&arm.ClientOptions{
+					ClientOptions: azcore.ClientOptions{
+						Transport: armtrafficmanagerfake.NewProfilesServerTransport(&fakeServer),
+						Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+					},
+				})
+			if err != nil {
+				t.Fatalf("failed to create the client factory: %v", err)
+			}
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			deletionTimestamp := metav1.NewTime(time.Now().Add(-tc.deletedFor).Truncate(time.Second))
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "profile",
+					Namespace:         "test-ns",
+					UID:               "profile-uid",
+					DeletionTimestamp: &deletionTimestamp,
+					Finalizers:        []string{objectmeta.TrafficManagerProfileFinalizer},
+				},
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					ResourceGroup: "rg",
+					RetentionPolicy: &fleetnetv1beta1.TrafficManagerProfileRetentionPolicy{
+						RetentionHours: 2,
+					},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(profile).WithStatusSubresource(profile).Build()
+			r := &Reconciler{
+				Client:             fakeClient,
+				AzureClientFactory: azureclient.NewTrafficManagerClientFactoryForClients(clientFactory.NewProfilesClient(), nil),
+				Recorder:           record.NewFakeRecorder(10),
+			}
+
+			res, err := r.handleDelete(context.Background(), profile)
+			if err != nil {
+				t.Fatalf("handleDelete() got error %v, want nil", err)
+			}
+			if gotRequeue := res.RequeueAfter > 0; gotRequeue != tc.wantRequeue {
+				t.Errorf("handleDelete() got requeueAfter %v, want requeue %v", res.RequeueAfter, tc.wantRequeue)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, fakeProfiles.deleted); diff != "" {
+				t.Errorf("handleDelete() deleted Azure Traffic Manager profiles mismatch (-want +got):\n%s", diff)
+			}
+
+			got := &fleetnetv1beta1.TrafficManagerProfile{}
+			getErr := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(profile), got)
+			if tc.wantProfileStatus == nil {
+				if !apierrors.IsNotFound(getErr) {
+					t.Errorf("handleDelete() got trafficManagerProfile error %v, want not found", getErr)
+				}
+				return
+			}
+			if getErr != nil {
+				t.Fatalf("failed to get trafficManagerProfile: %v", getErr)
+			}
+			wantScheduledDeletionTime := metav1.NewTime(deletionTimestamp.Add(2 * time.Hour))
+			if got.Status.ScheduledDeletionTime == nil || !got.Status.ScheduledDeletionTime.Equal(&wantScheduledDeletionTime) {
+				t.Errorf("handleDelete() got scheduledDeletionTime %v, want %v", got.Status.ScheduledDeletionTime, wantScheduledDeletionTime)
+			}
+			if diff := cmp.Diff(tc.wantProfileStatus, fakeProfiles.profiles[atmProfileName].Properties.ProfileStatus); diff != "" {
+				t.Errorf("handleDelete() Azure Traffic Manager profile status mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
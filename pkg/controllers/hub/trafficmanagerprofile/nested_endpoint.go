@@ -0,0 +1,362 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package trafficmanagerprofile
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"go.goms.io/fleet/pkg/utils/controller"
+
+	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/azureerrors"
+	"go.goms.io/fleet-networking/pkg/common/defaulter"
+)
+
+const (
+	// AzureResourceNestedEndpointNameFormat is the name format of the Azure Traffic Manager nested endpoint registered
+	// in the parent profile by the fleet controller, which is fleet-nested-{TrafficManagerProfileUUID}.
+	// It never follows the naming convention of the endpoints created for the trafficManagerBackends, so that the nested
+	// endpoints are not treated as the orphaned ones.
+	AzureResourceNestedEndpointNameFormat = "fleet-nested-%s"
+
+	// azureTrafficManagerNestedEndpointType is the resource type of the Azure Traffic Manager nested endpoint.
+	azureTrafficManagerNestedEndpointType = "Microsoft.Network/trafficManagerProfiles/nestedEndpoints"
+
+	// defaultNestedEndpointWeight is the default weight of the nested endpoint in the parent profile using the
+	// "Weighted" routing method.
+	defaultNestedEndpointWeight = int64(1)
+	// defaultMinChildEndpoints is the default minimum number of the healthy endpoints in the child profile.
+	defaultMinChildEndpoints = int64(1)
+
+	profileEventReasonInvalidParentProfile     = "InvalidParentProfile"
+	profileEventReasonNestedEndpointProgrammed = "NestedEndpointProgrammed"
+	profileEventReasonNestedEndpointDeleted    = "NestedEndpointDeleted"
+)
+
+// GenerateAzureTrafficManagerNestedEndpointName generates the name of the Azure Traffic Manager nested endpoint which
+// is registered in the parent profile.
+func GenerateAzureTrafficManagerNestedEndpointName(profile *fleetnetv1beta1.TrafficManagerProfile) string {
+	return fmt.Sprintf(AzureResourceNestedEndpointNameFormat, profile.UID)
+}
+
+// reconcileNestedEndpoint registers the programmed profile as a nested endpoint of its parent profile, and removes the
+// nested endpoint registered before when the parent profile is changed, removed or deleted.
+// The atmProfile is the Azure Traffic Manager profile of the profile, whose endpoints are counted for the fallback of
+// the minChildEndpoints.
+// It updates the status.nestedEndpointResourceID in memory and the caller is responsible for updating the status.
+func (r *Reconciler) reconcileNestedEndpoint(ctx context.Context, profile *fleetnetv1beta1.TrafficManagerProfile, atmProfile *armtrafficmanager.Profile) error {
+	profileKObj := klog.KObj(profile)
+	if profile.Spec.ParentProfile == nil {
+		return r.deleteNestedEndpoint(ctx, profile)
+	}
+
+	parentName := types.NamespacedName{Namespace: profile.Namespace, Name: profile.Spec.ParentProfile.Name}
+	parentKRef := klog.KRef(parentName.Namespace, parentName.Name)
+	parent := &fleetnetv1beta1.TrafficManagerProfile{}
+	if err := r.Client.Get(ctx, parentName, parent); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get the parent trafficManagerProfile", "trafficManagerProfile", profileKObj, "parentTrafficManagerProfile", parentKRef)
+			return controller.NewAPIServerError(true, err)
+		}
+		klog.V(2).InfoS("Parent trafficManagerProfile does not exist", "trafficManagerProfile", profileKObj, "parentTrafficManagerProfile", parentKRef)
+		r.Recorder.Eventf(profile, corev1.EventTypeWarning, profileEventReasonInvalidParentProfile, "Parent trafficManagerProfile %q is not found", parentName.Name)
+		return r.deleteNestedEndpoint(ctx, profile)
+	}
+	if !parent.DeletionTimestamp.IsZero() {
+		klog.V(2).InfoS("Parent trafficManagerProfile is being deleted", "trafficManagerProfile", profileKObj, "parentTrafficManagerProfile", parentKRef)
+		return r.deleteNestedEndpoint(ctx, profile)
+	}
+	if parent.Status.ResourceID == "" {
+		// Keep the existing nested endpoint untouched, as the resource ID is reset when the parent profile hits a
+		// transient error. The profile will be requeued once the parent profile is programmed.
+		klog.V(2).InfoS("Parent trafficManagerProfile is not programmed yet", "trafficManagerProfile", profileKObj, "parentTrafficManagerProfile", parentKRef)
+		return nil
+	}
+	defaulter.SetDefaultsTrafficManagerProfile(parent)
+	if err := validateParentProfile(profile.Spec.ParentProfile, parent); err != nil {
+		klog.V(2).InfoS("Invalid parent trafficManagerProfile", "trafficManagerProfile", profileKObj, "parentTrafficManagerProfile", parentKRef, "err", err)
+		r.Recorder.Eventf(profile, corev1.EventTypeWarning, profileEventReasonInvalidParentProfile, "Invalid parent trafficManagerProfile %q: %v", parentName.Name, err)
+		return nil
+	}
+
+	endpointName := GenerateAzureTrafficManagerNestedEndpointName(profile)
+	desiredID := fmt.Sprintf("%s/nestedEndpoints/%s", parent.Status.ResourceID, endpointName)
+	if profile.Status.NestedEndpointResourceID != "" && !strings.EqualFold(profile.Status.NestedEndpointResourceID, desiredID) {
+		// The parent profile is changed and the nested endpoint in the previous parent profile should be removed.
+		if err := r.deleteNestedEndpoint(ctx, profile); err != nil {
+			return err
+		}
+	}
+
+	clients, err := r.azureClients(parent.Spec.SubscriptionID)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get Azure Traffic Manager clients", "trafficManagerProfile", profileKObj, "parentTrafficManagerProfile", parentKRef, "subscriptionID", parent.Spec.SubscriptionID)
+		return err
+	}
+	atmParentProfileName := generateAzureTrafficManagerProfileNameFunc(parent)
+	minChildEndpoints := desiredMinChildEndpoints(profile.Spec.ParentProfile, countEnabledEndpoints(atmProfile))
+	if minChildEndpoints != ptr.Deref(profile.Spec.ParentProfile.MinChildEndpoints, defaultMinChildEndpoints) {
+		klog.V(2).InfoS("Falling back the minChildEndpoints to the number of the enabled endpoints", "trafficManagerProfile", profileKObj, "parentTrafficManagerProfile", parentKRef, "minChildEndpoints", minChildEndpoints)
+	}
+	desired := generateAzureTrafficManagerNestedEndpoint(profile, parent, minChildEndpoints)
+	getRes, getErr := clients.EndpointsClient.Get(ctx, parent.Spec.ResourceGroup, atmParentProfileName, armtrafficmanager.EndpointTypeNestedEndpoints, endpointName, nil)
+	if getErr != nil {
+		if !azureerrors.IsNotFound(getErr) {
+			klog.ErrorS(getErr, "Failed to get the nested endpoint", "trafficManagerProfile", profileKObj, "parentTrafficManagerProfile", parentKRef, "atmProfileName", atmParentProfileName, "atmEndpoint", endpointName)
+			r.Recorder.Eventf(profile, corev1.EventTypeWarning, profileEventReasonAzureAPIError, "Failed to get Azure Traffic Manager nested endpoint %s: %v", endpointName, getErr)
+			return getErr
+		}
+	} else if equalAzureTrafficManagerNestedEndpoint(getRes.Endpoint, desired) {
+		klog.V(2).InfoS("No nested endpoint update needed", "trafficManagerProfile", profileKObj, "parentTrafficManagerProfile", parentKRef, "atmProfileName", atmParentProfileName, "atmEndpoint", endpointName)
+		profile.Status.NestedEndpointResourceID = ptr.Deref(getRes.ID, desiredID)
+		return nil
+	}
+
+	res, updateErr := clients.EndpointsClient.CreateOrUpdate(ctx, parent.Spec.ResourceGroup, atmParentProfileName, armtrafficmanager.EndpointTypeNestedEndpoints, endpointName, desired, nil)
+	if updateErr != nil {
+		klog.ErrorS(updateErr, "Failed to create or update the nested endpoint", "trafficManagerProfile", profileKObj, "parentTrafficManagerProfile", parentKRef, "atmProfileName", atmParentProfileName, "atmEndpoint", endpointName)
+		r.Recorder.Eventf(profile, corev1.EventTypeWarning, profileEventReasonAzureAPIError, "Failed to create or update Azure Traffic Manager nested endpoint %s: %v", endpointName, updateErr)
+		if azureerrors.IsClientError(updateErr) && !azureerrors.IsThrottled(updateErr) && !azureerrors.IsConflict(updateErr) {
+			// The request is rejected, for example, the nesting introduces a loop, which cannot be resolved by retrying
+			// until the user changes the parent profile.
+			return nil
+		}
+		return updateErr
+	}
+	r.Recorder.Eventf(profile, corev1.EventTypeNormal, profileEventReasonNestedEndpointProgrammed, "Created or updated Azure Traffic Manager nested endpoint %s in %s", endpointName, atmParentProfileName)
+	klog.V(2).InfoS("Created or updated the nested endpoint", "trafficManagerProfile", profileKObj, "parentTrafficManagerProfile", parentKRef, "atmProfileName", atmParentProfileName, "atmEndpoint", endpointName)
+	profile.Status.NestedEndpointResourceID = ptr.Deref(res.ID, desiredID)
+	return nil
+}
+
+// deleteNestedEndpoint deletes the nested endpoint recorded by the status.nestedEndpointResourceID from the parent
+// profile and resets the field in memory.
+func (r *Reconciler) deleteNestedEndpoint(ctx context.Context, profile *fleetnetv1beta1.TrafficManagerProfile) error {
+	if profile.Status.NestedEndpointResourceID == "" {
+		return nil
+	}
+	profileKObj := klog.KObj(profile)
+	resourceID, err := arm.ParseResourceID(profile.Status.NestedEndpointResourceID)
+	if err != nil || resourceID.Parent == nil {
+		// The resource ID is returned by the Azure Traffic Manager and should always be valid.
+		klog.ErrorS(controller.NewUnexpectedBehaviorError(fmt.Errorf("invalid nested endpoint resource ID: %w", err)), "Failed to parse the nested endpoint resource ID", "trafficManagerProfile", profileKObj, "nestedEndpointResourceID", profile.Status.NestedEndpointResourceID)
+		profile.Status.NestedEndpointResourceID = ""
+		return nil
+	}
+	clients, err := r.azureClients(resourceID.SubscriptionID)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get Azure Traffic Manager clients", "trafficManagerProfile", profileKObj, "subscriptionID", resourceID.SubscriptionID)
+		return err
+	}
+	if _, err := clients.EndpointsClient.Delete(ctx, resourceID.ResourceGroupName, resourceID.Parent.Name, armtrafficmanager.EndpointTypeNestedEndpoints, resourceID.Name, nil); err != nil {
+		if !azureerrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete the nested endpoint", "trafficManagerProfile", profileKObj, "atmProfileName", resourceID.Parent.Name, "atmEndpoint", resourceID.Name)
+			r.Recorder.Eventf(profile, corev1.EventTypeWarning, profileEventReasonAzureAPIError, "Failed to delete Azure Traffic Manager nested endpoint %s: %v", resourceID.Name, err)
+			return err
+		}
+	}
+	r.Recorder.Eventf(profile, corev1.EventTypeNormal, profileEventReasonNestedEndpointDeleted, "Deleted Azure Traffic Manager nested endpoint %s from %s", resourceID.Name, resourceID.Parent.Name)
+	klog.V(2).InfoS("Deleted the nested endpoint", "trafficManagerProfile", profileKObj, "atmProfileName", resourceID.Parent.Name, "atmEndpoint", resourceID.Name)
+	profile.Status.NestedEndpointResourceID = ""
+	return nil
+}
+
+// validateParentProfile validates the nested endpoint settings against the routing method of the parent profile.
+func validateParentProfile(parentProfile *fleetnetv1beta1.TrafficManagerParentProfile, parent *fleetnetv1beta1.TrafficManagerProfile) error {
+	switch parent.Spec.RoutingMethod {
+	case fleetnetv1beta1.TrafficManagerRoutingMethodWeighted:
+		return nil
+	case fleetnetv1beta1.TrafficManagerRoutingMethodPriority:
+		if parentProfile.Priority == nil {
+			return fmt.Errorf("priority is required when the parent profile uses the %q routing method", parent.Spec.RoutingMethod)
+		}
+		return nil
+	default:
+		return fmt.Errorf("the %q routing method of the parent profile is not supported", parent.Spec.RoutingMethod)
+	}
+}
+
+// desiredMinChildEndpoints returns the minChildEndpoints of the nested endpoint, which falls back to the number of the
+// enabled endpoints in the child profile when the fallback is configured and the child profile has fewer enabled
+// endpoints.
+// The result is never less than 1, which is the minimum allowed by the Azure Traffic Manager.
+func desiredMinChildEndpoints(parentProfile *fleetnetv1beta1.TrafficManagerParentProfile, enabledEndpoints int64) int64 {
+	minChildEndpoints := ptr.Deref(parentProfile.MinChildEndpoints, defaultMinChildEndpoints)
+	if parentProfile.MinChildEndpointsFallback != fleetnetv1beta1.TrafficManagerMinChildEndpointsFallbackEndpointCount {
+		return minChildEndpoints
+	}
+	return max(min(minChildEndpoints, enabledEndpoints), defaultMinChildEndpoints)
+}
+
+// countEnabledEndpoints counts the enabled endpoints in the Azure Traffic Manager profile, including the nested
+// endpoints of its child profiles, as all of them are counted by the parent profile for the minChildEndpoints.
+// The endpoint without the endpoint status is enabled, which is the default of the Azure Traffic Manager.
+func countEnabledEndpoints(atmProfile *armtrafficmanager.Profile) int64 {
+	if atmProfile == nil || atmProfile.Properties == nil {
+		return 0
+	}
+	var count int64
+	for _, endpoint := range atmProfile.Properties.Endpoints {
+		if endpoint == nil {
+			continue
+		}
+		if endpoint.Properties != nil && endpoint.Properties.EndpointStatus != nil && *endpoint.Properties.EndpointStatus != armtrafficmanager.EndpointStatusEnabled {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// generateAzureTrafficManagerNestedEndpoint builds the nested endpoint targeting the Azure Traffic Manager profile of
+// the child profile with the given minChildEndpoints.
+func generateAzureTrafficManagerNestedEndpoint(profile, parent *fleetnetv1beta1.TrafficManagerProfile, minChildEndpoints int64) armtrafficmanager.Endpoint {
+	endpoint := armtrafficmanager.Endpoint{
+		Name: ptr.To(GenerateAzureTrafficManagerNestedEndpointName(profile)),
+		Type: ptr.To(azureTrafficManagerNestedEndpointType),
+		Properties: &armtrafficmanager.EndpointProperties{
+			TargetResourceID:  ptr.To(profile.Status.ResourceID),
+			EndpointStatus:    ptr.To(armtrafficmanager.EndpointStatusEnabled),
+			MinChildEndpoints: ptr.To(minChildEndpoints),
+		},
+	}
+	switch parent.Spec.RoutingMethod {
+	case fleetnetv1beta1.TrafficManagerRoutingMethodWeighted:
+		endpoint.Properties.Weight = ptr.To(ptr.Deref(profile.Spec.ParentProfile.Weight, defaultNestedEndpointWeight))
+	case fleetnetv1beta1.TrafficManagerRoutingMethodPriority:
+		endpoint.Properties.Priority = profile.Spec.ParentProfile.Priority
+	}
+	return endpoint
+}
+
+// equalAzureTrafficManagerNestedEndpoint compares only few fields of the current and desired nested endpoints by
+// ignoring others.
+// The desired endpoint is built by the controllers and all the required fields should not be nil.
+func equalAzureTrafficManagerNestedEndpoint(current, desired armtrafficmanager.Endpoint) bool {
+	if current.Properties == nil || current.Properties.TargetResourceID == nil || current.Properties.EndpointStatus == nil {
+		return false
+	}
+	if !strings.EqualFold(*current.Properties.TargetResourceID, *desired.Properties.TargetResourceID) ||
+		*current.Properties.EndpointStatus != *desired.Properties.EndpointStatus {
+		return false
+	}
+	if current.Properties.MinChildEndpoints == nil || *current.Properties.MinChildEndpoints != *desired.Properties.MinChildEndpoints {
+		return false
+	}
+	// The weight is only set when the parent profile uses the "Weighted" routing method.
+	if desired.Properties.Weight != nil && (current.Properties.Weight == nil || *current.Properties.Weight != *desired.Properties.Weight) {
+		return false
+	}
+	// The priority is only set when the parent profile uses the "Priority" routing method.
+	if desired.Properties.Priority != nil && (current.Properties.Priority == nil || *current.Properties.Priority != *desired.Properties.Priority) {
+		return false
+	}
+	return true
+}
+
+// handleParentProfileEvent enqueues the child profiles which are nested in the profile.
+func (r *Reconciler) handleParentProfileEvent(ctx context.Context, object client.Object) []reconcile.Request {
+	profileList := &fleetnetv1beta1.TrafficManagerProfileList{}
+	if err := r.Client.List(ctx, profileList, client.InNamespace(object.GetNamespace())); err != nil {
+		klog.ErrorS(err, "Failed to list trafficManagerProfiles for the parent profile", "parentTrafficManagerProfile", klog.KObj(object))
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range profileList.Items {
+		child := &profileList.Items[i]
+		if child.Spec.ParentProfile == nil || child.Spec.ParentProfile.Name != object.GetName() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: child.Namespace, Name: child.Name}})
+	}
+	return requests
+}
+
+// handleBackendEvent enqueues the profile referenced by the trafficManagerBackend when the profile is nested in a
+// parent profile with the "EndpointCount" fallback of the minChildEndpoints, as the number of its endpoints may be
+// changed.
+func (r *Reconciler) handleBackendEvent(ctx context.Context, object client.Object) []reconcile.Request {
+	backend, ok := object.(*fleetnetv1beta1.TrafficManagerBackend)
+	if !ok {
+		return nil
+	}
+	profileName := types.NamespacedName{Namespace: backend.Spec.Profile.Namespace, Name: backend.Spec.Profile.Name}
+	if profileName.Namespace == "" {
+		profileName.Namespace = backend.Namespace
+	}
+	profile := &fleetnetv1beta1.TrafficManagerProfile{}
+	if err := r.Client.Get(ctx, profileName, profile); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get the trafficManagerProfile for the trafficManagerBackend", "trafficManagerBackend", klog.KObj(backend), "trafficManagerProfile", klog.KRef(profileName.Namespace, profileName.Name))
+		}
+		return nil
+	}
+	if profile.Spec.ParentProfile == nil || profile.Spec.ParentProfile.MinChildEndpointsFallback != fleetnetv1beta1.TrafficManagerMinChildEndpointsFallbackEndpointCount {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: profileName}}
+}
+
+// backendEventPredicate filters the trafficManagerBackend events which may change the number of the endpoints in the
+// referenced profile, that is, the number of the accepted endpoints is changed or the backend is deleted.
+func backendEventPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool {
+			// The endpoints are only created after the backend is reconciled, which updates the backend status.
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldBackend, ok := e.ObjectOld.(*fleetnetv1beta1.TrafficManagerBackend)
+			if !ok {
+				return false
+			}
+			newBackend, ok := e.ObjectNew.(*fleetnetv1beta1.TrafficManagerBackend)
+			if !ok {
+				return false
+			}
+			return len(oldBackend.Status.Endpoints) != len(newBackend.Status.Endpoints)
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// parentProfileEventPredicate filters the parent profile events which may change the nested endpoints of the child
+// profiles, that is, the parent profile is created, programmed or deleted.
+func parentProfileEventPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldProfile, ok := e.ObjectOld.(*fleetnetv1beta1.TrafficManagerProfile)
+			if !ok {
+				return false
+			}
+			newProfile, ok := e.ObjectNew.(*fleetnetv1beta1.TrafficManagerProfile)
+			if !ok {
+				return false
+			}
+			return oldProfile.Status.ResourceID != newProfile.Status.ResourceID ||
+				(oldProfile.DeletionTimestamp.IsZero() && !newProfile.DeletionTimestamp.IsZero())
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+}
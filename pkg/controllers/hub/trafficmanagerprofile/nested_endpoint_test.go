@@ -0,0 +1,684 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package trafficmanagerprofile
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	azcorefake "github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager"
+	armtrafficmanagerfake "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/trafficmanager/armtrafficmanager/fake"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/azureclient"
+)
+
+const (
+	nestedTestNamespace   = "test-ns"
+	nestedTestSubResource = "/subscriptions/sub/resourceGroups/%s/providers/Microsoft.Network/trafficManagerProfiles/%s"
+)
+
+func nestedTestProfileResourceID(resourceGroup, atmProfileName string) string {
+	return fmt.Sprintf(nestedTestSubResource, resourceGroup, atmProfileName)
+}
+
+func nestedTestParentProfile(name string, routingMethod fleetnetv1beta1.TrafficManagerRoutingMethod, programmed bool) *fleetnetv1beta1.TrafficManagerProfile {
+	profile := &fleetnetv1beta1.TrafficManagerProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: nestedTestNamespace,
+			UID:       types.UID(name + "-uid"),
+		},
+		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+			ResourceGroup: name + "-rg",
+			RoutingMethod: routingMethod,
+		},
+	}
+	if programmed {
+		profile.Status.ResourceID = nestedTestProfileResourceID(name+"-rg", "fleet-"+name+"-uid")
+	}
+	return profile
+}
+
+// fakeNestedEndpointsServer stores the nested endpoints keyed by "{resourceGroup}/{profileName}/{endpointName}".
+type fakeNestedEndpointsServer struct {
+	endpoints map[string]armtrafficmanager.Endpoint
+	deleted   []string
+}
+
+func (f *fakeNestedEndpointsServer) server() armtrafficmanagerfake.EndpointsServer {
+	return armtrafficmanagerfake.EndpointsServer{
+		Get: func(_ context.Context, resourceGroupName string, profileName string, _ armtrafficmanager.EndpointType, endpointName string, _ *armtrafficmanager.EndpointsClientGetOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientGetResponse], errResp azcorefake.ErrorResponder) {
+			endpoint, ok := f.endpoints[fmt.Sprintf("%s/%s/%s", resourceGroupName, profileName, endpointName)]
+			if !ok {
+				errResp.SetResponseError(http.StatusNotFound, "NotFound")
+				return resp, errResp
+			}
+			resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientGetResponse{Endpoint: endpoint}, nil)
+			return resp, errResp
+		},
+		CreateOrUpdate: func(_ context.Context, resourceGroupName string, profileName string, _ armtrafficmanager.EndpointType, endpointName string, parameters armtrafficmanager.Endpoint, _ *armtrafficmanager.EndpointsClientCreateOrUpdateOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientCreateOrUpdateResponse], errResp azcorefake.ErrorResponder) {
+			parameters.ID = ptr.To(nestedTestProfileResourceID(resourceGroupName, profileName) + "/nestedEndpoints/" + endpointName)
+			f.endpoints[fmt.Sprintf("%s/%s/%s", resourceGroupName, profileName, endpointName)] = parameters
+			resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientCreateOrUpdateResponse{Endpoint: parameters}, nil)
+			return resp, errResp
+		},
+		Delete: func(_ context.Context, resourceGroupName string, profileName string, _ armtrafficmanager.EndpointType, endpointName string, _ *armtrafficmanager.EndpointsClientDeleteOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientDeleteResponse], errResp azcorefake.ErrorResponder) {
+			key := fmt.Sprintf("%s/%s/%s", resourceGroupName, profileName, endpointName)
+			f.deleted = append(f.deleted, key)
+			if _, ok := f.endpoints[key]; !ok {
+				errResp.SetResponseError(http.StatusNotFound, "NotFound")
+				return resp, errResp
+			}
+			delete(f.endpoints, key)
+			resp.SetResponse(http.StatusOK, armtrafficmanager.EndpointsClientDeleteResponse{}, nil)
+			return resp, errResp
+		},
+	}
+}
+
+func TestReconcileNestedEndpoint(t *testing.T) {
+	childResourceID := nestedTestProfileResourceID("child-rg", "fleet-child-uid")
+	parent1NestedEndpointID := nestedTestProfileResourceID("parent-1-rg", "fleet-parent-1-uid") + "/nestedEndpoints/fleet-nested-child-uid"
+	parent2NestedEndpointID := nestedTestProfileResourceID("parent-2-rg", "fleet-parent-2-uid") + "/nestedEndpoints/fleet-nested-child-uid"
+	parent1Endpoint := armtrafficmanager.Endpoint{
+		ID:   ptr.To(parent1NestedEndpointID),
+		Name: ptr.To("fleet-nested-child-uid"),
+		Type: ptr.To(azureTrafficManagerNestedEndpointType),
+		Properties: &armtrafficmanager.EndpointProperties{
+			TargetResourceID:  ptr.To(childResourceID),
+			EndpointStatus:    ptr.To(armtrafficmanager.EndpointStatusEnabled),
+			MinChildEndpoints: ptr.To(int64(1)),
+			Weight:            ptr.To(int64(1)),
+		},
+	}
+
+	tests := []struct {
+		name                 string
+		parentProfile        *fleetnetv1beta1.TrafficManagerParentProfile
+		nestedEndpointID     string
+		atmProfile           *armtrafficmanager.Profile
+		parents              []client.Object
+		existingEndpoints    map[string]armtrafficmanager.Endpoint
+		wantErr              bool
+		wantNestedEndpointID string
+		wantEndpoints        map[string]armtrafficmanager.Endpoint
+		wantDeleted          []string
+	}{
+		{
+			name: "profile is not nested",
+		},
+		{
+			name:              "parent profile is removed",
+			nestedEndpointID:  parent1NestedEndpointID,
+			existingEndpoints: map[string]armtrafficmanager.Endpoint{"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid": parent1Endpoint},
+			wantEndpoints:     map[string]armtrafficmanager.Endpoint{},
+			wantDeleted:       []string{"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid"},
+		},
+		{
+			name:          "create the nested endpoint in the weighted parent profile",
+			parentProfile: &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent-1"},
+			parents: []client.Object{
+				nestedTestParentProfile("parent-1", fleetnetv1beta1.TrafficManagerRoutingMethodWeighted, true),
+			},
+			wantNestedEndpointID: parent1NestedEndpointID,
+			wantEndpoints:        map[string]armtrafficmanager.Endpoint{"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid": parent1Endpoint},
+		},
+		{
+			name:             "nested endpoint is up to date",
+			parentProfile:    &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent-1"},
+			nestedEndpointID: parent1NestedEndpointID,
+			parents: []client.Object{
+				nestedTestParentProfile("parent-1", fleetnetv1beta1.TrafficManagerRoutingMethodWeighted, true),
+			},
+			existingEndpoints:    map[string]armtrafficmanager.Endpoint{"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid": parent1Endpoint},
+			wantNestedEndpointID: parent1NestedEndpointID,
+			wantEndpoints:        map[string]armtrafficmanager.Endpoint{"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid": parent1Endpoint},
+		},
+		{
+			name:             "parent profile is changed",
+			parentProfile:    &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent-2", Priority: ptr.To(int64(2)), MinChildEndpoints: ptr.To(int64(3))},
+			nestedEndpointID: parent1NestedEndpointID,
+			parents: []client.Object{
+				nestedTestParentProfile("parent-1", fleetnetv1beta1.TrafficManagerRoutingMethodWeighted, true),
+				nestedTestParentProfile("parent-2", fleetnetv1beta1.TrafficManagerRoutingMethodPriority, true),
+			},
+			existingEndpoints:    map[string]armtrafficmanager.Endpoint{"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid": parent1Endpoint},
+			wantNestedEndpointID: parent2NestedEndpointID,
+			wantEndpoints: map[string]armtrafficmanager.Endpoint{
+				"parent-2-rg/fleet-parent-2-uid/fleet-nested-child-uid": {
+					ID:   ptr.To(parent2NestedEndpointID),
+					Name: ptr.To("fleet-nested-child-uid"),
+					Type: ptr.To(azureTrafficManagerNestedEndpointType),
+					Properties: &armtrafficmanager.EndpointProperties{
+						TargetResourceID:  ptr.To(childResourceID),
+						EndpointStatus:    ptr.To(armtrafficmanager.EndpointStatusEnabled),
+						MinChildEndpoints: ptr.To(int64(3)),
+						Priority:          ptr.To(int64(2)),
+					},
+				},
+			},
+			wantDeleted: []string{"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid"},
+		},
+		{
+			name:          "minChildEndpoints falls back to the number of the enabled endpoints",
+			parentProfile: &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent-1", MinChildEndpoints: ptr.To(int64(3)), MinChildEndpointsFallback: fleetnetv1beta1.TrafficManagerMinChildEndpointsFallbackEndpointCount},
+			atmProfile: &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					Endpoints: []*armtrafficmanager.Endpoint{
+						{Properties: &armtrafficmanager.EndpointProperties{EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled)}},
+						{Properties: &armtrafficmanager.EndpointProperties{EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled)}},
+						{Properties: &armtrafficmanager.EndpointProperties{EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusDisabled)}},
+					},
+				},
+			},
+			nestedEndpointID: parent1NestedEndpointID,
+			parents: []client.Object{
+				nestedTestParentProfile("parent-1", fleetnetv1beta1.TrafficManagerRoutingMethodWeighted, true),
+			},
+			existingEndpoints:    map[string]armtrafficmanager.Endpoint{"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid": parent1Endpoint},
+			wantNestedEndpointID: parent1NestedEndpointID,
+			wantEndpoints: map[string]armtrafficmanager.Endpoint{
+				"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid": {
+					ID:   ptr.To(parent1NestedEndpointID),
+					Name: ptr.To("fleet-nested-child-uid"),
+					Type: ptr.To(azureTrafficManagerNestedEndpointType),
+					Properties: &armtrafficmanager.EndpointProperties{
+						TargetResourceID:  ptr.To(childResourceID),
+						EndpointStatus:    ptr.To(armtrafficmanager.EndpointStatusEnabled),
+						MinChildEndpoints: ptr.To(int64(2)),
+						Weight:            ptr.To(int64(1)),
+					},
+				},
+			},
+		},
+		{
+			name:                 "parent profile is not programmed yet",
+			parentProfile:        &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent-1"},
+			nestedEndpointID:     parent1NestedEndpointID,
+			parents:              []client.Object{nestedTestParentProfile("parent-1", fleetnetv1beta1.TrafficManagerRoutingMethodWeighted, false)},
+			existingEndpoints:    map[string]armtrafficmanager.Endpoint{"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid": parent1Endpoint},
+			wantNestedEndpointID: parent1NestedEndpointID,
+			wantEndpoints:        map[string]armtrafficmanager.Endpoint{"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid": parent1Endpoint},
+		},
+		{
+			name:              "parent profile is not found",
+			parentProfile:     &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent-1"},
+			nestedEndpointID:  parent1NestedEndpointID,
+			existingEndpoints: map[string]armtrafficmanager.Endpoint{"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid": parent1Endpoint},
+			wantEndpoints:     map[string]armtrafficmanager.Endpoint{},
+			wantDeleted:       []string{"parent-1-rg/fleet-parent-1-uid/fleet-nested-child-uid"},
+		},
+		{
+			name:          "parent profile uses unsupported routing method",
+			parentProfile: &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent-1"},
+			parents: []client.Object{
+				nestedTestParentProfile("parent-1", fleetnetv1beta1.TrafficManagerRoutingMethodGeographic, true),
+			},
+			wantEndpoints: map[string]armtrafficmanager.Endpoint{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoints := tc.existingEndpoints
+			if endpoints == nil {
+				endpoints = map[string]armtrafficmanager.Endpoint{}
+			}
+			fakeEndpoints := &fakeNestedEndpointsServer{endpoints: endpoints}
+			fakeServer := fakeEndpoints.server()
+			clientFactory, err := armtrafficmanager.NewClientFactory("subscription-id", &azcorefake.TokenCredential{},
+				&arm.ClientOptions{
+					ClientOptions: azcore.ClientOptions{
+						Transport: armtrafficmanagerfake.NewEndpointsServerTransport(&fakeServer),
+						Retry:     policy.RetryOptions{MaxRetries: -1}, // disable the retry of the SDK
+					},
+				})
+			if err != nil {
+				t.Fatalf("failed to create the client factory: %v", err)
+			}
+			scheme := runtime.NewScheme()
+			if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add scheme: %v", err)
+			}
+			r := &Reconciler{
+				Client:             fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.parents...).Build(),
+				AzureClientFactory: azureclient.NewTrafficManagerClientFactoryForClients(nil, clientFactory.NewEndpointsClient()),
+				Recorder:           record.NewFakeRecorder(10),
+			}
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "child",
+					Namespace: nestedTestNamespace,
+					UID:       "child-uid",
+				},
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					ResourceGroup: "child-rg",
+					ParentProfile: tc.parentProfile,
+				},
+				Status: fleetnetv1beta1.TrafficManagerProfileStatus{
+					ResourceID:               childResourceID,
+					NestedEndpointResourceID: tc.nestedEndpointID,
+				},
+			}
+			atmProfile := tc.atmProfile
+			if atmProfile == nil {
+				atmProfile = &armtrafficmanager.Profile{}
+			}
+			err = r.reconcileNestedEndpoint(context.Background(), profile, atmProfile)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("reconcileNestedEndpoint() got error %v, want error %v", err, tc.wantErr)
+			}
+			if profile.Status.NestedEndpointResourceID != tc.wantNestedEndpointID {
+				t.Errorf("reconcileNestedEndpoint() got nestedEndpointResourceID %q, want %q", profile.Status.NestedEndpointResourceID, tc.wantNestedEndpointID)
+			}
+			wantEndpoints := tc.wantEndpoints
+			if wantEndpoints == nil {
+				wantEndpoints = map[string]armtrafficmanager.Endpoint{}
+			}
+			if diff := cmp.Diff(wantEndpoints, fakeEndpoints.endpoints); diff != "" {
+				t.Errorf("reconcileNestedEndpoint() endpoints mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantDeleted, fakeEndpoints.deleted); diff != "" {
+				t.Errorf("reconcileNestedEndpoint() deleted endpoints mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateParentProfile(t *testing.T) {
+	tests := []struct {
+		name          string
+		parentProfile *fleetnetv1beta1.TrafficManagerParentProfile
+		routingMethod fleetnetv1beta1.TrafficManagerRoutingMethod
+		wantErr       bool
+	}{
+		{
+			name:          "weighted parent profile",
+			parentProfile: &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent"},
+			routingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+		},
+		{
+			name:          "priority parent profile",
+			parentProfile: &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent", Priority: ptr.To(int64(1))},
+			routingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodPriority,
+		},
+		{
+			name:          "priority parent profile without priority",
+			parentProfile: &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent"},
+			routingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodPriority,
+			wantErr:       true,
+		},
+		{
+			name:          "geographic parent profile",
+			parentProfile: &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent"},
+			routingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodGeographic,
+			wantErr:       true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parent := &fleetnetv1beta1.TrafficManagerProfile{
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{RoutingMethod: tc.routingMethod},
+			}
+			err := validateParentProfile(tc.parentProfile, parent)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("validateParentProfile() got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDesiredMinChildEndpoints(t *testing.T) {
+	tests := []struct {
+		name             string
+		parentProfile    *fleetnetv1beta1.TrafficManagerParentProfile
+		enabledEndpoints int64
+		want             int64
+	}{
+		{
+			name:             "defaults to 1",
+			parentProfile:    &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent"},
+			enabledEndpoints: 3,
+			want:             1,
+		},
+		{
+			name:             "no fallback with fewer enabled endpoints",
+			parentProfile:    &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent", MinChildEndpoints: ptr.To(int64(3))},
+			enabledEndpoints: 1,
+			want:             3,
+		},
+		{
+			name:             "explicit none fallback with fewer enabled endpoints",
+			parentProfile:    &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent", MinChildEndpoints: ptr.To(int64(3)), MinChildEndpointsFallback: fleetnetv1beta1.TrafficManagerMinChildEndpointsFallbackNone},
+			enabledEndpoints: 1,
+			want:             3,
+		},
+		{
+			name:             "fallback with more enabled endpoints",
+			parentProfile:    &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent", MinChildEndpoints: ptr.To(int64(3)), MinChildEndpointsFallback: fleetnetv1beta1.TrafficManagerMinChildEndpointsFallbackEndpointCount},
+			enabledEndpoints: 5,
+			want:             3,
+		},
+		{
+			name:             "fallback with the same number of enabled endpoints",
+			parentProfile:    &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent", MinChildEndpoints: ptr.To(int64(3)), MinChildEndpointsFallback: fleetnetv1beta1.TrafficManagerMinChildEndpointsFallbackEndpointCount},
+			enabledEndpoints: 3,
+			want:             3,
+		},
+		{
+			name:             "fallback with fewer enabled endpoints",
+			parentProfile:    &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent", MinChildEndpoints: ptr.To(int64(3)), MinChildEndpointsFallback: fleetnetv1beta1.TrafficManagerMinChildEndpointsFallbackEndpointCount},
+			enabledEndpoints: 2,
+			want:             2,
+		},
+		{
+			name:             "fallback without enabled endpoints",
+			parentProfile:    &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent", MinChildEndpoints: ptr.To(int64(3)), MinChildEndpointsFallback: fleetnetv1beta1.TrafficManagerMinChildEndpointsFallbackEndpointCount},
+			enabledEndpoints: 0,
+			want:             1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := desiredMinChildEndpoints(tc.parentProfile, tc.enabledEndpoints); got != tc.want {
+				t.Errorf("desiredMinChildEndpoints() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCountEnabledEndpoints(t *testing.T) {
+	tests := []struct {
+		name       string
+		atmProfile *armtrafficmanager.Profile
+		want       int64
+	}{
+		{
+			name: "nil profile",
+		},
+		{
+			name:       "nil properties",
+			atmProfile: &armtrafficmanager.Profile{},
+		},
+		{
+			name: "enabled, disabled and nested endpoints",
+			atmProfile: &armtrafficmanager.Profile{
+				Properties: &armtrafficmanager.ProfileProperties{
+					Endpoints: []*armtrafficmanager.Endpoint{
+						nil,
+						{Properties: &armtrafficmanager.EndpointProperties{EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled)}},
+						{Properties: &armtrafficmanager.EndpointProperties{EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusDisabled)}},
+						{
+							Type:       ptr.To(azureTrafficManagerNestedEndpointType),
+							Properties: &armtrafficmanager.EndpointProperties{EndpointStatus: ptr.To(armtrafficmanager.EndpointStatusEnabled)},
+						},
+						{Properties: &armtrafficmanager.EndpointProperties{}}, // enabled by default
+					},
+				},
+			},
+			want: 3,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := countEnabledEndpoints(tc.atmProfile); got != tc.want {
+				t.Errorf("countEnabledEndpoints() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEqualAzureTrafficManagerNestedEndpoint(t *testing.T) {
+	desired := armtrafficmanager.Endpoint{
+		Properties: &armtrafficmanager.EndpointProperties{
+			TargetResourceID:  ptr.To("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/trafficManagerProfiles/child"),
+			EndpointStatus:    ptr.To(armtrafficmanager.EndpointStatusEnabled),
+			MinChildEndpoints: ptr.To(int64(1)),
+			Weight:            ptr.To(int64(10)),
+		},
+	}
+	tests := []struct {
+		name    string
+		current armtrafficmanager.Endpoint
+		want    bool
+	}{
+		{
+			name: "same endpoint with different cases",
+			current: armtrafficmanager.Endpoint{
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID:  ptr.To(strings.ToUpper(*desired.Properties.TargetResourceID)),
+					EndpointStatus:    ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					MinChildEndpoints: ptr.To(int64(1)),
+					Weight:            ptr.To(int64(10)),
+					Priority:          ptr.To(int64(1)), // ignored as the parent profile uses weighted routing
+				},
+			},
+			want: true,
+		},
+		{
+			name:    "nil properties",
+			current: armtrafficmanager.Endpoint{},
+		},
+		{
+			name: "different min child endpoints",
+			current: armtrafficmanager.Endpoint{
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID:  desired.Properties.TargetResourceID,
+					EndpointStatus:    ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					MinChildEndpoints: ptr.To(int64(2)),
+					Weight:            ptr.To(int64(10)),
+				},
+			},
+		},
+		{
+			name: "different weight",
+			current: armtrafficmanager.Endpoint{
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID:  desired.Properties.TargetResourceID,
+					EndpointStatus:    ptr.To(armtrafficmanager.EndpointStatusEnabled),
+					MinChildEndpoints: ptr.To(int64(1)),
+					Weight:            ptr.To(int64(1)),
+				},
+			},
+		},
+		{
+			name: "disabled endpoint",
+			current: armtrafficmanager.Endpoint{
+				Properties: &armtrafficmanager.EndpointProperties{
+					TargetResourceID:  desired.Properties.TargetResourceID,
+					EndpointStatus:    ptr.To(armtrafficmanager.EndpointStatusDisabled),
+					MinChildEndpoints: ptr.To(int64(1)),
+					Weight:            ptr.To(int64(10)),
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := equalAzureTrafficManagerNestedEndpoint(tc.current, desired); got != tc.want {
+				t.Errorf("equalAzureTrafficManagerNestedEndpoint() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHandleParentProfileEvent(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	parent := nestedTestParentProfile("parent", fleetnetv1beta1.TrafficManagerRoutingMethodWeighted, true)
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			parent,
+			&fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "child-1", Namespace: nestedTestNamespace},
+				Spec:       fleetnetv1beta1.TrafficManagerProfileSpec{ParentProfile: &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent"}},
+			},
+			&fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "child-2", Namespace: nestedTestNamespace},
+				Spec:       fleetnetv1beta1.TrafficManagerProfileSpec{ParentProfile: &fleetnetv1beta1.TrafficManagerParentProfile{Name: "other-parent"}},
+			},
+			&fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "child-3", Namespace: "other-ns"},
+				Spec:       fleetnetv1beta1.TrafficManagerProfileSpec{ParentProfile: &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent"}},
+			},
+		).
+		Build()
+	r := &Reconciler{Client: fakeClient}
+	got := r.handleParentProfileEvent(context.Background(), parent)
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: nestedTestNamespace, Name: "child-1"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("handleParentProfileEvent() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHandleBackendEvent(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := fleetnetv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	fallback := &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent", MinChildEndpointsFallback: fleetnetv1beta1.TrafficManagerMinChildEndpointsFallbackEndpointCount}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			&fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "fallback", Namespace: nestedTestNamespace},
+				Spec:       fleetnetv1beta1.TrafficManagerProfileSpec{ParentProfile: fallback},
+			},
+			&fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "no-fallback", Namespace: nestedTestNamespace},
+				Spec:       fleetnetv1beta1.TrafficManagerProfileSpec{ParentProfile: &fleetnetv1beta1.TrafficManagerParentProfile{Name: "parent"}},
+			},
+			&fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "not-nested", Namespace: nestedTestNamespace},
+			},
+		).
+		Build()
+	r := &Reconciler{Client: fakeClient}
+
+	tests := []struct {
+		name    string
+		profile fleetnetv1beta1.TrafficManagerProfileRef
+		want    []reconcile.Request
+	}{
+		{
+			name:    "profile with the endpoint count fallback",
+			profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "fallback"},
+			want: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: nestedTestNamespace, Name: "fallback"}},
+			},
+		},
+		{
+			name:    "profile with the endpoint count fallback in another namespace",
+			profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "fallback", Namespace: nestedTestNamespace},
+			want: []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: nestedTestNamespace, Name: "fallback"}},
+			},
+		},
+		{
+			name:    "profile without the fallback",
+			profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "no-fallback"},
+		},
+		{
+			name:    "profile is not nested",
+			profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "not-nested"},
+		},
+		{
+			name:    "profile is not found",
+			profile: fleetnetv1beta1.TrafficManagerProfileRef{Name: "not-found"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			namespace := nestedTestNamespace
+			if tc.profile.Namespace != "" {
+				namespace = "team-ns"
+			}
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: namespace},
+				Spec:       fleetnetv1beta1.TrafficManagerBackendSpec{Profile: tc.profile},
+			}
+			got := r.handleBackendEvent(context.Background(), backend)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("handleBackendEvent() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBackendEventPredicate(t *testing.T) {
+	backendWithEndpoints := func(n int) *fleetnetv1beta1.TrafficManagerBackend {
+		backend := &fleetnetv1beta1.TrafficManagerBackend{}
+		for i := 0; i < n; i++ {
+			backend.Status.Endpoints = append(backend.Status.Endpoints, fleetnetv1beta1.TrafficManagerEndpointStatus{Name: fmt.Sprintf("endpoint-%d", i)})
+		}
+		return backend
+	}
+	tests := []struct {
+		name   string
+		oldObj client.Object
+		newObj client.Object
+		want   bool
+	}{
+		{
+			name:   "endpoint is added",
+			oldObj: backendWithEndpoints(1),
+			newObj: backendWithEndpoints(2),
+			want:   true,
+		},
+		{
+			name:   "endpoint is removed",
+			oldObj: backendWithEndpoints(2),
+			newObj: backendWithEndpoints(1),
+			want:   true,
+		},
+		{
+			name:   "number of endpoints is unchanged",
+			oldObj: backendWithEndpoints(2),
+			newObj: backendWithEndpoints(2),
+		},
+		{
+			name:   "not a backend",
+			oldObj: &fleetnetv1beta1.TrafficManagerProfile{},
+			newObj: backendWithEndpoints(1),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := backendEventPredicate().Update(event.UpdateEvent{ObjectOld: tc.oldObj, ObjectNew: tc.newObj})
+			if got != tc.want {
+				t.Errorf("backendEventPredicate().Update() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+	if backendEventPredicate().Create(event.CreateEvent{Object: backendWithEndpoints(1)}) {
+		t.Errorf("backendEventPredicate().Create() = true, want false")
+	}
+	if !backendEventPredicate().Delete(event.DeleteEvent{Object: backendWithEndpoints(1)}) {
+		t.Errorf("backendEventPredicate().Delete() = false, want true")
+	}
+}
@@ -27,6 +27,7 @@ import (
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/azureclient"
 	"go.goms.io/fleet-networking/test/common/trafficmanager/fakeprovider"
 )
 
@@ -100,9 +101,9 @@ var _ = BeforeSuite(func() {
 	}
 
 	err = (&Reconciler{
-		Client:         mgr.GetClient(),
-		ProfilesClient: profileClient,
-		Recorder:       mgr.GetEventRecorderFor(ControllerName),
+		Client:             mgr.GetClient(),
+		AzureClientFactory: azureclient.NewTrafficManagerClientFactoryForClients(profileClient, nil),
+		Recorder:           mgr.GetEventRecorderFor(ControllerName),
 	}).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred())
 
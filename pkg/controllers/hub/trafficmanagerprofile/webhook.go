@@ -0,0 +1,46 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package trafficmanagerprofile
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+	"go.goms.io/fleet-networking/pkg/common/defaulter"
+)
+
+//+kubebuilder:webhook:path=/mutate-networking-fleet-azure-com-v1beta1-trafficmanagerprofile,mutating=true,failurePolicy=ignore,sideEffects=None,groups=networking.fleet.azure.com,resources=trafficmanagerprofiles,verbs=create;update,versions=v1beta1,name=mtrafficmanagerprofile.networking.fleet.azure.com,admissionReviewVersions=v1
+
+// Defaulter sets the default values of the trafficManagerProfile at admission, for example, the monitor config, so that
+// the users see the effective configuration in the trafficManagerProfile right after it's created.
+// The defaults are the same as the ones applied by the controller, which still defaults the trafficManagerProfiles
+// admitted without the webhook.
+type Defaulter struct{}
+
+var _ admission.CustomDefaulter = &Defaulter{}
+
+// SetupWebhookWithManager registers the mutating webhook of the trafficManagerProfile with the manager.
+func (d *Defaulter) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&fleetnetv1beta1.TrafficManagerProfile{}).
+		WithDefaulter(d).
+		Complete()
+}
+
+// Default implements the admission.CustomDefaulter interface.
+func (d *Defaulter) Default(_ context.Context, obj runtime.Object) error {
+	profile, ok := obj.(*fleetnetv1beta1.TrafficManagerProfile)
+	if !ok {
+		return fmt.Errorf("expected a trafficManagerProfile object but got %T", obj)
+	}
+	defaulter.SetDefaultsTrafficManagerProfile(profile)
+	return nil
+}
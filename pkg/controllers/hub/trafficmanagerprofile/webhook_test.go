@@ -0,0 +1,115 @@
+/*
+Copyright (c) Microsoft Corporation.
+Licensed under the MIT license.
+*/
+
+package trafficmanagerprofile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
+)
+
+func TestDefaulter(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *fleetnetv1beta1.TrafficManagerProfile
+		want    fleetnetv1beta1.TrafficManagerProfileSpec
+	}{
+		{
+			name:    "nil monitor config",
+			profile: &fleetnetv1beta1.TrafficManagerProfile{},
+			want: fleetnetv1beta1.TrafficManagerProfileSpec{
+				RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+				MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+					IntervalInSeconds:         ptr.To(int64(30)),
+					Path:                      ptr.To("/"),
+					Port:                      ptr.To(int64(80)),
+					Protocol:                  ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolHTTP),
+					TimeoutInSeconds:          ptr.To(int64(10)),
+					ToleratedNumberOfFailures: ptr.To(int64(3)),
+				},
+			},
+		},
+		{
+			name: "partial monitor config",
+			profile: &fleetnetv1beta1.TrafficManagerProfile{
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodPriority,
+					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+						IntervalInSeconds: ptr.To(int64(10)),
+						Port:              ptr.To(int64(443)),
+						Protocol:          ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolHTTPS),
+					},
+				},
+			},
+			want: fleetnetv1beta1.TrafficManagerProfileSpec{
+				RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodPriority,
+				MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+					IntervalInSeconds:         ptr.To(int64(10)),
+					Path:                      ptr.To("/"),
+					Port:                      ptr.To(int64(443)),
+					Protocol:                  ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolHTTPS),
+					TimeoutInSeconds:          ptr.To(int64(9)),
+					ToleratedNumberOfFailures: ptr.To(int64(3)),
+				},
+			},
+		},
+		{
+			name: "full monitor config",
+			profile: &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-profile",
+					Namespace: "test-ns",
+				},
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+						IntervalInSeconds:         ptr.To(int64(30)),
+						Path:                      ptr.To("/healthz"),
+						Port:                      ptr.To(int64(8080)),
+						Protocol:                  ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolTCP),
+						TimeoutInSeconds:          ptr.To(int64(5)),
+						ToleratedNumberOfFailures: ptr.To(int64(1)),
+					},
+				},
+			},
+			want: fleetnetv1beta1.TrafficManagerProfileSpec{
+				RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodWeighted,
+				MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+					IntervalInSeconds:         ptr.To(int64(30)),
+					Path:                      ptr.To("/healthz"),
+					Port:                      ptr.To(int64(8080)),
+					Protocol:                  ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolTCP),
+					TimeoutInSeconds:          ptr.To(int64(5)),
+					ToleratedNumberOfFailures: ptr.To(int64(1)),
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &Defaulter{}
+			if err := d.Default(context.Background(), tc.profile); err != nil {
+				t.Fatalf("Default() got error %v, want nil", err)
+			}
+			if diff := cmp.Diff(tc.want, tc.profile.Spec); diff != "" {
+				t.Errorf("Default() spec mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDefaulter_UnexpectedObject(t *testing.T) {
+	d := &Defaulter{}
+	if err := d.Default(context.Background(), &corev1.ConfigMap{}); err == nil {
+		t.Errorf("Default() got nil error, want error for the unexpected object")
+	}
+}
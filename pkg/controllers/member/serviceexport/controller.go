@@ -11,15 +11,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
@@ -28,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"go.goms.io/fleet/pkg/utils/controller"
 
@@ -39,11 +45,22 @@ import (
 )
 
 const (
-	svcExportValidCondReason                 = "ServiceIsValid"
-	svcExportInvalidNotFoundCondReason       = "ServiceNotFound"
-	svcExportInvalidIneligibleCondReason     = "ServiceIneligible"
-	svcExportPendingConflictResolutionReason = "ServicePendingConflictResolution"
-	svcExportInvalidWeightAnnotationReason   = "ServiceExportInvalidWeightAnnotation"
+	svcExportValidCondReason                   = "ServiceIsValid"
+	svcExportInvalidNotFoundCondReason         = "ServiceNotFound"
+	svcExportInvalidIneligibleCondReason       = "ServiceIneligible"
+	svcExportPendingConflictResolutionReason   = "ServicePendingConflictResolution"
+	svcExportInvalidWeightAnnotationReason     = "ServiceExportInvalidWeightAnnotation"
+	svcExportInvalidGeoMappingAnnotationReason = "ServiceExportInvalidGeoMappingAnnotation"
+	svcExportInvalidPriorityAnnotationReason   = "ServiceExportInvalidPriorityAnnotation"
+	// svcExportInvalidSubnetsAnnotationReason is used when the subnets annotation is not a list of valid CIDRs.
+	svcExportInvalidSubnetsAnnotationReason = "ServiceExportInvalidSubnetsAnnotation"
+	// svcExportInvalidAlwaysServeAnnotationReason is used when the always serve annotation is not a valid boolean.
+	svcExportInvalidAlwaysServeAnnotationReason = "ServiceExportInvalidAlwaysServeAnnotation"
+	// svcExportInvalidWeightByReadyEndpointsAnnotationReason is used when the weight by ready endpoints annotation is not
+	// a valid boolean.
+	svcExportInvalidWeightByReadyEndpointsAnnotationReason = "ServiceExportInvalidWeightByReadyEndpointsAnnotation"
+	// svcExportInvalidWeightBoundsAnnotationReason is used when the min or max weight percentage annotation is invalid.
+	svcExportInvalidWeightBoundsAnnotationReason = "ServiceExportInvalidWeightBoundsAnnotation"
 
 	// svcExportCleanupFinalizer is the finalizer ServiceExport controllers adds to mark that
 	// a ServiceExport can only be deleted after its corresponding Service has been unexported from the hub cluster.
@@ -63,6 +80,7 @@ type Reconciler struct {
 	Recorder     record.EventRecorder
 
 	ResourceGroupName          string // default resource group name to create public IP address
+	Region                     string // the Azure region of the member cluster, reported to the hub cluster
 	AzurePublicIPAddressClient publicipaddressclient.Interface
 
 	EnableTrafficManagerFeature bool
@@ -73,6 +91,7 @@ type Reconciler struct {
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=serviceexports/finalizers,verbs=update
 //+kubebuilder:rbac:groups=networking.fleet.azure.com,resources=internalserviceexports,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile exports a Service.
@@ -175,25 +194,43 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// Get the weight from the serviceExport annotation and validate it.
 	exportWeight, err := objectmeta.ExtractWeightFromServiceExport(&svcExport)
 	if err != nil {
-		// Here we don't unexport the service as it will interrupt the current traffic.
-		// There is no need to requeue the error as the controller should be triggered when the user corrects the annotation.
-		klog.ErrorS(controller.NewUserError(err), "service export has invalid annotation weight", "service", svcRef)
-		curValidCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1beta1.ServiceExportValid))
-		expectedValidCond := metav1.Condition{
-			Type:               string(fleetnetv1beta1.ServiceExportValid),
-			Status:             metav1.ConditionFalse,
-			Reason:             svcExportInvalidWeightAnnotationReason,
-			ObservedGeneration: svcExport.Generation,
-			Message:            fmt.Sprintf("serviceExport %s/%s has an invalid weight annotation, err = %s", svcExport.Namespace, svcExport.Name, err),
-		}
-		// We have to compare the message since we cannot rely on the object generation as annotation does not change generation.
-		if condition.EqualConditionWithMessage(curValidCond, &expectedValidCond) {
-			// no need to retry if the condition is already set
-			return ctrl.Result{}, nil
-		}
-		r.Recorder.Eventf(&svcExport, corev1.EventTypeWarning, svcExportInvalidWeightAnnotationReason, "ServiceExport %s has invalid weight value in the annotation", svc.Name)
-		meta.SetStatusCondition(&svcExport.Status.Conditions, expectedValidCond)
-		return ctrl.Result{}, r.MemberClient.Status().Update(ctx, &svcExport)
+		return r.markServiceExportAsInvalidAnnotation(ctx, &svcExport, svcExportInvalidWeightAnnotationReason, "weight", err)
+	}
+
+	// Get the geo mapping from the serviceExport annotation and validate it.
+	exportGeoMapping, err := objectmeta.ExtractGeoMappingFromServiceExport(&svcExport)
+	if err != nil {
+		return r.markServiceExportAsInvalidAnnotation(ctx, &svcExport, svcExportInvalidGeoMappingAnnotationReason, "geo mapping", err)
+	}
+
+	// Get the subnets from the serviceExport annotation and validate them.
+	exportSubnets, err := objectmeta.ExtractSubnetsFromServiceExport(&svcExport)
+	if err != nil {
+		return r.markServiceExportAsInvalidAnnotation(ctx, &svcExport, svcExportInvalidSubnetsAnnotationReason, "subnets", err)
+	}
+
+	// Get the priority from the serviceExport annotation and validate it.
+	exportPriority, err := objectmeta.ExtractPriorityFromServiceExport(&svcExport)
+	if err != nil {
+		return r.markServiceExportAsInvalidAnnotation(ctx, &svcExport, svcExportInvalidPriorityAnnotationReason, "priority", err)
+	}
+
+	// Get the always serve setting from the serviceExport annotation and validate it.
+	exportAlwaysServe, err := objectmeta.ExtractAlwaysServeFromServiceExport(&svcExport)
+	if err != nil {
+		return r.markServiceExportAsInvalidAnnotation(ctx, &svcExport, svcExportInvalidAlwaysServeAnnotationReason, "always serve", err)
+	}
+
+	// Get the weight by ready endpoints setting from the serviceExport annotation and validate it.
+	exportWeightByReadyEndpoints, err := objectmeta.ExtractWeightByReadyEndpointsFromServiceExport(&svcExport)
+	if err != nil {
+		return r.markServiceExportAsInvalidAnnotation(ctx, &svcExport, svcExportInvalidWeightByReadyEndpointsAnnotationReason, "weight by ready endpoints", err)
+	}
+
+	// Get the weight bounds from the serviceExport annotations and validate them.
+	exportMinWeightPercentage, exportMaxWeightPercentage, err := objectmeta.ExtractWeightBoundsFromServiceExport(&svcExport)
+	if err != nil {
+		return r.markServiceExportAsInvalidAnnotation(ctx, &svcExport, svcExportInvalidWeightBoundsAnnotationReason, "weight bounds", err)
 	}
 
 	if exportWeight == 0 {
@@ -250,11 +287,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	// Export the Service or update the exported Service.
-	return r.exportService(ctx, &svcExport, &svc, exportedSince, exportWeight)
+	return r.exportService(ctx, &svcExport, &svc, exportedSince, exportWeight, exportMinWeightPercentage, exportMaxWeightPercentage, exportGeoMapping, exportSubnets, exportPriority, exportAlwaysServe, exportWeightByReadyEndpoints)
 }
 
 func (r *Reconciler) exportService(ctx context.Context, svcExport *fleetnetv1beta1.ServiceExport, svc *corev1.Service,
-	exportedSince time.Time, exportWeight int64) (ctrl.Result, error) {
+	exportedSince time.Time, exportWeight int64, exportMinWeightPercentage, exportMaxWeightPercentage *int64,
+	exportGeoMapping, exportSubnets string, exportPriority *int64, exportAlwaysServe, exportWeightByReadyEndpoints bool) (ctrl.Result, error) {
 	svcRef := klog.KObj(svc)
 	// Create or update the InternalServiceExport object.
 	internalSvcExport := fleetnetv1alpha1.InternalServiceExport{
@@ -298,6 +336,54 @@ func (r *Reconciler) exportService(ctx context.Context, svcExport *fleetnetv1bet
 		if r.EnableTrafficManagerFeature {
 			klog.V(2).InfoS("Collecting Traffic Manager related information and set to the internal service export", "service", svcRef)
 			internalSvcExport.Spec.Weight = ptr.To(exportWeight)
+			internalSvcExport.Spec.WeightPercentage = nil
+			if objectmeta.IsWeightPercentage(svcExport) {
+				internalSvcExport.Spec.WeightPercentage = ptr.To(exportWeight)
+			}
+			internalSvcExport.Spec.MinWeightPercentage = exportMinWeightPercentage
+			internalSvcExport.Spec.MaxWeightPercentage = exportMaxWeightPercentage
+			internalSvcExport.Spec.ReadyEndpointCount = nil
+			if exportWeightByReadyEndpoints {
+				count, err := r.countReadyEndpoints(ctx, svc)
+				if err != nil {
+					klog.ErrorS(err, "Failed to count the ready endpoints of the service", "service", svcRef)
+					return err
+				}
+				internalSvcExport.Spec.ReadyEndpointCount = ptr.To(count)
+			}
+			internalSvcExport.Spec.Priority = exportPriority
+			// The external targets are validated by the hub controller when configuring the Traffic Manager endpoints.
+			internalSvcExport.Spec.ExternalTargetFQDN = extractExternalTarget(svcExport, objectmeta.ServiceExportAnnotationExternalTargetFQDN)
+			internalSvcExport.Spec.ExternalTargetIP = extractExternalTarget(svcExport, objectmeta.ServiceExportAnnotationExternalTargetIP)
+			internalSvcExport.Spec.ExternalTargetLocation = extractExternalTarget(svcExport, objectmeta.ServiceExportAnnotationExternalTargetLocation)
+			internalSvcExport.Spec.Region = nil
+			if r.Region != "" {
+				internalSvcExport.Spec.Region = ptr.To(r.Region)
+			}
+			if len(exportGeoMapping) > 0 {
+				if internalSvcExport.Annotations == nil {
+					internalSvcExport.Annotations = map[string]string{}
+				}
+				internalSvcExport.Annotations[objectmeta.ServiceExportAnnotationGeoMapping] = exportGeoMapping
+			} else {
+				delete(internalSvcExport.Annotations, objectmeta.ServiceExportAnnotationGeoMapping)
+			}
+			if len(exportSubnets) > 0 {
+				if internalSvcExport.Annotations == nil {
+					internalSvcExport.Annotations = map[string]string{}
+				}
+				internalSvcExport.Annotations[objectmeta.ServiceExportAnnotationSubnets] = exportSubnets
+			} else {
+				delete(internalSvcExport.Annotations, objectmeta.ServiceExportAnnotationSubnets)
+			}
+			if exportAlwaysServe {
+				if internalSvcExport.Annotations == nil {
+					internalSvcExport.Annotations = map[string]string{}
+				}
+				internalSvcExport.Annotations[objectmeta.ServiceExportAnnotationAlwaysServe] = "true"
+			} else {
+				delete(internalSvcExport.Annotations, objectmeta.ServiceExportAnnotationAlwaysServe)
+			}
 			if err := r.setAzureRelatedInformation(ctx, svc, &internalSvcExport); err != nil {
 				klog.ErrorS(err, "Failed to populate the Azure information for the Traffic Manager feature in the internal service export", "service", svcRef)
 				return err
@@ -364,10 +450,11 @@ func (r *Reconciler) setAzureRelatedInformation(ctx context.Context,
 		return nil
 	}
 
-	pip, err := r.lookupPublicIPResourceIDByLoadBalancerIP(ctx, service)
+	pips, err := r.lookupPublicIPAddressesByLoadBalancerIPs(ctx, service)
 	if err != nil {
 		return err
 	}
+	pip := pips[service.Status.LoadBalancer.Ingress[0].IP]
 	if pip == nil {
 		klog.V(2).InfoS("The public IP is in the progressing", "service", serviceKObj, "ip", service.Status.LoadBalancer.Ingress[0].IP)
 		// Assuming once the service status is updated, the controller will be triggered again in instead of retrying here
@@ -375,6 +462,7 @@ func (r *Reconciler) setAzureRelatedInformation(ctx context.Context,
 		return nil
 	}
 	hubSvcExport.Spec.PublicIPResourceID = pip.ID
+	hubSvcExport.Spec.PublicIPAddresses = buildPublicIPAddresses(service, pips)
 
 	// Note the user can set the dns label via the Azure portal or Azure CLI without updating service.
 	// This information may be stale as we don't monitor the public IP address resource.
@@ -391,6 +479,9 @@ func (r *Reconciler) setAzureRelatedInformation(ctx context.Context,
 	}
 	if len(dnsName) == 0 {
 		hubSvcExport.Spec.IsDNSLabelConfigured = false // cloud provider will delete the DNS label on the pip.
+		for i := range hubSvcExport.Spec.PublicIPAddresses {
+			hubSvcExport.Spec.PublicIPAddresses[i].IsDNSLabelConfigured = false
+		}
 		return nil
 	}
 	if !hubSvcExport.Spec.IsDNSLabelConfigured {
@@ -402,10 +493,12 @@ func (r *Reconciler) setAzureRelatedInformation(ctx context.Context,
 	return nil
 }
 
+// lookupPublicIPAddressesByLoadBalancerIPs returns the public IP addresses keyed by the load balancer ingress IPs of
+// the service, for example, both the IPv4 and IPv6 ones of a dual-stack service.
 // TODO: can improve the performance by caching the public IP address resource ID.
 // Note: we don't support "service.beta.kubernetes.io/azure-pip-prefix-id" annotation, and public ip cannot be found in
 // this case.
-func (r *Reconciler) lookupPublicIPResourceIDByLoadBalancerIP(ctx context.Context, service *corev1.Service) (*armnetwork.PublicIPAddress, error) {
+func (r *Reconciler) lookupPublicIPAddressesByLoadBalancerIPs(ctx context.Context, service *corev1.Service) (map[string]*armnetwork.PublicIPAddress, error) {
 	// The customer can specify the resource group for the public IP address in the service annotation.
 	rg := strings.TrimSpace(service.Annotations[objectmeta.ServiceAnnotationLoadBalancerResourceGroup])
 	if len(rg) == 0 {
@@ -417,24 +510,102 @@ func (r *Reconciler) lookupPublicIPResourceIDByLoadBalancerIP(ctx context.Contex
 		klog.ErrorS(err, "Failed to list Azure public IP addresses", "service", serviceKObj, "resourceGroup", rg)
 		return nil, err
 	}
-	for _, pip := range pips {
-		if pip.Properties != nil && pip.Properties.IPAddress != nil &&
-			*pip.Properties.IPAddress == service.Status.LoadBalancer.Ingress[0].IP {
-			return pip, nil
+	res := make(map[string]*armnetwork.PublicIPAddress)
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.IP == "" {
+			continue
+		}
+		for _, pip := range pips {
+			if pip.Properties != nil && pip.Properties.IPAddress != nil && *pip.Properties.IPAddress == ingress.IP {
+				res[ingress.IP] = pip
+				break
+			}
+		}
+		if res[ingress.IP] == nil {
+			klog.V(2).InfoS("The public IP address resource ID cannot be found in the public IP lists", "service", serviceKObj, "ip", ingress.IP, "resourceGroup", rg)
 		}
 	}
-	klog.V(2).InfoS("The public IP address resource ID cannot be found in the public IP lists", "service", serviceKObj, "ip", service.Status.LoadBalancer.Ingress[0].IP, "resourceGroup", rg)
-	return nil, nil
+	return res, nil
+}
+
+// buildPublicIPAddresses returns the public IP addresses of the load balancer ingress IPs in order, keeping the first
+// one of each IP family.
+func buildPublicIPAddresses(service *corev1.Service, pips map[string]*armnetwork.PublicIPAddress) []fleetnetv1alpha1.PublicIPAddress {
+	var res []fleetnetv1alpha1.PublicIPAddress
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		pip := pips[ingress.IP]
+		if pip == nil || pip.ID == nil {
+			continue
+		}
+		ip := net.ParseIP(ingress.IP)
+		if ip == nil {
+			continue
+		}
+		family := corev1.IPv6Protocol
+		if ip.To4() != nil {
+			family = corev1.IPv4Protocol
+		}
+		if slices.ContainsFunc(res, func(addr fleetnetv1alpha1.PublicIPAddress) bool { return addr.IPFamily == family }) {
+			continue
+		}
+		res = append(res, fleetnetv1alpha1.PublicIPAddress{
+			IPFamily:             family,
+			ResourceID:           *pip.ID,
+			IsDNSLabelConfigured: pip.Properties != nil && pip.Properties.DNSSettings != nil && pip.Properties.DNSSettings.DomainNameLabel != nil,
+		})
+	}
+	return res
+}
+
+// countReadyEndpoints returns the number of the ready endpoints of the Service across its EndpointSlices.
+func (r *Reconciler) countReadyEndpoints(ctx context.Context, svc *corev1.Service) (int64, error) {
+	endpointSliceList := &discoveryv1.EndpointSliceList{}
+	listOpts := client.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			discoveryv1.LabelServiceName: svc.Name,
+		}),
+		Namespace: svc.Namespace,
+	}
+	if err := r.MemberClient.List(ctx, endpointSliceList, &listOpts); err != nil {
+		return 0, err
+	}
+	return countReadyEndpoints(endpointSliceList.Items), nil
 }
 
 // SetupWithManager builds a controller with Reconciler and sets it up with a controller manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		// The ServiceExport controller watches over ServiceExport objects.
 		For(&fleetnetv1beta1.ServiceExport{}).
 		// The ServiceExport controller watches over Service objects.
-		Watches(&corev1.Service{}, &handler.EnqueueRequestForObject{}).
-		Complete(r)
+		Watches(&corev1.Service{}, &handler.EnqueueRequestForObject{})
+	if r.EnableTrafficManagerFeature {
+		// The ServiceExport controller watches over EndpointSlice objects to update the ready endpoint count of the
+		// Services exported with the weight by ready endpoints annotation.
+		builder = builder.Watches(&discoveryv1.EndpointSlice{}, handler.EnqueueRequestsFromMapFunc(r.handleEndpointSliceEvent))
+	}
+	return builder.Complete(r)
+}
+
+// handleEndpointSliceEvent enqueues the ServiceExport of the EndpointSlice's Service only when the ServiceExport is
+// weighted by its ready endpoints, so that the changes of the endpoints do not re-export the other Services.
+func (r *Reconciler) handleEndpointSliceEvent(ctx context.Context, o client.Object) []reconcile.Request {
+	svcName := o.GetLabels()[discoveryv1.LabelServiceName]
+	if svcName == "" {
+		return nil
+	}
+	name := types.NamespacedName{Namespace: o.GetNamespace(), Name: svcName}
+	svcExport := &fleetnetv1beta1.ServiceExport{}
+	if err := r.MemberClient.Get(ctx, name, svcExport); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to get the service export of the endpoint slice", "endpointSlice", klog.KObj(o), "serviceExport", klog.KRef(name.Namespace, name.Name))
+		}
+		return nil
+	}
+	if enabled, _ := objectmeta.ExtractWeightByReadyEndpointsFromServiceExport(svcExport); !enabled {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: name}}
 }
 
 // unexportService unexports a Service, specifically, it deletes the corresponding InternalServiceExport from the
@@ -513,6 +684,30 @@ func (r *Reconciler) markServiceExportAsInvalidSvcIneligible(ctx context.Context
 	return r.MemberClient.Status().Update(ctx, svcExport)
 }
 
+// markServiceExportAsInvalidAnnotation marks a ServiceExport as invalid because of the invalid value of an annotation,
+// for example, the weight annotation.
+func (r *Reconciler) markServiceExportAsInvalidAnnotation(ctx context.Context, svcExport *fleetnetv1beta1.ServiceExport, reason, what string, err error) (ctrl.Result, error) {
+	// Here we don't unexport the service as it will interrupt the current traffic.
+	// There is no need to requeue the error as the controller should be triggered when the user corrects the annotation.
+	klog.ErrorS(controller.NewUserError(err), "service export has invalid annotation", "service", klog.KObj(svcExport), "annotation", what)
+	curValidCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1beta1.ServiceExportValid))
+	expectedValidCond := metav1.Condition{
+		Type:               string(fleetnetv1beta1.ServiceExportValid),
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		ObservedGeneration: svcExport.Generation,
+		Message:            fmt.Sprintf("serviceExport %s/%s has an invalid %s annotation, err = %s", svcExport.Namespace, svcExport.Name, what, err),
+	}
+	// We have to compare the message since we cannot rely on the object generation as annotation does not change generation.
+	if condition.EqualConditionWithMessage(curValidCond, &expectedValidCond) {
+		// no need to retry if the condition is already set
+		return ctrl.Result{}, nil
+	}
+	r.Recorder.Eventf(svcExport, corev1.EventTypeWarning, reason, "ServiceExport %s has invalid %s value in the annotation", svcExport.Name, what)
+	meta.SetStatusCondition(&svcExport.Status.Conditions, expectedValidCond)
+	return ctrl.Result{}, r.MemberClient.Status().Update(ctx, svcExport)
+}
+
 // addServiceExportCleanupFinalizer adds the cleanup finalizer to a ServiceExport.
 func (r *Reconciler) addServiceExportCleanupFinalizer(ctx context.Context, svcExport *fleetnetv1beta1.ServiceExport) error {
 	controllerutil.AddFinalizer(svcExport, svcExportCleanupFinalizer)
@@ -322,6 +322,65 @@ func serviceIsExportedToHubActual(serviceType corev1.ServiceType, isPublicAzureL
 	}
 }
 
+// internalServiceExportGeoMappingActual runs with Eventually and Consistently assertion to make sure that
+// the internalServiceExport on the hub cluster has the expected geo mapping annotation.
+func internalServiceExportGeoMappingActual(expectedGeoMapping string) func() error {
+	return func() error {
+		internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+		if err := hubClient.Get(ctx, internalSvcExportKey, internalSvcExport); err != nil {
+			return fmt.Errorf("internalServiceExport Get(%+v), got %w, want no error", internalSvcExportKey, err)
+		}
+		geoMapping, found := internalSvcExport.Annotations[objectmeta.ServiceExportAnnotationGeoMapping]
+		if expectedGeoMapping == "" {
+			if found {
+				return fmt.Errorf("internalServiceExport geo mapping annotation, got %q, want absent", geoMapping)
+			}
+			return nil
+		}
+		if geoMapping != expectedGeoMapping {
+			return fmt.Errorf("internalServiceExport geo mapping annotation, got %q, want %q", geoMapping, expectedGeoMapping)
+		}
+		return nil
+	}
+}
+
+// internalServiceExportSubnetsActual runs with Eventually and Consistently assertion to make sure that
+// the internalServiceExport on the hub cluster has the expected subnets annotation.
+func internalServiceExportSubnetsActual(expectedSubnets string) func() error {
+	return func() error {
+		internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+		if err := hubClient.Get(ctx, internalSvcExportKey, internalSvcExport); err != nil {
+			return fmt.Errorf("internalServiceExport Get(%+v), got %w, want no error", internalSvcExportKey, err)
+		}
+		subnets, found := internalSvcExport.Annotations[objectmeta.ServiceExportAnnotationSubnets]
+		if expectedSubnets == "" {
+			if found {
+				return fmt.Errorf("internalServiceExport subnets annotation, got %q, want absent", subnets)
+			}
+			return nil
+		}
+		if subnets != expectedSubnets {
+			return fmt.Errorf("internalServiceExport subnets annotation, got %q, want %q", subnets, expectedSubnets)
+		}
+		return nil
+	}
+}
+
+// internalServiceExportPriorityActual runs with Eventually and Consistently assertion to make sure that
+// the internalServiceExport on the hub cluster has the expected priority.
+func internalServiceExportPriorityActual(expectedPriority *int64) func() error {
+	return func() error {
+		internalSvcExport := &fleetnetv1alpha1.InternalServiceExport{}
+		if err := hubClient.Get(ctx, internalSvcExportKey, internalSvcExport); err != nil {
+			return fmt.Errorf("internalServiceExport Get(%+v), got %w, want no error", internalSvcExportKey, err)
+		}
+		if !cmp.Equal(internalSvcExport.Spec.Priority, expectedPriority) {
+			return fmt.Errorf("internalServiceExport priority, got %v, want %v", ptr.Deref(internalSvcExport.Spec.Priority, 0), ptr.Deref(expectedPriority, 0))
+		}
+		return nil
+	}
+}
+
 var _ = Describe("serviceexport controller", func() {
 	Context("export non-existent service", func() {
 		var svcExport = &fleetnetv1beta1.ServiceExport{}
@@ -663,6 +722,144 @@ var _ = Describe("serviceexport controller", func() {
 			Eventually(serviceIsExportedToHubActual(svc.Spec.Type, false, nil), eventuallyTimeout, eventuallyInterval).Should(Succeed())
 		})
 
+		It("annotation geo mapping should be propagated to the hub", func() {
+			By("confirm that the service has been exported")
+			Eventually(serviceIsExportedFromMemberActual, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+			Eventually(serviceIsExportedToHubActual(svc.Spec.Type, false, ptr.To(int64(weight))), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+			Eventually(internalServiceExportGeoMappingActual(""), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+
+			By("add the geo mapping annotation to the serviceExport in the member cluster")
+			Expect(memberClient.Get(ctx, svcOrSvcExportKey, svcExport)).Should(Succeed())
+			svcExport.Annotations[objectmeta.ServiceExportAnnotationGeoMapping] = "GEO-EU, US-CA"
+			Expect(memberClient.Update(ctx, svcExport)).Should(Succeed())
+
+			By("check the geo mapping of the exported service")
+			Eventually(internalServiceExportGeoMappingActual("GEO-EU,US-CA"), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+
+			By("change the geo mapping annotation of the serviceExport in the member cluster")
+			Expect(memberClient.Get(ctx, svcOrSvcExportKey, svcExport)).Should(Succeed())
+			svcExport.Annotations[objectmeta.ServiceExportAnnotationGeoMapping] = "GEO-AS"
+			Expect(memberClient.Update(ctx, svcExport)).Should(Succeed())
+
+			By("check the geo mapping of the exported service again")
+			Eventually(internalServiceExportGeoMappingActual("GEO-AS"), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+
+			By("remove the geo mapping annotation of the serviceExport in the member cluster")
+			Expect(memberClient.Get(ctx, svcOrSvcExportKey, svcExport)).Should(Succeed())
+			delete(svcExport.Annotations, objectmeta.ServiceExportAnnotationGeoMapping)
+			Expect(memberClient.Update(ctx, svcExport)).Should(Succeed())
+
+			By("make sure the geo mapping is removed from the exported service")
+			Eventually(internalServiceExportGeoMappingActual(""), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+			Eventually(serviceIsExportedToHubActual(svc.Spec.Type, false, ptr.To(int64(weight))), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+		})
+
+		It("invalid annotation geo mapping should invalidate the exported service", func() {
+			By("confirm that the service has been exported")
+			Eventually(serviceIsExportedFromMemberActual, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+			Eventually(serviceIsExportedToHubActual(svc.Spec.Type, false, ptr.To(int64(weight))), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+
+			By("update the serviceExport in the member cluster")
+			Expect(memberClient.Get(ctx, svcOrSvcExportKey, svcExport)).Should(Succeed())
+			svcExport.Annotations[objectmeta.ServiceExportAnnotationGeoMapping] = "  "
+			Expect(memberClient.Update(ctx, svcExport)).Should(Succeed())
+
+			By("make sure the serviceExport is marked as invalid")
+			err := fmt.Errorf("the geo mapping annotation is empty: %q", svcExport.Annotations[objectmeta.ServiceExportAnnotationGeoMapping])
+			expectedCond := metav1.Condition{
+				Type:               string(fleetnetv1beta1.ServiceExportValid),
+				Status:             metav1.ConditionFalse,
+				Reason:             svcExportInvalidGeoMappingAnnotationReason,
+				ObservedGeneration: svcExport.Generation,
+				Message:            fmt.Sprintf("serviceExport %s/%s has an invalid geo mapping annotation, err = %s", svcExport.Namespace, svcExport.Name, err),
+			}
+			Eventually(func() error {
+				svcExport := &fleetnetv1beta1.ServiceExport{}
+				Expect(memberClient.Get(ctx, svcOrSvcExportKey, svcExport)).Should(Succeed())
+				validCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1beta1.ServiceExportValid))
+				if diff := cmp.Diff(validCond, &expectedCond, ignoredCondFields); diff != "" {
+					return fmt.Errorf("serviceExportValid condition (-got, +want): %s", diff)
+				}
+				return nil
+			}, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+
+			By("make sure the service is still exported as we don't want to disrupt the service")
+			err = serviceIsExportedToHubActual(svc.Spec.Type, false, ptr.To(int64(weight)))()
+			Expect(err).Should(Succeed(), "Service is not exported to hub: %v", err)
+			err = internalServiceExportGeoMappingActual("")()
+			Expect(err).Should(Succeed(), "Service is exported with the geo mapping: %v", err)
+		})
+
+		It("annotation subnets should be propagated to the hub", func() {
+			By("confirm that the service has been exported")
+			Eventually(serviceIsExportedFromMemberActual, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+			Eventually(internalServiceExportSubnetsActual(""), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+
+			By("add the subnets annotation to the serviceExport in the member cluster")
+			Expect(memberClient.Get(ctx, svcOrSvcExportKey, svcExport)).Should(Succeed())
+			svcExport.Annotations[objectmeta.ServiceExportAnnotationSubnets] = "10.1.0.0/16, 10.2.0.0/16"
+			Expect(memberClient.Update(ctx, svcExport)).Should(Succeed())
+
+			By("check the subnets of the exported service")
+			Eventually(internalServiceExportSubnetsActual("10.1.0.0/16,10.2.0.0/16"), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+
+			By("remove the subnets annotation of the serviceExport in the member cluster")
+			Expect(memberClient.Get(ctx, svcOrSvcExportKey, svcExport)).Should(Succeed())
+			delete(svcExport.Annotations, objectmeta.ServiceExportAnnotationSubnets)
+			Expect(memberClient.Update(ctx, svcExport)).Should(Succeed())
+
+			By("make sure the subnets are removed from the exported service")
+			Eventually(internalServiceExportSubnetsActual(""), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+		})
+
+		It("annotation priority should be propagated to the hub", func() {
+			By("confirm that the service has been exported")
+			Eventually(serviceIsExportedFromMemberActual, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+			Eventually(internalServiceExportPriorityActual(nil), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+
+			By("add the priority annotation to the serviceExport in the member cluster")
+			Expect(memberClient.Get(ctx, svcOrSvcExportKey, svcExport)).Should(Succeed())
+			svcExport.Annotations[objectmeta.ServiceExportAnnotationPriority] = "10"
+			Expect(memberClient.Update(ctx, svcExport)).Should(Succeed())
+
+			By("check the priority of the exported service")
+			Eventually(internalServiceExportPriorityActual(ptr.To(int64(10))), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+
+			By("update the priority annotation to an invalid value")
+			Expect(memberClient.Get(ctx, svcOrSvcExportKey, svcExport)).Should(Succeed())
+			svcExport.Annotations[objectmeta.ServiceExportAnnotationPriority] = "0"
+			Expect(memberClient.Update(ctx, svcExport)).Should(Succeed())
+
+			By("make sure the serviceExport is marked as invalid")
+			err := fmt.Errorf("the priority annotation is not in the range [1, 1000]: %s", svcExport.Annotations[objectmeta.ServiceExportAnnotationPriority])
+			expectedCond := metav1.Condition{
+				Type:               string(fleetnetv1beta1.ServiceExportValid),
+				Status:             metav1.ConditionFalse,
+				Reason:             svcExportInvalidPriorityAnnotationReason,
+				ObservedGeneration: svcExport.Generation,
+				Message:            fmt.Sprintf("serviceExport %s/%s has an invalid priority annotation, err = %s", svcExport.Namespace, svcExport.Name, err),
+			}
+			Eventually(func() error {
+				svcExport := &fleetnetv1beta1.ServiceExport{}
+				Expect(memberClient.Get(ctx, svcOrSvcExportKey, svcExport)).Should(Succeed())
+				validCond := meta.FindStatusCondition(svcExport.Status.Conditions, string(fleetnetv1beta1.ServiceExportValid))
+				if diff := cmp.Diff(validCond, &expectedCond, ignoredCondFields); diff != "" {
+					return fmt.Errorf("serviceExportValid condition (-got, +want): %s", diff)
+				}
+				return nil
+			}, eventuallyTimeout, eventuallyInterval).Should(Succeed())
+
+			By("make sure the last valid priority is still exported as we don't want to disrupt the service")
+			Consistently(internalServiceExportPriorityActual(ptr.To(int64(10))), consistentlyDuration, consistentlyInterval).Should(Succeed())
+
+			By("remove the priority annotation of the serviceExport in the member cluster")
+			Expect(memberClient.Get(ctx, svcOrSvcExportKey, svcExport)).Should(Succeed())
+			delete(svcExport.Annotations, objectmeta.ServiceExportAnnotationPriority)
+			Expect(memberClient.Update(ctx, svcExport)).Should(Succeed())
+
+			By("make sure the priority is removed from the exported service")
+			Eventually(internalServiceExportPriorityActual(nil), eventuallyTimeout, eventuallyInterval).Should(Succeed())
+		})
 	})
 
 	Context("unexport service", func() {
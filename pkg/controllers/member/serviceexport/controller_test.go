@@ -18,6 +18,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -27,6 +28,7 @@ import (
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
@@ -259,6 +261,179 @@ func TestExtractServicePorts(t *testing.T) {
 	}
 }
 
+// TestExtractExternalTarget tests the extractExternalTarget function.
+func TestExtractExternalTarget(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		want        *string
+	}{
+		{
+			name: "annotation is not set",
+			want: nil,
+		},
+		{
+			name: "annotation is empty",
+			annotations: map[string]string{
+				objectmeta.ServiceExportAnnotationExternalTargetFQDN: "  ",
+			},
+			want: nil,
+		},
+		{
+			name: "annotation is set",
+			annotations: map[string]string{
+				objectmeta.ServiceExportAnnotationExternalTargetFQDN: " app.example.com ",
+			},
+			want: ptr.To("app.example.com"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   memberUserNS,
+					Name:        svcName,
+					Annotations: tc.annotations,
+				},
+			}
+			got := extractExternalTarget(svcExport, objectmeta.ServiceExportAnnotationExternalTargetFQDN)
+			if !cmp.Equal(got, tc.want) {
+				t.Fatalf("extractExternalTarget() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestCountReadyEndpoints tests the countReadyEndpoints function.
+func TestCountReadyEndpoints(t *testing.T) {
+	testCases := []struct {
+		name           string
+		endpointSlices []discoveryv1.EndpointSlice
+		want           int64
+	}{
+		{
+			name: "no endpoint slices",
+			want: 0,
+		},
+		{
+			name: "ready, unknown and not ready endpoints across endpoint slices",
+			endpointSlices: []discoveryv1.EndpointSlice{
+				{
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{
+						{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+						{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}},
+					},
+				},
+				{
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{
+						{Addresses: []string{"10.0.0.3"}},
+					},
+				},
+			},
+			want: 2,
+		},
+		{
+			name: "dual-stack endpoint slices",
+			endpointSlices: []discoveryv1.EndpointSlice{
+				{
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints: []discoveryv1.Endpoint{
+						{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+						{Addresses: []string{"10.0.0.2"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+					},
+				},
+				{
+					AddressType: discoveryv1.AddressTypeIPv6,
+					Endpoints: []discoveryv1.Endpoint{
+						{Addresses: []string{"fd00::1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+						{Addresses: []string{"fd00::2"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}},
+					},
+				},
+			},
+			want: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := countReadyEndpoints(tc.endpointSlices); got != tc.want {
+				t.Errorf("countReadyEndpoints() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestHandleEndpointSliceEvent tests the *Reconciler.handleEndpointSliceEvent method.
+func TestHandleEndpointSliceEvent(t *testing.T) {
+	endpointSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: memberUserNS,
+			Name:      "endpoint-slice",
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: svcName,
+			},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+	}
+	testCases := []struct {
+		name          string
+		endpointSlice *discoveryv1.EndpointSlice
+		annotations   map[string]string
+		want          []reconcile.Request
+	}{
+		{
+			name:          "service export is weighted by ready endpoints",
+			endpointSlice: endpointSlice,
+			annotations: map[string]string{
+				objectmeta.ServiceExportAnnotationWeightByReadyEndpoints: "true",
+			},
+			want: []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: memberUserNS, Name: svcName}}},
+		},
+		{
+			name:          "service export is not weighted by ready endpoints",
+			endpointSlice: endpointSlice,
+		},
+		{
+			name: "endpoint slice without service name",
+			endpointSlice: &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: memberUserNS,
+					Name:      "endpoint-slice",
+				},
+			},
+			annotations: map[string]string{
+				objectmeta.ServiceExportAnnotationWeightByReadyEndpoints: "true",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svcExport := &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   memberUserNS,
+					Name:        svcName,
+					Annotations: tc.annotations,
+				},
+			}
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(svcExport).
+				Build()
+			reconciler := Reconciler{
+				MemberClient: fakeMemberClient,
+			}
+			got := reconciler.handleEndpointSliceEvent(context.Background(), tc.endpointSlice)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("handleEndpointSliceEvent() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 // TestMarkServiceExportAsInvalidNotFound tests the *Reconciler.markServiceExportAsInvalidNotFound method.
 func TestMarkServiceExportAsInvalidNotFound(t *testing.T) {
 	exportGeneration := int64(123)
@@ -422,6 +597,95 @@ func TestMarkServiceExportAsInvalidIneligible(t *testing.T) {
 	}
 }
 
+// TestMarkServiceExportAsInvalidAnnotation tests the *Reconciler.markServiceExportAsInvalidAnnotation method.
+func TestMarkServiceExportAsInvalidAnnotation(t *testing.T) {
+	exportGeneration := int64(123)
+	annotationErr := errors.New("invalid value")
+	invalidPriorityCond := metav1.Condition{
+		Type:               string(fleetnetv1beta1.ServiceExportValid),
+		Status:             metav1.ConditionFalse,
+		Reason:             svcExportInvalidPriorityAnnotationReason,
+		ObservedGeneration: exportGeneration,
+		Message:            fmt.Sprintf("serviceExport %s/%s has an invalid priority annotation, err = %s", memberUserNS, svcName, annotationErr),
+	}
+	testCases := []struct {
+		name      string
+		svcExport *fleetnetv1beta1.ServiceExport
+		wantEvent bool
+	}{
+		{
+			name: "should mark a valid svc export as invalid (invalid annotation)",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  memberUserNS,
+					Name:       svcName,
+					Generation: exportGeneration,
+				},
+				Status: fleetnetv1beta1.ServiceExportStatus{
+					Conditions: []metav1.Condition{
+						serviceExportValidCondition(memberUserNS, svcName, exportGeneration),
+					},
+				},
+			},
+			wantEvent: true,
+		},
+		{
+			name: "should skip the svc export already marked as invalid with the same message",
+			svcExport: &fleetnetv1beta1.ServiceExport{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:  memberUserNS,
+					Name:       svcName,
+					Generation: exportGeneration,
+				},
+				Status: fleetnetv1beta1.ServiceExportStatus{
+					Conditions: []metav1.Condition{invalidPriorityCond},
+				},
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeMemberClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(tc.svcExport).
+				WithStatusSubresource(tc.svcExport).
+				Build()
+			recorder := record.NewFakeRecorder(10)
+			reconciler := Reconciler{
+				MemberClient: fakeMemberClient,
+				HubClient:    fake.NewClientBuilder().Build(),
+				HubNamespace: hubNSForMember,
+				Recorder:     recorder,
+			}
+
+			res, err := reconciler.markServiceExportAsInvalidAnnotation(ctx, tc.svcExport, svcExportInvalidPriorityAnnotationReason, "priority", annotationErr)
+			if err != nil {
+				t.Fatalf("failed to mark svc export: %v", err)
+			}
+			if res != (ctrl.Result{}) {
+				t.Errorf("markServiceExportAsInvalidAnnotation() = %+v, want empty result", res)
+			}
+
+			var updatedSvcExport = &fleetnetv1beta1.ServiceExport{}
+			svcExportKey := types.NamespacedName{Namespace: tc.svcExport.Namespace, Name: tc.svcExport.Name}
+			if err := fakeMemberClient.Get(ctx, svcExportKey, updatedSvcExport); err != nil {
+				t.Fatalf("svc export Get(%+v), got %v, want no error", svcExportKey, err)
+			}
+			conds := updatedSvcExport.Status.Conditions
+			wantConds := []metav1.Condition{invalidPriorityCond}
+			if !cmp.Equal(conds, wantConds, ignoredCondFields) {
+				t.Fatalf("svc export conditions, got %+v, want %+v", conds, wantConds)
+			}
+			if gotEvent := len(recorder.Events) > 0; gotEvent != tc.wantEvent {
+				t.Errorf("markServiceExportAsInvalidAnnotation() emitted event %v, want %v", gotEvent, tc.wantEvent)
+			}
+		})
+	}
+}
+
 // TestMarkServiceExportAsValid tests the *Reconciler.markServiceExportAsValid method.
 func TestMarkServiceExportAsValid(t *testing.T) {
 	exportGeneration := int64(123)
@@ -1099,6 +1363,13 @@ func TestSetAzureRelatedInformation(t *testing.T) {
 					IsDNSLabelConfigured:   true,
 					IsInternalLoadBalancer: false,
 					PublicIPResourceID:     ptr.To("/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip"),
+					PublicIPAddresses: []fleetnetv1alpha1.PublicIPAddress{
+						{
+							IPFamily:             corev1.IPv4Protocol,
+							ResourceID:           "/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip",
+							IsDNSLabelConfigured: true,
+						},
+					},
 				},
 			},
 		},
@@ -1137,6 +1408,12 @@ func TestSetAzureRelatedInformation(t *testing.T) {
 				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
 					Type:               corev1.ServiceTypeLoadBalancer,
 					PublicIPResourceID: ptr.To("/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip"),
+					PublicIPAddresses: []fleetnetv1alpha1.PublicIPAddress{
+						{
+							IPFamily:   corev1.IPv4Protocol,
+							ResourceID: "/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip",
+						},
+					},
 				},
 			},
 		},
@@ -1226,6 +1503,13 @@ func TestSetAzureRelatedInformation(t *testing.T) {
 					IsDNSLabelConfigured:   true,
 					IsInternalLoadBalancer: false,
 					PublicIPResourceID:     ptr.To("/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip"),
+					PublicIPAddresses: []fleetnetv1alpha1.PublicIPAddress{
+						{
+							IPFamily:             corev1.IPv4Protocol,
+							ResourceID:           "/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip",
+							IsDNSLabelConfigured: true,
+						},
+					},
 				},
 			},
 		},
@@ -1275,6 +1559,12 @@ func TestSetAzureRelatedInformation(t *testing.T) {
 					IsDNSLabelConfigured:   false,
 					IsInternalLoadBalancer: false,
 					PublicIPResourceID:     ptr.To("/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip"),
+					PublicIPAddresses: []fleetnetv1alpha1.PublicIPAddress{
+						{
+							IPFamily:   corev1.IPv4Protocol,
+							ResourceID: "/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip",
+						},
+					},
 				},
 			},
 		},
@@ -1321,6 +1611,12 @@ func TestSetAzureRelatedInformation(t *testing.T) {
 					IsDNSLabelConfigured:   false,
 					IsInternalLoadBalancer: false,
 					PublicIPResourceID:     ptr.To("/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip"),
+					PublicIPAddresses: []fleetnetv1alpha1.PublicIPAddress{
+						{
+							IPFamily:   corev1.IPv4Protocol,
+							ResourceID: "/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip",
+						},
+					},
 				},
 			},
 		},
@@ -1516,6 +1812,73 @@ func TestSetAzureRelatedInformation(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "dual-stack load balancer type with public ips",
+			service: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					UID: "uid",
+				},
+				Spec: corev1.ServiceSpec{
+					Type: corev1.ServiceTypeLoadBalancer,
+				},
+				Status: corev1.ServiceStatus{
+					LoadBalancer: corev1.LoadBalancerStatus{
+						Ingress: []corev1.LoadBalancerIngress{
+							{
+								IP: "1.2.3.4",
+							},
+							{
+								IP: "2001:db8::1",
+							},
+							{
+								IP: "1.2.5.6", // only the first public ip of each IP family is recorded
+							},
+						},
+					},
+				},
+			},
+			publicIPAddressListResponse: []*armnetwork.PublicIPAddress{
+				{
+					Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+						DNSSettings: &armnetwork.PublicIPAddressDNSSettings{
+							DomainNameLabel: ptr.To("dnsLabel"),
+						},
+						IPAddress: ptr.To("1.2.3.4"),
+					},
+					ID: ptr.To("/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip"),
+				},
+				{
+					Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+						IPAddress: ptr.To("2001:db8::1"),
+					},
+					ID: ptr.To("/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip-ipv6"),
+				},
+				{
+					Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+						IPAddress: ptr.To("1.2.5.6"),
+					},
+					ID: ptr.To("/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip-2"),
+				},
+			},
+			want: &fleetnetv1alpha1.InternalServiceExport{
+				Spec: fleetnetv1alpha1.InternalServiceExportSpec{
+					Type:                 corev1.ServiceTypeLoadBalancer,
+					IsDNSLabelConfigured: true,
+					PublicIPResourceID:   ptr.To("/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip"),
+					PublicIPAddresses: []fleetnetv1alpha1.PublicIPAddress{
+						{
+							IPFamily:             corev1.IPv4Protocol,
+							ResourceID:           "/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip",
+							IsDNSLabelConfigured: true,
+						},
+						{
+							IPFamily:   corev1.IPv6Protocol,
+							ResourceID: "/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip-ipv6",
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "copy the service Export weight annotations to InternalServiceExport with public ip",
 			service: &corev1.Service{
@@ -1554,6 +1917,12 @@ func TestSetAzureRelatedInformation(t *testing.T) {
 					IsDNSLabelConfigured:   false,
 					IsInternalLoadBalancer: false,
 					PublicIPResourceID:     ptr.To("/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip"),
+					PublicIPAddresses: []fleetnetv1alpha1.PublicIPAddress{
+						{
+							IPFamily:   corev1.IPv4Protocol,
+							ResourceID: "/subscriptions/sub1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip",
+						},
+					},
 				},
 			},
 		},
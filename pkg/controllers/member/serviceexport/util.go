@@ -7,8 +7,10 @@ package serviceexport
 
 import (
 	"fmt"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
@@ -43,3 +45,33 @@ func extractServicePorts(svc *corev1.Service) []fleetnetv1alpha1.ServicePort {
 
 	return svcExportPorts
 }
+
+// extractExternalTarget returns the trimmed value of the external target annotation on the ServiceExport, or nil if
+// the annotation is not set or empty.
+func extractExternalTarget(svcExport *fleetnetv1beta1.ServiceExport, annotation string) *string {
+	target := strings.TrimSpace(svcExport.Annotations[annotation])
+	if len(target) == 0 {
+		return nil
+	}
+	return &target
+}
+
+// countReadyEndpoints counts the ready endpoints in the EndpointSlices of a Service.
+// The EndpointSlice API dictates that consumers should interpret unknown ready state, represented by a nil value, as
+// true ready state. The endpoints of a dual-stack Service are listed in the EndpointSlices of each address type, so the
+// largest count of the address types is returned.
+func countReadyEndpoints(endpointSlices []discoveryv1.EndpointSlice) int64 {
+	counts := make(map[discoveryv1.AddressType]int64)
+	for _, endpointSlice := range endpointSlices {
+		for _, endpoint := range endpointSlice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				counts[endpointSlice.AddressType]++
+			}
+		}
+	}
+	var res int64
+	for _, count := range counts {
+		res = max(res, count)
+	}
+	return res
+}
@@ -457,6 +457,23 @@ var _ = Describe("Test networking v1alpha1 API validation", func() {
 			Expect(statusErr.Status().Message).Should(ContainSubstring("spec.resourceGroup: Too long: may not be longer than 90"))
 		})
 
+		It("should deny creating API with timeoutInSeconds > 9 when intervalInSeconds is 10", func() {
+			profile := &fleetnetv1alpha1.TrafficManagerProfile{
+				ObjectMeta: objectMetaWithNameValid,
+				Spec: fleetnetv1alpha1.TrafficManagerProfileSpec{
+					MonitorConfig: &fleetnetv1alpha1.MonitorConfig{
+						IntervalInSeconds: ptr.To(int64(10)),
+						TimeoutInSeconds:  ptr.To(int64(10)),
+					},
+					ResourceGroup: "test-resource-group",
+				},
+			}
+			By("expecting denial of CREATE API with invalid timeoutInSeconds")
+			var err = hubClient.Create(ctx, profile)
+			Expect(errors.As(err, &statusErr)).To(BeTrue(), fmt.Sprintf("Create API call produced error %s. Error type wanted is %s.", reflect.TypeOf(err), reflect.TypeOf(&k8serrors.StatusError{})))
+			Expect(statusErr.Status().Message).Should(ContainSubstring("timeoutInSeconds must be between 5 and 9 when intervalInSeconds is 10"))
+		})
+
 		It("should deny update of resourceGroup", func() {
 			// Create the API.
 			profile := &fleetnetv1alpha1.TrafficManagerProfile{
@@ -375,9 +375,120 @@ var _ = Describe("Test networking v1alpha1 API validation", func() {
 			Expect(errors.As(err, &statusErr)).To(BeTrue(), fmt.Sprintf("Create API call produced error %s. Error type wanted is %s.", reflect.TypeOf(err), reflect.TypeOf(&k8serrors.StatusError{})))
 			Expect(statusErr.Status().Message).Should(ContainSubstring("spec.monitorConfig.customHeaders: Too many: 9: must have at most 8 items,"))
 		})
+
+		It("should deny creating API with expectedStatusCodeRanges whose min is greater than max", func() {
+			// Create the API.
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: objectMetaWithNameValid,
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+						ExpectedStatusCodeRanges: []fleetnetv1beta1.MonitorConfigExpectedStatusCodeRange{
+							{
+								Min: 300,
+								Max: 200,
+							},
+						},
+					},
+					ResourceGroup: trafficManagerProfileSpec.ResourceGroup,
+				},
+			}
+			By("expecting denial of CREATE API with invalid expectedStatusCodeRanges")
+			var err = hubClient.Create(ctx, profile)
+			Expect(errors.As(err, &statusErr)).To(BeTrue(), fmt.Sprintf("Create API call produced error %s. Error type wanted is %s.", reflect.TypeOf(err), reflect.TypeOf(&k8serrors.StatusError{})))
+			Expect(statusErr.Status().Message).Should(ContainSubstring("min must be less than or equal to max"))
+		})
+
+		It("should deny creating API with expectedStatusCodeRanges out of range", func() {
+			// Create the API.
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: objectMetaWithNameValid,
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+						ExpectedStatusCodeRanges: []fleetnetv1beta1.MonitorConfigExpectedStatusCodeRange{
+							{
+								Min: 200,
+								Max: 1000,
+							},
+						},
+					},
+					ResourceGroup: trafficManagerProfileSpec.ResourceGroup,
+				},
+			}
+			By("expecting denial of CREATE API with invalid expectedStatusCodeRanges")
+			var err = hubClient.Create(ctx, profile)
+			Expect(errors.As(err, &statusErr)).To(BeTrue(), fmt.Sprintf("Create API call produced error %s. Error type wanted is %s.", reflect.TypeOf(err), reflect.TypeOf(&k8serrors.StatusError{})))
+			Expect(statusErr.Status().Message).Should(ContainSubstring("spec.monitorConfig.expectedStatusCodeRanges[0].max in body should be less than or equal to 999"))
+		})
+
+		It("should deny creating API with expectedStatusCodeRanges when protocol is TCP", func() {
+			// Create the API.
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: objectMetaWithNameValid,
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					MonitorConfig: &fleetnetv1beta1.MonitorConfig{
+						Protocol: ptr.To(fleetnetv1beta1.TrafficManagerMonitorProtocolTCP),
+						ExpectedStatusCodeRanges: []fleetnetv1beta1.MonitorConfigExpectedStatusCodeRange{
+							{
+								Min: 200,
+								Max: 299,
+							},
+						},
+					},
+					ResourceGroup: trafficManagerProfileSpec.ResourceGroup,
+				},
+			}
+			By("expecting denial of CREATE API with expectedStatusCodeRanges and TCP protocol")
+			var err = hubClient.Create(ctx, profile)
+			Expect(errors.As(err, &statusErr)).To(BeTrue(), fmt.Sprintf("Create API call produced error %s. Error type wanted is %s.", reflect.TypeOf(err), reflect.TypeOf(&k8serrors.StatusError{})))
+			Expect(statusErr.Status().Message).Should(ContainSubstring("expectedStatusCodeRanges is not supported when protocol is TCP"))
+		})
+
+		It("should deny creating API with MultiValue routing method without maxReturn", func() {
+			// Create the API.
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: objectMetaWithNameValid,
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					ResourceGroup: trafficManagerProfileSpec.ResourceGroup,
+					RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodMultiValue,
+				},
+			}
+			By("expecting denial of CREATE API with MultiValue routing method without maxReturn")
+			var err = hubClient.Create(ctx, profile)
+			Expect(errors.As(err, &statusErr)).To(BeTrue(), fmt.Sprintf("Create API call produced error %s. Error type wanted is %s.", reflect.TypeOf(err), reflect.TypeOf(&k8serrors.StatusError{})))
+			Expect(statusErr.Status().Message).Should(ContainSubstring("maxReturn is required when routingMethod is MultiValue and cannot be set otherwise"))
+		})
+
+		It("should deny creating API with maxReturn when routing method is not MultiValue", func() {
+			// Create the API.
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: objectMetaWithNameValid,
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					ResourceGroup: trafficManagerProfileSpec.ResourceGroup,
+					MaxReturn:     ptr.To(int64(2)),
+				},
+			}
+			By("expecting denial of CREATE API with maxReturn of the default Weighted routing method")
+			var err = hubClient.Create(ctx, profile)
+			Expect(errors.As(err, &statusErr)).To(BeTrue(), fmt.Sprintf("Create API call produced error %s. Error type wanted is %s.", reflect.TypeOf(err), reflect.TypeOf(&k8serrors.StatusError{})))
+			Expect(statusErr.Status().Message).Should(ContainSubstring("maxReturn is required when routingMethod is MultiValue and cannot be set otherwise"))
+		})
 	})
 
 	Context("Test TrafficManagerProfile API validation - valid cases", func() {
+		It("should allow creating API with MultiValue routing method and maxReturn", func() {
+			// Create the API.
+			profile := &fleetnetv1beta1.TrafficManagerProfile{
+				ObjectMeta: objectMetaWithNameValid,
+				Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
+					ResourceGroup: trafficManagerProfileSpec.ResourceGroup,
+					RoutingMethod: fleetnetv1beta1.TrafficManagerRoutingMethodMultiValue,
+					MaxReturn:     ptr.To(int64(2)),
+				},
+			}
+			Expect(hubClient.Create(ctx, profile)).Should(Succeed(), "failed to create trafficManagerProfile")
+			Expect(hubClient.Delete(ctx, profile)).Should(Succeed(), "failed to delete trafficManagerProfile")
+		})
+
 		It("should allow creating API with valid name size", func() {
 			// Create the API.
 			trafficManagerProfileName := &fleetnetv1beta1.TrafficManagerProfile{
@@ -629,6 +740,35 @@ var _ = Describe("Test networking v1alpha1 API validation", func() {
 			Expect(errors.As(err, &statusErr)).To(BeTrue(), fmt.Sprintf("Create API call produced error %s. Error type wanted is %s.", reflect.TypeOf(err), reflect.TypeOf(&k8serrors.StatusError{})))
 			Expect(statusErr.Status().Message).Should(ContainSubstring("metadata.name max length is 63"))
 		})
+
+		It("should deny update of backend name", func() {
+			// Create the API.
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: objectMetaWithNameValid,
+				Spec:       trafficManagerBackendSpec,
+			}
+			Expect(hubClient.Create(ctx, backend)).Should(Succeed(), "failed to create trafficManagerBackend")
+			backend.Spec.Backend.Name = "new-backend"
+			By("expecting denial of UPDATE API with backend name")
+			var err = hubClient.Update(ctx, backend)
+			Expect(errors.As(err, &statusErr)).To(BeTrue(), fmt.Sprintf("Update API call produced error %s. Error type wanted is %s.", reflect.TypeOf(err), reflect.TypeOf(&k8serrors.StatusError{})))
+			Expect(statusErr.Status().Message).Should(ContainSubstring("spec.backend.name is immutable"))
+			Expect(hubClient.Delete(ctx, backend)).Should(Succeed(), "failed to delete trafficManagerBackend")
+		})
+
+		It("should deny creating API with the backend name in the additional names", func() {
+			// Create the API.
+			spec := trafficManagerBackendSpec.DeepCopy()
+			spec.Backend.AdditionalNames = []string{"green-backend", spec.Backend.Name}
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: objectMetaWithNameValid,
+				Spec:       *spec,
+			}
+			By("expecting denial of CREATE API with the backend name in the additional names")
+			var err = hubClient.Create(ctx, backend)
+			Expect(errors.As(err, &statusErr)).To(BeTrue(), fmt.Sprintf("Create API call produced error %s. Error type wanted is %s.", reflect.TypeOf(err), reflect.TypeOf(&k8serrors.StatusError{})))
+			Expect(statusErr.Status().Message).Should(ContainSubstring("spec.backend.additionalNames must not contain spec.backend.name"))
+		})
 	})
 
 	Context("Test TrafficManagerBackend API validation - valid cases", func() {
@@ -681,5 +821,19 @@ var _ = Describe("Test networking v1alpha1 API validation", func() {
 			Expect(hubClient.Create(ctx, trafficManagerBackendName)).Should(Succeed(), "failed to create trafficManagerBackend")
 			Expect(hubClient.Delete(ctx, trafficManagerBackendName)).Should(Succeed(), "failed to delete trafficManagerBackend")
 		})
+
+		It("should allow updating the cluster selector", func() {
+			// Create the API.
+			backend := &fleetnetv1beta1.TrafficManagerBackend{
+				ObjectMeta: objectMetaWithNameValid,
+				Spec:       *trafficManagerBackendSpec.DeepCopy(),
+			}
+			Expect(hubClient.Create(ctx, backend)).Should(Succeed(), "failed to create trafficManagerBackend")
+			backend.Spec.Backend.ClusterSelector = &fleetnetv1beta1.TrafficManagerBackendClusterSelector{
+				Clusters: []string{"member-1"},
+			}
+			Expect(hubClient.Update(ctx, backend)).Should(Succeed(), "failed to update trafficManagerBackend")
+			Expect(hubClient.Delete(ctx, backend)).Should(Succeed(), "failed to delete trafficManagerBackend")
+		})
 	})
 })
@@ -28,6 +28,12 @@ var (
 		cmpopts.SortSlices(func(c1, c2 *armtrafficmanager.MonitorConfigCustomHeadersItem) bool {
 			return *c1.Name < *c2.Name
 		}),
+		cmpopts.SortSlices(func(r1, r2 *armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem) bool {
+			if *r1.Min != *r2.Min {
+				return *r1.Min < *r2.Min
+			}
+			return *r1.Max < *r2.Max
+		}),
 	}
 )
 
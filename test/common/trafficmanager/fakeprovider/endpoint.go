@@ -60,13 +60,18 @@ func EnableEndpointForbiddenErr() {
 	returnEndpointForbiddenErr = true
 }
 
+// isSupportedEndpointType returns true if the endpoint type can be sent by the controller.
+func isSupportedEndpointType(endpointType armtrafficmanager.EndpointType) bool {
+	return endpointType == armtrafficmanager.EndpointTypeAzureEndpoints || endpointType == armtrafficmanager.EndpointTypeExternalEndpoints
+}
+
 // EndpointDelete returns the http status code based on the profileName and endpointName.
 func EndpointDelete(_ context.Context, resourceGroupName string, profileName string, endpointType armtrafficmanager.EndpointType, endpointName string, _ *armtrafficmanager.EndpointsClientDeleteOptions) (resp azcorefake.Responder[armtrafficmanager.EndpointsClientDeleteResponse], errResp azcorefake.ErrorResponder) {
 	if resourceGroupName != DefaultResourceGroupName {
 		errResp.SetResponseError(http.StatusNotFound, "ResourceGroupNotFound")
 		return resp, errResp
 	}
-	if strings.HasPrefix(profileName, ValidProfileName) && endpointType == armtrafficmanager.EndpointTypeAzureEndpoints && strings.HasPrefix(strings.ToLower(endpointName), ValidBackendName+"#") {
+	if strings.HasPrefix(profileName, ValidProfileName) && isSupportedEndpointType(endpointType) && strings.HasPrefix(strings.ToLower(endpointName), ValidBackendName+"#") {
 		if endpointName == NotFoundErrEndpointName {
 			errResp.SetResponseError(http.StatusNotFound, "NotFound")
 			return resp, errResp
@@ -77,7 +82,7 @@ func EndpointDelete(_ context.Context, resourceGroupName string, profileName str
 		endpointResp := armtrafficmanager.EndpointsClientDeleteResponse{}
 		resp.SetResponse(http.StatusOK, endpointResp, nil)
 	} else {
-		if endpointType != armtrafficmanager.EndpointTypeAzureEndpoints {
+		if !isSupportedEndpointType(endpointType) {
 			// controller should not send other endpoint types.
 			errResp.SetResponseError(http.StatusBadRequest, "InvalidEndpointType")
 		} else {
@@ -92,7 +97,7 @@ func EndpointCreateOrUpdate(_ context.Context, resourceGroupName string, profile
 		errResp.SetResponseError(http.StatusNotFound, "ResourceGroupNotFound")
 		return resp, errResp
 	}
-	if strings.HasPrefix(profileName, ValidProfileName) && endpointType == armtrafficmanager.EndpointTypeAzureEndpoints && strings.HasPrefix(strings.ToLower(endpointName), ValidBackendName+"#") {
+	if strings.HasPrefix(profileName, ValidProfileName) && isSupportedEndpointType(endpointType) && strings.HasPrefix(strings.ToLower(endpointName), ValidBackendName+"#") {
 		if endpointName == CreateBadRequestErrEndpointName {
 			errResp.SetResponseError(http.StatusBadRequest, "BadRequest")
 			return resp, errResp
@@ -105,21 +110,35 @@ func EndpointCreateOrUpdate(_ context.Context, resourceGroupName string, profile
 				return resp, errResp
 			}
 		}
+		if profileName == ValidProfileWithGeographicRoutingName && (len(endpoint.Properties.GeoMapping) == 0 || endpoint.Properties.Weight != nil) {
+			// the geo mapping is required and the weight is not allowed when using the "Geographic" routing method
+			errResp.SetResponseError(http.StatusBadRequest, "BadRequest")
+			return resp, errResp
+		}
 		endpointResp := armtrafficmanager.EndpointsClientCreateOrUpdateResponse{
 			Endpoint: armtrafficmanager.Endpoint{
 				Name: ptr.To(endpointName),
 				Properties: &armtrafficmanager.EndpointProperties{
 					TargetResourceID: ptr.To(ValidPublicIPResourceID),
 					Weight:           endpoint.Properties.Weight,
+					GeoMapping:       endpoint.Properties.GeoMapping,
+					Priority:         endpoint.Properties.Priority,
 					Target:           ptr.To(ValidEndpointTarget),
 				},
 				Type: ptr.To(string(azureTrafficManagerEndpointTypePrefix + armtrafficmanager.EndpointTypeAzureEndpoints)),
 				ID:   ptr.To(fmt.Sprintf(EndpointResourceIDFormat, DefaultSubscriptionID, resourceGroupName, profileName, endpointName)),
 			},
 		}
+		if endpointType == armtrafficmanager.EndpointTypeExternalEndpoints {
+			// the external endpoint is identified by the target instead of the target resource
+			endpointResp.Endpoint.Properties.TargetResourceID = nil
+			endpointResp.Endpoint.Properties.Target = endpoint.Properties.Target
+			endpointResp.Endpoint.Properties.EndpointLocation = endpoint.Properties.EndpointLocation
+			endpointResp.Endpoint.Type = ptr.To(string(azureTrafficManagerEndpointTypePrefix + armtrafficmanager.EndpointTypeExternalEndpoints))
+		}
 		resp.SetResponse(http.StatusOK, endpointResp, nil)
 	} else {
-		if endpointType != armtrafficmanager.EndpointTypeAzureEndpoints {
+		if !isSupportedEndpointType(endpointType) {
 			// controller should not send other endpoint types.
 			errResp.SetResponseError(http.StatusBadRequest, "InvalidEndpointType")
 		} else {
@@ -37,10 +37,13 @@ const (
 	// ValidProfileWithUnexpectedResponse is to test a special case which should never happen in the production, for example,
 	// missing required fields in the response.
 	ValidProfileWithUnexpectedResponse = "valid-profile-with-unexpected-response"
-	ConflictErrProfileName             = "conflict-err-profile"
-	InternalServerErrProfileName       = "internal-server-err-profile"
-	ThrottledErrProfileName            = "throttled-err-profile"
-	RequestTimeoutProfileName          = "request-timeout-profile"
+	// ValidProfileWithGeographicRoutingName is the profile using the "Geographic" traffic routing method, which rejects
+	// the requests using other routing methods.
+	ValidProfileWithGeographicRoutingName = "valid-profile-with-geographic-routing"
+	ConflictErrProfileName                = "conflict-err-profile"
+	InternalServerErrProfileName          = "internal-server-err-profile"
+	ThrottledErrProfileName               = "throttled-err-profile"
+	RequestTimeoutProfileName             = "request-timeout-profile"
 
 	ValidBackendName                           = "valid-backend"
 	ServiceImportName                          = "test-import"
@@ -90,7 +93,7 @@ func ProfileGet(_ context.Context, resourceGroupName string, profileName string,
 		return resp, errResp
 	}
 	switch profileName {
-	case ValidProfileName, ValidProfileWithEndpointsName, ValidProfileWithFailToDeleteEndpointName:
+	case ValidProfileName, ValidProfileWithEndpointsName, ValidProfileWithFailToDeleteEndpointName, ValidProfileWithGeographicRoutingName:
 		namespacedName := types.NamespacedName{Name: profileName, Namespace: ProfileNamespace}
 		profileResp := armtrafficmanager.ProfilesClientGetResponse{
 			Profile: armtrafficmanager.Profile{
@@ -144,6 +147,8 @@ func ProfileGet(_ context.Context, resourceGroupName string, profileName string,
 					Name: ptr.To(FailToDeleteEndpointName),
 				},
 			}
+		} else if profileName == ValidProfileWithGeographicRoutingName {
+			profileResp.Profile.Properties.TrafficRoutingMethod = ptr.To(armtrafficmanager.TrafficRoutingMethodGeographic)
 		}
 		resp.SetResponse(http.StatusOK, profileResp, nil)
 	case ValidProfileWithNilPropertiesName:
@@ -175,7 +180,13 @@ func ProfileCreateOrUpdate(_ context.Context, resourceGroupName string, profileN
 		errResp.SetResponseError(http.StatusInternalServerError, "InternalServerError")
 	case ThrottledErrProfileName:
 		errResp.SetResponseError(http.StatusTooManyRequests, "ThrottledError")
-	case ValidProfileName, ValidProfileWithUnexpectedResponse:
+	case ValidProfileName, ValidProfileWithUnexpectedResponse, ValidProfileWithGeographicRoutingName:
+		if profileName == ValidProfileWithGeographicRoutingName &&
+			(parameters.Properties.TrafficRoutingMethod == nil || *parameters.Properties.TrafficRoutingMethod != armtrafficmanager.TrafficRoutingMethodGeographic) {
+			// the traffic routing method cannot be changed
+			errResp.SetResponseError(http.StatusBadRequest, "BadRequestError")
+			return resp, errResp
+		}
 		if parameters.Properties.MonitorConfig.IntervalInSeconds != nil && *parameters.Properties.MonitorConfig.IntervalInSeconds == 10 {
 			if parameters.Properties.MonitorConfig.TimeoutInSeconds != nil && *parameters.Properties.MonitorConfig.TimeoutInSeconds > 9 {
 				errResp.SetResponseError(http.StatusBadRequest, "BadRequestError")
@@ -195,10 +206,11 @@ func ProfileCreateOrUpdate(_ context.Context, resourceGroupName string, profileN
 					Endpoints:                   []*armtrafficmanager.Endpoint{},
 					MonitorConfig:               parameters.Properties.MonitorConfig,
 					ProfileStatus:               ptr.To(armtrafficmanager.ProfileStatusEnabled),
-					TrafficRoutingMethod:        ptr.To(armtrafficmanager.TrafficRoutingMethodWeighted),
+					TrafficRoutingMethod:        parameters.Properties.TrafficRoutingMethod,
 					TrafficViewEnrollmentStatus: ptr.To(armtrafficmanager.TrafficViewEnrollmentStatusDisabled),
 				},
-				ID: ptr.To(fmt.Sprintf(ProfileResourceIDFormat, DefaultSubscriptionID, DefaultResourceGroupName, profileName)),
+				Tags: parameters.Tags,
+				ID:   ptr.To(fmt.Sprintf(ProfileResourceIDFormat, DefaultSubscriptionID, DefaultResourceGroupName, profileName)),
 			}}
 		if profileName == ValidProfileWithUnexpectedResponse {
 			// reset the dns name to nil to test the unexpected response
@@ -219,7 +231,7 @@ func ProfileDelete(_ context.Context, resourceGroupName string, profileName stri
 		return resp, errResp
 	}
 	switch profileName {
-	case ValidProfileName, ValidProfileWithUnexpectedResponse:
+	case ValidProfileName, ValidProfileWithUnexpectedResponse, ValidProfileWithGeographicRoutingName:
 		profileResp := armtrafficmanager.ProfilesClientDeleteResponse{}
 		resp.SetResponse(http.StatusOK, profileResp, nil)
 	default:
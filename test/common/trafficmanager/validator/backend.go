@@ -14,6 +14,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -25,6 +26,14 @@ var (
 	cmpTrafficManagerBackendOptions = cmp.Options{
 		commonCmpOptions,
 		cmpopts.IgnoreFields(fleetnetv1beta1.TrafficManagerBackend{}, "TypeMeta"),
+		// The profile resource id is decided by the Azure resources and is validated separately.
+		// The invalid endpoints carry the messages of the validation and Azure errors, which are covered by the unit tests.
+		// The last sync time changes on every successful reconciliation.
+		// Whether the endpoints have been created is only used by the deletion and is covered by the unit tests.
+		cmpopts.IgnoreFields(fleetnetv1beta1.TrafficManagerBackendStatus{}, "ProfileResourceID", "InvalidEndpoints", "LastSyncTime", "EndpointsCreated"),
+		// The generation of the internalServiceExport is decided by the API server and is covered by the unit tests.
+		// The percentage is derived from the weights and is covered by the unit tests.
+		cmpopts.IgnoreFields(fleetnetv1beta1.TrafficManagerEndpointStatus{}, "FromGeneration", "Percentage"),
 		cmpopts.SortSlices(func(s1, s2 fleetnetv1beta1.TrafficManagerEndpointStatus) bool {
 			return s1.From.Cluster < s2.From.Cluster
 		}),
@@ -35,11 +44,28 @@ var (
 		cmpConditionOptions,
 		// Here we don't validate the endpoint name and resource id to be decoupled from the implementation.
 		// It will be validated separately by comparing the values with the ones in the Azure traffic manager profile.
-		cmpopts.IgnoreFields(fleetnetv1beta1.TrafficManagerEndpointStatus{}, "Name", "ResourceID"), // ignore the generated endpoint name
+		// The monitor status is changed by the Azure Traffic Manager asynchronously and cannot be predicted.
+		// The generation of the internalServiceExport is decided by the API server.
+		// The percentage is derived from the weights.
+		cmpopts.IgnoreFields(fleetnetv1beta1.TrafficManagerEndpointStatus{}, "Name", "ResourceID", "MonitorStatus", "FromGeneration", "Percentage"), // ignore the generated endpoint name
+		cmpopts.IgnoreFields(fleetnetv1beta1.TrafficManagerBackendStatus{}, "ProfileResourceID", "InvalidEndpoints", "LastSyncTime", "EndpointsCreated"),
 		cmpopts.SortSlices(func(s1, s2 fleetnetv1beta1.TrafficManagerEndpointStatus) bool {
 			return s1.From.Cluster < s2.From.Cluster
 		}),
 	}
+
+	cmpTrafficManagerBackendEndpointsOptions = cmp.Options{
+		// The resource id and target are decided by the Azure resources and are validated by comparing the values with
+		// the ones in the Azure traffic manager profile.
+		// The monitor status is changed by the Azure Traffic Manager asynchronously and cannot be predicted.
+		// The generation of the internalServiceExport is decided by the API server.
+		// The percentage is derived from the weights.
+		cmpopts.IgnoreFields(fleetnetv1beta1.TrafficManagerEndpointStatus{}, "ResourceID", "Target", "MonitorStatus", "FromGeneration", "Percentage"),
+		cmpopts.SortSlices(func(s1, s2 fleetnetv1beta1.TrafficManagerEndpointStatus) bool {
+			return s1.Name < s2.Name
+		}),
+		cmpopts.EquateEmpty(),
+	}
 )
 
 // ValidateTrafficManagerBackendIfAcceptedAndIgnoringEndpointName validates the trafficManagerBackend object if it is accepted
@@ -61,6 +87,12 @@ func ValidateTrafficManagerBackendIfAcceptedAndIgnoringEndpointName(ctx context.
 						Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonInvalid),
 						ObservedGeneration: backend.Generation,
 					},
+					{
+						Status:             metav1.ConditionFalse,
+						Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+						Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonNotAccepted),
+						ObservedGeneration: backend.Generation,
+					},
 				},
 				Endpoints: wantEndpoints,
 			}
@@ -73,6 +105,12 @@ func ValidateTrafficManagerBackendIfAcceptedAndIgnoringEndpointName(ctx context.
 						Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonAccepted),
 						ObservedGeneration: backend.Generation,
 					},
+					{
+						Status:             metav1.ConditionTrue,
+						Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionReady),
+						Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonReady),
+						ObservedGeneration: backend.Generation,
+					},
 				},
 				Endpoints: wantEndpoints,
 			}
@@ -110,6 +148,25 @@ func ValidateTrafficManagerBackendStatusAndIgnoringEndpointNameConsistently(ctx
 	}, duration, interval).Should(gomega.Succeed(), "Get() trafficManagerBackend status mismatch")
 }
 
+// ValidateTrafficManagerBackendEndpoints validates the endpoints in the trafficManagerBackend status, including the
+// actual endpoint weights and the original serviceExport weights, while ignoring the resource id and target.
+func ValidateTrafficManagerBackendEndpoints(ctx context.Context, k8sClient client.Client, backendName types.NamespacedName, wantEndpoints []fleetnetv1beta1.TrafficManagerEndpointStatus, timeout time.Duration) {
+	gomega.Eventually(func() error {
+		backend := &fleetnetv1beta1.TrafficManagerBackend{}
+		if err := k8sClient.Get(ctx, backendName, backend); err != nil {
+			return err
+		}
+		if diff := cmp.Diff(
+			backend.Status.Endpoints,
+			wantEndpoints,
+			cmpTrafficManagerBackendEndpointsOptions,
+		); diff != "" {
+			return fmt.Errorf("trafficManagerBackend endpoints diff (-got, +want): \n%s, got %+v", diff, backend.Status.Endpoints)
+		}
+		return nil
+	}, timeout, interval).Should(gomega.Succeed(), "Get() trafficManagerBackend endpoints mismatch")
+}
+
 // ValidateTrafficManagerBackend validates the trafficManagerBackend object.
 func ValidateTrafficManagerBackend(ctx context.Context, k8sClient client.Client, want *fleetnetv1beta1.TrafficManagerBackend, timeout time.Duration) {
 	key := types.NamespacedName{Name: want.Name, Namespace: want.Namespace}
@@ -140,6 +197,25 @@ func ValidateTrafficManagerBackendConsistently(ctx context.Context, k8sClient cl
 	}, duration, interval).Should(gomega.Succeed(), "Get() trafficManagerBackend mismatch")
 }
 
+// ValidateTrafficManagerBackendCondition validates the condition of the same type in the trafficManagerBackend status,
+// while ignoring the message and last transition time.
+func ValidateTrafficManagerBackendCondition(ctx context.Context, k8sClient client.Client, name types.NamespacedName, want metav1.Condition, timeout time.Duration) {
+	gomega.Eventually(func() error {
+		backend := &fleetnetv1beta1.TrafficManagerBackend{}
+		if err := k8sClient.Get(ctx, name, backend); err != nil {
+			return err
+		}
+		got := meta.FindStatusCondition(backend.Status.Conditions, want.Type)
+		if got == nil {
+			return fmt.Errorf("trafficManagerBackend %s has no %s condition, got conditions %+v", name, want.Type, backend.Status.Conditions)
+		}
+		if diff := cmp.Diff(want, *got, cmpConditionOptions); diff != "" {
+			return fmt.Errorf("trafficManagerBackend %s condition mismatch (-want, +got) :\n%s", name, diff)
+		}
+		return nil
+	}, timeout, interval).Should(gomega.Succeed(), "Get() trafficManagerBackend condition mismatch")
+}
+
 // IsTrafficManagerBackendDeleted validates whether the backend is deleted or not.
 func IsTrafficManagerBackendDeleted(ctx context.Context, k8sClient client.Client, name types.NamespacedName, timeout time.Duration) {
 	gomega.Eventually(func() error {
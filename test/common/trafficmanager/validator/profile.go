@@ -103,6 +103,8 @@ func ValidateIfTrafficManagerProfileIsProgrammed(ctx context.Context, k8sClient
 			profile.Status,
 			wantStatus,
 			cmpConditionOptions,
+			// The endpoint summary depends on the backends created by the tests.
+			cmpopts.IgnoreFields(fleetnetv1beta1.TrafficManagerProfileStatus{}, "EndpointSummary"),
 		); diff != "" {
 			return fmt.Errorf("trafficManagerProfile status diff (-got, +want): \n%s, got %+v", diff, profile.Status)
 		}
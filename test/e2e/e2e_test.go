@@ -33,6 +33,9 @@ import (
 const (
 	azureSubscriptionEnv                = "AZURE_SUBSCRIPTION_ID"
 	azureTrafficManagerResourceGroupEnv = "AZURE_RESOURCE_GROUP"
+	// azureTrafficManagerSecondaryResourceGroupEnv is optional and the tests moving the trafficManagerProfile to
+	// another resource group are skipped when it's not set.
+	azureTrafficManagerSecondaryResourceGroupEnv = "AZURE_SECONDARY_RESOURCE_GROUP"
 
 	azureDNSFormat                             = "%s.%s.cloudapp.azure.com"
 	azureTrafficManagerProfileResourceIDFormat = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/trafficManagerProfiles/%s"
@@ -52,11 +55,13 @@ var (
 	scheme = runtime.NewScheme()
 	ctx    = context.Background()
 
-	atmValidator *azureprovider.Validator
-	pipClient    publicipaddressclient.Interface
+	atmValidator          *azureprovider.Validator
+	atmSecondaryValidator *azureprovider.Validator
+	pipClient             publicipaddressclient.Interface
 
-	subscriptionID   string
-	atmResourceGroup string
+	subscriptionID            string
+	atmResourceGroup          string
+	atmSecondaryResourceGroup string
 )
 
 func init() {
@@ -110,6 +115,12 @@ func initAzureClients() {
 		EndpointClient: atmClientFactory.NewEndpointsClient(),
 		ResourceGroup:  atmResourceGroup,
 	}
+	atmSecondaryResourceGroup = os.Getenv(azureTrafficManagerSecondaryResourceGroupEnv)
+	atmSecondaryValidator = &azureprovider.Validator{
+		ProfileClient:  atmValidator.ProfileClient,
+		EndpointClient: atmValidator.EndpointClient,
+		ResourceGroup:  atmSecondaryResourceGroup,
+	}
 	pipClient, err = publicipaddressclient.New(subscriptionID, cred, nil)
 	Expect(err).Should(Succeed(), "Failed to create Azure public ip address client")
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	fleetnetv1alpha1 "go.goms.io/fleet-networking/api/v1alpha1"
 	fleetnetv1beta1 "go.goms.io/fleet-networking/api/v1beta1"
@@ -40,10 +42,85 @@ type WorkloadManager struct {
 	namespace          string
 	service            corev1.Service
 	deploymentTemplate appsv1.Deployment
+	dnsLabelNameFunc   DNSLabelNameFunc
 }
 
-// NewWorkloadManager returns a workload manager with default values.
-func NewWorkloadManager(fleet *Fleet) *WorkloadManager {
+// DNSLabelNameFunc formats the DNS label name of the service exported from the cluster.
+type DNSLabelNameFunc func(namespace, serviceName, clusterName string) string
+
+// defaultDNSLabelName formats the DNS label name as [NAMESPACE]-[SERVICE]-[CLUSTER]-[RANDOM].
+func defaultDNSLabelName(namespace, serviceName, clusterName string) string {
+	return fmt.Sprintf("%s-%s-%s-%s", namespace, serviceName, clusterName, uniquename.RandomLowerCaseAlphabeticString(5))
+}
+
+// WorkloadManagerOption configures the workload deployed by the workload manager.
+type WorkloadManagerOption func(*WorkloadManager)
+
+// WithServiceType sets the type of the service deployed by the workload manager, which is LoadBalancer by default.
+// Use ClusterIP to test exporting and importing services without provisioning the cloud load balancers.
+func WithServiceType(serviceType corev1.ServiceType) WorkloadManagerOption {
+	return func(wm *WorkloadManager) {
+		wm.service.Spec.Type = serviceType
+	}
+}
+
+// WithHeadlessService makes the service deployed by the workload manager a headless ClusterIP service.
+// NOTE: headless services are not eligible for export, and the serviceExport will be marked as invalid.
+func WithHeadlessService() WorkloadManagerOption {
+	return func(wm *WorkloadManager) {
+		wm.service.Spec.Type = corev1.ServiceTypeClusterIP
+		wm.service.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+}
+
+// WithDNSLabelNameFunc overrides the format of the DNS label names built by BuildServiceDNSLabelName.
+// The formatted names longer than 63 characters are still truncated to valid DNS labels.
+func WithDNSLabelNameFunc(f DNSLabelNameFunc) WorkloadManagerOption {
+	return func(wm *WorkloadManager) {
+		wm.dnsLabelNameFunc = f
+	}
+}
+
+// TrafficManagerOption configures the TrafficManagerProfile and TrafficManagerBackend definitions returned by the
+// workload manager.
+type TrafficManagerOption func(*trafficManagerOptions)
+
+type trafficManagerOptions struct {
+	profileName string
+	backendName string
+}
+
+// WithProfileName sets the name of the TrafficManagerProfile, which is also referenced by the TrafficManagerBackend.
+// The service name is used by default.
+// Use different profile names to test multiple profiles in the same workload namespace.
+func WithProfileName(name string) TrafficManagerOption {
+	return func(o *trafficManagerOptions) {
+		o.profileName = name
+	}
+}
+
+// WithBackendName sets the name of the TrafficManagerBackend, which is the service name by default.
+// Use different backend names to test multiple backends in the same workload namespace.
+func WithBackendName(name string) TrafficManagerOption {
+	return func(o *trafficManagerOptions) {
+		o.backendName = name
+	}
+}
+
+func (wm *WorkloadManager) trafficManagerOptions(opts []TrafficManagerOption) *trafficManagerOptions {
+	// use the service name as the profile and backend names by default
+	o := &trafficManagerOptions{
+		profileName: wm.service.Name,
+		backendName: wm.service.Name,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// NewWorkloadManager returns a workload manager with default values, which can be overridden by the options.
+func NewWorkloadManager(fleet *Fleet, opts ...WorkloadManagerOption) *WorkloadManager {
 	// Using unique namespace decouple tests, especially considering we have test failure, and simply cleanup stage.
 	namespaceUnique := UniqueTestNamespace()
 
@@ -98,12 +175,17 @@ func NewWorkloadManager(fleet *Fleet) *WorkloadManager {
 		},
 	}
 
-	return &WorkloadManager{
+	wm := &WorkloadManager{
 		Fleet:              fleet,
 		namespace:          namespaceUnique,
 		service:            svcDef,
 		deploymentTemplate: deploymentTemplateDef,
+		dnsLabelNameFunc:   defaultDNSLabelName,
+	}
+	for _, opt := range opts {
+		opt(wm)
 	}
+	return wm
 }
 
 // Service returns the service which workload manager will deploy.
@@ -137,11 +219,13 @@ func (wm *WorkloadManager) MultiClusterService() fleetnetv1alpha1.MultiClusterSe
 }
 
 // TrafficManagerProfile returns the TrafficManagerProfile definition from pre-defined service name and namespace.
-func (wm *WorkloadManager) TrafficManagerProfile(resourceGroup string) fleetnetv1beta1.TrafficManagerProfile {
+// The profile name can be overridden by the WithProfileName option.
+func (wm *WorkloadManager) TrafficManagerProfile(resourceGroup string, opts ...TrafficManagerOption) fleetnetv1beta1.TrafficManagerProfile {
+	o := wm.trafficManagerOptions(opts)
 	return fleetnetv1beta1.TrafficManagerProfile{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: wm.namespace,
-			Name:      wm.service.Name, // use the service name as the profile name
+			Name:      o.profileName,
 		},
 		Spec: fleetnetv1beta1.TrafficManagerProfileSpec{
 			MonitorConfig: &fleetnetv1beta1.MonitorConfig{
@@ -158,15 +242,17 @@ func (wm *WorkloadManager) TrafficManagerProfile(resourceGroup string) fleetnetv
 }
 
 // TrafficManagerBackend returns the TrafficManagerBackend definition from pre-defined service name and namespace.
-func (wm *WorkloadManager) TrafficManagerBackend() fleetnetv1beta1.TrafficManagerBackend {
+// The backend name and the referenced profile name can be overridden by the WithBackendName and WithProfileName options.
+func (wm *WorkloadManager) TrafficManagerBackend(opts ...TrafficManagerOption) fleetnetv1beta1.TrafficManagerBackend {
+	o := wm.trafficManagerOptions(opts)
 	return fleetnetv1beta1.TrafficManagerBackend{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: wm.namespace,
-			Name:      wm.service.Name, // use the service name as the endpoint name
+			Name:      o.backendName,
 		},
 		Spec: fleetnetv1beta1.TrafficManagerBackendSpec{
 			Profile: fleetnetv1beta1.TrafficManagerProfileRef{
-				Name: wm.service.Name,
+				Name: o.profileName,
 			},
 			Backend: fleetnetv1beta1.TrafficManagerBackendRef{
 				Name: wm.service.Name,
@@ -198,11 +284,13 @@ func (wm *WorkloadManager) DeployWorkload(ctx context.Context) error {
 
 	for _, m := range wm.Fleet.MemberClusters() {
 		deploymentDef := wm.Deployment(m.Name())
-		serviceDef := wm.service
+		// NOTE: `Create` fills the cluster IPs allocated by the member cluster into the definition, which must not be
+		// shared with other clusters.
+		serviceDef := wm.service.DeepCopy()
 		if err := m.Client().Create(ctx, deploymentDef); err != nil {
 			return fmt.Errorf("failed to create app deployment %s in cluster %s: %w", deploymentDef.Name, m.Name(), err)
 		}
-		if err := m.Client().Create(ctx, &serviceDef); err != nil {
+		if err := m.Client().Create(ctx, serviceDef); err != nil {
 			return fmt.Errorf("failed to create app service %s in cluster %s: %w", serviceDef.Name, m.Name(), err)
 		}
 	}
@@ -226,8 +314,10 @@ func (wm *WorkloadManager) AddServiceDNSLabel(ctx context.Context, cluster *Clus
 }
 
 // BuildServiceDNSLabelName builds the DNS label name for the service.
+// The name is truncated with a hash suffix when it exceeds 63 characters, as Azure rejects the DNS label of the public
+// IP otherwise and the service is never exposed.
 func (wm *WorkloadManager) BuildServiceDNSLabelName(cluster *Cluster) string {
-	return fmt.Sprintf("%s-%s-%s-%s", wm.namespace, wm.service.Name, cluster.Name(), uniquename.RandomLowerCaseAlphabeticString(5))
+	return uniquename.TruncateDNS1123Label(wm.dnsLabelNameFunc(wm.namespace, wm.service.Name, cluster.Name()))
 }
 
 // UpdateServiceType updates the service type in the member cluster.
@@ -284,6 +374,42 @@ func (wm *WorkloadManager) ValidateServiceExportCondition(ctx context.Context, c
 	return nil
 }
 
+// ScaleDeployment updates the replicas of the app deployment in the member cluster, for example, to scale it to zero
+// so that the service becomes unhealthy.
+func (wm *WorkloadManager) ScaleDeployment(ctx context.Context, cluster *Cluster, replicas int32) error {
+	deploymentDef := wm.Deployment(cluster.Name())
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var deployment appsv1.Deployment
+		if err := cluster.kubeClient.Get(ctx, types.NamespacedName{Namespace: deploymentDef.Namespace, Name: deploymentDef.Name}, &deployment); err != nil {
+			return fmt.Errorf("failed to get app deployment %s in cluster %s: %w", deploymentDef.Name, cluster.Name(), err)
+		}
+		deployment.Spec.Replicas = ptr.To(replicas)
+		return cluster.kubeClient.Update(ctx, &deployment)
+	})
+}
+
+// ValidateServiceReadyEndpoints validates the number of the ready endpoints behind the service in the member cluster,
+// for example, to make sure no pods are serving the traffic after scaling the deployment to zero.
+func (wm *WorkloadManager) ValidateServiceReadyEndpoints(ctx context.Context, cluster *Cluster, want int) error {
+	var endpointSliceList discoveryv1.EndpointSliceList
+	if err := cluster.kubeClient.List(ctx, &endpointSliceList, client.InNamespace(wm.namespace), client.MatchingLabels{discoveryv1.LabelServiceName: wm.service.Name}); err != nil {
+		return fmt.Errorf("failed to list endpointSlices of service %s in cluster %s: %w", wm.service.Name, cluster.Name(), err)
+	}
+	got := 0
+	for _, endpointSlice := range endpointSliceList.Items {
+		for _, endpoint := range endpointSlice.Endpoints {
+			// A nil ready condition should be interpreted as ready.
+			if ptr.Deref(endpoint.Conditions.Ready, true) {
+				got++
+			}
+		}
+	}
+	if got != want {
+		return fmt.Errorf("service %s in cluster %s has %d ready endpoints, want %d", wm.service.Name, cluster.Name(), got, want)
+	}
+	return nil
+}
+
 // RemoveWorkload deletes workload(deployment and its service) from member clusters.
 func (wm *WorkloadManager) RemoveWorkload(ctx context.Context) error {
 	for _, m := range wm.Fleet.MemberClusters() {
@@ -7,6 +7,8 @@ package e2e
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
@@ -115,8 +117,7 @@ var _ = Describe("Test exporting service via Azure traffic manager", Ordered, fu
 
 		BeforeEach(func() {
 			By("Creating trafficManagerProfile with invalid resource group")
-			invalidProfile = wm.TrafficManagerProfile("invalid-resource-group")
-			invalidProfile.Name = "invalid-profile-name"
+			invalidProfile = wm.TrafficManagerProfile("invalid-resource-group", framework.WithProfileName("invalid-profile-name"))
 			Expect(hubClient.Create(ctx, &invalidProfile)).Should(Succeed(), "Failed to create the invalid trafficManagerProfile")
 
 			By("Validating the trafficManagerProfile status")
@@ -135,11 +136,7 @@ var _ = Describe("Test exporting service via Azure traffic manager", Ordered, fu
 
 		It("Creating trafficManagerBackend with invalid profile", func() {
 			By("Creating trafficManagerBackend")
-			backend = wm.TrafficManagerBackend()
-			// update the profile to invalid one
-			backend.Spec.Profile = fleetnetv1beta1.TrafficManagerProfileRef{
-				Name: invalidProfile.Name,
-			}
+			backend = wm.TrafficManagerBackend(framework.WithProfileName(invalidProfile.Name))
 			backendName = types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}
 			Expect(hubClient.Create(ctx, &backend)).Should(Succeed(), "Failed to create the trafficManagerBackend")
 
@@ -422,6 +419,7 @@ var _ = Describe("Test exporting service via Azure traffic manager", Ordered, fu
 	Context("Test valid trafficManagerBackend", Ordered, func() {
 		var backend fleetnetv1beta1.TrafficManagerBackend
 		var backendName types.NamespacedName
+		var status fleetnetv1beta1.TrafficManagerBackendStatus
 		memberDNSLabels := make([]string, 2)
 
 		var extraTrafficManagerEndpoint *armtrafficmanager.Endpoint
@@ -463,8 +461,14 @@ var _ = Describe("Test exporting service via Azure traffic manager", Ordered, fu
 					},
 				},
 			}
-			status := validator.ValidateTrafficManagerBackendIfAcceptedAndIgnoringEndpointName(ctx, hubClient, backendName, true, wantEndpoints, heavyAzureOperationTimeout)
+			status = validator.ValidateTrafficManagerBackendIfAcceptedAndIgnoringEndpointName(ctx, hubClient, backendName, true, wantEndpoints, heavyAzureOperationTimeout)
 			validator.ValidateTrafficManagerBackendStatusAndIgnoringEndpointNameConsistently(ctx, hubClient, backendName, status)
+			validator.ValidateTrafficManagerBackendCondition(ctx, hubClient, backendName, metav1.Condition{
+				Type:               string(fleetnetv1beta1.TrafficManagerBackendConditionAccepted),
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: backend.Generation,
+				Reason:             string(fleetnetv1beta1.TrafficManagerBackendReasonAccepted),
+			}, lightAzureOperationTimeout)
 
 			By("Validating the Azure traffic manager profile")
 			atmProfile = buildDesiredATMProfile(profile, status.Endpoints)
@@ -566,6 +570,21 @@ var _ = Describe("Test exporting service via Azure traffic manager", Ordered, fu
 			atmValidator.ValidateProfile(ctx, atmProfileName, atmProfile) // no change on the profile
 		})
 
+		It("Updating the probe path of the trafficManagerProfile", func() {
+			profile.Spec.MonitorConfig.Path = ptr.To("/another-path")
+			updateTrafficManagerProfile(hubClient, profile)
+			// The Azure traffic manager profile is updated in place instead of being recreated, so that its resource ID
+			// is unchanged.
+			validator.ValidateIfTrafficManagerProfileIsProgrammed(ctx, hubClient, profileName, true, profileResourceID, lightAzureOperationTimeout)
+
+			By("Validating the Azure traffic manager profile keeps the endpoints created by the trafficManagerBackend")
+			atmProfile.Properties.MonitorConfig.Path = ptr.To("/another-path")
+			atmValidator.ValidateProfile(ctx, atmProfileName, atmProfile)
+
+			By("Validating the trafficManagerBackend status is unchanged")
+			validator.ValidateTrafficManagerBackendStatusAndIgnoringEndpointNameConsistently(ctx, hubClient, backendName, status)
+		})
+
 		It("Creating another trafficManagerBackend to export the same service", func() {
 			By("Creating an invalid trafficManagerBackend")
 			invalidBackend := wm.TrafficManagerBackend()
@@ -835,6 +854,31 @@ var _ = Describe("Test exporting service via Azure traffic manager", Ordered, fu
 			atmValidator.ValidateProfile(ctx, atmProfileName, atmProfile)
 		})
 
+		It("Scaling the deployment to zero on member-1", func() {
+			By("Getting the trafficManagerBackend status")
+			Expect(hubClient.Get(ctx, backendName, &backend)).Should(Succeed(), "Failed to get the trafficManagerBackend")
+			status := backend.Status
+
+			By("Scaling the deployment to zero on member-1")
+			replicas := *wm.Deployment(memberClusters[0].Name()).Spec.Replicas
+			Expect(wm.ScaleDeployment(ctx, memberClusters[0], 0)).Should(Succeed(), "Failed to scale the deployment")
+			DeferCleanup(func() {
+				By("Restoring the deployment replicas on member-1")
+				Expect(wm.ScaleDeployment(ctx, memberClusters[0], replicas)).Should(Succeed(), "Failed to restore the deployment replicas")
+				Eventually(func() error {
+					return wm.ValidateServiceReadyEndpoints(ctx, memberClusters[0], int(replicas))
+				}, defaultTimeout, framework.PollInterval).Should(Succeed(), "Failed to validate the ready endpoints of the service")
+			})
+
+			By("Validating the service has no ready endpoints on member-1")
+			Eventually(func() error {
+				return wm.ValidateServiceReadyEndpoints(ctx, memberClusters[0], 0)
+			}, defaultTimeout, framework.PollInterval).Should(Succeed(), "Failed to validate the ready endpoints of the service")
+
+			By("Validating the trafficManagerBackend keeps the endpoint of member-1")
+			validator.ValidateTrafficManagerBackendStatusAndIgnoringEndpointNameConsistently(ctx, hubClient, backendName, status)
+		})
+
 		It("Updating the weight to 0", func() {
 			By("Updating the trafficManagerBackend spec")
 			Eventually(func() error {
@@ -910,8 +954,130 @@ var _ = Describe("Test exporting service via Azure traffic manager", Ordered, fu
 			atmValidator.ValidateProfile(ctx, atmProfileName, atmProfile)
 		})
 	})
+
+	Context("Test moving trafficManagerProfile to another resource group", Ordered, func() {
+		var backend fleetnetv1beta1.TrafficManagerBackend
+		var backendName types.NamespacedName
+		var wantEndpoints []fleetnetv1beta1.TrafficManagerEndpointStatus
+		memberDNSLabels := make([]string, 2)
+
+		BeforeEach(func() {
+			if atmSecondaryResourceGroup == "" {
+				Skip("Skipping moving the trafficManagerProfile when the secondary resource group is not set")
+			}
+
+			By("Adding DNS label to the service on member-1 & member-2")
+			for i := range memberClusters {
+				memberDNSLabels[i] = wm.BuildServiceDNSLabelName(memberClusters[i])
+				Eventually(func() error {
+					return wm.AddServiceDNSLabel(ctx, memberClusters[i], memberDNSLabels[i])
+				}, defaultTimeout, framework.PollInterval).Should(Succeed(), "Failed to add DNS label to the service")
+			}
+
+			By("Exporting service with DNS label assigned")
+			Expect(wm.ExportService(ctx, wm.ServiceExport())).Should(Succeed(), "Failed to export the service")
+
+			By("Creating trafficManagerBackend")
+			backend = wm.TrafficManagerBackend()
+			backendName = types.NamespacedName{Namespace: backend.Namespace, Name: backend.Name}
+			Expect(hubClient.Create(ctx, &backend)).Should(Succeed(), "Failed to create the trafficManagerBackend")
+
+			By("Validating the trafficManagerBackend status")
+			wantEndpoints = []fleetnetv1beta1.TrafficManagerEndpointStatus{
+				{
+					Weight: ptr.To(int64(50)),
+					Target: ptr.To(fmt.Sprintf(azureDNSFormat, memberDNSLabels[0], clusterLocation)),
+					From: &fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: memberClusters[0].Name()},
+						Weight:        ptr.To(int64(1)),
+					},
+				},
+				{
+					Weight: ptr.To(int64(50)),
+					Target: ptr.To(fmt.Sprintf(azureDNSFormat, memberDNSLabels[1], clusterLocation)),
+					From: &fleetnetv1beta1.FromCluster{
+						ClusterStatus: fleetnetv1beta1.ClusterStatus{Cluster: memberClusters[1].Name()},
+						Weight:        ptr.To(int64(1)),
+					},
+				},
+			}
+			status := validator.ValidateTrafficManagerBackendIfAcceptedAndIgnoringEndpointName(ctx, hubClient, backendName, true, wantEndpoints, heavyAzureOperationTimeout)
+
+			By("Validating the Azure traffic manager profile")
+			atmProfile = buildDesiredATMProfile(profile, status.Endpoints)
+			atmValidator.ValidateProfile(ctx, atmProfileName, atmProfile)
+			validateTrafficManagerBackendProfileResourceID(hubClient, backendName, profileResourceID)
+		})
+
+		AfterEach(func() {
+			if atmSecondaryResourceGroup == "" {
+				Skip("Skipping deleting when the secondary resource group is not set")
+			}
+
+			By("Deleting trafficManagerBackend")
+			Expect(client.IgnoreNotFound(hubClient.Delete(ctx, &backend))).Should(Succeed(), "Failed to delete the trafficManagerBackend")
+			validator.IsTrafficManagerBackendDeleted(ctx, hubClient, backendName, lightAzureOperationTimeout)
+
+			By("Validating the Azure traffic manager profile in the secondary resource group")
+			atmSecondaryValidator.ValidateProfile(ctx, atmProfileName, buildDesiredATMProfile(profile, nil))
+
+			// The trafficManagerProfile is deleted here, as the outer AfterEach validates the deletion of the Azure
+			// traffic manager profile in the primary resource group only.
+			By("Deleting trafficManagerProfile")
+			err := hubClient.Delete(ctx, &profile)
+			Expect(err).Should(SatisfyAny(Succeed(), WithTransform(errors.IsNotFound, BeTrue())), "Failed to delete the trafficManagerProfile")
+			validator.IsTrafficManagerProfileDeleted(ctx, hubClient, profileName, lightAzureOperationTimeout)
+			atmSecondaryValidator.IsProfileDeleted(ctx, atmProfileName)
+		})
+
+		It("Recreating trafficManagerProfile with the same name in another resource group", func() {
+			previousATMProfileName := atmProfileName
+
+			By("Deleting trafficManagerProfile")
+			Expect(hubClient.Delete(ctx, &profile)).Should(Succeed(), "Failed to delete the trafficManagerProfile")
+			validator.IsTrafficManagerProfileDeleted(ctx, hubClient, profileName, lightAzureOperationTimeout)
+
+			By("Validating the trafficManagerBackend status")
+			validator.ValidateTrafficManagerBackendIfAcceptedAndIgnoringEndpointName(ctx, hubClient, backendName, false, nil, lightAzureOperationTimeout)
+
+			By("Creating trafficManagerProfile in the secondary resource group")
+			profile = wm.TrafficManagerProfile(atmSecondaryResourceGroup)
+			Expect(hubClient.Create(ctx, &profile)).Should(Succeed(), "Failed to create the trafficManagerProfile")
+			atmProfileName = fmt.Sprintf(trafficmanagerprofile.AzureResourceProfileNameFormat, profile.UID)
+			profileResourceID = fmt.Sprintf(azureTrafficManagerProfileResourceIDFormat, subscriptionID, atmSecondaryResourceGroup, atmProfileName)
+			profile = *validator.ValidateIfTrafficManagerProfileIsProgrammed(ctx, hubClient, profileName, true, profileResourceID, lightAzureOperationTimeout)
+
+			By("Validating the trafficManagerBackend status")
+			status := validator.ValidateTrafficManagerBackendIfAcceptedAndIgnoringEndpointName(ctx, hubClient, backendName, true, wantEndpoints, heavyAzureOperationTimeout)
+			validator.ValidateTrafficManagerBackendStatusAndIgnoringEndpointNameConsistently(ctx, hubClient, backendName, status)
+			validateTrafficManagerBackendProfileResourceID(hubClient, backendName, profileResourceID)
+
+			By("Validating the Azure traffic manager profile in the secondary resource group")
+			atmProfile = buildDesiredATMProfile(profile, status.Endpoints)
+			atmSecondaryValidator.ValidateProfile(ctx, atmProfileName, atmProfile)
+
+			By("Validating the endpoints under the previous Azure traffic manager profile are gone")
+			atmValidator.IsProfileDeleted(ctx, previousATMProfileName)
+		})
+	})
 })
 
+// validateTrafficManagerBackendProfileResourceID validates the backend records the Azure traffic manager profile hosting
+// its endpoints.
+func validateTrafficManagerBackendProfileResourceID(hubClient client.Client, backendName types.NamespacedName, want string) {
+	Eventually(func() error {
+		var backend fleetnetv1beta1.TrafficManagerBackend
+		if err := hubClient.Get(ctx, backendName, &backend); err != nil {
+			return err
+		}
+		// Azure may return the resource ID in a different case.
+		if !strings.EqualFold(backend.Status.ProfileResourceID, want) {
+			return fmt.Errorf("got profileResourceID %q, want %q", backend.Status.ProfileResourceID, want)
+		}
+		return nil
+	}, lightAzureOperationTimeout, framework.PollInterval).Should(Succeed(), "Failed to validate the profileResourceID of the trafficManagerBackend")
+}
+
 func buildDesiredATMProfile(profile fleetnetv1beta1.TrafficManagerProfile, endpoints []fleetnetv1beta1.TrafficManagerEndpointStatus) armtrafficmanager.Profile {
 	monitorConfig := profile.Spec.MonitorConfig
 	namespacedName := types.NamespacedName{Name: profile.Name, Namespace: profile.Namespace}
@@ -941,6 +1107,17 @@ func buildDesiredATMProfile(profile fleetnetv1beta1.TrafficManagerProfile, endpo
 		},
 	}
 
+	// Add tags if specified
+	if len(profile.Spec.Tags) > 0 {
+		names := make([]string, 0, len(profile.Spec.Tags))
+		for key, value := range profile.Spec.Tags {
+			res.Tags[key] = ptr.To(value)
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		res.Tags[objectmeta.AzureTrafficManagerProfileManagedTagsKey] = ptr.To(strings.Join(names, ","))
+	}
+
 	// Add custom headers if specified
 	if len(monitorConfig.CustomHeaders) > 0 {
 		customHeaders := make([]*armtrafficmanager.MonitorConfigCustomHeadersItem, 0, len(monitorConfig.CustomHeaders))
@@ -953,6 +1130,18 @@ func buildDesiredATMProfile(profile fleetnetv1beta1.TrafficManagerProfile, endpo
 		res.Properties.MonitorConfig.CustomHeaders = customHeaders
 	}
 
+	// Add expected status code ranges if specified
+	if len(monitorConfig.ExpectedStatusCodeRanges) > 0 {
+		ranges := make([]*armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem, 0, len(monitorConfig.ExpectedStatusCodeRanges))
+		for _, r := range monitorConfig.ExpectedStatusCodeRanges {
+			ranges = append(ranges, &armtrafficmanager.MonitorConfigExpectedStatusCodeRangesItem{
+				Min: ptr.To(r.Min),
+				Max: ptr.To(r.Max),
+			})
+		}
+		res.Properties.MonitorConfig.ExpectedStatusCodeRanges = ranges
+	}
+
 	for _, e := range endpoints {
 		res.Properties.Endpoints = append(res.Properties.Endpoints, &armtrafficmanager.Endpoint{
 			ID:   &e.ResourceID,